@@ -13,7 +13,7 @@ _fabric() {
   _get_comp_words_by_ref -n : cur prev words cword
 
   # Define all possible options/flags
-  local opts="--pattern -p --variable -v --context -C --session --attachment -a --setup -S --temperature -t --topp -T --stream -s --presencepenalty -P --raw -r --frequencypenalty -F --listpatterns -l --listmodels -L --listcontexts -x --listsessions -X --updatepatterns -U --copy -c --model -m --vendor -V --modelContextLength --output -o --output-session --latest -n --changeDefaultModel -d --youtube -y --playlist --transcript --transcript-with-timestamps --comments --metadata --yt-dlp-args --language -g --scrape_url -u --scrape_question -q --seed -e --thinking --wipecontext -w --wipesession -W --printcontext --printsession --readability --input-has-vars --dry-run --serve --serveOllama --address --api-key --config --search --search-location --image-file --image-size --image-quality --image-compression --image-background --suppress-think --think-start-tag --think-end-tag --disable-responses-api --voice --list-gemini-voices --notification --notification-command --version --listextensions --addextension --rmextension --strategy --liststrategies --listvendors --shell-complete-list --help -h"
+  local opts="--input-file --code-chunk-tokens --input-separator --input-header --pattern -p --variable -v --context -C --session --attachment -a --setup -S --temperature -t --topp -T --stream -s --presencepenalty -P --raw -r --frequencypenalty -F --listpatterns -l --listmodels -L --listcontexts -x --listsessions -X --updatepatterns -U --copy -c --model -m --vendor -V --modelContextLength --output -o --stream-file --output-session --latest -n --changeDefaultModel -d --youtube -y --playlist --transcript --transcript-with-timestamps --comments --metadata --yt-dlp-args --podcast --episode --feed --feed-entries --audio --transcribe-vendor --transcribe-model --language -g --scrape_url -u --scrape_question -q --seed -e --max-tokens --stop --thinking --wipecontext -w --wipesession -W --printcontext --printsession --backup --restore --readability --input-has-vars --dry-run --serve --demo --serveOllama --address --api-key --config --search --search-location --image-file --image-size --image-quality --image-compression --image-background --suppress-think --think-start-tag --think-end-tag --disable-responses-api --voice --list-gemini-voices --export-style --list-export-styles --speak --speak-model --notification --notification-command --version --listextensions --addextension --rmextension --strategy --liststrategies --listvendors --shell-complete-list --review-repo --review-range --review-pattern --review-concurrency --review-editor-url --batch-csv --batch-input-column --batch-output-column --batch-output-dir --batch-concurrency --batch-preview --batch-manifest --batch-confirm-cost --precheck --help -h"
 
   # Helper function for dynamic completions
   _fabric_get_list() {
@@ -22,7 +22,7 @@ _fabric() {
 
   # Handle completions based on the previous word
   case "${prev}" in
-  -p | --pattern)
+  -p | --pattern | --review-pattern)
     COMPREPLY=($(compgen -W "$(_fabric_get_list --listpatterns)" -- "${cur}"))
     return 0
     ;;
@@ -42,6 +42,10 @@ _fabric() {
     COMPREPLY=($(compgen -W "$(_fabric_get_list --listvendors)" -- "${cur}"))
     return 0
     ;;
+  --transcribe-vendor)
+    COMPREPLY=($(compgen -W "$(_fabric_get_list --listvendors) whisper.cpp" -- "${cur}"))
+    return 0
+    ;;
   -w | --wipecontext)
     COMPREPLY=($(compgen -W "$(_fabric_get_list --listcontexts)" -- "${cur}"))
     return 0
@@ -74,11 +78,19 @@ _fabric() {
     COMPREPLY=($(compgen -W "$(_fabric_get_list --list-gemini-voices)" -- "${cur}"))
     return 0
     ;;
+  --export-style)
+    COMPREPLY=($(compgen -W "$(_fabric_get_list --list-export-styles)" -- "${cur}"))
+    return 0
+    ;;
   # Options requiring file/directory paths
-  -a | --attachment | -o | --output | --config | --addextension | --image-file)
+  -a | --attachment | --input-file | -o | --output | --stream-file | --config | --addextension | --image-file | --restore | --audio | --batch-csv | --batch-manifest)
     _filedir
     return 0
     ;;
+  --backup | --review-repo | --batch-output-dir)
+    _filedir -d
+    return 0
+    ;;
   # Image generation options with specific values
   --image-size)
     COMPREPLY=($(compgen -W "1024x1024 1536x1024 1024x1536 auto" -- "$cur"))
@@ -92,8 +104,12 @@ _fabric() {
     COMPREPLY=($(compgen -W "opaque transparent" -- "$cur"))
     return 0
     ;;
+  --precheck)
+    COMPREPLY=($(compgen -W "show append" -- "$cur"))
+    return 0
+    ;;
   # Options requiring simple arguments (no specific completion logic here)
-  -v | --variable | -t | --temperature | -T | --topp | -P | --presencepenalty | -F | --frequencypenalty | --modelContextLength | -n | --latest | -y | --youtube | --yt-dlp-args | -g | --language | -u | --scrape_url | -q | --scrape_question | -e | --seed | --address | --api-key | --search-location | --image-compression | --think-start-tag | --think-end-tag | --notification-command)
+  -v | --variable | -t | --temperature | -T | --topp | -P | --presencepenalty | -F | --frequencypenalty | --modelContextLength | --code-chunk-tokens | --input-separator | --input-header | -n | --latest | -y | --youtube | --yt-dlp-args | --podcast | --episode | --feed | --feed-entries | --transcribe-model | -g | --language | -u | --scrape_url | -q | --scrape_question | -e | --seed | --max-tokens | --stop | --address | --api-key | --search-location | --image-compression | --think-start-tag | --think-end-tag | --speak-model | --notification-command | --review-range | --review-concurrency | --review-editor-url | --batch-input-column | --batch-output-column | --batch-concurrency | --batch-confirm-cost)
     # No specific completion suggestions, user types the value
     return 0
     ;;
@@ -47,6 +47,11 @@ function __fabric_get_gemini_voices
         $cmd --list-gemini-voices --shell-complete-list 2>/dev/null
 end
 
+function __fabric_get_export_styles
+        set cmd (commandline -opc)[1]
+        $cmd --list-export-styles --shell-complete-list 2>/dev/null
+end
+
 # Main completion function
 function __fabric_register_completions
         set cmd $argv[1]
@@ -65,18 +70,27 @@ function __fabric_register_completions
         complete -c $cmd -s m -l model -d "Choose model" -a "(__fabric_get_models)"
         complete -c $cmd -s V -l vendor -d "Specify vendor for chosen model (e.g., -V \"LM Studio\" -m openai/gpt-oss-20b)" -a "(__fabric_get_vendors)"
         complete -c $cmd -l modelContextLength -d "Model context length (only affects ollama)"
+        complete -c $cmd -l input-file -d "Read the input from a text file, a source file or a directory of sources, can be used multiple times" -r
+        complete -c $cmd -l code-chunk-tokens -d "Split source files of more tokens at their function and class boundaries"
+        complete -c $cmd -l input-separator -d "Separator between multiple input files"
+        complete -c $cmd -l input-header -d "Header before each of multiple input files, {{path}} and {{name}} are replaced"
         complete -c $cmd -s o -l output -d "Output to file" -r
+        complete -c $cmd -l stream-file -d "Write the output to this file as it arrives" -r
         complete -c $cmd -s n -l latest -d "Number of latest patterns to list (default: 0)"
-        complete -c $cmd -s y -l youtube -d "YouTube video or play list URL to grab transcript, comments from it"
+        complete -c $cmd -s y -l youtube -d "YouTube video, play list or channel URL to grab transcript, comments from it"
         complete -c $cmd -s g -l language -d "Specify the Language Code for the chat, e.g. -g=en -g=zh"
         complete -c $cmd -s u -l scrape_url -d "Scrape website URL to markdown using Jina AI"
         complete -c $cmd -s q -l scrape_question -d "Search question using Jina AI"
         complete -c $cmd -s e -l seed -d "Seed to be used for LMM generation"
+        complete -c $cmd -l max-tokens -d "Maximum number of tokens to generate"
+        complete -c $cmd -l stop -d "Stop generating when the model outputs this sequence (can be used multiple times)"
         complete -c $cmd -l thinking -d "Set reasoning/thinking level" -a "off low medium high"
         complete -c $cmd -s w -l wipecontext -d "Wipe context" -a "(__fabric_get_contexts)"
         complete -c $cmd -s W -l wipesession -d "Wipe session" -a "(__fabric_get_sessions)"
         complete -c $cmd -l printcontext -d "Print context" -a "(__fabric_get_contexts)"
         complete -c $cmd -l printsession -d "Print session" -a "(__fabric_get_sessions)"
+        complete -c $cmd -l backup -d "Back up user data into an archive in the given directory" -r -a "(__fish_complete_directories)"
+        complete -c $cmd -l restore -d "Restore a backup archive after verifying its integrity" -r -a "*.tar.gz"
         complete -c $cmd -l address -d "The address to bind the REST API (default: :8080)"
         complete -c $cmd -l api-key -d "API key used to secure server routes"
         complete -c $cmd -l config -d "Path to YAML config file" -r -a "*.yaml *.yml"
@@ -92,7 +106,21 @@ function __fabric_register_completions
         complete -c $cmd -l think-start-tag -d "Start tag for thinking sections (default: <think>)"
         complete -c $cmd -l think-end-tag -d "End tag for thinking sections (default: </think>)"
         complete -c $cmd -l voice -d "TTS voice name for supported models (e.g., Kore, Charon, Puck)" -a "(__fabric_get_gemini_voices)"
+        complete -c $cmd -l export-style -d "Export style of the -o file" -a "(__fabric_get_export_styles)"
         complete -c $cmd -l notification-command -d "Custom command to run for notifications (overrides built-in notifications)"
+        complete -c $cmd -l review-repo -d "Review the changes of the git repository at this path file by file" -r
+        complete -c $cmd -l review-range -d "Commit range reviewed by --review-repo (e.g., main..HEAD)"
+        complete -c $cmd -l review-pattern -d "Pattern run on the diff of each file by --review-repo" -a "(__fabric_get_patterns)"
+        complete -c $cmd -l review-concurrency -d "Number of files reviewed at the same time by --review-repo"
+        complete -c $cmd -l review-editor-url -d "Link opening a finding in your editor, {{path}} and {{line}} are replaced"
+        complete -c $cmd -l batch-csv -d "Run the pattern once per row of this CSV file, the columns fill the pattern variables" -r
+        complete -c $cmd -l batch-input-column -d "Column of --batch-csv sent as the input of each row"
+        complete -c $cmd -l batch-output-column -d "Column of --batch-csv the outputs are written to"
+        complete -c $cmd -l batch-output-dir -d "Write the output of each row of --batch-csv to a file of this directory" -r
+        complete -c $cmd -l batch-concurrency -d "Number of rows of --batch-csv run at the same time"
+        complete -c $cmd -l batch-manifest -d "Write a JSON manifest of the results of --batch-csv to this file" -r
+        complete -c $cmd -l batch-confirm-cost -d "Ask before running --batch-csv when its estimated cost in USD is above this"
+        complete -c $cmd -l precheck -d "Check the input of writing patterns for typos first" -a "show append"
 
         # Boolean flags (no arguments)
         complete -c $cmd -s S -l setup -d "Run setup for all reconfigurable parts of fabric"
@@ -112,21 +140,33 @@ function __fabric_register_completions
         complete -c $cmd -l comments -d "Grab comments from YouTube video and send to chat"
         complete -c $cmd -l metadata -d "Output video metadata"
         complete -c $cmd -l yt-dlp-args -d "Additional arguments to pass to yt-dlp (e.g. '--cookies-from-browser brave')"
+        complete -c $cmd -l podcast -d "Podcast RSS feed URL to list episodes from, select episodes to transcribe with --episode"
+        complete -c $cmd -l episode -d "Number of the podcast episode to transcribe, 1 is the latest (can be used multiple times)"
+        complete -c $cmd -l feed -d "RSS or Atom feed URL to fetch the latest entries from as markdown and send to chat"
+        complete -c $cmd -l feed-entries -d "Number of the latest feed entries to fetch (default: 5)"
+        complete -c $cmd -l audio -d "Transcribe the audio file and send the transcript to chat" -r
+        complete -c $cmd -l transcribe-vendor -d "Vendor used for audio transcription, whisper.cpp to transcribe locally" -a "(__fabric_get_vendors) whisper.cpp"
+        complete -c $cmd -l transcribe-model -d "Model used for audio transcription, the model file with whisper.cpp (default: whisper-1)"
         complete -c $cmd -l readability -d "Convert HTML input into a clean, readable view"
         complete -c $cmd -l input-has-vars -d "Apply variables to user input"
         complete -c $cmd -l dry-run -d "Show what would be sent to the model without actually sending it"
         complete -c $cmd -l search -d "Enable web search tool for supported models (Anthropic, OpenAI, Gemini)"
         complete -c $cmd -l serve -d "Serve the Fabric Rest API"
+        complete -c $cmd -l demo -d "Run with bundled demo patterns and an offline demo vendor"
         complete -c $cmd -l serveOllama -d "Serve the Fabric Rest API with ollama endpoints"
         complete -c $cmd -l version -d "Print current version"
         complete -c $cmd -l listextensions -d "List all registered extensions"
         complete -c $cmd -l liststrategies -d "List all strategies"
         complete -c $cmd -l listvendors -d "List all vendors"
         complete -c $cmd -l list-gemini-voices -d "List all available Gemini TTS voices"
+        complete -c $cmd -l list-export-styles -d "List the export styles"
+        complete -c $cmd -l speak -d "Read the output aloud sentence by sentence while it is generated"
+        complete -c $cmd -l speak-model -d "Gemini TTS model used by --speak"
         complete -c $cmd -l shell-complete-list -d "Output raw list without headers/formatting (for shell completion)"
         complete -c $cmd -l suppress-think -d "Suppress text enclosed in thinking tags"
         complete -c $cmd -l disable-responses-api -d "Disable OpenAI Responses API (default: false)"
         complete -c $cmd -l notification -d "Send desktop notification when command completes"
+        complete -c $cmd -l batch-preview -d "Show the prompt of the first row of --batch-csv without running the rows"
         complete -c $cmd -s h -l help -d "Show this help message"
 end
 
@@ -0,0 +1,238 @@
+package cli
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"maps"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/danielmiessler/fabric/internal/chat"
+	"github.com/danielmiessler/fabric/internal/core"
+	"github.com/danielmiessler/fabric/internal/domain"
+	"github.com/danielmiessler/fabric/internal/plugins/db/fsdb"
+	"github.com/danielmiessler/fabric/internal/tools/csvbatch"
+	"github.com/danielmiessler/fabric/internal/tools/pricing"
+)
+
+// handleCSVBatch runs the pattern once per row of --batch-csv, the columns filling the pattern variables.
+// The outputs go to a new column of the CSV, or to a file per row with --batch-output-dir.
+func handleCSVBatch(currentFlags *Flags, registry *core.PluginRegistry) (handled bool, err error) {
+	if currentFlags.BatchCSV == "" {
+		return
+	}
+	handled = true
+
+	if currentFlags.Pattern == "" {
+		err = fmt.Errorf("--batch-csv needs a --pattern to run on the rows")
+		return
+	}
+	var file *os.File
+	if file, err = os.Open(currentFlags.BatchCSV); err != nil {
+		return
+	}
+	defer file.Close()
+	var table *csvbatch.Table
+	if table, err = csvbatch.Read(file); err != nil {
+		err = fmt.Errorf("%s: %v", currentFlags.BatchCSV, err)
+		return
+	}
+	if len(table.Rows) == 0 {
+		fmt.Fprintf(os.Stderr, "No rows to run in %s\n", currentFlags.BatchCSV)
+		return
+	}
+
+	// the input column is the input of each row, the input of the command is used without one
+	inputColumn := table.Column(currentFlags.BatchInputColumn)
+	rowRequest := func(row map[string]string) (variables map[string]string, input string) {
+		variables = maps.Clone(currentFlags.PatternVariables)
+		if variables == nil {
+			variables = map[string]string{}
+		}
+		maps.Copy(variables, row)
+		input = currentFlags.Message
+		if inputColumn >= 0 {
+			input = row[currentFlags.BatchInputColumn]
+		}
+		return
+	}
+
+	// the prompts of the rows are built before running any, for the estimate and to fail early
+	prompts := make([]csvbatch.RowPrompt, len(table.Rows))
+	for i := range table.Rows {
+		variables, input := rowRequest(table.Row(i))
+		var pattern *fsdb.Pattern
+		if pattern, err = registry.Db.Patterns.GetApplyVariables(currentFlags.Pattern, variables, input); err != nil {
+			err = fmt.Errorf("row %d: %v", i+1, err)
+			return
+		}
+		prompts[i] = csvbatch.RowPrompt{Prompt: pattern.Pattern, Input: input}
+	}
+	fmt.Fprintf(os.Stderr, "Prompt of row 1 of %d:\n\n%s\n\n", len(table.Rows), prompts[0].Prompt)
+
+	prices, loadErr := pricing.Load(registry.Db.FilePath(pricing.FileName))
+	if loadErr != nil {
+		fmt.Fprintf(os.Stderr, "Warning: %v, the batch is not priced\n", loadErr)
+	}
+	calibrations, loadErr := csvbatch.LoadCalibrations(registry.Db.Store)
+	if loadErr != nil {
+		fmt.Fprintf(os.Stderr, "Warning: %v, the estimate is not corrected by the past batches\n", loadErr)
+	}
+	model := currentFlags.Model
+	if model == "" {
+		model = registry.Defaults.Model.Value
+	}
+	estimate := csvbatch.NewEstimate(currentFlags.Pattern, prompts, currentFlags.MaxTokens,
+		calibrations[currentFlags.Pattern], model, prices)
+	fmt.Fprintln(os.Stderr, estimate)
+	if currentFlags.BatchPreview {
+		return
+	}
+	var confirmed bool
+	if confirmed, err = confirmBatch(estimate, currentFlags.BatchConfirmCost); err != nil || !confirmed {
+		if err == nil {
+			fmt.Fprintln(os.Stderr, "Batch cancelled")
+		}
+		return
+	}
+
+	var chatOptions *domain.ChatOptions
+	if chatOptions, err = currentFlags.BuildChatOptions(); err != nil {
+		return
+	}
+	// the rows run concurrently, their outputs can't share a stream file
+	chatOptions.StreamFile = ""
+
+	run := func(ctx context.Context, index int, row map[string]string) (output string, metadata *domain.ExecutionMetadata, err error) {
+		var chatter *core.Chatter
+		if chatter, err = registry.GetChatter(currentFlags.Model, currentFlags.ModelContextLength,
+			currentFlags.Vendor, currentFlags.Strategy, false, currentFlags.DryRun); err != nil {
+			return
+		}
+		variables, input := rowRequest(row)
+		request := &domain.ChatRequest{
+			Message:          &chat.ChatCompletionMessage{Role: chat.ChatMessageRoleUser, Content: input},
+			ContextName:      currentFlags.Context,
+			PatternName:      currentFlags.Pattern,
+			StrategyName:     currentFlags.Strategy,
+			PatternVariables: variables,
+			Language:         currentFlags.Language,
+			App:              "cli",
+		}
+		options := *chatOptions
+		var session *fsdb.Session
+		if session, err = chatter.SendContext(ctx, request, &options); err != nil {
+			return
+		}
+		output, metadata = session.GetLastMessage().Content, session.Metadata
+		fmt.Fprintf(os.Stderr, "Row %d done\n", index+1)
+		return
+	}
+
+	fmt.Fprintf(os.Stderr, "Running %s on %d rows of %s...\n", currentFlags.Pattern, len(table.Rows), currentFlags.BatchCSV)
+	startedAt := time.Now()
+	results := csvbatch.Run(context.Background(), table, currentFlags.BatchConcurrency, run)
+	finishedAt := time.Now()
+
+	var failed int
+	outputs := make([]string, len(results))
+	failures := make([]string, len(results))
+	for i, result := range results {
+		if result.Err != nil {
+			failed++
+			failures[i] = result.Err.Error()
+			fmt.Fprintf(os.Stderr, "Row %d failed: %v\n", i+1, result.Err)
+			continue
+		}
+		outputs[i] = result.Output
+		if currentFlags.BatchOutputDir != "" {
+			if err = os.MkdirAll(currentFlags.BatchOutputDir, os.ModePerm); err != nil {
+				return
+			}
+			path := csvbatch.RowFile(currentFlags.BatchOutputDir, i, len(results))
+			if err = os.WriteFile(path, []byte(result.Output), 0o644); err != nil {
+				return
+			}
+		}
+	}
+	if failed > 0 {
+		fmt.Fprintf(os.Stderr, "%d of %d rows failed\n", failed, len(results))
+	}
+	if calibrations != nil && !currentFlags.DryRun {
+		if actual, ok := calibrations.Learn(currentFlags.Pattern, estimate, results); ok {
+			fmt.Fprintf(os.Stderr, "Used %d prompt and %d completion tokens, estimated %d and %d for all the rows\n",
+				actual.Prompt, actual.Completion, estimate.PromptTokens, estimate.CompletionTokens)
+			if saveErr := calibrations.Save(registry.Db.Store, currentFlags.Pattern); saveErr != nil {
+				fmt.Fprintf(os.Stderr, "Warning: could not save the batch estimates: %v\n", saveErr)
+			}
+		}
+	}
+	if currentFlags.BatchManifest != "" {
+		if err = writeBatchManifest(currentFlags, prices, results, startedAt, finishedAt); err != nil {
+			return
+		}
+	}
+	if currentFlags.BatchOutputDir != "" {
+		fmt.Fprintf(os.Stderr, "Outputs written to %s\n", currentFlags.BatchOutputDir)
+		return
+	}
+
+	table.SetColumn(currentFlags.BatchOutputColumn, outputs)
+	if failed > 0 {
+		table.SetColumn(currentFlags.BatchOutputColumn+"_error", failures)
+	}
+	var out bytes.Buffer
+	if err = table.Write(&out); err != nil {
+		return
+	}
+	err = currentFlags.WriteOutput(strings.TrimSuffix(out.String(), "\n"))
+	return
+}
+
+// writeBatchManifest writes the JSON manifest of the results to --batch-manifest, the costs are priced
+// with the prices of the config directory, nil when they couldn't be read
+func writeBatchManifest(currentFlags *Flags, prices *pricing.Prices, results []csvbatch.RowResult,
+	startedAt time.Time, finishedAt time.Time) (err error) {
+
+	var outputPath func(index int) string
+	if currentFlags.BatchOutputDir != "" {
+		outputPath = func(index int) string {
+			return csvbatch.RowFile(currentFlags.BatchOutputDir, index, len(results))
+		}
+	}
+	manifest := csvbatch.NewManifest(results, outputPath, prices)
+	manifest.Source, manifest.Pattern = currentFlags.BatchCSV, currentFlags.Pattern
+	manifest.StartedAt, manifest.FinishedAt = startedAt, finishedAt
+	if manifest.Output = currentFlags.BatchOutputDir; manifest.Output == "" {
+		manifest.Output = currentFlags.Output
+	}
+	if err = manifest.Save(currentFlags.BatchManifest); err != nil {
+		err = fmt.Errorf("could not write the batch manifest: %v", err)
+		return
+	}
+	fmt.Fprintf(os.Stderr, "Manifest written to %s\n", currentFlags.BatchManifest)
+	return
+}
+
+// confirmBatch asks on the terminal whether to run the rows when the estimated cost is above maxCost, or
+// unknown. When the answer can't be typed, a batch above maxCost is refused and one of unknown cost runs.
+func confirmBatch(estimate *csvbatch.Estimate, maxCost float64) (ret bool, err error) {
+	if estimate.Cost != nil && *estimate.Cost <= maxCost {
+		return true, nil
+	}
+	if info, statErr := os.Stdin.Stat(); statErr != nil || info.Mode()&os.ModeCharDevice == 0 {
+		if estimate.Cost != nil {
+			err = fmt.Errorf("the estimated cost of the batch, $%.4f, is above --batch-confirm-cost $%.2f, run it from a terminal to confirm it or raise the limit",
+				*estimate.Cost, maxCost)
+			return
+		}
+		return true, nil
+	}
+	fmt.Fprintf(os.Stderr, "Run the %d rows? [y/N] ", estimate.Rows)
+	var answer string
+	fmt.Scanln(&answer)
+	answer = strings.ToLower(strings.TrimSpace(answer))
+	return answer == "y" || answer == "yes", nil
+}
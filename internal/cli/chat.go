@@ -11,13 +11,21 @@ import (
 	"github.com/danielmiessler/fabric/internal/domain"
 	"github.com/danielmiessler/fabric/internal/plugins/db/fsdb"
 	"github.com/danielmiessler/fabric/internal/tools/notifications"
+	"github.com/danielmiessler/fabric/internal/tools/speak"
 )
 
 // handleChatProcessing handles the main chat processing logic
 func handleChatProcessing(currentFlags *Flags, registry *core.PluginRegistry, messageTools string) (err error) {
+	var send bool
+	if send, err = runPrecheck(currentFlags); err != nil || !send {
+		return
+	}
 	if messageTools != "" {
 		currentFlags.AppendMessage(messageTools)
 	}
+	if err = runRedaction(currentFlags); err != nil {
+		return
+	}
 
 	var chatter *core.Chatter
 	if chatter, err = registry.GetChatter(currentFlags.Model, currentFlags.ModelContextLength,
@@ -72,12 +80,34 @@ func handleChatProcessing(currentFlags *Flags, registry *core.PluginRegistry, me
 		chatOptions.AudioFormat = "wav" // Default to WAV format
 	}
 
+	if chatter.StallTimeout, chatter.OnStall, err = newStallHandler(currentFlags, registry.Features()); err != nil {
+		return
+	}
+
+	// speak the completed sentences while the response is still streaming
+	var speaker *speak.Speaker
+	if currentFlags.Speak {
+		if speaker, err = newSpeaker(currentFlags, registry); err != nil {
+			return
+		}
+		chatter.StreamListener = speaker.Write
+	}
+
 	if session, err = chatter.Send(chatReq, chatOptions); err != nil {
+		if speaker != nil {
+			speaker.Close()
+		}
 		return
 	}
 
 	result := session.GetLastMessage().Content
 
+	if speaker != nil {
+		if speakErr := speaker.Close(); speakErr != nil {
+			fmt.Fprintf(os.Stderr, "Warning: %v\n", speakErr)
+		}
+	}
+
 	if !currentFlags.Stream || currentFlags.SuppressThink {
 		// For TTS models with audio output, show a user-friendly message instead of raw data
 		if isTTSModel && isAudioOutput && strings.HasPrefix(result, "FABRIC_AUDIO_DATA:") {
@@ -112,6 +142,16 @@ func handleChatProcessing(currentFlags *Flags, registry *core.PluginRegistry, me
 					// Fallback for any error messages or unexpected responses
 					err = CreateOutputFile(result, currentFlags.Output)
 				}
+			} else if currentFlags.ExportStyle != "" || currentFlags.ExportProvenance {
+				model := chatOptions.Model
+				if session.Metadata != nil {
+					model = session.Metadata.Model
+				}
+				var exported string
+				if exported, err = exportOutput(currentFlags, registry, model, result); err != nil {
+					return
+				}
+				err = CreateOutputFile(exported, currentFlags.Output)
 			} else {
 				err = CreateOutputFile(result, currentFlags.Output)
 			}
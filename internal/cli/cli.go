@@ -31,7 +31,13 @@ func Cli(version string) (err error) {
 	}
 
 	// Initialize database and registry
-	var registry, err2 = initializeFabric()
+	var registry *core.PluginRegistry
+	var err2 error
+	if currentFlags.Demo {
+		registry, err2 = initializeDemo()
+	} else {
+		registry, err2 = initializeFabric()
+	}
 	if err2 != nil {
 		if !currentFlags.Setup {
 			fmt.Fprintln(os.Stderr, err2.Error())
@@ -45,6 +51,7 @@ func Cli(version string) (err error) {
 
 	// Configure OpenAI Responses API setting based on CLI flag
 	if registry != nil {
+		registry.Version = version
 		configureOpenAIResponsesAPI(registry, currentFlags.DisableResponsesAPI)
 	}
 
@@ -74,6 +81,16 @@ func Cli(version string) (err error) {
 		return
 	}
 
+	// Handle the review of the changes of a repository
+	if handled, err = handleRepoReview(currentFlags, registry); err != nil || handled {
+		return
+	}
+
+	// Handle the runs of the pattern on the rows of a CSV file
+	if handled, err = handleCSVBatch(currentFlags, registry); err != nil || handled {
+		return
+	}
+
 	// Process HTML readability if needed
 	if currentFlags.HtmlReadability {
 		if msg, cleanErr := converter.HtmlReadability(currentFlags.Message); cleanErr != nil {
@@ -0,0 +1,72 @@
+package cli
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/danielmiessler/fabric/internal/chat"
+	"github.com/danielmiessler/fabric/internal/core"
+	"github.com/danielmiessler/fabric/internal/demo"
+	"github.com/danielmiessler/fabric/internal/domain"
+	demovendor "github.com/danielmiessler/fabric/internal/plugins/ai/demo"
+	"github.com/danielmiessler/fabric/internal/plugins/db/fsdb"
+)
+
+// demoSeedPattern is run on every canned input so the demo starts with a populated history
+const demoSeedPattern = "summarize"
+
+// initializeDemo sets up the demo config directory with the bundled patterns and canned inputs,
+// and a registry whose default vendor is the offline demo vendor. The directory is reused by the
+// next demos, the bundled files are written again and the history is only seeded while it is empty.
+func initializeDemo() (registry *core.PluginRegistry, err error) {
+	configDir := filepath.Join(os.TempDir(), "fabric-demo")
+	if err = demo.Setup(configDir); err != nil {
+		return
+	}
+
+	// the environment takes precedence over the .env file, don't let it pick another vendor
+	os.Setenv("DEFAULT_VENDOR", demovendor.VendorName)
+	os.Setenv("DEFAULT_MODEL", demovendor.ModelName)
+
+	fabricDb := fsdb.NewDb(configDir)
+	if err = fabricDb.Configure(); err != nil {
+		return
+	}
+	if registry, err = core.NewPluginRegistry(fabricDb); err != nil {
+		return
+	}
+	registry.VendorsAll.AddVendors(demovendor.NewClient())
+	registry.ConfigureVendors()
+
+	if err = seedDemoHistory(registry); err != nil {
+		return
+	}
+
+	fmt.Fprintf(os.Stderr, "Demo mode: patterns %s, canned inputs in %s\n",
+		strings.Join(demo.PatternNames(), ", "), filepath.Join(configDir, "inputs"))
+	return
+}
+
+func seedDemoHistory(registry *core.PluginRegistry) (err error) {
+	var runs []*fsdb.Run
+	if runs, err = registry.Db.History.FilterRuns(fsdb.RunFilter{Limit: 1}); err != nil || len(runs) > 0 {
+		return
+	}
+
+	var chatter *core.Chatter
+	if chatter, err = registry.GetChatter(demovendor.ModelName, 0, demovendor.VendorName, "", false, false); err != nil {
+		return
+	}
+	for _, input := range demo.Inputs() {
+		request := &domain.ChatRequest{
+			PatternName: demoSeedPattern,
+			Message:     &chat.ChatCompletionMessage{Role: chat.ChatMessageRoleUser, Content: input.Content},
+		}
+		if _, err = chatter.Send(request, &domain.ChatOptions{Model: demovendor.ModelName}); err != nil {
+			return fmt.Errorf("could not seed the demo history: %v", err)
+		}
+	}
+	return
+}
@@ -0,0 +1,32 @@
+package cli
+
+import (
+	"fmt"
+	"path/filepath"
+	"strings"
+
+	"github.com/danielmiessler/fabric/internal/core"
+	"github.com/danielmiessler/fabric/internal/plugins/db/fsdb"
+	"github.com/danielmiessler/fabric/internal/tools/export"
+)
+
+func newExportStyles(fabricDb *fsdb.Db) *export.Styles {
+	return export.NewStyles(fabricDb.FilePath(export.StylesDir))
+}
+
+// exportOutput applies the export style of the flags to the output, in the format of the output file,
+// with the provenance when requested
+func exportOutput(currentFlags *Flags, registry *core.PluginRegistry, model string, result string) (ret string, err error) {
+	if strings.EqualFold(filepath.Ext(currentFlags.Output), ".pdf") {
+		err = fmt.Errorf("PDF export is not supported on the command line, export to .html and print it to PDF, or use the web UI")
+		return
+	}
+	return newExportStyles(registry.Db).Render(currentFlags.ExportStyle, export.FormatOf(currentFlags.Output), export.Document{
+		Pattern: currentFlags.Pattern,
+		Model:   model,
+		Content: result,
+
+		Watermark: currentFlags.ExportProvenance,
+		Version:   registry.Version,
+	})
+}
@@ -13,6 +13,9 @@ import (
 
 	"github.com/danielmiessler/fabric/internal/chat"
 	"github.com/danielmiessler/fabric/internal/domain"
+	"github.com/danielmiessler/fabric/internal/tools/codebase"
+	"github.com/danielmiessler/fabric/internal/tools/textfile"
+	"github.com/danielmiessler/fabric/internal/tools/tokenizer"
 	"github.com/danielmiessler/fabric/internal/util"
 	"github.com/jessevdk/go-flags"
 	"golang.org/x/text/language"
@@ -41,33 +44,52 @@ type Flags struct {
 	ListAllSessions                 bool                 `short:"X" long:"listsessions" description:"List all sessions"`
 	UpdatePatterns                  bool                 `short:"U" long:"updatepatterns" description:"Update patterns"`
 	Message                         string               `hidden:"true" description:"Messages to send to chat"`
+	InputFiles                      []string             `long:"input-file" description:"Read the input from a text file (UTF-8, UTF-16 or Latin-1, binary files are rejected), a source file or a directory of sources, can be used multiple times"`
+	CodeChunkTokens                 int                  `long:"code-chunk-tokens" yaml:"codeChunkTokens" description:"Split source files of more tokens at their function and class boundaries, 0 to keep them whole" default:"2000"`
+	InputSeparator                  string               `long:"input-separator" yaml:"inputSeparator" description:"Separator between multiple input files, \\n and \\t are expanded (default: blank line)"`
+	InputHeader                     string               `long:"input-header" yaml:"inputHeader" description:"Header before each of multiple input files, {{path}} and {{name}} are replaced (default: ==> {{path}} <==)"`
 	Copy                            bool                 `short:"c" long:"copy" description:"Copy to clipboard"`
 	Model                           string               `short:"m" long:"model" yaml:"model" description:"Choose model"`
 	Vendor                          string               `short:"V" long:"vendor" yaml:"vendor" description:"Specify vendor for the selected model (e.g., -V \"LM Studio\" -m openai/gpt-oss-20b)"`
 	ModelContextLength              int                  `long:"modelContextLength" yaml:"modelContextLength" description:"Model context length (only affects ollama)"`
 	Output                          string               `short:"o" long:"output" description:"Output to file" default:""`
+	StreamFile                      string               `long:"stream-file" yaml:"streamFile" description:"Write the output to this file as it arrives, so a crash mid-run keeps the partial output"`
 	OutputSession                   bool                 `long:"output-session" description:"Output the entire session (also a temporary one) to the output file"`
 	LatestPatterns                  string               `short:"n" long:"latest" description:"Number of latest patterns to list" default:"0"`
 	ChangeDefaultModel              bool                 `short:"d" long:"changeDefaultModel" description:"Change default model"`
-	YouTube                         string               `short:"y" long:"youtube" description:"YouTube video or play list \"URL\" to grab transcript, comments from it and send to chat or print it put to the console and store it in the output file"`
+	YouTube                         string               `short:"y" long:"youtube" description:"YouTube video, play list or channel \"URL\" to grab transcript, comments from it and send to chat or print it put to the console and store it in the output file"`
 	YouTubePlaylist                 bool                 `long:"playlist" description:"Prefer playlist over video if both ids are present in the URL"`
 	YouTubeTranscript               bool                 `long:"transcript" description:"Grab transcript from YouTube video and send to chat (it is used per default)."`
 	YouTubeTranscriptWithTimestamps bool                 `long:"transcript-with-timestamps" description:"Grab transcript from YouTube video with timestamps and send to chat"`
 	YouTubeComments                 bool                 `long:"comments" description:"Grab comments from YouTube video and send to chat"`
 	YouTubeMetadata                 bool                 `long:"metadata" description:"Output video metadata"`
+	Podcast                         string               `long:"podcast" description:"Podcast RSS feed \"URL\" to list episodes from, select episodes to transcribe with --episode"`
+	PodcastEpisodes                 []int                `long:"episode" description:"Number of the podcast episode to transcribe, 1 is the latest (can be used multiple times)"`
+	Feed                            string               `long:"feed" description:"RSS or Atom feed \"URL\" to fetch the latest entries from as markdown and send to chat"`
+	FeedEntries                     int                  `long:"feed-entries" yaml:"feedEntries" description:"Number of the latest feed entries to fetch" default:"5"`
+	EmailFile                       string               `long:"email" description:"Parse the .eml or .mbox file and send the messages, without quoted history and signatures, to chat"`
+	EmailMessages                   int                  `long:"email-messages" yaml:"emailMessages" description:"Number of the latest messages of a .mbox file to send" default:"10"`
+	AudioFile                       string               `long:"audio" description:"Transcribe the audio file and send the transcript to chat"`
+	TranscribeVendor                string               `long:"transcribe-vendor" yaml:"transcribeVendor" description:"Vendor used for audio transcription, whisper.cpp to transcribe locally (default: first configured vendor that supports it)"`
+	TranscribeModel                 string               `long:"transcribe-model" yaml:"transcribeModel" description:"Model used for audio transcription, the model file with whisper.cpp (default: whisper-1)"`
 	YtDlpArgs                       string               `long:"yt-dlp-args" yaml:"ytDlpArgs" description:"Additional arguments to pass to yt-dlp (e.g. '--cookies-from-browser brave')"`
 	Language                        string               `short:"g" long:"language" description:"Specify the Language Code for the chat, e.g. -g=en -g=zh" default:""`
 	ScrapeURL                       string               `short:"u" long:"scrape_url" description:"Scrape website URL to markdown using Jina AI"`
 	ScrapeQuestion                  string               `short:"q" long:"scrape_question" description:"Search question using Jina AI"`
 	Seed                            int                  `short:"e" long:"seed" yaml:"seed" description:"Seed to be used for LMM generation"`
+	MaxTokens                       int                  `long:"max-tokens" yaml:"maxTokens" description:"Maximum number of tokens to generate (default: vendor default)"`
+	StopSequences                   []string             `long:"stop" yaml:"stop" description:"Stop generating when the model outputs this sequence (can be used multiple times)"`
 	WipeContext                     string               `short:"w" long:"wipecontext" description:"Wipe context"`
 	WipeSession                     string               `short:"W" long:"wipesession" description:"Wipe session"`
 	PrintContext                    string               `long:"printcontext" description:"Print context"`
+	Backup                          string               `long:"backup" description:"Back up the run history, settings, contexts, sessions, jobs and custom patterns into an archive in the given directory"`
+	Restore                         string               `long:"restore" description:"Restore a backup archive after verifying its integrity"`
 	PrintSession                    string               `long:"printsession" description:"Print session"`
 	HtmlReadability                 bool                 `long:"readability" description:"Convert HTML input into a clean, readable view"`
 	InputHasVars                    bool                 `long:"input-has-vars" description:"Apply variables to user input"`
 	DryRun                          bool                 `long:"dry-run" description:"Show what would be sent to the model without actually sending it"`
 	Serve                           bool                 `long:"serve" description:"Serve the Fabric Rest API"`
+	Demo                            bool                 `long:"demo" description:"Run with bundled demo patterns, canned inputs and an offline demo vendor, no setup or API keys needed"`
 	ServeOllama                     bool                 `long:"serveOllama" description:"Serve the Fabric Rest API with ollama endpoints"`
 	ServeAddress                    string               `long:"address" description:"The address to bind the REST API" default:":8080"`
 	ServeAPIKey                     string               `long:"api-key" description:"API key used to secure server routes" default:""`
@@ -93,9 +115,35 @@ type Flags struct {
 	DisableResponsesAPI             bool                 `long:"disable-responses-api" yaml:"disableResponsesAPI" description:"Disable OpenAI Responses API (default: false)"`
 	Voice                           string               `long:"voice" yaml:"voice" description:"TTS voice name for supported models (e.g., Kore, Charon, Puck)" default:"Kore"`
 	ListGeminiVoices                bool                 `long:"list-gemini-voices" description:"List all available Gemini TTS voices"`
+	ExportStyle                     string               `long:"export-style" yaml:"exportStyle" description:"Export style of the -o file (minimal, report, academic or a custom style), HTML for .html files, a Word document for .docx files, markdown otherwise"`
+	ExportProvenance                bool                 `long:"export-provenance" yaml:"exportProvenance" description:"Embed the fabric version, pattern, model and date in the -o file: meta tags and a footer in HTML, a footer in markdown"`
+	ListExportStyles                bool                 `long:"list-export-styles" description:"List the export styles"`
+	ListFeatures                    bool                 `long:"list-features" description:"List the feature flags of the experimental subsystems and whether they are enabled"`
+	Speak                           bool                 `long:"speak" yaml:"speak" description:"Read the output aloud sentence by sentence while it is generated (uses Gemini TTS)"`
+	SpeakModel                      string               `long:"speak-model" yaml:"speakModel" description:"Gemini TTS model used by --speak" default:"gemini-2.5-flash-preview-tts"`
 	Notification                    bool                 `long:"notification" yaml:"notification" description:"Send desktop notification when command completes"`
 	NotificationCommand             string               `long:"notification-command" yaml:"notificationCommand" description:"Custom command to run for notifications (overrides built-in notifications)"`
 	Thinking                        domain.ThinkingLevel `long:"thinking" yaml:"thinking" description:"Set reasoning/thinking level (e.g., off, low, medium, high, or numeric tokens for Anthropic)"`
+	ReviewRepo                      string               `long:"review-repo" description:"Review the changes of the git repository at this path file by file and report the findings by severity"`
+	ReviewRange                     string               `long:"review-range" description:"Commit range reviewed by --review-repo (e.g., main..HEAD)" default:"HEAD~1..HEAD"`
+	ReviewPattern                   string               `long:"review-pattern" yaml:"reviewPattern" description:"Pattern run on the diff of each file by --review-repo, it must output a JSON array of findings" default:"review_diff"`
+	ReviewConcurrency               int                  `long:"review-concurrency" yaml:"reviewConcurrency" description:"Number of files reviewed at the same time by --review-repo" default:"4"`
+	ReviewEditorURL                 string               `long:"review-editor-url" yaml:"reviewEditorURL" description:"Link opening a finding in your editor, {{path}} and {{line}} are replaced, empty for no links" default:"vscode://file{{path}}:{{line}}"`
+	BatchCSV                        string               `long:"batch-csv" description:"Run the pattern once per row of this CSV file, the columns fill the pattern variables, and output the CSV with the outputs in a new column"`
+	BatchInputColumn                string               `long:"batch-input-column" yaml:"batchInputColumn" description:"Column of --batch-csv sent as the input of each row, the input of the command is sent without it" default:"input"`
+	BatchOutputColumn               string               `long:"batch-output-column" yaml:"batchOutputColumn" description:"Column of --batch-csv the outputs are written to" default:"output"`
+	BatchOutputDir                  string               `long:"batch-output-dir" description:"Write the output of each row of --batch-csv to a file of this directory instead of a column"`
+	BatchConcurrency                int                  `long:"batch-concurrency" yaml:"batchConcurrency" description:"Number of rows of --batch-csv run at the same time" default:"4"`
+	BatchPreview                    bool                 `long:"batch-preview" description:"Show the prompt of the first row of --batch-csv without running the rows"`
+	BatchManifest                   string               `long:"batch-manifest" yaml:"batchManifest" description:"Write a JSON manifest of the results of --batch-csv to this file: status, output path, tokens and cost of each row"`
+	BatchConfirmCost                float64              `long:"batch-confirm-cost" yaml:"batchConfirmCost" description:"Ask before running --batch-csv when its estimated cost in USD is above this, or unknown" default:"1"`
+	Precheck                        string               `long:"precheck" yaml:"precheck" description:"Check the input of writing patterns for typos first: show (list the issues and stop) or append (ask the model to fix them)"`
+	Redact                          bool                 `long:"redact" yaml:"redact" description:"Redact secrets (API keys, AWS keys, private keys, emails) from the input before sending it, listing what was redacted"`
+	RedactRules                     []string             `long:"redact-rule" yaml:"redactRules" description:"Built-in rule used by --redact: email, aws-access-key, aws-secret-key, api-key or private-key, can be used multiple times, all by default"`
+	RedactPatterns                  []string             `long:"redact-pattern" yaml:"redactPatterns" description:"Custom regex redacted by --redact, can be used multiple times"`
+	NoRedact                        bool                 `long:"no-redact" description:"Send the input as is, overriding redact in the config file"`
+	StallTimeout                    int                  `long:"stall-timeout" yaml:"stallTimeout" description:"Seconds without tokens from the vendor before the request is considered stalled, 0 to wait for the vendor timeout" default:"90"`
+	OnStall                         string               `long:"on-stall" yaml:"onStall" description:"What to do with a stalled request: ask (on a terminal, wait otherwise), wait, reconnect or cancel" default:"ask"`
 }
 
 var debug = false
@@ -205,6 +253,24 @@ func Init() (ret *Flags, err error) {
 		ret.Message = AppendMessage(ret.Message, args[len(args)-1])
 	}
 
+	if len(ret.InputFiles) > 0 {
+		var files []textfile.File
+		if files, err = readInputFiles(ret.InputFiles, ret.CodeChunkTokens); err != nil {
+			err = fmt.Errorf("could not read input file: %v", err)
+			return
+		}
+		separator, header := ret.InputSeparator, ret.InputHeader
+		if separator == "" {
+			separator = textfile.DefaultSeparator
+		}
+		if header == "" {
+			header = textfile.DefaultHeader
+		}
+		fileMessage := textfile.Combine(files, separator, header)
+		ret.Message = AppendMessage(ret.Message, fileMessage)
+		reportInputSize(len(files), fileMessage, ret.Model, ret.ModelContextLength)
+	}
+
 	if pipedToStdin {
 		var pipedMessage string
 		if pipedMessage, err = readStdin(); err != nil {
@@ -439,6 +505,8 @@ func (o *Flags) BuildChatOptions() (ret *domain.ChatOptions, err error) {
 		FrequencyPenalty:    o.FrequencyPenalty,
 		Raw:                 o.Raw,
 		Seed:                o.Seed,
+		MaxTokens:           o.MaxTokens,
+		StopSequences:       o.StopSequences,
 		Thinking:            o.Thinking,
 		ModelContextLength:  o.ModelContextLength,
 		Search:              o.Search,
@@ -454,6 +522,7 @@ func (o *Flags) BuildChatOptions() (ret *domain.ChatOptions, err error) {
 		Voice:               o.Voice,
 		Notification:        o.Notification || o.NotificationCommand != "",
 		NotificationCommand: o.NotificationCommand,
+		StreamFile:          o.StreamFile,
 	}
 	return
 }
@@ -467,6 +536,7 @@ func (o *Flags) BuildChatRequest(Meta string) (ret *domain.ChatRequest, err erro
 		PatternVariables: o.PatternVariables,
 		InputHasVars:     o.InputHasVars,
 		Meta:             Meta,
+		App:              "cli",
 	}
 
 	var message *chat.ChatCompletionMessage
@@ -549,3 +619,41 @@ func AppendMessage(message string, newMessage string) (ret string) {
 	}
 	return
 }
+
+// readInputFiles reads the input files, source files and directories are prepared for code patterns by
+// codebase.Ingest
+func readInputFiles(paths []string, codeChunkTokens int) (ret []textfile.File, err error) {
+	for _, path := range paths {
+		var content string
+		if codebase.IsCode(path) {
+			content, err = codebase.Ingest(path, codeChunkTokens)
+		} else {
+			content, err = textfile.Read(path, textfile.DefaultMaxBytes)
+		}
+		if err != nil {
+			return
+		}
+		ret = append(ret, textfile.File{Path: path, Content: content})
+	}
+	return
+}
+
+// reportInputSize prints the token count of combined input files to stderr and warns when it exceeds the
+// model context length. The count is estimated unless tokenizers.yaml has a tokenizer for the model.
+func reportInputSize(fileCount int, content string, model string, contextLength int) {
+	tokens, approx := textfile.EstimateTokens(content), "~"
+	if homedir, err := os.UserHomeDir(); err == nil {
+		tokenizers, _ := tokenizer.Load(filepath.Join(homedir, ".config/fabric", tokenizer.FileName))
+		if count, ok, countErr := tokenizers.Count(model, content); countErr != nil {
+			fmt.Fprintf(os.Stderr, "Warning: %v\n", countErr)
+		} else if ok {
+			tokens, approx = count, ""
+		}
+	}
+	if fileCount > 1 {
+		fmt.Fprintf(os.Stderr, "Input: %d files, %d characters, %s%d tokens\n", fileCount, len(content), approx, tokens)
+	}
+	if contextLength > 0 && tokens > contextLength {
+		fmt.Fprintf(os.Stderr, "Warning: the input of %s%d tokens exceeds the model context length of %d\n", approx, tokens, contextLength)
+	}
+}
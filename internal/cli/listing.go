@@ -9,6 +9,7 @@ import (
 	"github.com/danielmiessler/fabric/internal/plugins/ai"
 	"github.com/danielmiessler/fabric/internal/plugins/ai/gemini"
 	"github.com/danielmiessler/fabric/internal/plugins/db/fsdb"
+	"github.com/danielmiessler/fabric/internal/tools/export"
 )
 
 // handleListingCommands handles listing-related commands
@@ -64,6 +65,38 @@ func handleListingCommands(currentFlags *Flags, fabricDb *fsdb.Db, registry *cor
 		return true, err
 	}
 
+	if currentFlags.ListExportStyles {
+		var names []string
+		if names, err = newExportStyles(fabricDb).Names(); err != nil {
+			return true, err
+		}
+		if !currentFlags.ShellCompleteOutput {
+			fmt.Printf("Export styles (custom templates in %s):\n\n", fabricDb.FilePath(export.StylesDir))
+		}
+		for _, name := range names {
+			fmt.Println(name)
+		}
+		return true, nil
+	}
+
+	if currentFlags.ListFeatures {
+		if !currentFlags.ShellCompleteOutput {
+			fmt.Printf("Features (set in %s):\n\n", fabricDb.FilePath(core.FeaturesFileName))
+		}
+		for _, feature := range core.Features {
+			state := "off"
+			if registry.Features().Enabled(feature.Name) {
+				state = "on"
+			}
+			if currentFlags.ShellCompleteOutput {
+				fmt.Println(feature.Name)
+			} else {
+				fmt.Printf("%-16s %-3s %s\n", feature.Name, state, feature.Description)
+			}
+		}
+		return true, nil
+	}
+
 	if currentFlags.ListGeminiVoices {
 		voicesList := gemini.ListGeminiVoices(currentFlags.ShellCompleteOutput)
 		fmt.Print(voicesList)
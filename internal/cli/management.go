@@ -1,6 +1,8 @@
 package cli
 
 import (
+	"fmt"
+
 	"github.com/danielmiessler/fabric/internal/plugins/db/fsdb"
 )
 
@@ -27,5 +29,20 @@ func handleManagementCommands(currentFlags *Flags, fabricDb *fsdb.Db) (handled b
 		return true, err
 	}
 
+	if currentFlags.Backup != "" {
+		var archivePath string
+		if archivePath, err = fabricDb.Backup(currentFlags.Backup); err == nil {
+			fmt.Printf("Backup written to %s\n", archivePath)
+		}
+		return true, err
+	}
+
+	if currentFlags.Restore != "" {
+		if _, err = fabricDb.Restore(currentFlags.Restore); err == nil {
+			fmt.Printf("Restored backup %s\n", currentFlags.Restore)
+		}
+		return true, err
+	}
+
 	return false, nil
 }
@@ -0,0 +1,35 @@
+package cli
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/danielmiessler/fabric/internal/tools/precheck"
+)
+
+// runPrecheck checks the input of a writing pattern for typos with --precheck. In show mode the issues are
+// printed and nothing is sent, in append mode the model is asked to fix them along the way.
+func runPrecheck(currentFlags *Flags) (send bool, err error) {
+	send = true
+	if currentFlags.Precheck == "" || currentFlags.Message == "" || !precheck.IsWritingPattern(currentFlags.Pattern) {
+		return
+	}
+
+	issues := precheck.Check(currentFlags.Message)
+	if len(issues) == 0 {
+		return
+	}
+	switch currentFlags.Precheck {
+	case precheck.ModeShow:
+		send = false
+		fmt.Fprintf(os.Stderr, "%d possible issues in the input, fix them or run without --precheck:\n", len(issues))
+		for _, issue := range issues {
+			fmt.Fprintf(os.Stderr, "  %s\n", issue)
+		}
+	case precheck.ModeAppend:
+		currentFlags.AppendMessage(precheck.Instructions(issues))
+	default:
+		err = fmt.Errorf("invalid --precheck mode %q, use %s or %s", currentFlags.Precheck, precheck.ModeShow, precheck.ModeAppend)
+	}
+	return
+}
@@ -0,0 +1,29 @@
+package cli
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/danielmiessler/fabric/internal/tools/redact"
+)
+
+// runRedaction scrubs the secrets of the input with --redact before it is sent, the redactions are listed
+// so the user sees what the model won't
+func runRedaction(currentFlags *Flags) (err error) {
+	if !currentFlags.Redact || currentFlags.NoRedact || currentFlags.Message == "" {
+		return
+	}
+
+	var redactor *redact.Redactor
+	if redactor, err = redact.New(currentFlags.RedactRules, currentFlags.RedactPatterns); err != nil {
+		return
+	}
+	var matches []redact.Match
+	if currentFlags.Message, matches = redactor.Redact(currentFlags.Message); len(matches) > 0 {
+		fmt.Fprintf(os.Stderr, "Redacted %d secrets from the input, use --no-redact to send them:\n", len(matches))
+		for _, match := range matches {
+			fmt.Fprintf(os.Stderr, "  %s\n", match)
+		}
+	}
+	return
+}
@@ -0,0 +1,84 @@
+package cli
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/danielmiessler/fabric/internal/chat"
+	"github.com/danielmiessler/fabric/internal/core"
+	"github.com/danielmiessler/fabric/internal/domain"
+	"github.com/danielmiessler/fabric/internal/plugins/db/fsdb"
+	"github.com/danielmiessler/fabric/internal/tools/codereview"
+)
+
+// handleRepoReview reviews the changes of --review-repo file by file with the review pattern and outputs
+// the findings grouped by severity, as JSON when the -o file ends in .json
+func handleRepoReview(currentFlags *Flags, registry *core.PluginRegistry) (handled bool, err error) {
+	if currentFlags.ReviewRepo == "" {
+		return
+	}
+	handled = true
+
+	var repo string
+	if repo, err = filepath.Abs(currentFlags.ReviewRepo); err != nil {
+		return
+	}
+	var files []codereview.FileDiff
+	if files, err = codereview.Diff(repo, currentFlags.ReviewRange); err != nil {
+		return
+	}
+	if len(files) == 0 {
+		fmt.Fprintf(os.Stderr, "No changed files to review in %s\n", currentFlags.ReviewRange)
+		return
+	}
+
+	var chatOptions *domain.ChatOptions
+	if chatOptions, err = currentFlags.BuildChatOptions(); err != nil {
+		return
+	}
+	// the files are reviewed concurrently, their outputs can't share a stream file
+	chatOptions.StreamFile = ""
+
+	review := func(ctx context.Context, file codereview.FileDiff) (output string, err error) {
+		var chatter *core.Chatter
+		if chatter, err = registry.GetChatter(currentFlags.Model, currentFlags.ModelContextLength,
+			currentFlags.Vendor, currentFlags.Strategy, false, currentFlags.DryRun); err != nil {
+			return
+		}
+		request := &domain.ChatRequest{
+			Message:          &chat.ChatCompletionMessage{Role: chat.ChatMessageRoleUser, Content: file.Patch},
+			PatternName:      currentFlags.ReviewPattern,
+			PatternVariables: currentFlags.PatternVariables,
+			Language:         currentFlags.Language,
+			App:              "cli",
+		}
+		options := *chatOptions
+		var session *fsdb.Session
+		if session, err = chatter.SendContext(ctx, request, &options); err != nil {
+			return
+		}
+		output = session.GetLastMessage().Content
+		return
+	}
+
+	fmt.Fprintf(os.Stderr, "Reviewing %d files of %s with %s...\n", len(files), currentFlags.ReviewRange, currentFlags.ReviewPattern)
+	results := codereview.Review(context.Background(), files, currentFlags.ReviewConcurrency, review)
+	report := codereview.NewReport(repo, currentFlags.ReviewRange, results)
+
+	var output string
+	if strings.HasSuffix(strings.ToLower(currentFlags.Output), ".json") {
+		var data []byte
+		if data, err = json.MarshalIndent(report, "", "  "); err != nil {
+			return
+		}
+		output = string(data)
+	} else {
+		output = report.Markdown(currentFlags.ReviewEditorURL)
+	}
+	err = currentFlags.WriteOutput(output)
+	return
+}
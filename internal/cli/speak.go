@@ -0,0 +1,50 @@
+package cli
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/danielmiessler/fabric/internal/chat"
+	"github.com/danielmiessler/fabric/internal/core"
+	"github.com/danielmiessler/fabric/internal/domain"
+	"github.com/danielmiessler/fabric/internal/plugins/ai/gemini"
+	"github.com/danielmiessler/fabric/internal/tools/speak"
+)
+
+// newSpeaker creates a speaker reading the output aloud with the Gemini TTS model of the flags
+func newSpeaker(currentFlags *Flags, registry *core.PluginRegistry) (ret *speak.Speaker, err error) {
+	vendor := registry.VendorManager.FindByName("Gemini")
+	if vendor == nil {
+		err = fmt.Errorf("--speak requires the Gemini vendor to be configured for text to speech")
+		return
+	}
+
+	var play speak.Player
+	if play, err = speak.NewWAVPlayer(); err != nil {
+		return
+	}
+
+	synthesize := func(text string) (audio []byte, err error) {
+		var result string
+		if result, err = vendor.Send(context.Background(),
+			[]*chat.ChatCompletionMessage{{Role: chat.ChatMessageRoleUser, Content: text}},
+			&domain.ChatOptions{
+				Model:       currentFlags.SpeakModel,
+				AudioOutput: true,
+				AudioFormat: "wav",
+				Voice:       currentFlags.Voice,
+			}); err != nil {
+			return
+		}
+		if !strings.HasPrefix(result, gemini.AudioDataPrefix) {
+			err = fmt.Errorf("no audio returned by %s", currentFlags.SpeakModel)
+			return
+		}
+		audio = []byte(result[len(gemini.AudioDataPrefix):])
+		return
+	}
+
+	ret = speak.NewSpeaker(synthesize, play)
+	return
+}
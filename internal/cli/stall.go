@@ -0,0 +1,59 @@
+package cli
+
+import (
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/danielmiessler/fabric/internal/core"
+)
+
+// newStallHandler returns the stall timeout and handler of --stall-timeout and --on-stall, no timeout
+// when the watchdog is disabled, by the flag or the stall_watchdog feature
+func newStallHandler(currentFlags *Flags, features core.FeatureFlags) (timeout time.Duration, handler func(core.StallEvent) core.StallAction, err error) {
+	if currentFlags.StallTimeout <= 0 || !features.Enabled(core.FeatureStallWatchdog) {
+		return
+	}
+	timeout = time.Duration(currentFlags.StallTimeout) * time.Second
+
+	// the stall is asked about when the answer can be typed, the input isn't piped then
+	if currentFlags.OnStall == "" || currentFlags.OnStall == "ask" {
+		if info, statErr := os.Stdin.Stat(); statErr == nil && info.Mode()&os.ModeCharDevice != 0 {
+			handler = askStall
+			return
+		}
+		currentFlags.OnStall = "wait"
+	}
+
+	var action core.StallAction
+	if action, err = core.ParseStallAction(currentFlags.OnStall); err != nil {
+		err = fmt.Errorf("invalid --on-stall %s, expected ask, wait, reconnect or cancel", currentFlags.OnStall)
+		return
+	}
+	handler = func(event core.StallEvent) core.StallAction {
+		fmt.Fprintf(os.Stderr, "\n%s, %s\n", core.DescribeStall(event), stallActionVerbs[action])
+		return action
+	}
+	return
+}
+
+var stallActionVerbs = map[core.StallAction]string{
+	core.StallWait:      "waiting",
+	core.StallReconnect: "reconnecting",
+	core.StallCancel:    "cancelling",
+}
+
+// askStall asks on the terminal whether to wait, reconnect or cancel, waiting without an answer
+func askStall(event core.StallEvent) core.StallAction {
+	fmt.Fprintf(os.Stderr, "\n%s. [w]ait, [r]econnect or [c]ancel? ", core.DescribeStall(event))
+	var answer string
+	fmt.Scanln(&answer)
+	switch strings.ToLower(strings.TrimSpace(answer)) {
+	case "r", "reconnect":
+		return core.StallReconnect
+	case "c", "cancel":
+		return core.StallCancel
+	}
+	return core.StallWait
+}
@@ -1,13 +1,21 @@
 package cli
 
 import (
+	"context"
 	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
 
 	"github.com/danielmiessler/fabric/internal/core"
+	"github.com/danielmiessler/fabric/internal/plugins/ai"
+	"github.com/danielmiessler/fabric/internal/tools/email"
+	"github.com/danielmiessler/fabric/internal/tools/feed"
+	"github.com/danielmiessler/fabric/internal/tools/podcast"
 	"github.com/danielmiessler/fabric/internal/tools/youtube"
 )
 
-// handleToolProcessing handles YouTube and web scraping tool processing
+// handleToolProcessing handles YouTube, podcast, feed, email and web scraping tool processing
 func handleToolProcessing(currentFlags *Flags, registry *core.PluginRegistry) (messageTools string, err error) {
 	if currentFlags.YouTube != "" {
 		if !registry.YouTube.IsConfigured() {
@@ -17,9 +25,15 @@ func handleToolProcessing(currentFlags *Flags, registry *core.PluginRegistry) (m
 
 		var videoId string
 		var playlistId string
-		if videoId, playlistId, err = registry.YouTube.GetVideoOrPlaylistId(currentFlags.YouTube); err != nil {
+		if registry.YouTube.IsChannelUrl(currentFlags.YouTube) {
+			if playlistId, err = registry.YouTube.FetchChannelUploadsPlaylistId(currentFlags.YouTube); err != nil {
+				return
+			}
+		} else if videoId, playlistId, err = registry.YouTube.GetVideoOrPlaylistId(currentFlags.YouTube); err != nil {
 			return
-		} else if (videoId == "" || currentFlags.YouTubePlaylist) && playlistId != "" {
+		}
+
+		if (videoId == "" || currentFlags.YouTubePlaylist) && playlistId != "" {
 			if currentFlags.Output != "" {
 				err = registry.YouTube.FetchAndSavePlaylist(playlistId, currentFlags.Output)
 			} else {
@@ -56,6 +70,63 @@ func handleToolProcessing(currentFlags *Flags, registry *core.PluginRegistry) (m
 		}
 	}
 
+	if currentFlags.Podcast != "" {
+		if messageTools, err = processPodcast(currentFlags, registry); err != nil {
+			return
+		}
+		if !currentFlags.IsChatRequest() {
+			err = currentFlags.WriteOutput(messageTools)
+			return
+		}
+	}
+
+	if currentFlags.Feed != "" {
+		var rss *feed.Feed
+		if rss, err = feed.NewClient().Fetch(currentFlags.Feed); err != nil {
+			return
+		}
+		messageTools = AppendMessage(messageTools, rss.Markdown(currentFlags.FeedEntries))
+
+		if !currentFlags.IsChatRequest() {
+			err = currentFlags.WriteOutput(messageTools)
+			return
+		}
+	}
+
+	if currentFlags.EmailFile != "" {
+		var data []byte
+		if data, err = os.ReadFile(currentFlags.EmailFile); err != nil {
+			return
+		}
+		var messages []*email.Message
+		if messages, err = email.Parse(currentFlags.EmailFile, data); err != nil {
+			return
+		}
+		messageTools = AppendMessage(messageTools, email.Markdown(messages, currentFlags.EmailMessages))
+
+		if !currentFlags.IsChatRequest() {
+			err = currentFlags.WriteOutput(messageTools)
+			return
+		}
+	}
+
+	if currentFlags.AudioFile != "" {
+		var transcribe podcast.TranscribeFunc
+		if transcribe, err = newTranscribeFunc(currentFlags, registry); err != nil {
+			return
+		}
+		var transcript string
+		if transcript, err = transcribe(currentFlags.AudioFile); err != nil {
+			return
+		}
+		messageTools = AppendMessage(messageTools, transcript)
+
+		if !currentFlags.IsChatRequest() {
+			err = currentFlags.WriteOutput(messageTools)
+			return
+		}
+	}
+
 	if currentFlags.ScrapeURL != "" || currentFlags.ScrapeQuestion != "" {
 		if !registry.Jina.IsConfigured() {
 			err = fmt.Errorf("scraping functionality is not configured. Please set up Jina to enable scraping")
@@ -88,3 +159,58 @@ func handleToolProcessing(currentFlags *Flags, registry *core.PluginRegistry) (m
 
 	return
 }
+
+// processPodcast lists the episodes of the podcast feed, or returns the transcripts of the selected episodes
+func processPodcast(currentFlags *Flags, registry *core.PluginRegistry) (message string, err error) {
+	pod := podcast.NewPodcast(filepath.Join(registry.Db.Dir, "podcasts"))
+
+	var episodes []*podcast.Episode
+	if episodes, err = pod.FetchEpisodes(currentFlags.Podcast); err != nil {
+		return
+	}
+
+	if len(currentFlags.PodcastEpisodes) == 0 {
+		if currentFlags.IsChatRequest() {
+			err = fmt.Errorf("select the podcast episodes to process with --episode, run without a pattern to list them")
+			return
+		}
+		var list strings.Builder
+		for _, episode := range episodes {
+			fmt.Fprintf(&list, "%d: %s (%s)\n", episode.Number, episode.Title, episode.Published)
+		}
+		message = strings.TrimSuffix(list.String(), "\n")
+		return
+	}
+
+	var transcribe podcast.TranscribeFunc
+	if transcribe, err = newTranscribeFunc(currentFlags, registry); err != nil {
+		return
+	}
+
+	for _, number := range currentFlags.PodcastEpisodes {
+		if number < 1 || number > len(episodes) {
+			err = fmt.Errorf("episode %d not found, the feed has %d episodes", number, len(episodes))
+			return
+		}
+		episode := episodes[number-1]
+
+		var transcript string
+		if transcript, err = pod.Transcript(episode, transcribe); err != nil {
+			return
+		}
+		message = AppendMessage(message, fmt.Sprintf("# %s\n\n%s", episode.Title, transcript))
+	}
+	return
+}
+
+// newTranscribeFunc transcribes audio files with the vendor and model selected by the flags
+func newTranscribeFunc(currentFlags *Flags, registry *core.PluginRegistry) (ret podcast.TranscribeFunc, err error) {
+	var transcriber ai.Transcriber
+	if transcriber, err = registry.GetTranscriber(currentFlags.TranscribeVendor); err != nil {
+		return
+	}
+	ret = func(audioPath string) (string, error) {
+		return transcriber.TranscribeFile(context.Background(), audioPath, currentFlags.TranscribeModel, currentFlags.Language)
+	}
+	return
+}
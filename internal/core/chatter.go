@@ -5,15 +5,19 @@ import (
 	"errors"
 	"fmt"
 	"os"
+	"runtime"
 	"strings"
+	"time"
 
 	"github.com/danielmiessler/fabric/internal/chat"
 
 	"github.com/danielmiessler/fabric/internal/domain"
 	"github.com/danielmiessler/fabric/internal/plugins/ai"
+	"github.com/danielmiessler/fabric/internal/plugins/ai/compat"
 	"github.com/danielmiessler/fabric/internal/plugins/db/fsdb"
 	"github.com/danielmiessler/fabric/internal/plugins/strategy"
 	"github.com/danielmiessler/fabric/internal/plugins/template"
+	"github.com/danielmiessler/fabric/internal/tools/autosave"
 )
 
 const NoSessionPatternUserMessages = "no session, pattern or user messages provided"
@@ -24,14 +28,44 @@ type Chatter struct {
 	Stream bool
 	DryRun bool
 
+	// StreamListener, if set, receives the text of the answer as it arrives; setting it streams the response
+	StreamListener func(chunk string)
+	// ChunkListener, if set, receives the chunks of the response as they arrive, the reasoning and tool
+	// calls included; setting it streams the response
+	ChunkListener func(chunk domain.StreamChunk)
+
+	// StallTimeout, if set with OnStall, is how long a vendor request may go without tokens before OnStall
+	// decides to keep waiting, reconnect or cancel
+	StallTimeout time.Duration
+	OnStall      func(StallEvent) StallAction
+
 	model              string
 	modelContextLength int
 	vendor             ai.Vendor
 	strategy           string
+
+	breaker   *ai.CircuitBreaker
+	fallbacks []fallbackVendor
+	hooks     []ExecutionHook
+	runDirs   *RunDirs
+	version   string // of fabric, recorded in the run environment
+	autosave  string // the path template the outputs are written to, see autosave.Path, empty to not write them
+}
+
+// fallbackVendor is a Vendor|model pair of the configured fallback chain
+type fallbackVendor struct {
+	vendor ai.Vendor
+	model  string
 }
 
 // Send processes a chat request and applies file changes for create_coding_feature pattern
 func (o *Chatter) Send(request *domain.ChatRequest, opts *domain.ChatOptions) (session *fsdb.Session, err error) {
+	return o.SendContext(context.Background(), request, opts)
+}
+
+// SendContext is Send with a context. Cancelling it aborts the vendor request, the cancelled
+// run is neither saved to the session nor recorded in the history.
+func (o *Chatter) SendContext(ctx context.Context, request *domain.ChatRequest, opts *domain.ChatOptions) (session *fsdb.Session, err error) {
 	modelToUse := opts.Model
 	if modelToUse == "" {
 		modelToUse = o.model
@@ -39,6 +73,15 @@ func (o *Chatter) Send(request *domain.ChatRequest, opts *domain.ChatOptions) (s
 	if o.vendor.NeedsRawMode(modelToUse) {
 		opts.Raw = true
 	}
+	if len(o.hooks) > 0 && o.runDirs != nil {
+		if request.RunDir, err = o.runDirs.Create(); err != nil {
+			return
+		}
+		defer o.runDirs.Release(request.RunDir)
+	}
+	if err = o.runBeforeHooks(request); err != nil {
+		return
+	}
 	if session, err = o.BuildSession(request, opts.Raw); err != nil {
 		return
 	}
@@ -63,45 +106,28 @@ func (o *Chatter) Send(request *domain.ChatRequest, opts *domain.ChatOptions) (s
 		opts.ModelContextLength = o.modelContextLength
 	}
 
-	message := ""
-
-	if o.Stream {
-		responseChan := make(chan string)
-		errChan := make(chan error, 1)
-		done := make(chan struct{})
-
-		go func() {
-			defer close(done)
-			if streamErr := o.vendor.SendStream(session.GetVendorMessages(), opts, responseChan); streamErr != nil {
-				errChan <- streamErr
-			}
-		}()
-
-		for response := range responseChan {
-			message += response
-			if !opts.SuppressThink {
-				fmt.Print(response)
-			}
-		}
-
-		// Wait for goroutine to finish
-		<-done
-
-		// Check for errors in errChan
-		select {
-		case streamErr := <-errChan:
-			if streamErr != nil {
-				err = streamErr
-				return
-			}
-		default:
-			// No errors, continue
-		}
-	} else {
-		if message, err = o.vendor.Send(context.Background(), session.GetVendorMessages(), opts); err != nil {
-			return
-		}
+	var message string
+	var metadata *domain.ExecutionMetadata
+	start := time.Now()
+	if message, metadata, err = o.sendWithFallback(ctx, session, opts); err != nil {
+		return
+	}
+	if err = ctx.Err(); err != nil {
+		return
+	}
+	metadata.LatencyMs = time.Since(start).Milliseconds()
+	metadata.Strategy = request.StrategyName
+	metadata.Seed = opts.Seed
+	metadata.Temperature = opts.Temperature
+	metadata.TopP = opts.TopP
+	metadata.PresencePenalty = opts.PresencePenalty
+	metadata.FrequencyPenalty = opts.FrequencyPenalty
+	metadata.MaxTokens = opts.MaxTokens
+	metadata.StopSequences = opts.StopSequences
+	if request.RunDir != "" && o.runDirs.Retention > 0 {
+		metadata.RunDir = request.RunDir
 	}
+	metadata.Environment = o.environment(session.Environment, metadata.Environment)
 
 	if opts.SuppressThink && !o.DryRun {
 		message = domain.StripThinkBlocks(message, opts.ThinkStartTag, opts.ThinkEndTag)
@@ -134,11 +160,291 @@ func (o *Chatter) Send(request *domain.ChatRequest, opts *domain.ChatOptions) (s
 		message = summary
 	}
 
+	for _, hook := range o.hooks {
+		if message, err = hook.After(request, message); err != nil {
+			err = fmt.Errorf("hook %s failed: %v", hook.Name(), err)
+			return
+		}
+	}
+
 	session.Append(&chat.ChatCompletionMessage{Role: chat.ChatMessageRoleAssistant, Content: message})
+	session.Metadata = metadata
 
 	if session.Name != "" {
 		err = o.db.Sessions.SaveSession(session)
 	}
+
+	o.recordRun(request, message, metadata)
+	o.autosaveOutput(request, message, metadata)
+	return
+}
+
+// autosaveOutput writes the output to the autosave path, if set. Failing to write it doesn't fail the run.
+func (o *Chatter) autosaveOutput(request *domain.ChatRequest, output string, metadata *domain.ExecutionMetadata) {
+	if o.DryRun || o.autosave == "" {
+		return
+	}
+	model := o.model
+	if metadata != nil && metadata.Model != "" {
+		model = metadata.Model
+	}
+	if _, err := autosave.Save(o.autosave, request.PatternName, model, output, time.Now()); err != nil {
+		fmt.Fprintf(os.Stderr, "Warning: %v\n", err)
+	}
+}
+
+// recordRun adds the run to the history. Failing to record it doesn't fail the run.
+func (o *Chatter) recordRun(request *domain.ChatRequest, output string, metadata *domain.ExecutionMetadata) {
+	if o.DryRun || o.db.History == nil {
+		return
+	}
+
+	run := &fsdb.Run{
+		JobName:      request.JobName,
+		PatternName:  request.PatternName,
+		ContextName:  request.ContextName,
+		SessionName:  request.SessionName,
+		StrategyName: request.StrategyName,
+		Variables:    request.PatternVariables,
+		Language:     request.Language,
+		Output:       output,
+		Metadata:     metadata,
+	}
+	if request.Message != nil {
+		run.Input = request.Message.Content
+	}
+	if err := o.db.History.SaveRun(run); err != nil {
+		fmt.Fprintf(os.Stderr, "Warning: failed to record run history: %v\n", err)
+	} else if metadata != nil {
+		// the clients star the run by it
+		metadata.RunId = run.Id
+	}
+}
+
+// runBeforeHooks lets the hooks transform the user input
+func (o *Chatter) runBeforeHooks(request *domain.ChatRequest) (err error) {
+	if len(o.hooks) == 0 || request.Message == nil {
+		return
+	}
+	message := *request.Message
+	for _, hook := range o.hooks {
+		if message.Content, err = hook.Before(request, message.Content); err != nil {
+			err = fmt.Errorf("hook %s failed: %v", hook.Name(), err)
+			return
+		}
+	}
+	request.Message = &message
+	return
+}
+
+// sendWithFallback sends the session to the chatter vendor while honoring the circuit breaker.
+// When the vendor is degraded or fails, the configured fallback chain is tried in order.
+func (o *Chatter) sendWithFallback(ctx context.Context, session *fsdb.Session, opts *domain.ChatOptions) (
+	message string, metadata *domain.ExecutionMetadata, err error) {
+	candidates := append([]fallbackVendor{{vendor: o.vendor, model: opts.Model}}, o.fallbacks...)
+	for i, candidate := range candidates {
+		if ctxErr := ctx.Err(); ctxErr != nil {
+			err = ctxErr
+			return
+		}
+		vendorName := candidate.vendor.GetName()
+		if o.breaker != nil && !o.breaker.Allow(vendorName) {
+			err = fmt.Errorf("vendor %s is degraded after repeated failures, skipping it", vendorName)
+			continue
+		}
+		if i > 0 {
+			fmt.Fprintf(os.Stderr, "Falling back to %s|%s\n", vendorName, candidate.model)
+		}
+
+		candidateOpts := *opts
+		candidateOpts.Model = candidate.model
+		var usage *domain.Usage
+		if message, usage, err = o.sendToVendor(ctx, candidate.vendor, session, &candidateOpts); err == nil {
+			if o.breaker != nil {
+				o.breaker.RecordSuccess(vendorName)
+			}
+			opts.Model = candidate.model
+			metadata = &domain.ExecutionMetadata{Model: candidate.model, Vendor: vendorName,
+				Environment: &domain.Environment{VendorEndpoint: vendorEndpoint(candidate.vendor)}}
+			if usage != nil {
+				metadata.Usage = *usage
+			}
+			return
+		}
+
+		// a cancelled request is not a vendor failure
+		if ctx.Err() != nil || errors.Is(err, ErrStallCancelled) {
+			return
+		}
+		if o.breaker != nil && o.breaker.RecordFailure(vendorName, err) {
+			fmt.Fprintf(os.Stderr, "Vendor %s failed repeatedly and is marked as degraded\n", vendorName)
+		}
+		// a partially streamed answer can't be continued by another vendor
+		if message != "" {
+			return
+		}
+	}
+	return
+}
+
+// environment completes the environment the session was built with with the one of the vendor call
+func (o *Chatter) environment(built *domain.Environment, vendor *domain.Environment) (ret *domain.Environment) {
+	ret = &domain.Environment{}
+	if built != nil {
+		*ret = *built
+	}
+	if vendor != nil {
+		ret.VendorEndpoint = vendor.VendorEndpoint
+	}
+	ret.FabricVersion = o.version
+	ret.Platform = runtime.GOOS + "/" + runtime.GOARCH
+	return
+}
+
+// vendorEndpoint is the API base URL of vendors implementing ai.EndpointReporter
+func vendorEndpoint(vendor ai.Vendor) string {
+	if reporter, ok := vendor.(ai.EndpointReporter); ok {
+		return reporter.Endpoint()
+	}
+	return ""
+}
+
+// sendToVendor sends the session to the vendor, watched for stalls when a stall timeout is set. Usage is only
+// returned for non-streamed responses of vendors implementing ai.UsageReporter.
+func (o *Chatter) sendToVendor(ctx context.Context, vendor ai.Vendor, session *fsdb.Session, opts *domain.ChatOptions) (
+	message string, usage *domain.Usage, err error) {
+	if o.StallTimeout <= 0 || o.OnStall == nil {
+		return o.sendAttempt(ctx, vendor, session, opts, nil)
+	}
+	for {
+		attemptCtx, cancel := context.WithCancel(ctx)
+		dog := &watchdog{
+			timeout:  o.StallTimeout,
+			endpoint: vendorEndpoint(vendor),
+			event:    StallEvent{Vendor: vendor.GetName(), Model: opts.Model},
+			onStall:  o.OnStall,
+			cancel:   cancel,
+		}
+		go dog.run(attemptCtx)
+		message, usage, err = o.sendAttempt(attemptCtx, vendor, session, opts, dog)
+		cancel()
+
+		switch dog.Action() {
+		case StallReconnect:
+			if o.Stream {
+				fmt.Fprintf(os.Stderr, "\nReconnecting to %s, the answer starts over\n", vendor.GetName())
+			}
+			continue
+		case StallCancel:
+			err = ErrStallCancelled
+		}
+		return
+	}
+}
+
+// sendAttempt sends the session to the vendor once, the watchdog, if set, is touched by every token
+func (o *Chatter) sendAttempt(ctx context.Context, vendor ai.Vendor, session *fsdb.Session, opts *domain.ChatOptions,
+	dog *watchdog) (message string, usage *domain.Usage, err error) {
+	if o.Stream || opts.StreamFile != "" || o.StreamListener != nil || o.ChunkListener != nil {
+		// the stream file is written unbuffered, so the output received so far survives a crash
+		var streamFile *os.File
+		if opts.StreamFile != "" {
+			if streamFile, err = os.Create(opts.StreamFile); err != nil {
+				err = fmt.Errorf("could not create stream file %s: %v", opts.StreamFile, err)
+				return
+			}
+			defer streamFile.Close()
+		}
+
+		chunks := make(chan domain.StreamChunk)
+		go compat.Stream(vendor, session.GetVendorMessages(), opts, chunks)
+
+		// the reasoning is kept in the message between the think tags, as the vendors streaming plain text
+		// write it, unless it is suppressed
+		startTag, endTag := compat.ThinkTags(opts)
+		reasoning, skipSpace := false, false
+		write := func(text string) {
+			message += text
+			if o.Stream && !opts.SuppressThink {
+				fmt.Print(text)
+			}
+			if streamFile != nil {
+				if _, writeErr := streamFile.WriteString(text); writeErr != nil {
+					fmt.Fprintf(os.Stderr, "Warning: failed to write stream file, no longer writing it: %v\n", writeErr)
+					streamFile = nil
+				}
+			}
+		}
+
+	receive:
+		for {
+			var chunk domain.StreamChunk
+			var ok bool
+			select {
+			case chunk, ok = <-chunks:
+				if !ok {
+					break receive
+				}
+			case <-ctx.Done():
+				// the vendor streams can't be cancelled, the rest of the abandoned stream is dropped
+				go func() {
+					for range chunks {
+					}
+				}()
+				err = ctx.Err()
+				return
+			}
+			if dog != nil {
+				dog.Touch()
+			}
+			if o.ChunkListener != nil {
+				o.ChunkListener(chunk)
+			}
+			switch chunk.Type {
+			case domain.StreamText:
+				text := chunk.Text
+				if reasoning {
+					write(endTag)
+					reasoning = false
+				}
+				if skipSpace {
+					// the whitespace after suppressed reasoning goes with it, as with StripThinkBlocks
+					if text = strings.TrimLeft(text, " \t\r\n"); text == "" {
+						continue
+					}
+					skipSpace = false
+				}
+				write(text)
+				if o.StreamListener != nil {
+					o.StreamListener(text)
+				}
+			case domain.StreamReasoning:
+				if opts.SuppressThink {
+					skipSpace = true
+					continue
+				}
+				if !reasoning {
+					write(startTag)
+					reasoning = true
+				}
+				write(chunk.Text)
+			case domain.StreamDone:
+				usage = chunk.Usage
+			case domain.StreamError:
+				err = chunk.Err
+			}
+		}
+		if reasoning {
+			write(endTag)
+		}
+		if err != nil {
+			return
+		}
+	} else if reporter, ok := vendor.(ai.UsageReporter); ok {
+		message, usage, err = reporter.SendWithUsage(ctx, session.GetVendorMessages(), opts)
+	} else {
+		message, err = vendor.Send(ctx, session.GetVendorMessages(), opts)
+	}
 	return
 }
 
@@ -153,6 +459,7 @@ func (o *Chatter) BuildSession(request *domain.ChatRequest, raw bool) (session *
 	} else {
 		session = &fsdb.Session{}
 	}
+	session.Environment = &domain.Environment{App: request.App}
 
 	if request.Meta != "" {
 		session.Append(&chat.ChatCompletionMessage{Role: domain.ChatMessageRoleMeta, Content: request.Meta})
@@ -167,6 +474,7 @@ func (o *Chatter) BuildSession(request *domain.ChatRequest, raw bool) (session *
 			return
 		}
 		contextContent = ctx.Content
+		session.Environment.ContextHash = domain.ContentHash(contextContent)
 	}
 
 	// Process template variables in message content
@@ -196,6 +504,7 @@ func (o *Chatter) BuildSession(request *domain.ChatRequest, raw bool) (session *
 			return nil, fmt.Errorf("could not get pattern %s: %v", request.PatternName, err)
 		}
 		patternContent = pattern.Pattern
+		session.Environment.PatternHash = pattern.Hash
 		inputUsed = true
 	}
 
@@ -206,6 +515,9 @@ func (o *Chatter) BuildSession(request *domain.ChatRequest, raw bool) (session *
 		if err != nil {
 			return nil, fmt.Errorf("could not load strategy %s: %v", request.StrategyName, err)
 		}
+		if strategy != nil {
+			session.Environment.StrategyHash = strategy.Hash
+		}
 		if strategy != nil && strategy.Prompt != "" {
 			// prepend the strategy prompt to the system message
 			systemMessage = fmt.Sprintf("%s\n%s", strategy.Prompt, systemMessage)
@@ -4,21 +4,30 @@ import (
 	"bytes"
 	"context"
 	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
 	"testing"
+	"time"
 
 	"github.com/danielmiessler/fabric/internal/chat"
 	"github.com/danielmiessler/fabric/internal/domain"
+	"github.com/danielmiessler/fabric/internal/plugins/ai"
 	"github.com/danielmiessler/fabric/internal/plugins/db/fsdb"
 )
 
 // mockVendor implements the ai.Vendor interface for testing
 type mockVendor struct {
+	name            string
 	sendStreamError error
 	streamChunks    []string
 	sendFunc        func(context.Context, []*chat.ChatCompletionMessage, *domain.ChatOptions) (string, error)
 }
 
 func (m *mockVendor) GetName() string {
+	if m.name != "" {
+		return m.name
+	}
 	return "mock"
 }
 
@@ -228,3 +237,219 @@ func TestChatter_Send_StreamingSuccessfulAggregation(t *testing.T) {
 		t.Errorf("Expected aggregated message %q, got %q", expectedMessage, assistantMessage.Content)
 	}
 }
+
+func TestChatter_Send_StreamsToFile(t *testing.T) {
+	tempDir := t.TempDir()
+	streamFile := filepath.Join(tempDir, "output.md")
+
+	chatter := &Chatter{
+		db:     fsdb.NewDb(tempDir),
+		vendor: &mockVendor{streamChunks: []string{"partial", " output"}, sendStreamError: errors.New("connection lost")},
+		model:  "test-model",
+	}
+	request := &domain.ChatRequest{
+		Message: &chat.ChatCompletionMessage{Role: chat.ChatMessageRoleUser, Content: "test message"},
+	}
+
+	// the run fails, but the output received before the failure is kept
+	if _, err := chatter.Send(request, &domain.ChatOptions{Model: "test-model", StreamFile: streamFile}); err == nil {
+		t.Fatal("expected the stream error")
+	}
+	content, err := os.ReadFile(streamFile)
+	if err != nil {
+		t.Fatalf("expected the stream file to be written: %v", err)
+	}
+	if string(content) != "partial output" {
+		t.Errorf("expected the partial output in the stream file, got %q", content)
+	}
+}
+
+func TestChatter_Send_FallsBackWhenVendorFails(t *testing.T) {
+	db := fsdb.NewDb(t.TempDir())
+
+	failing := &mockVendor{sendFunc: func(context.Context, []*chat.ChatCompletionMessage, *domain.ChatOptions) (string, error) {
+		return "", errors.New("outage")
+	}}
+	var fallbackModel string
+	fallback := &mockVendor{name: "fallback", sendFunc: func(_ context.Context, _ []*chat.ChatCompletionMessage, o *domain.ChatOptions) (string, error) {
+		fallbackModel = o.Model
+		return "fallback response", nil
+	}}
+
+	breaker := ai.NewCircuitBreaker(1, time.Minute, time.Minute)
+	chatter := &Chatter{
+		db:        db,
+		vendor:    failing,
+		model:     "test-model",
+		breaker:   breaker,
+		fallbacks: []fallbackVendor{{vendor: fallback, model: "fallback-model"}},
+	}
+
+	request := &domain.ChatRequest{
+		Message: &chat.ChatCompletionMessage{Role: chat.ChatMessageRoleUser, Content: "test"},
+	}
+	opts := &domain.ChatOptions{Model: "test-model"}
+
+	session, err := chatter.Send(request, opts)
+	if err != nil {
+		t.Fatalf("Send returned error: %v", err)
+	}
+	if session.GetLastMessage().Content != "fallback response" {
+		t.Errorf("expected fallback response, got %q", session.GetLastMessage().Content)
+	}
+	if fallbackModel != "fallback-model" || opts.Model != "fallback-model" {
+		t.Errorf("expected fallback model to be used, got %q / %q", fallbackModel, opts.Model)
+	}
+	if breaker.Allow("mock") {
+		t.Error("expected failing vendor to be marked as degraded")
+	}
+}
+
+// usageMockVendor reports token usage like vendors implementing ai.UsageReporter
+type usageMockVendor struct {
+	mockVendor
+}
+
+func (m *usageMockVendor) SendWithUsage(context.Context, []*chat.ChatCompletionMessage, *domain.ChatOptions) (string, *domain.Usage, error) {
+	return "usage response", &domain.Usage{PromptTokens: 12, CompletionTokens: 34, FinishReason: "stop"}, nil
+}
+
+func TestChatter_Send_RecordsExecutionMetadata(t *testing.T) {
+	chatter := &Chatter{
+		db:     fsdb.NewDb(t.TempDir()),
+		vendor: &usageMockVendor{},
+		model:  "test-model",
+	}
+
+	request := &domain.ChatRequest{
+		Message: &chat.ChatCompletionMessage{Role: chat.ChatMessageRoleUser, Content: "test"},
+	}
+	session, err := chatter.Send(request, &domain.ChatOptions{})
+	if err != nil {
+		t.Fatalf("Send returned error: %v", err)
+	}
+
+	metadata := session.Metadata
+	if metadata == nil {
+		t.Fatal("expected execution metadata")
+	}
+	if metadata.Model != "test-model" || metadata.Vendor != "mock" {
+		t.Errorf("unexpected model/vendor: %q/%q", metadata.Model, metadata.Vendor)
+	}
+	if metadata.PromptTokens != 12 || metadata.CompletionTokens != 34 || metadata.FinishReason != "stop" {
+		t.Errorf("unexpected usage: %+v", metadata.Usage)
+	}
+}
+
+// endpointMockVendor reports its API URL like vendors implementing ai.EndpointReporter
+type endpointMockVendor struct {
+	mockVendor
+}
+
+func (m *endpointMockVendor) Endpoint() string {
+	return "http://localhost:11434"
+}
+
+func TestChatter_Send_RecordsEnvironment(t *testing.T) {
+	db := fsdb.NewDb(t.TempDir())
+	patternDir := filepath.Join(db.Patterns.Dir, "summarize")
+	if err := os.MkdirAll(patternDir, 0755); err != nil {
+		t.Fatal(err)
+	}
+	patternContent := "Summarize the input {{input}}"
+	if err := os.WriteFile(filepath.Join(patternDir, "system.md"), []byte(patternContent), 0644); err != nil {
+		t.Fatal(err)
+	}
+	chatter := &Chatter{
+		db:      db,
+		vendor:  &endpointMockVendor{},
+		model:   "test-model",
+		version: "v1.2.3",
+	}
+
+	request := &domain.ChatRequest{
+		Message:     &chat.ChatCompletionMessage{Role: chat.ChatMessageRoleUser, Content: "test"},
+		PatternName: "summarize",
+		App:         "web 1.0.0",
+	}
+	session, err := chatter.Send(request, &domain.ChatOptions{})
+	if err != nil {
+		t.Fatalf("Send returned error: %v", err)
+	}
+
+	environment := session.Metadata.Environment
+	if environment == nil {
+		t.Fatal("expected the environment in the metadata")
+	}
+	if environment.FabricVersion != "v1.2.3" || environment.App != "web 1.0.0" || environment.Platform == "" {
+		t.Errorf("unexpected environment: %+v", environment)
+	}
+	if environment.VendorEndpoint != "http://localhost:11434" {
+		t.Errorf("unexpected vendor endpoint %q", environment.VendorEndpoint)
+	}
+	if environment.PatternHash != domain.ContentHash(patternContent) || environment.StrategyHash != "" || environment.ContextHash != "" {
+		t.Errorf("unexpected hashes: %+v", environment)
+	}
+}
+
+func TestChatter_Send_StreamListener(t *testing.T) {
+	var chunks []string
+	chatter := &Chatter{
+		db:             fsdb.NewDb(t.TempDir()),
+		vendor:         &mockVendor{streamChunks: []string{"Hello.", " World."}},
+		model:          "test-model",
+		StreamListener: func(chunk string) { chunks = append(chunks, chunk) },
+	}
+	request := &domain.ChatRequest{
+		Message: &chat.ChatCompletionMessage{Role: chat.ChatMessageRoleUser, Content: "test message"},
+	}
+
+	// the listener streams the response even without the stream flag
+	session, err := chatter.Send(request, &domain.ChatOptions{Model: "test-model"})
+	if err != nil {
+		t.Fatalf("Send returned error: %v", err)
+	}
+	if len(chunks) != 2 || chunks[0] != "Hello." || chunks[1] != " World." {
+		t.Errorf("unexpected chunks %q", chunks)
+	}
+	if session.GetLastMessage().Content != "Hello. World." {
+		t.Errorf("unexpected message %q", session.GetLastMessage().Content)
+	}
+}
+
+func TestChatter_Send_StreamsChunks(t *testing.T) {
+	var chunks []domain.StreamChunk
+	chatter := &Chatter{
+		db:            fsdb.NewDb(t.TempDir()),
+		vendor:        &mockVendor{streamChunks: []string{"<think>let me ", "see</think>", "\n\nThe answer"}},
+		model:         "test-model",
+		ChunkListener: func(chunk domain.StreamChunk) { chunks = append(chunks, chunk) },
+	}
+	request := &domain.ChatRequest{
+		Message: &chat.ChatCompletionMessage{Role: chat.ChatMessageRoleUser, Content: "test message"},
+	}
+
+	session, err := chatter.Send(request, &domain.ChatOptions{Model: "test-model"})
+	if err != nil {
+		t.Fatalf("Send returned error: %v", err)
+	}
+	if content := session.GetLastMessage().Content; content != "<think>let me see</think>\n\nThe answer" {
+		t.Errorf("expected the reasoning to be kept in the message, got %q", content)
+	}
+	var types []domain.StreamChunkType
+	for _, chunk := range chunks {
+		types = append(types, chunk.Type)
+	}
+	expected := []domain.StreamChunkType{domain.StreamReasoning, domain.StreamReasoning, domain.StreamText, domain.StreamDone}
+	if fmt.Sprint(types) != fmt.Sprint(expected) {
+		t.Errorf("expected chunks %v, got %v", expected, types)
+	}
+
+	session, err = chatter.Send(request, &domain.ChatOptions{Model: "test-model", SuppressThink: true})
+	if err != nil {
+		t.Fatalf("Send returned error: %v", err)
+	}
+	if content := session.GetLastMessage().Content; content != "The answer" {
+		t.Errorf("expected the reasoning to be suppressed, got %q", content)
+	}
+}
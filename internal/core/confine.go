@@ -0,0 +1,50 @@
+package core
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"runtime"
+
+	"github.com/danielmiessler/fabric/internal/domain"
+)
+
+// confineCommand is the bubblewrap binary confining the hook commands
+var confineCommand = "bwrap"
+
+// checkConfinement fails when the hook commands can't be confined on this system
+func checkConfinement() (err error) {
+	if runtime.GOOS != "linux" {
+		err = fmt.Errorf("hook commands are confined with bubblewrap, which runs on Linux only; set unconfined: true under run_dirs in %s to run them as you", HooksFileName)
+		return
+	}
+	if _, err = exec.LookPath(confineCommand); err != nil {
+		err = fmt.Errorf("hook commands are confined with bubblewrap, install it (%s not found) or set unconfined: true under run_dirs in %s to run them as you", confineCommand, HooksFileName)
+	}
+	return
+}
+
+// confinedHookCommand runs the shell command under bubblewrap. The file system is read-only but for the
+// run directory, the home directory and /tmp are empty and there is no network. The environment only has
+// PATH, LANG, HOME and the variables of the run, the API keys of the .env are not passed.
+func confinedHookCommand(command string, request *domain.ChatRequest) *exec.Cmd {
+	args := []string{"--die-with-parent", "--new-session", "--unshare-all",
+		"--ro-bind", "/", "/", "--dev", "/dev", "--proc", "/proc", "--tmpfs", "/tmp"}
+	env := []string{"PATH=" + os.Getenv("PATH"), "LANG=" + os.Getenv("LANG"), "FABRIC_PATTERN=" + request.PatternName}
+	if home, err := os.UserHomeDir(); err == nil {
+		args = append(args, "--tmpfs", home)
+		env = append(env, "HOME="+home)
+	}
+	dir := "/"
+	if runDir := request.RunDir; runDir != "" {
+		// bound after the empty /tmp and home directory, which may contain it
+		args = append(args, "--bind", runDir, runDir)
+		env = append(env, "FABRIC_RUN_DIR="+runDir, "TMPDIR="+runDir)
+		dir = runDir
+	}
+	args = append(args, "--chdir", dir, "--", "sh", "-c", command)
+
+	cmd := exec.Command(confineCommand, args...)
+	cmd.Env = env
+	return cmd
+}
@@ -0,0 +1,112 @@
+package core
+
+import (
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// FeaturesFileName is the feature flags file in the fabric config directory
+//
+//	hooks: true
+//	scheduler: true
+const FeaturesFileName = "features.yaml"
+
+// Names of the feature flags
+const (
+	FeatureHooks         = "hooks"
+	FeatureExtensions    = "extensions"
+	FeatureScheduler     = "scheduler"
+	FeatureWatcher       = "watcher"
+	FeatureStallWatchdog = "stall_watchdog"
+)
+
+// Feature is a subsystem gated by a feature flag. New experimental subsystems ship disabled by default, so
+// they can be tried before they are turned on for everyone, and any of them can be disabled quickly when it
+// misbehaves.
+type Feature struct {
+	Name        string `json:"name"`
+	Description string `json:"description"`
+	Default     bool   `json:"default"`
+	Restart     bool   `json:"restart"` // a running fabric --serve applies the change on restart only
+}
+
+// Features are the subsystems gated by feature flags
+var Features = []Feature{
+	{FeatureHooks, "Pre and post execution hooks of hooks.yaml, including their shell commands", false, false},
+	{FeatureExtensions, "Template extensions, {{ext:name:operation}} in patterns", true, false},
+	{FeatureScheduler, "Scheduled jobs of fabric --serve", false, true},
+	{FeatureWatcher, "Watched folders of fabric --serve", false, true},
+	{FeatureStallWatchdog, "Detection of stalled vendor requests", true, false},
+}
+
+// FeatureFlags are the flags set in the features file, the unset ones have their default
+type FeatureFlags map[string]bool
+
+// Enabled tells whether the feature is enabled, unknown features are disabled
+func (o FeatureFlags) Enabled(name string) bool {
+	if enabled, ok := o[name]; ok {
+		return enabled
+	}
+	for _, feature := range Features {
+		if feature.Name == name {
+			return feature.Default
+		}
+	}
+	return false
+}
+
+// Validate rejects the flags of unknown features, likely typos that would leave a feature on
+func (o FeatureFlags) Validate() (err error) {
+	var unknown []string
+	for name := range o {
+		known := false
+		for _, feature := range Features {
+			known = known || feature.Name == name
+		}
+		if !known {
+			unknown = append(unknown, name)
+		}
+	}
+	if len(unknown) > 0 {
+		sort.Strings(unknown)
+		names := make([]string, len(Features))
+		for i, feature := range Features {
+			names[i] = feature.Name
+		}
+		err = fmt.Errorf("unknown features %s, available features: %s", strings.Join(unknown, ", "), strings.Join(names, ", "))
+	}
+	return
+}
+
+// LoadFeatureFlags reads the features file. A missing file means the defaults.
+func LoadFeatureFlags(path string) (ret FeatureFlags, err error) {
+	ret = FeatureFlags{}
+	var data []byte
+	if data, err = os.ReadFile(path); err != nil {
+		if os.IsNotExist(err) {
+			err = nil
+		}
+		return
+	}
+	if err = yaml.Unmarshal(data, &ret); err != nil {
+		err = fmt.Errorf("invalid features file %s: %v", path, err)
+		return
+	}
+	if err = ret.Validate(); err != nil {
+		err = fmt.Errorf("invalid features file %s: %v", path, err)
+	}
+	return
+}
+
+// SaveFeatureFlags writes the features file
+func SaveFeatureFlags(path string, flags FeatureFlags) (err error) {
+	var data []byte
+	if data, err = yaml.Marshal(flags); err != nil {
+		return
+	}
+	return os.WriteFile(path, data, 0644)
+}
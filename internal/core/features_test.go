@@ -0,0 +1,93 @@
+package core
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/danielmiessler/fabric/internal/plugins/db/fsdb"
+)
+
+func TestLoadFeatureFlags(t *testing.T) {
+	path := filepath.Join(t.TempDir(), FeaturesFileName)
+
+	flags, err := LoadFeatureFlags(path)
+	if err != nil || flags.Enabled(FeatureHooks) || flags.Enabled(FeatureScheduler) || flags.Enabled(FeatureWatcher) ||
+		!flags.Enabled(FeatureExtensions) || flags.Enabled("rag") {
+		t.Fatalf("expected the defaults without features file, got %v, %v", flags, err)
+	}
+
+	if err = os.WriteFile(path, []byte("hooks: false\nwatcher: true\n"), 0644); err != nil {
+		t.Fatalf("failed to write features file: %v", err)
+	}
+	if flags, err = LoadFeatureFlags(path); err != nil {
+		t.Fatalf("failed to load features: %v", err)
+	}
+	if flags.Enabled(FeatureHooks) || !flags.Enabled(FeatureWatcher) || flags.Enabled(FeatureScheduler) {
+		t.Errorf("expected the watcher on only, got %v", flags)
+	}
+
+	if err = os.WriteFile(path, []byte("hoks: false\n"), 0644); err != nil {
+		t.Fatalf("failed to write features file: %v", err)
+	}
+	if _, err = LoadFeatureFlags(path); err == nil || !strings.Contains(err.Error(), "unknown features hoks") {
+		t.Errorf("expected the unknown feature to fail, got %v", err)
+	}
+}
+
+func TestPluginRegistry_SetFeatures(t *testing.T) {
+	db := fsdb.NewDb(t.TempDir())
+	if err := os.WriteFile(db.FilePath(HooksFileName), []byte("pre:\n  - name: trim\n"), 0644); err != nil {
+		t.Fatalf("failed to write hooks config: %v", err)
+	}
+	registry := &PluginRegistry{Db: db}
+
+	registry.SetFeatures(FeatureFlags{})
+	if hooks, _ := registry.Hooks(); len(hooks) != 0 {
+		t.Fatalf("expected the hooks to be disabled by default, got %d", len(hooks))
+	}
+	registry.SetFeatures(FeatureFlags{FeatureHooks: true})
+	if hooks, _ := registry.Hooks(); len(hooks) != 1 {
+		t.Fatalf("expected the hooks to be loaded, got %d", len(hooks))
+	}
+	registry.SetFeatures(FeatureFlags{FeatureHooks: false})
+	if hooks, runDirs := registry.Hooks(); len(hooks) != 0 || runDirs == nil {
+		t.Errorf("expected no hooks and run directories, got %d hooks", len(hooks))
+	}
+}
+
+func TestPluginRegistry_SetFeaturesWhileReading(t *testing.T) {
+	db := fsdb.NewDb(t.TempDir())
+	if err := os.WriteFile(db.FilePath(HooksFileName), []byte("pre:\n  - name: trim\n"), 0644); err != nil {
+		t.Fatalf("failed to write hooks config: %v", err)
+	}
+	registry := &PluginRegistry{Db: db}
+	registry.SetFeatures(FeatureFlags{})
+
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		for i := 0; i < 100; i++ {
+			registry.SetFeatures(FeatureFlags{FeatureHooks: i%2 == 0})
+		}
+	}()
+	for running := true; running; {
+		select {
+		case <-done:
+			running = false
+		default:
+		}
+		registry.Features().Enabled(FeatureHooks)
+		registry.Hooks()
+	}
+
+	// PUT /hooks can't bring back the hooks disabled meanwhile
+	registry.SetFeatures(FeatureFlags{FeatureHooks: true})
+	hooks, runDirs := registry.Hooks()
+	registry.SetFeatures(FeatureFlags{FeatureHooks: false})
+	registry.SetHooks(hooks, runDirs)
+	if hooks, _ = registry.Hooks(); len(hooks) != 0 {
+		t.Errorf("expected the hooks to stay disabled, got %d", len(hooks))
+	}
+}
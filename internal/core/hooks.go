@@ -0,0 +1,379 @@
+package core
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"os/exec"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/danielmiessler/fabric/internal/domain"
+	"gopkg.in/yaml.v3"
+)
+
+// HooksFileName is the hooks config file in the fabric config directory
+const HooksFileName = "hooks.yaml"
+
+// ExecutionHook transforms the user input before it is sent and the model output after it is received
+type ExecutionHook interface {
+	Name() string
+	Before(request *domain.ChatRequest, input string) (string, error)
+	After(request *domain.ChatRequest, output string) (string, error)
+}
+
+// HooksConfig is the content of the hooks config file
+//
+//	pre:
+//	  - name: trim
+//	post:
+//	  - name: strip_code_fences
+//	  - command: "sed 's/foo/bar/'"
+//	patterns:
+//	  summarize:
+//	    post:
+//	      - name: strip_preamble
+//	      - template: "# {{pattern}}\n\n{{text}}"
+//	  summarize_paper:
+//	    pre:
+//	      - command: fmt -w 100
+//	  summarize_meeting:
+//	    post:
+//	      - command: tee summary.md
+//	        mode: passthrough
+//	run_dirs:
+//	  retention: 24h
+type HooksConfig struct {
+	Pre     []HookConfig  `yaml:"pre" json:"pre"`
+	Post    []HookConfig  `yaml:"post" json:"post"`
+	RunDirs RunDirsConfig `yaml:"run_dirs,omitempty" json:"runDirs"`
+	// Patterns are the hooks of the runs of a pattern only, they run after the hooks of all the runs
+	Patterns map[string]PatternHooks `yaml:"patterns,omitempty" json:"patterns,omitempty"`
+}
+
+// hasHooks tells whether the config has any hook
+func (o *HooksConfig) hasHooks() bool {
+	return len(o.Pre) > 0 || len(o.Post) > 0 || len(o.Patterns) > 0
+}
+
+// PatternHooks are the hooks of a pattern
+type PatternHooks struct {
+	Pre  []HookConfig `yaml:"pre,omitempty" json:"pre,omitempty"`
+	Post []HookConfig `yaml:"post,omitempty" json:"post,omitempty"`
+}
+
+// RunDirsConfig sets how long the run directories of hook commands are kept, e.g. "24h".
+// Empty removes them when the run completes. Unconfined runs the commands as the user where bubblewrap
+// isn't available, they can then read and write anywhere the user can.
+type RunDirsConfig struct {
+	Retention  string `yaml:"retention,omitempty" json:"retention,omitempty"`
+	Unconfined bool   `yaml:"unconfined,omitempty" json:"unconfined,omitempty"`
+}
+
+func (o *RunDirsConfig) Build() (ret *RunDirs, err error) {
+	var retention time.Duration
+	if o.Retention != "" {
+		if retention, err = time.ParseDuration(o.Retention); err != nil || retention < 0 {
+			err = fmt.Errorf("invalid run directories retention %q", o.Retention)
+			return
+		}
+	}
+	ret = NewRunDirs(retention)
+	return
+}
+
+// HookConfig selects a built-in transform by name, runs a shell command that receives the text on
+// stdin, or wraps the text in a template. The Mode of a command tells what is done with what it prints.
+// The {{text}} of a template is replaced by the text, {{pattern}} and {{date}} by the pattern and date of
+// the run.
+type HookConfig struct {
+	Name     string `yaml:"name,omitempty" json:"name,omitempty"`
+	Command  string `yaml:"command,omitempty" json:"command,omitempty"`
+	Mode     string `yaml:"mode,omitempty" json:"mode,omitempty"`
+	Template string `yaml:"template,omitempty" json:"template,omitempty"`
+}
+
+// Modes of the hook commands
+const (
+	// HookModeTransform replaces the text with the output of the command, the default
+	HookModeTransform = "transform"
+	// HookModeAppend appends the output of the command to the text, e.g. to inject `git diff --staged`
+	HookModeAppend = "append"
+	// HookModePassthrough keeps the text, the command is run for its effect, e.g. to copy the output
+	HookModePassthrough = "passthrough"
+)
+
+type transform func(request *domain.ChatRequest, text string) (string, error)
+
+// codeFenceRegex matches an output that is a single fenced block, its body keeps the newline ending its last line
+var codeFenceRegex = regexp.MustCompile("(?s)^\\s*```[\\w-]*\\n(.*?\\n?)```\\s*$")
+
+// preambleRegex matches a leading line of the model announcing its answer, like "Sure, here is the
+// summary:" or "Here are the key points:", with the blank lines after it
+var preambleRegex = regexp.MustCompile(`(?i)^\s*(?:(?:sure|certainly|of course|absolutely|okay|ok)[,!.][^\n]{0,200}|here(?:'s| is| are)\b[^\n]{0,200}:)[ \t]*(?:\n|$)(?:[ \t]*\n)*`)
+
+// BuiltinTransforms are the transforms hooks can refer to by name
+var BuiltinTransforms = map[string]transform{
+	"trim": func(_ *domain.ChatRequest, text string) (string, error) {
+		return strings.TrimSpace(text), nil
+	},
+	"strip_code_fences": func(_ *domain.ChatRequest, text string) (string, error) {
+		return codeFenceRegex.ReplaceAllString(text, "$1"), nil
+	},
+	"strip_preamble": func(_ *domain.ChatRequest, text string) (string, error) {
+		// "Sure!" and "Here is the summary:" may take two lines
+		for range 2 {
+			text = preambleRegex.ReplaceAllString(text, "")
+		}
+		return text, nil
+	},
+	"frontmatter": func(request *domain.ChatRequest, text string) (string, error) {
+		var frontmatter strings.Builder
+		frontmatter.WriteString("---\n")
+		if request.PatternName != "" {
+			fmt.Fprintf(&frontmatter, "pattern: %s\n", request.PatternName)
+		}
+		fmt.Fprintf(&frontmatter, "date: %s\n", time.Now().Format(time.RFC3339))
+		frontmatter.WriteString("---\n\n")
+		return frontmatter.String() + text, nil
+	},
+}
+
+// transformHook applies a transform either before sending or after receiving, to the runs of pattern
+// only when it is set
+type transformHook struct {
+	name    string
+	pattern string
+	before  transform
+	after   transform
+}
+
+func (o *transformHook) Name() string {
+	return o.name
+}
+
+func (o *transformHook) Before(request *domain.ChatRequest, input string) (string, error) {
+	return o.apply(o.before, request, input)
+}
+
+func (o *transformHook) After(request *domain.ChatRequest, output string) (string, error) {
+	return o.apply(o.after, request, output)
+}
+
+// apply runs the transform, the runs of the pattern hooks are logged as they are configured per pattern
+// and easily forgotten
+func (o *transformHook) apply(fn transform, request *domain.ChatRequest, text string) (ret string, err error) {
+	if fn == nil || (o.pattern != "" && request.PatternName != o.pattern) {
+		return text, nil
+	}
+	start := time.Now()
+	if ret, err = fn(request, text); err != nil || o.pattern == "" {
+		return
+	}
+	fmt.Fprintf(os.Stderr, "Hook %s: %d to %d bytes in %s\n", o.name, len(text), len(ret), time.Since(start).Round(time.Millisecond))
+	return
+}
+
+// LoadHooks reads the hooks config file. A missing file means no hooks.
+func LoadHooks(path string) (ret []ExecutionHook, err error) {
+	var config *HooksConfig
+	if config, err = LoadHooksConfig(path); err != nil {
+		return
+	}
+	return config.BuildHooks()
+}
+
+func LoadHooksConfig(path string) (ret *HooksConfig, err error) {
+	ret = &HooksConfig{}
+	var data []byte
+	if data, err = os.ReadFile(path); err != nil {
+		if os.IsNotExist(err) {
+			err = nil
+		}
+		return
+	}
+	if err = yaml.Unmarshal(data, ret); err != nil {
+		err = fmt.Errorf("invalid hooks config %s: %v", path, err)
+	}
+	return
+}
+
+// BuildHooks creates the hooks in config order, pre hooks first, the hooks of all the runs before the
+// pattern hooks, in pattern name order
+func (o *HooksConfig) BuildHooks() (ret []ExecutionHook, err error) {
+	patterns := make([]string, 0, len(o.Patterns))
+	for pattern := range o.Patterns {
+		patterns = append(patterns, pattern)
+	}
+	sort.Strings(patterns)
+
+	add := func(stage string, pattern string, configs []HookConfig) (err error) {
+		for _, config := range configs {
+			var fn transform
+			if fn, err = config.transform(o.RunDirs.Unconfined); err != nil {
+				if pattern != "" {
+					err = fmt.Errorf("%s hook of pattern %s: %v", stage, pattern, err)
+				}
+				return
+			}
+			hook := &transformHook{name: stage + ":" + config.label(), pattern: pattern}
+			if pattern != "" {
+				hook.name = stage + ":" + pattern + ":" + config.label()
+			}
+			if stage == "pre" {
+				hook.before = fn
+			} else {
+				hook.after = fn
+			}
+			ret = append(ret, hook)
+		}
+		return
+	}
+
+	if err = add("pre", "", o.Pre); err != nil {
+		return
+	}
+	for _, pattern := range patterns {
+		if err = add("pre", pattern, o.Patterns[pattern].Pre); err != nil {
+			return
+		}
+	}
+	if err = add("post", "", o.Post); err != nil {
+		return
+	}
+	for _, pattern := range patterns {
+		if err = add("post", pattern, o.Patterns[pattern].Post); err != nil {
+			return
+		}
+	}
+	return
+}
+
+// CheckBuiltinsOnly fails when a hook is not a built-in transform: the commands, run by the shell,
+// the templates and the unconfined commands are only taken from the hand-edited hooks config file,
+// not from the API
+func (o *HooksConfig) CheckBuiltinsOnly() (err error) {
+	if o.RunDirs.Unconfined {
+		return fmt.Errorf("unconfined commands are set in %s only", HooksFileName)
+	}
+	check := func(configs []HookConfig) error {
+		for _, config := range configs {
+			if config.Command != "" || config.Template != "" || config.Mode != "" {
+				return fmt.Errorf("hook %q is not a built-in, commands and templates are set in %s only", config.label(), HooksFileName)
+			}
+			if _, ok := BuiltinTransforms[config.Name]; !ok {
+				return fmt.Errorf("unknown hook %q", config.Name)
+			}
+		}
+		return nil
+	}
+	if err = check(o.Pre); err != nil {
+		return
+	}
+	if err = check(o.Post); err != nil {
+		return
+	}
+	for _, hooks := range o.Patterns {
+		if err = check(hooks.Pre); err != nil {
+			return
+		}
+		if err = check(hooks.Post); err != nil {
+			return
+		}
+	}
+	return
+}
+
+func (o *HookConfig) label() string {
+	if o.Name != "" {
+		return o.Name
+	}
+	if o.Template != "" {
+		return "template"
+	}
+	return o.Command
+}
+
+// transform builds the transform of the hook, the commands are confined unless unconfined is set
+func (o *HookConfig) transform(unconfined bool) (ret transform, err error) {
+	if o.Command != "" {
+		command, mode := o.Command, o.Mode
+		if mode != "" && mode != HookModeTransform && mode != HookModeAppend && mode != HookModePassthrough {
+			err = fmt.Errorf("invalid mode %q of hook %q, use %s, %s or %s", mode, command, HookModeTransform, HookModeAppend, HookModePassthrough)
+			return
+		}
+		if !unconfined {
+			if err = checkConfinement(); err != nil {
+				return
+			}
+		}
+		ret = func(request *domain.ChatRequest, text string) (ret string, err error) {
+			var output string
+			if output, err = runHookCommand(command, text, request, unconfined); err != nil {
+				return
+			}
+			switch mode {
+			case HookModeAppend:
+				ret = strings.TrimRight(text, "\n") + "\n\n" + strings.TrimRight(output, "\n")
+			case HookModePassthrough:
+				ret = text
+			default:
+				ret = output
+			}
+			return
+		}
+		return
+	}
+	if o.Mode != "" {
+		err = fmt.Errorf("the mode of hook %q applies to commands only", o.label())
+		return
+	}
+	if o.Template != "" {
+		if o.Name != "" {
+			err = fmt.Errorf("hook %q can't have a template, a hook is a name, a command or a template", o.Name)
+			return
+		}
+		template := o.Template
+		ret = func(request *domain.ChatRequest, text string) (string, error) {
+			return strings.NewReplacer(
+				"{{text}}", text,
+				"{{pattern}}", request.PatternName,
+				"{{date}}", time.Now().Format(time.DateOnly),
+			).Replace(template), nil
+		}
+		return
+	}
+	var ok bool
+	if ret, ok = BuiltinTransforms[o.Name]; !ok {
+		err = fmt.Errorf("unknown hook %q", o.Name)
+	}
+	return
+}
+
+// runHookCommand runs the command in the run directory, if there is one, with the pattern of the run
+// in FABRIC_PATTERN. It is confined by confinedHookCommand unless unconfined is set.
+func runHookCommand(command string, text string, request *domain.ChatRequest, unconfined bool) (ret string, err error) {
+	var cmd *exec.Cmd
+	if unconfined {
+		cmd = exec.Command("sh", "-c", command)
+		cmd.Env = append(os.Environ(), "FABRIC_PATTERN="+request.PatternName)
+		if runDir := request.RunDir; runDir != "" {
+			cmd.Dir = runDir
+			cmd.Env = append(cmd.Env, "FABRIC_RUN_DIR="+runDir, "TMPDIR="+runDir)
+		}
+	} else {
+		cmd = confinedHookCommand(command, request)
+	}
+	cmd.Stdin = strings.NewReader(text)
+	var stdout, stderr bytes.Buffer
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+	if err = cmd.Run(); err != nil {
+		err = fmt.Errorf("hook command %q failed: %v: %s", command, err, strings.TrimSpace(stderr.String()))
+		return
+	}
+	ret = stdout.String()
+	return
+}
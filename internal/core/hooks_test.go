@@ -0,0 +1,227 @@
+package core
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/danielmiessler/fabric/internal/chat"
+	"github.com/danielmiessler/fabric/internal/domain"
+	"github.com/danielmiessler/fabric/internal/plugins/db/fsdb"
+)
+
+func TestLoadHooks(t *testing.T) {
+	path := filepath.Join(t.TempDir(), HooksFileName)
+
+	hooks, err := LoadHooks(path)
+	if err != nil || len(hooks) != 0 {
+		t.Fatalf("expected no hooks without config file, got %v, %v", hooks, err)
+	}
+
+	config := "pre:\n  - name: trim\npost:\n  - name: strip_code_fences\n  - command: tr a-z A-Z\nrun_dirs:\n  unconfined: true\n"
+	if err = os.WriteFile(path, []byte(config), 0644); err != nil {
+		t.Fatalf("failed to write hooks config: %v", err)
+	}
+	if hooks, err = LoadHooks(path); err != nil {
+		t.Fatalf("failed to load hooks: %v", err)
+	}
+	if len(hooks) != 3 {
+		t.Fatalf("expected 3 hooks, got %d", len(hooks))
+	}
+
+	if err = os.WriteFile(path, []byte("post:\n  - name: unknown\n"), 0644); err != nil {
+		t.Fatalf("failed to write hooks config: %v", err)
+	}
+	if _, err = LoadHooks(path); err == nil {
+		t.Error("expected unknown hook to fail")
+	}
+}
+
+func TestChatter_Send_AppliesHooks(t *testing.T) {
+	var sentInput string
+	vendor := &mockVendor{sendFunc: func(_ context.Context, msgs []*chat.ChatCompletionMessage, _ *domain.ChatOptions) (string, error) {
+		sentInput = msgs[len(msgs)-1].Content
+		return "```markdown\nhello\n```", nil
+	}}
+
+	config := &HooksConfig{
+		Pre:     []HookConfig{{Name: "trim"}},
+		Post:    []HookConfig{{Name: "strip_code_fences"}, {Command: "tr a-z A-Z"}},
+		RunDirs: RunDirsConfig{Unconfined: true},
+	}
+	hooks, err := config.BuildHooks()
+	if err != nil {
+		t.Fatalf("failed to build hooks: %v", err)
+	}
+
+	chatter := &Chatter{db: fsdb.NewDb(t.TempDir()), vendor: vendor, model: "test-model", hooks: hooks}
+	request := &domain.ChatRequest{
+		Message: &chat.ChatCompletionMessage{Role: chat.ChatMessageRoleUser, Content: "  input  \n"},
+	}
+	session, err := chatter.Send(request, &domain.ChatOptions{})
+	if err != nil {
+		t.Fatalf("Send returned error: %v", err)
+	}
+
+	if sentInput != "input" {
+		t.Errorf("expected trimmed input to be sent, got %q", sentInput)
+	}
+	if output := session.GetLastMessage().Content; output != "HELLO\n" {
+		t.Errorf("expected post-processed output, got %q", output)
+	}
+}
+
+func TestCommandHook_RunsInRunDir(t *testing.T) {
+	config := &HooksConfig{Post: []HookConfig{{Command: "pwd; echo $FABRIC_RUN_DIR"}}, RunDirs: RunDirsConfig{Unconfined: true}}
+	hooks, err := config.BuildHooks()
+	if err != nil {
+		t.Fatalf("failed to build hooks: %v", err)
+	}
+
+	dir, err := filepath.EvalSymlinks(t.TempDir())
+	if err != nil {
+		t.Fatalf("failed to resolve temp dir: %v", err)
+	}
+	output, err := hooks[0].After(&domain.ChatRequest{RunDir: dir}, "")
+	if err != nil {
+		t.Fatalf("hook failed: %v", err)
+	}
+	if expected := dir + "\n" + dir + "\n"; output != expected {
+		t.Errorf("expected hook to run in %s, got %q", dir, output)
+	}
+}
+
+func TestCommandHook_Confined(t *testing.T) {
+	config := &HooksConfig{Post: []HookConfig{{Command: "echo ok > $FABRIC_RUN_DIR/out; touch $HOME/escaped || echo refused"}}}
+
+	if checkConfinement() != nil {
+		t.Skip("bubblewrap is not installed")
+	}
+	hooks, err := config.BuildHooks()
+	if err != nil {
+		t.Fatalf("failed to build hooks: %v", err)
+	}
+	dir := t.TempDir()
+	output, err := hooks[0].After(&domain.ChatRequest{RunDir: dir}, "")
+	if err != nil {
+		t.Fatalf("hook failed: %v", err)
+	}
+	if !strings.Contains(output, "refused") {
+		t.Errorf("expected the home directory to be read-only, got %q", output)
+	}
+	if data, _ := os.ReadFile(filepath.Join(dir, "out")); string(data) != "ok\n" {
+		t.Errorf("expected the run directory to be writable, got %q", data)
+	}
+}
+
+func TestCheckConfinement(t *testing.T) {
+	defer func(command string) { confineCommand = command }(confineCommand)
+	confineCommand = "fabric-test-missing-bwrap"
+
+	config := &HooksConfig{Post: []HookConfig{{Command: "cat"}}}
+	if _, err := config.BuildHooks(); err == nil || !strings.Contains(err.Error(), "unconfined: true") {
+		t.Errorf("expected the command to fail without bubblewrap, got %v", err)
+	}
+	config.RunDirs.Unconfined = true
+	if _, err := config.BuildHooks(); err != nil {
+		t.Errorf("expected the unconfined command to build, got %v", err)
+	}
+}
+
+func TestPatternHooks(t *testing.T) {
+	config := &HooksConfig{
+		Post: []HookConfig{{Name: "trim"}},
+		Patterns: map[string]PatternHooks{
+			"write_commit_message": {Pre: []HookConfig{{Command: "echo diff of $FABRIC_PATTERN", Mode: HookModeAppend}}},
+			"summarize_meeting":    {Post: []HookConfig{{Command: "cat > /dev/null", Mode: HookModePassthrough}}},
+		},
+		RunDirs: RunDirsConfig{Unconfined: true},
+	}
+	hooks, err := config.BuildHooks()
+	if err != nil {
+		t.Fatalf("failed to build hooks: %v", err)
+	}
+	if len(hooks) != 3 {
+		t.Fatalf("expected 3 hooks, got %d", len(hooks))
+	}
+
+	request := &domain.ChatRequest{PatternName: "write_commit_message"}
+	input, err := hooks[0].Before(request, "message\n")
+	if err != nil {
+		t.Fatalf("hook failed: %v", err)
+	}
+	if expected := "message\n\ndiff of write_commit_message"; input != expected {
+		t.Errorf("expected %q, got %q", expected, input)
+	}
+	if input, _ = hooks[0].Before(&domain.ChatRequest{PatternName: "summarize"}, "message"); input != "message" {
+		t.Errorf("expected the hook of another pattern to keep the input, got %q", input)
+	}
+
+	output, err := hooks[2].After(&domain.ChatRequest{PatternName: "summarize_meeting"}, "summary")
+	if err != nil || output != "summary" {
+		t.Errorf("expected passthrough hook to keep the output, got %q, %v", output, err)
+	}
+
+	config = &HooksConfig{Patterns: map[string]PatternHooks{"summarize": {Post: []HookConfig{{Command: "cat", Mode: "unknown"}}}}}
+	if _, err = config.BuildHooks(); err == nil {
+		t.Error("expected unknown mode to fail")
+	}
+}
+
+func TestStripPreamble(t *testing.T) {
+	strip := BuiltinTransforms["strip_preamble"]
+	cases := map[string]string{
+		"Sure, here is the summary:\n\n# Summary\n":             "# Summary\n",
+		"Certainly!\n\nHere are the key points:\n- one\n":       "- one\n",
+		"Here's the rewritten text:\n    indented code\n":       "    indented code\n",
+		"Here are the three main points. The first is speed.\n": "Here are the three main points. The first is speed.\n",
+		"# Summary\n\nSure, the author is right.\n":             "# Summary\n\nSure, the author is right.\n",
+		"Surely this is the answer.\n":                          "Surely this is the answer.\n",
+	}
+	for text, expected := range cases {
+		if output, err := strip(&domain.ChatRequest{}, text); err != nil || output != expected {
+			t.Errorf("strip_preamble(%q) = %q, %v, expected %q", text, output, err, expected)
+		}
+	}
+}
+
+func TestTemplateHook(t *testing.T) {
+	config := &HooksConfig{Post: []HookConfig{{Template: "# {{pattern}}\n\n{{text}}"}}}
+	hooks, err := config.BuildHooks()
+	if err != nil {
+		t.Fatalf("failed to build hooks: %v", err)
+	}
+	if name := hooks[0].Name(); name != "post:template" {
+		t.Errorf("unexpected hook name %q", name)
+	}
+	output, err := hooks[0].After(&domain.ChatRequest{PatternName: "summarize"}, "the summary")
+	if expected := "# summarize\n\nthe summary"; err != nil || output != expected {
+		t.Errorf("expected %q, got %q, %v", expected, output, err)
+	}
+
+	config = &HooksConfig{Post: []HookConfig{{Name: "trim", Template: "{{text}}"}}}
+	if _, err = config.BuildHooks(); err == nil {
+		t.Error("expected a named hook with a template to fail")
+	}
+}
+
+func TestHooksConfig_CheckBuiltinsOnly(t *testing.T) {
+	config := &HooksConfig{Pre: []HookConfig{{Name: "trim"}},
+		Patterns: map[string]PatternHooks{"summarize": {Post: []HookConfig{{Name: "strip_preamble"}}}}}
+	if err := config.CheckBuiltinsOnly(); err != nil {
+		t.Errorf("expected the built-ins to pass, got %v", err)
+	}
+
+	for _, hook := range []HookConfig{{Command: "rm -rf ~"}, {Template: "{{text}}"}, {Name: "unknown"}} {
+		config = &HooksConfig{Patterns: map[string]PatternHooks{"summarize": {Pre: []HookConfig{hook}}}}
+		if err := config.CheckBuiltinsOnly(); err == nil {
+			t.Errorf("expected %+v to fail", hook)
+		}
+	}
+	config = &HooksConfig{Pre: []HookConfig{{Name: "trim"}}, RunDirs: RunDirsConfig{Unconfined: true}}
+	if err := config.CheckBuiltinsOnly(); err == nil {
+		t.Error("expected the unconfined commands to fail")
+	}
+}
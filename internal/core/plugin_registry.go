@@ -9,6 +9,7 @@ import (
 	"sort"
 	"strconv"
 	"strings"
+	"sync"
 
 	"github.com/danielmiessler/fabric/internal/plugins/ai/anthropic"
 	"github.com/danielmiessler/fabric/internal/plugins/ai/azure"
@@ -33,6 +34,9 @@ import (
 	"github.com/danielmiessler/fabric/internal/tools/custom_patterns"
 	"github.com/danielmiessler/fabric/internal/tools/jina"
 	"github.com/danielmiessler/fabric/internal/tools/lang"
+	"github.com/danielmiessler/fabric/internal/tools/ocr"
+	"github.com/danielmiessler/fabric/internal/tools/tokenizer"
+	"github.com/danielmiessler/fabric/internal/tools/whisper"
 	"github.com/danielmiessler/fabric/internal/tools/youtube"
 	"github.com/danielmiessler/fabric/internal/util"
 )
@@ -78,6 +82,8 @@ func NewPluginRegistry(db *fsdb.Db) (ret *PluginRegistry, err error) {
 		YouTube:        youtube.NewYouTube(),
 		Language:       lang.NewLanguage(),
 		Jina:           jina.NewClient(),
+		WhisperCpp:     whisper.NewWhisperCpp(),
+		Tesseract:      ocr.NewTesseract(),
 		Strategies:     strategy.NewStrategiesManager(),
 	}
 
@@ -86,6 +92,21 @@ func NewPluginRegistry(db *fsdb.Db) (ret *PluginRegistry, err error) {
 		return
 	}
 	ret.TemplateExtensions = template.NewExtensionManager(filepath.Join(homedir, ".config/fabric"))
+	// the {{var:name}} calls of the templates are filled from the variables stored across runs
+	template.Variables = db.Variables
+
+	var features FeatureFlags
+	if features, err = LoadFeatureFlags(db.FilePath(FeaturesFileName)); err != nil {
+		fmt.Fprintf(os.Stderr, "Warning: the default features are used: %v\n", err)
+		features = FeatureFlags{}
+		err = nil
+	}
+	ret.SetFeatures(features)
+
+	if ret.Tokenizers, err = tokenizer.Load(db.FilePath(tokenizer.FileName)); err != nil {
+		fmt.Fprintf(os.Stderr, "Warning: the token counts are estimated: %v\n", err)
+		err = nil
+	}
 
 	ret.Defaults = tools.NeeDefaults(ret.GetModels)
 
@@ -148,8 +169,76 @@ type PluginRegistry struct {
 	YouTube            *youtube.YouTube
 	Language           *lang.Language
 	Jina               *jina.Client
+	WhisperCpp         *whisper.WhisperCpp
+	Tesseract          *ocr.Tesseract
 	TemplateExtensions *template.ExtensionManager
 	Strategies         *strategy.StrategiesManager
+	Tokenizers         *tokenizer.Tokenizers // of the models the generic estimate doesn't fit
+	Version            string                // of fabric, recorded in the environment of the runs
+
+	// the features and the hooks are replaced by PUT /features and PUT /hooks while other requests read them
+	mu       sync.RWMutex
+	features FeatureFlags
+	hooks    []ExecutionHook
+	runDirs  *RunDirs
+}
+
+// SetHooks replaces the hooks and the run directories of the new runs, the running ones keep theirs.
+// The hooks are dropped while the hooks feature is disabled.
+func (o *PluginRegistry) SetHooks(hooks []ExecutionHook, runDirs *RunDirs) {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	if !o.features.Enabled(FeatureHooks) {
+		hooks = nil
+	}
+	o.hooks, o.runDirs = hooks, runDirs
+}
+
+// Hooks returns the hooks and the run directories of the new runs
+func (o *PluginRegistry) Hooks() ([]ExecutionHook, *RunDirs) {
+	o.mu.RLock()
+	defer o.mu.RUnlock()
+	return o.hooks, o.runDirs
+}
+
+// Features returns the feature flags in effect, they must not be modified
+func (o *PluginRegistry) Features() FeatureFlags {
+	o.mu.RLock()
+	defer o.mu.RUnlock()
+	return o.features
+}
+
+// SetFeatures applies the feature flags: the hooks are loaded when enabled and the template extensions
+// toggled. The scheduler and the watcher of fabric --serve check the flags when they start. The flags,
+// the hooks and the run directories are replaced together, a run sees either the old or the new ones.
+func (o *PluginRegistry) SetFeatures(features FeatureFlags) {
+	var hooks []ExecutionHook
+	var runDirs *RunDirs
+	var err error
+	var hooksConfig *HooksConfig
+	if hooksConfig, err = LoadHooksConfig(o.Db.FilePath(HooksFileName)); err == nil {
+		if features.Enabled(FeatureHooks) {
+			hooks, err = hooksConfig.BuildHooks()
+		} else if _, set := features[FeatureHooks]; !set && hooksConfig.hasHooks() {
+			fmt.Fprintf(os.Stderr, "Warning: the hooks of %s are ignored, set %s: true in %s to run them\n",
+				HooksFileName, FeatureHooks, FeaturesFileName)
+		}
+		if err == nil {
+			runDirs, err = hooksConfig.RunDirs.Build()
+		}
+	}
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Warning: hooks are disabled: %v\n", err)
+		hooks = nil
+	}
+	if runDirs == nil {
+		runDirs = NewRunDirs(0)
+	}
+
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	o.features, o.hooks, o.runDirs = features, hooks, runDirs
+	template.SetExtensionsEnabled(features.Enabled(FeatureExtensions))
 }
 
 func (o *PluginRegistry) SaveEnvFile() (err error) {
@@ -167,6 +256,8 @@ func (o *PluginRegistry) SaveEnvFile() (err error) {
 
 	o.YouTube.SetupFillEnvFileContent(&envFileContent)
 	o.Jina.SetupFillEnvFileContent(&envFileContent)
+	o.WhisperCpp.SetupFillEnvFileContent(&envFileContent)
+	o.Tesseract.SetupFillEnvFileContent(&envFileContent)
 	o.Language.SetupFillEnvFileContent(&envFileContent)
 
 	err = o.Db.SaveEnv(envFileContent.String())
@@ -191,7 +282,7 @@ func (o *PluginRegistry) Setup() (err error) {
 			return vendor
 		})...)
 
-	groupsPlugins.AddGroupItems("Tools", o.CustomPatterns, o.Defaults, o.Jina, o.Language, o.PatternsLoader, o.Strategies, o.YouTube)
+	groupsPlugins.AddGroupItems("Tools", o.CustomPatterns, o.Defaults, o.Jina, o.Language, o.PatternsLoader, o.Strategies, o.Tesseract, o.WhisperCpp, o.YouTube)
 
 	for {
 		groupsPlugins.Print(false)
@@ -253,6 +344,45 @@ func (o *PluginRegistry) ConfigureVendors() {
 	}
 }
 
+// GetTranscriber returns the vendor used for speech-to-text, or whisper.cpp for local transcription. Without a vendor
+// name the first configured vendor that supports transcription is used, whisper.cpp if none does.
+func (o *PluginRegistry) GetTranscriber(vendorName string) (ret ai.Transcriber, err error) {
+	o.ConfigureVendors()
+	if strings.EqualFold(vendorName, whisper.VendorName) {
+		if !o.WhisperCpp.IsConfigured() {
+			err = fmt.Errorf("%s is not configured, please run the setup procedure", whisper.VendorName)
+			return
+		}
+		ret = o.WhisperCpp
+		return
+	}
+	if vendorName != "" {
+		vendor := o.VendorManager.FindByName(vendorName)
+		if vendor == nil {
+			err = fmt.Errorf("vendor %s not found or not configured", vendorName)
+			return
+		}
+		var ok bool
+		if ret, ok = vendor.(ai.Transcriber); !ok {
+			err = fmt.Errorf("vendor %s does not support transcription", vendorName)
+		}
+		return
+	}
+
+	for _, vendor := range o.VendorManager.Vendors {
+		if transcriber, ok := vendor.(ai.Transcriber); ok {
+			ret = transcriber
+			return
+		}
+	}
+	if o.WhisperCpp.IsConfigured() {
+		ret = o.WhisperCpp
+		return
+	}
+	err = fmt.Errorf("no configured vendor supports transcription, please set up OpenAI or Whisper.cpp")
+	return
+}
+
 func (o *PluginRegistry) GetModels() (ret *ai.VendorsModels, err error) {
 	o.ConfigureVendors()
 	ret, err = o.VendorManager.GetModels()
@@ -281,9 +411,11 @@ func (o *PluginRegistry) Configure() (err error) {
 		o.PatternsLoader.Patterns.CustomPatternsDir = customPatternsDir
 	}
 
-	//YouTube and Jina are not mandatory, so ignore not configured error
+	//YouTube, Jina, Whisper.cpp and Tesseract are not mandatory, so ignore not configured error
 	_ = o.YouTube.Configure()
 	_ = o.Jina.Configure()
+	_ = o.WhisperCpp.Configure()
+	_ = o.Tesseract.Configure()
 	_ = o.Language.Configure()
 	return
 }
@@ -359,5 +491,44 @@ func (o *PluginRegistry) GetChatter(model string, modelContextLength int, vendor
 		return
 	}
 	ret.strategy = strategy
+	ret.hooks, ret.runDirs = o.Hooks()
+	ret.version = o.Version
+	if o.Defaults.AutosavePath != nil {
+		ret.autosave = o.Defaults.AutosavePath.Value
+	}
+
+	if !dryRun {
+		ret.breaker = vendorManager.Breaker
+		if o.Defaults.FallbackModels != nil {
+			ret.fallbacks = o.resolveFallbacks(o.Defaults.FallbackModels.Value, ret.vendor, ret.model)
+		}
+	}
+	return
+}
+
+// resolveFallbacks parses the comma separated Vendor|model fallback chain.
+// Unknown vendors and the primary Vendor|model pair are skipped.
+func (o *PluginRegistry) resolveFallbacks(chain string, primary ai.Vendor, primaryModel string) (ret []fallbackVendor) {
+	for _, item := range strings.Split(chain, ",") {
+		item = strings.TrimSpace(item)
+		if item == "" {
+			continue
+		}
+		vendorName, model, found := strings.Cut(item, "|")
+		if !found || model == "" {
+			fmt.Fprintf(os.Stderr, "Warning: ignoring fallback %q, expected Vendor|model\n", item)
+			continue
+		}
+		vendor := o.VendorManager.FindByName(strings.TrimSpace(vendorName))
+		if vendor == nil {
+			fmt.Fprintf(os.Stderr, "Warning: ignoring fallback %q, vendor is not configured\n", item)
+			continue
+		}
+		model = strings.TrimSpace(model)
+		if vendor == primary && model == primaryModel {
+			continue
+		}
+		ret = append(ret, fallbackVendor{vendor: vendor, model: model})
+	}
 	return
 }
@@ -0,0 +1,57 @@
+package core
+
+import (
+	"context"
+
+	"github.com/danielmiessler/fabric/internal/chat"
+	"github.com/danielmiessler/fabric/internal/domain"
+	"github.com/danielmiessler/fabric/internal/plugins/db/fsdb"
+)
+
+// ReplayRequest rebuilds the request and options of a recorded run. The session is not continued,
+// so the replay sees the same messages as the original run. Runs recorded without metadata use the
+// default model and sampling parameters.
+func ReplayRequest(run *fsdb.Run) (request *domain.ChatRequest, opts *domain.ChatOptions) {
+	request = &domain.ChatRequest{
+		Message:          &chat.ChatCompletionMessage{Role: chat.ChatMessageRoleUser, Content: run.Input},
+		PatternName:      run.PatternName,
+		PatternVariables: run.Variables,
+		ContextName:      run.ContextName,
+		StrategyName:     run.StrategyName,
+		Language:         run.Language,
+		App:              "replay",
+	}
+
+	opts = &domain.ChatOptions{
+		Temperature: domain.DefaultTemperature,
+		TopP:        domain.DefaultTopP,
+	}
+	if metadata := run.Metadata; metadata != nil {
+		opts.Model = metadata.Model
+		opts.Seed = metadata.Seed
+		opts.Temperature = metadata.Temperature
+		opts.TopP = metadata.TopP
+		opts.PresencePenalty = metadata.PresencePenalty
+		opts.FrequencyPenalty = metadata.FrequencyPenalty
+		opts.MaxTokens = metadata.MaxTokens
+		opts.StopSequences = metadata.StopSequences
+	}
+	return
+}
+
+// ReplayRun runs a recorded run again with the same vendor, model, seed and sampling parameters.
+// The replay is recorded in the history as a new run.
+func (o *PluginRegistry) ReplayRun(ctx context.Context, run *fsdb.Run) (session *fsdb.Session, err error) {
+	request, opts := ReplayRequest(run)
+
+	var vendor string
+	if run.Metadata != nil {
+		vendor = run.Metadata.Vendor
+	}
+
+	var chatter *Chatter
+	if chatter, err = o.GetChatter(opts.Model, 0, vendor, run.StrategyName, false, false); err != nil {
+		return
+	}
+	return chatter.SendContext(ctx, request, opts)
+}
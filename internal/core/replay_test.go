@@ -0,0 +1,48 @@
+package core
+
+import (
+	"testing"
+
+	"github.com/danielmiessler/fabric/internal/domain"
+	"github.com/danielmiessler/fabric/internal/plugins/db/fsdb"
+)
+
+func TestReplayRequest(t *testing.T) {
+	run := &fsdb.Run{
+		PatternName: "summarize",
+		SessionName: "notes",
+		Variables:   map[string]string{"lang": "de"},
+		Language:    "de",
+		Input:       "some text",
+		Metadata: &domain.ExecutionMetadata{
+			Model:         "gpt-4o",
+			Vendor:        "OpenAI",
+			Seed:          42,
+			Temperature:   0.2,
+			TopP:          0.8,
+			MaxTokens:     512,
+			StopSequences: []string{"END"},
+		},
+	}
+
+	request, opts := ReplayRequest(run)
+	if request.Message.Content != "some text" || request.PatternName != "summarize" || request.Language != "de" {
+		t.Errorf("unexpected replay request: %+v", request)
+	}
+	if request.SessionName != "" {
+		t.Errorf("expected the session not to be continued, got %q", request.SessionName)
+	}
+	if request.PatternVariables["lang"] != "de" {
+		t.Errorf("expected pattern variables to be replayed, got %v", request.PatternVariables)
+	}
+	if opts.Model != "gpt-4o" || opts.Seed != 42 || opts.Temperature != 0.2 || opts.TopP != 0.8 ||
+		opts.MaxTokens != 512 || len(opts.StopSequences) != 1 {
+		t.Errorf("unexpected replay options: %+v", opts)
+	}
+
+	// failed runs have no metadata, they are replayed with the defaults
+	_, opts = ReplayRequest(&fsdb.Run{Input: "input"})
+	if opts.Temperature != domain.DefaultTemperature || opts.TopP != domain.DefaultTopP || opts.Seed != 0 {
+		t.Errorf("expected default options, got %+v", opts)
+	}
+}
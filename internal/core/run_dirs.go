@@ -0,0 +1,57 @@
+package core
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+// RunDirs creates the per-run working directories of hook commands under <TempDir>/fabric-runs.
+// Commands start in the directory with TMPDIR and FABRIC_RUN_DIR pointing to it, so the files of a run
+// are found together. It is the only directory the confined commands can write to, see
+// confinedHookCommand. Directories are removed after Retention.
+type RunDirs struct {
+	Root      string
+	Retention time.Duration // 0 removes the directory as soon as the run completes
+}
+
+func NewRunDirs(retention time.Duration) *RunDirs {
+	return &RunDirs{Root: filepath.Join(os.TempDir(), "fabric-runs"), Retention: retention}
+}
+
+// Create makes a new run directory, removing the expired ones first
+func (o *RunDirs) Create() (dir string, err error) {
+	if err = os.MkdirAll(o.Root, 0700); err != nil {
+		return
+	}
+	o.Clean(time.Now())
+	if dir, err = os.MkdirTemp(o.Root, time.Now().Format("20060102-150405-")); err != nil {
+		err = fmt.Errorf("could not create run directory: %v", err)
+	}
+	return
+}
+
+// Release is called when the run completes
+func (o *RunDirs) Release(dir string) {
+	if o.Retention == 0 {
+		os.RemoveAll(dir)
+	}
+}
+
+// Clean removes the run directories last modified more than Retention before now
+func (o *RunDirs) Clean(now time.Time) {
+	entries, err := os.ReadDir(o.Root)
+	if err != nil {
+		return
+	}
+	for _, entry := range entries {
+		info, infoErr := entry.Info()
+		if infoErr != nil || !entry.IsDir() {
+			continue
+		}
+		if now.Sub(info.ModTime()) > o.Retention {
+			os.RemoveAll(filepath.Join(o.Root, entry.Name()))
+		}
+	}
+}
@@ -0,0 +1,38 @@
+package core
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestRunDirs_Retention(t *testing.T) {
+	runDirs := &RunDirs{Root: filepath.Join(t.TempDir(), "runs")}
+
+	dir, err := runDirs.Create()
+	if err != nil {
+		t.Fatalf("Create failed: %v", err)
+	}
+	if filepath.Dir(dir) != runDirs.Root {
+		t.Errorf("expected run dir under %s, got %s", runDirs.Root, dir)
+	}
+	runDirs.Release(dir)
+	if _, err = os.Stat(dir); !os.IsNotExist(err) {
+		t.Errorf("expected run dir to be removed without retention")
+	}
+
+	runDirs.Retention = time.Hour
+	if dir, err = runDirs.Create(); err != nil {
+		t.Fatalf("Create failed: %v", err)
+	}
+	runDirs.Release(dir)
+	if _, err = os.Stat(dir); err != nil {
+		t.Errorf("expected run dir to be kept during retention: %v", err)
+	}
+
+	runDirs.Clean(time.Now().Add(2 * time.Hour))
+	if _, err = os.Stat(dir); !os.IsNotExist(err) {
+		t.Errorf("expected expired run dir to be removed")
+	}
+}
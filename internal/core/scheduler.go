@@ -0,0 +1,285 @@
+package core
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/danielmiessler/fabric/internal/chat"
+	"github.com/danielmiessler/fabric/internal/domain"
+	"github.com/danielmiessler/fabric/internal/plugins/db/fsdb"
+	"github.com/danielmiessler/fabric/internal/tools/cron"
+	"github.com/danielmiessler/fabric/internal/tools/notifications"
+)
+
+// Scheduler runs the enabled jobs of the jobs directory on their cron schedules. Results are
+// stored in the run history.
+type Scheduler struct {
+	registry *PluginRegistry
+
+	mu      sync.Mutex
+	running map[string]bool
+	ctx     context.Context // cancels the running jobs
+	wg      sync.WaitGroup
+}
+
+func NewScheduler(registry *PluginRegistry) *Scheduler {
+	return &Scheduler{registry: registry, running: map[string]bool{}, ctx: context.Background()}
+}
+
+// Start checks the jobs every minute until the context is cancelled, cancelling it also cancels the running jobs
+func (o *Scheduler) Start(ctx context.Context) {
+	o.mu.Lock()
+	o.ctx = ctx
+	o.mu.Unlock()
+
+	last := time.Now()
+	ticker := time.NewTicker(time.Minute)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case now := <-ticker.C:
+			o.runDueJobs(last, now)
+			last = now
+		}
+	}
+}
+
+// runDueJobs starts the jobs and the backup scheduled in (from, to]
+func (o *Scheduler) runDueJobs(from, to time.Time) {
+	o.runDueBackup(from, to)
+
+	jobs, err := o.registry.Db.Jobs.GetJobs()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Scheduler: could not load jobs: %v\n", err)
+		return
+	}
+
+	for _, job := range jobs {
+		if !job.Enabled {
+			continue
+		}
+		schedule, parseErr := cron.Parse(job.Schedule)
+		if parseErr != nil {
+			fmt.Fprintf(os.Stderr, "Scheduler: job %s: %v\n", job.Name, parseErr)
+			continue
+		}
+		if next := schedule.Next(from); next.IsZero() || next.After(to) {
+			continue
+		}
+		name := job.Name
+		if startErr := o.StartJob(job, func(runErr error) {
+			if runErr != nil {
+				fmt.Fprintf(os.Stderr, "Scheduler: job %s failed: %v\n", name, runErr)
+			}
+		}); startErr != nil {
+			fmt.Fprintf(os.Stderr, "Scheduler: %v\n", startErr)
+		}
+	}
+}
+
+func (o *Scheduler) runDueBackup(from, to time.Time) {
+	settings, err := o.registry.Db.GetBackupSettings()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Scheduler: could not load backup settings: %v\n", err)
+		return
+	}
+	if !settings.Enabled {
+		return
+	}
+	schedule, err := cron.Parse(settings.Schedule)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Scheduler: backup: %v\n", err)
+		return
+	}
+	if next := schedule.Next(from); next.IsZero() || next.After(to) {
+		return
+	}
+	o.wg.Add(1)
+	go func() {
+		defer o.wg.Done()
+		directory, backupErr := o.registry.Db.BackupPath(settings.Directory)
+		if backupErr == nil {
+			_, backupErr = o.registry.Db.Backup(directory)
+		}
+		if backupErr != nil {
+			fmt.Fprintf(os.Stderr, "Scheduler: backup failed: %v\n", backupErr)
+		}
+	}()
+}
+
+// StartJob runs the job right away in the background, done, if set, gets its error when it completes. A job that is
+// still running is not started again. The job is waited for by Wait as soon as StartJob returns.
+func (o *Scheduler) StartJob(job *fsdb.Job, done func(err error)) (err error) {
+	var ctx context.Context
+	if ctx, err = o.reserve(job); err != nil {
+		return
+	}
+	go func() {
+		defer o.release(job)
+		runErr := o.execute(ctx, job)
+		if done != nil {
+			done(runErr)
+		}
+	}()
+	return
+}
+
+// reserve marks the job running and adds it to the jobs Wait waits for
+func (o *Scheduler) reserve(job *fsdb.Job) (ctx context.Context, err error) {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	if o.running[job.Name] {
+		err = fmt.Errorf("job %s is already running", job.Name)
+		return
+	}
+	o.running[job.Name] = true
+	o.wg.Add(1)
+	ctx = o.ctx
+	return
+}
+
+func (o *Scheduler) release(job *fsdb.Job) {
+	o.mu.Lock()
+	delete(o.running, job.Name)
+	o.mu.Unlock()
+	o.wg.Done()
+}
+
+// execute runs the reserved job, recording its failure in the history and notifying it
+func (o *Scheduler) execute(ctx context.Context, job *fsdb.Job) (err error) {
+	var output string
+	if output, err = o.runJob(ctx, job); err != nil {
+		// successful runs are recorded by the chatter, failures are recorded here
+		run := &fsdb.Run{
+			JobName:      job.Name,
+			PatternName:  job.PatternName,
+			ContextName:  job.ContextName,
+			StrategyName: job.StrategyName,
+			Error:        err.Error(),
+		}
+		if saveErr := o.registry.Db.History.SaveRun(run); saveErr != nil {
+			fmt.Fprintf(os.Stderr, "Warning: failed to record run history: %v\n", saveErr)
+		}
+	}
+
+	if job.Notify {
+		o.notify(job, output, err)
+	}
+	return
+}
+
+// Wait blocks until the running jobs have finished
+func (o *Scheduler) Wait() {
+	o.wg.Wait()
+}
+
+func (o *Scheduler) runJob(ctx context.Context, job *fsdb.Job) (output string, err error) {
+	var input string
+	if input, err = o.loadInput(job); err != nil {
+		return
+	}
+
+	var chatter *Chatter
+	if chatter, err = o.registry.GetChatter(job.Model, 0, job.Vendor, job.StrategyName, false, false); err != nil {
+		return
+	}
+
+	request := &domain.ChatRequest{
+		Message:          &chat.ChatCompletionMessage{Role: chat.ChatMessageRoleUser, Content: input},
+		PatternName:      job.PatternName,
+		PatternVariables: job.Variables,
+		ContextName:      job.ContextName,
+		StrategyName:     job.StrategyName,
+		Language:         job.Language,
+		JobName:          job.Name,
+		App:              "scheduler",
+	}
+	opts := &domain.ChatOptions{
+		Model:       job.Model,
+		Temperature: domain.DefaultTemperature,
+		TopP:        domain.DefaultTopP,
+	}
+
+	var session *fsdb.Session
+	if session, err = chatter.SendContext(ctx, request, opts); err != nil {
+		return
+	}
+	if lastMsg := session.GetLastMessage(); lastMsg != nil {
+		output = lastMsg.Content
+	}
+	return
+}
+
+func (o *Scheduler) loadInput(job *fsdb.Job) (ret string, err error) {
+	switch {
+	case job.InputURL != "":
+		if !o.registry.Jina.IsConfigured() {
+			err = fmt.Errorf("job %s uses a URL input but scraping is not configured, please set up Jina", job.Name)
+			return
+		}
+		ret, err = o.registry.Jina.ScrapeURL(job.InputURL)
+	case job.InputFile != "":
+		if ret, err = o.registry.Db.Jobs.ReadInput(job.InputFile); err != nil {
+			err = fmt.Errorf("could not read input file of job %s: %v", job.Name, err)
+		}
+	default:
+		ret = job.Input
+	}
+	return
+}
+
+func (o *Scheduler) notify(job *fsdb.Job, output string, runErr error) {
+	title := fmt.Sprintf("Fabric job %s completed", job.Name)
+	message := output
+	if runErr != nil {
+		title = fmt.Sprintf("Fabric job %s failed", job.Name)
+		message = runErr.Error()
+	}
+	message = strings.ReplaceAll(message, "\n", " ")
+	if runes := []rune(message); len(runes) > 100 {
+		message = string(runes[:100]) + "..."
+	}
+
+	manager := notifications.NewNotificationManager()
+	if !manager.IsAvailable() {
+		return
+	}
+	if err := manager.Send(title, message); err != nil {
+		fmt.Fprintf(os.Stderr, "Warning: failed to send notification: %v\n", err)
+	}
+}
+
+// Upcoming returns the names of the jobs scheduled per local day in [from, to)
+func (o *Scheduler) Upcoming(from, to time.Time) (ret map[string][]string, err error) {
+	var jobs []*fsdb.Job
+	if jobs, err = o.registry.Db.Jobs.GetJobs(); err != nil {
+		return
+	}
+
+	ret = map[string][]string{}
+	for _, job := range jobs {
+		if !job.Enabled {
+			continue
+		}
+		schedule, parseErr := cron.Parse(job.Schedule)
+		if parseErr != nil {
+			continue
+		}
+		// list a job once per day even if it runs several times, so continue with the next day
+		for next := schedule.Next(from.Add(-time.Minute)); !next.IsZero() && next.Before(to); {
+			next = next.Local()
+			day := next.Format(fsdb.HistoryDayLayout)
+			ret[day] = append(ret[day], job.Name)
+			nextDay := time.Date(next.Year(), next.Month(), next.Day()+1, 0, 0, 0, 0, next.Location())
+			next = schedule.Next(nextDay.Add(-time.Minute))
+		}
+	}
+	return
+}
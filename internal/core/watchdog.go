@@ -0,0 +1,167 @@
+package core
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"sync"
+	"time"
+)
+
+// keepAliveTimeout limits the check of the vendor endpoint when a request stalls
+const keepAliveTimeout = 5 * time.Second
+
+// StallState tells why a vendor request stalled, as far as the keep-alive check of its endpoint can tell
+type StallState string
+
+const (
+	// StallThinking is a stall with the vendor reachable, the model is likely still working
+	StallThinking StallState = "thinking"
+	// StallDead is a stall with the vendor unreachable, the connection is likely dead
+	StallDead StallState = "dead"
+	// StallUnknown is a stall of a vendor without a known endpoint to check
+	StallUnknown StallState = "unknown"
+)
+
+// StallEvent reports a vendor request without any tokens for the stall timeout
+type StallEvent struct {
+	Vendor  string
+	Model   string
+	State   StallState
+	Silence time.Duration // since the last token, or the start of the request
+}
+
+// StallAction is what to do with a stalled request
+type StallAction int
+
+const (
+	// StallWait keeps waiting, the stall is reported again after another stall timeout without tokens
+	StallWait StallAction = iota
+	// StallReconnect abandons the request and sends it again
+	StallReconnect
+	// StallCancel abandons the request, it fails with ErrStallCancelled
+	StallCancel
+)
+
+// ErrStallCancelled is the error of requests cancelled because they stalled
+var ErrStallCancelled = errors.New("the request stalled and was cancelled")
+
+// ParseStallAction parses the name of a StallAction: wait, reconnect or cancel
+func ParseStallAction(name string) (ret StallAction, err error) {
+	switch name {
+	case "wait":
+		ret = StallWait
+	case "reconnect":
+		ret = StallReconnect
+	case "cancel":
+		ret = StallCancel
+	default:
+		err = fmt.Errorf("invalid stall action %s, expected wait, reconnect or cancel", name)
+	}
+	return
+}
+
+// watchdog watches a vendor request for tokens. Touch is called for every token, when none arrived for the
+// timeout the endpoint of the vendor is checked and the stall is reported.
+type watchdog struct {
+	timeout  time.Duration
+	endpoint string
+	event    StallEvent
+	onStall  func(StallEvent) StallAction
+	cancel   context.CancelFunc
+
+	mu       sync.Mutex
+	last     time.Time
+	reported time.Time // the last report, stalls are reported once per timeout
+	action   StallAction
+}
+
+// Touch records the arrival of tokens
+func (o *watchdog) Touch() {
+	o.mu.Lock()
+	o.last = time.Now()
+	o.mu.Unlock()
+}
+
+// Action returns the action taken on the request, StallWait when it was not abandoned
+func (o *watchdog) Action() StallAction {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	return o.action
+}
+
+// run watches until the context is done, it cancels the request when the stall handler abandons it
+func (o *watchdog) run(ctx context.Context) {
+	o.Touch()
+	ticker := time.NewTicker(max(o.timeout/4, 10*time.Millisecond))
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case now := <-ticker.C:
+			o.mu.Lock()
+			since := o.last
+			if o.reported.After(since) {
+				since = o.reported
+			}
+			o.mu.Unlock()
+			if now.Sub(since) < o.timeout {
+				continue
+			}
+
+			event := o.event
+			event.State = checkEndpoint(ctx, o.endpoint)
+			o.mu.Lock()
+			event.Silence = time.Since(o.last).Round(time.Second)
+			o.reported = time.Now()
+			o.mu.Unlock()
+			if ctx.Err() != nil {
+				return
+			}
+
+			if action := o.onStall(event); action != StallWait {
+				o.mu.Lock()
+				o.action = action
+				o.mu.Unlock()
+				o.cancel()
+				return
+			}
+		}
+	}
+}
+
+// checkEndpoint tells a vendor still working from a dead connection: any HTTP answer of the endpoint,
+// even an error status, means it is reachable
+func checkEndpoint(ctx context.Context, endpoint string) StallState {
+	if endpoint == "" {
+		return StallUnknown
+	}
+	ctx, cancel := context.WithTimeout(ctx, keepAliveTimeout)
+	defer cancel()
+	request, err := http.NewRequestWithContext(ctx, http.MethodHead, endpoint, nil)
+	if err != nil {
+		return StallUnknown
+	}
+	response, err := http.DefaultClient.Do(request)
+	if err != nil {
+		return StallDead
+	}
+	response.Body.Close()
+	return StallThinking
+}
+
+// DescribeStall describes the stall for the user
+func DescribeStall(event StallEvent) string {
+	var reason string
+	switch event.State {
+	case StallThinking:
+		reason = "the vendor is reachable, the model may still be thinking"
+	case StallDead:
+		reason = "the vendor is unreachable, the connection may be dead"
+	default:
+		reason = "the vendor could not be checked"
+	}
+	return fmt.Sprintf("No tokens from %s for %s, %s", event.Vendor, event.Silence, reason)
+}
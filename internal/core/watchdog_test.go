@@ -0,0 +1,88 @@
+package core
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/danielmiessler/fabric/internal/chat"
+	"github.com/danielmiessler/fabric/internal/domain"
+	"github.com/danielmiessler/fabric/internal/plugins/db/fsdb"
+)
+
+// stallingSend blocks the first calls until their request is abandoned, the later ones answer
+func stallingSend(stalls int32) func(context.Context, []*chat.ChatCompletionMessage, *domain.ChatOptions) (string, error) {
+	var calls int32
+	return func(ctx context.Context, _ []*chat.ChatCompletionMessage, _ *domain.ChatOptions) (string, error) {
+		if atomic.AddInt32(&calls, 1) <= stalls {
+			<-ctx.Done()
+			return "", ctx.Err()
+		}
+		return "answer", nil
+	}
+}
+
+func TestChatter_Send_StallReconnect(t *testing.T) {
+	var events []StallEvent
+	chatter := &Chatter{
+		db:           fsdb.NewDb(t.TempDir()),
+		vendor:       &mockVendor{sendFunc: stallingSend(1)},
+		model:        "test-model",
+		StallTimeout: 50 * time.Millisecond,
+		OnStall: func(event StallEvent) StallAction {
+			events = append(events, event)
+			return StallReconnect
+		},
+	}
+	request := &domain.ChatRequest{
+		Message: &chat.ChatCompletionMessage{Role: chat.ChatMessageRoleUser, Content: "test message"},
+	}
+
+	session, err := chatter.Send(request, &domain.ChatOptions{Model: "test-model"})
+	if err != nil {
+		t.Fatalf("Send returned error: %v", err)
+	}
+	if session.GetLastMessage().Content != "answer" {
+		t.Errorf("unexpected message %q", session.GetLastMessage().Content)
+	}
+	if len(events) != 1 || events[0].Vendor != "mock" || events[0].Model != "test-model" || events[0].State != StallUnknown {
+		t.Errorf("unexpected stall events %+v", events)
+	}
+}
+
+func TestChatter_Send_StallCancel(t *testing.T) {
+	chatter := &Chatter{
+		db:           fsdb.NewDb(t.TempDir()),
+		vendor:       &mockVendor{sendFunc: stallingSend(1)},
+		model:        "test-model",
+		StallTimeout: 50 * time.Millisecond,
+		OnStall:      func(StallEvent) StallAction { return StallCancel },
+	}
+	request := &domain.ChatRequest{
+		Message: &chat.ChatCompletionMessage{Role: chat.ChatMessageRoleUser, Content: "test message"},
+	}
+
+	if _, err := chatter.Send(request, &domain.ChatOptions{Model: "test-model"}); !errors.Is(err, ErrStallCancelled) {
+		t.Errorf("expected ErrStallCancelled, got %v", err)
+	}
+}
+
+func TestCheckEndpoint(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusUnauthorized)
+	}))
+	if state := checkEndpoint(context.Background(), server.URL); state != StallThinking {
+		t.Errorf("expected a reachable endpoint to be thinking, got %s", state)
+	}
+	server.Close()
+	if state := checkEndpoint(context.Background(), server.URL); state != StallDead {
+		t.Errorf("expected a closed endpoint to be dead, got %s", state)
+	}
+	if state := checkEndpoint(context.Background(), ""); state != StallUnknown {
+		t.Errorf("expected no endpoint to be unknown, got %s", state)
+	}
+}
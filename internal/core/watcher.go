@@ -0,0 +1,257 @@
+package core
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/danielmiessler/fabric/internal/chat"
+	"github.com/danielmiessler/fabric/internal/domain"
+	"github.com/danielmiessler/fabric/internal/plugins/db/fsdb"
+	"github.com/danielmiessler/fabric/internal/tools/watch"
+)
+
+const (
+	// DefaultWatchInterval is how often the watched directories are polled
+	DefaultWatchInterval = 5 * time.Second
+
+	maxWatchEvents = 200
+)
+
+// Status of a file of a watched directory
+const (
+	WatchEventRunning = "running"
+	WatchEventDone    = "done"
+	WatchEventFailed  = "failed"
+)
+
+// WatchEvent is the processing of a file of a watched directory
+type WatchEvent struct {
+	Time   time.Time `json:"time"`
+	Watch  string    `json:"watch"`
+	File   string    `json:"file"`
+	Output string    `json:"output,omitempty"`
+	Status string    `json:"status"`
+	Error  string    `json:"error,omitempty"`
+}
+
+// Watcher runs the patterns of the enabled watches on the new and changed files of their directories,
+// writing the outputs to their output directories. Runs are stored in the run history.
+type Watcher struct {
+	registry *PluginRegistry
+
+	mu       sync.Mutex
+	dirs     map[string]*watchedDir
+	running  map[string]bool
+	activity []*WatchEvent // the latest last
+	wg       sync.WaitGroup
+}
+
+type watchedDir struct {
+	dir       *watch.Dir
+	signature string // the watched directory changes with the settings of the watch
+	lastError string
+}
+
+func NewWatcher(registry *PluginRegistry) *Watcher {
+	return &Watcher{registry: registry, dirs: map[string]*watchedDir{}, running: map[string]bool{}}
+}
+
+// Start polls the watched directories every interval until the context is cancelled, cancelling it also
+// cancels the running patterns
+func (o *Watcher) Start(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			o.poll(ctx)
+		}
+	}
+}
+
+// Wait blocks until the files being processed are done
+func (o *Watcher) Wait() {
+	o.wg.Wait()
+}
+
+// Activity returns the latest events of the watch, of all watches when name is empty, the latest first
+func (o *Watcher) Activity(name string) (ret []*WatchEvent) {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+
+	ret = []*WatchEvent{}
+	for i := len(o.activity) - 1; i >= 0; i-- {
+		if event := o.activity[i]; name == "" || event.Watch == name {
+			copied := *event
+			ret = append(ret, &copied)
+		}
+	}
+	return
+}
+
+func (o *Watcher) poll(ctx context.Context) {
+	watches, err := o.registry.Db.Watches.GetWatches()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Watcher: could not load watches: %v\n", err)
+		return
+	}
+
+	o.mu.Lock()
+	defer o.mu.Unlock()
+
+	enabled := map[string]bool{}
+	for _, item := range watches {
+		if !item.Enabled {
+			continue
+		}
+		enabled[item.Name] = true
+		// the files of a watch still processing wait, they are still pending at the next poll
+		if o.running[item.Name] {
+			continue
+		}
+
+		signature := fmt.Sprintf("%s|%s", filepath.Clean(item.Directory), strings.Join(item.Extensions, ","))
+		watched := o.dirs[item.Name]
+		if watched == nil || watched.signature != signature {
+			watched = &watchedDir{dir: watch.NewDir(item.Directory, item.Extensions), signature: signature}
+			o.dirs[item.Name] = watched
+		}
+
+		ready, pollErr := watched.dir.Poll()
+		if pollErr != nil {
+			// reported once, not at every poll
+			if pollErr.Error() != watched.lastError {
+				watched.lastError = pollErr.Error()
+				o.addEvent(&WatchEvent{Time: time.Now(), Watch: item.Name, File: item.Directory, Status: WatchEventFailed, Error: pollErr.Error()})
+			}
+			continue
+		}
+		watched.lastError = ""
+		if len(ready) == 0 {
+			continue
+		}
+
+		o.running[item.Name] = true
+		o.wg.Add(1)
+		go o.process(ctx, item, ready)
+	}
+
+	// disabled watches start over when enabled again, the files added meanwhile are not new
+	for name := range o.dirs {
+		if !enabled[name] {
+			delete(o.dirs, name)
+		}
+	}
+}
+
+// process runs the pattern of the watch on the files one after the other
+func (o *Watcher) process(ctx context.Context, item *fsdb.Watch, files []string) {
+	defer func() {
+		o.mu.Lock()
+		delete(o.running, item.Name)
+		o.mu.Unlock()
+		o.wg.Done()
+	}()
+
+	for _, file := range files {
+		if ctx.Err() != nil {
+			return
+		}
+		event := &WatchEvent{Time: time.Now(), Watch: item.Name, File: file, Status: WatchEventRunning}
+		o.mu.Lock()
+		o.addEvent(event)
+		o.mu.Unlock()
+
+		output, err := o.processFile(ctx, item, file)
+
+		o.mu.Lock()
+		event.Time = time.Now()
+		if err != nil {
+			event.Status = WatchEventFailed
+			event.Error = err.Error()
+		} else {
+			event.Status = WatchEventDone
+			event.Output = output
+		}
+		o.mu.Unlock()
+	}
+}
+
+func (o *Watcher) processFile(ctx context.Context, item *fsdb.Watch, file string) (outputPath string, err error) {
+	// checked again, the directories of watches.json may have been edited or the roots changed
+	if err = item.CheckDirs(); err != nil {
+		return
+	}
+	var content []byte
+	if content, err = readWatchedFile(file); err != nil {
+		return
+	}
+
+	var chatter *Chatter
+	if chatter, err = o.registry.GetChatter(item.Model, 0, item.Vendor, item.StrategyName, false, false); err != nil {
+		return
+	}
+
+	request := &domain.ChatRequest{
+		Message:          &chat.ChatCompletionMessage{Role: chat.ChatMessageRoleUser, Content: string(content)},
+		PatternName:      item.PatternName,
+		PatternVariables: item.Variables,
+		ContextName:      item.ContextName,
+		StrategyName:     item.StrategyName,
+		Language:         item.Language,
+		App:              "watch",
+	}
+	opts := &domain.ChatOptions{
+		Model:       item.Model,
+		Temperature: domain.DefaultTemperature,
+		TopP:        domain.DefaultTopP,
+	}
+
+	var session *fsdb.Session
+	if session, err = chatter.SendContext(ctx, request, opts); err != nil {
+		return
+	}
+	var output string
+	if lastMsg := session.GetLastMessage(); lastMsg != nil {
+		output = lastMsg.Content
+	}
+
+	if err = os.MkdirAll(item.OutputDirectory, os.ModePerm); err != nil {
+		return
+	}
+	outputPath = watch.OutputPath(item.OutputDirectory, file)
+	err = os.WriteFile(outputPath, []byte(output), 0644)
+	return
+}
+
+// readWatchedFile reads the file unless it is over fsdb.MaxWatchFileSize
+func readWatchedFile(file string) (ret []byte, err error) {
+	var f *os.File
+	if f, err = os.Open(file); err != nil {
+		return
+	}
+	defer f.Close()
+
+	if ret, err = io.ReadAll(io.LimitReader(f, fsdb.MaxWatchFileSize+1)); err == nil && len(ret) > fsdb.MaxWatchFileSize {
+		ret = nil
+		err = fmt.Errorf("%s is over the %d MB a watch sends to the model", filepath.Base(file), fsdb.MaxWatchFileSize>>20)
+	}
+	return
+}
+
+// addEvent keeps the latest events, o.mu is held by the caller
+func (o *Watcher) addEvent(event *WatchEvent) {
+	o.activity = append(o.activity, event)
+	if len(o.activity) > maxWatchEvents {
+		o.activity = o.activity[len(o.activity)-maxWatchEvents:]
+	}
+}
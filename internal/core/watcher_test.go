@@ -0,0 +1,25 @@
+package core
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/danielmiessler/fabric/internal/plugins/db/fsdb"
+)
+
+func TestReadWatchedFile(t *testing.T) {
+	dir := t.TempDir()
+	small := filepath.Join(dir, "small.md")
+	os.WriteFile(small, []byte("notes"), 0644)
+	large := filepath.Join(dir, "large.md")
+	os.WriteFile(large, []byte(strings.Repeat("a", fsdb.MaxWatchFileSize+1)), 0644)
+
+	if content, err := readWatchedFile(small); err != nil || string(content) != "notes" {
+		t.Errorf("unexpected content %q, %v", content, err)
+	}
+	if _, err := readWatchedFile(large); err == nil {
+		t.Error("expected the file over the size limit to be refused")
+	}
+}
@@ -0,0 +1,70 @@
+package demo
+
+import (
+	"embed"
+	"fmt"
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	demovendor "github.com/danielmiessler/fabric/internal/plugins/ai/demo"
+)
+
+//go:embed data
+var data embed.FS
+
+// Input is a canned input shipped with the demo
+type Input struct {
+	Name    string `json:"name"`
+	Content string `json:"content"`
+}
+
+// Setup writes the bundled patterns, the canned inputs (to configDir/inputs) and an .env selecting the
+// demo vendor into configDir. Existing files are overwritten, so configDir should be used only for the demo.
+func Setup(configDir string) (err error) {
+	if err = fs.WalkDir(data, "data", func(name string, entry fs.DirEntry, walkErr error) (err error) {
+		if walkErr != nil || entry.IsDir() {
+			return walkErr
+		}
+		var content []byte
+		if content, err = data.ReadFile(name); err != nil {
+			return
+		}
+		target := filepath.Join(configDir, filepath.FromSlash(strings.TrimPrefix(name, "data/")))
+		if err = os.MkdirAll(filepath.Dir(target), os.ModePerm); err != nil {
+			return
+		}
+		return os.WriteFile(target, content, 0644)
+	}); err != nil {
+		return fmt.Errorf("could not set up demo data: %v", err)
+	}
+
+	env := fmt.Sprintf("DEFAULT_VENDOR=%s\nDEFAULT_MODEL=%s\n", demovendor.VendorName, demovendor.ModelName)
+	return os.WriteFile(filepath.Join(configDir, ".env"), []byte(env), 0644)
+}
+
+// PatternNames lists the bundled patterns
+func PatternNames() (ret []string) {
+	entries, _ := data.ReadDir("data/patterns")
+	for _, entry := range entries {
+		ret = append(ret, entry.Name())
+	}
+	return
+}
+
+// Inputs returns the canned inputs sorted by name
+func Inputs() (ret []Input) {
+	entries, _ := data.ReadDir("data/inputs")
+	for _, entry := range entries {
+		content, err := data.ReadFile(path.Join("data/inputs", entry.Name()))
+		if err != nil {
+			continue
+		}
+		ret = append(ret, Input{Name: strings.TrimSuffix(entry.Name(), ".md"), Content: string(content)})
+	}
+	sort.Slice(ret, func(i, j int) bool { return ret[i].Name < ret[j].Name })
+	return
+}
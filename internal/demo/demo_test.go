@@ -0,0 +1,46 @@
+package demo
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestSetup(t *testing.T) {
+	dir := t.TempDir()
+	if err := Setup(dir); err != nil {
+		t.Fatalf("Setup failed: %v", err)
+	}
+
+	for _, name := range PatternNames() {
+		if _, err := os.Stat(filepath.Join(dir, "patterns", name, "system.md")); err != nil {
+			t.Errorf("expected pattern %s to be written: %v", name, err)
+		}
+	}
+	for _, input := range Inputs() {
+		if _, err := os.Stat(filepath.Join(dir, "inputs", input.Name+".md")); err != nil {
+			t.Errorf("expected input %s to be written: %v", input.Name, err)
+		}
+	}
+
+	env, err := os.ReadFile(filepath.Join(dir, ".env"))
+	if err != nil || !strings.Contains(string(env), "DEFAULT_VENDOR=Demo") {
+		t.Errorf("expected .env selecting the demo vendor, got %q, %v", env, err)
+	}
+}
+
+func TestBundledData(t *testing.T) {
+	if names := PatternNames(); len(names) == 0 {
+		t.Error("expected bundled patterns")
+	}
+	inputs := Inputs()
+	if len(inputs) == 0 {
+		t.Fatal("expected canned inputs")
+	}
+	for _, input := range inputs {
+		if strings.TrimSpace(input.Content) == "" {
+			t.Errorf("canned input %s is empty", input.Name)
+		}
+	}
+}
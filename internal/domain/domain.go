@@ -22,6 +22,9 @@ type ChatRequest struct {
 	Meta             string
 	InputHasVars     bool
 	StrategyName     string
+	JobName          string // set for scheduled runs, recorded in the run history
+	RunDir           string // working directory of the hook commands, set by the chatter for the run
+	App              string // the client running the request, e.g. "cli" or "web 1.4.0", recorded in the run environment
 }
 
 type ChatOptions struct {
@@ -35,6 +38,7 @@ type ChatOptions struct {
 	Thinking            ThinkingLevel
 	ModelContextLength  int
 	MaxTokens           int
+	StopSequences       []string
 	Search              bool
 	SearchLocation      string
 	ImageFile           string
@@ -50,6 +54,7 @@ type ChatOptions struct {
 	Voice               string
 	Notification        bool
 	NotificationCommand string
+	StreamFile          string // the output is also written to this file as it arrives
 }
 
 // NormalizeMessages remove empty messages and ensure messages order user-assist-user
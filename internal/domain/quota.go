@@ -0,0 +1,31 @@
+package domain
+
+const (
+	// QuotaWarningShare is the share of a spending cap from which the quota is near the cap
+	QuotaWarningShare = 0.9
+	// QuotaLowBalance is the prepaid balance, in the currency of the vendor, below which the quota is near the cap
+	QuotaLowBalance = 1.0
+)
+
+// Quota is the spend and remaining quota a vendor reports for the configured API key. The fields
+// a vendor doesn't report are nil, Limit is nil for keys without a spending cap.
+type Quota struct {
+	Vendor    string   `json:"vendor"`
+	Currency  string   `json:"currency,omitempty"` // e.g. USD, or credits
+	Spent     *float64 `json:"spent,omitempty"`
+	Limit     *float64 `json:"limit,omitempty"`
+	Remaining *float64 `json:"remaining,omitempty"` // left of the limit, or the prepaid balance
+	NearCap   bool     `json:"nearCap"`
+	Error     string   `json:"error,omitempty"` // set when the quota could not be fetched
+}
+
+// IsNearCap reports whether the spend is close to the cap, or the prepaid balance is running out
+func (o *Quota) IsNearCap() bool {
+	if o.Remaining == nil {
+		return false
+	}
+	if o.Limit != nil && *o.Limit > 0 {
+		return *o.Remaining <= *o.Limit*(1-QuotaWarningShare)
+	}
+	return *o.Remaining <= QuotaLowBalance
+}
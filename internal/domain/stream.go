@@ -0,0 +1,34 @@
+package domain
+
+// StreamChunkType is the kind of a StreamChunk
+type StreamChunkType string
+
+const (
+	// StreamText is a piece of the answer
+	StreamText StreamChunkType = "text"
+	// StreamReasoning is a piece of the reasoning of a thinking model, not part of the answer
+	StreamReasoning StreamChunkType = "reasoning"
+	// StreamToolCall is a tool the model called, like the web search of the vendor
+	StreamToolCall StreamChunkType = "tool_call"
+	// StreamDone ends a complete stream, with the usage when the vendor reports it
+	StreamDone StreamChunkType = "done"
+	// StreamError ends a failed stream
+	StreamError StreamChunkType = "error"
+)
+
+// StreamChunk is an event of the response stream of a vendor, normalized so the clients handle the
+// streams of all the vendors the same way
+type StreamChunk struct {
+	Type     StreamChunkType `json:"type"`
+	Text     string          `json:"text,omitempty"`     // of text and reasoning chunks, the message of error chunks
+	ToolCall *ToolCall       `json:"toolCall,omitempty"` // of tool_call chunks
+	Usage    *Usage          `json:"usage,omitempty"`    // of done chunks
+	Err      error           `json:"-"`                  // of error chunks
+}
+
+// ToolCall is a tool called by the model, Arguments is the JSON the model called it with
+type ToolCall struct {
+	Id        string `json:"id,omitempty"`
+	Name      string `json:"name"`
+	Arguments string `json:"arguments,omitempty"`
+}
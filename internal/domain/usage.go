@@ -0,0 +1,54 @@
+package domain
+
+import (
+	"crypto/sha256"
+	"fmt"
+)
+
+// Usage is the token usage and finish reason a vendor reports for a response
+type Usage struct {
+	PromptTokens     int    `json:"promptTokens,omitempty"`
+	CompletionTokens int    `json:"completionTokens,omitempty"`
+	FinishReason     string `json:"finishReason,omitempty"`
+}
+
+// ExecutionMetadata describes a completed model call. Usage is only set for vendors that report it.
+// The sampling parameters are kept so a run can be replayed with identical parameters.
+type ExecutionMetadata struct {
+	Model     string `json:"model"`
+	Vendor    string `json:"vendor"`
+	Strategy  string `json:"strategy,omitempty"`
+	LatencyMs int64  `json:"latencyMs"`
+	Usage
+
+	Seed             int      `json:"seed,omitempty"` // only honored by vendors supporting it
+	Temperature      float64  `json:"temperature"`
+	TopP             float64  `json:"topP"`
+	PresencePenalty  float64  `json:"presencePenalty,omitempty"`
+	FrequencyPenalty float64  `json:"frequencyPenalty,omitempty"`
+	MaxTokens        int      `json:"maxTokens,omitempty"`
+	StopSequences    []string `json:"stopSequences,omitempty"`
+
+	RunDir string `json:"runDir,omitempty"` // set while the run directory is retained
+	RunId  string `json:"runId,omitempty"`  // the run in the history, set once it is recorded
+
+	Environment *Environment `json:"environment,omitempty"`
+}
+
+// Environment is a snapshot of the configuration a run was executed with, to reproduce the run and to
+// find out why the same run gives different results on two machines. The hashes are the SHA-256 of the
+// pattern, strategy and context files, so a changed file shows without keeping its content.
+type Environment struct {
+	FabricVersion  string `json:"fabricVersion,omitempty"`
+	App            string `json:"app,omitempty"` // the client that started the run, e.g. "cli" or "web 1.4.0"
+	Platform       string `json:"platform"`      // GOOS/GOARCH
+	VendorEndpoint string `json:"vendorEndpoint,omitempty"`
+	PatternHash    string `json:"patternHash,omitempty"`
+	StrategyHash   string `json:"strategyHash,omitempty"`
+	ContextHash    string `json:"contextHash,omitempty"`
+}
+
+// ContentHash is the hex SHA-256 of the content, as recorded in the Environment
+func ContentHash(content string) string {
+	return fmt.Sprintf("%x", sha256.Sum256([]byte(content)))
+}
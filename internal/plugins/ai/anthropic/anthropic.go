@@ -10,6 +10,7 @@ import (
 
 	"github.com/anthropics/anthropic-sdk-go"
 	"github.com/anthropics/anthropic-sdk-go/option"
+	"github.com/anthropics/anthropic-sdk-go/packages/ssestream"
 	"github.com/danielmiessler/fabric/internal/chat"
 	"github.com/danielmiessler/fabric/internal/domain"
 	"github.com/danielmiessler/fabric/internal/plugins"
@@ -151,6 +152,11 @@ func (an *Client) configure() (err error) {
 	return
 }
 
+// Endpoint is the API base URL, see ai.EndpointReporter
+func (an *Client) Endpoint() string {
+	return an.ApiBaseURL.Value
+}
+
 func (an *Client) ListModels() (ret []string, err error) {
 	return an.models, nil
 }
@@ -185,20 +191,7 @@ func (an *Client) SendStream(
 		return
 	}
 
-	ctx := context.Background()
-
-	params := an.buildMessageParams(messages, opts)
-	betas := an.modelBetas[opts.Model]
-	var reqOpts []option.RequestOption
-	if len(betas) > 0 {
-		reqOpts = append(reqOpts, option.WithHeader("anthropic-beta", strings.Join(betas, ",")))
-	}
-	stream := an.client.Messages.NewStreaming(ctx, params, reqOpts...)
-	if stream.Err() != nil && len(betas) > 0 {
-		fmt.Fprintf(os.Stderr, "Anthropic beta feature %s failed: %v\n", strings.Join(betas, ","), stream.Err())
-		stream = an.client.Messages.NewStreaming(ctx, params)
-	}
-
+	stream := an.newStream(messages, opts)
 	for stream.Next() {
 		event := stream.Current()
 
@@ -215,13 +208,84 @@ func (an *Client) SendStream(
 	return
 }
 
+// SendStreamChunks streams the answer, the thinking and the tool calls of the model as separate chunks,
+// ending with the usage of the response
+func (an *Client) SendStreamChunks(
+	msgs []*chat.ChatCompletionMessage, opts *domain.ChatOptions, channel chan domain.StreamChunk,
+) (err error) {
+	defer close(channel)
+	messages := an.toMessages(msgs)
+	if len(messages) == 0 {
+		return
+	}
+
+	stream := an.newStream(messages, opts)
+	usage := &domain.Usage{}
+	// the arguments of a tool call are streamed in pieces, it is sent once its block ends
+	var toolCall *domain.ToolCall
+	for stream.Next() {
+		event := stream.Current()
+		switch event.Type {
+		case "message_start":
+			usage.PromptTokens = int(event.Message.Usage.InputTokens)
+		case "content_block_start":
+			if block := event.ContentBlock; block.Type == "tool_use" || block.Type == "server_tool_use" {
+				toolCall = &domain.ToolCall{Id: block.ID, Name: block.Name}
+			}
+		case "content_block_delta":
+			switch {
+			case event.Delta.Text != "":
+				channel <- domain.StreamChunk{Type: domain.StreamText, Text: event.Delta.Text}
+			case event.Delta.Thinking != "":
+				channel <- domain.StreamChunk{Type: domain.StreamReasoning, Text: event.Delta.Thinking}
+			case event.Delta.PartialJSON != "" && toolCall != nil:
+				toolCall.Arguments += event.Delta.PartialJSON
+			}
+		case "content_block_stop":
+			if toolCall != nil {
+				channel <- domain.StreamChunk{Type: domain.StreamToolCall, ToolCall: toolCall}
+				toolCall = nil
+			}
+		case "message_delta":
+			usage.CompletionTokens = int(event.Usage.OutputTokens)
+			usage.FinishReason = string(event.Delta.StopReason)
+		}
+	}
+	if err = stream.Err(); err != nil {
+		return
+	}
+	channel <- domain.StreamChunk{Type: domain.StreamDone, Usage: usage}
+	return
+}
+
+// newStream starts streaming the response, without the beta features of the model if they fail
+func (an *Client) newStream(messages []anthropic.MessageParam, opts *domain.ChatOptions) *ssestream.Stream[anthropic.MessageStreamEventUnion] {
+	ctx := context.Background()
+	params := an.buildMessageParams(messages, opts)
+	betas := an.modelBetas[opts.Model]
+	var reqOpts []option.RequestOption
+	if len(betas) > 0 {
+		reqOpts = append(reqOpts, option.WithHeader("anthropic-beta", strings.Join(betas, ",")))
+	}
+	stream := an.client.Messages.NewStreaming(ctx, params, reqOpts...)
+	if stream.Err() != nil && len(betas) > 0 {
+		fmt.Fprintf(os.Stderr, "Anthropic beta feature %s failed: %v\n", strings.Join(betas, ","), stream.Err())
+		stream = an.client.Messages.NewStreaming(ctx, params)
+	}
+	return stream
+}
+
 func (an *Client) buildMessageParams(msgs []anthropic.MessageParam, opts *domain.ChatOptions) (
 	params anthropic.MessageNewParams) {
 
 	params = anthropic.MessageNewParams{
-		Model:     anthropic.Model(opts.Model),
-		MaxTokens: int64(an.maxTokens),
-		Messages:  msgs,
+		Model:         anthropic.Model(opts.Model),
+		MaxTokens:     int64(an.maxTokens),
+		Messages:      msgs,
+		StopSequences: opts.StopSequences,
+	}
+	if opts.MaxTokens > 0 {
+		params.MaxTokens = int64(opts.MaxTokens)
 	}
 
 	// Only set one of Temperature or TopP as some models don't allow both
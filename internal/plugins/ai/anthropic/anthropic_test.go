@@ -325,3 +325,28 @@ func TestBuildMessageParams_ExplicitTopP(t *testing.T) {
 		t.Errorf("Expected TopP %f, got %f", opts.TopP, params.TopP.Value)
 	}
 }
+
+func TestBuildMessageParams_MaxTokensAndStopSequences(t *testing.T) {
+	client := NewClient()
+	messages := []anthropic.MessageParam{
+		anthropic.NewUserMessage(anthropic.NewTextBlock("Hello")),
+	}
+
+	params := client.buildMessageParams(messages, &domain.ChatOptions{Model: "claude-3-5-sonnet-latest"})
+	if params.MaxTokens != int64(client.maxTokens) {
+		t.Errorf("Expected the client max tokens %d by default, got %d", client.maxTokens, params.MaxTokens)
+	}
+
+	opts := &domain.ChatOptions{
+		Model:         "claude-3-5-sonnet-latest",
+		MaxTokens:     256,
+		StopSequences: []string{"END", "\n\n---"},
+	}
+	params = client.buildMessageParams(messages, opts)
+	if params.MaxTokens != 256 {
+		t.Errorf("Expected max tokens 256, got %d", params.MaxTokens)
+	}
+	if len(params.StopSequences) != 2 || params.StopSequences[0] != "END" {
+		t.Errorf("Expected the stop sequences to be passed, got %v", params.StopSequences)
+	}
+}
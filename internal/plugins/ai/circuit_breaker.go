@@ -0,0 +1,152 @@
+package ai
+
+import (
+	"sort"
+	"sync"
+	"time"
+)
+
+// Default circuit breaker tuning: trip after this many failures inside the
+// window, then stop routing requests to the vendor for the cool-down period.
+const (
+	DefaultBreakerFailureThreshold = 3
+	DefaultBreakerWindow           = 5 * time.Minute
+	DefaultBreakerCooldown         = 2 * time.Minute
+)
+
+const (
+	VendorStatusHealthy  = "healthy"
+	VendorStatusDegraded = "degraded"
+)
+
+// VendorHealth describes the circuit breaker state of a single vendor
+type VendorHealth struct {
+	Vendor     string    `json:"vendor"`
+	Status     string    `json:"status"`
+	Failures   int       `json:"failures"`
+	LastError  string    `json:"lastError,omitempty"`
+	RetryAfter time.Time `json:"retryAfter,omitempty"`
+}
+
+type breakerState struct {
+	failures  []time.Time
+	openUntil time.Time
+	lastError string
+}
+
+// CircuitBreaker tracks vendor failures and marks a vendor as degraded when it
+// fails repeatedly within a time window. A degraded vendor is skipped until
+// its cool-down period expires.
+type CircuitBreaker struct {
+	FailureThreshold int
+	Window           time.Duration
+	Cooldown         time.Duration
+
+	mu     sync.Mutex
+	states map[string]*breakerState
+	now    func() time.Time
+}
+
+func NewCircuitBreaker(failureThreshold int, window, cooldown time.Duration) *CircuitBreaker {
+	return &CircuitBreaker{
+		FailureThreshold: failureThreshold,
+		Window:           window,
+		Cooldown:         cooldown,
+		states:           map[string]*breakerState{},
+		now:              time.Now,
+	}
+}
+
+// Allow reports whether requests may be routed to the vendor
+func (o *CircuitBreaker) Allow(vendorName string) bool {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+
+	state := o.states[vendorName]
+	if state == nil || state.openUntil.IsZero() {
+		return true
+	}
+	if o.now().Before(state.openUntil) {
+		return false
+	}
+	// cool-down is over, give the vendor another chance
+	state.openUntil = time.Time{}
+	state.failures = nil
+	return true
+}
+
+// RecordSuccess resets the failure history of the vendor
+func (o *CircuitBreaker) RecordSuccess(vendorName string) {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	delete(o.states, vendorName)
+}
+
+// RecordFailure registers a failed request and trips the breaker once the
+// threshold is reached inside the window. It returns true if the breaker tripped.
+func (o *CircuitBreaker) RecordFailure(vendorName string, err error) (tripped bool) {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+
+	now := o.now()
+	state := o.states[vendorName]
+	if state == nil {
+		state = &breakerState{}
+		o.states[vendorName] = state
+	}
+	if err != nil {
+		state.lastError = err.Error()
+	}
+
+	// keep only the failures inside the window
+	windowStart := now.Add(-o.Window)
+	recent := state.failures[:0]
+	for _, failure := range state.failures {
+		if failure.After(windowStart) {
+			recent = append(recent, failure)
+		}
+	}
+	state.failures = append(recent, now)
+
+	if len(state.failures) >= o.FailureThreshold && state.openUntil.IsZero() {
+		state.openUntil = now.Add(o.Cooldown)
+		tripped = true
+	}
+	return
+}
+
+// Health returns the breaker state of the given vendor
+func (o *CircuitBreaker) Health(vendorName string) (ret VendorHealth) {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	return o.health(vendorName)
+}
+
+// HealthAll returns the breaker state of every vendor with recorded failures, sorted by vendor name
+func (o *CircuitBreaker) HealthAll() (ret []VendorHealth) {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+
+	for vendorName := range o.states {
+		ret = append(ret, o.health(vendorName))
+	}
+	sort.Slice(ret, func(i, j int) bool {
+		return ret[i].Vendor < ret[j].Vendor
+	})
+	return
+}
+
+func (o *CircuitBreaker) health(vendorName string) (ret VendorHealth) {
+	ret = VendorHealth{Vendor: vendorName, Status: VendorStatusHealthy}
+	state := o.states[vendorName]
+	if state == nil {
+		return
+	}
+	ret.Failures = len(state.failures)
+	ret.LastError = state.lastError
+	if !state.openUntil.IsZero() && o.now().Before(state.openUntil) {
+		ret.Status = VendorStatusDegraded
+		ret.RetryAfter = state.openUntil
+	}
+	return
+}
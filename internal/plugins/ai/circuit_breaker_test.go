@@ -0,0 +1,65 @@
+package ai
+
+import (
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestCircuitBreaker_TripsAfterThreshold(t *testing.T) {
+	now := time.Date(2025, 1, 1, 12, 0, 0, 0, time.UTC)
+	breaker := NewCircuitBreaker(3, time.Minute, 2*time.Minute)
+	breaker.now = func() time.Time { return now }
+
+	for i := 0; i < 2; i++ {
+		if breaker.RecordFailure("vendor", errors.New("boom")) {
+			t.Fatalf("breaker tripped after %d failures", i+1)
+		}
+	}
+	if !breaker.Allow("vendor") {
+		t.Fatal("expected vendor to be allowed below the threshold")
+	}
+	if !breaker.RecordFailure("vendor", errors.New("boom")) {
+		t.Fatal("expected breaker to trip on the third failure")
+	}
+	if breaker.Allow("vendor") {
+		t.Fatal("expected degraded vendor to be skipped")
+	}
+
+	health := breaker.Health("vendor")
+	if health.Status != VendorStatusDegraded || health.LastError != "boom" {
+		t.Errorf("unexpected health %+v", health)
+	}
+
+	now = now.Add(3 * time.Minute)
+	if !breaker.Allow("vendor") {
+		t.Fatal("expected vendor to be allowed after the cool-down")
+	}
+	if health = breaker.Health("vendor"); health.Status != VendorStatusHealthy {
+		t.Errorf("expected healthy vendor after cool-down, got %+v", health)
+	}
+}
+
+func TestCircuitBreaker_FailuresOutsideWindowAreIgnored(t *testing.T) {
+	now := time.Date(2025, 1, 1, 12, 0, 0, 0, time.UTC)
+	breaker := NewCircuitBreaker(2, time.Minute, time.Minute)
+	breaker.now = func() time.Time { return now }
+
+	breaker.RecordFailure("vendor", nil)
+	now = now.Add(2 * time.Minute)
+	if breaker.RecordFailure("vendor", nil) {
+		t.Fatal("failures outside the window must not trip the breaker")
+	}
+}
+
+func TestCircuitBreaker_SuccessResets(t *testing.T) {
+	breaker := NewCircuitBreaker(2, time.Minute, time.Minute)
+	breaker.RecordFailure("vendor", nil)
+	breaker.RecordSuccess("vendor")
+	if breaker.RecordFailure("vendor", nil) {
+		t.Fatal("success should reset the failure count")
+	}
+	if len(breaker.HealthAll()) != 1 {
+		t.Errorf("expected one tracked vendor, got %d", len(breaker.HealthAll()))
+	}
+}
@@ -0,0 +1,171 @@
+// Package compat normalizes the response streams of the vendors to domain.StreamChunk events, so the
+// CLI, the REST API and the web UI handle the deltas, thinking blocks and tool calls of every vendor the
+// same way.
+package compat
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/danielmiessler/fabric/internal/chat"
+	"github.com/danielmiessler/fabric/internal/domain"
+	"github.com/danielmiessler/fabric/internal/plugins/ai"
+)
+
+// Default tags of the reasoning the models write in their answer
+const (
+	DefaultThinkStartTag = "<think>"
+	DefaultThinkEndTag   = "</think>"
+)
+
+// ChunkStreamer is implemented by the vendors whose stream tells the reasoning, the tool calls and the
+// usage apart from the answer. The vendor closes the channel once the stream ends, its done chunk only
+// carries the usage; the chunk ending the stream is sent by Stream.
+type ChunkStreamer interface {
+	SendStreamChunks([]*chat.ChatCompletionMessage, *domain.ChatOptions, chan domain.StreamChunk) error
+}
+
+// ThinkTags returns the tags of the reasoning of the options, the default ones when they aren't set
+func ThinkTags(opts *domain.ChatOptions) (start string, end string) {
+	start, end = opts.ThinkStartTag, opts.ThinkEndTag
+	if start == "" || end == "" {
+		start, end = DefaultThinkStartTag, DefaultThinkEndTag
+	}
+	return
+}
+
+// Stream sends the messages to the vendor and sends its response to chunks as it arrives, then closes
+// chunks. The stream always ends with a done or an error chunk. The vendors streaming plain text get
+// their reasoning, written between the think tags, split off the answer.
+func Stream(vendor ai.Vendor, msgs []*chat.ChatCompletionMessage, opts *domain.ChatOptions, chunks chan<- domain.StreamChunk) {
+	defer close(chunks)
+
+	var usage *domain.Usage
+	var err error
+	if streamer, ok := vendor.(ChunkStreamer); ok {
+		vendorChunks := make(chan domain.StreamChunk)
+		errChan := make(chan error, 1)
+		go func() {
+			errChan <- streamer.SendStreamChunks(msgs, opts, vendorChunks)
+		}()
+		for chunk := range vendorChunks {
+			switch chunk.Type {
+			case domain.StreamDone:
+				usage = chunk.Usage
+			case domain.StreamError:
+				err = chunk.Err
+			default:
+				chunks <- chunk
+			}
+		}
+		if streamErr := <-errChan; streamErr != nil {
+			err = streamErr
+		}
+	} else {
+		texts := make(chan string)
+		errChan := make(chan error, 1)
+		go func() {
+			errChan <- vendor.SendStream(msgs, opts, texts)
+		}()
+		splitter := NewThinkSplitter(ThinkTags(opts))
+		for text := range texts {
+			for _, chunk := range splitter.Write(text) {
+				chunks <- chunk
+			}
+		}
+		for _, chunk := range splitter.Flush() {
+			chunks <- chunk
+		}
+		err = <-errChan
+	}
+
+	if err != nil {
+		chunks <- domain.StreamChunk{Type: domain.StreamError, Text: err.Error(), Err: err}
+		return
+	}
+	chunks <- domain.StreamChunk{Type: domain.StreamDone, Usage: usage}
+}
+
+// Collect reads the chunks to the end and returns the answer, the error of an error chunk, if any
+func Collect(chunks <-chan domain.StreamChunk) (answer string, usage *domain.Usage, err error) {
+	var builder strings.Builder
+	for chunk := range chunks {
+		switch chunk.Type {
+		case domain.StreamText:
+			builder.WriteString(chunk.Text)
+		case domain.StreamDone:
+			usage = chunk.Usage
+		case domain.StreamError:
+			if err = chunk.Err; err == nil {
+				err = fmt.Errorf("%s", chunk.Text)
+			}
+		}
+	}
+	answer = builder.String()
+	return
+}
+
+// ThinkSplitter splits the reasoning written between the think tags off a text stream, the tags may be
+// cut across the pieces of the stream
+type ThinkSplitter struct {
+	startTag  string
+	endTag    string
+	reasoning bool
+	pending   string // the end of the text, which may be the start of a tag
+}
+
+func NewThinkSplitter(startTag string, endTag string) *ThinkSplitter {
+	return &ThinkSplitter{startTag: startTag, endTag: endTag}
+}
+
+// Write returns the chunks of the text, the end of the text that may start a tag is held back until the
+// next text tells
+func (o *ThinkSplitter) Write(text string) (ret []domain.StreamChunk) {
+	text = o.pending + text
+	o.pending = ""
+	for text != "" {
+		tag := o.startTag
+		if o.reasoning {
+			tag = o.endTag
+		}
+		if i := strings.Index(text, tag); i >= 0 {
+			ret = o.appendChunk(ret, text[:i])
+			text = text[i+len(tag):]
+			o.reasoning = !o.reasoning
+			continue
+		}
+		keep := partialSuffix(text, tag)
+		ret = o.appendChunk(ret, text[:len(text)-keep])
+		o.pending = text[len(text)-keep:]
+		break
+	}
+	return
+}
+
+// Flush returns the text held back, once the stream ended
+func (o *ThinkSplitter) Flush() (ret []domain.StreamChunk) {
+	ret = o.appendChunk(ret, o.pending)
+	o.pending = ""
+	return
+}
+
+func (o *ThinkSplitter) appendChunk(chunks []domain.StreamChunk, text string) []domain.StreamChunk {
+	if text == "" {
+		return chunks
+	}
+	chunkType := domain.StreamText
+	if o.reasoning {
+		chunkType = domain.StreamReasoning
+	}
+	return append(chunks, domain.StreamChunk{Type: chunkType, Text: text})
+}
+
+// partialSuffix returns the length of the longest end of the text starting the tag
+func partialSuffix(text string, tag string) int {
+	for n := min(len(tag)-1, len(text)); n > 0; n-- {
+		if strings.HasSuffix(text, tag[:n]) {
+			return n
+		}
+	}
+	return 0
+}
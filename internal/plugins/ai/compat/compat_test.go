@@ -0,0 +1,101 @@
+package compat
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/danielmiessler/fabric/internal/chat"
+	"github.com/danielmiessler/fabric/internal/domain"
+	"github.com/danielmiessler/fabric/internal/plugins/ai"
+)
+
+// textVendor streams the texts as plain text, like most vendors
+type textVendor struct {
+	ai.Vendor
+	texts []string
+	err   error
+}
+
+func (o *textVendor) SendStream(_ []*chat.ChatCompletionMessage, _ *domain.ChatOptions, channel chan string) error {
+	for _, text := range o.texts {
+		channel <- text
+	}
+	close(channel)
+	return o.err
+}
+
+// chunkVendor streams its chunks as they are
+type chunkVendor struct {
+	ai.Vendor
+	chunks []domain.StreamChunk
+}
+
+func (o *chunkVendor) SendStreamChunks(_ []*chat.ChatCompletionMessage, _ *domain.ChatOptions, channel chan domain.StreamChunk) error {
+	for _, chunk := range o.chunks {
+		channel <- chunk
+	}
+	close(channel)
+	return nil
+}
+
+func stream(vendor ai.Vendor) (ret []domain.StreamChunk) {
+	chunks := make(chan domain.StreamChunk)
+	go Stream(vendor, nil, &domain.ChatOptions{}, chunks)
+	for chunk := range chunks {
+		ret = append(ret, chunk)
+	}
+	return
+}
+
+func TestThinkSplitter(t *testing.T) {
+	splitter := NewThinkSplitter(DefaultThinkStartTag, DefaultThinkEndTag)
+	var chunks []domain.StreamChunk
+	for _, text := range []string{"<thi", "nk>let me ", "see</th", "ink>\nThe answer is <", "b>42</b>"} {
+		chunks = append(chunks, splitter.Write(text)...)
+	}
+	chunks = append(chunks, splitter.Flush()...)
+
+	var reasoning, answer string
+	for _, chunk := range chunks {
+		switch chunk.Type {
+		case domain.StreamReasoning:
+			reasoning += chunk.Text
+		case domain.StreamText:
+			answer += chunk.Text
+		default:
+			t.Errorf("unexpected chunk %v", chunk)
+		}
+	}
+	if reasoning != "let me see" || answer != "\nThe answer is <b>42</b>" {
+		t.Errorf("unexpected split: reasoning %q, answer %q", reasoning, answer)
+	}
+}
+
+func TestStream_TextVendor(t *testing.T) {
+	chunks := stream(&textVendor{texts: []string{"<think>hmm</think>", "Hello"}})
+	if len(chunks) != 3 || chunks[0].Type != domain.StreamReasoning || chunks[1].Text != "Hello" || chunks[2].Type != domain.StreamDone {
+		t.Errorf("unexpected chunks: %v", chunks)
+	}
+
+	chunks = stream(&textVendor{texts: []string{"Hel"}, err: errors.New("connection reset")})
+	if last := chunks[len(chunks)-1]; last.Type != domain.StreamError || last.Text != "connection reset" {
+		t.Errorf("expected the stream to end with the error, got %v", chunks)
+	}
+}
+
+func TestStream_ChunkVendor(t *testing.T) {
+	usage := &domain.Usage{PromptTokens: 10, CompletionTokens: 2, FinishReason: "end_turn"}
+	chunks := make(chan domain.StreamChunk)
+	go Stream(&chunkVendor{chunks: []domain.StreamChunk{
+		{Type: domain.StreamReasoning, Text: "searching"},
+		{Type: domain.StreamToolCall, ToolCall: &domain.ToolCall{Name: "web_search", Arguments: `{"query":"fabric"}`}},
+		{Type: domain.StreamText, Text: "Fabric is "},
+		{Type: domain.StreamText, Text: "a framework"},
+		{Type: domain.StreamDone, Usage: usage},
+	}}, nil, &domain.ChatOptions{}, chunks)
+
+	answer, gotUsage, err := Collect(chunks)
+	if err != nil || answer != "Fabric is a framework" || gotUsage != usage {
+		t.Errorf("unexpected result %q, %v, %v", answer, gotUsage, err)
+	}
+}
@@ -0,0 +1,358 @@
+package compat
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/danielmiessler/fabric/internal/chat"
+	"github.com/danielmiessler/fabric/internal/domain"
+	"github.com/danielmiessler/fabric/internal/plugins/ai"
+	"github.com/danielmiessler/fabric/internal/plugins/ai/anthropic"
+	"github.com/danielmiessler/fabric/internal/plugins/ai/ollama"
+	"github.com/danielmiessler/fabric/internal/plugins/ai/openai"
+)
+
+// fixture is a response recorded from a vendor API, its body is the file of testdata
+type fixture struct {
+	status      int
+	contentType string
+	file        string
+}
+
+func sse(file string) fixture    { return fixture{http.StatusOK, "text/event-stream", file} }
+func ndjson(file string) fixture { return fixture{http.StatusOK, "application/x-ndjson", file} }
+func jsonFixture(status int, file string) fixture {
+	return fixture{status, "application/json", file}
+}
+
+type recordedRequest struct {
+	Path   string
+	Header http.Header
+	Body   map[string]any
+}
+
+// fixtureServer replays the fixtures in order, the last one to every later request, and records the requests
+type fixtureServer struct {
+	*httptest.Server
+	mu       sync.Mutex
+	requests []recordedRequest
+}
+
+func newFixtureServer(t *testing.T, fixtures ...fixture) (ret *fixtureServer) {
+	t.Helper()
+	bodies := make([][]byte, len(fixtures))
+	for i, fixture := range fixtures {
+		var err error
+		if bodies[i], err = os.ReadFile(filepath.Join("testdata", fixture.file)); err != nil {
+			t.Fatalf("failed to read fixture: %v", err)
+		}
+	}
+
+	ret = &fixtureServer{}
+	ret.Server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		data, _ := io.ReadAll(r.Body)
+		request := recordedRequest{Path: r.URL.Path, Header: r.Header.Clone()}
+		json.Unmarshal(data, &request.Body)
+
+		ret.mu.Lock()
+		ret.requests = append(ret.requests, request)
+		i := min(len(ret.requests), len(fixtures)) - 1
+		ret.mu.Unlock()
+
+		w.Header().Set("Content-Type", fixtures[i].contentType)
+		w.WriteHeader(fixtures[i].status)
+		w.Write(bodies[i])
+	}))
+	t.Cleanup(ret.Close)
+	return
+}
+
+func (o *fixtureServer) request(t *testing.T, i int) recordedRequest {
+	t.Helper()
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	if i >= len(o.requests) {
+		t.Fatalf("expected at least %d requests, got %d", i+1, len(o.requests))
+	}
+	return o.requests[i]
+}
+
+func newOpenAIClient(t *testing.T, server *fixtureServer) (ret *openai.Client) {
+	t.Setenv("OPENAI_API_KEY", "test-key")
+	t.Setenv("OPENAI_API_BASE_URL", server.URL)
+	ret = openai.NewClient()
+	if err := ret.Configure(); err != nil {
+		t.Fatalf("failed to configure the client: %v", err)
+	}
+	return
+}
+
+func newAnthropicClient(t *testing.T, server *fixtureServer) (ret *anthropic.Client) {
+	t.Setenv("ANTHROPIC_API_KEY", "test-key")
+	t.Setenv("ANTHROPIC_API_BASE_URL", server.URL)
+	t.Setenv("ANTHROPIC_USE_OAUTH", "false")
+	ret = anthropic.NewClient()
+	if err := ret.Configure(); err != nil {
+		t.Fatalf("failed to configure the client: %v", err)
+	}
+	return
+}
+
+func newOllamaClient(t *testing.T, server *fixtureServer) (ret *ollama.Client) {
+	t.Setenv("OLLAMA_API_URL", server.URL)
+	t.Setenv("OLLAMA_HTTP_TIMEOUT", "10s")
+	ret = ollama.NewClient()
+	if err := ret.Configure(); err != nil {
+		t.Fatalf("failed to configure the client: %v", err)
+	}
+	return
+}
+
+var fixtureMessages = []*chat.ChatCompletionMessage{
+	{Role: chat.ChatMessageRoleSystem, Content: "Answer in one line."},
+	{Role: chat.ChatMessageRoleUser, Content: "What is fabric?"},
+}
+
+// streamChunks streams the fixture messages through Stream, as the CLI and the REST API do
+func streamChunks(vendor ai.Vendor, opts *domain.ChatOptions) (ret []domain.StreamChunk) {
+	chunks := make(chan domain.StreamChunk)
+	go Stream(vendor, fixtureMessages, opts, chunks)
+	for chunk := range chunks {
+		ret = append(ret, chunk)
+	}
+	return
+}
+
+// texts joins the text of the chunks of each type
+func texts(chunks []domain.StreamChunk) (ret map[domain.StreamChunkType]string) {
+	ret = map[domain.StreamChunkType]string{}
+	for _, chunk := range chunks {
+		ret[chunk.Type] += chunk.Text
+	}
+	return
+}
+
+func lastChunk(t *testing.T, chunks []domain.StreamChunk) domain.StreamChunk {
+	t.Helper()
+	if len(chunks) == 0 {
+		t.Fatal("expected the stream to end with a chunk")
+	}
+	return chunks[len(chunks)-1]
+}
+
+func expectFields(t *testing.T, body map[string]any, expected map[string]any) {
+	t.Helper()
+	for field, value := range expected {
+		if value == nil {
+			if _, ok := body[field]; ok {
+				t.Errorf("expected no %s, got %v", field, body[field])
+			}
+		} else if !reflect.DeepEqual(body[field], value) {
+			t.Errorf("expected %s %v, got %v", field, value, body[field])
+		}
+	}
+}
+
+func TestFixtures_OpenAIResponsesStream(t *testing.T) {
+	server := newFixtureServer(t, sse("openai/responses_stream.sse"))
+	chunks := streamChunks(newOpenAIClient(t, server), &domain.ChatOptions{Model: "gpt-4o", Temperature: 0.7})
+
+	if got := texts(chunks)[domain.StreamText]; got != "Fabric is an open-source framework.\n" {
+		t.Errorf("expected the deltas without the done text, got %q", got)
+	}
+	if last := lastChunk(t, chunks); last.Type != domain.StreamDone {
+		t.Errorf("expected the stream to end with a done chunk, got %v", last)
+	}
+
+	request := server.request(t, 0)
+	if request.Path != "/responses" {
+		t.Errorf("expected the Responses API, got %s", request.Path)
+	}
+	expectFields(t, request.Body, map[string]any{"model": "gpt-4o", "stream": true, "temperature": 0.7, "stop": nil})
+	if input, _ := request.Body["input"].([]any); len(input) != 2 {
+		t.Errorf("expected the system and the user message as input, got %v", request.Body["input"])
+	}
+	if auth := request.Header.Get("Authorization"); auth != "Bearer test-key" {
+		t.Errorf("expected the API key, got %q", auth)
+	}
+}
+
+func TestFixtures_OpenAIChatCompletionsStream(t *testing.T) {
+	server := newFixtureServer(t, sse("openai/chat_completions_stream.sse"))
+	chunks := streamChunks(newOpenAIClient(t, server), &domain.ChatOptions{
+		Model: "gpt-4o", Temperature: 0.2, TopP: 0.5, MaxTokens: 64, Seed: 7, StopSequences: []string{"\n\n"}})
+
+	got := texts(chunks)
+	if got[domain.StreamReasoning] != "The user wants one line." || got[domain.StreamText] != "Fabric augments humans using AI\n" {
+		t.Errorf("expected the reasoning split off the answer, got %q", got)
+	}
+	if last := lastChunk(t, chunks); last.Type != domain.StreamDone {
+		t.Errorf("expected the stream to end with a done chunk, got %v", last)
+	}
+
+	// the stop sequences send the request to the Chat Completions API
+	request := server.request(t, 0)
+	if request.Path != "/chat/completions" {
+		t.Errorf("expected the Chat Completions API, got %s", request.Path)
+	}
+	expectFields(t, request.Body, map[string]any{"model": "gpt-4o", "stream": true, "temperature": 0.2, "top_p": 0.5,
+		"max_tokens": float64(64), "seed": float64(7), "stop": []any{"\n\n"}})
+}
+
+func TestFixtures_OpenAIChatCompletion(t *testing.T) {
+	server := newFixtureServer(t, jsonFixture(http.StatusOK, "openai/chat_completion.json"))
+	client := openai.NewClientCompatibleWithResponses("Compatible", "", false, nil)
+	t.Setenv("COMPATIBLE_API_KEY", "test-key")
+	t.Setenv("COMPATIBLE_API_BASE_URL", server.URL)
+	if err := client.Configure(); err != nil {
+		t.Fatalf("failed to configure the client: %v", err)
+	}
+
+	answer, usage, err := client.SendWithUsage(context.Background(), fixtureMessages, &domain.ChatOptions{Model: "llama-3.3-70b", Temperature: 0.7})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if answer != "Fabric augments humans using AI" {
+		t.Errorf("unexpected answer %q", answer)
+	}
+	if *usage != (domain.Usage{PromptTokens: 19, CompletionTokens: 10, FinishReason: "length"}) {
+		t.Errorf("unexpected usage %+v", usage)
+	}
+	expectFields(t, server.request(t, 0).Body, map[string]any{"model": "llama-3.3-70b", "stream": nil, "temperature": 0.7})
+}
+
+func TestFixtures_OpenAIError(t *testing.T) {
+	server := newFixtureServer(t, jsonFixture(http.StatusUnauthorized, "openai/invalid_api_key.json"))
+	chunks := streamChunks(newOpenAIClient(t, server), &domain.ChatOptions{Model: "gpt-4o"})
+
+	last := lastChunk(t, chunks)
+	if last.Type != domain.StreamError || !strings.Contains(last.Text, "401") || !strings.Contains(last.Text, "Incorrect API key") {
+		t.Errorf("expected the stream to end with the error of the API, got %v", last)
+	}
+	if got := texts(chunks)[domain.StreamText]; got != "" {
+		t.Errorf("expected no text, got %q", got)
+	}
+}
+
+func TestFixtures_AnthropicStream(t *testing.T) {
+	server := newFixtureServer(t, sse("anthropic/messages_stream.sse"))
+	chunks := streamChunks(newAnthropicClient(t, server), &domain.ChatOptions{
+		Model: "claude-3-7-sonnet-latest", Temperature: 0.7, TopP: domain.DefaultTopP, MaxTokens: 2048,
+		Thinking: domain.ThinkingLow, StopSequences: []string{"END"}})
+
+	got := texts(chunks)
+	if got[domain.StreamReasoning] != "The user asks what fabric is, I should search." {
+		t.Errorf("unexpected reasoning %q", got[domain.StreamReasoning])
+	}
+	if got[domain.StreamText] != "Fabric is an open-source framework." {
+		t.Errorf("unexpected answer %q", got[domain.StreamText])
+	}
+	var toolCalls []*domain.ToolCall
+	for _, chunk := range chunks {
+		if chunk.Type == domain.StreamToolCall {
+			toolCalls = append(toolCalls, chunk.ToolCall)
+		}
+	}
+	if len(toolCalls) != 1 || toolCalls[0].Name != "web_search" || toolCalls[0].Arguments != `{"query": "fabric framework"}` {
+		t.Errorf("expected the web search with its arguments joined, got %v", toolCalls)
+	}
+	last := lastChunk(t, chunks)
+	if last.Type != domain.StreamDone || last.Usage == nil ||
+		*last.Usage != (domain.Usage{PromptTokens: 472, CompletionTokens: 87, FinishReason: "end_turn"}) {
+		t.Errorf("expected the stream to end with the usage, got %v", last)
+	}
+
+	request := server.request(t, 0)
+	if request.Path != "/v1/messages" {
+		t.Errorf("expected the Messages API, got %s", request.Path)
+	}
+	// the default top_p sends the temperature only, some models refuse both
+	expectFields(t, request.Body, map[string]any{"model": "claude-3-7-sonnet-latest", "stream": true,
+		"max_tokens": float64(2048), "temperature": 0.7, "top_p": nil, "stop_sequences": []any{"END"},
+		"thinking": map[string]any{"type": "enabled", "budget_tokens": float64(domain.TokenBudgetLow)}})
+	if key := request.Header.Get("X-Api-Key"); key != "test-key" {
+		t.Errorf("expected the API key, got %q", key)
+	}
+
+	// the system message is sent as the start of the first user message
+	messages, _ := request.Body["messages"].([]any)
+	if len(messages) != 1 {
+		t.Fatalf("expected one user message, got %v", request.Body["messages"])
+	}
+	content, _ := json.Marshal(messages[0])
+	if !strings.Contains(string(content), "Answer in one line.") || !strings.Contains(string(content), "What is fabric?") {
+		t.Errorf("expected the system and the user message in the first message, got %s", content)
+	}
+}
+
+func TestFixtures_AnthropicStreamError(t *testing.T) {
+	server := newFixtureServer(t, sse("anthropic/overloaded_stream.sse"))
+	chunks := streamChunks(newAnthropicClient(t, server), &domain.ChatOptions{Model: "claude-3-7-sonnet-latest"})
+
+	if got := texts(chunks)[domain.StreamText]; got != "Fabric" {
+		t.Errorf("expected the text streamed before the error, got %q", got)
+	}
+	if last := lastChunk(t, chunks); last.Type != domain.StreamError || !strings.Contains(last.Text, "overloaded_error") {
+		t.Errorf("expected the stream to end with the error event, got %v", last)
+	}
+}
+
+func TestFixtures_AnthropicBetaFallback(t *testing.T) {
+	server := newFixtureServer(t, jsonFixture(http.StatusBadRequest, "anthropic/beta_rejected.json"),
+		sse("anthropic/messages_stream.sse"))
+	chunks := streamChunks(newAnthropicClient(t, server), &domain.ChatOptions{Model: "claude-sonnet-4-20250514"})
+
+	if last := lastChunk(t, chunks); last.Type != domain.StreamDone {
+		t.Errorf("expected the stream without the beta to succeed, got %v", last)
+	}
+	if beta := server.request(t, 0).Header.Get("Anthropic-Beta"); beta != "context-1m-2025-08-07" {
+		t.Errorf("expected the beta of the model on the first request, got %q", beta)
+	}
+	if beta := server.request(t, 1).Header.Get("Anthropic-Beta"); beta != "" {
+		t.Errorf("expected no beta on the retry, got %q", beta)
+	}
+}
+
+func TestFixtures_OllamaStream(t *testing.T) {
+	server := newFixtureServer(t, ndjson("ollama/chat_stream.ndjson"))
+	chunks := streamChunks(newOllamaClient(t, server), &domain.ChatOptions{
+		Model: "qwen3:8b", Temperature: 0.7, TopP: 0.9, MaxTokens: 256, ModelContextLength: 8192, Seed: 42,
+		StopSequences: []string{"</answer>"}})
+
+	got := texts(chunks)
+	if got[domain.StreamReasoning] != "\nA one line answer.\n" || got[domain.StreamText] != "\n\nFabric augments humans using AI." {
+		t.Errorf("expected the reasoning split off the answer, got %q", got)
+	}
+	if last := lastChunk(t, chunks); last.Type != domain.StreamDone {
+		t.Errorf("expected the stream to end with a done chunk, got %v", last)
+	}
+
+	request := server.request(t, 0)
+	if request.Path != "/api/chat" {
+		t.Errorf("expected the chat API, got %s", request.Path)
+	}
+	expectFields(t, request.Body, map[string]any{"model": "qwen3:8b", "stream": nil})
+	expectFields(t, request.Body["options"].(map[string]any), map[string]any{"temperature": 0.7, "top_p": 0.9,
+		"num_predict": float64(256), "num_ctx": float64(8192), "seed": float64(42), "stop": []any{"</answer>"}})
+	if messages, _ := request.Body["messages"].([]any); len(messages) != 2 {
+		t.Errorf("expected the system and the user message, got %v", request.Body["messages"])
+	}
+}
+
+func TestFixtures_OllamaError(t *testing.T) {
+	server := newFixtureServer(t, jsonFixture(http.StatusNotFound, "ollama/model_not_found.json"))
+	chunks := streamChunks(newOllamaClient(t, server), &domain.ChatOptions{Model: "qwen3:8b"})
+
+	if last := lastChunk(t, chunks); last.Type != domain.StreamError || !strings.Contains(last.Text, "not found, try pulling it first") {
+		t.Errorf("expected the stream to end with the error of the API, got %v", last)
+	}
+}
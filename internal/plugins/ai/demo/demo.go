@@ -0,0 +1,108 @@
+package demo
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/danielmiessler/fabric/internal/chat"
+	"github.com/danielmiessler/fabric/internal/domain"
+	"github.com/danielmiessler/fabric/internal/plugins"
+)
+
+const (
+	VendorName = "Demo"
+	ModelName  = "demo-model"
+)
+
+// Client is an offline vendor used by --demo. It answers with a canned response built from the
+// pattern and the input, streamed word by word so the streaming UI can be explored without an API key.
+type Client struct {
+	*plugins.PluginBase
+	StreamDelay time.Duration
+}
+
+func NewClient() *Client {
+	return &Client{PluginBase: &plugins.PluginBase{Name: VendorName}, StreamDelay: 30 * time.Millisecond}
+}
+
+func (c *Client) ListModels() ([]string, error) {
+	return []string{ModelName}, nil
+}
+
+func (c *Client) SendStream(msgs []*chat.ChatCompletionMessage, _ *domain.ChatOptions, channel chan string) error {
+	defer close(channel)
+	for _, word := range strings.SplitAfter(Response(msgs), " ") {
+		channel <- word
+		time.Sleep(c.StreamDelay)
+	}
+	return nil
+}
+
+func (c *Client) Send(_ context.Context, msgs []*chat.ChatCompletionMessage, _ *domain.ChatOptions) (string, error) {
+	return Response(msgs), nil
+}
+
+func (c *Client) Setup() error {
+	return nil
+}
+
+func (c *Client) SetupFillEnvFileContent(_ *bytes.Buffer) {
+	// No environment variables needed for the demo
+}
+
+func (c *Client) NeedsRawMode(modelName string) bool {
+	return false
+}
+
+// Response builds the canned answer: the first heading of the system prompt names the task,
+// the input is summarized by its first sentences and a few statistics
+func Response(msgs []*chat.ChatCompletionMessage) string {
+	var system, input string
+	for _, msg := range msgs {
+		switch msg.Role {
+		case chat.ChatMessageRoleSystem:
+			system = msg.Content
+		case chat.ChatMessageRoleUser:
+			input = msg.Content
+		}
+	}
+
+	task := "Response"
+	for _, line := range strings.Split(system, "\n") {
+		if heading, ok := strings.CutPrefix(strings.TrimSpace(line), "# "); ok {
+			task = strings.TrimSpace(heading)
+			break
+		}
+	}
+
+	words := strings.Fields(input)
+	var builder strings.Builder
+	fmt.Fprintf(&builder, "# %s\n\n", task)
+	builder.WriteString("This is a canned answer of the demo vendor, no model was called.\n\n")
+	builder.WriteString("## Highlights\n\n")
+	for _, sentence := range firstSentences(input, 3) {
+		fmt.Fprintf(&builder, "- %s\n", sentence)
+	}
+	builder.WriteString("\n## Input statistics\n\n")
+	fmt.Fprintf(&builder, "- Words: %d\n", len(words))
+	fmt.Fprintf(&builder, "- Lines: %d\n", strings.Count(strings.TrimSpace(input), "\n")+1)
+	return builder.String()
+}
+
+func firstSentences(text string, count int) (ret []string) {
+	text = strings.Join(strings.Fields(text), " ")
+	for len(ret) < count && text != "" {
+		end := strings.IndexAny(text, ".!?")
+		if end < 0 {
+			end = len(text) - 1
+		}
+		if sentence := strings.TrimSpace(text[:end+1]); sentence != "" {
+			ret = append(ret, sentence)
+		}
+		text = text[end+1:]
+	}
+	return
+}
@@ -0,0 +1,47 @@
+package demo
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/danielmiessler/fabric/internal/chat"
+)
+
+func TestResponse(t *testing.T) {
+	msgs := []*chat.ChatCompletionMessage{
+		{Role: chat.ChatMessageRoleSystem, Content: "# IDENTITY\n\nYou summarize.\n"},
+		{Role: chat.ChatMessageRoleUser, Content: "First point. Second point!\nThird point? Fourth point."},
+	}
+
+	ret := Response(msgs)
+	if !strings.HasPrefix(ret, "# IDENTITY\n") {
+		t.Errorf("expected the system prompt heading as title, got %q", ret)
+	}
+	for _, expected := range []string{"- First point.", "- Second point!", "- Third point?", "- Words: 8"} {
+		if !strings.Contains(ret, expected) {
+			t.Errorf("expected response to contain %q, got %q", expected, ret)
+		}
+	}
+	if strings.Contains(ret, "Fourth point") {
+		t.Errorf("expected only the first three sentences, got %q", ret)
+	}
+}
+
+func TestSendStream(t *testing.T) {
+	client := NewClient()
+	client.StreamDelay = 0
+
+	msgs := []*chat.ChatCompletionMessage{{Role: chat.ChatMessageRoleUser, Content: "Hello world."}}
+	channel := make(chan string)
+	go client.SendStream(msgs, nil, channel)
+
+	var streamed strings.Builder
+	chunks := 0
+	for chunk := range channel {
+		streamed.WriteString(chunk)
+		chunks++
+	}
+	if streamed.String() != Response(msgs) || chunks < 2 {
+		t.Errorf("expected the response streamed in chunks, got %d chunks: %q", chunks, streamed.String())
+	}
+}
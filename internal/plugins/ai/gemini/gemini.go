@@ -185,6 +185,15 @@ func (o *Client) buildGenerateContentConfig(opts *domain.ChatOptions) (*genai.Ge
 		Temperature:     &temperature,
 		TopP:            &topP,
 		MaxOutputTokens: int32(opts.ModelContextLength),
+		StopSequences:   opts.StopSequences,
+	}
+	if opts.MaxTokens > 0 {
+		cfg.MaxOutputTokens = int32(opts.MaxTokens)
+	}
+
+	if opts.Seed != 0 {
+		seed := int32(opts.Seed)
+		cfg.Seed = &seed
 	}
 
 	if opts.Search {
@@ -51,6 +51,11 @@ func (c *Client) configure() error {
 }
 
 // ListModels returns a list of available models.
+// Endpoint is the API URL, see ai.EndpointReporter
+func (c *Client) Endpoint() string {
+	return c.ApiUrl.Value
+}
+
 func (c *Client) ListModels() ([]string, error) {
 	url := fmt.Sprintf("%s/models", c.ApiUrl.Value)
 
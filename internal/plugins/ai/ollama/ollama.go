@@ -89,6 +89,11 @@ func (o *Client) configure() (err error) {
 	return
 }
 
+// Endpoint is the API URL, see ai.EndpointReporter
+func (o *Client) Endpoint() string {
+	return o.ApiUrl.Value
+}
+
 func (o *Client) ListModels() (ret []string, err error) {
 	ctx := context.Background()
 
@@ -104,6 +109,9 @@ func (o *Client) ListModels() (ret []string, err error) {
 }
 
 func (o *Client) SendStream(msgs []*chat.ChatCompletionMessage, opts *domain.ChatOptions, channel chan string) (err error) {
+	// the channel is closed on errors too, the readers wait for it
+	defer close(channel)
+
 	req := o.createChatRequest(msgs, opts)
 
 	respFunc := func(resp ollamaapi.ChatResponse) (streamErr error) {
@@ -113,11 +121,7 @@ func (o *Client) SendStream(msgs []*chat.ChatCompletionMessage, opts *domain.Cha
 
 	ctx := context.Background()
 
-	if err = o.client.Chat(ctx, &req, respFunc); err != nil {
-		return
-	}
-
-	close(channel)
+	err = o.client.Chat(ctx, &req, respFunc)
 	return
 }
 
@@ -154,6 +158,18 @@ func (o *Client) createChatRequest(msgs []*chat.ChatCompletionMessage, opts *dom
 		options["num_ctx"] = opts.ModelContextLength
 	}
 
+	if opts.Seed != 0 {
+		options["seed"] = opts.Seed
+	}
+
+	if opts.MaxTokens > 0 {
+		options["num_predict"] = opts.MaxTokens
+	}
+
+	if len(opts.StopSequences) > 0 {
+		options["stop"] = opts.StopSequences
+	}
+
 	ret = ollamaapi.ChatRequest{
 		Model:    opts.Model,
 		Messages: messages,
@@ -15,15 +15,21 @@ import (
 )
 
 // sendChatCompletions sends a request using the Chat Completions API
-func (o *Client) sendChatCompletions(ctx context.Context, msgs []*chat.ChatCompletionMessage, opts *domain.ChatOptions) (ret string, err error) {
+func (o *Client) sendChatCompletions(ctx context.Context, msgs []*chat.ChatCompletionMessage, opts *domain.ChatOptions) (
+	ret string, usage *domain.Usage, err error) {
 	req := o.buildChatCompletionParams(msgs, opts)
 
 	var resp *openai.ChatCompletion
 	if resp, err = o.ApiClient.Chat.Completions.New(ctx, req); err != nil {
 		return
 	}
+	usage = &domain.Usage{
+		PromptTokens:     int(resp.Usage.PromptTokens),
+		CompletionTokens: int(resp.Usage.CompletionTokens),
+	}
 	if len(resp.Choices) > 0 {
 		ret = resp.Choices[0].Message.Content
+		usage.FinishReason = resp.Choices[0].FinishReason
 	}
 	return
 }
@@ -84,6 +90,9 @@ func (o *Client) buildChatCompletionParams(
 		if opts.Seed != 0 {
 			ret.Seed = openai.Int(int64(opts.Seed))
 		}
+		if len(opts.StopSequences) > 0 {
+			ret.Stop = openai.ChatCompletionNewParamsStopUnion{OfStringArray: opts.StopSequences}
+		}
 	}
 	if eff, ok := parseReasoningEffort(opts.Thinking); ok {
 		ret.ReasoningEffort = eff
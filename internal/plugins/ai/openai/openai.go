@@ -3,6 +3,7 @@ package openai
 import (
 	"context"
 	"fmt"
+	"os"
 	"slices"
 	"strings"
 
@@ -81,6 +82,11 @@ func (o *Client) configure() (ret error) {
 	return
 }
 
+// Endpoint is the API base URL, see ai.EndpointReporter
+func (o *Client) Endpoint() string {
+	return o.ApiBaseURL.Value
+}
+
 func (o *Client) ListModels() (ret []string, err error) {
 	var page *pagination.Page[openai.Model]
 	if page, err = o.ApiClient.Models.List(context.Background()); err != nil {
@@ -96,7 +102,7 @@ func (o *Client) SendStream(
 	msgs []*chat.ChatCompletionMessage, opts *domain.ChatOptions, channel chan string,
 ) (err error) {
 	// Use Responses API for OpenAI, Chat Completions API for other providers
-	if o.supportsResponsesAPI() {
+	if o.useResponsesAPI(opts) {
 		return o.sendStreamResponses(msgs, opts, channel)
 	}
 	return o.sendStreamChatCompletions(msgs, opts, channel)
@@ -129,17 +135,25 @@ func (o *Client) sendStreamResponses(
 }
 
 func (o *Client) Send(ctx context.Context, msgs []*chat.ChatCompletionMessage, opts *domain.ChatOptions) (ret string, err error) {
+	ret, _, err = o.SendWithUsage(ctx, msgs, opts)
+	return
+}
+
+// SendWithUsage sends the messages and also returns the token usage and finish reason of the response
+func (o *Client) SendWithUsage(ctx context.Context, msgs []*chat.ChatCompletionMessage, opts *domain.ChatOptions) (
+	ret string, usage *domain.Usage, err error) {
 	// Use Responses API for OpenAI, Chat Completions API for other providers
-	if o.supportsResponsesAPI() {
+	if o.useResponsesAPI(opts) {
 		return o.sendResponses(ctx, msgs, opts)
 	}
 	return o.sendChatCompletions(ctx, msgs, opts)
 }
 
-func (o *Client) sendResponses(ctx context.Context, msgs []*chat.ChatCompletionMessage, opts *domain.ChatOptions) (ret string, err error) {
+func (o *Client) sendResponses(ctx context.Context, msgs []*chat.ChatCompletionMessage, opts *domain.ChatOptions) (
+	ret string, usage *domain.Usage, err error) {
 	// Validate model supports image generation if image file is specified
 	if opts.ImageFile != "" && !supportsImageGeneration(opts.Model) {
-		return "", fmt.Errorf("model '%s' does not support image generation. Supported models: %s", opts.Model, strings.Join(ImageGenerationSupportedModels, ", "))
+		return "", nil, fmt.Errorf("model '%s' does not support image generation. Supported models: %s", opts.Model, strings.Join(ImageGenerationSupportedModels, ", "))
 	}
 
 	req := o.buildResponseParams(msgs, opts)
@@ -155,6 +169,14 @@ func (o *Client) sendResponses(ctx context.Context, msgs []*chat.ChatCompletionM
 	}
 
 	ret = o.extractText(resp)
+	usage = &domain.Usage{
+		PromptTokens:     int(resp.Usage.InputTokens),
+		CompletionTokens: int(resp.Usage.OutputTokens),
+		FinishReason:     string(resp.Status),
+	}
+	if resp.IncompleteDetails.Reason != "" {
+		usage.FinishReason = resp.IncompleteDetails.Reason
+	}
 	return
 }
 
@@ -163,6 +185,28 @@ func (o *Client) supportsResponsesAPI() bool {
 	return o.ImplementsResponses
 }
 
+// useResponsesAPI tells whether the request goes to the Responses API. It has no stop sequences, the requests
+// with some go to the Chat Completions API unless they need the web search or the image generation tools of
+// the Responses API, their stop sequences are then ignored.
+func (o *Client) useResponsesAPI(opts *domain.ChatOptions) bool {
+	if !o.supportsResponsesAPI() {
+		return false
+	}
+	// the raw requests send no stop sequences to any of the APIs
+	if len(opts.StopSequences) == 0 || opts.Raw {
+		return true
+	}
+	if opts.Search || o.shouldUseImageGeneration(opts) {
+		tool := "image generation"
+		if opts.Search {
+			tool = "web search"
+		}
+		fmt.Fprintf(os.Stderr, "Warning: the stop sequences are ignored, the %s tool needs the Responses API, which has none\n", tool)
+		return true
+	}
+	return false
+}
+
 func (o *Client) NeedsRawMode(modelName string) bool {
 	openaiModelsPrefixes := []string{
 		"o1",
@@ -0,0 +1,117 @@
+package openai
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+
+	openai "github.com/openai/openai-go"
+)
+
+// DefaultTranscriptionModel is used when no transcription model is given
+const DefaultTranscriptionModel = openai.AudioModelWhisper1
+
+// MaxTranscriptionFileSize is the largest file the audio transcriptions API accepts, 25 MB
+const MaxTranscriptionFileSize = 25 * 1024 * 1024
+
+// The length and the bitrate of the parts of a larger file, they stay well below MaxTranscriptionFileSize
+const (
+	transcriptionSegmentSeconds = 600
+	transcriptionSegmentBitrate = "64k"
+)
+
+// TranscribeFile sends the audio file to the audio transcriptions API and returns the text.
+// The API accepts flac, mp3, mp4, mpeg, mpga, m4a, ogg, wav and webm files of up to 25 MB; larger files are
+// split into parts with ffmpeg, transcribed in order and joined.
+func (o *Client) TranscribeFile(ctx context.Context, filePath string, model string, language string) (ret string, err error) {
+	if model == "" {
+		model = DefaultTranscriptionModel
+	}
+
+	var info os.FileInfo
+	if info, err = os.Stat(filePath); err != nil {
+		return
+	}
+	if info.Size() <= MaxTranscriptionFileSize {
+		return o.transcribePart(ctx, filePath, model, language)
+	}
+
+	var parts []string
+	var cleanup func()
+	if parts, cleanup, err = splitAudio(ctx, filePath, info.Size()); err != nil {
+		return
+	}
+	defer cleanup()
+
+	texts := make([]string, 0, len(parts))
+	for i, part := range parts {
+		var text string
+		if text, err = o.transcribePart(ctx, part, model, language); err != nil {
+			err = fmt.Errorf("part %d of %d: %w", i+1, len(parts), err)
+			return
+		}
+		texts = append(texts, strings.TrimSpace(text))
+	}
+	ret = strings.Join(texts, " ")
+	return
+}
+
+func (o *Client) transcribePart(ctx context.Context, filePath string, model string, language string) (ret string, err error) {
+	var file *os.File
+	if file, err = os.Open(filePath); err != nil {
+		return
+	}
+	defer file.Close()
+
+	params := openai.AudioTranscriptionNewParams{
+		File:  file,
+		Model: openai.AudioModel(model),
+	}
+	if language != "" {
+		params.Language = openai.String(language)
+	}
+
+	var transcription *openai.Transcription
+	if transcription, err = o.ApiClient.Audio.Transcriptions.New(ctx, params); err != nil {
+		err = fmt.Errorf("transcription failed: %w", err)
+		return
+	}
+	ret = transcription.Text
+	return
+}
+
+// splitAudio encodes the audio into mp3 parts of transcriptionSegmentSeconds in a temporary directory,
+// removed by cleanup, in order
+func splitAudio(ctx context.Context, filePath string, size int64) (parts []string, cleanup func(), err error) {
+	if _, err = exec.LookPath("ffmpeg"); err != nil {
+		err = fmt.Errorf("%s is %d MB, over the %d MB the transcription API accepts: install ffmpeg to split it, or split it yourself",
+			filepath.Base(filePath), size/(1024*1024), MaxTranscriptionFileSize/(1024*1024))
+		return
+	}
+
+	var dir string
+	if dir, err = os.MkdirTemp("", "fabric-transcribe-"); err != nil {
+		return
+	}
+	cleanup = func() { os.RemoveAll(dir) }
+
+	cmd := exec.CommandContext(ctx, "ffmpeg", "-nostdin", "-loglevel", "error", "-i", filePath, "-vn", "-ac", "1",
+		"-c:a", "libmp3lame", "-b:a", transcriptionSegmentBitrate, "-f", "segment",
+		"-segment_time", fmt.Sprint(transcriptionSegmentSeconds), filepath.Join(dir, "part-%03d.mp3"))
+	if output, splitErr := cmd.CombinedOutput(); splitErr != nil {
+		cleanup()
+		err = fmt.Errorf("could not split %s: %v: %s", filePath, splitErr, strings.TrimSpace(string(output)))
+		return
+	}
+
+	if parts, err = filepath.Glob(filepath.Join(dir, "part-*.mp3")); err == nil && len(parts) == 0 {
+		err = fmt.Errorf("could not split %s: ffmpeg wrote no audio", filePath)
+	}
+	if err != nil {
+		cleanup()
+	}
+	return
+}
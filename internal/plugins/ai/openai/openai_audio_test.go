@@ -0,0 +1,26 @@
+package openai
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestTranscribeFile_TooLargeWithoutFfmpeg(t *testing.T) {
+	t.Setenv("PATH", t.TempDir())
+	audioPath := filepath.Join(t.TempDir(), "episode.mp3")
+	file, err := os.Create(audioPath)
+	if err != nil {
+		t.Fatalf("failed to create the audio file: %v", err)
+	}
+	file.Truncate(MaxTranscriptionFileSize + 1)
+	file.Close()
+
+	// fails before the API is called, the client has no key
+	_, err = NewClient().TranscribeFile(context.Background(), audioPath, "", "")
+	if err == nil || !strings.Contains(err.Error(), "install ffmpeg") {
+		t.Errorf("expected the size limit to be reported, got %v", err)
+	}
+}
@@ -1,6 +1,7 @@
 package openai
 
 import (
+	"encoding/json"
 	"strings"
 	"testing"
 
@@ -175,3 +176,50 @@ func TestCitationFormatting(t *testing.T) {
 	citationCount := strings.Count(result, "- [")
 	assert.Equal(t, 2, citationCount, "Expected 2 unique citations")
 }
+
+func TestBuildResponseParams_NoStopSequences(t *testing.T) {
+	client := NewClient()
+	opts := &domain.ChatOptions{
+		Model:         "gpt-4o",
+		Temperature:   0.7,
+		Seed:          42,
+		StopSequences: []string{"END"},
+	}
+
+	params := client.buildResponseParams([]*chat.ChatCompletionMessage{{Role: "user", Content: "Hello"}}, opts)
+
+	body, err := json.Marshal(params)
+	assert.NoError(t, err)
+	assert.Contains(t, string(body), `"seed":42`)
+	assert.NotContains(t, string(body), `"stop"`, "the Responses API has no stop parameter")
+}
+
+func TestUseResponsesAPI(t *testing.T) {
+	client := NewClient()
+	tests := []struct {
+		name     string
+		opts     domain.ChatOptions
+		expected bool
+	}{
+		{"no stop sequences", domain.ChatOptions{}, true},
+		{"stop sequences", domain.ChatOptions{StopSequences: []string{"END"}}, false},
+		{"stop sequences in raw mode", domain.ChatOptions{StopSequences: []string{"END"}, Raw: true}, true},
+		{"stop sequences with the web search", domain.ChatOptions{StopSequences: []string{"END"}, Search: true}, true},
+		{"stop sequences with the image generation", domain.ChatOptions{StopSequences: []string{"END"}, ImageFile: "out.png"}, true},
+	}
+	for _, test := range tests {
+		assert.Equal(t, test.expected, client.useResponsesAPI(&test.opts), test.name)
+	}
+
+	compatible := NewClientCompatibleNoSetupQuestions("Groq", nil)
+	assert.False(t, compatible.useResponsesAPI(&domain.ChatOptions{}))
+}
+
+func TestBuildChatCompletionParams_StopSequences(t *testing.T) {
+	client := NewClient()
+	opts := &domain.ChatOptions{Model: "gpt-4o", StopSequences: []string{"END", "STOP"}}
+
+	params := client.buildChatCompletionParams([]*chat.ChatCompletionMessage{{Role: "user", Content: "Hello"}}, opts)
+
+	assert.Equal(t, []string{"END", "STOP"}, params.Stop.OfStringArray)
+}
@@ -0,0 +1,105 @@
+package openai_compatible
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"time"
+
+	"github.com/danielmiessler/fabric/internal/domain"
+	"github.com/danielmiessler/fabric/internal/plugins/ai"
+)
+
+// quotaFetchers are the providers with an endpoint reporting the quota of the API key
+var quotaFetchers = map[string]func(context.Context, *Client) (*domain.Quota, error){
+	"OpenRouter": fetchOpenRouterQuota,
+	"DeepSeek":   fetchDeepSeekQuota,
+}
+
+// GetQuota implements ai.QuotaReporter for the providers in quotaFetchers
+func (c *Client) GetQuota(ctx context.Context) (*domain.Quota, error) {
+	fetch, ok := quotaFetchers[c.GetName()]
+	if !ok {
+		return nil, ai.ErrQuotaNotSupported
+	}
+	return fetch(ctx, c)
+}
+
+// fetchOpenRouterQuota reads the usage and credit limit of the key, see https://openrouter.ai/docs/api-reference/limits
+func fetchOpenRouterQuota(ctx context.Context, c *Client) (ret *domain.Quota, err error) {
+	var response struct {
+		Data struct {
+			Usage          float64  `json:"usage"`
+			Limit          *float64 `json:"limit"`
+			LimitRemaining *float64 `json:"limit_remaining"`
+		} `json:"data"`
+	}
+	if err = c.getJSON(ctx, "key", &response); err != nil {
+		return
+	}
+	ret = &domain.Quota{
+		Currency:  "USD",
+		Spent:     &response.Data.Usage,
+		Limit:     response.Data.Limit,
+		Remaining: response.Data.LimitRemaining,
+	}
+	return
+}
+
+// fetchDeepSeekQuota reads the prepaid balance of the account, see https://api-docs.deepseek.com/api/get-user-balance
+func fetchDeepSeekQuota(ctx context.Context, c *Client) (ret *domain.Quota, err error) {
+	var response struct {
+		BalanceInfos []struct {
+			Currency     string `json:"currency"`
+			TotalBalance string `json:"total_balance"`
+		} `json:"balance_infos"`
+	}
+	if err = c.getJSON(ctx, "user/balance", &response); err != nil {
+		return
+	}
+	if len(response.BalanceInfos) == 0 {
+		err = fmt.Errorf("no balance returned by %s", c.GetName())
+		return
+	}
+	info := response.BalanceInfos[0]
+	var balance float64
+	if balance, err = strconv.ParseFloat(info.TotalBalance, 64); err != nil {
+		err = fmt.Errorf("invalid balance %q returned by %s: %v", info.TotalBalance, c.GetName(), err)
+		return
+	}
+	ret = &domain.Quota{Currency: info.Currency, Remaining: &balance}
+	return
+}
+
+// getJSON sends an authorized GET request to the path below the API base URL and decodes the response
+func (c *Client) getJSON(ctx context.Context, path string, target any) (err error) {
+	var fullURL string
+	if fullURL, err = url.JoinPath(c.ApiBaseURL.Value, path); err != nil {
+		return fmt.Errorf("failed to create %s URL: %w", path, err)
+	}
+
+	var req *http.Request
+	if req, err = http.NewRequestWithContext(ctx, http.MethodGet, fullURL, nil); err != nil {
+		return
+	}
+	req.Header.Set("Authorization", fmt.Sprintf("Bearer %s", c.ApiKey.Value))
+	req.Header.Set("Accept", "application/json")
+
+	client := &http.Client{Timeout: 10 * time.Second}
+	var resp *http.Response
+	if resp, err = client.Do(req); err != nil {
+		return
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		bodyBytes, _ := io.ReadAll(io.LimitReader(resp.Body, errorResponseLimit))
+		return fmt.Errorf("unexpected status code: %d from provider %s, response body: %s",
+			resp.StatusCode, c.GetName(), string(bodyBytes))
+	}
+	return json.NewDecoder(resp.Body).Decode(target)
+}
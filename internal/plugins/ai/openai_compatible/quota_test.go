@@ -0,0 +1,65 @@
+package openai_compatible
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/danielmiessler/fabric/internal/plugins/ai"
+)
+
+func newQuotaTestClient(t *testing.T, provider string, path string, body string) *Client {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != path || r.Header.Get("Authorization") != "Bearer test-key" {
+			http.NotFound(w, r)
+			return
+		}
+		w.Write([]byte(body))
+	}))
+	t.Cleanup(server.Close)
+
+	client, _ := CreateClient(provider)
+	client.ApiBaseURL.Value = server.URL + "/v1"
+	client.ApiKey.Value = "test-key"
+	return client
+}
+
+func TestGetQuota_OpenRouter(t *testing.T) {
+	client := newQuotaTestClient(t, "OpenRouter", "/v1/key", `{"data":{"usage":9.5,"limit":10,"limit_remaining":0.5}}`)
+
+	quota, err := client.GetQuota(context.Background())
+	if err != nil {
+		t.Fatalf("GetQuota() failed: %v", err)
+	}
+	if *quota.Spent != 9.5 || *quota.Limit != 10 || *quota.Remaining != 0.5 {
+		t.Errorf("unexpected quota %+v", quota)
+	}
+	if !quota.IsNearCap() {
+		t.Error("expected the quota to be near the cap")
+	}
+}
+
+func TestGetQuota_DeepSeek(t *testing.T) {
+	client := newQuotaTestClient(t, "DeepSeek", "/v1/user/balance",
+		`{"is_available":true,"balance_infos":[{"currency":"CNY","total_balance":"110.00"}]}`)
+
+	quota, err := client.GetQuota(context.Background())
+	if err != nil {
+		t.Fatalf("GetQuota() failed: %v", err)
+	}
+	if quota.Currency != "CNY" || *quota.Remaining != 110 || quota.Limit != nil {
+		t.Errorf("unexpected quota %+v", quota)
+	}
+	if quota.IsNearCap() {
+		t.Error("expected the balance not to be near the cap")
+	}
+}
+
+func TestGetQuota_NotSupported(t *testing.T) {
+	client, _ := CreateClient("Groq")
+	if _, err := client.GetQuota(context.Background()); !errors.Is(err, ai.ErrQuotaNotSupported) {
+		t.Errorf("expected ErrQuotaNotSupported, got %v", err)
+	}
+}
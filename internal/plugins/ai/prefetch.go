@@ -0,0 +1,93 @@
+package ai
+
+import (
+	"context"
+	"log/slog"
+	"sort"
+	"strings"
+	"time"
+)
+
+const (
+	// DefaultPrefetchInterval is how old the prefetched model lists get before they are read again
+	DefaultPrefetchInterval = 30 * time.Minute
+	// DefaultPrefetchVendorDelay spaces the model list requests, not to hit the rate limits of the vendors
+	DefaultPrefetchVendorDelay = 2 * time.Second
+
+	// prefetchIdleCheck is how often a due refresh checks whether the server became idle
+	prefetchIdleCheck = time.Minute
+)
+
+// PrefetchModels reads the model lists of the vendors in the background until the context is done, so
+// listing the models never waits for the vendors. The lists are read at once, then again when they are
+// older than interval and idle reports no pending work. The vendors are asked one after another with
+// vendorDelay in between, vendors whose circuit is open or whose request fails keep their last list.
+func (o *VendorsManager) PrefetchModels(ctx context.Context, interval, vendorDelay time.Duration, idle func() bool) {
+	if interval <= 0 {
+		interval = DefaultPrefetchInterval
+	}
+	o.refreshModels(ctx, vendorDelay)
+	refreshed := time.Now()
+
+	ticker := time.NewTicker(min(interval, prefetchIdleCheck))
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			if time.Since(refreshed) >= interval && (idle == nil || idle()) {
+				o.refreshModels(ctx, vendorDelay)
+				refreshed = time.Now()
+			}
+		}
+	}
+}
+
+func (o *VendorsManager) refreshModels(ctx context.Context, vendorDelay time.Duration) {
+	o.modelsMu.Lock()
+	previous := o.Models
+	vendors := o.Vendors
+	o.modelsMu.Unlock()
+
+	models := NewVendorsModels()
+	for i, vendor := range vendors {
+		if i > 0 && vendorDelay > 0 {
+			select {
+			case <-ctx.Done():
+				return
+			case <-time.After(vendorDelay):
+			}
+		}
+
+		name := vendor.GetName()
+		list := previousModels(previous, name)
+		if o.Breaker.Allow(name) {
+			if fetched, err := vendor.ListModels(); err != nil {
+				slog.Debug("Failed to prefetch the models", "vendor", name, "error", err)
+			} else {
+				sort.Slice(fetched, func(i, j int) bool { return strings.ToLower(fetched[i]) < strings.ToLower(fetched[j]) })
+				list = fetched
+			}
+		}
+		if len(list) > 0 {
+			models.AddGroupItems(name, list...)
+		}
+	}
+
+	o.modelsMu.Lock()
+	o.Models = models
+	o.modelsMu.Unlock()
+}
+
+func previousModels(models *VendorsModels, vendorName string) []string {
+	if models == nil {
+		return nil
+	}
+	for _, group := range models.GroupsItems {
+		if group.Group == vendorName {
+			return group.Items
+		}
+	}
+	return nil
+}
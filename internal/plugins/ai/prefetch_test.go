@@ -0,0 +1,76 @@
+package ai
+
+import (
+	"context"
+	"errors"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+type listingVendor struct {
+	Vendor
+	name   string
+	models []string
+	err    error
+	calls  atomic.Int32
+}
+
+func (o *listingVendor) GetName() string { return o.name }
+
+func (o *listingVendor) ListModels() ([]string, error) {
+	o.calls.Add(1)
+	return o.models, o.err
+}
+
+func TestRefreshModels(t *testing.T) {
+	openai := &listingVendor{name: "OpenAI", models: []string{"gpt-4o", "GPT-3.5"}}
+	flaky := &listingVendor{name: "Flaky", models: []string{"small"}}
+	manager := NewVendorsManager()
+	manager.AddVendors(openai, flaky)
+
+	manager.refreshModels(context.Background(), 0)
+	models, err := manager.GetModels()
+	if err != nil {
+		t.Fatalf("GetModels() error = %v", err)
+	}
+	if got := previousModels(models, "OpenAI"); len(got) != 2 || got[0] != "GPT-3.5" {
+		t.Errorf("expected the sorted models of OpenAI, got %v", got)
+	}
+
+	// failing and tripped vendors keep their last list
+	flaky.models, flaky.err = nil, errors.New("rate limited")
+	for range DefaultBreakerFailureThreshold {
+		manager.Breaker.RecordFailure("OpenAI", errors.New("down"))
+	}
+	manager.refreshModels(context.Background(), 0)
+	models, _ = manager.GetModels()
+	if got := previousModels(models, "Flaky"); len(got) != 1 || got[0] != "small" {
+		t.Errorf("expected the last models of the failing vendor, got %v", got)
+	}
+	if openai.calls.Load() != 1 || len(previousModels(models, "OpenAI")) != 2 {
+		t.Errorf("expected the tripped vendor to be skipped and keep its models, %d calls", openai.calls.Load())
+	}
+}
+
+func TestPrefetchModelsStops(t *testing.T) {
+	vendor := &listingVendor{name: "OpenAI", models: []string{"gpt-4o"}}
+	manager := NewVendorsManager()
+	manager.AddVendors(vendor)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+	go func() {
+		manager.PrefetchModels(ctx, time.Hour, 0, nil)
+		close(done)
+	}()
+	for deadline := time.Now().Add(time.Second); vendor.calls.Load() == 0 && time.Now().Before(deadline); {
+		time.Sleep(time.Millisecond)
+	}
+	cancel()
+	select {
+	case <-done:
+	case <-time.After(time.Second):
+		t.Fatal("PrefetchModels() didn't return once the context was done")
+	}
+}
@@ -2,6 +2,7 @@ package ai
 
 import (
 	"context"
+	"errors"
 
 	"github.com/danielmiessler/fabric/internal/chat"
 	"github.com/danielmiessler/fabric/internal/plugins"
@@ -16,3 +17,27 @@ type Vendor interface {
 	Send(context.Context, []*chat.ChatCompletionMessage, *domain.ChatOptions) (string, error)
 	NeedsRawMode(modelName string) bool
 }
+
+// Transcriber is implemented by vendors that offer speech-to-text
+type Transcriber interface {
+	TranscribeFile(ctx context.Context, filePath string, model string, language string) (string, error)
+}
+
+// QuotaReporter is implemented by vendors whose API reports the spend or remaining quota of the API key.
+// Vendors supporting it only for some providers return ErrQuotaNotSupported for the others.
+type QuotaReporter interface {
+	GetQuota(context.Context) (*domain.Quota, error)
+}
+
+var ErrQuotaNotSupported = errors.New("the vendor does not report its quota")
+
+// EndpointReporter is implemented by vendors calling a configurable API, Endpoint returns its base URL.
+// It is recorded in the environment of the runs.
+type EndpointReporter interface {
+	Endpoint() string
+}
+
+// UsageReporter is implemented by vendors that report token usage and the finish reason of a response
+type UsageReporter interface {
+	SendWithUsage(context.Context, []*chat.ChatCompletionMessage, *domain.ChatOptions) (string, *domain.Usage, error)
+}
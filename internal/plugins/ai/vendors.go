@@ -3,11 +3,13 @@ package ai
 import (
 	"bytes"
 	"context"
+	"errors"
 	"fmt"
 	"sort"
 	"strings"
 	"sync"
 
+	"github.com/danielmiessler/fabric/internal/domain"
 	"github.com/danielmiessler/fabric/internal/plugins"
 )
 
@@ -15,6 +17,8 @@ func NewVendorsManager() *VendorsManager {
 	return &VendorsManager{
 		Vendors:       []Vendor{},
 		VendorsByName: map[string]Vendor{},
+		Breaker: NewCircuitBreaker(DefaultBreakerFailureThreshold,
+			DefaultBreakerWindow, DefaultBreakerCooldown),
 	}
 }
 
@@ -23,6 +27,10 @@ type VendorsManager struct {
 	Vendors       []Vendor
 	VendorsByName map[string]Vendor
 	Models        *VendorsModels
+	Breaker       *CircuitBreaker
+
+	// modelsMu guards Models, which PrefetchModels replaces in the background
+	modelsMu sync.Mutex
 }
 
 func (o *VendorsManager) AddVendors(vendors ...Vendor) {
@@ -35,7 +43,9 @@ func (o *VendorsManager) AddVendors(vendors ...Vendor) {
 func (o *VendorsManager) Clear(vendors ...Vendor) {
 	o.VendorsByName = map[string]Vendor{}
 	o.Vendors = []Vendor{}
+	o.modelsMu.Lock()
 	o.Models = nil
+	o.modelsMu.Unlock()
 }
 
 func (o *VendorsManager) SetupFillEnvFileContent(envFileContent *bytes.Buffer) {
@@ -45,6 +55,8 @@ func (o *VendorsManager) SetupFillEnvFileContent(envFileContent *bytes.Buffer) {
 }
 
 func (o *VendorsManager) GetModels() (ret *VendorsModels, err error) {
+	o.modelsMu.Lock()
+	defer o.modelsMu.Unlock()
 	if o.Models == nil {
 		err = o.readModels()
 	}
@@ -156,3 +168,48 @@ type modelResult struct {
 	models     []string
 	err        error
 }
+
+// Quota fetches the quota of the vendor, ErrQuotaNotSupported if the vendor doesn't report it
+func (o *VendorsManager) Quota(ctx context.Context, vendorName string) (ret *domain.Quota, err error) {
+	reporter, ok := o.FindByName(vendorName).(QuotaReporter)
+	if !ok {
+		err = ErrQuotaNotSupported
+		return
+	}
+	if ret, err = reporter.GetQuota(ctx); err != nil {
+		return
+	}
+	ret.Vendor = vendorName
+	ret.NearCap = ret.IsNearCap()
+	return
+}
+
+// Quotas fetches the quota of every vendor reporting it, concurrently. Failed requests are
+// reported in the Error of the quota.
+func (o *VendorsManager) Quotas(ctx context.Context) (ret []*domain.Quota) {
+	var wg sync.WaitGroup
+	var mu sync.Mutex
+	for _, vendor := range o.Vendors {
+		if _, ok := vendor.(QuotaReporter); !ok {
+			continue
+		}
+		wg.Add(1)
+		go func(name string) {
+			defer wg.Done()
+			quota, err := o.Quota(ctx, name)
+			if errors.Is(err, ErrQuotaNotSupported) {
+				return
+			}
+			if err != nil {
+				quota = &domain.Quota{Vendor: name, Error: err.Error()}
+			}
+			mu.Lock()
+			ret = append(ret, quota)
+			mu.Unlock()
+		}(vendor.GetName())
+	}
+	wg.Wait()
+
+	sort.Slice(ret, func(i, j int) bool { return ret[i].Vendor < ret[j].Vendor })
+	return
+}
@@ -0,0 +1,148 @@
+package fsdb
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+
+	"github.com/danielmiessler/fabric/internal/tools/backup"
+	"github.com/danielmiessler/fabric/internal/util"
+	"github.com/joho/godotenv"
+)
+
+const (
+	settingsCollection = "settings"
+	backupSettingsKey  = "backup"
+
+	// HooksBackupName is the hooks file in the backups, its commands run on the machine restoring it
+	HooksBackupName = "hooks.yaml"
+
+	// BackupsDirName is the directory of the config the REST API and the scheduled backups write to and
+	// restore from, the archives hold the .env
+	BackupsDirName = "backups"
+)
+
+// BackupSettings configure the scheduled backups
+type BackupSettings struct {
+	Enabled   bool   `json:"enabled"`
+	Directory string `json:"directory"`
+	Schedule  string `json:"schedule"` // five field cron expression
+}
+
+func (o *Db) GetBackupSettings() (ret *BackupSettings, err error) {
+	ret = &BackupSettings{}
+	_, err = o.Store.Get(settingsCollection, backupSettingsKey, ret)
+	return
+}
+
+func (o *Db) SaveBackupSettings(settings *BackupSettings) (err error) {
+	return o.Store.Put(settingsCollection, backupSettingsKey, settings)
+}
+
+// BackupsDir is the directory the REST API and the scheduled backups are kept in
+func (o *Db) BackupsDir() string {
+	return o.FilePath(BackupsDirName)
+}
+
+// BackupPath resolves a directory or an archive of the backups directory, relative to it or absolute below
+// it, the backups directory itself when name is empty. Paths outside of it are refused.
+func (o *Db) BackupPath(name string) (ret string, err error) {
+	dir := o.BackupsDir()
+	if err = os.MkdirAll(dir, 0700); err != nil {
+		return
+	}
+	if name == "" {
+		name = dir
+	} else if !filepath.IsAbs(name) {
+		name = filepath.Join(dir, name)
+	}
+	var ok bool
+	if ret, ok = util.ResolveBelow(name, []string{dir}); !ok {
+		err = fmt.Errorf("%s is not below the backups directory %s", name, dir)
+	}
+	return
+}
+
+// backupSources are the user data kept in backups. Downloaded patterns are not included,
+// they are restored with --updatepatterns, but the custom patterns directories are, each under an id of its
+// path so they are restored to the same directory when CustomPatternsDir is reordered.
+func (o *Db) backupSources(storePath string) (ret []backup.Source) {
+	ret = []backup.Source{
+		{Name: StoreFileName, Path: storePath},
+		{Name: LayoutMigrationsFileName, Path: o.FilePath(LayoutMigrationsFileName)}, // goes with the store
+		{Name: ".env", Path: o.EnvFilePath},
+		{Name: HooksBackupName, Path: o.FilePath("hooks.yaml")},
+		{Name: "features.yaml", Path: o.FilePath("features.yaml")},
+		{Name: "export_styles", Path: o.FilePath("export_styles")},
+		{Name: "contexts", Path: o.Contexts.Dir},
+		{Name: "sessions", Path: o.Sessions.Dir},
+		{Name: "jobs", Path: o.Jobs.Dir},
+		{Name: "watches", Path: o.Watches.Dir},
+		{Name: "trash", Path: o.Trash.Dir},
+	}
+	for _, dir := range o.Patterns.CustomPatternsDirs() {
+		ret = append(ret, backup.Source{Name: customPatternsBackupName(dir), Path: dir})
+	}
+	return
+}
+
+func customPatternsBackupName(dir string) string {
+	if abs, err := filepath.Abs(dir); err == nil {
+		dir = abs
+	}
+	sum := sha256.Sum256([]byte(filepath.ToSlash(dir)))
+	return "custom_patterns_" + hex.EncodeToString(sum[:6])
+}
+
+// Backup writes the user data into a timestamped archive in destDir
+func (o *Db) Backup(destDir string) (archivePath string, err error) {
+	var tmpDir string
+	if tmpDir, err = os.MkdirTemp("", "fabric-backup"); err != nil {
+		return
+	}
+	defer os.RemoveAll(tmpDir)
+
+	snapshot := filepath.Join(tmpDir, StoreFileName)
+	if err = o.Store.Snapshot(snapshot); err != nil {
+		err = fmt.Errorf("could not snapshot database: %v", err)
+		return
+	}
+	return backup.Create(destDir, o.backupSources(snapshot))
+}
+
+// Restore replaces the user data with the content of the archive after verifying it, except the sources
+// named in skip, which are kept as they are; skipped returns the ones the archive has.
+// The database is closed and reopened on next use, the variables of the restored .env replace the ones
+// of the environment.
+func (o *Db) Restore(archivePath string, skip ...string) (skipped []string, err error) {
+	var manifest *backup.Manifest
+	if manifest, err = backup.Verify(archivePath); err != nil {
+		return
+	}
+	skipped = manifest.Sources(skip)
+	if err = o.Store.Close(); err != nil {
+		return
+	}
+	// stale write-ahead log files would be applied to the restored database
+	for _, suffix := range []string{"-wal", "-shm"} {
+		if err = os.RemoveAll(o.Store.Path + suffix); err != nil {
+			return
+		}
+	}
+	var sources []backup.Source
+	for _, source := range o.backupSources(o.Store.Path) {
+		if !slices.Contains(skip, source.Name) {
+			sources = append(sources, source)
+		}
+	}
+	if err = backup.Restore(archivePath, sources); err != nil {
+		return
+	}
+	if err = godotenv.Overload(o.EnvFilePath); err != nil && os.IsNotExist(err) {
+		err = nil
+	}
+	return
+}
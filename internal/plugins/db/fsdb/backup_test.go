@@ -0,0 +1,74 @@
+package fsdb
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestDb_BackupAndRestore(t *testing.T) {
+	db := NewDb(t.TempDir())
+	defer db.Store.Close()
+	os.WriteFile(db.EnvFilePath, []byte("FABRIC_TEST_RESTORED=archived\n"), 0644)
+	os.WriteFile(db.FilePath("hooks.yaml"), []byte("pre: []\n"), 0644)
+	personal, team := t.TempDir(), t.TempDir()
+	os.WriteFile(filepath.Join(personal, "mine"), []byte("personal"), 0644)
+	os.WriteFile(filepath.Join(team, "ours"), []byte("team"), 0644)
+	db.Patterns.CustomPatternsDir = personal + string(os.PathListSeparator) + team
+
+	archivePath, err := db.Backup(t.TempDir())
+	if err != nil {
+		t.Fatalf("Backup failed: %v", err)
+	}
+
+	// the directories are restored by their path, whatever their order
+	db.Patterns.CustomPatternsDir = team + string(os.PathListSeparator) + personal
+	os.WriteFile(filepath.Join(personal, "mine"), []byte("changed"), 0644)
+	os.WriteFile(db.FilePath("hooks.yaml"), []byte("post: []\n"), 0644)
+	t.Setenv("FABRIC_TEST_RESTORED", "current")
+
+	skipped, err := db.Restore(archivePath, HooksBackupName)
+	if err != nil {
+		t.Fatalf("Restore failed: %v", err)
+	}
+	if len(skipped) != 1 || skipped[0] != HooksBackupName {
+		t.Errorf("expected the hooks to be skipped, got %v", skipped)
+	}
+	if data, _ := os.ReadFile(filepath.Join(personal, "mine")); string(data) != "personal" {
+		t.Errorf("expected the personal pattern to be restored, got %q", data)
+	}
+	if _, err = os.Stat(filepath.Join(personal, "ours")); !os.IsNotExist(err) {
+		t.Errorf("expected the team patterns to stay in their directory")
+	}
+	if data, _ := os.ReadFile(db.FilePath("hooks.yaml")); string(data) != "post: []\n" {
+		t.Errorf("expected the hooks to be kept, got %q", data)
+	}
+	if value := os.Getenv("FABRIC_TEST_RESTORED"); value != "archived" {
+		t.Errorf("expected the restored .env to apply without a restart, got %q", value)
+	}
+}
+
+func TestDb_BackupPath(t *testing.T) {
+	db := NewDb(t.TempDir())
+	defer db.Store.Close()
+	dir, err := filepath.EvalSymlinks(t.TempDir())
+	if err != nil {
+		t.Fatalf("failed to resolve temp dir: %v", err)
+	}
+	db.Dir = dir
+
+	if ret, err := db.BackupPath(""); err != nil || ret != db.BackupsDir() {
+		t.Errorf("expected the backups directory, got %q, %v", ret, err)
+	}
+	archive := filepath.Join(db.BackupsDir(), "fabric-backup.tar.gz")
+	for _, name := range []string{"fabric-backup.tar.gz", archive} {
+		if ret, err := db.BackupPath(name); err != nil || ret != archive {
+			t.Errorf("expected %s to resolve to %s, got %q, %v", name, archive, ret, err)
+		}
+	}
+	for _, name := range []string{"../.env", db.EnvFilePath, t.TempDir()} {
+		if _, err := db.BackupPath(name); err == nil {
+			t.Errorf("expected %s to be refused", name)
+		}
+	}
+}
@@ -4,6 +4,7 @@ import (
 	"fmt"
 	"os"
 	"path/filepath"
+	"strconv"
 	"strings"
 	"time"
 
@@ -29,15 +30,47 @@ func NewDb(dir string) (db *Db) {
 	db.Contexts = &ContextsEntity{
 		&StorageEntity{Label: "Contexts", Dir: db.FilePath("contexts")}}
 
+	db.Jobs = &JobsEntity{
+		StorageEntity: &StorageEntity{Label: "Jobs", Dir: db.FilePath("jobs"), FileExtension: ".json"},
+		InputsDir:     db.FilePath(JobInputsDirName)}
+
+	db.Watches = &WatchesEntity{
+		&StorageEntity{Label: "Watches", Dir: db.FilePath("watches"), FileExtension: ".json"}}
+
+	db.Proposals = &ProposalsEntity{
+		StorageEntity: &StorageEntity{Label: "Proposals", Dir: db.FilePath("proposals"), FileExtension: ".json"}}
+
+	db.Store = &Store{Path: db.FilePath(StoreFileName)}
+
+	db.Patterns.MetadataStore = db.Store
+
+	db.History = &HistoryEntity{Store: db.Store}
+
+	db.Starred = &StarredEntity{Store: db.Store, History: db.History}
+
+	db.Trash = &TrashEntity{Store: db.Store, Dir: db.FilePath("trash"), Patterns: db.Patterns, History: db.History}
+
+	db.Variables = &VariablesEntity{Store: db.Store}
+
 	return
 }
 
 type Db struct {
 	Dir string
 
-	Patterns *PatternsEntity
-	Sessions *SessionsEntity
-	Contexts *ContextsEntity
+	Patterns  *PatternsEntity
+	Sessions  *SessionsEntity
+	Contexts  *ContextsEntity
+	Jobs      *JobsEntity
+	Watches   *WatchesEntity
+	Proposals *ProposalsEntity
+	History   *HistoryEntity
+	Starred   *StarredEntity
+	Trash     *TrashEntity
+	Variables *VariablesEntity
+
+	// Store is the SQLite database shared by the run history and the keyed collections
+	Store *Store
 
 	EnvFilePath string
 }
@@ -63,6 +96,12 @@ func (o *Db) Configure() (err error) {
 		o.Patterns.CustomPatternsDir = customPatternsDir
 	}
 
+	if workers := os.Getenv("PATTERN_LOAD_WORKERS"); workers != "" {
+		if o.Patterns.LoadWorkers, err = strconv.Atoi(workers); err != nil {
+			return fmt.Errorf("invalid PATTERN_LOAD_WORKERS %q: %v", workers, err)
+		}
+	}
+
 	if err = o.Patterns.Configure(); err != nil {
 		return
 	}
@@ -75,6 +114,27 @@ func (o *Db) Configure() (err error) {
 		return
 	}
 
+	if err = o.Jobs.Configure(); err != nil {
+		return
+	}
+
+	if err = o.Watches.Configure(); err != nil {
+		return
+	}
+
+	if err = o.Proposals.Configure(); err != nil {
+		return
+	}
+
+	// data of older versions is upgraded in place, a failed migration leaves the data untouched
+	for _, result := range o.MigrateLayouts() {
+		if result.Err != nil {
+			fmt.Fprintf(os.Stderr, "Warning: migration %s failed: %v\n", result.Name, result.Err)
+		} else {
+			fmt.Fprintf(os.Stderr, "Migrated %s: %s\n", result.Name, result.Report)
+		}
+	}
+
 	return
 }
 
@@ -0,0 +1,442 @@
+package fsdb
+
+import (
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/danielmiessler/fabric/internal/domain"
+)
+
+// run ids are their UTC start time, so they sort chronologically
+const runIdLayout = "20060102T150405.000000000Z"
+
+// HistoryDayLayout is the format of the days runs are grouped by
+const HistoryDayLayout = "2006-01-02"
+
+// Run is a single pattern execution kept in the run history
+type Run struct {
+	Id           string                    `json:"id"`
+	Timestamp    time.Time                 `json:"timestamp"`
+	JobName      string                    `json:"jobName,omitempty"` // set for scheduled runs
+	PatternName  string                    `json:"patternName,omitempty"`
+	ContextName  string                    `json:"contextName,omitempty"`
+	SessionName  string                    `json:"sessionName,omitempty"`
+	StrategyName string                    `json:"strategyName,omitempty"`
+	Variables    map[string]string         `json:"variables,omitempty"`
+	Language     string                    `json:"language,omitempty"`
+	Input        string                    `json:"input"`
+	Output       string                    `json:"output"`
+	Error        string                    `json:"error,omitempty"`
+	Metadata     *domain.ExecutionMetadata `json:"metadata,omitempty"`
+	Notes        string                    `json:"notes,omitempty"` // written by the user, why the output is good or bad
+}
+
+const runColumns = `id, timestamp, job_name, pattern_name, context_name, session_name, strategy_name,
+	input, output, error, metadata, variables, language, notes`
+
+// HistoryEntity keeps the run history in the SQLite store
+type HistoryEntity struct {
+	Store *Store
+}
+
+func (o *HistoryEntity) conn() (*sql.DB, error) {
+	return o.Store.Conn()
+}
+
+// SaveRun stores the run, its id and timestamp are set when missing
+func (o *HistoryEntity) SaveRun(run *Run) (err error) {
+	if run.Timestamp.IsZero() {
+		run.Timestamp = time.Now()
+	}
+	if run.Id == "" {
+		run.Id = run.Timestamp.UTC().Format(runIdLayout)
+	}
+
+	var db *sql.DB
+	if db, err = o.conn(); err != nil {
+		return
+	}
+	return insertRun(db, run, "INSERT OR REPLACE")
+}
+
+// SetNotes sets the notes of the run, empty notes remove them
+func (o *HistoryEntity) SetNotes(id string, notes string) (ret *Run, err error) {
+	var db *sql.DB
+	if db, err = o.conn(); err != nil {
+		return
+	}
+	var result sql.Result
+	if result, err = db.Exec(`UPDATE runs SET notes = ? WHERE id = ?`, strings.TrimSpace(notes), id); err != nil {
+		return
+	}
+	if updated, _ := result.RowsAffected(); updated == 0 {
+		err = fmt.Errorf("run %s not found", id)
+		return
+	}
+	return o.GetRun(id)
+}
+
+func (o *HistoryEntity) Exists(id string) (ret bool) {
+	db, err := o.conn()
+	if err != nil {
+		return
+	}
+	var count int
+	if err = db.QueryRow(`SELECT COUNT(*) FROM runs WHERE id = ?`, id).Scan(&count); err == nil {
+		ret = count > 0
+	}
+	return
+}
+
+func (o *HistoryEntity) GetRun(id string) (ret *Run, err error) {
+	var db *sql.DB
+	if db, err = o.conn(); err != nil {
+		return
+	}
+	if ret, err = scanRun(db.QueryRow(`SELECT `+runColumns+` FROM runs WHERE id = ?`, id)); err == sql.ErrNoRows {
+		err = fmt.Errorf("run %s not found", id)
+	}
+	return
+}
+
+// DeleteRun removes the run from the history, see TrashEntity.TrashRun to keep it restorable
+func (o *HistoryEntity) DeleteRun(id string) (err error) {
+	var db *sql.DB
+	if db, err = o.conn(); err != nil {
+		return
+	}
+	_, err = db.Exec(`DELETE FROM runs WHERE id = ?`, id)
+	return
+}
+
+// ListRuns returns the runs started in [from, to), newest first
+func (o *HistoryEntity) ListRuns(from, to time.Time) (ret []*Run, err error) {
+	var db *sql.DB
+	if db, err = o.conn(); err != nil {
+		return
+	}
+
+	var rows *sql.Rows
+	if rows, err = db.Query(`SELECT `+runColumns+` FROM runs WHERE timestamp >= ? AND timestamp < ? ORDER BY timestamp DESC, id DESC`,
+		from.UnixNano(), to.UnixNano()); err != nil {
+		return
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var run *Run
+		if run, err = scanRun(rows); err != nil {
+			return
+		}
+		ret = append(ret, run)
+	}
+	err = rows.Err()
+	return
+}
+
+// likeEscaper escapes the wildcards of LIKE patterns
+var likeEscaper = strings.NewReplacer(`\`, `\\`, `%`, `\%`, `_`, `\_`)
+
+// SearchRuns returns the latest runs, up to limit, with the query in their pattern, job, input, output or notes,
+// ignoring the case of ASCII letters
+func (o *HistoryEntity) SearchRuns(query string, limit int) (ret []*Run, err error) {
+	return o.FilterRuns(RunFilter{Query: query, Limit: limit})
+}
+
+// RunFilter selects the runs of FilterRuns, its empty fields select every run
+type RunFilter struct {
+	Query   string    // in the pattern, job, input, output or notes, ignoring the case of ASCII letters
+	Pattern string    // the exact pattern name
+	Model   string    // the exact model of the metadata
+	From    time.Time // inclusive
+	To      time.Time // exclusive
+	Limit   int       // 0 returns every run
+	Offset  int
+}
+
+// FilterRuns returns the runs of the filter, newest first
+func (o *HistoryEntity) FilterRuns(filter RunFilter) (ret []*Run, err error) {
+	var db *sql.DB
+	if db, err = o.conn(); err != nil {
+		return
+	}
+
+	var conditions []string
+	var args []any
+	if filter.Query != "" {
+		pattern := "%" + likeEscaper.Replace(filter.Query) + "%"
+		conditions = append(conditions, `(pattern_name LIKE ? ESCAPE '\' OR job_name LIKE ? ESCAPE '\' OR input LIKE ? ESCAPE '\' OR output LIKE ? ESCAPE '\' OR notes LIKE ? ESCAPE '\')`)
+		args = append(args, pattern, pattern, pattern, pattern, pattern)
+	}
+	if filter.Pattern != "" {
+		conditions = append(conditions, `pattern_name = ?`)
+		args = append(args, filter.Pattern)
+	}
+	if filter.Model != "" {
+		conditions = append(conditions, `json_extract(metadata, '$.model') = ?`)
+		args = append(args, filter.Model)
+	}
+	if !filter.From.IsZero() {
+		conditions = append(conditions, `timestamp >= ?`)
+		args = append(args, filter.From.UnixNano())
+	}
+	if !filter.To.IsZero() {
+		conditions = append(conditions, `timestamp < ?`)
+		args = append(args, filter.To.UnixNano())
+	}
+
+	query := `SELECT ` + runColumns + ` FROM runs`
+	if len(conditions) > 0 {
+		query += ` WHERE ` + strings.Join(conditions, ` AND `)
+	}
+	query += ` ORDER BY timestamp DESC, id DESC`
+	if filter.Limit > 0 {
+		query += ` LIMIT ? OFFSET ?`
+		args = append(args, filter.Limit, filter.Offset)
+	}
+
+	var rows *sql.Rows
+	if rows, err = db.Query(query, args...); err != nil {
+		return
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var run *Run
+		if run, err = scanRun(rows); err != nil {
+			return
+		}
+		ret = append(ret, run)
+	}
+	err = rows.Err()
+	return
+}
+
+// RunFacets returns the distinct patterns and models of the runs, sorted, to filter them by
+func (o *HistoryEntity) RunFacets() (patterns []string, models []string, err error) {
+	var db *sql.DB
+	if db, err = o.conn(); err != nil {
+		return
+	}
+	if patterns, err = queryStrings(db, `SELECT DISTINCT pattern_name FROM runs WHERE pattern_name != '' ORDER BY pattern_name`); err != nil {
+		return
+	}
+	models, err = queryStrings(db, `SELECT DISTINCT json_extract(metadata, '$.model') AS model FROM runs
+		WHERE model IS NOT NULL AND model != '' ORDER BY model`)
+	return
+}
+
+func queryStrings(db *sql.DB, query string) (ret []string, err error) {
+	var rows *sql.Rows
+	if rows, err = db.Query(query); err != nil {
+		return
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var value string
+		if err = rows.Scan(&value); err != nil {
+			return
+		}
+		ret = append(ret, value)
+	}
+	err = rows.Err()
+	return
+}
+
+// CountRunsByDay returns the number of runs started in [from, to) per local day
+func (o *HistoryEntity) CountRunsByDay(from, to time.Time) (ret map[string]int, err error) {
+	var db *sql.DB
+	if db, err = o.conn(); err != nil {
+		return
+	}
+
+	var rows *sql.Rows
+	if rows, err = db.Query(`SELECT timestamp FROM runs WHERE timestamp >= ? AND timestamp < ?`,
+		from.UnixNano(), to.UnixNano()); err != nil {
+		return
+	}
+	defer rows.Close()
+
+	ret = map[string]int{}
+	for rows.Next() {
+		var timestamp int64
+		if err = rows.Scan(&timestamp); err != nil {
+			return
+		}
+		ret[time.Unix(0, timestamp).Local().Format(HistoryDayLayout)]++
+	}
+	err = rows.Err()
+	return
+}
+
+// MedianLatencyByModel returns the median latency in milliseconds of the successful runs started since the
+// given time, by model
+func (o *HistoryEntity) MedianLatencyByModel(since time.Time) (ret map[string]int64, err error) {
+	var db *sql.DB
+	if db, err = o.conn(); err != nil {
+		return
+	}
+
+	var rows *sql.Rows
+	if rows, err = db.Query(`SELECT metadata FROM runs WHERE timestamp >= ? AND error = '' AND metadata IS NOT NULL`,
+		since.UnixNano()); err != nil {
+		return
+	}
+	defer rows.Close()
+
+	latencies := map[string][]int64{}
+	for rows.Next() {
+		var metadata string
+		if err = rows.Scan(&metadata); err != nil {
+			return
+		}
+		var parsed domain.ExecutionMetadata
+		// runs with unreadable metadata don't count
+		if json.Unmarshal([]byte(metadata), &parsed) != nil || parsed.Model == "" || parsed.LatencyMs <= 0 {
+			continue
+		}
+		latencies[parsed.Model] = append(latencies[parsed.Model], parsed.LatencyMs)
+	}
+	if err = rows.Err(); err != nil {
+		return
+	}
+
+	ret = map[string]int64{}
+	for model, values := range latencies {
+		sort.Slice(values, func(i, j int) bool { return values[i] < values[j] })
+		ret[model] = values[len(values)/2]
+	}
+	return
+}
+
+// RunStats counts the runs of a pattern with a model, the failed ones ended with an error or were cut
+// at the token limit
+type RunStats struct {
+	Runs   int `json:"runs"`
+	Failed int `json:"failed"`
+}
+
+// PatternRunStats returns the RunStats of the runs of the pattern started since the given time, by model
+func (o *HistoryEntity) PatternRunStats(pattern string, since time.Time) (ret map[string]RunStats, err error) {
+	var db *sql.DB
+	if db, err = o.conn(); err != nil {
+		return
+	}
+
+	var rows *sql.Rows
+	if rows, err = db.Query(`SELECT json_extract(metadata, '$.model') AS model, COUNT(*),
+		SUM(error != '' OR json_extract(metadata, '$.finishReason') = 'length')
+		FROM runs WHERE pattern_name = ? AND timestamp >= ? AND model IS NOT NULL AND model != ''
+		GROUP BY model`, pattern, since.UnixNano()); err != nil {
+		return
+	}
+	defer rows.Close()
+
+	ret = map[string]RunStats{}
+	for rows.Next() {
+		var model string
+		var stats RunStats
+		if err = rows.Scan(&model, &stats.Runs, &stats.Failed); err != nil {
+			return
+		}
+		ret[model] = stats
+	}
+	err = rows.Err()
+	return
+}
+
+// PatternUsage is how often and when a pattern was last run
+type PatternUsage struct {
+	Runs    int       `json:"runs"`
+	LastRun time.Time `json:"lastRun"`
+}
+
+// PatternUsage returns the PatternUsage of the patterns of the runs, by pattern
+func (o *HistoryEntity) PatternUsage() (ret map[string]PatternUsage, err error) {
+	var db *sql.DB
+	if db, err = o.conn(); err != nil {
+		return
+	}
+
+	var rows *sql.Rows
+	if rows, err = db.Query(`SELECT pattern_name, COUNT(*), MAX(timestamp) FROM runs
+		WHERE pattern_name != '' GROUP BY pattern_name`); err != nil {
+		return
+	}
+	defer rows.Close()
+
+	ret = map[string]PatternUsage{}
+	for rows.Next() {
+		var pattern string
+		var usage PatternUsage
+		var lastRun int64
+		if err = rows.Scan(&pattern, &usage.Runs, &lastRun); err != nil {
+			return
+		}
+		usage.LastRun = time.Unix(0, lastRun)
+		ret[pattern] = usage
+	}
+	err = rows.Err()
+	return
+}
+
+type rowScanner interface {
+	Scan(dest ...any) error
+}
+
+func scanRun(row rowScanner) (ret *Run, err error) {
+	ret = &Run{}
+	var timestamp int64
+	var metadata, variables sql.NullString
+	if err = row.Scan(&ret.Id, &timestamp, &ret.JobName, &ret.PatternName, &ret.ContextName, &ret.SessionName,
+		&ret.StrategyName, &ret.Input, &ret.Output, &ret.Error, &metadata, &variables, &ret.Language, &ret.Notes); err != nil {
+		ret = nil
+		return
+	}
+	if variables.Valid {
+		if err = json.Unmarshal([]byte(variables.String), &ret.Variables); err != nil {
+			err = fmt.Errorf("invalid variables of run %s: %v", ret.Id, err)
+			return
+		}
+	}
+	ret.Timestamp = time.Unix(0, timestamp)
+	if metadata.Valid {
+		ret.Metadata = &domain.ExecutionMetadata{}
+		if err = json.Unmarshal([]byte(metadata.String), ret.Metadata); err != nil {
+			err = fmt.Errorf("invalid metadata of run %s: %v", ret.Id, err)
+		}
+	}
+	return
+}
+
+type execer interface {
+	Exec(query string, args ...any) (sql.Result, error)
+}
+
+func insertRun(db execer, run *Run, verb string) (err error) {
+	var metadata sql.NullString
+	if run.Metadata != nil {
+		var data []byte
+		if data, err = json.Marshal(run.Metadata); err != nil {
+			return
+		}
+		metadata = sql.NullString{String: string(data), Valid: true}
+	}
+	var variables sql.NullString
+	if len(run.Variables) > 0 {
+		var data []byte
+		if data, err = json.Marshal(run.Variables); err != nil {
+			return
+		}
+		variables = sql.NullString{String: string(data), Valid: true}
+	}
+	_, err = db.Exec(verb+` INTO runs (`+runColumns+`) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)`,
+		run.Id, run.Timestamp.UnixNano(), run.JobName, run.PatternName, run.ContextName, run.SessionName,
+		run.StrategyName, run.Input, run.Output, run.Error, metadata, variables, run.Language, run.Notes)
+	return
+}
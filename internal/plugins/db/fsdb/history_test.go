@@ -0,0 +1,248 @@
+package fsdb
+
+import (
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/danielmiessler/fabric/internal/domain"
+)
+
+func TestHistory_SaveAndListRuns(t *testing.T) {
+	history := &HistoryEntity{Store: &Store{Path: filepath.Join(t.TempDir(), StoreFileName)}}
+	defer history.Store.Close()
+
+	day := time.Date(2024, 3, 10, 12, 0, 0, 0, time.Local)
+	runs := []*Run{
+		{Timestamp: day.Add(-24 * time.Hour), PatternName: "summarize", Output: "yesterday"},
+		{Timestamp: day, PatternName: "summarize", Output: "first"},
+		{Timestamp: day.Add(time.Hour), PatternName: "extract_wisdom", Output: "second"},
+	}
+	for _, run := range runs {
+		if err := history.SaveRun(run); err != nil {
+			t.Fatalf("failed to save run: %v", err)
+		}
+		if run.Id == "" {
+			t.Fatalf("expected run id to be set")
+		}
+	}
+
+	dayStart := time.Date(2024, 3, 10, 0, 0, 0, 0, time.Local)
+	ret, err := history.ListRuns(dayStart, dayStart.AddDate(0, 0, 1))
+	if err != nil {
+		t.Fatalf("failed to list runs: %v", err)
+	}
+	if len(ret) != 2 {
+		t.Fatalf("expected 2 runs of the day, got %d", len(ret))
+	}
+	if ret[0].Output != "second" || ret[1].Output != "first" {
+		t.Errorf("expected newest run first, got %q, %q", ret[0].Output, ret[1].Output)
+	}
+
+	counts, err := history.CountRunsByDay(dayStart.AddDate(0, 0, -7), dayStart.AddDate(0, 0, 7))
+	if err != nil {
+		t.Fatalf("failed to count runs: %v", err)
+	}
+	if counts["2024-03-10"] != 2 || counts["2024-03-09"] != 1 {
+		t.Errorf("unexpected counts per day: %v", counts)
+	}
+}
+
+func TestHistory_ListRunsWithoutHistory(t *testing.T) {
+	history := &HistoryEntity{Store: &Store{Path: filepath.Join(t.TempDir(), StoreFileName)}}
+	defer history.Store.Close()
+	ret, err := history.ListRuns(time.Time{}, time.Now())
+	if err != nil || len(ret) != 0 {
+		t.Errorf("expected no runs and no error, got %v, %v", ret, err)
+	}
+}
+
+func TestHistory_SearchRuns(t *testing.T) {
+	history := &HistoryEntity{Store: &Store{Path: filepath.Join(t.TempDir(), StoreFileName)}}
+	defer history.Store.Close()
+
+	day := time.Date(2024, 3, 10, 12, 0, 0, 0, time.Local)
+	runs := []*Run{
+		{Timestamp: day, PatternName: "summarize", Input: "Quarterly Report", Output: "revenue grew"},
+		{Timestamp: day.Add(time.Hour), PatternName: "extract_wisdom", Input: "podcast", Output: "the report says 100% growth"},
+		{Timestamp: day.Add(2 * time.Hour), PatternName: "summarize", Input: "notes", Output: "nothing"},
+	}
+	for _, run := range runs {
+		if err := history.SaveRun(run); err != nil {
+			t.Fatalf("failed to save run: %v", err)
+		}
+	}
+
+	ret, err := history.SearchRuns("report", 10)
+	if err != nil {
+		t.Fatalf("failed to search runs: %v", err)
+	}
+	if len(ret) != 2 || ret[0].PatternName != "extract_wisdom" || ret[1].Input != "Quarterly Report" {
+		t.Errorf("expected the two runs about the report, newest first, got %+v", ret)
+	}
+
+	if ret, err = history.SearchRuns("summarize", 1); err != nil || len(ret) != 1 || ret[0].Input != "notes" {
+		t.Errorf("expected the latest summarize run only, got %+v, %v", ret, err)
+	}
+	if ret, err = history.SearchRuns("0%", 10); err != nil || len(ret) != 1 {
+		t.Errorf("expected %% to match literally, got %d runs, %v", len(ret), err)
+	}
+	if ret, err = history.SearchRuns("_", 10); err != nil || len(ret) != 1 || ret[0].PatternName != "extract_wisdom" {
+		t.Errorf("expected _ to match literally, got %+v, %v", ret, err)
+	}
+}
+
+func TestHistory_MedianLatencyByModel(t *testing.T) {
+	history := &HistoryEntity{Store: &Store{Path: filepath.Join(t.TempDir(), StoreFileName)}}
+	defer history.Store.Close()
+
+	now := time.Now()
+	runs := []*Run{
+		{Timestamp: now.Add(-3 * time.Minute), Metadata: &domain.ExecutionMetadata{Model: "gpt-4o", LatencyMs: 3000}},
+		{Timestamp: now.Add(-2 * time.Minute), Metadata: &domain.ExecutionMetadata{Model: "gpt-4o", LatencyMs: 1000}},
+		{Timestamp: now.Add(-time.Minute), Metadata: &domain.ExecutionMetadata{Model: "gpt-4o", LatencyMs: 2000}},
+		{Timestamp: now, Metadata: &domain.ExecutionMetadata{Model: "llama3", LatencyMs: 500}},
+		{Timestamp: now.Add(time.Second), Error: "timeout", Metadata: &domain.ExecutionMetadata{Model: "llama3", LatencyMs: 60000}},
+		{Timestamp: now.Add(-48 * time.Hour), Metadata: &domain.ExecutionMetadata{Model: "claude", LatencyMs: 900}},
+		{Timestamp: now.Add(2 * time.Second), Output: "no metadata"},
+	}
+	for _, run := range runs {
+		if err := history.SaveRun(run); err != nil {
+			t.Fatalf("failed to save run: %v", err)
+		}
+	}
+
+	ret, err := history.MedianLatencyByModel(now.Add(-24 * time.Hour))
+	if err != nil {
+		t.Fatalf("failed to compute the latencies: %v", err)
+	}
+	if len(ret) != 2 || ret["gpt-4o"] != 2000 || ret["llama3"] != 500 {
+		t.Errorf("unexpected latencies: %v", ret)
+	}
+}
+
+func TestHistory_PatternRunStats(t *testing.T) {
+	history := &HistoryEntity{Store: &Store{Path: filepath.Join(t.TempDir(), StoreFileName)}}
+	defer history.Store.Close()
+
+	now := time.Now()
+	runs := []*Run{
+		{Timestamp: now.Add(-3 * time.Minute), PatternName: "extract_wisdom", Metadata: &domain.ExecutionMetadata{Model: "llama3"}},
+		{Timestamp: now.Add(-2 * time.Minute), PatternName: "extract_wisdom", Error: "context exceeded", Metadata: &domain.ExecutionMetadata{Model: "llama3"}},
+		{Timestamp: now.Add(-time.Minute), PatternName: "extract_wisdom",
+			Metadata: &domain.ExecutionMetadata{Model: "llama3", Usage: domain.Usage{FinishReason: "length"}}},
+		{Timestamp: now, PatternName: "extract_wisdom", Metadata: &domain.ExecutionMetadata{Model: "gpt-4o", Usage: domain.Usage{FinishReason: "stop"}}},
+		{Timestamp: now.Add(time.Second), PatternName: "summarize", Error: "timeout", Metadata: &domain.ExecutionMetadata{Model: "gpt-4o"}},
+		{Timestamp: now.Add(-48 * time.Hour), PatternName: "extract_wisdom", Error: "timeout", Metadata: &domain.ExecutionMetadata{Model: "gpt-4o"}},
+		{Timestamp: now.Add(2 * time.Second), PatternName: "extract_wisdom", Output: "no metadata"},
+	}
+	for _, run := range runs {
+		if err := history.SaveRun(run); err != nil {
+			t.Fatalf("failed to save run: %v", err)
+		}
+	}
+
+	ret, err := history.PatternRunStats("extract_wisdom", now.Add(-24*time.Hour))
+	if err != nil {
+		t.Fatalf("failed to compute the stats: %v", err)
+	}
+	if len(ret) != 2 || ret["llama3"] != (RunStats{Runs: 3, Failed: 2}) || ret["gpt-4o"] != (RunStats{Runs: 1}) {
+		t.Errorf("unexpected stats: %v", ret)
+	}
+}
+
+func TestHistory_PatternUsage(t *testing.T) {
+	history := &HistoryEntity{Store: &Store{Path: filepath.Join(t.TempDir(), StoreFileName)}}
+	defer history.Store.Close()
+
+	now := time.Now()
+	runs := []*Run{
+		{Timestamp: now.Add(-48 * time.Hour), PatternName: "summarize"},
+		{Timestamp: now.Add(-time.Hour), PatternName: "summarize", Error: "timeout"},
+		{Timestamp: now.Add(-2 * time.Hour), PatternName: "summarize"},
+		{Timestamp: now, PatternName: "extract_wisdom"},
+		{Timestamp: now.Add(time.Second), Output: "no pattern"},
+	}
+	for _, run := range runs {
+		if err := history.SaveRun(run); err != nil {
+			t.Fatalf("failed to save run: %v", err)
+		}
+	}
+
+	ret, err := history.PatternUsage()
+	if err != nil {
+		t.Fatalf("failed to compute the usage: %v", err)
+	}
+	if len(ret) != 2 || ret["summarize"].Runs != 3 || ret["extract_wisdom"].Runs != 1 {
+		t.Errorf("unexpected usage: %v", ret)
+	}
+	if !ret["summarize"].LastRun.Equal(now.Add(-time.Hour)) {
+		t.Errorf("unexpected last run of summarize: %v", ret["summarize"].LastRun)
+	}
+}
+
+func TestHistory_FilterRuns(t *testing.T) {
+	history := &HistoryEntity{Store: &Store{Path: filepath.Join(t.TempDir(), StoreFileName)}}
+	defer history.Store.Close()
+
+	day := time.Date(2024, 3, 10, 12, 0, 0, 0, time.Local)
+	runs := []*Run{
+		{Timestamp: day, PatternName: "summarize", Output: "the report", Metadata: &domain.ExecutionMetadata{Model: "gpt-4o"}},
+		{Timestamp: day.Add(time.Hour), PatternName: "extract_wisdom", Output: "the report", Metadata: &domain.ExecutionMetadata{Model: "llama3"}},
+		{Timestamp: day.AddDate(0, 0, 1), PatternName: "summarize", Output: "the report", Metadata: &domain.ExecutionMetadata{Model: "llama3"}},
+		{Timestamp: day.AddDate(0, 0, 2), PatternName: "summarize", Output: "notes"},
+	}
+	for _, run := range runs {
+		if err := history.SaveRun(run); err != nil {
+			t.Fatalf("failed to save run: %v", err)
+		}
+	}
+
+	ret, err := history.FilterRuns(RunFilter{Query: "report", Pattern: "summarize"})
+	if err != nil || len(ret) != 2 || ret[0].Id != runs[2].Id {
+		t.Errorf("expected the summaries of the report, newest first, got %+v, %v", ret, err)
+	}
+	if ret, err = history.FilterRuns(RunFilter{Model: "llama3", To: day.AddDate(0, 0, 1)}); err != nil || len(ret) != 1 || ret[0].Id != runs[1].Id {
+		t.Errorf("expected the llama3 run of the first day, got %+v, %v", ret, err)
+	}
+	if ret, err = history.FilterRuns(RunFilter{From: day.AddDate(0, 0, 1), Limit: 1, Offset: 1}); err != nil || len(ret) != 1 || ret[0].Id != runs[2].Id {
+		t.Errorf("expected the second run since the next day, got %+v, %v", ret, err)
+	}
+
+	patterns, models, err := history.RunFacets()
+	if err != nil {
+		t.Fatalf("failed to get the facets: %v", err)
+	}
+	if strings.Join(patterns, ",") != "extract_wisdom,summarize" || strings.Join(models, ",") != "gpt-4o,llama3" {
+		t.Errorf("unexpected facets %v %v", patterns, models)
+	}
+}
+
+func TestHistory_SetNotes(t *testing.T) {
+	history := &HistoryEntity{Store: &Store{Path: filepath.Join(t.TempDir(), StoreFileName)}}
+	defer history.Store.Close()
+
+	run := &Run{PatternName: "summarize", Output: "short"}
+	if err := history.SaveRun(run); err != nil {
+		t.Fatalf("failed to save run: %v", err)
+	}
+
+	ret, err := history.SetNotes(run.Id, "  too short, missed the second point \n")
+	if err != nil {
+		t.Fatalf("failed to set the notes: %v", err)
+	}
+	if ret.Notes != "too short, missed the second point" {
+		t.Errorf("expected the trimmed notes, got %q", ret.Notes)
+	}
+	if found, err := history.SearchRuns("second point", 10); err != nil || len(found) != 1 {
+		t.Errorf("expected the run found by its notes, got %v, %v", found, err)
+	}
+
+	if ret, err = history.SetNotes(run.Id, ""); err != nil || ret.Notes != "" {
+		t.Errorf("expected the notes removed, got %v, %v", ret, err)
+	}
+	if _, err = history.SetNotes("missing", "notes"); err == nil {
+		t.Errorf("expected an error for a missing run")
+	}
+}
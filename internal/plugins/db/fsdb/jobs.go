@@ -0,0 +1,105 @@
+package fsdb
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// JobInputsDirName is the directory of the config directory the input files of the jobs are read from
+const JobInputsDirName = "inputs"
+
+// Job is a pattern run on a cron schedule
+type Job struct {
+	Name         string            `json:"name"`
+	Schedule     string            `json:"schedule"` // five field cron expression, e.g. "0 9 * * 1-5"
+	Enabled      bool              `json:"enabled"`
+	PatternName  string            `json:"patternName"`
+	Model        string            `json:"model,omitempty"`
+	Vendor       string            `json:"vendor,omitempty"`
+	ContextName  string            `json:"contextName,omitempty"`
+	StrategyName string            `json:"strategyName,omitempty"`
+	Variables    map[string]string `json:"variables,omitempty"`
+	Language     string            `json:"language,omitempty"`
+
+	// Input source, exactly one of them is used: URL is scraped, File is read, Input is sent as is.
+	// File is relative to the inputs directory, see JobsEntity.InputPath.
+	InputURL  string `json:"inputUrl,omitempty"`
+	InputFile string `json:"inputFile,omitempty"`
+	Input     string `json:"input,omitempty"`
+
+	Notify bool `json:"notify,omitempty"` // send a desktop notification when the job completes
+}
+
+type JobsEntity struct {
+	*StorageEntity
+	InputsDir string // the input files of the jobs are read from it only
+}
+
+// InputPath returns the path of an input file of a job, which must be relative to InputsDir and stay in
+// it: the jobs are saved through the REST API, which must not send any file of the user to a vendor
+func (o *JobsEntity) InputPath(file string) (ret string, err error) {
+	if !filepath.IsLocal(file) {
+		err = fmt.Errorf("input file %q must be a relative path in %s", file, o.InputsDir)
+		return
+	}
+	ret = filepath.Join(o.InputsDir, file)
+	return
+}
+
+// ReadInput reads an input file of a job, a link leading out of InputsDir is refused
+func (o *JobsEntity) ReadInput(file string) (ret string, err error) {
+	var path, resolved, dir string
+	if path, err = o.InputPath(file); err != nil {
+		return
+	}
+	if resolved, err = filepath.EvalSymlinks(path); err != nil {
+		return
+	}
+	if dir, err = filepath.EvalSymlinks(o.InputsDir); err != nil {
+		return
+	}
+	if !strings.HasPrefix(resolved, dir+string(os.PathSeparator)) {
+		err = fmt.Errorf("input file %q leads out of %s", file, o.InputsDir)
+		return
+	}
+	var content []byte
+	if content, err = os.ReadFile(resolved); err != nil {
+		return
+	}
+	ret = string(content)
+	return
+}
+
+func (o *JobsEntity) Get(name string) (ret *Job, err error) {
+	ret = &Job{}
+	if err = o.LoadAsJson(name, ret); err != nil {
+		return nil, err
+	}
+	ret.Name = name
+	return
+}
+
+func (o *JobsEntity) SaveJob(job *Job) (err error) {
+	return o.SaveAsJson(job.Name, job)
+}
+
+// GetJobs loads all jobs sorted by name
+func (o *JobsEntity) GetJobs() (ret []*Job, err error) {
+	var names []string
+	if names, err = o.GetNames(); err != nil {
+		return
+	}
+	sort.Strings(names)
+
+	for _, name := range names {
+		var job *Job
+		if job, err = o.Get(name); err != nil {
+			return
+		}
+		ret = append(ret, job)
+	}
+	return
+}
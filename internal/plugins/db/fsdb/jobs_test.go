@@ -0,0 +1,34 @@
+package fsdb
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestJobsEntity_ReadInput(t *testing.T) {
+	db := NewDb(t.TempDir())
+	if err := os.MkdirAll(db.Jobs.InputsDir, 0755); err != nil {
+		t.Fatalf("failed to create the inputs dir: %v", err)
+	}
+	if err := os.WriteFile(filepath.Join(db.Jobs.InputsDir, "notes.md"), []byte("the notes"), 0644); err != nil {
+		t.Fatalf("failed to write the input: %v", err)
+	}
+
+	if input, err := db.Jobs.ReadInput("notes.md"); err != nil || input != "the notes" {
+		t.Errorf("expected the notes, got %q, %v", input, err)
+	}
+
+	secret := filepath.Join(t.TempDir(), "id_rsa")
+	if err := os.WriteFile(secret, []byte("key"), 0600); err != nil {
+		t.Fatalf("failed to write the secret: %v", err)
+	}
+	if err := os.Symlink(secret, filepath.Join(db.Jobs.InputsDir, "link")); err != nil {
+		t.Fatalf("failed to link the secret: %v", err)
+	}
+	for _, file := range []string{secret, "../.env", "link"} {
+		if _, err := db.Jobs.ReadInput(file); err == nil {
+			t.Errorf("expected %s to be refused", file)
+		}
+	}
+}
@@ -0,0 +1,219 @@
+package fsdb
+
+import (
+	"archive/tar"
+	"compress/gzip"
+	"encoding/json"
+	"fmt"
+	"io"
+	"path"
+	"slices"
+	"strings"
+	"time"
+)
+
+const (
+	packManifestName  = "pack.json"
+	packOutputsDir    = "outputs/"
+	packFormatVersion = 1
+	maxPackEntryBytes = 32 << 20
+
+	// KnowledgePackExtension names the knowledge pack archives, e.g. security-notes.fabricpack.tar.gz
+	KnowledgePackExtension = ".fabricpack.tar.gz"
+)
+
+// KnowledgePack describes a pack of starred outputs shared between users
+type KnowledgePack struct {
+	Version     int       `json:"version"`
+	Name        string    `json:"name"`
+	Author      string    `json:"author,omitempty"`
+	Description string    `json:"description,omitempty"`
+	Created     time.Time `json:"created"`
+	Outputs     int       `json:"outputs"`
+}
+
+// ImportResult counts the outputs of an imported pack, outputs already starred are kept as they are
+type ImportResult struct {
+	Pack     KnowledgePack `json:"pack"`
+	Imported int           `json:"imported"`
+	Skipped  int           `json:"skipped"`
+}
+
+// ExportPack writes the starred outputs of the run ids, all of them without ids, into a knowledge pack
+// archive: pack.json describing the pack, and per output its JSON and a markdown rendering to read it
+// without fabric.
+func (o *StarredEntity) ExportPack(w io.Writer, pack KnowledgePack, runIds []string) (err error) {
+	var outputs []*StarredOutput
+	if len(runIds) == 0 {
+		if outputs, err = o.List(); err != nil {
+			return
+		}
+	}
+	for _, id := range runIds {
+		var starred *StarredOutput
+		if starred, err = o.Get(id); err != nil {
+			return
+		}
+		if starred == nil {
+			return fmt.Errorf("run %s is not starred", id)
+		}
+		outputs = append(outputs, starred)
+	}
+	if len(outputs) == 0 {
+		return fmt.Errorf("no starred outputs to export")
+	}
+
+	pack.Version = packFormatVersion
+	pack.Created = time.Now()
+	pack.Outputs = len(outputs)
+
+	gz := gzip.NewWriter(w)
+	tw := tar.NewWriter(gz)
+	if err = writePackEntry(tw, packManifestName, pack); err != nil {
+		return
+	}
+	for _, starred := range outputs {
+		name := packOutputsDir + starred.Run.Id
+		if err = writePackEntry(tw, name+".json", starred); err != nil {
+			return
+		}
+		if err = writePackFile(tw, name+".md", []byte(starred.Markdown())); err != nil {
+			return
+		}
+	}
+	if err = tw.Close(); err != nil {
+		return
+	}
+	return gz.Close()
+}
+
+// ImportPack merges the outputs of a knowledge pack archive into the starred outputs, recording the pack
+// in their provenance. Outputs already starred are skipped, keeping the local annotations.
+func (o *StarredEntity) ImportPack(r io.Reader) (ret *ImportResult, err error) {
+	var gz *gzip.Reader
+	if gz, err = gzip.NewReader(r); err != nil {
+		err = fmt.Errorf("not a knowledge pack: %v", err)
+		return
+	}
+	defer gz.Close()
+
+	var pack *KnowledgePack
+	var outputs []*StarredOutput
+	tr := tar.NewReader(gz)
+	for {
+		var header *tar.Header
+		if header, err = tr.Next(); err == io.EOF {
+			err = nil
+			break
+		} else if err != nil {
+			err = fmt.Errorf("invalid knowledge pack: %v", err)
+			return
+		}
+
+		name := path.Clean(header.Name)
+		switch {
+		case name == packManifestName:
+			pack = &KnowledgePack{}
+			err = readPackEntry(tr, pack)
+		case strings.HasPrefix(name, packOutputsDir) && strings.HasSuffix(name, ".json"):
+			starred := &StarredOutput{}
+			if err = readPackEntry(tr, starred); err == nil {
+				outputs = append(outputs, starred)
+			}
+		}
+		if err != nil {
+			err = fmt.Errorf("invalid knowledge pack entry %s: %v", header.Name, err)
+			return
+		}
+	}
+	if pack == nil {
+		err = fmt.Errorf("not a knowledge pack: %s is missing", packManifestName)
+		return
+	}
+	if pack.Version > packFormatVersion {
+		err = fmt.Errorf("knowledge pack version %d is newer than the supported version %d, update fabric", pack.Version, packFormatVersion)
+		return
+	}
+
+	ret = &ImportResult{Pack: *pack}
+	provenance := Provenance{Pack: pack.Name, Author: pack.Author, Imported: time.Now()}
+	for _, starred := range outputs {
+		if starred.Run.Id == "" {
+			continue
+		}
+		var existing *StarredOutput
+		if existing, err = o.Get(starred.Run.Id); err != nil {
+			return
+		}
+		if existing != nil {
+			ret.Skipped++
+			continue
+		}
+		starred.Provenance = append(starred.Provenance, provenance)
+		if err = o.Save(starred); err != nil {
+			return
+		}
+		ret.Imported++
+	}
+	return
+}
+
+// Markdown renders the starred output for reading
+func (o *StarredOutput) Markdown() string {
+	var builder strings.Builder
+	title := o.Run.PatternName
+	if title == "" {
+		title = "Output"
+	}
+	fmt.Fprintf(&builder, "# %s\n\n", title)
+	fmt.Fprintf(&builder, "- Run: %s\n", o.Run.Timestamp.Format(time.DateTime))
+	if o.Run.Metadata != nil {
+		fmt.Fprintf(&builder, "- Model: %s|%s\n", o.Run.Metadata.Vendor, o.Run.Metadata.Model)
+	}
+	for _, step := range o.Provenance {
+		fmt.Fprintf(&builder, "- From pack: %s", step.Pack)
+		if step.Author != "" {
+			fmt.Fprintf(&builder, " by %s", step.Author)
+		}
+		builder.WriteString("\n")
+	}
+	var notes []string
+	for _, note := range []string{o.Annotation, o.Run.Notes} {
+		if note = strings.TrimSpace(note); note != "" && !slices.Contains(notes, note) {
+			notes = append(notes, note)
+		}
+	}
+	if len(notes) > 0 {
+		fmt.Fprintf(&builder, "\n## Notes\n\n%s\n", strings.Join(notes, "\n\n"))
+	}
+	fmt.Fprintf(&builder, "\n## Input\n\n%s\n\n## Output\n\n%s\n", strings.TrimSpace(o.Run.Input), strings.TrimSpace(o.Run.Output))
+	return builder.String()
+}
+
+func writePackEntry(tw *tar.Writer, name string, value any) (err error) {
+	var data []byte
+	if data, err = json.MarshalIndent(value, "", "  "); err != nil {
+		return
+	}
+	return writePackFile(tw, name, data)
+}
+
+func writePackFile(tw *tar.Writer, name string, data []byte) (err error) {
+	header := &tar.Header{Name: name, Mode: 0644, Size: int64(len(data)), ModTime: time.Now()}
+	if err = tw.WriteHeader(header); err != nil {
+		return
+	}
+	_, err = tw.Write(data)
+	return
+}
+
+func readPackEntry(r io.Reader, value any) (err error) {
+	var data []byte
+	if data, err = io.ReadAll(io.LimitReader(r, maxPackEntryBytes+1)); err != nil {
+		return
+	}
+	if len(data) > maxPackEntryBytes {
+		return fmt.Errorf("larger than %d bytes", maxPackEntryBytes)
+	}
+	return json.Unmarshal(data, value)
+}
@@ -0,0 +1,310 @@
+package fsdb
+
+import (
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+// LayoutMigration upgrades data written by an older version to the current on-disk layout.
+// Detect must be cheap, it runs on every start: it must not open the store, which is opened by Apply
+// only. Apply must leave the data untouched when it fails and the data must not be detected again
+// once it succeeded, see LayoutMigrationsFileName.
+type LayoutMigration struct {
+	Name   string
+	Detect func(db *Db) bool
+	Apply  func(db *Db) (report string, err error)
+}
+
+// The Streamlit UI kept its outputs as JSON lists in the outputs directory of the config
+const (
+	legacyOutputsDirName     = "outputs"
+	legacyOutputLogsFileName = "output_logs.json"
+	legacyOutputsFileName    = "outputs.json"
+	legacyStarredFileName    = "starred_outputs.json"
+)
+
+// The batches kept the calibrations of their estimates in a JSON object by pattern, see BatchEstimatesCollection
+const legacyBatchEstimatesFileName = "batch_estimates.json"
+
+// BatchEstimatesCollection keeps the calibrations of the batch estimates by pattern, see csvbatch.Calibration
+const BatchEstimatesCollection = "batch_estimates"
+
+// LayoutMigrations are applied in order, append new migrations at the end
+var LayoutMigrations = []*LayoutMigration{
+	legacyOutputsMigration("output logs", legacyOutputLogsFileName, false),
+	legacyOutputsMigration("outputs", legacyOutputsFileName, false),
+	legacyOutputsMigration("starred outputs", legacyStarredFileName, true),
+	{
+		Name: "batch estimates",
+		Detect: func(db *Db) bool {
+			_, err := os.Stat(db.FilePath(legacyBatchEstimatesFileName))
+			return err == nil
+		},
+		Apply: migrateLegacyBatchEstimates,
+	},
+}
+
+// LayoutMigrationsFileName is the marker of the config directory recording the size and modification
+// time of the files imported by the migrations, by file name, so they are not imported again
+const LayoutMigrationsFileName = "layout_migrations.json"
+
+type MigrationResult struct {
+	Name   string
+	Report string
+	Err    error
+}
+
+// MigrateLayouts applies the migrations whose older layout is detected
+func (o *Db) MigrateLayouts() (ret []MigrationResult) {
+	for _, migration := range LayoutMigrations {
+		if !migration.Detect(o) {
+			continue
+		}
+		report, err := migration.Apply(o)
+		ret = append(ret, MigrationResult{Name: migration.Name, Report: report, Err: err})
+	}
+	return
+}
+
+func (o *Db) legacyOutputsPath(fileName string) string {
+	return o.FilePath(filepath.Join(legacyOutputsDirName, fileName))
+}
+
+// legacyOutput is an entry of the files of the Streamlit UI. Its output panel wrote timestamp,
+// pattern_name, input and output, its storage service created_at, pattern, input_text and output_text.
+type legacyOutput struct {
+	Timestamp   string `json:"timestamp"`
+	CreatedAt   string `json:"created_at"`
+	PatternName string `json:"pattern_name"`
+	Pattern     string `json:"pattern"`
+	Input       string `json:"input"`
+	InputText   string `json:"input_text"`
+	Output      string `json:"output"`
+	OutputText  string `json:"output_text"`
+	CustomName  string `json:"custom_name"`
+	Name        string `json:"name"`
+}
+
+// The timestamps were written by datetime.strftime or datetime.isoformat, in local time without a zone
+var legacyTimestampLayouts = []string{time.RFC3339Nano, "2006-01-02T15:04:05.999999999", "2006-01-02 15:04:05"}
+
+func (o *legacyOutput) run() (ret *Run, err error) {
+	value := firstNonEmpty(o.Timestamp, o.CreatedAt)
+	var timestamp time.Time
+	for _, layout := range legacyTimestampLayouts {
+		if timestamp, err = time.ParseInLocation(layout, value, time.Local); err == nil {
+			break
+		}
+	}
+	if err != nil {
+		err = fmt.Errorf("invalid timestamp %q", value)
+		return
+	}
+	ret = &Run{
+		Timestamp:   timestamp,
+		PatternName: firstNonEmpty(o.PatternName, o.Pattern),
+		Input:       firstNonEmpty(o.Input, o.InputText),
+		Output:      firstNonEmpty(o.Output, o.OutputText),
+	}
+	ret.Id = timestamp.UTC().Format(runIdLayout)
+	return
+}
+
+func firstNonEmpty(values ...string) string {
+	for _, value := range values {
+		if value != "" {
+			return value
+		}
+	}
+	return ""
+}
+
+type legacyFileState struct {
+	Size    int64 `json:"size"`
+	ModTime int64 `json:"modTime"`
+}
+
+func statLegacyFile(path string) (ret legacyFileState, err error) {
+	var info os.FileInfo
+	if info, err = os.Stat(path); err == nil {
+		ret = legacyFileState{Size: info.Size(), ModTime: info.ModTime().UnixNano()}
+	}
+	return
+}
+
+// importedFiles returns the states recorded by the migrations, none when the marker is missing or invalid
+func (o *Db) importedFiles() (ret map[string]legacyFileState) {
+	ret = map[string]legacyFileState{}
+	if data, err := os.ReadFile(o.FilePath(LayoutMigrationsFileName)); err == nil {
+		json.Unmarshal(data, &ret)
+	}
+	return
+}
+
+// markImported records the state of the imported file, the marker is replaced atomically
+func (o *Db) markImported(fileName string, state legacyFileState) (err error) {
+	imported := o.importedFiles()
+	imported[fileName] = state
+	var data []byte
+	if data, err = json.MarshalIndent(imported, "", "  "); err != nil {
+		return
+	}
+	path := o.FilePath(LayoutMigrationsFileName)
+	if err = os.WriteFile(path+".tmp", data, 0644); err != nil {
+		return
+	}
+	return os.Rename(path+".tmp", path)
+}
+
+// legacyOutputsMigration imports a file of the Streamlit UI into the run history, the starred ones are
+// starred too. The file is left in place since the Streamlit UI still reads it; it is detected again
+// when it changes, the runs already imported are kept as they are.
+func legacyOutputsMigration(name string, fileName string, starred bool) *LayoutMigration {
+	return &LayoutMigration{
+		Name: name,
+		Detect: func(db *Db) bool {
+			state, err := statLegacyFile(db.legacyOutputsPath(fileName))
+			if err != nil {
+				return false
+			}
+			imported, found := db.importedFiles()[fileName]
+			return !found || imported != state
+		},
+		Apply: func(db *Db) (string, error) {
+			return migrateLegacyOutputs(db, fileName, starred)
+		},
+	}
+}
+
+// migrateLegacyOutputs imports the entries in a single transaction, the state of the file is recorded once
+// it is committed. The entries are inserted if missing, importing them again changes nothing.
+func migrateLegacyOutputs(db *Db, fileName string, starred bool) (report string, err error) {
+	path := db.legacyOutputsPath(fileName)
+	var state legacyFileState
+	if state, err = statLegacyFile(path); err != nil {
+		return
+	}
+	var data []byte
+	if data, err = os.ReadFile(path); err != nil {
+		return
+	}
+	var entries []json.RawMessage
+	if err = json.Unmarshal(data, &entries); err != nil {
+		err = fmt.Errorf("%s is not a JSON list: %v", path, err)
+		return
+	}
+
+	var conn *sql.DB
+	if conn, err = db.Store.Conn(); err != nil {
+		return
+	}
+	var tx *sql.Tx
+	if tx, err = conn.Begin(); err != nil {
+		return
+	}
+
+	imported, skipped := 0, 0
+	// entries written in the same second get distinct ids, the files only kept seconds
+	outputs := map[string]string{}
+	for _, raw := range entries {
+		entry := &legacyOutput{}
+		var run *Run
+		if json.Unmarshal(raw, entry) != nil {
+			skipped++
+			continue
+		}
+		if run, err = entry.run(); err != nil || run.Output == "" {
+			err = nil
+			skipped++
+			continue
+		}
+		for {
+			if output, taken := outputs[run.Id]; !taken || output == run.Output {
+				break
+			}
+			run.Timestamp = run.Timestamp.Add(time.Nanosecond)
+			run.Id = run.Timestamp.UTC().Format(runIdLayout)
+		}
+		outputs[run.Id] = run.Output
+
+		if err = insertRun(tx, run, "INSERT OR IGNORE"); err != nil {
+			break
+		}
+		if starred {
+			// starring it again here would replace the annotation added since the last import
+			if err = putEntry(tx, "INSERT OR IGNORE", starredCollection, run.Id, &StarredOutput{
+				Run: *run, Title: firstNonEmpty(entry.CustomName, entry.Name), Starred: run.Timestamp}); err != nil {
+				break
+			}
+		}
+		imported++
+	}
+	if err != nil {
+		tx.Rollback()
+		return
+	}
+	if err = tx.Commit(); err != nil {
+		return
+	}
+	if err = db.markImported(fileName, state); err != nil {
+		return
+	}
+
+	report = fmt.Sprintf("imported %d entries of %s into %s", imported, path, StoreFileName)
+	if skipped > 0 {
+		report += fmt.Sprintf(", skipped %d invalid entries", skipped)
+	}
+	return
+}
+
+// migrateLegacyBatchEstimates imports the calibrations of the file, keeping the ones already learned in the
+// store, and removes the file once they are committed
+func migrateLegacyBatchEstimates(db *Db) (report string, err error) {
+	path := db.FilePath(legacyBatchEstimatesFileName)
+	var data []byte
+	if data, err = os.ReadFile(path); err != nil {
+		return
+	}
+	var calibrations map[string]json.RawMessage
+	if err = json.Unmarshal(data, &calibrations); err != nil {
+		err = fmt.Errorf("%s is not a JSON object: %v", path, err)
+		return
+	}
+
+	var conn *sql.DB
+	if conn, err = db.Store.Conn(); err != nil {
+		return
+	}
+	var tx *sql.Tx
+	if tx, err = conn.Begin(); err != nil {
+		return
+	}
+	for pattern, calibration := range calibrations {
+		if err = putEntry(tx, "INSERT OR IGNORE", BatchEstimatesCollection, pattern, calibration); err != nil {
+			tx.Rollback()
+			return
+		}
+	}
+	if err = tx.Commit(); err != nil {
+		return
+	}
+	if err = os.Remove(path); err != nil {
+		return
+	}
+	report = fmt.Sprintf("imported the estimates of %d patterns of %s into %s", len(calibrations), path, StoreFileName)
+	return
+}
+
+func putEntry(tx *sql.Tx, verb, collection, key string, value any) (err error) {
+	var data []byte
+	if data, err = json.Marshal(value); err != nil {
+		return
+	}
+	_, err = tx.Exec(verb+` INTO entries (collection, key, value, updated_at) VALUES (?, ?, ?, ?)`,
+		collection, key, string(data), time.Now().UnixNano())
+	return
+}
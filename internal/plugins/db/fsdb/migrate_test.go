@@ -0,0 +1,119 @@
+package fsdb
+
+import (
+	"os"
+	"testing"
+	"time"
+)
+
+func TestMigrateLayouts_Nothing(t *testing.T) {
+	db := NewDb(t.TempDir())
+	if results := db.MigrateLayouts(); len(results) != 0 {
+		t.Errorf("expected no migration, got %+v", results)
+	}
+	if _, err := os.Stat(db.FilePath(StoreFileName)); !os.IsNotExist(err) {
+		t.Errorf("expected the store to stay unopened: %v", err)
+	}
+}
+
+func TestMigrateLayouts_LegacyOutputs(t *testing.T) {
+	db := NewDb(t.TempDir())
+	defer db.Store.Close()
+
+	if err := os.MkdirAll(db.FilePath(legacyOutputsDirName), os.ModePerm); err != nil {
+		t.Fatalf("failed to create outputs dir: %v", err)
+	}
+	os.WriteFile(db.legacyOutputsPath(legacyOutputLogsFileName), []byte(`[
+		{"timestamp": "2024-03-10 12:00:00", "pattern_name": "summarize", "input": "in", "output": "first"},
+		{"timestamp": "2024-03-10 12:00:00", "pattern_name": "summarize", "input": "in", "output": "second"},
+		{"timestamp": "not a time", "output": "broken"}
+	]`), 0644)
+	os.WriteFile(db.legacyOutputsPath(legacyStarredFileName), []byte(`[
+		{"timestamp": "2024-03-10 12:00:00", "pattern_name": "summarize", "input": "in", "output": "first",
+		 "is_starred": true, "custom_name": "Great summary"}
+	]`), 0644)
+
+	results := db.MigrateLayouts()
+	if len(results) != 2 || results[0].Err != nil || results[1].Err != nil {
+		t.Fatalf("expected two successful migrations, got %+v", results)
+	}
+
+	runs, err := db.History.FilterRuns(RunFilter{})
+	if err != nil {
+		t.Fatalf("failed to list runs: %v", err)
+	}
+	if len(runs) != 2 {
+		t.Fatalf("expected the two valid output logs as runs, got %d", len(runs))
+	}
+	id := time.Date(2024, 3, 10, 12, 0, 0, 0, time.Local).UTC().Format(runIdLayout)
+	run, err := db.History.GetRun(id)
+	if err != nil || run.Output != "first" || run.PatternName != "summarize" {
+		t.Fatalf("unexpected imported run: %+v, %v", run, err)
+	}
+
+	starred, err := db.Starred.Get(id)
+	if err != nil || starred == nil {
+		t.Fatalf("expected the starred output to be starred: %v", err)
+	}
+	if starred.Title != "Great summary" || starred.Run.Output != "first" {
+		t.Errorf("unexpected starred output: %+v", starred)
+	}
+
+	if results = db.MigrateLayouts(); len(results) != 0 {
+		t.Errorf("expected no migration while the files are unchanged, got %+v", results)
+	}
+	// the imported files are recorded in the marker, the next starts don't open the store
+	restarted := NewDb(db.Dir)
+	if results = restarted.MigrateLayouts(); len(results) != 0 || restarted.Store.db != nil {
+		t.Errorf("expected no migration and a closed store after a restart, got %+v", results)
+	}
+	if _, err = os.Stat(db.legacyOutputsPath(legacyOutputLogsFileName)); err != nil {
+		t.Errorf("expected the output logs to stay in place for the Streamlit UI: %v", err)
+	}
+
+	// starring it again in the Streamlit UI keeps the annotation added here
+	if _, err = db.Starred.Star(id, "kept"); err != nil {
+		t.Fatalf("failed to annotate: %v", err)
+	}
+	later := time.Now().Add(time.Minute)
+	os.Chtimes(db.legacyOutputsPath(legacyStarredFileName), later, later)
+	if results = db.MigrateLayouts(); len(results) != 1 || results[0].Err != nil {
+		t.Fatalf("expected the changed file to be imported again, got %+v", results)
+	}
+	if starred, _ = db.Starred.Get(id); starred == nil || starred.Annotation != "kept" {
+		t.Errorf("expected the annotation to be kept, got %+v", starred)
+	}
+}
+
+func TestMigrateLayouts_LegacyBatchEstimates(t *testing.T) {
+	db := NewDb(t.TempDir())
+	defer db.Store.Close()
+
+	if err := db.Store.Put(BatchEstimatesCollection, "summarize", map[string]any{"batches": 3}); err != nil {
+		t.Fatalf("failed to store the estimate: %v", err)
+	}
+	os.WriteFile(db.FilePath(legacyBatchEstimatesFileName), []byte(`{
+		"summarize": {"promptRatio": 1.2, "completionTokens": 400, "batches": 1},
+		"extract_wisdom": {"promptRatio": 0.9, "completionTokens": 900, "batches": 2}
+	}`), 0644)
+
+	results := db.MigrateLayouts()
+	if len(results) != 1 || results[0].Err != nil {
+		t.Fatalf("expected the estimates to be migrated, got %+v", results)
+	}
+	if _, err := os.Stat(db.FilePath(legacyBatchEstimatesFileName)); !os.IsNotExist(err) {
+		t.Errorf("expected the estimates file to be removed: %v", err)
+	}
+	var calibration struct {
+		Batches int `json:"batches"`
+	}
+	if found, err := db.Store.Get(BatchEstimatesCollection, "extract_wisdom", &calibration); !found || err != nil || calibration.Batches != 2 {
+		t.Errorf("expected the estimate of the file, got %+v, %v", calibration, err)
+	}
+	if found, err := db.Store.Get(BatchEstimatesCollection, "summarize", &calibration); !found || err != nil || calibration.Batches != 3 {
+		t.Errorf("expected the estimate of the store to be kept, got %+v, %v", calibration, err)
+	}
+	if results = db.MigrateLayouts(); len(results) != 0 {
+		t.Errorf("expected no migration once the file is imported, got %+v", results)
+	}
+}
@@ -0,0 +1,191 @@
+package fsdb
+
+import (
+	"fmt"
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+	"unicode/utf8"
+)
+
+// maxIncludedAttachmentSize limits the attachments included in the prompt, they are sent with every run
+const maxIncludedAttachmentSize = 256 << 10
+
+// attachmentsSentinel marks where the included attachments go while the variables are applied, so the
+// attachments are not processed as templates
+const attachmentsSentinel = "__FABRIC_ATTACHMENTS_SENTINEL_TOKEN__"
+
+// Kinds of pattern attachments
+const (
+	AttachmentText  = "text"
+	AttachmentImage = "image"
+	AttachmentOther = "other"
+)
+
+var imageExtensions = map[string]bool{".png": true, ".jpg": true, ".jpeg": true, ".gif": true, ".webp": true, ".svg": true}
+
+// PatternAttachment is an extra file shipped in a pattern directory, like examples, images or data.
+// The attachments listed in the attachments of the PatternMeta are included in the prompt.
+type PatternAttachment struct {
+	Name     string `json:"name"` // path relative to the pattern directory, with slashes
+	Size     int64  `json:"size"`
+	Kind     string `json:"kind"` // AttachmentText, AttachmentImage or AttachmentOther
+	Included bool   `json:"included"`
+}
+
+// GetAttachments returns the files of the pattern directory besides the pattern and its meta.yaml, sorted
+// by name. Hidden files and directories are skipped.
+func (o *PatternsEntity) GetAttachments(name string) (ret []*PatternAttachment, err error) {
+	var pattern *Pattern
+	if pattern, err = o.getFromDB(name); err != nil {
+		return
+	}
+	included := map[string]bool{}
+	if pattern.Meta != nil {
+		for _, file := range pattern.Meta.Attachments {
+			included[path.Clean(filepath.ToSlash(file))] = true
+		}
+	}
+
+	dir := filepath.Dir(o.patternPath(name))
+	ret = []*PatternAttachment{}
+	err = filepath.WalkDir(dir, func(filePath string, entry fs.DirEntry, walkErr error) error {
+		if walkErr != nil {
+			return walkErr
+		}
+		if filePath == dir {
+			return nil
+		}
+		if strings.HasPrefix(entry.Name(), ".") {
+			if entry.IsDir() {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		if entry.IsDir() {
+			return nil
+		}
+		rel, relErr := filepath.Rel(dir, filePath)
+		if relErr != nil {
+			return relErr
+		}
+		rel = filepath.ToSlash(rel)
+		if rel == o.SystemPatternFile || rel == PatternMetaFile {
+			return nil
+		}
+		info, infoErr := entry.Info()
+		if infoErr != nil || !info.Mode().IsRegular() {
+			return nil
+		}
+		ret = append(ret, &PatternAttachment{Name: rel, Size: info.Size(), Kind: attachmentKind(filePath), Included: included[rel]})
+		return nil
+	})
+	sort.Slice(ret, func(i, j int) bool { return ret[i].Name < ret[j].Name })
+	return
+}
+
+// AttachmentPath returns the resolved path of an attachment of the pattern, file is relative to the pattern
+// directory. Paths leaving the pattern directory, symlinks included, and hidden files are refused.
+func (o *PatternsEntity) AttachmentPath(name string, file string) (ret string, err error) {
+	patternPath := o.patternPath(name)
+	if _, err = os.Stat(patternPath); err != nil {
+		err = fmt.Errorf("pattern %s not found", name)
+		return
+	}
+	return attachmentPath(filepath.Dir(patternPath), file)
+}
+
+func attachmentPath(dir string, file string) (ret string, err error) {
+	clean := path.Clean("/" + filepath.ToSlash(file))[1:]
+	if clean == "" || clean != strings.TrimPrefix(filepath.ToSlash(file), "./") {
+		err = fmt.Errorf("invalid attachment %s", file)
+		return
+	}
+	for _, part := range strings.Split(clean, "/") {
+		if strings.HasPrefix(part, ".") {
+			err = fmt.Errorf("invalid attachment %s", file)
+			return
+		}
+	}
+	// a symlink of the pattern directory could lead anywhere, the resolved path must stay in it
+	var root string
+	if root, err = filepath.EvalSymlinks(dir); err == nil {
+		ret, err = filepath.EvalSymlinks(filepath.Join(dir, filepath.FromSlash(clean)))
+	}
+	if err != nil {
+		err = fmt.Errorf("attachment %s not found", file)
+		return
+	}
+	if rel, relErr := filepath.Rel(root, ret); relErr != nil || !filepath.IsLocal(rel) {
+		err = fmt.Errorf("attachment %s is outside the pattern directory", file)
+		return
+	}
+	var info os.FileInfo
+	if info, err = os.Stat(ret); err != nil {
+		err = fmt.Errorf("attachment %s not found", file)
+		return
+	}
+	if !info.Mode().IsRegular() {
+		err = fmt.Errorf("attachment %s is not a file", file)
+	}
+	return
+}
+
+// includedAttachments renders the attachments listed in the meta of the pattern for the prompt, dir is the
+// directory of the pattern
+func includedAttachments(pattern *Pattern, dir string) (ret string, err error) {
+	if pattern.Meta == nil || len(pattern.Meta.Attachments) == 0 {
+		return
+	}
+	var builder strings.Builder
+	for _, file := range pattern.Meta.Attachments {
+		var filePath string
+		if filePath, err = attachmentPath(dir, file); err != nil {
+			return
+		}
+		var content []byte
+		if content, err = os.ReadFile(filePath); err != nil {
+			return
+		}
+		if len(content) > maxIncludedAttachmentSize {
+			err = fmt.Errorf("attachment %s is larger than %d KB", file, maxIncludedAttachmentSize>>10)
+			return
+		}
+		if attachmentKind(filePath) == AttachmentImage || !utf8.Valid(content) {
+			err = fmt.Errorf("attachment %s is not a text file", file)
+			return
+		}
+		fmt.Fprintf(&builder, "# ATTACHMENT: %s\n\n%s\n\n", file, strings.TrimSpace(string(content)))
+	}
+	ret = builder.String()
+	return
+}
+
+// withAttachmentsSentinel marks the place of the attachments: before the line of the input, at the end
+// when the pattern has no input placeholder
+func withAttachmentsSentinel(content string) string {
+	index := strings.Index(content, "{{input}}")
+	if index < 0 {
+		if content != "" && !strings.HasSuffix(content, "\n") {
+			content += "\n"
+		}
+		return content + attachmentsSentinel + "\n"
+	}
+	lineStart := strings.LastIndex(content[:index], "\n") + 1
+	return content[:lineStart] + attachmentsSentinel + "\n" + content[lineStart:]
+}
+
+func attachmentKind(filePath string) string {
+	extension := strings.ToLower(filepath.Ext(filePath))
+	switch {
+	case imageExtensions[extension]:
+		return AttachmentImage
+	case extension == ".md" || extension == ".txt" || extension == ".json" || extension == ".yaml" ||
+		extension == ".yml" || extension == ".csv" || extension == ".xml" || extension == "":
+		return AttachmentText
+	}
+	return AttachmentOther
+}
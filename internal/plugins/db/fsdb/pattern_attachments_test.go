@@ -0,0 +1,93 @@
+package fsdb
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func writePatternFile(t *testing.T, entity *PatternsEntity, pattern string, file string, content string) {
+	t.Helper()
+	path := filepath.Join(entity.Dir, pattern, file)
+	require.NoError(t, os.MkdirAll(filepath.Dir(path), 0755))
+	require.NoError(t, os.WriteFile(path, []byte(content), 0644))
+}
+
+func TestGetAttachments(t *testing.T) {
+	entity, cleanup := setupTestPatternsEntity(t)
+	defer cleanup()
+
+	createTestPattern(t, entity, "with-files", "---\nattachments:\n  - examples/good.md\n---\nReview {{input}}")
+	writePatternFile(t, entity, "with-files", "examples/good.md", "A good example")
+	writePatternFile(t, entity, "with-files", "diagram.png", "png")
+	writePatternFile(t, entity, "with-files", "data.bin", "data")
+	writePatternFile(t, entity, "with-files", ".hidden", "hidden")
+	writePatternFile(t, entity, "with-files", ".git/config", "hidden")
+
+	attachments, err := entity.GetAttachments("with-files")
+	require.NoError(t, err)
+	assert.Equal(t, []*PatternAttachment{
+		{Name: "data.bin", Size: 4, Kind: AttachmentOther},
+		{Name: "diagram.png", Size: 3, Kind: AttachmentImage},
+		{Name: "examples/good.md", Size: 14, Kind: AttachmentText, Included: true},
+	}, attachments)
+
+	createTestPattern(t, entity, "plain", "Just {{input}}")
+	attachments, err = entity.GetAttachments("plain")
+	require.NoError(t, err)
+	assert.Empty(t, attachments)
+}
+
+func TestAttachmentPath(t *testing.T) {
+	entity, cleanup := setupTestPatternsEntity(t)
+	defer cleanup()
+	createTestPattern(t, entity, "with-files", "Review {{input}}")
+	writePatternFile(t, entity, "with-files", "examples/good.md", "A good example")
+	writePatternFile(t, entity, "with-files", ".secret", "hidden")
+
+	outside := filepath.Join(t.TempDir(), "outside.md")
+	require.NoError(t, os.WriteFile(outside, []byte("secret"), 0644))
+	require.NoError(t, os.Symlink(outside, filepath.Join(entity.Dir, "with-files", "link.md")))
+	require.NoError(t, os.Symlink("good.md", filepath.Join(entity.Dir, "with-files", "examples", "alias.md")))
+
+	path, err := entity.AttachmentPath("with-files", "examples/good.md")
+	require.NoError(t, err)
+	expected, err := filepath.EvalSymlinks(filepath.Join(entity.Dir, "with-files", "examples", "good.md"))
+	require.NoError(t, err)
+	assert.Equal(t, expected, path)
+	path, err = entity.AttachmentPath("with-files", "examples/alias.md")
+	require.NoError(t, err)
+	assert.Equal(t, expected, path)
+
+	for _, file := range []string{"../plain/system.md", "/etc/passwd", "examples/../../x", ".secret", "examples", "missing.md", "", "link.md"} {
+		_, err = entity.AttachmentPath("with-files", file)
+		assert.Error(t, err, file)
+	}
+}
+
+func TestGetApplyVariablesIncludesAttachments(t *testing.T) {
+	entity, cleanup := setupTestPatternsEntity(t)
+	defer cleanup()
+
+	createTestPattern(t, entity, "with-files", "---\nattachments:\n  - examples.md\n---\nYou are a {{role}}.\n\nINPUT: {{input}}")
+	// the attachments are not templates
+	writePatternFile(t, entity, "with-files", "examples.md", "Example using {{placeholders}}\n")
+
+	pattern, err := entity.GetApplyVariables("with-files", map[string]string{"role": "reviewer"}, "the input")
+	require.NoError(t, err)
+	assert.Equal(t, "You are a reviewer.\n\n# ATTACHMENT: examples.md\n\nExample using {{placeholders}}\n\nINPUT: the input", pattern.Pattern)
+
+	createTestPattern(t, entity, "no-input", "---\nattachments: [examples.md]\n---\nSummarize.")
+	writePatternFile(t, entity, "no-input", "examples.md", "An example")
+	pattern, err = entity.GetApplyVariables("no-input", nil, "the input")
+	require.NoError(t, err)
+	assert.Equal(t, "Summarize.\n# ATTACHMENT: examples.md\n\nAn example\n\nthe input", pattern.Pattern)
+
+	createTestPattern(t, entity, "binary", "---\nattachments: [image.png]\n---\nDescribe {{input}}")
+	writePatternFile(t, entity, "binary", "image.png", "png")
+	_, err = entity.GetApplyVariables("binary", nil, "the input")
+	assert.Error(t, err)
+}
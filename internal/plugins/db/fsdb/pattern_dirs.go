@@ -0,0 +1,66 @@
+package fsdb
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// CustomPatternsDirs returns the custom pattern directories. CustomPatternsDir lists them separated by
+// os.PathListSeparator, like PATH, e.g. a personal directory and a team-shared one; when several have a
+// pattern of the same name, the one of the earliest directory is used.
+func (o *PatternsEntity) CustomPatternsDirs() (ret []string) {
+	for _, dir := range filepath.SplitList(o.CustomPatternsDir) {
+		if dir = strings.TrimSpace(dir); dir == "" {
+			continue
+		}
+		if strings.HasPrefix(dir, "~/") {
+			if home, err := os.UserHomeDir(); err == nil {
+				dir = filepath.Join(home, dir[2:])
+			}
+		}
+		ret = append(ret, dir)
+	}
+	return
+}
+
+// PatternDirs returns the directories the patterns are looked up in, in order: the custom ones, then the
+// main one
+func (o *PatternsEntity) PatternDirs() []string {
+	return append(o.CustomPatternsDirs(), o.Dir)
+}
+
+// Source returns the directory the pattern is loaded from, empty when no directory has it
+func (o *PatternsEntity) Source(name string) string {
+	for _, dir := range o.PatternDirs() {
+		if _, err := os.Stat(filepath.Join(dir, name, o.SystemPatternFile)); err == nil {
+			return dir
+		}
+	}
+	return ""
+}
+
+// Collisions returns the patterns found in several directories, with these directories in order: the
+// pattern of the first one is used, it shadows the others
+func (o *PatternsEntity) Collisions() (ret map[string][]string) {
+	ret = map[string][]string{}
+	found := map[string][]string{}
+	for _, dir := range o.PatternDirs() {
+		storage := &StorageEntity{Dir: dir, ItemIsDir: o.StorageEntity.ItemIsDir, FileExtension: o.StorageEntity.FileExtension}
+		names, err := storage.GetNames()
+		if err != nil {
+			continue
+		}
+		for _, name := range names {
+			if _, err = os.Stat(filepath.Join(dir, name, o.SystemPatternFile)); err == nil {
+				found[name] = append(found[name], dir)
+			}
+		}
+	}
+	for name, dirs := range found {
+		if len(dirs) > 1 {
+			ret[name] = dirs
+		}
+	}
+	return
+}
@@ -0,0 +1,116 @@
+package fsdb
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// PatternMetaFile declares the PatternMeta of a pattern whose system.md has no frontmatter
+const PatternMetaFile = "meta.yaml"
+
+const frontmatterDelimiter = "---"
+
+// PatternMeta is declared by a pattern in a frontmatter block at the top of system.md or in a meta.yaml
+// next to it:
+//
+//	---
+//	variables:
+//	  role: expert   # default used when the variable isn't given
+//	model: gpt-4o
+//	temperature: 0.2
+//	format: markdown  # markdown, mermaid or plain
+//	attachments:      # files of the pattern directory included in the prompt
+//	  - examples.md
+//	requires: [tools] # capabilities of the model: tools (function calling), vision or json
+//	minContext: 32000 # context window in tokens the pattern needs, with its usual input
+//	notes: Needs a model following long output formats.  # shown when the model may not run it
+//	schema: schema.json  # JSON schema of the pattern directory the JSON output is validated against
+//	---
+type PatternMeta struct {
+	Variables   map[string]string `yaml:"variables,omitempty" json:"variables,omitempty"`
+	Model       string            `yaml:"model,omitempty" json:"model,omitempty"`
+	Temperature *float64          `yaml:"temperature,omitempty" json:"temperature,omitempty"`
+	Format      string            `yaml:"format,omitempty" json:"format,omitempty"`
+	Attachments []string          `yaml:"attachments,omitempty" json:"attachments,omitempty"`
+	Requires    []string          `yaml:"requires,omitempty" json:"requires,omitempty"`
+	MinContext  int               `yaml:"minContext,omitempty" json:"minContext,omitempty"`
+	Notes       string            `yaml:"notes,omitempty" json:"notes,omitempty"`
+	Schema      string            `yaml:"schema,omitempty" json:"schema,omitempty"`
+}
+
+// ParseFrontmatter splits a leading frontmatter block off the content. Content without one is returned
+// unchanged with a nil meta.
+func ParseFrontmatter(content string) (meta *PatternMeta, body string, err error) {
+	body = content
+	lines := strings.SplitAfter(strings.TrimPrefix(strings.ReplaceAll(content, "\r\n", "\n"), "\uFEFF"), "\n")
+	if strings.TrimSuffix(lines[0], "\n") != frontmatterDelimiter {
+		return
+	}
+
+	closing := 0
+	for i := 1; i < len(lines) && closing == 0; i++ {
+		if strings.TrimSuffix(lines[i], "\n") == frontmatterDelimiter {
+			closing = i
+		}
+	}
+	if closing == 0 {
+		// a horizontal rule at the top, not a frontmatter block
+		return
+	}
+	block := strings.Join(lines[1:closing], "")
+	rest := strings.Join(lines[closing+1:], "")
+
+	meta = &PatternMeta{}
+	if err = yaml.Unmarshal([]byte(block), meta); err != nil {
+		meta = nil
+		err = fmt.Errorf("invalid frontmatter: %v", err)
+		return
+	}
+	body = strings.TrimLeft(rest, "\n")
+	return
+}
+
+// readPatternMeta parses the meta.yaml of a pattern directory, nil if there is none
+func readPatternMeta(dir string) (meta *PatternMeta, err error) {
+	var data []byte
+	if data, err = os.ReadFile(filepath.Join(dir, PatternMetaFile)); err != nil {
+		if os.IsNotExist(err) {
+			err = nil
+		}
+		return
+	}
+	meta = &PatternMeta{}
+	if err = yaml.Unmarshal(data, meta); err != nil {
+		meta = nil
+		err = fmt.Errorf("invalid %s: %v", PatternMetaFile, err)
+	}
+	return
+}
+
+// withMeta moves the frontmatter of the pattern to its Meta, falling back to the meta.yaml in dir
+func withMeta(pattern *Pattern, dir string) (err error) {
+	if pattern.Meta, pattern.Pattern, err = ParseFrontmatter(pattern.Pattern); err != nil || pattern.Meta != nil {
+		return
+	}
+	pattern.Meta, err = readPatternMeta(dir)
+	return
+}
+
+// withDefaults returns the variables completed by the defaults the pattern declares
+func (o *PatternMeta) withDefaults(variables map[string]string) (ret map[string]string) {
+	if o == nil || len(o.Variables) == 0 {
+		return variables
+	}
+	ret = make(map[string]string, len(o.Variables)+len(variables))
+	for name, value := range o.Variables {
+		ret[name] = value
+	}
+	for name, value := range variables {
+		ret[name] = value
+	}
+	return
+}
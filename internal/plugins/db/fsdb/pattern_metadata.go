@@ -0,0 +1,210 @@
+package fsdb
+
+import (
+	"crypto/sha256"
+	"database/sql"
+	"encoding/hex"
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+	"time"
+)
+
+// patternMetadataCacheVersion invalidates the whole cache, bump it when the derivation changes
+const patternMetadataCacheVersion = 1
+
+// maxDescriptionLength caps derived descriptions, they are shown in lists
+const maxDescriptionLength = 200
+
+// PatternMetadata is derived from the pattern file: the description is the first sentence of its
+// IDENTITY section (or first paragraph), the derived tags come from the words of its name
+type PatternMetadata struct {
+	Name        string   `json:"name"`
+	Description string   `json:"description"`
+	DerivedTags []string `json:"derivedTags,omitempty"`
+}
+
+// derivedTagWords maps words of pattern names to the tags used by the pattern descriptions
+var derivedTagWords = map[string]string{
+	"analyze": "ANALYSIS", "analysis": "ANALYSIS", "rate": "ANALYSIS", "compare": "ANALYSIS",
+	"identify": "ANALYSIS", "find": "ANALYSIS", "check": "ANALYSIS",
+	"extract": "EXTRACT", "get": "EXTRACT", "capture": "EXTRACT",
+	"summarize": "SUMMARIZE", "summary": "SUMMARIZE",
+	"write": "WRITING", "essay": "WRITING", "improve": "WRITING",
+	"code": "DEVELOPMENT", "coding": "DEVELOPMENT", "git": "DEVELOPMENT", "pr": "DEVELOPMENT",
+	"software": "DEVELOPMENT", "design": "DEVELOPMENT", "sql": "DEVELOPMENT",
+	"security": "SECURITY", "threat": "SECURITY", "malware": "SECURITY", "vulnerabilities": "SECURITY",
+	"cyber": "SECURITY", "stride": "SECURITY", "incident": "SECURITY",
+	"convert": "CONVERSION", "translate": "CONVERSION", "clean": "CONVERSION",
+	"explain": "LEARNING", "learn": "LEARNING", "quiz": "LEARNING", "flashcards": "LEARNING", "teach": "LEARNING",
+	"visualize": "VISUALIZE", "mermaid": "VISUALIZE", "diagram": "VISUALIZE", "graph": "VISUALIZE",
+	"markmap": "VISUALIZE", "chart": "VISUALIZE",
+	"review": "REVIEW",
+	"wisdom": "WISDOM", "insights": "WISDOM",
+	"research": "RESEARCH", "paper": "RESEARCH", "papers": "RESEARCH", "claims": "RESEARCH",
+	"business": "BUSINESS", "pitch": "BUSINESS", "okr": "BUSINESS", "sales": "BUSINESS", "startup": "BUSINESS",
+	"ai": "AI", "prompt": "AI",
+}
+
+// patternMetadataCollection caches the metadata of LoadMetadata, keyed by pattern name
+const patternMetadataCollection = "pattern_metadata"
+
+type patternMetadataCacheEntry struct {
+	Version  int              `json:"version"`
+	Checksum string           `json:"checksum"`
+	Size     int64            `json:"size"`
+	ModTime  time.Time        `json:"modTime"`
+	Metadata *PatternMetadata `json:"metadata"`
+}
+
+// LoadMetadata returns the metadata of all patterns sorted by name. It's cached in the pattern_metadata
+// collection of MetadataStore keyed by the checksum of the pattern files: unchanged files (same size and
+// modification time) aren't read, touched files with the same checksum aren't parsed again.
+func (o *PatternsEntity) LoadMetadata() (ret []*PatternMetadata, err error) {
+	var names []string
+	if names, err = o.GetNames(); err != nil {
+		return
+	}
+
+	o.metadataMu.Lock()
+	defer o.metadataMu.Unlock()
+
+	cached := o.readMetadataCache()
+	updated := map[string]*patternMetadataCacheEntry{}
+	changed := len(cached) != len(names)
+	for _, name := range names {
+		path := o.patternPath(name)
+		info, statErr := os.Stat(path)
+		if statErr != nil {
+			continue
+		}
+
+		entry := cached[name]
+		if entry == nil || entry.Size != info.Size() || !entry.ModTime.Equal(info.ModTime()) {
+			content, readErr := os.ReadFile(path)
+			if readErr != nil {
+				continue
+			}
+			sum := sha256.Sum256(content)
+			checksum := hex.EncodeToString(sum[:])
+			if entry == nil || entry.Checksum != checksum {
+				entry = &patternMetadataCacheEntry{Version: patternMetadataCacheVersion, Checksum: checksum,
+					Metadata: DerivePatternMetadata(name, string(content))}
+			}
+			entry.Size = info.Size()
+			entry.ModTime = info.ModTime()
+			changed = true
+		}
+		updated[name] = entry
+		ret = append(ret, entry.Metadata)
+	}
+
+	if changed && o.MetadataStore != nil {
+		if writeErr := writeMetadataCache(o.MetadataStore, updated); writeErr != nil {
+			fmt.Fprintf(os.Stderr, "Warning: could not write the pattern metadata cache: %v\n", writeErr)
+		}
+	}
+	return
+}
+
+// readMetadataCache returns the cached entries of the current version, none when the store can't be read
+func (o *PatternsEntity) readMetadataCache() (ret map[string]*patternMetadataCacheEntry) {
+	ret = map[string]*patternMetadataCacheEntry{}
+	if o.MetadataStore == nil {
+		return
+	}
+	names, err := o.MetadataStore.Keys(patternMetadataCollection)
+	if err != nil {
+		return
+	}
+	for _, name := range names {
+		entry := &patternMetadataCacheEntry{}
+		if found, getErr := o.MetadataStore.Get(patternMetadataCollection, name, entry); getErr != nil || !found ||
+			entry.Version != patternMetadataCacheVersion || entry.Metadata == nil {
+			continue
+		}
+		ret[name] = entry
+	}
+	return
+}
+
+// writeMetadataCache replaces the cached entries in a single transaction, the entries of the removed
+// patterns are dropped
+func writeMetadataCache(store *Store, entries map[string]*patternMetadataCacheEntry) (err error) {
+	var conn *sql.DB
+	if conn, err = store.Conn(); err != nil {
+		return
+	}
+	var tx *sql.Tx
+	if tx, err = conn.Begin(); err != nil {
+		return
+	}
+	if _, err = tx.Exec(`DELETE FROM entries WHERE collection = ?`, patternMetadataCollection); err == nil {
+		for name, entry := range entries {
+			if err = putEntry(tx, "INSERT", patternMetadataCollection, name, entry); err != nil {
+				break
+			}
+		}
+	}
+	if err != nil {
+		tx.Rollback()
+		return
+	}
+	return tx.Commit()
+}
+
+// DerivePatternMetadata parses the metadata of a pattern from its content
+func DerivePatternMetadata(name, content string) (ret *PatternMetadata) {
+	ret = &PatternMetadata{Name: name, Description: deriveDescription(content)}
+
+	tags := map[string]bool{}
+	for _, word := range strings.Split(strings.ToLower(name), "_") {
+		if tag, ok := derivedTagWords[word]; ok {
+			tags[tag] = true
+		}
+	}
+	for tag := range tags {
+		ret.DerivedTags = append(ret.DerivedTags, tag)
+	}
+	sort.Strings(ret.DerivedTags)
+	return
+}
+
+// deriveDescription returns the first sentence of the IDENTITY section, or of the first paragraph
+func deriveDescription(content string) string {
+	var paragraph, first string
+	inIdentity := false
+	for _, block := range strings.Split(strings.ReplaceAll(content, "\r\n", "\n"), "\n\n") {
+		block = strings.TrimSpace(block)
+		if block == "" {
+			continue
+		}
+		if strings.HasPrefix(block, "#") {
+			heading, rest, _ := strings.Cut(block, "\n")
+			inIdentity = strings.Contains(strings.ToUpper(heading), "IDENTITY")
+			if block = strings.TrimSpace(rest); block == "" {
+				continue
+			}
+		}
+		if first == "" {
+			first = block
+		}
+		if inIdentity {
+			paragraph = block
+			break
+		}
+	}
+	if paragraph == "" {
+		paragraph = first
+	}
+
+	paragraph = strings.Join(strings.Fields(paragraph), " ")
+	if end := strings.IndexAny(paragraph, ".!?"); end >= 0 {
+		paragraph = paragraph[:end+1]
+	}
+	if len(paragraph) > maxDescriptionLength {
+		paragraph = strings.TrimSpace(paragraph[:maxDescriptionLength-3]) + "..."
+	}
+	return paragraph
+}
@@ -0,0 +1,74 @@
+package fsdb
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDerivePatternMetadata(t *testing.T) {
+	content := "# IDENTITY and PURPOSE\n\nYou are an expert content summarizer. You take content in.\n\n# STEPS\n\n- Read it.\n"
+	metadata := DerivePatternMetadata("summarize_git_diff", content)
+	assert.Equal(t, "You are an expert content summarizer.", metadata.Description)
+	assert.Equal(t, []string{"DEVELOPMENT", "SUMMARIZE"}, metadata.DerivedTags)
+
+	// without an IDENTITY section the first paragraph is used
+	metadata = DerivePatternMetadata("custom", "# Task\n\nTurn the input into a haiku! Keep it short.")
+	assert.Equal(t, "Turn the input into a haiku!", metadata.Description)
+	assert.Empty(t, metadata.DerivedTags)
+}
+
+func TestPatternsEntity_LoadMetadata(t *testing.T) {
+	entity, cleanup := setupTestPatternsEntity(t)
+	defer cleanup()
+	entity.MetadataStore = &Store{Path: filepath.Join(t.TempDir(), StoreFileName)}
+	defer entity.MetadataStore.Close()
+
+	createTestPattern(t, entity, "extract_ideas", "# IDENTITY\n\nYou extract ideas.")
+	createTestPattern(t, entity, "write_essay", "# IDENTITY\n\nYou write essays.")
+
+	metadata, err := entity.LoadMetadata()
+	require.NoError(t, err)
+	require.Len(t, metadata, 2)
+	assert.Equal(t, "You extract ideas.", metadata[0].Description)
+	assert.Equal(t, []string{"WRITING"}, metadata[1].DerivedTags)
+	names, err := entity.MetadataStore.Keys(patternMetadataCollection)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"extract_ideas", "write_essay"}, names)
+
+	// an unchanged file is served from the cache without being read
+	cache := entity.readMetadataCache()
+	cache["extract_ideas"].Metadata.Description = "from the cache"
+	require.NoError(t, writeMetadataCache(entity.MetadataStore, cache))
+	metadata, err = entity.LoadMetadata()
+	require.NoError(t, err)
+	assert.Equal(t, "from the cache", metadata[0].Description)
+
+	// a touched file with the same checksum keeps its cached metadata
+	path := filepath.Join(entity.Dir, "extract_ideas", entity.SystemPatternFile)
+	later := time.Now().Add(time.Minute)
+	require.NoError(t, os.Chtimes(path, later, later))
+	metadata, err = entity.LoadMetadata()
+	require.NoError(t, err)
+	assert.Equal(t, "from the cache", metadata[0].Description)
+
+	// a changed file is parsed again
+	createTestPattern(t, entity, "extract_ideas", "# IDENTITY\n\nYou extract the best ideas.")
+	require.NoError(t, os.Chtimes(path, later.Add(time.Minute), later.Add(time.Minute)))
+	metadata, err = entity.LoadMetadata()
+	require.NoError(t, err)
+	assert.Equal(t, "You extract the best ideas.", metadata[0].Description)
+
+	// the entry of a removed pattern is dropped
+	require.NoError(t, os.RemoveAll(filepath.Join(entity.Dir, "write_essay")))
+	metadata, err = entity.LoadMetadata()
+	require.NoError(t, err)
+	require.Len(t, metadata, 1)
+	names, err = entity.MetadataStore.Keys(patternMetadataCollection)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"extract_ideas"}, names)
+}
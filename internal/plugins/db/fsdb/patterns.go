@@ -6,7 +6,10 @@ import (
 	"path/filepath"
 	"sort"
 	"strings"
+	"sync"
+	"time"
 
+	"github.com/danielmiessler/fabric/internal/domain"
 	"github.com/danielmiessler/fabric/internal/plugins/template"
 	"github.com/danielmiessler/fabric/internal/util"
 )
@@ -17,7 +20,15 @@ type PatternsEntity struct {
 	*StorageEntity
 	SystemPatternFile      string
 	UniquePatternsFilePath string
-	CustomPatternsDir      string
+	CustomPatternsDir      string // one or several directories, see CustomPatternsDirs
+	LoadWorkers            int    // concurrency of LoadAll, 0 adapts it to the machine
+	MetadataStore          *Store // cache of LoadMetadata, none if nil
+
+	// modification times of the pattern files at the last load, see ReloadChanged
+	loadMu sync.Mutex
+	loaded map[string]time.Time
+
+	metadataMu sync.Mutex
 }
 
 // Pattern represents a single pattern with its metadata
@@ -25,6 +36,9 @@ type Pattern struct {
 	Name        string
 	Description string
 	Pattern     string
+	Meta        *PatternMeta `json:",omitempty"` // declared in the frontmatter or meta.yaml, see PatternMeta
+	Source      string       `json:",omitempty"` // the pattern directory it is loaded from, see PatternDirs
+	Hash        string       `json:"-"`          // of the pattern before the variables are applied, set by GetApplyVariables
 }
 
 // GetApplyVariables main entry point for getting patterns from any source
@@ -37,6 +51,8 @@ func (o *PatternsEntity) GetApplyVariables(
 		strings.HasPrefix(source, "~") ||
 		strings.HasPrefix(source, ".")
 
+	// the directory of the pattern, its attachments are relative to it
+	var dir string
 	if isFilePath {
 		// Resolve the file path using GetAbsolutePath
 		absPath, err := util.GetAbsolutePath(source)
@@ -45,24 +61,46 @@ func (o *PatternsEntity) GetApplyVariables(
 		}
 
 		// Use the resolved absolute path to get the pattern
-		pattern, _ = o.getFromFile(absPath)
+		var fileErr error
+		if pattern, fileErr = o.getFromFile(absPath); fileErr != nil {
+			return nil, fileErr
+		}
+		dir = filepath.Dir(absPath)
 	} else {
 		// Otherwise, get the pattern from the database
 		pattern, err = o.getFromDB(source)
+		dir = filepath.Dir(o.patternPath(source))
 	}
 
 	if err != nil {
 		return
 	}
 
+	// the attachments are put in after the variables are applied, they are not templates
+	var attachments string
+	if attachments, err = includedAttachments(pattern, dir); err != nil {
+		return nil, fmt.Errorf("pattern %s: %v", source, err)
+	}
+	pattern.Hash = domain.ContentHash(pattern.Pattern + attachments)
+	if attachments != "" {
+		pattern.Pattern = withAttachmentsSentinel(pattern.Pattern)
+	}
+
 	// Apply variables to the pattern
-	err = o.applyVariables(pattern, variables, input)
+	if err = o.applyVariables(pattern, variables, input); err != nil {
+		return
+	}
+	if attachments != "" {
+		pattern.Pattern = strings.Replace(pattern.Pattern, attachmentsSentinel+"\n", attachments, 1)
+	}
 	return
 }
 
 func (o *PatternsEntity) applyVariables(
 	pattern *Pattern, variables map[string]string, input string) (err error) {
 
+	variables = pattern.Meta.withDefaults(variables)
+
 	// Ensure pattern has an {{input}} placeholder
 	// If not present, append it on a new line
 	if !strings.Contains(pattern.Pattern, "{{input}}") {
@@ -89,32 +127,23 @@ func (o *PatternsEntity) applyVariables(
 	return
 }
 
-// retrieves a pattern from the database by name
+// retrieves a pattern from the database by name, from the first pattern directory having it
 func (o *PatternsEntity) getFromDB(name string) (ret *Pattern, err error) {
-	// First check custom patterns directory if it exists
-	if o.CustomPatternsDir != "" {
-		customPatternPath := filepath.Join(o.CustomPatternsDir, name, o.SystemPatternFile)
-		if pattern, customErr := os.ReadFile(customPatternPath); customErr == nil {
-			ret = &Pattern{
-				Name:    name,
-				Pattern: string(pattern),
-			}
-			return ret, nil
-		}
-	}
-
-	// Fallback to main patterns directory
-	patternPath := filepath.Join(o.Dir, name, o.SystemPatternFile)
+	patternPath := o.patternPath(name)
 
 	var pattern []byte
 	if pattern, err = os.ReadFile(patternPath); err != nil {
 		return
 	}
 
-	patternStr := string(pattern)
 	ret = &Pattern{
 		Name:    name,
-		Pattern: patternStr,
+		Pattern: string(pattern),
+		Source:  filepath.Dir(filepath.Dir(patternPath)),
+	}
+	if err = withMeta(ret, filepath.Dir(patternPath)); err != nil {
+		ret = nil
+		err = fmt.Errorf("pattern %s: %v", name, err)
 	}
 	return
 }
@@ -157,6 +186,10 @@ func (o *PatternsEntity) getFromFile(pathStr string) (pattern *Pattern, err erro
 		Name:    pathStr,
 		Pattern: string(content),
 	}
+	if err = withMeta(pattern, filepath.Dir(pathStr)); err != nil {
+		pattern = nil
+		err = fmt.Errorf("pattern file %s: %v", pathStr, err)
+	}
 	return
 }
 
@@ -174,23 +207,19 @@ func (o *PatternsEntity) GetNames() (ret []string, err error) {
 		nameMap[name] = true
 	}
 
-	// Get names from custom patterns directory if it exists
-	if o.CustomPatternsDir != "" {
-		// Create a temporary StorageEntity for the custom directory
+	// Get names from the custom patterns directories, they override the main patterns with the same name
+	for _, dir := range o.CustomPatternsDirs() {
 		customStorage := &StorageEntity{
-			Dir:           o.CustomPatternsDir,
+			Dir:           dir,
 			ItemIsDir:     o.StorageEntity.ItemIsDir,
 			FileExtension: o.StorageEntity.FileExtension,
 		}
-
-		customNames, customErr := customStorage.GetNames()
-		if customErr == nil {
-			// Add custom patterns, they will override main patterns with same name
+		// Ignore errors from custom directories (they might not exist)
+		if customNames, customErr := customStorage.GetNames(); customErr == nil {
 			for _, name := range customNames {
 				nameMap[name] = true
 			}
 		}
-		// Ignore errors from custom directory (it might not exist)
 	}
 
 	// Convert map keys back to slice
@@ -222,16 +251,40 @@ func (o *PatternsEntity) ListNames(shellCompleteList bool) (err error) {
 	for _, item := range names {
 		fmt.Printf("%s\n", item)
 	}
+	if !shellCompleteList {
+		o.warnCollisions()
+	}
 	return
 }
 
+// warnCollisions tells which patterns of a pattern directory shadow the ones of the same name of the next
+func (o *PatternsEntity) warnCollisions() {
+	collisions := o.Collisions()
+	names := make([]string, 0, len(collisions))
+	for name := range collisions {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		dirs := collisions[name]
+		fmt.Fprintf(os.Stderr, "Warning: pattern %s is in %s, the one of %s is used\n", name,
+			strings.Join(dirs, " and "), dirs[0])
+	}
+}
+
 // Get required for Storage interface
 func (o *PatternsEntity) Get(name string) (*Pattern, error) {
 	// Use GetPattern with no variables
 	return o.GetApplyVariables(name, nil, "")
 }
+
+// Save writes the system prompt of the pattern, in the directory it is loaded from, the custom patterns
+// directory first, or in a new directory of the patterns directory
 func (o *PatternsEntity) Save(name string, content []byte) (err error) {
-	patternDir := filepath.Join(o.Dir, name)
+	if err = validatePatternName(name); err != nil {
+		return
+	}
+	patternDir := filepath.Dir(o.patternPath(name))
 	if err = os.MkdirAll(patternDir, os.ModePerm); err != nil {
 		return fmt.Errorf("could not create pattern directory: %v", err)
 	}
@@ -241,3 +294,52 @@ func (o *PatternsEntity) Save(name string, content []byte) (err error) {
 	}
 	return nil
 }
+
+// UserPatternFile is the optional user prompt of a pattern, next to its system prompt
+const UserPatternFile = "user.md"
+
+// GetUserPrompt returns the user prompt of the pattern, empty when it has none
+func (o *PatternsEntity) GetUserPrompt(name string) (ret string, err error) {
+	if err = validatePatternName(name); err != nil {
+		return
+	}
+	var data []byte
+	if data, err = os.ReadFile(filepath.Join(filepath.Dir(o.patternPath(name)), UserPatternFile)); err != nil {
+		if os.IsNotExist(err) {
+			err = nil
+		}
+		return
+	}
+	ret = string(data)
+	return
+}
+
+// SaveUserPrompt writes the user prompt next to the system prompt of the pattern, an empty one removes it
+func (o *PatternsEntity) SaveUserPrompt(name string, content []byte) (err error) {
+	if err = validatePatternName(name); err != nil {
+		return
+	}
+	systemPath := o.patternPath(name)
+	if _, err = os.Stat(systemPath); err != nil {
+		return fmt.Errorf("pattern %s not found", name)
+	}
+	userPath := filepath.Join(filepath.Dir(systemPath), UserPatternFile)
+	if strings.TrimSpace(string(content)) == "" {
+		if err = os.Remove(userPath); os.IsNotExist(err) {
+			err = nil
+		}
+		return
+	}
+	if err = os.WriteFile(userPath, content, 0644); err != nil {
+		err = fmt.Errorf("could not save the user prompt: %v", err)
+	}
+	return
+}
+
+// validatePatternName refuses the names that aren't a directory of the patterns directory
+func validatePatternName(name string) error {
+	if name == "" || name == "." || name == ".." || name != filepath.Base(name) || strings.ContainsAny(name, `/\`) {
+		return fmt.Errorf("invalid pattern name %q", name)
+	}
+	return nil
+}
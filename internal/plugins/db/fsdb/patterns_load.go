@@ -0,0 +1,116 @@
+package fsdb
+
+import (
+	"runtime"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+const (
+	minLoadWorkers = 2
+	maxLoadWorkers = 64
+	// files per worker in a wave, the concurrency is adapted between waves
+	filesPerWorkerPerWave = 4
+)
+
+// PatternLoadStats instruments a LoadAll call
+type PatternLoadStats struct {
+	Patterns   int     `json:"patterns"`
+	Failed     int     `json:"failed"`
+	Bytes      int64   `json:"bytes"`
+	DurationMs int64   `json:"durationMs"`
+	Workers    int     `json:"workers"` // concurrency of the last wave
+	PerSecond  float64 `json:"perSecond"`
+}
+
+// LoadAll reads all patterns concurrently, sorted by name. Unreadable patterns are skipped and counted as failed.
+// With LoadWorkers 0 the concurrency adapts: it starts at the CPU count and, wave by wave, grows while
+// the throughput improves (slow disks serving parallel reads) and shrinks when it drops (the disk pushes back).
+func (o *PatternsEntity) LoadAll() (ret []*Pattern, stats PatternLoadStats, err error) {
+	var names []string
+	if names, err = o.GetNames(); err != nil {
+		return
+	}
+
+	// stat before reading, so an edit made during the load is picked up by the next ReloadChanged
+	modTimes, _ := o.modTimes()
+
+	start := time.Now()
+	adaptive := o.LoadWorkers <= 0
+	workers := o.LoadWorkers
+	if adaptive {
+		workers = clampWorkers(runtime.NumCPU())
+	}
+
+	loaded := make([]*Pattern, len(names))
+	var lastRate float64
+	for next := 0; next < len(names); {
+		end := min(len(names), next+workers*filesPerWorkerPerWave)
+		waveStart := time.Now()
+		stats.Bytes += o.loadWave(names[next:end], loaded[next:end], workers)
+		stats.Workers = workers
+
+		if adaptive {
+			rate := float64(end-next) / time.Since(waveStart).Seconds()
+			if lastRate == 0 || rate > lastRate*1.1 {
+				workers = clampWorkers(workers * 2)
+			} else if rate < lastRate*0.9 {
+				workers = clampWorkers(workers / 2)
+			}
+			lastRate = rate
+		}
+		next = end
+	}
+
+	for i, pattern := range loaded {
+		if pattern == nil {
+			stats.Failed++
+			// reported as added by ReloadChanged once it can be read
+			delete(modTimes, names[i])
+			continue
+		}
+		ret = append(ret, pattern)
+	}
+	stats.Patterns = len(ret)
+
+	o.loadMu.Lock()
+	o.loaded = modTimes
+	o.loadMu.Unlock()
+
+	elapsed := time.Since(start)
+	stats.DurationMs = elapsed.Milliseconds()
+	if elapsed > 0 {
+		stats.PerSecond = float64(stats.Patterns) / elapsed.Seconds()
+	}
+	return
+}
+
+// loadWave loads the names into results with the given number of workers and returns the bytes read
+func (o *PatternsEntity) loadWave(names []string, results []*Pattern, workers int) (bytes int64) {
+	indexes := make(chan int)
+	var total atomic.Int64
+	var wg sync.WaitGroup
+	for range min(workers, len(names)) {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range indexes {
+				if pattern, err := o.getFromDB(names[i]); err == nil {
+					results[i] = pattern
+					total.Add(int64(len(pattern.Pattern)))
+				}
+			}
+		}()
+	}
+	for i := range names {
+		indexes <- i
+	}
+	close(indexes)
+	wg.Wait()
+	return total.Load()
+}
+
+func clampWorkers(workers int) int {
+	return max(minLoadWorkers, min(maxLoadWorkers, workers))
+}
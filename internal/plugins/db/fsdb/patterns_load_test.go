@@ -0,0 +1,63 @@
+package fsdb
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPatternsEntity_LoadAll(t *testing.T) {
+	for _, workers := range []int{0, 1, 3} {
+		t.Run(fmt.Sprintf("workers %d", workers), func(t *testing.T) {
+			entity, cleanup := setupTestPatternsEntity(t)
+			defer cleanup()
+			entity.LoadWorkers = workers
+
+			for i := range 50 {
+				createTestPattern(t, entity, fmt.Sprintf("pattern_%02d", i), fmt.Sprintf("content %d", i))
+			}
+
+			patterns, stats, err := entity.LoadAll()
+			require.NoError(t, err)
+			require.Len(t, patterns, 50)
+			assert.Equal(t, "pattern_00", patterns[0].Name)
+			assert.Equal(t, "content 49", patterns[49].Pattern)
+			assert.Equal(t, 50, stats.Patterns)
+			assert.Zero(t, stats.Failed)
+			assert.Positive(t, stats.Bytes)
+			if workers > 0 {
+				assert.Equal(t, workers, stats.Workers)
+			}
+		})
+	}
+}
+
+// BenchmarkPatternsEntity_LoadAll measures the cold load throughput of a pattern library of realistic size
+func BenchmarkPatternsEntity_LoadAll(b *testing.B) {
+	for _, workers := range []int{0, 1, 8} {
+		b.Run(fmt.Sprintf("workers %d", workers), func(b *testing.B) {
+			entity := &PatternsEntity{
+				StorageEntity:     &StorageEntity{Dir: b.TempDir(), ItemIsDir: true},
+				SystemPatternFile: "system.md",
+				LoadWorkers:       workers,
+			}
+			for i := range 300 {
+				if err := entity.Save(fmt.Sprintf("pattern_%03d", i), make([]byte, 4096)); err != nil {
+					b.Fatal(err)
+				}
+			}
+
+			var perSecond float64
+			for b.Loop() {
+				_, stats, err := entity.LoadAll()
+				if err != nil {
+					b.Fatal(err)
+				}
+				perSecond += stats.PerSecond
+			}
+			b.ReportMetric(perSecond/float64(b.N), "patterns/s")
+		})
+	}
+}
@@ -0,0 +1,97 @@
+package fsdb
+
+import (
+	"os"
+	"path/filepath"
+	"sort"
+	"time"
+)
+
+type PatternChangeType string
+
+const (
+	PatternAdded    PatternChangeType = "added"
+	PatternModified PatternChangeType = "modified"
+	PatternRemoved  PatternChangeType = "removed"
+)
+
+// PatternChange is a pattern added, modified or removed since the previous load, Pattern is nil for removed ones
+type PatternChange struct {
+	Type    PatternChangeType `json:"type"`
+	Name    string            `json:"name"`
+	Pattern *Pattern          `json:"pattern,omitempty"`
+}
+
+// ReloadChanged stats the pattern files and reloads only those modified since the last LoadAll or
+// ReloadChanged. Before the first load every pattern is reported as added.
+func (o *PatternsEntity) ReloadChanged() (ret []PatternChange, err error) {
+	var current map[string]time.Time
+	if current, err = o.modTimes(); err != nil {
+		return
+	}
+
+	o.loadMu.Lock()
+	defer o.loadMu.Unlock()
+
+	for name, modTime := range current {
+		previous, known := o.loaded[name]
+		if known && previous.Equal(modTime) {
+			continue
+		}
+		pattern, loadErr := o.getFromDB(name)
+		if loadErr != nil {
+			// keep the previous state, so it's retried on the next call
+			if known {
+				current[name] = previous
+			} else {
+				delete(current, name)
+			}
+			continue
+		}
+		change := PatternChange{Type: PatternModified, Name: name, Pattern: pattern}
+		if !known {
+			change.Type = PatternAdded
+		}
+		ret = append(ret, change)
+	}
+	for name := range o.loaded {
+		if _, exists := current[name]; !exists {
+			ret = append(ret, PatternChange{Type: PatternRemoved, Name: name})
+		}
+	}
+	o.loaded = current
+
+	sort.Slice(ret, func(i, j int) bool { return ret[i].Name < ret[j].Name })
+	return
+}
+
+// modTimes returns the modification time of the system pattern file of every pattern, or of its meta.yaml
+// when that is more recent
+func (o *PatternsEntity) modTimes() (ret map[string]time.Time, err error) {
+	var names []string
+	if names, err = o.GetNames(); err != nil {
+		return
+	}
+	ret = make(map[string]time.Time, len(names))
+	for _, name := range names {
+		path := o.patternPath(name)
+		if info, statErr := os.Stat(path); statErr == nil {
+			ret[name] = info.ModTime()
+			if meta, metaErr := os.Stat(filepath.Join(filepath.Dir(path), PatternMetaFile)); metaErr == nil &&
+				meta.ModTime().After(info.ModTime()) {
+				ret[name] = meta.ModTime()
+			}
+		}
+	}
+	return
+}
+
+// patternPath returns the system pattern file of the pattern, in the first of the pattern directories
+// having it, the custom ones overriding the main one; in the main directory when none has it
+func (o *PatternsEntity) patternPath(name string) string {
+	dir := o.Source(name)
+	if dir == "" {
+		dir = o.Dir
+	}
+	return filepath.Join(dir, name, o.SystemPatternFile)
+}
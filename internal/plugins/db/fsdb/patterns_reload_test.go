@@ -0,0 +1,45 @@
+package fsdb
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPatternsEntity_ReloadChanged(t *testing.T) {
+	entity, cleanup := setupTestPatternsEntity(t)
+	defer cleanup()
+
+	createTestPattern(t, entity, "kept", "kept content")
+	createTestPattern(t, entity, "edited", "old content")
+	createTestPattern(t, entity, "deleted", "deleted content")
+
+	_, _, err := entity.LoadAll()
+	require.NoError(t, err)
+
+	changes, err := entity.ReloadChanged()
+	require.NoError(t, err)
+	assert.Empty(t, changes, "nothing changed since the load")
+
+	createTestPattern(t, entity, "edited", "new content")
+	// file systems with a coarse timestamp resolution might not see the edit otherwise
+	later := time.Now().Add(time.Second)
+	require.NoError(t, os.Chtimes(filepath.Join(entity.Dir, "edited", entity.SystemPatternFile), later, later))
+	createTestPattern(t, entity, "created", "created content")
+	require.NoError(t, os.RemoveAll(filepath.Join(entity.Dir, "deleted")))
+
+	changes, err = entity.ReloadChanged()
+	require.NoError(t, err)
+	require.Len(t, changes, 3)
+	assert.Equal(t, PatternChange{Type: PatternAdded, Name: "created", Pattern: &Pattern{Name: "created", Pattern: "created content", Source: entity.Dir}}, changes[0])
+	assert.Equal(t, PatternChange{Type: PatternRemoved, Name: "deleted"}, changes[1])
+	assert.Equal(t, PatternChange{Type: PatternModified, Name: "edited", Pattern: &Pattern{Name: "edited", Pattern: "new content", Source: entity.Dir}}, changes[2])
+
+	changes, err = entity.ReloadChanged()
+	require.NoError(t, err)
+	assert.Empty(t, changes, "changes are reported once")
+}
@@ -5,6 +5,7 @@ import (
 	"path/filepath"
 	"testing"
 
+	"github.com/danielmiessler/fabric/internal/domain"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 )
@@ -141,6 +142,8 @@ func TestGetApplyVariables(t *testing.T) {
 
 			require.NoError(t, err)
 			assert.Equal(t, tt.want, result.Pattern)
+			// the hash is of the pattern, not of the applied variables
+			assert.Equal(t, domain.ContentHash("You are a {{role}}.\n{{input}}"), result.Hash)
 		})
 	}
 }
@@ -282,3 +285,99 @@ func TestPatternsEntity_CustomPatternsEmpty(t *testing.T) {
 	require.NoError(t, err)
 	assert.Equal(t, "Main pattern content", pattern.Pattern)
 }
+
+func TestPatternFrontmatter(t *testing.T) {
+	entity, cleanup := setupTestPatternsEntity(t)
+	defer cleanup()
+
+	createTestPattern(t, entity, "with-frontmatter",
+		"---\nvariables:\n  role: expert\nmodel: gpt-4o\ntemperature: 0.2\nformat: plain\n---\n\nYou are a {{role}}.\n{{input}}")
+
+	result, err := entity.GetApplyVariables("with-frontmatter", nil, "data")
+	require.NoError(t, err)
+	assert.Equal(t, "You are a expert.\ndata", result.Pattern)
+	require.NotNil(t, result.Meta)
+	assert.Equal(t, "gpt-4o", result.Meta.Model)
+	assert.Equal(t, 0.2, *result.Meta.Temperature)
+	assert.Equal(t, "plain", result.Meta.Format)
+
+	// given variables override the defaults
+	result, err = entity.GetApplyVariables("with-frontmatter", map[string]string{"role": "novice"}, "data")
+	require.NoError(t, err)
+	assert.Equal(t, "You are a novice.\ndata", result.Pattern)
+}
+
+func TestPatternMetaFile(t *testing.T) {
+	entity, cleanup := setupTestPatternsEntity(t)
+	defer cleanup()
+
+	createTestPattern(t, entity, "with-meta", "---\n\nStarts with a rule.\n{{input}}")
+	require.NoError(t, os.WriteFile(filepath.Join(entity.Dir, "with-meta", PatternMetaFile), []byte("model: llama3\n"), 0644))
+
+	result, err := entity.getFromDB("with-meta")
+	require.NoError(t, err)
+	// an unclosed delimiter is a horizontal rule, kept in the pattern
+	assert.Equal(t, "---\n\nStarts with a rule.\n{{input}}", result.Pattern)
+	require.NotNil(t, result.Meta)
+	assert.Equal(t, "llama3", result.Meta.Model)
+
+	createTestPattern(t, entity, "invalid", "---\nmodel: [\n---\nbody")
+	_, err = entity.getFromDB("invalid")
+	assert.Error(t, err)
+}
+
+func TestPatternsEntity_UserPrompt(t *testing.T) {
+	entity, cleanup := setupTestPatternsEntity(t)
+	defer cleanup()
+
+	require.Error(t, entity.SaveUserPrompt("missing", []byte("user")))
+	require.NoError(t, entity.Save("edited", []byte("system")))
+
+	prompt, err := entity.GetUserPrompt("edited")
+	require.NoError(t, err)
+	assert.Empty(t, prompt)
+
+	require.NoError(t, entity.SaveUserPrompt("edited", []byte("Summarize the input")))
+	prompt, err = entity.GetUserPrompt("edited")
+	require.NoError(t, err)
+	assert.Equal(t, "Summarize the input", prompt)
+
+	// an empty user prompt removes the file
+	require.NoError(t, entity.SaveUserPrompt("edited", []byte("  ")))
+	_, err = os.Stat(filepath.Join(entity.Dir, "edited", UserPatternFile))
+	assert.True(t, os.IsNotExist(err))
+
+	assert.Error(t, entity.Save("../outside", []byte("system")))
+	_, err = entity.GetUserPrompt("..")
+	assert.Error(t, err)
+}
+
+func TestPatternsEntity_SeveralCustomDirs(t *testing.T) {
+	entity, cleanup := setupTestPatternsEntity(t)
+	defer cleanup()
+
+	personal, team := t.TempDir(), t.TempDir()
+	entity.CustomPatternsDir = personal + string(os.PathListSeparator) + team
+	for dir, patterns := range map[string][]string{entity.Dir: {"shared", "main_only"}, personal: {"shared"}, team: {"shared", "team_only"}} {
+		for _, name := range patterns {
+			require.NoError(t, os.MkdirAll(filepath.Join(dir, name), 0755))
+			require.NoError(t, os.WriteFile(filepath.Join(dir, name, entity.SystemPatternFile), []byte(name+" of "+dir), 0644))
+		}
+	}
+
+	assert.Equal(t, []string{personal, team, entity.Dir}, entity.PatternDirs())
+	names, err := entity.GetNames()
+	require.NoError(t, err)
+	assert.Subset(t, names, []string{"shared", "main_only", "team_only"})
+
+	pattern, err := entity.getFromDB("shared")
+	require.NoError(t, err)
+	assert.Equal(t, "shared of "+personal, pattern.Pattern)
+	assert.Equal(t, personal, pattern.Source)
+
+	pattern, err = entity.getFromDB("team_only")
+	require.NoError(t, err)
+	assert.Equal(t, team, pattern.Source)
+
+	assert.Equal(t, map[string][]string{"shared": {personal, team, entity.Dir}}, entity.Collisions())
+}
@@ -0,0 +1,84 @@
+package fsdb
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+)
+
+// Proposal is a run proposed by another tool through the REST API, it runs once approved in the GUI
+type Proposal struct {
+	Id      string    `json:"id"`
+	Source  string    `json:"source,omitempty"` // the tool proposing the run
+	Reason  string    `json:"reason,omitempty"` // why the run is proposed, shown for the approval
+	Created time.Time `json:"created"`
+	Run     Job       `json:"run"` // the schedule of the job is not used
+}
+
+// ProposalsEntity keeps the proposals waiting for approval, a proposal is removed once decided
+type ProposalsEntity struct {
+	*StorageEntity
+
+	mu sync.Mutex
+}
+
+// Add saves the proposal with a new id
+func (o *ProposalsEntity) Add(proposal *Proposal) (err error) {
+	id := make([]byte, 8)
+	if _, err = rand.Read(id); err != nil {
+		return
+	}
+	proposal.Id = hex.EncodeToString(id)
+	proposal.Created = time.Now()
+
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	return o.SaveAsJson(proposal.Id, proposal)
+}
+
+func (o *ProposalsEntity) Get(id string) (ret *Proposal, err error) {
+	if !o.Exists(id) {
+		err = fmt.Errorf("proposal %s not found", id)
+		return
+	}
+	ret = &Proposal{}
+	if err = o.LoadAsJson(id, ret); err != nil {
+		return nil, err
+	}
+	return
+}
+
+// Take removes the proposal and returns it, a proposal is taken once only
+func (o *ProposalsEntity) Take(id string) (ret *Proposal, err error) {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+
+	if ret, err = o.Get(id); err != nil {
+		return
+	}
+	if err = o.Delete(id); err != nil {
+		ret = nil
+	}
+	return
+}
+
+// GetProposals loads the pending proposals, oldest first
+func (o *ProposalsEntity) GetProposals() (ret []*Proposal, err error) {
+	var ids []string
+	if ids, err = o.GetNames(); err != nil {
+		return
+	}
+
+	for _, id := range ids {
+		var proposal *Proposal
+		if proposal, err = o.Get(id); err != nil {
+			return
+		}
+		ret = append(ret, proposal)
+	}
+	sort.Slice(ret, func(i, j int) bool { return ret[i].Created.Before(ret[j].Created) })
+	return
+}
@@ -0,0 +1,55 @@
+package fsdb
+
+import (
+	"testing"
+)
+
+func newTestProposals(t *testing.T) *ProposalsEntity {
+	entity := &ProposalsEntity{StorageEntity: &StorageEntity{Dir: t.TempDir(), FileExtension: ".json"}}
+	if err := entity.Configure(); err != nil {
+		t.Fatalf("Configure failed: %v", err)
+	}
+	return entity
+}
+
+func TestProposalsAddAndList(t *testing.T) {
+	proposals := newTestProposals(t)
+
+	for _, pattern := range []string{"summarize", "extract_wisdom"} {
+		proposal := &Proposal{Source: "ci", Run: Job{PatternName: pattern, Input: "text"}}
+		if err := proposals.Add(proposal); err != nil {
+			t.Fatalf("Add failed: %v", err)
+		}
+		if proposal.Id == "" || proposal.Created.IsZero() {
+			t.Fatalf("expected the id and creation time to be set, got %+v", proposal)
+		}
+	}
+
+	pending, err := proposals.GetProposals()
+	if err != nil {
+		t.Fatalf("GetProposals failed: %v", err)
+	}
+	if len(pending) != 2 || pending[0].Run.PatternName != "summarize" || pending[1].Run.PatternName != "extract_wisdom" {
+		t.Errorf("expected the proposals oldest first, got %+v", pending)
+	}
+}
+
+func TestProposalsTakeOnce(t *testing.T) {
+	proposals := newTestProposals(t)
+
+	proposal := &Proposal{Run: Job{PatternName: "summarize"}}
+	if err := proposals.Add(proposal); err != nil {
+		t.Fatalf("Add failed: %v", err)
+	}
+
+	taken, err := proposals.Take(proposal.Id)
+	if err != nil {
+		t.Fatalf("Take failed: %v", err)
+	}
+	if taken.Run.PatternName != "summarize" {
+		t.Errorf("unexpected proposal: %+v", taken)
+	}
+	if _, err = proposals.Take(proposal.Id); err == nil {
+		t.Error("expected a taken proposal to be gone")
+	}
+}
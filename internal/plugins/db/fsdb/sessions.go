@@ -40,6 +40,11 @@ type Session struct {
 	Name     string
 	Messages []*chat.ChatCompletionMessage
 
+	// Metadata of the last model call, it is not persisted with the session
+	Metadata *domain.ExecutionMetadata `json:"-"`
+	// Environment the session was built with, completed with the vendor and recorded in the Metadata
+	Environment *domain.Environment `json:"-"`
+
 	vendorMessages []*chat.ChatCompletionMessage
 }
 
@@ -0,0 +1,106 @@
+package fsdb
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+)
+
+const starredCollection = "starred"
+
+// Provenance records a knowledge pack a starred output was imported from
+type Provenance struct {
+	Pack     string    `json:"pack"`
+	Author   string    `json:"author,omitempty"`
+	Imported time.Time `json:"imported"`
+}
+
+// StarredOutput is a run kept apart from the history, with the notes of the user. Provenance lists the
+// packs it went through, the first one is where it came from, empty when it was starred here.
+type StarredOutput struct {
+	Run        Run          `json:"run"`
+	Title      string       `json:"title,omitempty"` // named by the user, the pattern of the run shows without
+	Annotation string       `json:"annotation,omitempty"`
+	Starred    time.Time    `json:"starred"`
+	Provenance []Provenance `json:"provenance,omitempty"`
+}
+
+// StarredEntity keeps the starred outputs in the store by run id. They are copies, so they survive the
+// runs being removed from the history and runs of other users can be imported.
+type StarredEntity struct {
+	Store   *Store
+	History *HistoryEntity
+}
+
+// Star stars the run of the history with the annotation, starring it again updates the annotation
+func (o *StarredEntity) Star(runId string, annotation string) (ret *StarredOutput, err error) {
+	if ret, err = o.Get(runId); err != nil {
+		return
+	}
+	if ret == nil {
+		if !o.History.Exists(runId) {
+			err = fmt.Errorf("run %s not found", runId)
+			return
+		}
+		var run *Run
+		if run, err = o.History.GetRun(runId); err != nil {
+			return
+		}
+		ret = &StarredOutput{Run: *run, Starred: time.Now()}
+	}
+	ret.Annotation = annotation
+	err = o.Save(ret)
+	return
+}
+
+// Rename sets the title of the starred output, an empty title removes it
+func (o *StarredEntity) Rename(runId string, title string) (ret *StarredOutput, err error) {
+	if ret, err = o.Get(runId); err != nil {
+		return
+	}
+	if ret == nil {
+		err = fmt.Errorf("run %s is not starred", runId)
+		return
+	}
+	ret.Title = strings.TrimSpace(title)
+	err = o.Save(ret)
+	return
+}
+
+func (o *StarredEntity) Save(starred *StarredOutput) (err error) {
+	return o.Store.Put(starredCollection, starred.Run.Id, starred)
+}
+
+// Get returns the starred output of the run, nil if the run isn't starred
+func (o *StarredEntity) Get(runId string) (ret *StarredOutput, err error) {
+	starred := &StarredOutput{}
+	var found bool
+	if found, err = o.Store.Get(starredCollection, runId, starred); err == nil && found {
+		ret = starred
+	}
+	return
+}
+
+func (o *StarredEntity) Unstar(runId string) (err error) {
+	return o.Store.Remove(starredCollection, runId)
+}
+
+// List returns the starred outputs, the most recently starred first
+func (o *StarredEntity) List() (ret []*StarredOutput, err error) {
+	var ids []string
+	if ids, err = o.Store.Keys(starredCollection); err != nil {
+		return
+	}
+	for _, id := range ids {
+		var starred *StarredOutput
+		if starred, err = o.Get(id); err != nil {
+			return
+		}
+		if starred != nil {
+			ret = append(ret, starred)
+		}
+	}
+	sort.SliceStable(ret, func(i, j int) bool { return ret[i].Starred.After(ret[j].Starred) })
+	return
+}
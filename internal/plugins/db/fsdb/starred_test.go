@@ -0,0 +1,107 @@
+package fsdb
+
+import (
+	"bytes"
+	"path/filepath"
+	"testing"
+)
+
+func newTestStarred(t *testing.T) *StarredEntity {
+	store := &Store{Path: filepath.Join(t.TempDir(), StoreFileName)}
+	t.Cleanup(func() { store.Close() })
+	return &StarredEntity{Store: store, History: &HistoryEntity{Store: store}}
+}
+
+func TestStarred_StarAndList(t *testing.T) {
+	starred := newTestStarred(t)
+	run := &Run{PatternName: "summarize", Input: "article", Output: "summary"}
+	if err := starred.History.SaveRun(run); err != nil {
+		t.Fatalf("failed to save run: %v", err)
+	}
+
+	if _, err := starred.Star("missing", ""); err == nil {
+		t.Errorf("expected an error starring a missing run")
+	}
+	if _, err := starred.Star(run.Id, "good one"); err != nil {
+		t.Fatalf("failed to star run: %v", err)
+	}
+	if _, err := starred.Star(run.Id, "great one"); err != nil {
+		t.Fatalf("failed to annotate run: %v", err)
+	}
+
+	list, err := starred.List()
+	if err != nil {
+		t.Fatalf("failed to list starred outputs: %v", err)
+	}
+	if len(list) != 1 || list[0].Run.Output != "summary" || list[0].Annotation != "great one" {
+		t.Fatalf("unexpected starred outputs: %+v", list)
+	}
+
+	if _, err = starred.Rename(run.Id, "  Q3 summary "); err != nil {
+		t.Fatalf("failed to rename starred output: %v", err)
+	}
+	if ret, _ := starred.Get(run.Id); ret == nil || ret.Title != "Q3 summary" || ret.Annotation != "great one" {
+		t.Errorf("unexpected renamed output: %+v", ret)
+	}
+	if _, err = starred.Rename("missing", "title"); err == nil {
+		t.Errorf("expected an error renaming an unstarred run")
+	}
+
+	if err = starred.Unstar(run.Id); err != nil {
+		t.Fatalf("failed to unstar run: %v", err)
+	}
+	if ret, _ := starred.Get(run.Id); ret != nil {
+		t.Errorf("expected the run to be unstarred")
+	}
+}
+
+func TestStarred_KnowledgePack(t *testing.T) {
+	mine := newTestStarred(t)
+	for _, output := range []string{"first", "second"} {
+		run := &Run{PatternName: "extract_wisdom", Output: output}
+		if err := mine.History.SaveRun(run); err != nil {
+			t.Fatalf("failed to save run: %v", err)
+		}
+		if _, err := mine.Star(run.Id, "note on "+output); err != nil {
+			t.Fatalf("failed to star run: %v", err)
+		}
+	}
+
+	var archive bytes.Buffer
+	if err := mine.ExportPack(&archive, KnowledgePack{Name: "wisdom", Author: "alice"}, nil); err != nil {
+		t.Fatalf("failed to export pack: %v", err)
+	}
+	if err := mine.ExportPack(&bytes.Buffer{}, KnowledgePack{Name: "none"}, []string{"missing"}); err == nil {
+		t.Errorf("expected an error exporting an output that isn't starred")
+	}
+
+	theirs := newTestStarred(t)
+	data := archive.Bytes()
+	result, err := theirs.ImportPack(bytes.NewReader(data))
+	if err != nil {
+		t.Fatalf("failed to import pack: %v", err)
+	}
+	if result.Imported != 2 || result.Skipped != 0 || result.Pack.Name != "wisdom" || result.Pack.Outputs != 2 {
+		t.Errorf("unexpected import result: %+v", result)
+	}
+
+	list, err := theirs.List()
+	if err != nil || len(list) != 2 {
+		t.Fatalf("expected 2 imported outputs, got %d, %v", len(list), err)
+	}
+	for _, output := range list {
+		if len(output.Provenance) != 1 || output.Provenance[0].Pack != "wisdom" || output.Provenance[0].Author != "alice" {
+			t.Errorf("expected the provenance of the pack, got %+v", output.Provenance)
+		}
+		if output.Annotation != "note on "+output.Run.Output {
+			t.Errorf("expected the annotation to be kept, got %q", output.Annotation)
+		}
+	}
+
+	if result, err = theirs.ImportPack(bytes.NewReader(data)); err != nil || result.Imported != 0 || result.Skipped != 2 {
+		t.Errorf("expected the outputs to be skipped when imported again, got %+v, %v", result, err)
+	}
+	if _, err = theirs.ImportPack(bytes.NewReader([]byte("not an archive"))); err == nil {
+		t.Errorf("expected an error importing an invalid archive")
+	}
+}
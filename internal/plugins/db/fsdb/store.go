@@ -0,0 +1,211 @@
+package fsdb
+
+import (
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	_ "github.com/mattn/go-sqlite3"
+)
+
+// StoreFileName is the SQLite database in the config directory
+const StoreFileName = "fabric.db"
+
+// migrations upgrade the database schema, the schema version is the number of applied migrations.
+// Only append to the list, never change an applied migration.
+var migrations = []string{
+	`CREATE TABLE runs (
+		id TEXT PRIMARY KEY,
+		timestamp INTEGER NOT NULL,
+		job_name TEXT NOT NULL DEFAULT '',
+		pattern_name TEXT NOT NULL DEFAULT '',
+		context_name TEXT NOT NULL DEFAULT '',
+		session_name TEXT NOT NULL DEFAULT '',
+		strategy_name TEXT NOT NULL DEFAULT '',
+		input TEXT NOT NULL DEFAULT '',
+		output TEXT NOT NULL DEFAULT '',
+		error TEXT NOT NULL DEFAULT '',
+		metadata TEXT
+	);
+	CREATE INDEX idx_runs_timestamp ON runs(timestamp);
+	CREATE TABLE entries (
+		collection TEXT NOT NULL,
+		key TEXT NOT NULL,
+		value TEXT NOT NULL,
+		updated_at INTEGER NOT NULL,
+		PRIMARY KEY (collection, key)
+	);`,
+	`ALTER TABLE runs ADD COLUMN variables TEXT;
+	ALTER TABLE runs ADD COLUMN language TEXT NOT NULL DEFAULT '';`,
+	`ALTER TABLE runs ADD COLUMN notes TEXT NOT NULL DEFAULT '';`,
+}
+
+// Store is the SQLite database holding the run history and the small keyed collections
+// (starred outputs, presets, caches, ...). It is opened on first use.
+type Store struct {
+	Path string
+
+	mu sync.Mutex
+	db *sql.DB
+}
+
+// Conn opens the database and applies pending migrations on first use
+func (o *Store) Conn() (ret *sql.DB, err error) {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+
+	if o.db != nil {
+		ret = o.db
+		return
+	}
+
+	if err = os.MkdirAll(filepath.Dir(o.Path), os.ModePerm); err != nil {
+		return
+	}
+
+	var db *sql.DB
+	if db, err = sql.Open("sqlite3", o.Path+"?_busy_timeout=5000&_journal_mode=WAL"); err != nil {
+		err = fmt.Errorf("could not open database %s: %v", o.Path, err)
+		return
+	}
+	if err = migrate(db); err != nil {
+		db.Close()
+		err = fmt.Errorf("could not migrate database %s: %v", o.Path, err)
+		return
+	}
+
+	o.db = db
+	ret = db
+	return
+}
+
+func (o *Store) Close() (err error) {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+
+	if o.db != nil {
+		err = o.db.Close()
+		o.db = nil
+	}
+	return
+}
+
+// SchemaVersion returns the number of applied migrations
+func (o *Store) SchemaVersion() (ret int, err error) {
+	var db *sql.DB
+	if db, err = o.Conn(); err != nil {
+		return
+	}
+	err = db.QueryRow("PRAGMA user_version").Scan(&ret)
+	return
+}
+
+func migrate(db *sql.DB) (err error) {
+	var version int
+	if err = db.QueryRow("PRAGMA user_version").Scan(&version); err != nil {
+		return
+	}
+
+	for i := version; i < len(migrations); i++ {
+		var tx *sql.Tx
+		if tx, err = db.Begin(); err != nil {
+			return
+		}
+		if _, err = tx.Exec(migrations[i]); err == nil {
+			// PRAGMA doesn't accept parameters
+			_, err = tx.Exec(fmt.Sprintf("PRAGMA user_version = %d", i+1))
+		}
+		if err != nil {
+			tx.Rollback()
+			return fmt.Errorf("migration %d failed: %v", i+1, err)
+		}
+		if err = tx.Commit(); err != nil {
+			return
+		}
+	}
+	return
+}
+
+// Put stores the value as JSON under the key of the collection, replacing an existing value
+func (o *Store) Put(collection, key string, value any) (err error) {
+	var db *sql.DB
+	if db, err = o.Conn(); err != nil {
+		return
+	}
+
+	var data []byte
+	if data, err = json.Marshal(value); err != nil {
+		return
+	}
+	_, err = db.Exec(`INSERT OR REPLACE INTO entries (collection, key, value, updated_at) VALUES (?, ?, ?, ?)`,
+		collection, key, string(data), time.Now().UnixNano())
+	return
+}
+
+// Get loads the value of the key into value, found is false if there is none
+func (o *Store) Get(collection, key string, value any) (found bool, err error) {
+	var db *sql.DB
+	if db, err = o.Conn(); err != nil {
+		return
+	}
+
+	var data string
+	err = db.QueryRow(`SELECT value FROM entries WHERE collection = ? AND key = ?`, collection, key).Scan(&data)
+	if err == sql.ErrNoRows {
+		err = nil
+		return
+	}
+	if err != nil {
+		return
+	}
+	found = true
+	err = json.Unmarshal([]byte(data), value)
+	return
+}
+
+func (o *Store) Remove(collection, key string) (err error) {
+	var db *sql.DB
+	if db, err = o.Conn(); err != nil {
+		return
+	}
+	_, err = db.Exec(`DELETE FROM entries WHERE collection = ? AND key = ?`, collection, key)
+	return
+}
+
+// Keys returns the keys of the collection sorted by name
+func (o *Store) Keys(collection string) (ret []string, err error) {
+	var db *sql.DB
+	if db, err = o.Conn(); err != nil {
+		return
+	}
+
+	var rows *sql.Rows
+	if rows, err = db.Query(`SELECT key FROM entries WHERE collection = ? ORDER BY key`, collection); err != nil {
+		return
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var key string
+		if err = rows.Scan(&key); err != nil {
+			return
+		}
+		ret = append(ret, key)
+	}
+	err = rows.Err()
+	return
+}
+
+// Snapshot writes a consistent copy of the database to path
+func (o *Store) Snapshot(path string) (err error) {
+	var db *sql.DB
+	if db, err = o.Conn(); err != nil {
+		return
+	}
+	_, err = db.Exec(`VACUUM INTO ?`, path)
+	return
+}
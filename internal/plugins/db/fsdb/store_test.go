@@ -0,0 +1,49 @@
+package fsdb
+
+import (
+	"path/filepath"
+	"testing"
+)
+
+func TestStore_MigratesAndStoresEntries(t *testing.T) {
+	path := filepath.Join(t.TempDir(), StoreFileName)
+	store := &Store{Path: path}
+
+	version, err := store.SchemaVersion()
+	if err != nil {
+		t.Fatalf("failed to open store: %v", err)
+	}
+	if version != len(migrations) {
+		t.Errorf("expected schema version %d, got %d", len(migrations), version)
+	}
+
+	type preset struct {
+		Model string `json:"model"`
+	}
+	if err = store.Put("presets", "fast", preset{Model: "gpt-4o-mini"}); err != nil {
+		t.Fatalf("failed to put entry: %v", err)
+	}
+	store.Close()
+
+	// reopening must not apply the migrations again
+	store = &Store{Path: path}
+	defer store.Close()
+
+	var ret preset
+	found, err := store.Get("presets", "fast", &ret)
+	if err != nil || !found || ret.Model != "gpt-4o-mini" {
+		t.Fatalf("expected stored preset, got %v, %v, %v", ret, found, err)
+	}
+
+	keys, err := store.Keys("presets")
+	if err != nil || len(keys) != 1 || keys[0] != "fast" {
+		t.Errorf("unexpected keys %v, %v", keys, err)
+	}
+
+	if err = store.Remove("presets", "fast"); err != nil {
+		t.Fatalf("failed to remove entry: %v", err)
+	}
+	if found, _ = store.Get("presets", "fast", &ret); found {
+		t.Errorf("expected entry to be removed")
+	}
+}
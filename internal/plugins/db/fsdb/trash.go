@@ -0,0 +1,228 @@
+package fsdb
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"time"
+)
+
+const (
+	trashCollection  = "trash"
+	trashSettingsKey = "trash"
+)
+
+// DefaultTrashRetentionDays is how long deleted patterns and runs are kept when not set
+const DefaultTrashRetentionDays = 30
+
+// Kinds of the trashed items
+const (
+	TrashKindPattern = "pattern"
+	TrashKindRun     = "run"
+)
+
+// TrashedItem is a deleted pattern or run, kept until it is restored or purged. The run is kept in the
+// item, the directory of the pattern is moved to the trash directory.
+type TrashedItem struct {
+	Id      string    `json:"id"`
+	Kind    string    `json:"kind"`
+	Name    string    `json:"name"`           // of the pattern, the pattern of the run
+	Path    string    `json:"path,omitempty"` // the directory the pattern is restored to
+	Deleted time.Time `json:"deleted"`
+	Run     *Run      `json:"run,omitempty"`
+}
+
+// TrashSettings configure the trash, items older than RetentionDays are purged, 0 keeps them forever
+type TrashSettings struct {
+	RetentionDays int `json:"retentionDays"`
+}
+
+// TrashEntity keeps the deleted patterns and runs, so they can be restored. The expired items are purged
+// whenever the trash is used.
+type TrashEntity struct {
+	Store    *Store
+	Dir      string // the directories of the trashed patterns
+	Patterns *PatternsEntity
+	History  *HistoryEntity
+}
+
+func (o *TrashEntity) GetSettings() (ret *TrashSettings, err error) {
+	ret = &TrashSettings{RetentionDays: DefaultTrashRetentionDays}
+	_, err = o.Store.Get(settingsCollection, trashSettingsKey, ret)
+	return
+}
+
+func (o *TrashEntity) SaveSettings(settings *TrashSettings) (err error) {
+	if settings.RetentionDays < 0 {
+		return fmt.Errorf("invalid retention of %d days", settings.RetentionDays)
+	}
+	return o.Store.Put(settingsCollection, trashSettingsKey, settings)
+}
+
+// TrashPattern moves the directory of the pattern, the custom one when it overrides the main one, to the
+// trash
+func (o *TrashEntity) TrashPattern(name string) (ret *TrashedItem, err error) {
+	if name == "" || name == "." || name == ".." || name != filepath.Base(name) {
+		err = fmt.Errorf("invalid pattern name %q", name)
+		return
+	}
+	patternDir := filepath.Dir(o.Patterns.patternPath(name))
+	if _, err = os.Stat(patternDir); err != nil {
+		err = fmt.Errorf("pattern %s not found", name)
+		return
+	}
+	if err = os.MkdirAll(o.Dir, os.ModePerm); err != nil {
+		return
+	}
+
+	ret = &TrashedItem{Id: newTrashId(TrashKindPattern), Kind: TrashKindPattern, Name: name, Path: patternDir, Deleted: time.Now()}
+	if err = os.Rename(patternDir, filepath.Join(o.Dir, ret.Id)); err != nil {
+		err = fmt.Errorf("could not move pattern %s to the trash: %v", name, err)
+		return
+	}
+	if err = o.Store.Put(trashCollection, ret.Id, ret); err != nil {
+		// the pattern is kept where it was rather than lost in the trash directory
+		os.Rename(filepath.Join(o.Dir, ret.Id), patternDir)
+		return
+	}
+	o.PurgeExpired(time.Now())
+	return
+}
+
+// TrashRun moves the run from the history to the trash
+func (o *TrashEntity) TrashRun(id string) (ret *TrashedItem, err error) {
+	var run *Run
+	if run, err = o.History.GetRun(id); err != nil {
+		return
+	}
+	ret = &TrashedItem{Id: newTrashId(TrashKindRun), Kind: TrashKindRun, Name: run.PatternName, Deleted: time.Now(), Run: run}
+	if err = o.Store.Put(trashCollection, ret.Id, ret); err != nil {
+		return
+	}
+	if err = o.History.DeleteRun(id); err != nil {
+		o.Store.Remove(trashCollection, ret.Id)
+		return
+	}
+	o.PurgeExpired(time.Now())
+	return
+}
+
+// Get returns the trashed item, nil if there is none with the id
+func (o *TrashEntity) Get(id string) (ret *TrashedItem, err error) {
+	item := &TrashedItem{}
+	var found bool
+	if found, err = o.Store.Get(trashCollection, id, item); err == nil && found {
+		ret = item
+	}
+	return
+}
+
+// List purges the expired items and returns the others, the most recently deleted first
+func (o *TrashEntity) List() (ret []*TrashedItem, err error) {
+	if _, err = o.PurgeExpired(time.Now()); err != nil {
+		return
+	}
+	if ret, err = o.items(); err == nil {
+		sort.SliceStable(ret, func(i, j int) bool { return ret[i].Deleted.After(ret[j].Deleted) })
+	}
+	return
+}
+
+func (o *TrashEntity) items() (ret []*TrashedItem, err error) {
+	var ids []string
+	if ids, err = o.Store.Keys(trashCollection); err != nil {
+		return
+	}
+	for _, id := range ids {
+		var item *TrashedItem
+		if item, err = o.Get(id); err != nil {
+			return
+		}
+		if item != nil {
+			ret = append(ret, item)
+		}
+	}
+	return
+}
+
+// Restore puts the item back where it was deleted from, it fails when a pattern of the same name or the
+// run was created since
+func (o *TrashEntity) Restore(id string) (ret *TrashedItem, err error) {
+	if ret, err = o.Get(id); err != nil {
+		return
+	}
+	if ret == nil {
+		err = fmt.Errorf("trashed item %s not found", id)
+		return
+	}
+
+	switch ret.Kind {
+	case TrashKindPattern:
+		if _, statErr := os.Stat(ret.Path); statErr == nil {
+			err = fmt.Errorf("pattern %s exists, rename it before restoring the deleted one", ret.Name)
+			return
+		}
+		if err = os.MkdirAll(filepath.Dir(ret.Path), os.ModePerm); err != nil {
+			return
+		}
+		if err = os.Rename(filepath.Join(o.Dir, ret.Id), ret.Path); err != nil {
+			err = fmt.Errorf("could not restore pattern %s: %v", ret.Name, err)
+			return
+		}
+	case TrashKindRun:
+		if o.History.Exists(ret.Run.Id) {
+			err = fmt.Errorf("run %s exists", ret.Run.Id)
+			return
+		}
+		if err = o.History.SaveRun(ret.Run); err != nil {
+			return
+		}
+	}
+	err = o.Store.Remove(trashCollection, id)
+	return
+}
+
+// Purge deletes the item for good
+func (o *TrashEntity) Purge(id string) (err error) {
+	var item *TrashedItem
+	if item, err = o.Get(id); err != nil {
+		return
+	}
+	if item == nil {
+		return fmt.Errorf("trashed item %s not found", id)
+	}
+	if item.Kind == TrashKindPattern {
+		if err = os.RemoveAll(filepath.Join(o.Dir, item.Id)); err != nil {
+			return
+		}
+	}
+	return o.Store.Remove(trashCollection, id)
+}
+
+// PurgeExpired deletes for good the items deleted before the retention period, returning their number
+func (o *TrashEntity) PurgeExpired(now time.Time) (ret int, err error) {
+	var settings *TrashSettings
+	if settings, err = o.GetSettings(); err != nil || settings.RetentionDays == 0 {
+		return
+	}
+	var items []*TrashedItem
+	if items, err = o.items(); err != nil {
+		return
+	}
+	expiry := now.AddDate(0, 0, -settings.RetentionDays)
+	for _, item := range items {
+		if item.Deleted.Before(expiry) {
+			if err = o.Purge(item.Id); err != nil {
+				return
+			}
+			ret++
+		}
+	}
+	return
+}
+
+// newTrashId is unique as the run ids are, by the time of the deletion
+func newTrashId(kind string) string {
+	return kind + "-" + time.Now().UTC().Format(runIdLayout)
+}
@@ -0,0 +1,80 @@
+package fsdb
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func newTestTrash(t *testing.T) *TrashEntity {
+	dir := t.TempDir()
+	store := &Store{Path: filepath.Join(dir, StoreFileName)}
+	t.Cleanup(func() { store.Close() })
+	patterns := &PatternsEntity{
+		StorageEntity:     &StorageEntity{Label: "Patterns", Dir: filepath.Join(dir, "patterns"), ItemIsDir: true},
+		SystemPatternFile: "system.md",
+	}
+	return &TrashEntity{Store: store, Dir: filepath.Join(dir, "trash"), Patterns: patterns, History: &HistoryEntity{Store: store}}
+}
+
+func TestTrash_PatternRestore(t *testing.T) {
+	trash := newTestTrash(t)
+	if err := trash.Patterns.Save("summarize", []byte("Summarize")); err != nil {
+		t.Fatalf("failed to save pattern: %v", err)
+	}
+
+	item, err := trash.TrashPattern("summarize")
+	if err != nil {
+		t.Fatalf("failed to trash pattern: %v", err)
+	}
+	if trash.Patterns.Exists("summarize") {
+		t.Errorf("expected the pattern to be moved to the trash")
+	}
+	if _, err = trash.TrashPattern("../trash"); err == nil {
+		t.Errorf("expected an error trashing a path")
+	}
+
+	list, err := trash.List()
+	if err != nil || len(list) != 1 || list[0].Name != "summarize" {
+		t.Fatalf("unexpected trash: %+v, %v", list, err)
+	}
+
+	if _, err = trash.Restore(item.Id); err != nil {
+		t.Fatalf("failed to restore pattern: %v", err)
+	}
+	content, err := os.ReadFile(filepath.Join(trash.Patterns.Dir, "summarize", "system.md"))
+	if err != nil || string(content) != "Summarize" {
+		t.Errorf("expected the pattern to be restored, got %q, %v", content, err)
+	}
+	if list, _ = trash.List(); len(list) != 0 {
+		t.Errorf("expected an empty trash, got %+v", list)
+	}
+}
+
+func TestTrash_RunPurge(t *testing.T) {
+	trash := newTestTrash(t)
+	run := &Run{PatternName: "summarize", Input: "article", Output: "summary"}
+	if err := trash.History.SaveRun(run); err != nil {
+		t.Fatalf("failed to save run: %v", err)
+	}
+
+	item, err := trash.TrashRun(run.Id)
+	if err != nil {
+		t.Fatalf("failed to trash run: %v", err)
+	}
+	if trash.History.Exists(run.Id) {
+		t.Errorf("expected the run to be removed from the history")
+	}
+
+	if purged, _ := trash.PurgeExpired(time.Now()); purged != 0 {
+		t.Errorf("expected no expired item, got %d", purged)
+	}
+	purged, err := trash.PurgeExpired(time.Now().AddDate(0, 0, DefaultTrashRetentionDays+1))
+	if err != nil || purged != 1 {
+		t.Fatalf("expected the run to be purged, got %d, %v", purged, err)
+	}
+	if _, err = trash.Restore(item.Id); err == nil {
+		t.Errorf("expected an error restoring a purged run")
+	}
+}
@@ -0,0 +1,62 @@
+package fsdb
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+)
+
+const variablesCollection = "variables"
+
+var variableNamePattern = regexp.MustCompile(`^[A-Za-z_][\w.-]*$`)
+
+// StoredVariable is a value kept across runs, filled in the templates by {{var:name}}
+type StoredVariable struct {
+	Name  string `json:"name"`
+	Value string `json:"value"`
+}
+
+// VariablesEntity keeps the stored variables in the store by name, so recurring values are entered once
+type VariablesEntity struct {
+	Store *Store
+}
+
+// Get returns the value of the variable, found is false when it isn't stored
+func (o *VariablesEntity) Get(name string) (value string, found bool, err error) {
+	variable := &StoredVariable{}
+	if found, err = o.Store.Get(variablesCollection, name, variable); err == nil && found {
+		value = variable.Value
+	}
+	return
+}
+
+func (o *VariablesEntity) Set(name string, value string) (err error) {
+	if !variableNamePattern.MatchString(name) {
+		return fmt.Errorf("invalid variable name %q", name)
+	}
+	return o.Store.Put(variablesCollection, name, &StoredVariable{Name: name, Value: value})
+}
+
+func (o *VariablesEntity) Delete(name string) (err error) {
+	return o.Store.Remove(variablesCollection, name)
+}
+
+// List returns the stored variables by name
+func (o *VariablesEntity) List() (ret []*StoredVariable, err error) {
+	var names []string
+	if names, err = o.Store.Keys(variablesCollection); err != nil {
+		return
+	}
+	for _, name := range names {
+		variable := &StoredVariable{}
+		var found bool
+		if found, err = o.Store.Get(variablesCollection, name, variable); err != nil {
+			return
+		}
+		if found {
+			ret = append(ret, variable)
+		}
+	}
+	sort.Slice(ret, func(i, j int) bool { return ret[i].Name < ret[j].Name })
+	return
+}
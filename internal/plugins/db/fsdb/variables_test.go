@@ -0,0 +1,48 @@
+package fsdb
+
+import (
+	"path/filepath"
+	"testing"
+)
+
+func TestVariables_SetGetList(t *testing.T) {
+	store := &Store{Path: filepath.Join(t.TempDir(), StoreFileName)}
+	t.Cleanup(func() { store.Close() })
+	variables := &VariablesEntity{Store: store}
+
+	if err := variables.Set("client name", "Acme"); err == nil {
+		t.Errorf("expected an error for an invalid name")
+	}
+	if err := variables.Set("client_name", "Acme"); err != nil {
+		t.Fatalf("failed to set variable: %v", err)
+	}
+	if err := variables.Set("author", "Jane"); err != nil {
+		t.Fatalf("failed to set variable: %v", err)
+	}
+	if err := variables.Set("client_name", "Globex"); err != nil {
+		t.Fatalf("failed to update variable: %v", err)
+	}
+
+	value, found, err := variables.Get("client_name")
+	if err != nil || !found || value != "Globex" {
+		t.Fatalf("unexpected variable: %q %v %v", value, found, err)
+	}
+	if _, found, _ = variables.Get("missing"); found {
+		t.Errorf("expected a missing variable not to be found")
+	}
+
+	list, err := variables.List()
+	if err != nil {
+		t.Fatalf("failed to list variables: %v", err)
+	}
+	if len(list) != 2 || list[0].Name != "author" || list[1].Value != "Globex" {
+		t.Fatalf("unexpected variables: %+v", list)
+	}
+
+	if err = variables.Delete("author"); err != nil {
+		t.Fatalf("failed to delete variable: %v", err)
+	}
+	if _, found, _ = variables.Get("author"); found {
+		t.Errorf("expected the deleted variable not to be found")
+	}
+}
@@ -0,0 +1,82 @@
+package fsdb
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+
+	"github.com/danielmiessler/fabric/internal/util"
+)
+
+// WatchRootsEnv lists the directories the watches may read from and write to, separated like PATH
+const WatchRootsEnv = "FABRIC_WATCH_ROOTS"
+
+// MaxWatchFileSize is the largest file of a watched directory sent to the model, 1 MB
+const MaxWatchFileSize = 1 << 20
+
+// Watch runs a pattern on each new or changed file of a directory, writing the outputs to another directory
+type Watch struct {
+	Name            string            `json:"name"`
+	Directory       string            `json:"directory"`
+	OutputDirectory string            `json:"outputDirectory"`
+	Extensions      []string          `json:"extensions,omitempty"` // e.g. [".md", ".txt"], all files when empty
+	Enabled         bool              `json:"enabled"`
+	PatternName     string            `json:"patternName"`
+	Model           string            `json:"model,omitempty"`
+	Vendor          string            `json:"vendor,omitempty"`
+	ContextName     string            `json:"contextName,omitempty"`
+	StrategyName    string            `json:"strategyName,omitempty"`
+	Variables       map[string]string `json:"variables,omitempty"`
+	Language        string            `json:"language,omitempty"`
+}
+
+// CheckDirs checks the directory and the output directory of the watch are below a directory of
+// WatchRootsEnv, once their symlinks are resolved. It is read on every call, the .env may change.
+func (o *Watch) CheckDirs() (err error) {
+	roots := filepath.SplitList(os.Getenv(WatchRootsEnv))
+	if len(roots) == 0 {
+		return fmt.Errorf("watches are disabled, list the directories they may use in %s", WatchRootsEnv)
+	}
+	for _, dir := range []string{o.Directory, o.OutputDirectory} {
+		if _, ok := util.ResolveBelow(dir, roots); !ok {
+			return fmt.Errorf("%s is not an absolute path below a directory of %s", dir, WatchRootsEnv)
+		}
+	}
+	return
+}
+
+type WatchesEntity struct {
+	*StorageEntity
+}
+
+func (o *WatchesEntity) Get(name string) (ret *Watch, err error) {
+	ret = &Watch{}
+	if err = o.LoadAsJson(name, ret); err != nil {
+		return nil, err
+	}
+	ret.Name = name
+	return
+}
+
+func (o *WatchesEntity) SaveWatch(watch *Watch) (err error) {
+	return o.SaveAsJson(watch.Name, watch)
+}
+
+// GetWatches loads all watches sorted by name
+func (o *WatchesEntity) GetWatches() (ret []*Watch, err error) {
+	var names []string
+	if names, err = o.GetNames(); err != nil {
+		return
+	}
+	sort.Strings(names)
+
+	for _, name := range names {
+		var watch *Watch
+		if watch, err = o.Get(name); err != nil {
+			return
+		}
+		ret = append(ret, watch)
+	}
+	return
+}
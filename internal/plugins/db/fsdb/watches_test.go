@@ -0,0 +1,31 @@
+package fsdb
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestWatch_CheckDirs(t *testing.T) {
+	root := t.TempDir()
+	inbox := filepath.Join(root, "inbox")
+	os.MkdirAll(inbox, os.ModePerm)
+
+	watch := &Watch{Directory: inbox, OutputDirectory: filepath.Join(root, "summaries")}
+	t.Setenv(WatchRootsEnv, "")
+	if err := watch.CheckDirs(); err == nil {
+		t.Error("expected the watches to be disabled without roots")
+	}
+
+	t.Setenv(WatchRootsEnv, root)
+	if err := watch.CheckDirs(); err != nil {
+		t.Errorf("expected the directories below the root to be allowed: %v", err)
+	}
+
+	for _, outside := range []string{t.TempDir(), filepath.Join(root, "..", "summaries"), "summaries"} {
+		watch.OutputDirectory = outside
+		if err := watch.CheckDirs(); err == nil {
+			t.Errorf("expected output directory %s to be refused", outside)
+		}
+	}
+}
@@ -9,6 +9,7 @@ import (
 	"sort"
 	"strings"
 
+	"github.com/danielmiessler/fabric/internal/domain"
 	"github.com/danielmiessler/fabric/internal/plugins"
 	"github.com/danielmiessler/fabric/internal/tools/githelper"
 )
@@ -55,6 +56,7 @@ type Strategy struct {
 	Name        string `json:"name"`
 	Description string `json:"description"`
 	Prompt      string `json:"prompt"`
+	Hash        string `json:"-"` // of the strategy file, set by LoadStrategy
 }
 
 func LoadAllFiles() (strategies map[string]Strategy, err error) {
@@ -192,6 +194,7 @@ func LoadStrategy(filename string) (*Strategy, error) {
 		return nil, err
 	}
 	strategy.Name = strings.TrimSuffix(filepath.Base(strategyPath), ".json")
+	strategy.Hash = domain.ContentHash(string(data))
 
 	return &strategy, nil
 }
@@ -6,6 +6,7 @@ import (
 	"path/filepath"
 	"regexp"
 	"strings"
+	"sync/atomic"
 )
 
 var (
@@ -19,6 +20,15 @@ var (
 
 var extensionManager *ExtensionManager
 
+// extensionsDisabled is set by the extensions feature flag, the extension calls of the templates fail then.
+// PUT /features toggles it while templates are applied.
+var extensionsDisabled atomic.Bool
+
+// SetExtensionsEnabled turns the extension calls of the templates on or off
+func SetExtensionsEnabled(enabled bool) {
+	extensionsDisabled.Store(!enabled)
+}
+
 func init() {
 	homedir, err := os.UserHomeDir()
 	if err != nil {
@@ -29,6 +39,28 @@ func init() {
 	// Extensions will work if registry exists, otherwise they'll just fail gracefully
 }
 
+// VariableStore keeps values across runs, see fsdb.VariablesEntity
+type VariableStore interface {
+	Get(name string) (value string, found bool, err error)
+}
+
+// Variables fill the {{var:name}} calls of the templates, they fail while it is nil
+var Variables VariableStore
+
+func storedVariable(name string) (ret string, err error) {
+	if Variables == nil {
+		err = fmt.Errorf("stored variable %s: no variable store", name)
+		return
+	}
+	var found bool
+	if ret, found, err = Variables.Get(name); err != nil {
+		err = fmt.Errorf("stored variable %s: %v", name, err)
+	} else if !found {
+		err = fmt.Errorf("missing stored variable: %s", name)
+	}
+	return
+}
+
 var pluginPattern = regexp.MustCompile(`\{\{plugin:([^:]+):([^:]+)(?::([^}]+))?\}\}`)
 var extensionPattern = regexp.MustCompile(`\{\{ext:([^:]+):([^:]+)(?::([^}]+))?\}\}`)
 
@@ -120,6 +152,9 @@ func ApplyTemplate(content string, variables map[string]string, input string) (s
 				debugf("  Operation: %s\n", operation)
 				debugf("  Value: %s\n", value)
 
+				if extensionsDisabled.Load() {
+					return "", fmt.Errorf("extension %s error: extensions are disabled in the features file", name)
+				}
 				result, err := extensionManager.ProcessExtension(name, operation, value)
 				if err != nil {
 					return "", fmt.Errorf("extension %s error: %v", name, err)
@@ -136,6 +171,14 @@ func ApplyTemplate(content string, variables map[string]string, input string) (s
 				debugf("Replacing {{input}}\n")
 				replaced = true
 				content = strings.ReplaceAll(content, fullMatch, input)
+			} else if name, ok := strings.CutPrefix(varName, "var:"); ok {
+				val, err := storedVariable(name)
+				if err != nil {
+					return "", err
+				}
+				debugf("Replacing stored variable %s with value: %s\n", name, val)
+				content = strings.ReplaceAll(content, fullMatch, val)
+				replaced = true
 			} else {
 				if val, ok := variables[varName]; !ok {
 					debugf("Missing variable: %s\n", varName)
@@ -223,6 +266,14 @@ func ApplyTemplate(content string, variables map[string]string, input string) (s
 				debugf("Replacing {{input}}\n")
 				replaced = true
 				content = strings.ReplaceAll(content, fullMatch, input)
+			} else if name, ok := strings.CutPrefix(varName, "var:"); ok {
+				val, err := storedVariable(name)
+				if err != nil {
+					return "", err
+				}
+				debugf("Replacing stored variable %s with value: %s\n", name, val)
+				content = strings.ReplaceAll(content, fullMatch, val)
+				replaced = true
 			} else {
 				if val, ok := variables[varName]; !ok {
 					debugf("Missing variable: %s\n", varName)
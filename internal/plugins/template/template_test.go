@@ -143,3 +143,31 @@ func TestApplyTemplate(t *testing.T) {
 		})
 	}
 }
+
+type mapVariables map[string]string
+
+func (o mapVariables) Get(name string) (value string, found bool, err error) {
+	value, found = o[name]
+	return
+}
+
+func TestApplyTemplate_StoredVariables(t *testing.T) {
+	if _, err := ApplyTemplate("{{var:client_name}}", nil, ""); err == nil || !strings.Contains(err.Error(), "no variable store") {
+		t.Errorf("expected an error without a variable store, got %v", err)
+	}
+
+	Variables = mapVariables{"client_name": "Acme"}
+	defer func() { Variables = nil }()
+
+	got, err := ApplyTemplate("Report for {{var:client_name}} by {{author}}", map[string]string{"author": "Jane"}, "")
+	if err != nil {
+		t.Fatalf("ApplyTemplate() error = %v", err)
+	}
+	if got != "Report for Acme by Jane" {
+		t.Errorf("ApplyTemplate() = %q", got)
+	}
+
+	if _, err = ApplyTemplate("{{var:missing}}", nil, ""); err == nil || !strings.Contains(err.Error(), "missing stored variable: missing") {
+		t.Errorf("expected a missing stored variable error, got %v", err)
+	}
+}
@@ -1,6 +1,7 @@
 package restapi
 
 import (
+	"net"
 	"net/http"
 
 	"github.com/gin-gonic/gin"
@@ -10,6 +11,12 @@ const APIKeyHeader = "X-API-Key"
 
 func APIKeyMiddleware(apiKey string) gin.HandlerFunc {
 	return func(c *gin.Context) {
+		// the CORS preflights carry no headers of the request, the request itself is checked
+		if c.Request.Method == http.MethodOptions {
+			c.Next()
+			return
+		}
+
 		headerApiKey := c.GetHeader(APIKeyHeader)
 
 		if headerApiKey == "" {
@@ -25,3 +32,30 @@ func APIKeyMiddleware(apiKey string) gin.HandlerFunc {
 		c.Next()
 	}
 }
+
+// HostAccessMiddleware guards the routes starting programs on the host, like the file manager. Without an
+// API key they are served only when the server listens on the loopback interface, the default ":8080"
+// listens on all of them.
+func HostAccessMiddleware(address string, apiKey string) gin.HandlerFunc {
+	allowed := apiKey != "" || isLoopbackAddress(address)
+	return func(c *gin.Context) {
+		if !allowed {
+			c.AbortWithStatusJSON(http.StatusForbidden, gin.H{
+				"error": "opening files on the host needs an API key or the server bound to localhost, e.g. --address=localhost:8080"})
+			return
+		}
+		c.Next()
+	}
+}
+
+func isLoopbackAddress(address string) bool {
+	host, _, err := net.SplitHostPort(address)
+	if err != nil {
+		return false
+	}
+	if host == "localhost" {
+		return true
+	}
+	ip := net.ParseIP(host)
+	return ip != nil && ip.IsLoopback()
+}
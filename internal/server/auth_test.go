@@ -0,0 +1,35 @@
+package restapi
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+)
+
+func TestHostAccessMiddleware(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	tests := []struct {
+		address string
+		apiKey  string
+		code    int
+	}{
+		{":8080", "", http.StatusForbidden},
+		{"0.0.0.0:8080", "", http.StatusForbidden},
+		{"192.168.1.2:8080", "", http.StatusForbidden},
+		{":8080", "secret", http.StatusOK},
+		{"localhost:8080", "", http.StatusOK},
+		{"127.0.0.1:8080", "", http.StatusOK},
+		{"[::1]:8080", "", http.StatusOK},
+	}
+	for _, tt := range tests {
+		r := gin.New()
+		r.POST("/open", HostAccessMiddleware(tt.address, tt.apiKey), func(c *gin.Context) { c.Status(http.StatusOK) })
+		recorder := httptest.NewRecorder()
+		r.ServeHTTP(recorder, httptest.NewRequest(http.MethodPost, "/open", nil))
+		if recorder.Code != tt.code {
+			t.Errorf("address %q with key %q: expected %d, got %d", tt.address, tt.apiKey, tt.code, recorder.Code)
+		}
+	}
+}
@@ -0,0 +1,125 @@
+package restapi
+
+import (
+	"net/http"
+
+	"github.com/danielmiessler/fabric/internal/plugins/db/fsdb"
+	"github.com/danielmiessler/fabric/internal/tools/cron"
+	"github.com/gin-gonic/gin"
+)
+
+// The directories and archives of the requests are in the backups directory of the config, relative to it or
+// absolute below it: the archives hold the .env and restore the settings
+type BackupRequest struct {
+	Directory string `json:"directory"` // defaults to the directory of the backup settings
+}
+
+type RestoreRequest struct {
+	Path string `json:"path"`
+}
+
+// BackupHandler creates and restores backups of the user data
+type BackupHandler struct {
+	db *fsdb.Db
+}
+
+func NewBackupHandler(r *gin.Engine, db *fsdb.Db) *BackupHandler {
+	handler := &BackupHandler{db: db}
+	r.POST("/backup", handler.Backup)
+	r.POST("/backup/restore", handler.Restore)
+	r.GET("/backup/settings", handler.GetSettings)
+	r.PUT("/backup/settings", handler.SaveSettings)
+	return handler
+}
+
+// Backup handles the POST /backup route, returning the path of the archive
+func (h *BackupHandler) Backup(c *gin.Context) {
+	var request BackupRequest
+	if err := c.ShouldBindJSON(&request); err != nil && c.Request.ContentLength > 0 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid request"})
+		return
+	}
+
+	directory := request.Directory
+	if directory == "" {
+		settings, err := h.db.GetBackupSettings()
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+			return
+		}
+		directory = settings.Directory
+	}
+	directory, err := h.db.BackupPath(directory)
+	if err != nil {
+		c.JSON(http.StatusForbidden, gin.H{"error": err.Error()})
+		return
+	}
+
+	archivePath, err := h.db.Backup(directory)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"path": archivePath})
+}
+
+// Restore handles the POST /backup/restore route. Invalid archives are rejected before anything is changed.
+// The hooks file of the archive is never restored from the API, its commands would run on the server;
+// fabric --restore restores it.
+func (h *BackupHandler) Restore(c *gin.Context) {
+	var request RestoreRequest
+	if err := c.BindJSON(&request); err != nil || request.Path == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "archive path is required"})
+		return
+	}
+
+	archivePath, err := h.db.BackupPath(request.Path)
+	if err != nil {
+		c.JSON(http.StatusForbidden, gin.H{"error": err.Error()})
+		return
+	}
+
+	skipped, err := h.db.Restore(archivePath, fsdb.HooksBackupName)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"restored": archivePath, "skipped": skipped})
+}
+
+// GetSettings handles the GET /backup/settings route
+func (h *BackupHandler) GetSettings(c *gin.Context) {
+	settings, err := h.db.GetBackupSettings()
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, settings)
+}
+
+// SaveSettings handles the PUT /backup/settings route
+func (h *BackupHandler) SaveSettings(c *gin.Context) {
+	var settings fsdb.BackupSettings
+	if err := c.BindJSON(&settings); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid request"})
+		return
+	}
+	if settings.Directory != "" {
+		if _, err := h.db.BackupPath(settings.Directory); err != nil {
+			c.JSON(http.StatusForbidden, gin.H{"error": err.Error()})
+			return
+		}
+	}
+	if settings.Enabled {
+		if _, err := cron.Parse(settings.Schedule); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+	}
+
+	if err := h.db.SaveBackupSettings(&settings); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, settings)
+}
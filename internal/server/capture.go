@@ -0,0 +1,202 @@
+package restapi
+
+import (
+	"crypto/rand"
+	"crypto/subtle"
+	"encoding/hex"
+	"fmt"
+	"net/http"
+	"os"
+	"strings"
+	"sync"
+
+	"github.com/danielmiessler/fabric/internal/core"
+	"github.com/gin-gonic/gin"
+)
+
+// CaptureTokenFileName is the file of the fabric config directory with the capture token
+const CaptureTokenFileName = "capture_token"
+
+// CaptureRequest is posted by a bookmarklet or browser extension with the current page. The token is
+// the capture token of the install, any page of the browser could post otherwise.
+type CaptureRequest struct {
+	Token     string `json:"token"`
+	URL       string `json:"url"`
+	Title     string `json:"title"`
+	Selection string `json:"selection"`
+
+	// With a pattern the capture is run silently and the output is returned to the browser,
+	// otherwise it is kept for the GUI to pick up. The vendor is the one of the model, no context is
+	// used: they are the choices of the user, not of the page.
+	PatternName  string            `json:"patternName"`
+	Model        string            `json:"model"`
+	StrategyName string            `json:"strategyName"`
+	Variables    map[string]string `json:"variables,omitempty"`
+	Language     string            `json:"language"`
+}
+
+type CaptureResponse struct {
+	Id      string `json:"id,omitempty"`
+	OpenURL string `json:"openUrl,omitempty"`
+	Output  string `json:"output,omitempty"`
+}
+
+// Capture is the page content waiting to be opened in the GUI
+type Capture struct {
+	URL     string `json:"url"`
+	Title   string `json:"title"`
+	Content string `json:"content"`
+}
+
+// CaptureHandler receives pages from the browser
+type CaptureHandler struct {
+	registry *core.PluginRegistry
+
+	mu       sync.Mutex
+	captures map[string]*Capture
+	token    string // read or created on first use
+}
+
+func NewCaptureHandler(r *gin.Engine, registry *core.PluginRegistry) *CaptureHandler {
+	handler := &CaptureHandler{registry: registry, captures: map[string]*Capture{}}
+	r.OPTIONS("/capture", handler.Preflight)
+	r.POST("/capture", handler.Capture)
+	r.GET("/capture/token", handler.Token)
+	r.GET("/capture/:id", handler.Get)
+	return handler
+}
+
+// Preflight answers the CORS preflight browser extensions send before posting JSON, the post itself
+// is refused without the capture token
+func (h *CaptureHandler) Preflight(c *gin.Context) {
+	if origin := c.GetHeader("Origin"); origin != "" {
+		c.Header("Access-Control-Allow-Origin", origin)
+		c.Header("Vary", "Origin")
+	}
+	c.Header("Access-Control-Allow-Methods", "POST, OPTIONS")
+	c.Header("Access-Control-Allow-Headers", "Content-Type, "+APIKeyHeader)
+	c.Status(http.StatusNoContent)
+}
+
+// Capture handles the POST /capture route. The selection is used as input, without one the page is scraped.
+func (h *CaptureHandler) Capture(c *gin.Context) {
+	var req CaptureRequest
+	if err := c.BindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid request"})
+		return
+	}
+
+	token, err := h.captureToken()
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	if subtle.ConstantTimeCompare([]byte(req.Token), []byte(token)) != 1 {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "invalid capture token"})
+		return
+	}
+	// the bookmarklet posts from the page origin, only the holder of the token reads the answer
+	if origin := c.GetHeader("Origin"); origin != "" {
+		c.Header("Access-Control-Allow-Origin", origin)
+		c.Header("Vary", "Origin")
+	}
+
+	content := strings.TrimSpace(req.Selection)
+	if content == "" {
+		if req.URL == "" {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "url or selection is required"})
+			return
+		}
+		if !h.registry.Jina.IsConfigured() {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "no selection and scraping is not configured, please set up Jina"})
+			return
+		}
+		if content, err = h.registry.Jina.ScrapeURL(req.URL); err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+			return
+		}
+	}
+
+	if req.PatternName != "" {
+		output, err := runPrompt(c.Request.Context(), h.registry, PromptRequest{
+			UserInput:    content,
+			Model:        req.Model,
+			PatternName:  req.PatternName,
+			StrategyName: req.StrategyName,
+			Variables:    req.Variables,
+		}, req.Language)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+			return
+		}
+		c.JSON(http.StatusOK, CaptureResponse{Output: output})
+		return
+	}
+
+	id := newCaptureId()
+	h.mu.Lock()
+	h.captures[id] = &Capture{URL: req.URL, Title: req.Title, Content: content}
+	h.mu.Unlock()
+
+	c.JSON(http.StatusOK, CaptureResponse{Id: id, OpenURL: "/chat?capture=" + id})
+}
+
+// Get handles the GET /capture/:id route. A capture can be picked up only once.
+func (h *CaptureHandler) Get(c *gin.Context) {
+	id := c.Param("id")
+
+	h.mu.Lock()
+	capture, ok := h.captures[id]
+	delete(h.captures, id)
+	h.mu.Unlock()
+
+	if !ok {
+		c.JSON(http.StatusNotFound, gin.H{"error": "capture not found"})
+		return
+	}
+	c.JSON(http.StatusOK, capture)
+}
+
+// Token handles the GET /capture/token route, returning the capture token to put in the bookmarklet. It
+// sends no CORS header, so the pages of other origins can't read it.
+func (h *CaptureHandler) Token(c *gin.Context) {
+	token, err := h.captureToken()
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"token": token})
+}
+
+// captureToken reads the capture token of the install, creating it the first time
+func (h *CaptureHandler) captureToken() (ret string, err error) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	if h.token != "" {
+		return h.token, nil
+	}
+
+	path := h.registry.Db.FilePath(CaptureTokenFileName)
+	var data []byte
+	if data, err = os.ReadFile(path); err == nil && strings.TrimSpace(string(data)) != "" {
+		h.token = strings.TrimSpace(string(data))
+		return h.token, nil
+	}
+	buf := make([]byte, 16)
+	if _, err = rand.Read(buf); err != nil {
+		return
+	}
+	token := hex.EncodeToString(buf)
+	if err = os.WriteFile(path, []byte(token+"\n"), 0600); err != nil {
+		err = fmt.Errorf("could not save the capture token: %v", err)
+		return
+	}
+	h.token = token
+	return h.token, nil
+}
+
+func newCaptureId() string {
+	buf := make([]byte, 8)
+	_, _ = rand.Read(buf)
+	return hex.EncodeToString(buf)
+}
@@ -1,13 +1,13 @@
 package restapi
 
 import (
+	"context"
 	"encoding/json"
 	"fmt"
 	"log"
 	"net/http"
-	"os"
-	"path/filepath"
 	"strings"
+	"time"
 
 	"github.com/danielmiessler/fabric/internal/chat"
 
@@ -17,6 +17,13 @@ import (
 	"github.com/gin-gonic/gin"
 )
 
+// AppHeader names the client sending the request, e.g. "web 1.4.0", recorded in the run environment.
+// Requests without it are recorded as defaultApp.
+const (
+	AppHeader  = "X-Fabric-App"
+	defaultApp = "api"
+)
+
 type ChatHandler struct {
 	registry *core.PluginRegistry
 	db       *fsdb.Db
@@ -28,20 +35,35 @@ type PromptRequest struct {
 	Model        string            `json:"model"`
 	ContextName  string            `json:"contextName"`
 	PatternName  string            `json:"patternName"`
-	StrategyName string            `json:"strategyName"`        // Optional strategy name
+	StrategyName string            `json:"strategyName"`        // Optional strategy prepended to the system prompt
+	SessionName  string            `json:"sessionName"`         // Optional session to continue, created if missing
 	Variables    map[string]string `json:"variables,omitempty"` // Pattern variables
+	Images       []string          `json:"images,omitempty"`    // data:image/...;base64 URLs sent to vision models with the input
 }
 
 type ChatRequest struct {
 	Prompts            []PromptRequest `json:"prompts"`
-	Language           string          `json:"language"` // Add Language field to bind from request
+	Language           string          `json:"language"`     // Add Language field to bind from request
+	StallTimeout       int             `json:"stallTimeout"` // seconds without a response before a "stall" is sent, defaultStallTimeout when not set, negative for never
 	domain.ChatOptions                 // Embed the ChatOptions from common package
 }
 
+// defaultStallTimeout is the number of seconds without a response before the client is told the request stalled
+const defaultStallTimeout = 90
+
 type StreamResponse struct {
-	Type    string `json:"type"`    // "content", "error", "complete"
-	Format  string `json:"format"`  // "markdown", "mermaid", "plain"
-	Content string `json:"content"` // The actual content
+	Type     string                    `json:"type"`               // "chunk", "content", "error", "stall", "complete"
+	Format   string                    `json:"format"`             // "markdown", "mermaid", "json", "plain"
+	Content  string                    `json:"content"`            // The actual content
+	Metadata *domain.ExecutionMetadata `json:"metadata,omitempty"` // Sent with "complete" after a successful run
+	Stall    *StallInfo                `json:"stall,omitempty"`    // Sent with "stall", the client decides to wait, reconnect or cancel
+	Chunk    *domain.StreamChunk       `json:"chunk,omitempty"`    // Sent with "chunk" as the model answers, before the "content" of the whole answer
+}
+
+// StallInfo describes a request without a response for the stall timeout
+type StallInfo struct {
+	State   core.StallState `json:"state"`   // thinking, dead or unknown
+	Seconds int             `json:"seconds"` // without a response
 }
 
 func NewChatHandler(r *gin.Engine, registry *core.PluginRegistry, db *fsdb.Db) *ChatHandler {
@@ -76,6 +98,14 @@ func (h *ChatHandler) HandleChat(c *gin.Context) {
 	c.Writer.Header().Set("X-Accel-Buffering", "no")
 
 	clientGone := c.Writer.CloseNotify()
+	app := clientApp(c)
+	stallTimeout := request.StallTimeout
+	if stallTimeout == 0 {
+		stallTimeout = defaultStallTimeout
+	}
+	if !h.registry.Features().Enabled(core.FeatureStallWatchdog) {
+		stallTimeout = -1
+	}
 
 	for i, prompt := range request.Prompts {
 		select {
@@ -87,41 +117,60 @@ func (h *ChatHandler) HandleChat(c *gin.Context) {
 				i+1, prompt.Model, prompt.PatternName, prompt.ContextName)
 
 			streamChan := make(chan string)
+			stallChan := make(chan core.StallEvent, 1)
+			// unbuffered, so every chunk is written before the content of the whole answer
+			chunkChan := make(chan domain.StreamChunk)
+			// written by the goroutine before it closes streamChan
+			var metadata *domain.ExecutionMetadata
 
 			go func(p PromptRequest) {
 				defer close(streamChan)
 
-				// Load and prepend strategy prompt if strategyName is set
-				if p.StrategyName != "" {
-					strategyFile := filepath.Join(os.Getenv("HOME"), ".config", "fabric", "strategies", p.StrategyName+".json")
-					data, err := os.ReadFile(strategyFile)
-					if err == nil {
-						var s struct {
-							Prompt string `json:"prompt"`
-						}
-						if err := json.Unmarshal(data, &s); err == nil && s.Prompt != "" {
-							p.UserInput = s.Prompt + "\n" + p.UserInput
+				chatter, err := h.registry.GetChatter(p.Model, 2048, p.Vendor, p.StrategyName, false, false)
+				if err != nil {
+					log.Printf("Error creating chatter: %v", err)
+					streamChan <- fmt.Sprintf("Error: %v", err)
+					return
+				}
+				// the client is told about stalls and reconnects or cancels by itself
+				if stallTimeout > 0 {
+					chatter.StallTimeout = time.Duration(stallTimeout) * time.Second
+					chatter.OnStall = func(event core.StallEvent) core.StallAction {
+						select {
+						case stallChan <- event:
+						default:
 						}
+						return core.StallWait
 					}
 				}
 
-				chatter, err := h.registry.GetChatter(p.Model, 2048, p.Vendor, "", false, false)
+				chatter.ChunkListener = func(chunk domain.StreamChunk) {
+					// the end of the stream is sent as the content, or the error, of the whole answer
+					if chunk.Type == domain.StreamDone || chunk.Type == domain.StreamError {
+						return
+					}
+					select {
+					case chunkChan <- chunk:
+					case <-c.Request.Context().Done():
+					}
+				}
+
+				message, err := userMessage(p)
 				if err != nil {
-					log.Printf("Error creating chatter: %v", err)
 					streamChan <- fmt.Sprintf("Error: %v", err)
 					return
 				}
 
 				// Pass the language received in the initial request to the domain.ChatRequest
 				chatReq := &domain.ChatRequest{
-					Message: &chat.ChatCompletionMessage{
-						Role:    "user",
-						Content: p.UserInput,
-					},
+					Message:          message,
 					PatternName:      p.PatternName,
 					ContextName:      p.ContextName,
+					SessionName:      p.SessionName,
+					StrategyName:     p.StrategyName,
 					PatternVariables: p.Variables,      // Pass pattern variables
 					Language:         request.Language, // Pass the language field
+					App:              app,
 				}
 
 				opts := &domain.ChatOptions{
@@ -130,10 +179,13 @@ func (h *ChatHandler) HandleChat(c *gin.Context) {
 					TopP:             request.TopP,
 					FrequencyPenalty: request.FrequencyPenalty,
 					PresencePenalty:  request.PresencePenalty,
+					Seed:             request.Seed,
 					Thinking:         request.Thinking,
+					MaxTokens:        request.MaxTokens,
+					StopSequences:    request.StopSequences,
 				}
 
-				session, err := chatter.Send(chatReq, opts)
+				session, err := chatter.SendContext(c.Request.Context(), chatReq, opts)
 				if err != nil {
 					log.Printf("Error from chatter.Send: %v", err)
 					streamChan <- fmt.Sprintf("Error: %v", err)
@@ -146,6 +198,7 @@ func (h *ChatHandler) HandleChat(c *gin.Context) {
 					return
 				}
 
+				metadata = session.Metadata
 				lastMsg := session.GetLastMessage()
 				if lastMsg != nil {
 					streamChan <- lastMsg.Content
@@ -155,11 +208,32 @@ func (h *ChatHandler) HandleChat(c *gin.Context) {
 				}
 			}(prompt)
 
-			for content := range streamChan {
+		receive:
+			for {
 				select {
 				case <-clientGone:
 					return
-				default:
+				case event := <-stallChan:
+					response := StreamResponse{
+						Type:    "stall",
+						Format:  "plain",
+						Content: core.DescribeStall(event),
+						Stall:   &StallInfo{State: event.State, Seconds: int(event.Silence.Seconds())},
+					}
+					if err := writeSSEResponse(c.Writer, response); err != nil {
+						log.Printf("Error writing stall response: %v", err)
+						return
+					}
+				case chunk := <-chunkChan:
+					response := StreamResponse{Type: "chunk", Format: "plain", Chunk: &chunk}
+					if err := writeSSEResponse(c.Writer, response); err != nil {
+						log.Printf("Error writing chunk response: %v", err)
+						return
+					}
+				case content, ok := <-streamChan:
+					if !ok {
+						break receive
+					}
 					var response StreamResponse
 					if strings.HasPrefix(content, "Error:") {
 						response = StreamResponse{
@@ -182,9 +256,10 @@ func (h *ChatHandler) HandleChat(c *gin.Context) {
 			}
 
 			completeResponse := StreamResponse{
-				Type:    "complete",
-				Format:  "plain",
-				Content: "",
+				Type:     "complete",
+				Format:   "plain",
+				Content:  "",
+				Metadata: metadata,
 			}
 			if err := writeSSEResponse(c.Writer, completeResponse); err != nil {
 				log.Printf("Error writing completion response: %v", err)
@@ -194,6 +269,42 @@ func (h *ChatHandler) HandleChat(c *gin.Context) {
 	}
 }
 
+// runPrompt sends a single prompt without streaming and returns the model output
+func runPrompt(ctx context.Context, registry *core.PluginRegistry, prompt PromptRequest, language string) (output string, err error) {
+	var chatter *core.Chatter
+	if chatter, err = registry.GetChatter(prompt.Model, 0, prompt.Vendor, prompt.StrategyName, false, false); err != nil {
+		return
+	}
+
+	chatReq := &domain.ChatRequest{
+		Message: &chat.ChatCompletionMessage{
+			Role:    chat.ChatMessageRoleUser,
+			Content: prompt.UserInput,
+		},
+		PatternName:      prompt.PatternName,
+		PatternVariables: prompt.Variables,
+		ContextName:      prompt.ContextName,
+		SessionName:      prompt.SessionName,
+		StrategyName:     prompt.StrategyName,
+		Language:         language,
+		App:              defaultApp,
+	}
+	opts := &domain.ChatOptions{
+		Model:       prompt.Model,
+		Temperature: domain.DefaultTemperature,
+		TopP:        domain.DefaultTopP,
+	}
+
+	var session *fsdb.Session
+	if session, err = chatter.SendContext(ctx, chatReq, opts); err != nil {
+		return
+	}
+	if lastMsg := session.GetLastMessage(); lastMsg != nil {
+		output = lastMsg.Content
+	}
+	return
+}
+
 func writeSSEResponse(w gin.ResponseWriter, response StreamResponse) error {
 	data, err := json.Marshal(response)
 	if err != nil {
@@ -217,5 +328,40 @@ func detectFormat(content string) string {
 		strings.HasPrefix(content, "stateDiagram") {
 		return "mermaid"
 	}
+	if trimmed := strings.TrimSpace(content); (strings.HasPrefix(trimmed, "{") || strings.HasPrefix(trimmed, "[")) &&
+		json.Valid([]byte(trimmed)) {
+		return "json"
+	}
 	return "markdown"
 }
+
+// clientApp is the client of the request as sent in AppHeader
+func clientApp(c *gin.Context) string {
+	if app := strings.TrimSpace(c.GetHeader(AppHeader)); app != "" {
+		return app
+	}
+	return defaultApp
+}
+
+// userMessage is the input of the prompt, with its images as image parts like the attachments of the CLI
+func userMessage(p PromptRequest) (ret *chat.ChatCompletionMessage, err error) {
+	ret = &chat.ChatCompletionMessage{Role: chat.ChatMessageRoleUser}
+	if len(p.Images) == 0 {
+		ret.Content = p.UserInput
+		return
+	}
+	if p.UserInput != "" {
+		ret.MultiContent = append(ret.MultiContent, chat.ChatMessagePart{Type: chat.ChatMessagePartTypeText, Text: p.UserInput})
+	}
+	for i, image := range p.Images {
+		if !strings.HasPrefix(image, "data:image/") || !strings.Contains(image, ";base64,") {
+			err = fmt.Errorf("image %d is not a base64 data URL of an image", i+1)
+			return
+		}
+		ret.MultiContent = append(ret.MultiContent, chat.ChatMessagePart{
+			Type:     chat.ChatMessagePartTypeImageURL,
+			ImageURL: &chat.ChatMessageImageURL{URL: image},
+		})
+	}
+	return
+}
@@ -0,0 +1,48 @@
+package restapi
+
+import (
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/danielmiessler/fabric/internal/tools/codebase"
+	"github.com/gin-gonic/gin"
+)
+
+// CodeCorpusRequest selects the files of a repository for the code input source
+type CodeCorpusRequest struct {
+	Root string `json:"root"` // absolute path of the repository
+	codebase.CorpusOptions
+}
+
+// NewCodeHandler registers the /code/corpus POST endpoint, concatenating the files of a repository on the
+// server matching include and exclude globs within a token budget. The repository must be below one of the
+// directories of FABRIC_CODE_ROOTS, the endpoint is disabled when it is unset.
+func NewCodeHandler(r *gin.Engine) {
+	r.POST("/code/corpus", func(c *gin.Context) {
+		var request CodeCorpusRequest
+		if err := c.BindJSON(&request); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "invalid request"})
+			return
+		}
+		// read on every request, the configuration page of the web UI may change it
+		allowed := filepath.SplitList(os.Getenv(codebase.CodeRootsEnv))
+		if len(allowed) == 0 {
+			c.JSON(http.StatusForbidden, gin.H{"error": "the code input source is disabled, list the directories of the repositories in " + codebase.CodeRootsEnv})
+			return
+		}
+		root, err := codebase.AllowedRoot(strings.TrimSpace(request.Root), allowed)
+		if err != nil {
+			c.JSON(http.StatusForbidden, gin.H{"error": err.Error()})
+			return
+		}
+
+		corpus, err := codebase.BuildCorpus(root, request.CorpusOptions)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		c.JSON(http.StatusOK, corpus)
+	})
+}
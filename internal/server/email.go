@@ -0,0 +1,76 @@
+package restapi
+
+import (
+	"io"
+	"net/http"
+	"strconv"
+
+	"github.com/danielmiessler/fabric/internal/tools/email"
+	"github.com/gin-gonic/gin"
+)
+
+// maxEmailSize limits the uploaded emails and mailboxes
+const maxEmailSize = 50 << 20
+
+// EmailHandler parses uploaded .eml and .mbox files for the email input source
+type EmailHandler struct{}
+
+// EmailResponse is the response of POST /email
+type EmailResponse struct {
+	Messages []*email.Message `json:"messages"`
+	Markdown string           `json:"markdown"` // the latest messages rendered to send to a pattern
+}
+
+func NewEmailHandler(r *gin.Engine) (ret *EmailHandler) {
+	ret = &EmailHandler{}
+	r.POST("/email", ret.Parse)
+	return
+}
+
+// Parse handles POST /email, a multipart form with the .eml or .mbox "file" and the optional number of
+// the latest "messages" to render, email.DefaultMessages when not set
+func (h *EmailHandler) Parse(c *gin.Context) {
+	fileHeader, err := c.FormFile("file")
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "an email file is required"})
+		return
+	}
+	if !email.IsEmail(fileHeader.Filename) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "only .eml and .mbox files are supported"})
+		return
+	}
+	if fileHeader.Size > maxEmailSize {
+		c.JSON(http.StatusRequestEntityTooLarge, gin.H{"error": "the email file is too large"})
+		return
+	}
+	count := email.DefaultMessages
+	if value := c.PostForm("messages"); value != "" {
+		if count, err = strconv.Atoi(value); err != nil || count <= 0 {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "invalid messages, expected a positive number"})
+			return
+		}
+	}
+
+	file, err := fileHeader.Open()
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	defer file.Close()
+
+	var data []byte
+	if data, err = io.ReadAll(file); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	var messages []*email.Message
+	if messages, err = email.Parse(fileHeader.Filename, data); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if len(messages) > count {
+		messages = messages[len(messages)-count:]
+	}
+	c.JSON(http.StatusOK, EmailResponse{Messages: messages, Markdown: email.Markdown(messages, count)})
+}
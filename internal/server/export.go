@@ -0,0 +1,89 @@
+package restapi
+
+import (
+	"net/http"
+	"strings"
+
+	"github.com/danielmiessler/fabric/internal/plugins/db/fsdb"
+	"github.com/danielmiessler/fabric/internal/tools/export"
+	"github.com/gin-gonic/gin"
+)
+
+type ExportRequest struct {
+	Content string `json:"content"`
+	Style   string `json:"style"`  // defaults to minimal
+	Format  string `json:"format"` // "markdown", "html" or "docx"
+	Title   string `json:"title"`  // defaults to the leading heading of the content
+	Pattern string `json:"pattern"`
+	Model   string `json:"model"`
+	// Provenance embeds the fabric version, pattern, model and date: meta tags and a footer in HTML, a footer in markdown
+	Provenance bool `json:"provenance"`
+	// Notes of the user on the output, added after it in a Notes section
+	Notes string `json:"notes"`
+}
+
+// ExportHandler exports outputs with the export styles, the custom templates are read from the config dir
+type ExportHandler struct {
+	styles  *export.Styles
+	version string // of fabric, named in the provenance
+}
+
+func NewExportHandler(r *gin.Engine, db *fsdb.Db, version string) *ExportHandler {
+	handler := &ExportHandler{styles: export.NewStyles(db.FilePath(export.StylesDir)), version: version}
+	r.GET("/export/styles", handler.ListStyles)
+	r.POST("/export", handler.Export)
+	return handler
+}
+
+// ListStyles handles the GET /export/styles route
+func (h *ExportHandler) ListStyles(c *gin.Context) {
+	names, err := h.styles.Names()
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"styles": names, "default": export.DefaultStyle})
+}
+
+// docxContentType is the media type of Word documents
+const docxContentType = "application/vnd.openxmlformats-officedocument.wordprocessingml.document"
+
+// Export handles the POST /export route, returning the exported document, as is when it is a binary Word
+// document
+func (h *ExportHandler) Export(c *gin.Context) {
+	var request ExportRequest
+	if err := c.BindJSON(&request); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid request"})
+		return
+	}
+	if request.Format == "" {
+		request.Format = export.FormatMarkdown
+	}
+
+	content, err := h.styles.Render(request.Style, request.Format, export.Document{
+		Title:   request.Title,
+		Pattern: request.Pattern,
+		Model:   request.Model,
+		Content: withNotes(request.Content, request.Notes),
+
+		Watermark: request.Provenance,
+		Version:   h.version,
+	})
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if request.Format == export.FormatDocx {
+		c.Data(http.StatusOK, docxContentType, []byte(content))
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"content": content})
+}
+
+// withNotes adds the notes after the content in a Notes section
+func withNotes(content string, notes string) string {
+	if notes = strings.TrimSpace(notes); notes == "" {
+		return content
+	}
+	return strings.TrimRight(content, "\n") + "\n\n## Notes\n\n" + notes + "\n"
+}
@@ -0,0 +1,59 @@
+package restapi
+
+import (
+	"net/http"
+
+	"github.com/danielmiessler/fabric/internal/core"
+	"github.com/gin-gonic/gin"
+)
+
+// FeatureState is a feature with its current flag
+type FeatureState struct {
+	core.Feature
+	Enabled bool `json:"enabled"`
+}
+
+// FeaturesHandler reads and updates the feature flags gating the experimental subsystems
+type FeaturesHandler struct {
+	registry *core.PluginRegistry
+}
+
+func NewFeaturesHandler(r *gin.Engine, registry *core.PluginRegistry) *FeaturesHandler {
+	handler := &FeaturesHandler{registry: registry}
+	r.GET("/features", handler.Get)
+	r.PUT("/features", handler.Update)
+	return handler
+}
+
+// Get handles the GET /features route
+func (h *FeaturesHandler) Get(c *gin.Context) {
+	c.JSON(http.StatusOK, h.states())
+}
+
+// Update handles the PUT /features route, saving the flags and applying them to new runs. The scheduler and
+// the watcher apply them on restart.
+func (h *FeaturesHandler) Update(c *gin.Context) {
+	var flags core.FeatureFlags
+	if err := c.BindJSON(&flags); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid request"})
+		return
+	}
+	if err := flags.Validate(); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	if err := core.SaveFeatureFlags(h.registry.Db.FilePath(core.FeaturesFileName), flags); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	h.registry.SetFeatures(flags)
+	c.JSON(http.StatusOK, h.states())
+}
+
+func (h *FeaturesHandler) states() (ret []FeatureState) {
+	for _, feature := range core.Features {
+		ret = append(ret, FeatureState{Feature: feature, Enabled: h.registry.Features().Enabled(feature.Name)})
+	}
+	return
+}
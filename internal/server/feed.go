@@ -0,0 +1,46 @@
+package restapi
+
+import (
+	"net/http"
+	"strings"
+
+	"github.com/danielmiessler/fabric/internal/tools/feed"
+	"github.com/gin-gonic/gin"
+)
+
+type FeedRequest struct {
+	URL     string `json:"url"`
+	Entries int    `json:"entries"` // the number of latest entries, feed.DefaultEntries when not set
+}
+
+type FeedResponse struct {
+	Title    string        `json:"title"`
+	Entries  []*feed.Entry `json:"entries"`
+	Markdown string        `json:"markdown"` // the entries rendered to send to a pattern
+}
+
+// NewFeedHandler registers the /feed POST endpoint, fetching the latest entries of a RSS or Atom feed
+func NewFeedHandler(r *gin.Engine) {
+	client := feed.NewClient()
+	r.POST("/feed", func(c *gin.Context) {
+		var request FeedRequest
+		if err := c.BindJSON(&request); err != nil || strings.TrimSpace(request.URL) == "" {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "invalid request"})
+			return
+		}
+		if request.Entries <= 0 {
+			request.Entries = feed.DefaultEntries
+		}
+
+		rss, err := client.Fetch(strings.TrimSpace(request.URL))
+		if err != nil {
+			c.JSON(http.StatusBadGateway, gin.H{"error": err.Error()})
+			return
+		}
+		c.JSON(http.StatusOK, FeedResponse{
+			Title:    rss.Title,
+			Entries:  rss.Latest(request.Entries),
+			Markdown: rss.Markdown(request.Entries),
+		})
+	})
+}
@@ -0,0 +1,382 @@
+package restapi
+
+import (
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/danielmiessler/fabric/internal/core"
+	"github.com/danielmiessler/fabric/internal/domain"
+	"github.com/danielmiessler/fabric/internal/plugins/db/fsdb"
+	"github.com/danielmiessler/fabric/internal/tools/textdiff"
+	"github.com/gin-gonic/gin"
+)
+
+const maxSearchResults = 100
+
+// latencyDays is the default number of past days the typical latencies of the models are computed on
+const latencyDays = 30
+
+// CalendarDay is one cell of the run history heatmap
+type CalendarDay struct {
+	Date      string   `json:"date"`
+	Runs      int      `json:"runs"`
+	Scheduled []string `json:"scheduled,omitempty"` // jobs scheduled on the day
+}
+
+// RunFacets are the values the runs can be filtered by
+type RunFacets struct {
+	Patterns []string `json:"patterns"`
+	Models   []string `json:"models"`
+}
+
+// ReplayResponse is the result of replaying a run
+type ReplayResponse struct {
+	Output   string                    `json:"output"`
+	Metadata *domain.ExecutionMetadata `json:"metadata,omitempty"`
+}
+
+// RunNotesRequest sets the notes of a run, empty notes remove them
+type RunNotesRequest struct {
+	Notes string `json:"notes"`
+}
+
+// RunDiff compares the outputs of two runs
+type RunDiff struct {
+	A       *fsdb.Run       `json:"a"`
+	B       *fsdb.Run       `json:"b"`
+	Lines   []textdiff.Line `json:"lines"`
+	Unified string          `json:"unified"`
+	// the sentences of the outputs aligned by similarity, for the heatmap of the sections that differ most
+	Sentences []textdiff.SentencePair `json:"sentences"`
+}
+
+// HistoryHandler serves the run history
+type HistoryHandler struct {
+	registry  *core.PluginRegistry
+	history   *fsdb.HistoryEntity
+	scheduler *core.Scheduler
+}
+
+func NewHistoryHandler(r *gin.Engine, registry *core.PluginRegistry, scheduler *core.Scheduler) *HistoryHandler {
+	handler := &HistoryHandler{registry: registry, history: registry.Db.History, scheduler: scheduler}
+	r.GET("/history/calendar", handler.Calendar)
+	r.GET("/history/days/:date", handler.Day)
+	r.GET("/history/runs/:id", handler.Run)
+	r.DELETE("/history/runs/:id", handler.Delete)
+	r.PUT("/history/runs/:id/notes", handler.SetNotes)
+	r.POST("/history/runs/:id/replay", handler.Replay)
+	r.GET("/history/diff", handler.Diff)
+	r.GET("/history/search", handler.Search)
+	r.GET("/history/runs", handler.Runs)
+	r.GET("/history/facets", handler.Facets)
+	r.GET("/history/latency", handler.Latency)
+	r.GET("/history/patterns/:name/stats", handler.PatternStats)
+	r.GET("/history/usage", handler.Usage)
+	return handler
+}
+
+// Calendar handles the GET /history/calendar route. It returns the number of runs and the scheduled jobs
+// for every day from the "from" to the "to" query date (inclusive), defaulting to the last year.
+func (h *HistoryHandler) Calendar(c *gin.Context) {
+	today := startOfDay(time.Now())
+	from, err := parseDay(c.Query("from"), today.AddDate(-1, 0, 1))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid from date, expected YYYY-MM-DD"})
+		return
+	}
+	to, err := parseDay(c.Query("to"), today)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid to date, expected YYYY-MM-DD"})
+		return
+	}
+
+	counts, err := h.history.CountRunsByDay(from, to.AddDate(0, 0, 1))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	// only upcoming runs are scheduled, past days show what actually ran
+	scheduled := map[string][]string{}
+	if now := time.Now(); to.AddDate(0, 0, 1).After(now) {
+		if scheduled, err = h.scheduler.Upcoming(now, to.AddDate(0, 0, 1)); err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+			return
+		}
+	}
+
+	days := []CalendarDay{}
+	for day := from; !day.After(to); day = day.AddDate(0, 0, 1) {
+		date := day.Format(fsdb.HistoryDayLayout)
+		days = append(days, CalendarDay{Date: date, Runs: counts[date], Scheduled: scheduled[date]})
+	}
+	c.JSON(http.StatusOK, days)
+}
+
+// Latency handles the GET /history/latency route, returning the median latency in milliseconds of the
+// successful runs of the last "days" (default 30) by model
+func (h *HistoryHandler) Latency(c *gin.Context) {
+	days, err := strconv.Atoi(c.DefaultQuery("days", strconv.Itoa(latencyDays)))
+	if err != nil || days <= 0 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid days, expected a positive number"})
+		return
+	}
+
+	latencies, err := h.history.MedianLatencyByModel(time.Now().AddDate(0, 0, -days))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, latencies)
+}
+
+// PatternStats handles the GET /history/patterns/:name/stats route, returning the runs and failures of
+// the pattern in the last "days" (default 30) by model
+func (h *HistoryHandler) PatternStats(c *gin.Context) {
+	days, err := strconv.Atoi(c.DefaultQuery("days", strconv.Itoa(latencyDays)))
+	if err != nil || days <= 0 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid days, expected a positive number"})
+		return
+	}
+
+	stats, err := h.history.PatternRunStats(c.Param("name"), time.Now().AddDate(0, 0, -days))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, stats)
+}
+
+// Usage handles the GET /history/usage route, returning how often and when each pattern was last run
+func (h *HistoryHandler) Usage(c *gin.Context) {
+	usage, err := h.history.PatternUsage()
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, usage)
+}
+
+// Day handles the GET /history/days/:date route, returning the runs of the day newest first
+func (h *HistoryHandler) Day(c *gin.Context) {
+	day, err := parseDay(c.Param("date"), time.Time{})
+	if err != nil || day.IsZero() {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid date, expected YYYY-MM-DD"})
+		return
+	}
+
+	runs, err := h.history.ListRuns(day, day.AddDate(0, 0, 1))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	if runs == nil {
+		runs = []*fsdb.Run{}
+	}
+	c.JSON(http.StatusOK, runs)
+}
+
+// Search handles the GET /history/search route, returning the latest runs, up to "limit" (default 20),
+// with the "q" query in their pattern, job, input, output or notes
+func (h *HistoryHandler) Search(c *gin.Context) {
+	query := strings.TrimSpace(c.Query("q"))
+	if query == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "q is required"})
+		return
+	}
+	limit, err := strconv.Atoi(c.DefaultQuery("limit", "20"))
+	if err != nil || limit <= 0 || limit > maxSearchResults {
+		c.JSON(http.StatusBadRequest, gin.H{"error": fmt.Sprintf("invalid limit, expected 1 to %d", maxSearchResults)})
+		return
+	}
+
+	runs, err := h.history.SearchRuns(query, limit)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	if runs == nil {
+		runs = []*fsdb.Run{}
+	}
+	c.JSON(http.StatusOK, runs)
+}
+
+// Runs handles the GET /history/runs route, returning the runs newest first, up to "limit" (default 20)
+// from "offset". They are filtered by the "q" query in their pattern, job, input, output or notes, the exact
+// "pattern" and "model", and the "from" and "to" days (inclusive).
+func (h *HistoryHandler) Runs(c *gin.Context) {
+	filter := fsdb.RunFilter{
+		Query:   strings.TrimSpace(c.Query("q")),
+		Pattern: c.Query("pattern"),
+		Model:   c.Query("model"),
+	}
+	var err error
+	if filter.Limit, err = strconv.Atoi(c.DefaultQuery("limit", "20")); err != nil || filter.Limit <= 0 || filter.Limit > maxSearchResults {
+		c.JSON(http.StatusBadRequest, gin.H{"error": fmt.Sprintf("invalid limit, expected 1 to %d", maxSearchResults)})
+		return
+	}
+	if filter.Offset, err = strconv.Atoi(c.DefaultQuery("offset", "0")); err != nil || filter.Offset < 0 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid offset, expected a positive number"})
+		return
+	}
+	if filter.From, err = parseDay(c.Query("from"), time.Time{}); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid from date, expected YYYY-MM-DD"})
+		return
+	}
+	var to time.Time
+	if to, err = parseDay(c.Query("to"), time.Time{}); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid to date, expected YYYY-MM-DD"})
+		return
+	}
+	if !to.IsZero() {
+		filter.To = to.AddDate(0, 0, 1)
+	}
+
+	runs, err := h.history.FilterRuns(filter)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	if runs == nil {
+		runs = []*fsdb.Run{}
+	}
+	c.JSON(http.StatusOK, runs)
+}
+
+// Facets handles the GET /history/facets route, returning the patterns and models the runs can be
+// filtered by
+func (h *HistoryHandler) Facets(c *gin.Context) {
+	patterns, models, err := h.history.RunFacets()
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	facets := RunFacets{Patterns: patterns, Models: models}
+	if facets.Patterns == nil {
+		facets.Patterns = []string{}
+	}
+	if facets.Models == nil {
+		facets.Models = []string{}
+	}
+	c.JSON(http.StatusOK, facets)
+}
+
+// Run handles the GET /history/runs/:id route
+func (h *HistoryHandler) Run(c *gin.Context) {
+	id := c.Param("id")
+	if !h.history.Exists(id) {
+		c.JSON(http.StatusNotFound, gin.H{"error": "run not found"})
+		return
+	}
+	run, err := h.history.GetRun(id)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, run)
+}
+
+// Delete handles the DELETE /history/runs/:id route, moving the run to the trash
+func (h *HistoryHandler) Delete(c *gin.Context) {
+	id := c.Param("id")
+	if !h.history.Exists(id) {
+		c.JSON(http.StatusNotFound, gin.H{"error": "run not found"})
+		return
+	}
+	item, err := h.registry.Db.Trash.TrashRun(id)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, item)
+}
+
+// SetNotes handles the PUT /history/runs/:id/notes route, returning the run with its notes
+func (h *HistoryHandler) SetNotes(c *gin.Context) {
+	var request RunNotesRequest
+	if err := c.ShouldBindJSON(&request); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid request"})
+		return
+	}
+	run, err := h.history.SetNotes(c.Param("id"), request.Notes)
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, run)
+}
+
+// Replay handles the POST /history/runs/:id/replay route, running the run again with identical parameters
+func (h *HistoryHandler) Replay(c *gin.Context) {
+	id := c.Param("id")
+	if !h.history.Exists(id) {
+		c.JSON(http.StatusNotFound, gin.H{"error": "run not found"})
+		return
+	}
+	run, err := h.history.GetRun(id)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	session, err := h.registry.ReplayRun(c.Request.Context(), run)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	response := ReplayResponse{Metadata: session.Metadata}
+	if lastMsg := session.GetLastMessage(); lastMsg != nil {
+		response.Output = lastMsg.Content
+	}
+	c.JSON(http.StatusOK, response)
+}
+
+// Diff handles the GET /history/diff?a=<run id>&b=<run id> route, diffing the output of run a to run b
+func (h *HistoryHandler) Diff(c *gin.Context) {
+	var runs [2]*fsdb.Run
+	for i, id := range []string{c.Query("a"), c.Query("b")} {
+		if id == "" || !h.history.Exists(id) {
+			c.JSON(http.StatusNotFound, gin.H{"error": "run not found: " + id})
+			return
+		}
+		var err error
+		if runs[i], err = h.history.GetRun(id); err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+			return
+		}
+	}
+
+	a, b := runs[0], runs[1]
+	c.JSON(http.StatusOK, RunDiff{
+		A:         a,
+		B:         b,
+		Lines:     textdiff.Lines(a.Output, b.Output),
+		Unified:   textdiff.Unified(a.Output, b.Output, runLabel(a), runLabel(b), 3),
+		Sentences: textdiff.Sentences(a.Output, b.Output),
+	})
+}
+
+// runLabel names a run in the diff header, e.g. "summarize OpenAI|gpt-4o 2024-03-10 12:00:00"
+func runLabel(run *fsdb.Run) string {
+	label := run.PatternName
+	if run.Metadata != nil {
+		label += " " + run.Metadata.Vendor + "|" + run.Metadata.Model
+	}
+	return strings.TrimSpace(label + " " + run.Timestamp.Local().Format(time.DateTime))
+}
+
+func parseDay(value string, defaultDay time.Time) (ret time.Time, err error) {
+	if value == "" {
+		ret = defaultDay
+		return
+	}
+	ret, err = time.ParseInLocation(fsdb.HistoryDayLayout, value, time.Local)
+	return
+}
+
+func startOfDay(t time.Time) time.Time {
+	return time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, t.Location())
+}
@@ -0,0 +1,92 @@
+package restapi
+
+import (
+	"net/http"
+	"os"
+	"sort"
+
+	"github.com/danielmiessler/fabric/internal/core"
+	"github.com/gin-gonic/gin"
+	"gopkg.in/yaml.v3"
+)
+
+type HooksResponse struct {
+	Config   *core.HooksConfig `json:"config"`
+	Builtins []string          `json:"builtins"`
+}
+
+// HooksHandler reads and updates the pre/post execution hooks config
+type HooksHandler struct {
+	registry *core.PluginRegistry
+}
+
+func NewHooksHandler(r *gin.Engine, registry *core.PluginRegistry) *HooksHandler {
+	handler := &HooksHandler{registry: registry}
+	r.GET("/hooks", handler.Get)
+	r.PUT("/hooks", handler.Update)
+	return handler
+}
+
+// Get handles the GET /hooks route
+func (h *HooksHandler) Get(c *gin.Context) {
+	config, err := core.LoadHooksConfig(h.configPath())
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	builtins := make([]string, 0, len(core.BuiltinTransforms))
+	for name := range core.BuiltinTransforms {
+		builtins = append(builtins, name)
+	}
+	sort.Strings(builtins)
+
+	c.JSON(http.StatusOK, HooksResponse{Config: config, Builtins: builtins})
+}
+
+// Update handles the PUT /hooks route, saving the config and applying it to new runs. Only the built-in
+// hooks are accepted, the commands would run whatever the client sent on the machine of the server.
+func (h *HooksHandler) Update(c *gin.Context) {
+	var config core.HooksConfig
+	if err := c.ShouldBindJSON(&config); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid request"})
+		return
+	}
+	if err := config.CheckBuiltinsOnly(); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	// the commands and templates of the hand-edited file would be lost
+	if current, err := core.LoadHooksConfig(h.configPath()); err != nil || current.CheckBuiltinsOnly() != nil {
+		c.JSON(http.StatusConflict, gin.H{"error": core.HooksFileName + " has commands or templates, edit it by hand"})
+		return
+	}
+
+	hooks, err := config.BuildHooks()
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	runDirs, err := config.RunDirs.Build()
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	data, err := yaml.Marshal(&config)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	if err = os.WriteFile(h.configPath(), data, 0644); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	h.registry.SetHooks(hooks, runDirs)
+	c.JSON(http.StatusOK, config)
+}
+
+func (h *HooksHandler) configPath() string {
+	return h.registry.Db.FilePath(core.HooksFileName)
+}
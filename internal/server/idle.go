@@ -0,0 +1,34 @@
+package restapi
+
+import (
+	"sync/atomic"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// idleAfter is how long the server has to go without requests to be idle
+const idleAfter = 2 * time.Minute
+
+// IdleTracker tells whether the server is idle: no request in progress and none for a while.
+// Background work like the model list prefetch waits for it not to compete with the users.
+type IdleTracker struct {
+	inFlight atomic.Int64
+	last     atomic.Int64 // unix nanoseconds of the end of the last request
+}
+
+// Middleware records the requests
+func (o *IdleTracker) Middleware() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		o.inFlight.Add(1)
+		defer func() {
+			o.last.Store(time.Now().UnixNano())
+			o.inFlight.Add(-1)
+		}()
+		c.Next()
+	}
+}
+
+func (o *IdleTracker) Idle() bool {
+	return o.inFlight.Load() == 0 && time.Since(time.Unix(0, o.last.Load())) >= idleAfter
+}
@@ -0,0 +1,92 @@
+package restapi
+
+import (
+	"net/http"
+
+	"github.com/danielmiessler/fabric/internal/core"
+	"github.com/danielmiessler/fabric/internal/plugins/db/fsdb"
+	"github.com/danielmiessler/fabric/internal/tools/cron"
+	"github.com/gin-gonic/gin"
+)
+
+// JobsHandler defines the handler for scheduled jobs
+type JobsHandler struct {
+	*StorageHandler[fsdb.Job]
+	jobs      *fsdb.JobsEntity
+	scheduler *core.Scheduler
+}
+
+// NewJobsHandler creates a new JobsHandler
+func NewJobsHandler(r *gin.Engine, jobs *fsdb.JobsEntity, scheduler *core.Scheduler) (ret *JobsHandler) {
+	ret = &JobsHandler{jobs: jobs, scheduler: scheduler}
+	// registered before the generic storage routes so the job is validated before it is saved
+	r.POST("/jobs/:name", ret.SaveJob)
+	r.POST("/jobs/:name/run", ret.RunJob)
+	r.GET("/jobs", ret.GetJobs)
+	ret.StorageHandler = &StorageHandler[fsdb.Job]{storage: jobs}
+	r.GET("/jobs/:name", ret.Get)
+	r.GET("/jobs/names", ret.GetNames)
+	r.DELETE("/jobs/:name", ret.Delete)
+	r.GET("/jobs/exists/:name", ret.Exists)
+	r.PUT("/jobs/rename/:oldName/:newName", ret.Rename)
+	return
+}
+
+// GetJobs handles the GET /jobs route
+func (h *JobsHandler) GetJobs(c *gin.Context) {
+	jobs, err := h.jobs.GetJobs()
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	if jobs == nil {
+		jobs = []*fsdb.Job{}
+	}
+	c.JSON(http.StatusOK, jobs)
+}
+
+// SaveJob handles the POST /jobs/:name route
+func (h *JobsHandler) SaveJob(c *gin.Context) {
+	var job fsdb.Job
+	if err := c.BindJSON(&job); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid request"})
+		return
+	}
+	job.Name = c.Param("name")
+
+	if job.PatternName == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "patternName is required"})
+		return
+	}
+	if _, err := cron.Parse(job.Schedule); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if job.InputFile != "" {
+		if _, err := h.jobs.InputPath(job.InputFile); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+	}
+
+	if err := h.jobs.SaveJob(&job); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, job)
+}
+
+// RunJob handles the POST /jobs/:name/run route, running the job right away in the background
+func (h *JobsHandler) RunJob(c *gin.Context) {
+	job, err := h.jobs.Get(c.Param("name"))
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		return
+	}
+
+	if err = h.scheduler.StartJob(job, nil); err != nil {
+		c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+		return
+	}
+	c.Status(http.StatusAccepted)
+}
@@ -0,0 +1,56 @@
+package restapi
+
+import (
+	"context"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/danielmiessler/fabric/internal/tools/mermaid"
+	"github.com/gin-gonic/gin"
+)
+
+// mermaidTimeout bounds a render, the mermaid CLI starts a headless browser
+const mermaidTimeout = 60 * time.Second
+
+type MermaidRequest struct {
+	Source string `json:"source"`
+}
+
+// MermaidHandler renders the mermaid diagrams of the outputs with the mermaid CLI
+type MermaidHandler struct {
+	renderer *mermaid.Renderer
+}
+
+func NewMermaidHandler(r *gin.Engine) (ret *MermaidHandler) {
+	ret = &MermaidHandler{renderer: mermaid.NewRenderer()}
+	r.POST("/mermaid", ret.Render)
+	return
+}
+
+// Render handles POST /mermaid, returning the SVG of the diagram. It fails with 503 when the mermaid CLI
+// isn't installed, the clients then show the diagram as text.
+func (h *MermaidHandler) Render(c *gin.Context) {
+	var request MermaidRequest
+	if err := c.BindJSON(&request); err != nil || strings.TrimSpace(request.Source) == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid request, a diagram is required"})
+		return
+	}
+	if len(request.Source) > mermaid.MaxSourceSize {
+		c.JSON(http.StatusRequestEntityTooLarge, gin.H{"error": "the diagram is too large to render"})
+		return
+	}
+	if !h.renderer.Available() {
+		c.JSON(http.StatusServiceUnavailable, gin.H{"error": "the mermaid CLI (mmdc) is not installed on the server"})
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(c.Request.Context(), mermaidTimeout)
+	defer cancel()
+	svg, err := h.renderer.RenderSVG(ctx, request.Source)
+	if err != nil {
+		c.JSON(http.StatusUnprocessableEntity, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"svg": string(svg)})
+}
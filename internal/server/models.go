@@ -1,6 +1,9 @@
 package restapi
 
 import (
+	"net/http"
+
+	"github.com/danielmiessler/fabric/internal/domain"
 	"github.com/danielmiessler/fabric/internal/plugins/ai"
 	"github.com/gin-gonic/gin"
 )
@@ -15,6 +18,8 @@ func NewModelsHandler(r *gin.Engine, vendorManager *ai.VendorsManager) {
 	}
 
 	r.GET("/models/names", handler.GetModelNames)
+	r.GET("/vendors/health", handler.GetVendorsHealth)
+	r.GET("/vendors/quota", handler.GetVendorsQuota)
 }
 
 func (h *ModelsHandler) GetModelNames(c *gin.Context) {
@@ -43,3 +48,22 @@ func (h *ModelsHandler) getAllModelNames(vendorsModels *ai.VendorsModels) []stri
 	}
 	return allModelNames
 }
+
+// GetVendorsHealth reports the circuit breaker state of every configured vendor,
+// so degraded vendors can be marked in the provider panel
+func (h *ModelsHandler) GetVendorsHealth(c *gin.Context) {
+	health := make([]ai.VendorHealth, 0, len(h.vendorManager.Vendors))
+	for _, vendor := range h.vendorManager.Vendors {
+		health = append(health, h.vendorManager.Breaker.Health(vendor.GetName()))
+	}
+	c.JSON(http.StatusOK, health)
+}
+
+// GetVendorsQuota reports the spend and remaining quota of the vendors whose API reports it
+func (h *ModelsHandler) GetVendorsQuota(c *gin.Context) {
+	quotas := h.vendorManager.Quotas(c.Request.Context())
+	if quotas == nil {
+		quotas = []*domain.Quota{}
+	}
+	c.JSON(http.StatusOK, quotas)
+}
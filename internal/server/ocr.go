@@ -0,0 +1,61 @@
+package restapi
+
+import (
+	"io"
+	"net/http"
+
+	"github.com/danielmiessler/fabric/internal/core"
+	"github.com/gin-gonic/gin"
+)
+
+// maxOCRImageSize limits the uploaded images, a full screen capture is a few MB
+const maxOCRImageSize = 20 << 20
+
+// OCRHandler recognizes the text of uploaded images, like screenshots, locally with Tesseract
+type OCRHandler struct {
+	registry *core.PluginRegistry
+}
+
+// OCRResponse is the response of POST /ocr
+type OCRResponse struct {
+	Text string `json:"text"`
+}
+
+func NewOCRHandler(r *gin.Engine, registry *core.PluginRegistry) (ret *OCRHandler) {
+	ret = &OCRHandler{registry: registry}
+	r.POST("/ocr", ret.Recognize)
+	return
+}
+
+// Recognize handles POST /ocr, a multipart form with the image "file" and the optional Tesseract "languages"
+func (h *OCRHandler) Recognize(c *gin.Context) {
+	fileHeader, err := c.FormFile("file")
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "an image file is required"})
+		return
+	}
+	if fileHeader.Size > maxOCRImageSize {
+		c.JSON(http.StatusRequestEntityTooLarge, gin.H{"error": "the image is too large"})
+		return
+	}
+
+	file, err := fileHeader.Open()
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	defer file.Close()
+
+	var image []byte
+	if image, err = io.ReadAll(file); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	var text string
+	if text, err = h.registry.Tesseract.Recognize(c.Request.Context(), image, c.PostForm("languages")); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, OCRResponse{Text: text})
+}
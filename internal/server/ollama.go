@@ -87,7 +87,7 @@ func ServeOllama(registry *core.PluginRegistry, address string, version string)
 
 	// Register routes
 	fabricDb := registry.Db
-	NewPatternsHandler(r, fabricDb.Patterns)
+	NewPatternsHandler(r, fabricDb.Patterns, fabricDb.Trash, HostAccessMiddleware(address, ""))
 	NewContextsHandler(r, fabricDb.Contexts)
 	NewSessionsHandler(r, fabricDb.Sessions)
 	NewChatHandler(r, registry, fabricDb)
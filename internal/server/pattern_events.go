@@ -0,0 +1,111 @@
+package restapi
+
+import (
+	"io"
+	"log"
+	"sync"
+	"time"
+
+	"github.com/danielmiessler/fabric/internal/plugins/db/fsdb"
+	"github.com/gin-gonic/gin"
+)
+
+// patternEventsInterval is how often pattern files are checked for changes while clients listen
+const patternEventsInterval = 2 * time.Second
+
+// patternWatcher polls ReloadChanged while clients are subscribed and fans the changes out to all of them,
+// as ReloadChanged reports every change only once
+type patternWatcher struct {
+	patterns *fsdb.PatternsEntity
+	interval time.Duration
+
+	mu          sync.Mutex
+	subscribers map[chan []fsdb.PatternChange]bool
+	stop        chan struct{}
+}
+
+func newPatternWatcher(patterns *fsdb.PatternsEntity) *patternWatcher {
+	return &patternWatcher{
+		patterns:    patterns,
+		interval:    patternEventsInterval,
+		subscribers: map[chan []fsdb.PatternChange]bool{},
+	}
+}
+
+// subscribe starts polling with the first subscriber
+func (o *patternWatcher) subscribe() chan []fsdb.PatternChange {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+
+	changes := make(chan []fsdb.PatternChange, 8)
+	if len(o.subscribers) == 0 {
+		o.stop = make(chan struct{})
+		go o.run(o.stop)
+	}
+	o.subscribers[changes] = true
+	return changes
+}
+
+// unsubscribe stops polling with the last subscriber
+func (o *patternWatcher) unsubscribe(changes chan []fsdb.PatternChange) {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+
+	delete(o.subscribers, changes)
+	if len(o.subscribers) == 0 {
+		close(o.stop)
+	}
+}
+
+func (o *patternWatcher) run(stop chan struct{}) {
+	ticker := time.NewTicker(o.interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-stop:
+			return
+		case <-ticker.C:
+		}
+
+		changes, err := o.patterns.ReloadChanged()
+		if err != nil {
+			log.Printf("Error reloading patterns: %v", err)
+			continue
+		}
+		if len(changes) == 0 {
+			continue
+		}
+
+		o.mu.Lock()
+		select {
+		case <-stop:
+			// the subscribers belong to a newer run
+		default:
+			for subscriber := range o.subscribers {
+				select {
+				case subscriber <- changes:
+				default:
+					// a stalled client misses the changes rather than blocking the others
+				}
+			}
+		}
+		o.mu.Unlock()
+	}
+}
+
+// Events handles the GET /patterns/events route - streams pattern changes as server-sent "patterns" events
+func (h *PatternsHandler) Events(c *gin.Context) {
+	changes := h.watcher.subscribe()
+	defer h.watcher.unsubscribe(changes)
+
+	c.Stream(func(w io.Writer) bool {
+		select {
+		case batch := <-changes:
+			c.SSEvent("patterns", batch)
+			return true
+		case <-c.Request.Context().Done():
+			return false
+		}
+	})
+}
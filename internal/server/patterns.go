@@ -1,7 +1,13 @@
 package restapi
 
 import (
+	"fmt"
+	"io"
+	"log"
 	"net/http"
+	"os/exec"
+	"path/filepath"
+	"runtime"
 
 	"github.com/danielmiessler/fabric/internal/plugins/db/fsdb"
 	"github.com/gin-gonic/gin"
@@ -11,23 +17,34 @@ import (
 type PatternsHandler struct {
 	*StorageHandler[fsdb.Pattern]
 	patterns *fsdb.PatternsEntity
+	trash    *fsdb.TrashEntity
+	watcher  *patternWatcher
 }
 
-// NewPatternsHandler creates a new PatternsHandler
-func NewPatternsHandler(r *gin.Engine, patterns *fsdb.PatternsEntity) (ret *PatternsHandler) {
+// NewPatternsHandler creates a new PatternsHandler, hostAccess guards the routes opening files on the host
+func NewPatternsHandler(r *gin.Engine, patterns *fsdb.PatternsEntity, trash *fsdb.TrashEntity, hostAccess gin.HandlerFunc) (ret *PatternsHandler) {
 	// Create a storage handler but don't register any routes yet
 	storageHandler := &StorageHandler[fsdb.Pattern]{storage: patterns}
-	ret = &PatternsHandler{StorageHandler: storageHandler, patterns: patterns}
+	ret = &PatternsHandler{StorageHandler: storageHandler, patterns: patterns, trash: trash, watcher: newPatternWatcher(patterns)}
 
 	// Register routes manually - use custom Get for patterns, others from StorageHandler
 	r.GET("/patterns/:name", ret.Get)                       // Custom method with variables support
 	r.GET("/patterns/names", ret.GetNames)                  // From StorageHandler
-	r.DELETE("/patterns/:name", ret.Delete)                 // From StorageHandler
+	r.GET("/patterns/all", ret.GetAll)                      // All patterns with their content in one request
+	r.GET("/patterns/events", ret.Events)                   // Changed patterns as server-sent events
+	r.GET("/patterns/metadata", ret.GetMetadata)            // Derived descriptions and tags, cached
+	r.DELETE("/patterns/:name", ret.Delete)                 // Moves the pattern to the trash
 	r.GET("/patterns/exists/:name", ret.Exists)             // From StorageHandler
 	r.PUT("/patterns/rename/:oldName/:newName", ret.Rename) // From StorageHandler
 	r.POST("/patterns/:name", ret.Save)                     // From StorageHandler
 	// Add POST route for patterns with variables in request body
 	r.POST("/patterns/:name/apply", ret.ApplyPattern)
+	r.POST("/patterns/:name/reveal", hostAccess, ret.Reveal) // Opens the pattern directory in the file manager
+	r.GET("/patterns/:name/attachments", ret.GetAttachments)
+	r.GET("/patterns/:name/attachment", ret.GetAttachment)                    // The content of the attachment ?file=
+	r.POST("/patterns/:name/attachment/open", hostAccess, ret.OpenAttachment) // Opens the attachment ?file= with the default app
+	r.GET("/patterns/:name/user", ret.GetUserPrompt)                          // The user.md of the pattern, empty when it has none
+	r.PUT("/patterns/:name/user", ret.SaveUserPrompt)                         // The body is the raw user prompt, empty removes it
 	return
 }
 
@@ -51,6 +68,157 @@ func (h *PatternsHandler) Get(c *gin.Context) {
 	c.JSON(http.StatusOK, pattern)
 }
 
+// Delete handles the DELETE /patterns/:name route - moves the pattern to the trash, it can be restored until
+// it is purged
+func (h *PatternsHandler) Delete(c *gin.Context) {
+	item, err := h.trash.TrashPattern(c.Param("name"))
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, item)
+}
+
+// Reveal handles the POST /patterns/:name/reveal route - opens the pattern directory in the file manager of the host
+func (h *PatternsHandler) Reveal(c *gin.Context) {
+	name := c.Param("name")
+	if name != filepath.Base(name) || !h.patterns.Exists(name) {
+		c.JSON(http.StatusNotFound, gin.H{"error": fmt.Sprintf("pattern %s not found", name)})
+		return
+	}
+
+	dir := h.patterns.BuildFilePathByName(name)
+	if err := openFileManager(dir); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": fmt.Sprintf("could not open %s: %v", dir, err)})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"path": dir})
+}
+
+// GetUserPrompt handles the GET /patterns/:name/user route - returns the user prompt of the pattern
+func (h *PatternsHandler) GetUserPrompt(c *gin.Context) {
+	content, err := h.patterns.GetUserPrompt(c.Param("name"))
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"content": content})
+}
+
+// SaveUserPrompt handles the PUT /patterns/:name/user route - writes the user prompt next to the system prompt
+func (h *PatternsHandler) SaveUserPrompt(c *gin.Context) {
+	content, err := io.ReadAll(c.Request.Body)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if err = h.patterns.SaveUserPrompt(c.Param("name"), content); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	c.Status(http.StatusOK)
+}
+
+// GetAttachments handles the GET /patterns/:name/attachments route - lists the extra files of the pattern directory
+func (h *PatternsHandler) GetAttachments(c *gin.Context) {
+	name := c.Param("name")
+	if name != filepath.Base(name) {
+		c.JSON(http.StatusNotFound, gin.H{"error": fmt.Sprintf("pattern %s not found", name)})
+		return
+	}
+	attachments, err := h.patterns.GetAttachments(name)
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, attachments)
+}
+
+// GetAttachment handles the GET /patterns/:name/attachment?file= route - serves the attachment for the previews
+func (h *PatternsHandler) GetAttachment(c *gin.Context) {
+	path, ok := h.attachmentPath(c)
+	if !ok {
+		return
+	}
+	c.File(path)
+}
+
+// OpenAttachment handles the POST /patterns/:name/attachment/open?file= route - opens the attachment with the
+// default app of the host
+func (h *PatternsHandler) OpenAttachment(c *gin.Context) {
+	path, ok := h.attachmentPath(c)
+	if !ok {
+		return
+	}
+	if err := openFileManager(path); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": fmt.Sprintf("could not open %s: %v", path, err)})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"path": path})
+}
+
+// attachmentPath resolves the attachment of the request, responding with the error when it is invalid
+func (h *PatternsHandler) attachmentPath(c *gin.Context) (path string, ok bool) {
+	name := c.Param("name")
+	if name != filepath.Base(name) {
+		c.JSON(http.StatusNotFound, gin.H{"error": fmt.Sprintf("pattern %s not found", name)})
+		return
+	}
+	var err error
+	if path, err = h.patterns.AttachmentPath(name, c.Query("file")); err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		return
+	}
+	ok = true
+	return
+}
+
+// openFileManager opens dir with the file manager of the platform, a file with its default app
+func openFileManager(dir string) (err error) {
+	var cmd *exec.Cmd
+	switch runtime.GOOS {
+	case "darwin":
+		cmd = exec.Command("open", dir)
+	case "windows":
+		cmd = exec.Command("explorer", dir)
+	default:
+		cmd = exec.Command("xdg-open", dir)
+	}
+	if err = cmd.Start(); err != nil {
+		return
+	}
+	go cmd.Wait()
+	return
+}
+
+// PatternsLoadResponse is the response of GET /patterns/all
+type PatternsLoadResponse struct {
+	Patterns []*fsdb.Pattern       `json:"patterns"`
+	Stats    fsdb.PatternLoadStats `json:"stats"`
+}
+
+// GetAll handles the GET /patterns/all route - loads all patterns concurrently
+func (h *PatternsHandler) GetAll(c *gin.Context) {
+	patterns, stats, err := h.patterns.LoadAll()
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	log.Printf("Loaded %d patterns (%d failed, %d bytes) in %dms with %d workers, %.0f patterns/s",
+		stats.Patterns, stats.Failed, stats.Bytes, stats.DurationMs, stats.Workers, stats.PerSecond)
+	c.JSON(http.StatusOK, PatternsLoadResponse{Patterns: patterns, Stats: stats})
+}
+
+// GetMetadata handles the GET /patterns/metadata route
+func (h *PatternsHandler) GetMetadata(c *gin.Context) {
+	metadata, err := h.patterns.LoadMetadata()
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, metadata)
+}
+
 // PatternApplyRequest represents the request body for applying a pattern
 type PatternApplyRequest struct {
 	Input     string            `json:"input"`
@@ -0,0 +1,39 @@
+package restapi
+
+import (
+	"net/http"
+
+	"github.com/danielmiessler/fabric/internal/tools/precheck"
+	"github.com/gin-gonic/gin"
+)
+
+type PrecheckRequest struct {
+	Text    string `json:"text"`
+	Pattern string `json:"pattern"`
+}
+
+type PrecheckResponse struct {
+	Writing      bool             `json:"writing"` // the text is only checked for writing patterns
+	Issues       []precheck.Issue `json:"issues"`
+	Instructions string           `json:"instructions"` // to append to the input so the model fixes the issues
+}
+
+// NewPrecheckHandler registers the /precheck POST endpoint, checking the input of writing patterns for typos
+func NewPrecheckHandler(r *gin.Engine) {
+	r.POST("/precheck", func(c *gin.Context) {
+		var request PrecheckRequest
+		if err := c.BindJSON(&request); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "invalid request"})
+			return
+		}
+
+		response := PrecheckResponse{Issues: []precheck.Issue{}}
+		if response.Writing = precheck.IsWritingPattern(request.Pattern); response.Writing {
+			if issues := precheck.Check(request.Text); len(issues) > 0 {
+				response.Issues = issues
+				response.Instructions = precheck.Instructions(issues)
+			}
+		}
+		c.JSON(http.StatusOK, response)
+	})
+}
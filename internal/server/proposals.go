@@ -0,0 +1,162 @@
+package restapi
+
+import (
+	"fmt"
+	"net/http"
+	"os"
+
+	"github.com/danielmiessler/fabric/internal/core"
+	"github.com/danielmiessler/fabric/internal/plugins/db/fsdb"
+	"github.com/danielmiessler/fabric/internal/tools/notifications"
+	"github.com/gin-gonic/gin"
+)
+
+// ProposeRequest is posted by other tools to propose a run, it waits in the queue until approved in the GUI
+type ProposeRequest struct {
+	Source string `json:"source"` // name of the proposing tool, shown for the approval
+	Reason string `json:"reason"`
+
+	PatternName  string            `json:"patternName"`
+	Model        string            `json:"model"`
+	Vendor       string            `json:"vendor"`
+	ContextName  string            `json:"contextName"`
+	StrategyName string            `json:"strategyName"`
+	Variables    map[string]string `json:"variables,omitempty"`
+	Language     string            `json:"language"`
+
+	// Input source, exactly one of them: URL is scraped, File is read, Input is sent as is
+	InputURL  string `json:"inputUrl"`
+	InputFile string `json:"inputFile"`
+	Input     string `json:"input"`
+}
+
+// ProposalsHandler queues the runs proposed by other tools for approval
+type ProposalsHandler struct {
+	proposals *fsdb.ProposalsEntity
+	patterns  *fsdb.PatternsEntity
+	jobs      *fsdb.JobsEntity
+	scheduler *core.Scheduler
+}
+
+func NewProposalsHandler(r *gin.Engine, db *fsdb.Db, scheduler *core.Scheduler) (ret *ProposalsHandler) {
+	ret = &ProposalsHandler{proposals: db.Proposals, patterns: db.Patterns, jobs: db.Jobs, scheduler: scheduler}
+	r.POST("/proposals", ret.Propose)
+	r.GET("/proposals", ret.GetProposals)
+	r.POST("/proposals/:id/approve", ret.Approve)
+	r.DELETE("/proposals/:id", ret.Reject)
+	return
+}
+
+// Propose handles the POST /proposals route
+func (h *ProposalsHandler) Propose(c *gin.Context) {
+	var req ProposeRequest
+	if err := c.BindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid request"})
+		return
+	}
+
+	if req.PatternName == "" || !h.patterns.Exists(req.PatternName) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": fmt.Sprintf("pattern %q not found", req.PatternName)})
+		return
+	}
+	inputs := 0
+	for _, input := range []string{req.InputURL, req.InputFile, req.Input} {
+		if input != "" {
+			inputs++
+		}
+	}
+	if inputs != 1 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "set exactly one of input, inputUrl and inputFile"})
+		return
+	}
+	if req.InputFile != "" {
+		if _, err := h.jobs.InputPath(req.InputFile); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+	}
+
+	proposal := &fsdb.Proposal{
+		Source: req.Source,
+		Reason: req.Reason,
+		Run: fsdb.Job{
+			PatternName:  req.PatternName,
+			Model:        req.Model,
+			Vendor:       req.Vendor,
+			ContextName:  req.ContextName,
+			StrategyName: req.StrategyName,
+			Variables:    req.Variables,
+			Language:     req.Language,
+			InputURL:     req.InputURL,
+			InputFile:    req.InputFile,
+			Input:        req.Input,
+		},
+	}
+	if err := h.proposals.Add(proposal); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	notifyProposal(proposal)
+	c.JSON(http.StatusCreated, proposal)
+}
+
+// GetProposals handles the GET /proposals route, the proposals waiting for approval
+func (h *ProposalsHandler) GetProposals(c *gin.Context) {
+	proposals, err := h.proposals.GetProposals()
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	if proposals == nil {
+		proposals = []*fsdb.Proposal{}
+	}
+	c.JSON(http.StatusOK, proposals)
+}
+
+// Approve handles the POST /proposals/:id/approve route, running the proposal in the background.
+// The result is recorded in the run history like the results of jobs.
+func (h *ProposalsHandler) Approve(c *gin.Context) {
+	proposal, err := h.proposals.Take(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		return
+	}
+
+	job := proposal.Run
+	job.Name = "proposal-" + proposal.Id
+	if err = h.scheduler.StartJob(&job, func(runErr error) {
+		if runErr != nil {
+			fmt.Fprintf(os.Stderr, "Proposal %s failed: %v\n", proposal.Id, runErr)
+		}
+	}); err != nil {
+		c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusAccepted, gin.H{"jobName": job.Name})
+}
+
+// Reject handles the DELETE /proposals/:id route
+func (h *ProposalsHandler) Reject(c *gin.Context) {
+	if _, err := h.proposals.Take(c.Param("id")); err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		return
+	}
+	c.Status(http.StatusNoContent)
+}
+
+// notifyProposal tells the user a run waits for approval, when desktop notifications are available
+func notifyProposal(proposal *fsdb.Proposal) {
+	manager := notifications.NewNotificationManager()
+	if !manager.IsAvailable() {
+		return
+	}
+	source := proposal.Source
+	if source == "" {
+		source = "A tool"
+	}
+	message := fmt.Sprintf("%s proposes running %s, approve it in the History page", source, proposal.Run.PatternName)
+	if err := manager.Send("Fabric run proposed", message); err != nil {
+		fmt.Fprintf(os.Stderr, "Warning: failed to send notification: %v\n", err)
+	}
+}
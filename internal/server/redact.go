@@ -0,0 +1,42 @@
+package restapi
+
+import (
+	"net/http"
+
+	"github.com/danielmiessler/fabric/internal/tools/redact"
+	"github.com/gin-gonic/gin"
+)
+
+type RedactRequest struct {
+	Text     string   `json:"text"`
+	Rules    []string `json:"rules"`    // built-in rules, all when missing
+	Patterns []string `json:"patterns"` // custom regexes
+}
+
+type RedactResponse struct {
+	Text    string         `json:"text"` // with the secrets replaced by placeholders
+	Matches []redact.Match `json:"matches"`
+}
+
+// NewRedactHandler registers the /redact POST endpoint, scrubbing secrets from the input before it is sent to a vendor
+func NewRedactHandler(r *gin.Engine) {
+	r.POST("/redact", func(c *gin.Context) {
+		var request RedactRequest
+		if err := c.BindJSON(&request); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "invalid request"})
+			return
+		}
+		redactor, err := redact.New(request.Rules, request.Patterns)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+
+		response := RedactResponse{Matches: []redact.Match{}}
+		var matches []redact.Match
+		if response.Text, matches = redactor.Redact(request.Text); len(matches) > 0 {
+			response.Matches = matches
+		}
+		c.JSON(http.StatusOK, response)
+	})
+}
@@ -1,9 +1,18 @@
 package restapi
 
 import (
+	"context"
+	"errors"
 	"log/slog"
+	"net"
+	"net/http"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
 
 	"github.com/danielmiessler/fabric/internal/core"
+	"github.com/danielmiessler/fabric/internal/plugins/ai"
 	"github.com/gin-gonic/gin"
 )
 
@@ -13,6 +22,8 @@ func Serve(registry *core.PluginRegistry, address string, apiKey string) (err er
 	// Middleware
 	r.Use(gin.Logger())
 	r.Use(gin.Recovery())
+	idle := &IdleTracker{}
+	r.Use(idle.Middleware())
 
 	if apiKey != "" {
 		r.Use(APIKeyMiddleware(apiKey))
@@ -20,22 +31,104 @@ func Serve(registry *core.PluginRegistry, address string, apiKey string) (err er
 		slog.Warn("Starting REST API server without API key authentication. This may pose security risks.")
 	}
 
+	// cancelled on shutdown, it is the parent of every request context and of the scheduled jobs
+	executions, cancelExecutions := context.WithCancel(context.Background())
+	defer cancelExecutions()
+
 	// Register routes
 	fabricDb := registry.Db
-	NewPatternsHandler(r, fabricDb.Patterns)
+	NewPatternsHandler(r, fabricDb.Patterns, fabricDb.Trash, HostAccessMiddleware(address, apiKey))
 	NewContextsHandler(r, fabricDb.Contexts)
 	NewSessionsHandler(r, fabricDb.Sessions)
 	NewChatHandler(r, registry, fabricDb)
 	NewYouTubeHandler(r, registry)
+	NewCaptureHandler(r, registry)
+	NewHooksHandler(r, registry)
+	NewFeaturesHandler(r, registry)
+	scheduler := core.NewScheduler(registry)
+	if registry.Features().Enabled(core.FeatureScheduler) {
+		go scheduler.Start(executions)
+	} else {
+		slog.Warn("Scheduled jobs are disabled, set scheduler: true in features.yaml to run them")
+	}
+	NewJobsHandler(r, fabricDb.Jobs, scheduler)
+	NewProposalsHandler(r, fabricDb, scheduler)
+	watcher := core.NewWatcher(registry)
+	if registry.Features().Enabled(core.FeatureWatcher) {
+		go watcher.Start(executions, core.DefaultWatchInterval)
+	} else {
+		slog.Warn("Watched folders are disabled, set watcher: true in features.yaml to watch them")
+	}
+	NewWatchesHandler(r, fabricDb.Watches, watcher)
+	NewHistoryHandler(r, registry, scheduler)
+	NewBackupHandler(r, fabricDb)
 	NewConfigHandler(r, fabricDb)
 	NewModelsHandler(r, registry.VendorManager)
 	NewStrategiesHandler(r)
+	NewExportHandler(r, fabricDb, registry.Version)
+	NewTranscribeHandler(r, registry)
+	NewOCRHandler(r, registry)
+	NewSpreadsheetHandler(r)
+	NewEmailHandler(r)
+	NewCodeHandler(r)
+	NewPrecheckHandler(r)
+	NewRedactHandler(r)
+	NewTokensHandler(r, registry)
+	NewFeedHandler(r)
+	NewStarredHandler(r, fabricDb.Starred)
+	NewTrashHandler(r, fabricDb.Trash)
+	NewVariablesHandler(r, fabricDb.Variables)
+	NewShareHandler(r)
+	NewMermaidHandler(r)
 
-	// Start server
-	err = r.Run(address)
-	if err != nil {
-		return err
+	// the model lists are ready when the GUI asks for them
+	go registry.VendorManager.PrefetchModels(executions, ai.DefaultPrefetchInterval, ai.DefaultPrefetchVendorDelay, idle.Idle)
+
+	server := &http.Server{
+		Addr:        address,
+		Handler:     r,
+		BaseContext: func(net.Listener) context.Context { return executions },
 	}
 
+	stop, stopNotify := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stopNotify()
+
+	serveErr := make(chan error, 1)
+	go func() {
+		slog.Info("Starting REST API server", "address", address)
+		serveErr <- server.ListenAndServe()
+	}()
+
+	select {
+	case err = <-serveErr:
+		if errors.Is(err, http.ErrServerClosed) {
+			err = nil
+		}
+		return
+	case <-stop.Done():
+	}
+
+	err = shutdown(server, scheduler, watcher, registry, cancelExecutions)
+	return
+}
+
+// shutdown cancels the running executions, waits for their handlers, jobs and watched files to return
+// and closes the database so no run is left half written
+func shutdown(server *http.Server, scheduler *core.Scheduler, watcher *core.Watcher, registry *core.PluginRegistry, cancelExecutions context.CancelFunc) (err error) {
+	slog.Info("Shutting down REST API server")
+	cancelExecutions()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	if err = server.Shutdown(ctx); err != nil {
+		slog.Warn("Forcing REST API server to close", "error", err)
+		err = server.Close()
+	}
+	scheduler.Wait()
+	watcher.Wait()
+
+	if closeErr := registry.Db.Store.Close(); closeErr != nil && err == nil {
+		err = closeErr
+	}
 	return
 }
@@ -1,6 +1,9 @@
 package restapi
 
 import (
+	"net/http"
+
+	"github.com/danielmiessler/fabric/internal/chat"
 	"github.com/danielmiessler/fabric/internal/plugins/db/fsdb"
 	"github.com/gin-gonic/gin"
 )
@@ -15,5 +18,34 @@ type SessionsHandler struct {
 func NewSessionsHandler(r *gin.Engine, sessions *fsdb.SessionsEntity) (ret *SessionsHandler) {
 	ret = &SessionsHandler{
 		StorageHandler: NewStorageHandler(r, "sessions", sessions), sessions: sessions}
+	r.POST("/sessions/:name/messages", ret.AppendMessages)
 	return ret
 }
+
+// AppendMessagesRequest carries the messages to add to a session, e.g. the input and output
+// of a previous run that should be continued as a chat
+type AppendMessagesRequest struct {
+	Messages []*chat.ChatCompletionMessage `json:"messages"`
+}
+
+// AppendMessages handles the POST /sessions/:name/messages route, creating the session if missing
+func (h *SessionsHandler) AppendMessages(c *gin.Context) {
+	var req AppendMessagesRequest
+	if err := c.ShouldBindJSON(&req); err != nil || len(req.Messages) == 0 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "messages are required"})
+		return
+	}
+
+	session, err := h.sessions.Get(c.Param("name"))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	session.Append(req.Messages...)
+
+	if err = h.sessions.SaveSession(session); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, session.Messages)
+}
@@ -0,0 +1,62 @@
+package restapi
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/danielmiessler/fabric/internal/chat"
+	"github.com/danielmiessler/fabric/internal/plugins/db/fsdb"
+	"github.com/gin-gonic/gin"
+)
+
+func TestSessionsHandler_AppendMessages(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	sessions := &fsdb.SessionsEntity{
+		StorageEntity: &fsdb.StorageEntity{Label: "Sessions", Dir: t.TempDir(), FileExtension: ".json"}}
+	existing := &fsdb.Session{Name: "work"}
+	existing.Append(&chat.ChatCompletionMessage{Role: chat.ChatMessageRoleUser, Content: "summarize this"},
+		&chat.ChatCompletionMessage{Role: chat.ChatMessageRoleAssistant, Content: "the summary"})
+	if err := sessions.SaveSession(existing); err != nil {
+		t.Fatalf("failed to save the session: %v", err)
+	}
+
+	r := gin.New()
+	NewSessionsHandler(r, sessions)
+	post := func(body string) *httptest.ResponseRecorder {
+		recorder := httptest.NewRecorder()
+		r.ServeHTTP(recorder, httptest.NewRequest(http.MethodPost, "/sessions/work/messages", strings.NewReader(body)))
+		return recorder
+	}
+
+	recorder := post(`{"messages":[{"role":"user","content":"shorter"},{"role":"assistant","content":"the short summary"}]}`)
+	if recorder.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d: %s", recorder.Code, recorder.Body)
+	}
+	var messages []*chat.ChatCompletionMessage
+	if err := json.Unmarshal(recorder.Body.Bytes(), &messages); err != nil {
+		t.Fatalf("invalid response: %v", err)
+	}
+
+	saved, err := sessions.Get("work")
+	if err != nil {
+		t.Fatalf("failed to load the session: %v", err)
+	}
+	expected := []string{"summarize this", "the summary", "shorter", "the short summary"}
+	if len(saved.Messages) != len(expected) || len(messages) != len(expected) {
+		t.Fatalf("expected the messages to be appended, got %d saved and %d returned", len(saved.Messages), len(messages))
+	}
+	for i, content := range expected {
+		if saved.Messages[i].Content != content {
+			t.Errorf("message %d: expected %q, got %q", i, content, saved.Messages[i].Content)
+		}
+	}
+
+	for _, body := range []string{`{"messages":[]}`, `{`} {
+		if recorder = post(body); recorder.Code != http.StatusBadRequest {
+			t.Errorf("expected 400 for %s, got %d", body, recorder.Code)
+		}
+	}
+}
@@ -0,0 +1,82 @@
+package restapi
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"os"
+	"time"
+
+	"github.com/danielmiessler/fabric/internal/tools/share"
+	"github.com/gin-gonic/gin"
+)
+
+// ShareRequest is a result to share with teammates
+type ShareRequest struct {
+	Input    string          `json:"input"`
+	Output   string          `json:"output"`
+	Pattern  string          `json:"pattern"`
+	Model    string          `json:"model"`
+	Metadata json.RawMessage `json:"metadata"` // the execution metadata of the run
+	// Token is the GitHub token creating the gist, GITHUB_TOKEN of the environment when not set
+	Token string `json:"token"`
+}
+
+// ShareHandler shares the results as zip bundles and private gists
+type ShareHandler struct {
+	gists *share.GistClient
+}
+
+func NewShareHandler(r *gin.Engine) (ret *ShareHandler) {
+	ret = &ShareHandler{gists: share.NewGistClient()}
+	r.POST("/share/zip", ret.Zip)
+	r.POST("/share/gist", ret.Gist)
+	return
+}
+
+// Zip handles POST /share/zip, returning the zip bundle of the input, the output and the metadata
+func (h *ShareHandler) Zip(c *gin.Context) {
+	var request ShareRequest
+	bundle, ok := bindBundle(c, &request)
+	if !ok {
+		return
+	}
+	var buffer bytes.Buffer
+	if err := bundle.Zip(&buffer); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	c.Header("Content-Disposition", `attachment; filename="`+bundle.Name()+`.zip"`)
+	c.Data(http.StatusOK, "application/zip", buffer.Bytes())
+}
+
+// Gist handles POST /share/gist, creating a private gist of the bundle and returning its URL
+func (h *ShareHandler) Gist(c *gin.Context) {
+	var request ShareRequest
+	bundle, ok := bindBundle(c, &request)
+	if !ok {
+		return
+	}
+	token := request.Token
+	if token == "" {
+		token = os.Getenv("GITHUB_TOKEN")
+	}
+	url, err := h.gists.CreateGist(token, bundle)
+	if err != nil {
+		c.JSON(http.StatusBadGateway, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"url": url})
+}
+
+// bindBundle reads the request and its bundle, responding to the invalid ones
+func bindBundle(c *gin.Context, request *ShareRequest) (ret *share.Bundle, ok bool) {
+	if err := c.BindJSON(request); err != nil || request.Output == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid request, an output is required"})
+		return
+	}
+	ret = &share.Bundle{Pattern: request.Pattern, Model: request.Model, Input: request.Input, Output: request.Output,
+		Metadata: request.Metadata, Created: time.Now()}
+	ok = true
+	return
+}
@@ -0,0 +1,63 @@
+package restapi
+
+import (
+	"io"
+	"net/http"
+
+	"github.com/danielmiessler/fabric/internal/tools/spreadsheet"
+	"github.com/gin-gonic/gin"
+)
+
+// maxSpreadsheetSize limits the uploaded spreadsheets
+const maxSpreadsheetSize = 20 << 20
+
+// SpreadsheetHandler parses uploaded CSV and XLSX files, so the client can pick the columns and rows to send
+type SpreadsheetHandler struct{}
+
+// SpreadsheetResponse is the response of POST /spreadsheet
+type SpreadsheetResponse struct {
+	Sheets []*spreadsheet.Sheet `json:"sheets"`
+}
+
+func NewSpreadsheetHandler(r *gin.Engine) (ret *SpreadsheetHandler) {
+	ret = &SpreadsheetHandler{}
+	r.POST("/spreadsheet", ret.Parse)
+	return
+}
+
+// Parse handles POST /spreadsheet, a multipart form with the CSV, TSV or XLSX "file"
+func (h *SpreadsheetHandler) Parse(c *gin.Context) {
+	fileHeader, err := c.FormFile("file")
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "a spreadsheet file is required"})
+		return
+	}
+	if !spreadsheet.IsSpreadsheet(fileHeader.Filename) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "only CSV, TSV and XLSX files are supported"})
+		return
+	}
+	if fileHeader.Size > maxSpreadsheetSize {
+		c.JSON(http.StatusRequestEntityTooLarge, gin.H{"error": "the spreadsheet is too large"})
+		return
+	}
+
+	file, err := fileHeader.Open()
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	defer file.Close()
+
+	var data []byte
+	if data, err = io.ReadAll(file); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	var sheets []*spreadsheet.Sheet
+	if sheets, err = spreadsheet.Read(fileHeader.Filename, data); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, SpreadsheetResponse{Sheets: sheets})
+}
@@ -0,0 +1,138 @@
+package restapi
+
+import (
+	"bytes"
+	"fmt"
+	"net/http"
+	"regexp"
+
+	"github.com/danielmiessler/fabric/internal/plugins/db/fsdb"
+	"github.com/gin-gonic/gin"
+)
+
+type StarRequest struct {
+	Annotation string `json:"annotation"`
+}
+
+type RenameStarredRequest struct {
+	Title string `json:"title"`
+}
+
+// ExportPackRequest selects the starred outputs of a knowledge pack, all of them without run ids
+type ExportPackRequest struct {
+	Name        string   `json:"name"`
+	Author      string   `json:"author"`
+	Description string   `json:"description"`
+	RunIds      []string `json:"runIds"`
+}
+
+var unsafeFileNameChars = regexp.MustCompile(`[^A-Za-z0-9._-]+`)
+
+// StarredHandler serves the starred outputs and their knowledge packs
+type StarredHandler struct {
+	starred *fsdb.StarredEntity
+}
+
+func NewStarredHandler(r *gin.Engine, starred *fsdb.StarredEntity) *StarredHandler {
+	handler := &StarredHandler{starred: starred}
+	r.GET("/starred", handler.List)
+	r.PUT("/starred/:id", handler.Star)
+	r.PUT("/starred/:id/title", handler.Rename)
+	r.DELETE("/starred/:id", handler.Unstar)
+	r.POST("/starred/export", handler.Export)
+	r.POST("/starred/import", handler.Import)
+	return handler
+}
+
+// List handles the GET /starred route, the most recently starred first
+func (h *StarredHandler) List(c *gin.Context) {
+	list, err := h.starred.List()
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	if list == nil {
+		list = []*fsdb.StarredOutput{}
+	}
+	c.JSON(http.StatusOK, list)
+}
+
+// Star handles the PUT /starred/:id route, starring the run of the history or updating its annotation
+func (h *StarredHandler) Star(c *gin.Context) {
+	var request StarRequest
+	if err := c.ShouldBindJSON(&request); err != nil && c.Request.ContentLength > 0 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid request"})
+		return
+	}
+	starred, err := h.starred.Star(c.Param("id"), request.Annotation)
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, starred)
+}
+
+// Rename handles the PUT /starred/:id/title route, an empty title removes it
+func (h *StarredHandler) Rename(c *gin.Context) {
+	var request RenameStarredRequest
+	if err := c.ShouldBindJSON(&request); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid request"})
+		return
+	}
+	starred, err := h.starred.Rename(c.Param("id"), request.Title)
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, starred)
+}
+
+// Unstar handles the DELETE /starred/:id route
+func (h *StarredHandler) Unstar(c *gin.Context) {
+	if err := h.starred.Unstar(c.Param("id")); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"unstarred": c.Param("id")})
+}
+
+// Export handles the POST /starred/export route, downloading the knowledge pack archive
+func (h *StarredHandler) Export(c *gin.Context) {
+	var request ExportPackRequest
+	if err := c.BindJSON(&request); err != nil || request.Name == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "pack name is required"})
+		return
+	}
+
+	var archive bytes.Buffer
+	pack := fsdb.KnowledgePack{Name: request.Name, Author: request.Author, Description: request.Description}
+	if err := h.starred.ExportPack(&archive, pack, request.RunIds); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	fileName := unsafeFileNameChars.ReplaceAllString(request.Name, "-") + fsdb.KnowledgePackExtension
+	c.Header("Content-Disposition", fmt.Sprintf("attachment; filename=%q", fileName))
+	c.Data(http.StatusOK, "application/gzip", archive.Bytes())
+}
+
+// Import handles the POST /starred/import route, a multipart form with the knowledge pack "file"
+func (h *StarredHandler) Import(c *gin.Context) {
+	fileHeader, err := c.FormFile("file")
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "a knowledge pack file is required"})
+		return
+	}
+	file, err := fileHeader.Open()
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	defer file.Close()
+
+	result, err := h.starred.ImportPack(file)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, result)
+}
@@ -1,12 +1,10 @@
 package restapi
 
 import (
-	"encoding/json"
 	"net/http"
-	"os"
-	"path/filepath"
-	"strings"
+	"sort"
 
+	"github.com/danielmiessler/fabric/internal/plugins/strategy"
 	"github.com/gin-gonic/gin"
 )
 
@@ -20,41 +18,23 @@ type StrategyMeta struct {
 // NewStrategiesHandler registers the /strategies GET endpoint
 func NewStrategiesHandler(r *gin.Engine) {
 	r.GET("/strategies", func(c *gin.Context) {
-		strategiesDir := filepath.Join(os.Getenv("HOME"), ".config", "fabric", "strategies")
-
-		files, err := os.ReadDir(strategiesDir)
+		loaded, err := strategy.LoadAllFiles()
 		if err != nil {
 			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to read strategies directory"})
 			return
 		}
 
-		var strategies []StrategyMeta
-
-		for _, file := range files {
-			if file.IsDir() || filepath.Ext(file.Name()) != ".json" {
-				continue
-			}
-
-			fullPath := filepath.Join(strategiesDir, file.Name())
-			data, err := os.ReadFile(fullPath)
-			if err != nil {
-				continue
-			}
-
-			var s struct {
-				Description string `json:"description"`
-				Prompt      string `json:"prompt"`
-			}
-			if err := json.Unmarshal(data, &s); err != nil {
-				continue
-			}
-
+		strategies := make([]StrategyMeta, 0, len(loaded))
+		for name, s := range loaded {
 			strategies = append(strategies, StrategyMeta{
-				Name:        strings.TrimSuffix(file.Name(), ".json"),
+				Name:        name,
 				Description: s.Description,
 				Prompt:      s.Prompt,
 			})
 		}
+		sort.Slice(strategies, func(i, j int) bool {
+			return strategies[i].Name < strategies[j].Name
+		})
 
 		c.JSON(http.StatusOK, strategies)
 	})
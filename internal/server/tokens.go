@@ -0,0 +1,43 @@
+package restapi
+
+import (
+	"net/http"
+
+	"github.com/danielmiessler/fabric/internal/core"
+	"github.com/gin-gonic/gin"
+)
+
+type TokensRequest struct {
+	Model string   `json:"model"`
+	Texts []string `json:"texts"`
+}
+
+type TokensResponse struct {
+	Tokenizer string `json:"tokenizer"` // the command of the model, empty when the counts are to be estimated
+	Counts    []int  `json:"counts,omitempty"`
+}
+
+// NewTokensHandler registers the /tokens/count POST endpoint, counting the tokens of the texts with the
+// tokenizer of the model configured in tokenizers.yaml
+func NewTokensHandler(r *gin.Engine, registry *core.PluginRegistry) {
+	r.POST("/tokens/count", func(c *gin.Context) {
+		var request TokensRequest
+		if err := c.BindJSON(&request); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "invalid request"})
+			return
+		}
+
+		response := TokensResponse{Tokenizer: registry.Tokenizers.Command(request.Model)}
+		if response.Tokenizer != "" {
+			response.Counts = make([]int, len(request.Texts))
+			for i, text := range request.Texts {
+				var err error
+				if response.Counts[i], _, err = registry.Tokenizers.Count(request.Model, text); err != nil {
+					c.JSON(http.StatusBadGateway, gin.H{"error": err.Error()})
+					return
+				}
+			}
+		}
+		c.JSON(http.StatusOK, response)
+	})
+}
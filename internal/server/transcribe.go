@@ -0,0 +1,63 @@
+package restapi
+
+import (
+	"net/http"
+	"os"
+	"path/filepath"
+
+	"github.com/danielmiessler/fabric/internal/core"
+	"github.com/danielmiessler/fabric/internal/plugins/ai"
+	"github.com/gin-gonic/gin"
+)
+
+// TranscribeHandler transcribes uploaded audio files with the speech-to-text vendors
+type TranscribeHandler struct {
+	registry *core.PluginRegistry
+}
+
+// TranscribeResponse is the response of POST /transcribe
+type TranscribeResponse struct {
+	Transcript string `json:"transcript"`
+}
+
+func NewTranscribeHandler(r *gin.Engine, registry *core.PluginRegistry) (ret *TranscribeHandler) {
+	ret = &TranscribeHandler{registry: registry}
+	r.POST("/transcribe", ret.Transcribe)
+	return
+}
+
+// Transcribe handles POST /transcribe, a multipart form with the audio "file" and optional "vendor", "model" and "language"
+func (h *TranscribeHandler) Transcribe(c *gin.Context) {
+	fileHeader, err := c.FormFile("file")
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "an audio file is required"})
+		return
+	}
+
+	var transcriber ai.Transcriber
+	if transcriber, err = h.registry.GetTranscriber(c.PostForm("vendor")); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	dir, err := os.MkdirTemp("", "fabric-audio-")
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	defer os.RemoveAll(dir)
+
+	// keep the extension, the vendors tell the audio format by it
+	audioPath := filepath.Join(dir, "audio"+filepath.Ext(fileHeader.Filename))
+	if err = c.SaveUploadedFile(fileHeader, audioPath); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	var transcript string
+	if transcript, err = transcriber.TranscribeFile(c.Request.Context(), audioPath, c.PostForm("model"), c.PostForm("language")); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, TranscribeResponse{Transcript: transcript})
+}
@@ -0,0 +1,79 @@
+package restapi
+
+import (
+	"net/http"
+
+	"github.com/danielmiessler/fabric/internal/plugins/db/fsdb"
+	"github.com/gin-gonic/gin"
+)
+
+// TrashHandler serves the deleted patterns and runs, see DELETE /patterns/:name and DELETE /history/runs/:id
+type TrashHandler struct {
+	trash *fsdb.TrashEntity
+}
+
+func NewTrashHandler(r *gin.Engine, trash *fsdb.TrashEntity) *TrashHandler {
+	handler := &TrashHandler{trash: trash}
+	r.GET("/trash", handler.List)
+	r.POST("/trash/:id/restore", handler.Restore)
+	r.DELETE("/trash/:id", handler.Purge)
+	r.GET("/trash/settings", handler.GetSettings)
+	r.PUT("/trash/settings", handler.SaveSettings)
+	return handler
+}
+
+// List handles the GET /trash route, the most recently deleted first. The expired items are purged first.
+func (h *TrashHandler) List(c *gin.Context) {
+	list, err := h.trash.List()
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	if list == nil {
+		list = []*fsdb.TrashedItem{}
+	}
+	c.JSON(http.StatusOK, list)
+}
+
+// Restore handles the POST /trash/:id/restore route
+func (h *TrashHandler) Restore(c *gin.Context) {
+	item, err := h.trash.Restore(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, item)
+}
+
+// Purge handles the DELETE /trash/:id route, deleting the item for good
+func (h *TrashHandler) Purge(c *gin.Context) {
+	if err := h.trash.Purge(c.Param("id")); err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"purged": c.Param("id")})
+}
+
+// GetSettings handles the GET /trash/settings route
+func (h *TrashHandler) GetSettings(c *gin.Context) {
+	settings, err := h.trash.GetSettings()
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, settings)
+}
+
+// SaveSettings handles the PUT /trash/settings route
+func (h *TrashHandler) SaveSettings(c *gin.Context) {
+	var settings fsdb.TrashSettings
+	if err := c.BindJSON(&settings); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid request"})
+		return
+	}
+	if err := h.trash.SaveSettings(&settings); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, settings)
+}
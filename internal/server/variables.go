@@ -0,0 +1,59 @@
+package restapi
+
+import (
+	"net/http"
+
+	"github.com/danielmiessler/fabric/internal/plugins/db/fsdb"
+	"github.com/gin-gonic/gin"
+)
+
+// VariablesHandler serves the variables stored across runs, filled in the templates by {{var:name}}
+type VariablesHandler struct {
+	variables *fsdb.VariablesEntity
+}
+
+func NewVariablesHandler(r *gin.Engine, variables *fsdb.VariablesEntity) *VariablesHandler {
+	handler := &VariablesHandler{variables: variables}
+	r.GET("/variables", handler.List)
+	r.PUT("/variables/:name", handler.Set)
+	r.DELETE("/variables/:name", handler.Delete)
+	return handler
+}
+
+// List handles the GET /variables route, by name
+func (h *VariablesHandler) List(c *gin.Context) {
+	list, err := h.variables.List()
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	if list == nil {
+		list = []*fsdb.StoredVariable{}
+	}
+	c.JSON(http.StatusOK, list)
+}
+
+// Set handles the PUT /variables/:name route, with the value in the body
+func (h *VariablesHandler) Set(c *gin.Context) {
+	var body struct {
+		Value string `json:"value"`
+	}
+	if err := c.BindJSON(&body); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid request"})
+		return
+	}
+	if err := h.variables.Set(c.Param("name"), body.Value); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, fsdb.StoredVariable{Name: c.Param("name"), Value: body.Value})
+}
+
+// Delete handles the DELETE /variables/:name route
+func (h *VariablesHandler) Delete(c *gin.Context) {
+	if err := h.variables.Delete(c.Param("name")); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"deleted": c.Param("name")})
+}
@@ -0,0 +1,90 @@
+package restapi
+
+import (
+	"net/http"
+	"os"
+	"path/filepath"
+
+	"github.com/danielmiessler/fabric/internal/core"
+	"github.com/danielmiessler/fabric/internal/plugins/db/fsdb"
+	"github.com/gin-gonic/gin"
+)
+
+// WatchesHandler defines the handler for the watched directories
+type WatchesHandler struct {
+	*StorageHandler[fsdb.Watch]
+	watches *fsdb.WatchesEntity
+	watcher *core.Watcher
+}
+
+// NewWatchesHandler creates a new WatchesHandler
+func NewWatchesHandler(r *gin.Engine, watches *fsdb.WatchesEntity, watcher *core.Watcher) (ret *WatchesHandler) {
+	ret = &WatchesHandler{watches: watches, watcher: watcher}
+	// registered before the generic storage routes so the watch is validated before it is saved
+	r.POST("/watches/:name", ret.SaveWatch)
+	r.GET("/watches", ret.GetWatches)
+	r.GET("/watches/activity", ret.GetActivity)
+	ret.StorageHandler = &StorageHandler[fsdb.Watch]{storage: watches}
+	r.GET("/watches/:name", ret.Get)
+	r.GET("/watches/names", ret.GetNames)
+	r.DELETE("/watches/:name", ret.Delete)
+	r.GET("/watches/exists/:name", ret.Exists)
+	r.PUT("/watches/rename/:oldName/:newName", ret.Rename)
+	return
+}
+
+// GetWatches handles the GET /watches route
+func (h *WatchesHandler) GetWatches(c *gin.Context) {
+	watches, err := h.watches.GetWatches()
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	if watches == nil {
+		watches = []*fsdb.Watch{}
+	}
+	c.JSON(http.StatusOK, watches)
+}
+
+// GetActivity handles the GET /watches/activity route, the latest processed files, of one watch with ?watch=
+func (h *WatchesHandler) GetActivity(c *gin.Context) {
+	c.JSON(http.StatusOK, h.watcher.Activity(c.Query("watch")))
+}
+
+// SaveWatch handles the POST /watches/:name route
+func (h *WatchesHandler) SaveWatch(c *gin.Context) {
+	var watch fsdb.Watch
+	if err := c.BindJSON(&watch); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid request"})
+		return
+	}
+	watch.Name = c.Param("name")
+
+	if watch.PatternName == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "patternName is required"})
+		return
+	}
+	if watch.Directory == "" || watch.OutputDirectory == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "directory and outputDirectory are required"})
+		return
+	}
+	if info, err := os.Stat(watch.Directory); err != nil || !info.IsDir() {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "directory " + watch.Directory + " does not exist"})
+		return
+	}
+	if err := watch.CheckDirs(); err != nil {
+		c.JSON(http.StatusForbidden, gin.H{"error": err.Error()})
+		return
+	}
+	// the outputs would be processed as new files
+	if filepath.Clean(watch.Directory) == filepath.Clean(watch.OutputDirectory) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "outputDirectory has to be another directory"})
+		return
+	}
+
+	if err := h.watches.SaveWatch(&watch); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, watch)
+}
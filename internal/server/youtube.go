@@ -1,15 +1,24 @@
 package restapi
 
 import (
+	"archive/zip"
+	"bytes"
+	"context"
+	"fmt"
+	"io"
 	"net/http"
+	"strings"
+	"time"
 
 	"github.com/danielmiessler/fabric/internal/core"
+	"github.com/danielmiessler/fabric/internal/tools/export"
 	"github.com/danielmiessler/fabric/internal/tools/youtube"
 	"github.com/gin-gonic/gin"
 )
 
 type YouTubeHandler struct {
-	yt *youtube.YouTube
+	yt       *youtube.YouTube
+	registry *core.PluginRegistry
 }
 
 type YouTubeRequest struct {
@@ -23,9 +32,62 @@ type YouTubeResponse struct {
 	Title      string `json:"title"`
 }
 
+// YouTubeVideosRequest asks for the videos of a playlist or channel URL
+type YouTubeVideosRequest struct {
+	URL string `json:"url"`
+}
+
+type YouTubeVideo struct {
+	Id    string `json:"id"`
+	Title string `json:"title"`
+}
+
+type YouTubeVideosResponse struct {
+	PlaylistId string         `json:"playlistId"`
+	Videos     []YouTubeVideo `json:"videos"`
+}
+
+// YouTubeBatchRequest runs a pattern over the transcripts of the selected videos
+type YouTubeBatchRequest struct {
+	Videos       []YouTubeVideo    `json:"videos"`
+	PatternName  string            `json:"patternName"`
+	Model        string            `json:"model"`
+	Vendor       string            `json:"vendor"`
+	ContextName  string            `json:"contextName"`
+	StrategyName string            `json:"strategyName"`
+	Variables    map[string]string `json:"variables,omitempty"`
+	Language     string            `json:"language"`
+	Timestamps   bool              `json:"timestamps"`
+	Aggregate    bool              `json:"aggregate"`   // also combine all outputs into one document
+	IgnoreQuota  bool              `json:"ignoreQuota"` // run even though the vendor is near its quota cap
+}
+
+type YouTubeBatchResult struct {
+	YouTubeVideo
+	Output string `json:"output,omitempty"`
+	Error  string `json:"error,omitempty"`
+}
+
+type YouTubeBatchResponse struct {
+	Results  []YouTubeBatchResult `json:"results"`
+	Document string               `json:"document,omitempty"`
+}
+
+// YouTubeBatchFilesRequest asks for the outputs of a batch as separate files
+type YouTubeBatchFilesRequest struct {
+	PatternName string               `json:"patternName"`
+	Results     []YouTubeBatchResult `json:"results"`
+}
+
+// quotaCheckMinBatch is the batch size from which the quota of the vendor is checked before starting
+const quotaCheckMinBatch = 5
+
 func NewYouTubeHandler(r *gin.Engine, registry *core.PluginRegistry) *YouTubeHandler {
-	handler := &YouTubeHandler{yt: registry.YouTube}
+	handler := &YouTubeHandler{yt: registry.YouTube, registry: registry}
 	r.POST("/youtube/transcript", handler.Transcript)
+	r.POST("/youtube/videos", handler.Videos)
+	r.POST("/youtube/batch", handler.Batch)
+	r.POST("/youtube/batch/files", handler.BatchFiles)
 	return handler
 }
 
@@ -68,3 +130,168 @@ func (h *YouTubeHandler) Transcript(c *gin.Context) {
 
 	c.JSON(http.StatusOK, YouTubeResponse{Transcript: transcript, Title: videoID})
 }
+
+// Videos lists the videos of a playlist or channel URL, so the client can select which ones to process
+func (h *YouTubeHandler) Videos(c *gin.Context) {
+	var req YouTubeVideosRequest
+	if err := c.BindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid request"})
+		return
+	}
+	if req.URL == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "url is required"})
+		return
+	}
+
+	playlistID, err := h.yt.ResolvePlaylistId(req.URL)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	var videos []*youtube.VideoMeta
+	if videos, err = h.yt.FetchPlaylistVideos(playlistID); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	response := YouTubeVideosResponse{PlaylistId: playlistID, Videos: make([]YouTubeVideo, 0, len(videos))}
+	for _, video := range videos {
+		response.Videos = append(response.Videos, YouTubeVideo{Id: video.Id, Title: video.Title})
+	}
+	c.JSON(http.StatusOK, response)
+}
+
+// Batch runs the pattern over the transcript of every selected video. Failures are reported per video
+// and don't stop the batch.
+func (h *YouTubeHandler) Batch(c *gin.Context) {
+	var req YouTubeBatchRequest
+	if err := c.BindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid request"})
+		return
+	}
+	if len(req.Videos) == 0 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "at least one video is required"})
+		return
+	}
+	if req.PatternName == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "patternName is required"})
+		return
+	}
+	language := req.Language
+	if language == "" {
+		language = "en"
+	}
+
+	if !req.IgnoreQuota && len(req.Videos) >= quotaCheckMinBatch {
+		vendorName := req.Vendor
+		if vendorName == "" {
+			vendorName = h.registry.Defaults.Vendor.Value
+		}
+		if quota, err := h.registry.VendorManager.Quota(c.Request.Context(), vendorName); err == nil && quota.NearCap {
+			c.JSON(http.StatusConflict, gin.H{
+				"error": fmt.Sprintf("%s is close to its quota cap, set ignoreQuota to run the batch of %d videos anyway", vendorName, len(req.Videos)),
+				"quota": quota,
+			})
+			return
+		}
+	}
+
+	response := YouTubeBatchResponse{Results: make([]YouTubeBatchResult, 0, len(req.Videos))}
+	var document strings.Builder
+	for _, video := range req.Videos {
+		result := YouTubeBatchResult{YouTubeVideo: video}
+		if output, err := h.runPatternOnVideo(c.Request.Context(), &req, video.Id, language); err != nil {
+			result.Error = err.Error()
+		} else {
+			result.Output = output
+			if req.Aggregate {
+				title := video.Title
+				if title == "" {
+					title = video.Id
+				}
+				fmt.Fprintf(&document, "# %s\n\n%s\n\n", title, strings.TrimSpace(output))
+			}
+		}
+		response.Results = append(response.Results, result)
+	}
+	response.Document = document.String()
+
+	c.JSON(http.StatusOK, response)
+}
+
+func (h *YouTubeHandler) runPatternOnVideo(ctx context.Context, req *YouTubeBatchRequest, videoID string, language string) (output string, err error) {
+	var transcript string
+	if req.Timestamps {
+		transcript, err = h.yt.GrabTranscriptWithTimestamps(videoID, language)
+	} else {
+		transcript, err = h.yt.GrabTranscript(videoID, language)
+	}
+	if err != nil {
+		return
+	}
+
+	output, err = runPrompt(ctx, h.registry, PromptRequest{
+		UserInput:    transcript,
+		Vendor:       req.Vendor,
+		Model:        req.Model,
+		ContextName:  req.ContextName,
+		PatternName:  req.PatternName,
+		StrategyName: req.StrategyName,
+		Variables:    req.Variables,
+	}, req.Language)
+	return
+}
+
+// BatchFiles returns a zip of the outputs of a batch, a markdown file per video named after its position
+// and its title. The failed videos have no file.
+func (h *YouTubeHandler) BatchFiles(c *gin.Context) {
+	var req YouTubeBatchFilesRequest
+	if err := c.BindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid request"})
+		return
+	}
+
+	name := "fabric-youtube"
+	if slug := export.Slug(req.PatternName); slug != "" {
+		name += "-" + slug
+	}
+	var buffer bytes.Buffer
+	archive := zip.NewWriter(&buffer)
+	files := 0
+	for i, result := range req.Results {
+		if result.Error != "" || strings.TrimSpace(result.Output) == "" {
+			continue
+		}
+		title := result.Title
+		if title == "" {
+			title = result.Id
+		}
+		slug := export.Slug(title)
+		if slug == "" {
+			slug = export.Slug(result.Id)
+		}
+		fileName := fmt.Sprintf("%s/%02d-%s.md", name, i+1, slug)
+		file, err := archive.CreateHeader(&zip.FileHeader{Name: fileName, Method: zip.Deflate, Modified: time.Now()})
+		if err == nil {
+			_, err = io.WriteString(file, fmt.Sprintf("# %s\n\nhttps://www.youtube.com/watch?v=%s\n\n%s\n", title, result.Id,
+				strings.TrimSpace(result.Output)))
+		}
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+			return
+		}
+		files++
+	}
+	if files == 0 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "no video has an output"})
+		return
+	}
+	if err := archive.Close(); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.Header("Content-Disposition", `attachment; filename="`+name+`.zip"`)
+	c.Data(http.StatusOK, "application/zip", buffer.Bytes())
+}
@@ -0,0 +1,95 @@
+// Package autosave writes the output of every run to a file, so no output is lost when it wasn't saved.
+package autosave
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"time"
+)
+
+// DefaultPathTemplate is where the outputs are written when autosave is set to "default"
+const DefaultPathTemplate = "~/FabricOutputs/{pattern}/{timestamp}.md"
+
+var (
+	token = regexp.MustCompile(`\{(\w+)\}`)
+	// the characters the file systems don't allow in a name, the / of the model names included
+	forbidden = regexp.MustCompile(`[\\/:*?"<>|\x00-\x1f]`)
+)
+
+// Path is the file the output of a run is written to: the template with {pattern}, {model}, {date}
+// (2006-01-02), {time} (150405) and {timestamp} (20060102-150405) replaced, ~ the home directory.
+// "default" is DefaultPathTemplate.
+func Path(template string, pattern string, model string, now time.Time) (ret string, err error) {
+	if template = strings.TrimSpace(template); template == "default" {
+		template = DefaultPathTemplate
+	}
+	if pattern == "" {
+		pattern = "no-pattern"
+	}
+	if model == "" {
+		model = "model"
+	}
+	values := map[string]string{
+		"pattern":   pattern,
+		"model":     model,
+		"date":      now.Format("2006-01-02"),
+		"time":      now.Format("150405"),
+		"timestamp": now.Format("20060102-150405"),
+	}
+	ret = token.ReplaceAllStringFunc(template, func(match string) string {
+		if value, ok := values[match[1:len(match)-1]]; ok {
+			return forbidden.ReplaceAllString(value, "-")
+		}
+		return match
+	})
+	if ret == "~" || strings.HasPrefix(ret, "~/") {
+		var home string
+		if home, err = os.UserHomeDir(); err != nil {
+			return
+		}
+		ret = filepath.Join(home, ret[1:])
+	}
+	ret = filepath.Clean(ret)
+	return
+}
+
+// Save writes the output to the file of the template, creating its directory. An existing file is never
+// overwritten, a number is added to the name instead. Returns the path written.
+func Save(template string, pattern string, model string, output string, now time.Time) (ret string, err error) {
+	var path string
+	if path, err = Path(template, pattern, model, now); err != nil {
+		return
+	}
+	if err = os.MkdirAll(filepath.Dir(path), os.ModePerm); err != nil {
+		err = fmt.Errorf("could not create the autosave directory: %v", err)
+		return
+	}
+
+	ext := filepath.Ext(path)
+	base := strings.TrimSuffix(path, ext)
+	for i := 1; ; i++ {
+		ret = path
+		if i > 1 {
+			ret = fmt.Sprintf("%s-%d%s", base, i, ext)
+		}
+		var file *os.File
+		if file, err = os.OpenFile(ret, os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0o644); err != nil {
+			if os.IsExist(err) {
+				continue
+			}
+			err = fmt.Errorf("could not autosave the output: %v", err)
+			return
+		}
+		_, err = file.WriteString(output)
+		if closeErr := file.Close(); err == nil {
+			err = closeErr
+		}
+		if err != nil {
+			err = fmt.Errorf("could not autosave the output: %v", err)
+		}
+		return
+	}
+}
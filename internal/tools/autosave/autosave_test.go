@@ -0,0 +1,46 @@
+package autosave
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+var testTime = time.Date(2024, 3, 10, 9, 5, 7, 0, time.UTC)
+
+func TestPath(t *testing.T) {
+	path, err := Path("/tmp/out/{pattern}/{date}_{model}_{time}.md", "summarize", "openai/gpt-4o", testTime)
+	if err != nil || path != "/tmp/out/summarize/2024-03-10_openai-gpt-4o_090507.md" {
+		t.Errorf("Path = %q, %v", path, err)
+	}
+
+	home, _ := os.UserHomeDir()
+	path, err = Path("default", "", "", testTime)
+	if expected := filepath.Join(home, "FabricOutputs", "no-pattern", "20240310-090507.md"); err != nil || path != expected {
+		t.Errorf("Path = %q, %v, expected %q", path, err, expected)
+	}
+
+	if path, _ = Path("/tmp/{unknown}.md", "p", "m", testTime); path != "/tmp/{unknown}.md" {
+		t.Errorf("unknown tokens should be kept, got %q", path)
+	}
+}
+
+func TestSave_NeverOverwrites(t *testing.T) {
+	template := filepath.Join(t.TempDir(), "{pattern}", "{timestamp}.md")
+	first, err := Save(template, "summarize", "", "first", testTime)
+	if err != nil {
+		t.Fatalf("Save failed: %v", err)
+	}
+	second, err := Save(template, "summarize", "", "second", testTime)
+	if err != nil {
+		t.Fatalf("Save failed: %v", err)
+	}
+	if first == second || !strings.HasSuffix(second, "20240310-090507-2.md") {
+		t.Errorf("expected a numbered second file, got %q and %q", first, second)
+	}
+	if data, _ := os.ReadFile(first); string(data) != "first" {
+		t.Errorf("the first file was overwritten: %q", data)
+	}
+}
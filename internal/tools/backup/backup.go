@@ -0,0 +1,363 @@
+package backup
+
+import (
+	"archive/tar"
+	"compress/gzip"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+const (
+	manifestName  = "manifest.json"
+	formatVersion = 1
+
+	// ArchivePrefix and ArchiveExtension name the archives, e.g. fabric-backup-20240310-120000.tar.gz
+	ArchivePrefix    = "fabric-backup-"
+	ArchiveExtension = ".tar.gz"
+	archiveTimeFmt   = "20060102-150405"
+)
+
+// Source is a file or directory stored in the archive under Name
+type Source struct {
+	Name string
+	Path string
+}
+
+// Manifest lists the checksum of every file in the archive, it is written last
+type Manifest struct {
+	Version int               `json:"version"`
+	Created time.Time         `json:"created"`
+	Files   map[string]string `json:"files"` // archive path -> sha256
+}
+
+// Sources returns the names of the sources the archive has files of, in the order of names
+func (o *Manifest) Sources(names []string) (ret []string) {
+	for _, name := range names {
+		for file := range o.Files {
+			if file == name || strings.HasPrefix(file, name+"/") {
+				ret = append(ret, name)
+				break
+			}
+		}
+	}
+	return
+}
+
+// Create writes the sources into a timestamped archive in destDir. Missing sources are skipped.
+func Create(destDir string, sources []Source) (archivePath string, err error) {
+	if err = os.MkdirAll(destDir, os.ModePerm); err != nil {
+		return
+	}
+
+	now := time.Now()
+	archivePath = filepath.Join(destDir, ArchivePrefix+now.Format(archiveTimeFmt)+ArchiveExtension)
+
+	// write to a temporary file first, so a failed backup never leaves a partial archive behind
+	var tmp *os.File
+	if tmp, err = os.CreateTemp(destDir, ".fabric-backup-*"); err != nil {
+		return
+	}
+	defer os.Remove(tmp.Name())
+
+	gz := gzip.NewWriter(tmp)
+	tw := tar.NewWriter(gz)
+	manifest := &Manifest{Version: formatVersion, Created: now, Files: map[string]string{}}
+
+	for _, source := range sources {
+		if err = addSource(tw, source, manifest); err != nil {
+			break
+		}
+	}
+	if err == nil {
+		var data []byte
+		if data, err = json.MarshalIndent(manifest, "", "  "); err == nil {
+			err = writeEntry(tw, manifestName, data)
+		}
+	}
+	if err == nil {
+		err = tw.Close()
+	}
+	if err == nil {
+		err = gz.Close()
+	}
+	if closeErr := tmp.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		err = fmt.Errorf("could not create backup: %v", err)
+		return
+	}
+
+	err = os.Rename(tmp.Name(), archivePath)
+	return
+}
+
+func addSource(tw *tar.Writer, source Source, manifest *Manifest) (err error) {
+	if _, statErr := os.Stat(source.Path); os.IsNotExist(statErr) {
+		return
+	}
+
+	return filepath.WalkDir(source.Path, func(filePath string, entry fs.DirEntry, walkErr error) (err error) {
+		if walkErr != nil || entry.IsDir() {
+			return walkErr
+		}
+		if !entry.Type().IsRegular() {
+			return
+		}
+
+		var rel string
+		if rel, err = filepath.Rel(source.Path, filePath); err != nil {
+			return
+		}
+		name := source.Name
+		if rel != "." {
+			name = path.Join(source.Name, filepath.ToSlash(rel))
+		}
+
+		var data []byte
+		if data, err = os.ReadFile(filePath); err != nil {
+			return
+		}
+		if err = writeEntry(tw, name, data); err != nil {
+			return
+		}
+		manifest.Files[name] = checksum(data)
+		return
+	})
+}
+
+func writeEntry(tw *tar.Writer, name string, data []byte) (err error) {
+	header := &tar.Header{Name: name, Mode: 0644, Size: int64(len(data)), ModTime: time.Now(), Typeflag: tar.TypeReg}
+	if err = tw.WriteHeader(header); err != nil {
+		return
+	}
+	_, err = tw.Write(data)
+	return
+}
+
+// Verify checks that the archive is complete and that every file matches its manifest checksum
+func Verify(archivePath string) (ret *Manifest, err error) {
+	_, ret, err = readArchive(archivePath)
+	return
+}
+
+// Restore verifies the archive and then replaces the sources found in it with their archived content.
+// Sources missing from the archive are left untouched. The sources are written next to their path first and
+// renamed into place once all of them are written. When a rename fails the sources already replaced are put
+// back, a failed restore leaves the current data in place; the current data is only removed once every source
+// is replaced, or kept next to its path when it can't be put back.
+func Restore(archivePath string, sources []Source) (err error) {
+	var files map[string][]byte
+	if files, _, err = readArchive(archivePath); err != nil {
+		return
+	}
+
+	var staged []*stagedSource
+	defer func() {
+		for _, stage := range staged {
+			if !stage.keep {
+				os.RemoveAll(stage.dir)
+			}
+		}
+	}()
+	for _, source := range sources {
+		var stage *stagedSource
+		if stage, err = stageSource(source, files); err != nil {
+			err = fmt.Errorf("could not restore %s: %v", source.Name, err)
+			return
+		}
+		if stage != nil {
+			staged = append(staged, stage)
+		}
+	}
+
+	for i, stage := range staged {
+		if err = stage.swap(); err != nil {
+			err = fmt.Errorf("could not restore %s: %v", stage.source.Name, err)
+			for j := i - 1; j >= 0; j-- {
+				if undoErr := staged[j].undo(); undoErr != nil {
+					staged[j].keep = true
+					err = fmt.Errorf("%v; %s could not be put back, it is kept in %s: %v",
+						err, staged[j].source.Name, staged[j].oldPath(), undoErr)
+				}
+			}
+			return
+		}
+	}
+	return
+}
+
+// stagedSource is the archived content of a source written in a temporary directory next to its path
+type stagedSource struct {
+	source  Source
+	dir     string
+	existed bool // the current content was moved aside by swap
+	keep    bool // the current content could not be put back, dir is not removed
+}
+
+func (o *stagedSource) newPath() string { return filepath.Join(o.dir, "new") }
+func (o *stagedSource) oldPath() string { return filepath.Join(o.dir, "old") }
+
+// stageSource writes the archived files of the source in a temporary directory, nil when the archive has
+// none of them
+func stageSource(source Source, files map[string][]byte) (ret *stagedSource, err error) {
+	prefix := source.Name + "/"
+	var restored []string
+	for name := range files {
+		if name == source.Name || strings.HasPrefix(name, prefix) {
+			restored = append(restored, name)
+		}
+	}
+	if len(restored) == 0 {
+		return
+	}
+
+	parent := filepath.Dir(source.Path)
+	if err = os.MkdirAll(parent, os.ModePerm); err != nil {
+		return
+	}
+	ret = &stagedSource{source: source}
+	if ret.dir, err = os.MkdirTemp(parent, "."+filepath.Base(source.Path)+"-restore-*"); err != nil {
+		return
+	}
+	for _, name := range restored {
+		target := ret.newPath()
+		if name != source.Name {
+			target = filepath.Join(target, filepath.FromSlash(strings.TrimPrefix(name, prefix)))
+		}
+		if err = os.MkdirAll(filepath.Dir(target), os.ModePerm); err == nil {
+			err = os.WriteFile(target, files[name], 0644)
+		}
+		if err != nil {
+			os.RemoveAll(ret.dir)
+			ret = nil
+			return
+		}
+	}
+	return
+}
+
+// swap moves the current content of the source aside and the staged one in its place, the current content
+// is put back when the move fails
+func (o *stagedSource) swap() (err error) {
+	_, statErr := os.Lstat(o.source.Path)
+	if statErr == nil {
+		if err = rename(o.source.Path, o.oldPath()); err != nil {
+			return
+		}
+		o.existed = true
+	}
+	if err = rename(o.newPath(), o.source.Path); err != nil && o.existed {
+		if backErr := rename(o.oldPath(), o.source.Path); backErr != nil {
+			o.keep = true
+			err = fmt.Errorf("%v; it could not be put back, it is kept in %s: %v", err, o.oldPath(), backErr)
+		} else {
+			o.existed = false
+		}
+	}
+	return
+}
+
+// undo puts back the content moved aside by a successful swap
+func (o *stagedSource) undo() (err error) {
+	if err = rename(o.source.Path, o.newPath()); err != nil || !o.existed {
+		return
+	}
+	if err = rename(o.oldPath(), o.source.Path); err == nil {
+		o.existed = false
+	}
+	return
+}
+
+// rename is replaced by the tests to make the swaps fail
+var rename = os.Rename
+
+func readArchive(archivePath string) (files map[string][]byte, manifest *Manifest, err error) {
+	var file *os.File
+	if file, err = os.Open(archivePath); err != nil {
+		return
+	}
+	defer file.Close()
+
+	var gz *gzip.Reader
+	if gz, err = gzip.NewReader(file); err != nil {
+		err = fmt.Errorf("invalid backup archive %s: %v", archivePath, err)
+		return
+	}
+	defer gz.Close()
+
+	files = map[string][]byte{}
+	tr := tar.NewReader(gz)
+	for {
+		var header *tar.Header
+		if header, err = tr.Next(); err == io.EOF {
+			err = nil
+			break
+		}
+		if err != nil {
+			err = fmt.Errorf("invalid backup archive %s: %v", archivePath, err)
+			return
+		}
+		if header.Typeflag != tar.TypeReg {
+			continue
+		}
+		name := path.Clean(header.Name)
+		if path.IsAbs(name) || name == ".." || strings.HasPrefix(name, "../") {
+			err = fmt.Errorf("invalid backup archive %s: unsafe path %s", archivePath, header.Name)
+			return
+		}
+		var data []byte
+		if data, err = io.ReadAll(tr); err != nil {
+			err = fmt.Errorf("invalid backup archive %s: %v", archivePath, err)
+			return
+		}
+		if name == manifestName {
+			manifest = &Manifest{}
+			if err = json.Unmarshal(data, manifest); err != nil {
+				err = fmt.Errorf("invalid backup manifest: %v", err)
+				return
+			}
+			continue
+		}
+		files[name] = data
+	}
+
+	if manifest == nil {
+		err = fmt.Errorf("invalid backup archive %s: manifest is missing", archivePath)
+		return
+	}
+	if manifest.Version > formatVersion {
+		err = fmt.Errorf("backup archive version %d is not supported, please update fabric", manifest.Version)
+		return
+	}
+	if len(files) != len(manifest.Files) {
+		err = fmt.Errorf("backup archive is incomplete: %d of %d files", len(files), len(manifest.Files))
+		return
+	}
+	for name, sum := range manifest.Files {
+		data, ok := files[name]
+		if !ok {
+			err = fmt.Errorf("backup archive is incomplete: %s is missing", name)
+			return
+		}
+		if checksum(data) != sum {
+			err = fmt.Errorf("backup archive is corrupted: checksum of %s does not match", name)
+			return
+		}
+	}
+	return
+}
+
+func checksum(data []byte) string {
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:])
+}
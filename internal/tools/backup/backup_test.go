@@ -0,0 +1,151 @@
+package backup
+
+import (
+	"archive/tar"
+	"compress/gzip"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestCreateAndRestore(t *testing.T) {
+	dir := t.TempDir()
+	envFile := filepath.Join(dir, ".env")
+	contextsDir := filepath.Join(dir, "contexts")
+	if err := os.MkdirAll(filepath.Join(contextsDir, "nested"), os.ModePerm); err != nil {
+		t.Fatalf("failed to create dir: %v", err)
+	}
+	os.WriteFile(envFile, []byte("KEY=value\n"), 0644)
+	os.WriteFile(filepath.Join(contextsDir, "work"), []byte("work context"), 0644)
+	os.WriteFile(filepath.Join(contextsDir, "nested", "deep"), []byte("deep context"), 0644)
+
+	sources := []Source{
+		{Name: ".env", Path: envFile},
+		{Name: "contexts", Path: contextsDir},
+		{Name: "missing", Path: filepath.Join(dir, "missing")},
+	}
+	archivePath, err := Create(filepath.Join(dir, "backups"), sources)
+	if err != nil {
+		t.Fatalf("Create failed: %v", err)
+	}
+	if !strings.HasPrefix(filepath.Base(archivePath), ArchivePrefix) {
+		t.Errorf("unexpected archive name %s", archivePath)
+	}
+
+	manifest, err := Verify(archivePath)
+	if err != nil {
+		t.Fatalf("Verify failed: %v", err)
+	}
+	if len(manifest.Files) != 3 {
+		t.Errorf("expected 3 files in manifest, got %v", manifest.Files)
+	}
+
+	// change the data, the restore must bring back the archived state
+	os.WriteFile(envFile, []byte("KEY=changed\n"), 0644)
+	os.WriteFile(filepath.Join(contextsDir, "added"), []byte("added later"), 0644)
+
+	if err = Restore(archivePath, sources); err != nil {
+		t.Fatalf("Restore failed: %v", err)
+	}
+	if data, _ := os.ReadFile(envFile); string(data) != "KEY=value\n" {
+		t.Errorf("expected .env to be restored, got %q", data)
+	}
+	if data, _ := os.ReadFile(filepath.Join(contextsDir, "nested", "deep")); string(data) != "deep context" {
+		t.Errorf("expected nested context to be restored, got %q", data)
+	}
+	if _, err = os.Stat(filepath.Join(contextsDir, "added")); !os.IsNotExist(err) {
+		t.Errorf("expected files added after the backup to be removed")
+	}
+}
+
+func TestRestoreRejectsCorruptedArchive(t *testing.T) {
+	dir := t.TempDir()
+	envFile := filepath.Join(dir, ".env")
+	os.WriteFile(envFile, []byte("KEY=value\n"), 0644)
+
+	// an archive with a manifest checksum that doesn't match the file
+	archivePath := filepath.Join(dir, "corrupted.tar.gz")
+	file, _ := os.Create(archivePath)
+	gz := gzip.NewWriter(file)
+	tw := tar.NewWriter(gz)
+	writeEntry(tw, ".env", []byte("KEY=tampered\n"))
+	writeEntry(tw, manifestName, []byte(`{"version":1,"files":{".env":"0000"}}`))
+	tw.Close()
+	gz.Close()
+	file.Close()
+
+	if err := Restore(archivePath, []Source{{Name: ".env", Path: envFile}}); err == nil {
+		t.Fatal("expected corrupted archive to be rejected")
+	}
+	if data, _ := os.ReadFile(envFile); string(data) != "KEY=value\n" {
+		t.Errorf("expected .env to be untouched, got %q", data)
+	}
+}
+
+func TestRestoreFailureKeepsData(t *testing.T) {
+	dir := t.TempDir()
+	contextsDir := filepath.Join(dir, "contexts")
+	os.MkdirAll(contextsDir, os.ModePerm)
+	os.WriteFile(filepath.Join(contextsDir, "work"), []byte("work context"), 0644)
+	blocker := filepath.Join(dir, "blocker")
+	os.WriteFile(blocker, []byte("a file"), 0644)
+	os.MkdirAll(filepath.Join(dir, "sessions"), os.ModePerm)
+	os.WriteFile(filepath.Join(dir, "sessions", "chat"), []byte("[]"), 0644)
+
+	sources := []Source{{Name: "contexts", Path: contextsDir}, {Name: "sessions", Path: filepath.Join(dir, "sessions")}}
+	archivePath, err := Create(filepath.Join(dir, "backups"), sources)
+	if err != nil {
+		t.Fatalf("Create failed: %v", err)
+	}
+	os.WriteFile(filepath.Join(contextsDir, "work"), []byte("changed"), 0644)
+
+	// the sessions can't be written below a file, the contexts must not be replaced either
+	sources[1].Path = filepath.Join(blocker, "sessions")
+	if err = Restore(archivePath, sources); err == nil {
+		t.Fatal("expected the restore to fail")
+	}
+	if data, _ := os.ReadFile(filepath.Join(contextsDir, "work")); string(data) != "changed" {
+		t.Errorf("expected the contexts to be untouched, got %q", data)
+	}
+	if matches, _ := filepath.Glob(filepath.Join(dir, ".*-restore-*")); len(matches) > 0 {
+		t.Errorf("expected the staged sources to be removed, got %v", matches)
+	}
+}
+
+func TestRestoreRollsBackSwappedSources(t *testing.T) {
+	dir := t.TempDir()
+	sources := []Source{{Name: "contexts", Path: filepath.Join(dir, "contexts")}, {Name: "sessions", Path: filepath.Join(dir, "sessions")}}
+	for _, source := range sources {
+		os.MkdirAll(source.Path, os.ModePerm)
+		os.WriteFile(filepath.Join(source.Path, "item"), []byte("archived"), 0644)
+	}
+	archivePath, err := Create(filepath.Join(dir, "backups"), sources)
+	if err != nil {
+		t.Fatalf("Create failed: %v", err)
+	}
+	for _, source := range sources {
+		os.WriteFile(filepath.Join(source.Path, "item"), []byte("current"), 0644)
+	}
+
+	// the contexts are swapped, moving the staged sessions into place fails
+	defer func() { rename = os.Rename }()
+	rename = func(from, to string) error {
+		if filepath.Base(from) == "new" && to == sources[1].Path {
+			return errors.New("rename failed")
+		}
+		return os.Rename(from, to)
+	}
+	if err = Restore(archivePath, sources); err == nil {
+		t.Fatal("expected the restore to fail")
+	}
+	for _, source := range sources {
+		if data, _ := os.ReadFile(filepath.Join(source.Path, "item")); string(data) != "current" {
+			t.Errorf("expected %s to be put back, got %q", source.Name, data)
+		}
+	}
+	if matches, _ := filepath.Glob(filepath.Join(dir, ".*-restore-*")); len(matches) > 0 {
+		t.Errorf("expected the staged sources to be removed, got %v", matches)
+	}
+}
@@ -0,0 +1,153 @@
+package codebase
+
+import (
+	"path/filepath"
+	"regexp"
+	"strings"
+
+	"github.com/danielmiessler/fabric/internal/tools/textfile"
+)
+
+// languages maps the extensions of source files to their language
+var languages = map[string]string{
+	".go": "go", ".py": "python", ".js": "javascript", ".jsx": "javascript", ".mjs": "javascript",
+	".cjs": "javascript", ".ts": "typescript", ".tsx": "typescript", ".svelte": "svelte", ".vue": "vue",
+	".java": "java", ".kt": "kotlin", ".kts": "kotlin", ".scala": "scala", ".cs": "csharp", ".rs": "rust",
+	".rb": "ruby", ".php": "php", ".swift": "swift", ".c": "c", ".h": "c", ".cc": "cpp", ".cpp": "cpp",
+	".cxx": "cpp", ".hpp": "cpp", ".sh": "shell", ".bash": "shell", ".zsh": "shell", ".lua": "lua", ".sql": "sql",
+}
+
+var (
+	cFamilyBoundary = regexp.MustCompile(`^(\s{0,4})(public|private|protected|internal|static|abstract|final|sealed|partial|` +
+		`class|interface|enum|record|struct|namespace|template|typedef|void|int|char|bool|func|fun|override|object|trait|def)\b`)
+	jsBoundary = regexp.MustCompile(`^(export\s+)?(default\s+)?(async\s+)?(function|class|interface|type|enum|const|let)\b`)
+)
+
+// boundaries match the first line of the top-level declarations of each language
+var boundaries = map[string]*regexp.Regexp{
+	"go":         regexp.MustCompile(`^(func|type|var|const)\b`),
+	"python":     regexp.MustCompile(`^(async\s+def|def|class)\s`),
+	"javascript": jsBoundary,
+	"typescript": jsBoundary,
+	"svelte":     regexp.MustCompile(`^(\s*)(<script|<style|export\s+|function\s|const\s|let\s)`),
+	"vue":        regexp.MustCompile(`^(<template|<script|<style)`),
+	"java":       cFamilyBoundary,
+	"kotlin":     cFamilyBoundary,
+	"scala":      cFamilyBoundary,
+	"csharp":     cFamilyBoundary,
+	"swift":      cFamilyBoundary,
+	"php":        regexp.MustCompile(`^(\s{0,4})((abstract|final|public|private|protected|static)\s+)*(function|class|interface|trait)\b`),
+	"c":          cFamilyBoundary,
+	"cpp":        cFamilyBoundary,
+	"rust":       regexp.MustCompile(`^(pub(\([\w:]+\))?\s+)?(async\s+)?(fn|struct|enum|impl|trait|mod|type|const|static)\b`),
+	"ruby":       regexp.MustCompile(`^\s{0,2}(def|class|module)\s`),
+	"shell":      regexp.MustCompile(`^(function\s+\w+|\w+\s*\(\)\s*\{?)`),
+	"lua":        regexp.MustCompile(`^(local\s+)?function\b`),
+	"sql":        regexp.MustCompile(`(?i)^(create|alter|drop|insert|update|delete|select|with)\b`),
+}
+
+// commentLine matches comment and decorator lines, kept with the declaration that follows them
+var commentLine = regexp.MustCompile(`^\s*(//|#|/\*|\*|--|@|///)`)
+
+// Chunk is a part of a source file, its lines are 1-based and inclusive
+type Chunk struct {
+	StartLine int
+	EndLine   int
+	Content   string
+}
+
+// LanguageOf returns the language of a source file by its extension, empty for other files
+func LanguageOf(path string) string {
+	return languages[strings.ToLower(filepath.Ext(path))]
+}
+
+// Split cuts the content in chunks of at most maxTokens, see textfile.EstimateTokens. The cuts are made
+// before top-level declarations of the language, together with their doc comments, so a function or
+// class is only split when it is larger than maxTokens by itself; then, and for other languages, the cuts
+// are made at blank lines. The content is a single chunk when it fits or with maxTokens 0.
+func Split(content string, language string, maxTokens int) (ret []Chunk) {
+	lines := strings.SplitAfter(content, "\n")
+	if lines[len(lines)-1] == "" {
+		lines = lines[:len(lines)-1]
+	}
+	if maxTokens <= 0 || textfile.EstimateTokens(content) <= maxTokens || len(lines) < 2 {
+		return []Chunk{{StartLine: 1, EndLine: max(1, len(lines)), Content: content}}
+	}
+
+	segments := splitSegments(lines, 0, len(lines), boundaryStarts(lines, boundaries[language]), maxTokens)
+
+	// merge consecutive segments while they fit
+	start, tokens := 0, 0
+	for _, segment := range segments {
+		segmentTokens := textfile.EstimateTokens(strings.Join(lines[segment[0]:segment[1]], ""))
+		if tokens > 0 && tokens+segmentTokens > maxTokens {
+			ret = append(ret, newChunk(lines, start, segment[0]))
+			start, tokens = segment[0], 0
+		}
+		tokens += segmentTokens
+	}
+	ret = append(ret, newChunk(lines, start, len(lines)))
+	return
+}
+
+// splitSegments cuts lines[from:to] at the starts, cutting the segments still above maxTokens at blank
+// lines and then every few lines
+func splitSegments(lines []string, from int, to int, starts []int, maxTokens int) (ret [][2]int) {
+	cuts := []int{from}
+	for _, start := range starts {
+		if start > from && start < to {
+			cuts = append(cuts, start)
+		}
+	}
+	cuts = append(cuts, to)
+
+	for i := 0; i+1 < len(cuts); i++ {
+		segment := [2]int{cuts[i], cuts[i+1]}
+		if segment[1]-segment[0] < 2 || textfile.EstimateTokens(strings.Join(lines[segment[0]:segment[1]], "")) <= maxTokens {
+			ret = append(ret, segment)
+			continue
+		}
+		if blanks := blankStarts(lines, segment[0], segment[1]); len(blanks) > 0 {
+			ret = append(ret, splitSegments(lines, segment[0], segment[1], blanks, maxTokens)...)
+			continue
+		}
+		// no blank line either, cut in halves until the parts fit
+		middle := (segment[0] + segment[1]) / 2
+		ret = append(ret, splitSegments(lines, segment[0], segment[1], []int{middle}, maxTokens)...)
+	}
+	return
+}
+
+// boundaryStarts returns the indexes of the lines starting a declaration, moved up over their comments
+func boundaryStarts(lines []string, boundary *regexp.Regexp) (ret []int) {
+	if boundary == nil {
+		return
+	}
+	for i, line := range lines {
+		if !boundary.MatchString(line) {
+			continue
+		}
+		start := i
+		for start > 0 && commentLine.MatchString(lines[start-1]) {
+			start--
+		}
+		if len(ret) == 0 || ret[len(ret)-1] < start {
+			ret = append(ret, start)
+		}
+	}
+	return
+}
+
+// blankStarts returns the indexes of the lines following a blank line within lines[from:to]
+func blankStarts(lines []string, from int, to int) (ret []int) {
+	for i := from + 1; i < to; i++ {
+		if strings.TrimSpace(lines[i-1]) == "" && strings.TrimSpace(lines[i]) != "" {
+			ret = append(ret, i)
+		}
+	}
+	return
+}
+
+func newChunk(lines []string, from int, to int) Chunk {
+	return Chunk{StartLine: from + 1, EndLine: to, Content: strings.Join(lines[from:to], "")}
+}
@@ -0,0 +1,236 @@
+package codebase
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/danielmiessler/fabric/internal/tools/textfile"
+	"github.com/go-git/go-git/v5/plumbing/format/gitignore"
+)
+
+// DefaultChunkTokens is the size above which a source file is split at its function and class boundaries
+const DefaultChunkTokens = 2000
+
+// maxFileBytes skips larger files of a directory, they are data or generated code
+const maxFileBytes = 1 << 20
+
+// vendoredDirs are skipped at any depth
+var vendoredDirs = map[string]bool{
+	".git": true, ".hg": true, ".svn": true, "vendor": true, "node_modules": true, "third_party": true,
+	"bower_components": true, ".venv": true, "venv": true, "__pycache__": true, ".tox": true,
+	"dist": true, "build": true, "target": true, ".next": true, ".svelte-kit": true, ".idea": true, ".vscode": true,
+}
+
+// generatedFiles are lock files and the like, large and of no use to explain the code
+var generatedFiles = map[string]bool{
+	"package-lock.json": true, "yarn.lock": true, "pnpm-lock.yaml": true, "go.sum": true, "Cargo.lock": true,
+	"poetry.lock": true, "Gemfile.lock": true, "composer.lock": true,
+}
+
+// File is a source file of a directory, its path is relative to the directory and slash separated
+type File struct {
+	Path     string
+	Language string
+	Content  string
+}
+
+// IsCode tells whether the path is a directory or a source file, inputs Ingest prepares for code patterns
+func IsCode(path string) bool {
+	if info, err := os.Stat(path); err == nil && info.IsDir() {
+		return true
+	}
+	return LanguageOf(path) != ""
+}
+
+// Ingest reads a source file or a directory of sources as input for code patterns. A directory is read
+// with Collect and preceded by its file tree. Files above maxChunkTokens are split at their function and
+// class boundaries, each part under a header giving its lines, no split with maxChunkTokens 0.
+func Ingest(path string, maxChunkTokens int) (ret string, err error) {
+	var info os.FileInfo
+	if info, err = os.Stat(path); err != nil {
+		return
+	}
+
+	if !info.IsDir() {
+		var content string
+		if content, err = textfile.Read(path, textfile.DefaultMaxBytes); err != nil {
+			return
+		}
+		file := File{Path: filepath.Base(path), Language: LanguageOf(path), Content: content}
+		ret = formatFile(file, maxChunkTokens, false)
+		return
+	}
+
+	var files []File
+	var skipped int
+	if files, skipped, err = Collect(path); err != nil {
+		return
+	}
+	if len(files) == 0 {
+		err = fmt.Errorf("no source files in %s", path)
+		return
+	}
+
+	var builder strings.Builder
+	fmt.Fprintf(&builder, "Files of %s (%d", filepath.Base(filepath.Clean(path)), len(files))
+	if skipped > 0 {
+		fmt.Fprintf(&builder, ", %d binary, vendored or ignored files skipped", skipped)
+	}
+	builder.WriteString("):\n")
+	builder.WriteString(Tree(files))
+	for _, file := range files {
+		builder.WriteString("\n")
+		builder.WriteString(formatFile(file, maxChunkTokens, true))
+	}
+	ret = builder.String()
+	return
+}
+
+// Collect reads the text files of the directory, sorted by path. Files and directories ignored by the
+// .gitignore files of the directory, vendored directories, lock files, binary files and files larger than
+// 1 MiB are skipped and counted.
+func Collect(root string) (ret []File, skipped int, err error) {
+	var patterns []gitignore.Pattern
+	err = filepath.WalkDir(root, func(path string, entry fs.DirEntry, walkErr error) error {
+		if walkErr != nil {
+			return walkErr
+		}
+		rel, _ := filepath.Rel(root, path)
+		if rel == "." {
+			patterns = append(patterns, readGitignore(path, nil)...)
+			return nil
+		}
+		parts := strings.Split(filepath.ToSlash(rel), "/")
+
+		if entry.IsDir() {
+			if vendoredDirs[entry.Name()] || gitignore.NewMatcher(patterns).Match(parts, true) {
+				skipped++
+				return filepath.SkipDir
+			}
+			// the patterns of nested .gitignore files apply below their directory only
+			patterns = append(patterns, readGitignore(path, parts)...)
+			return nil
+		}
+
+		if !entry.Type().IsRegular() || generatedFiles[entry.Name()] || isMinified(entry.Name()) ||
+			gitignore.NewMatcher(patterns).Match(parts, false) {
+			skipped++
+			return nil
+		}
+		if info, infoErr := entry.Info(); infoErr != nil || info.Size() > maxFileBytes {
+			skipped++
+			return nil
+		}
+
+		content, readErr := textfile.Read(path, maxFileBytes)
+		if errors.Is(readErr, textfile.ErrBinary) {
+			skipped++
+			return nil
+		} else if readErr != nil {
+			return readErr
+		}
+		ret = append(ret, File{Path: strings.Join(parts, "/"), Language: LanguageOf(path), Content: content})
+		return nil
+	})
+
+	sort.Slice(ret, func(i, j int) bool { return ret[i].Path < ret[j].Path })
+	return
+}
+
+// Tree renders the paths of the files as an indented tree, directories first
+func Tree(files []File) string {
+	root := &treeNode{children: map[string]*treeNode{}}
+	for _, file := range files {
+		node := root
+		for _, part := range strings.Split(file.Path, "/") {
+			child, ok := node.children[part]
+			if !ok {
+				child = &treeNode{name: part, children: map[string]*treeNode{}}
+				node.children[part] = child
+			}
+			node = child
+		}
+	}
+
+	var builder strings.Builder
+	root.write(&builder, "")
+	return builder.String()
+}
+
+type treeNode struct {
+	name     string
+	children map[string]*treeNode
+}
+
+func (o *treeNode) write(builder *strings.Builder, indent string) {
+	children := make([]*treeNode, 0, len(o.children))
+	for _, child := range o.children {
+		children = append(children, child)
+	}
+	sort.Slice(children, func(i, j int) bool {
+		iDir, jDir := len(children[i].children) > 0, len(children[j].children) > 0
+		if iDir != jDir {
+			return iDir
+		}
+		return children[i].name < children[j].name
+	})
+
+	for _, child := range children {
+		if len(child.children) > 0 {
+			fmt.Fprintf(builder, "%s%s/\n", indent, child.name)
+			child.write(builder, indent+"  ")
+		} else {
+			fmt.Fprintf(builder, "%s%s\n", indent, child.name)
+		}
+	}
+}
+
+// formatFile renders the file under a header, or its chunks under a header each
+func formatFile(file File, maxChunkTokens int, header bool) string {
+	chunks := Split(file.Content, file.Language, maxChunkTokens)
+	if len(chunks) == 1 {
+		if !header {
+			return file.Content
+		}
+		return fmt.Sprintf("==> %s <==\n%s\n", file.Path, strings.TrimRight(file.Content, "\n"))
+	}
+
+	var builder strings.Builder
+	for i, chunk := range chunks {
+		if i > 0 {
+			builder.WriteString("\n")
+		}
+		fmt.Fprintf(&builder, "==> %s (lines %d-%d, part %d/%d) <==\n%s\n",
+			file.Path, chunk.StartLine, chunk.EndLine, i+1, len(chunks), strings.TrimRight(chunk.Content, "\n"))
+	}
+	return builder.String()
+}
+
+// readGitignore parses the .gitignore of the directory, domain is the directory relative to the root
+func readGitignore(dir string, domain []string) (ret []gitignore.Pattern) {
+	file, err := os.Open(filepath.Join(dir, ".gitignore"))
+	if err != nil {
+		return
+	}
+	defer file.Close()
+
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		line := strings.TrimRight(scanner.Text(), " \r")
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		ret = append(ret, gitignore.ParsePattern(line, domain))
+	}
+	return
+}
+
+func isMinified(name string) bool {
+	return strings.HasSuffix(name, ".min.js") || strings.HasSuffix(name, ".min.css") || strings.HasSuffix(name, ".map")
+}
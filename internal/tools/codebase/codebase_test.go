@@ -0,0 +1,145 @@
+package codebase
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func writeFiles(t *testing.T, root string, files map[string]string) {
+	for path, content := range files {
+		full := filepath.Join(root, filepath.FromSlash(path))
+		require.NoError(t, os.MkdirAll(filepath.Dir(full), 0755))
+		require.NoError(t, os.WriteFile(full, []byte(content), 0644))
+	}
+}
+
+func TestCollect(t *testing.T) {
+	root := t.TempDir()
+	writeFiles(t, root, map[string]string{
+		".gitignore":              "*.log\n/out\n",
+		"main.go":                 "package main\n",
+		"app.log":                 "ignored\n",
+		"out/result.txt":          "ignored\n",
+		"pkg/.gitignore":          "secret.go\n",
+		"pkg/secret.go":           "package pkg\n",
+		"pkg/util.go":             "package pkg\n",
+		"node_modules/x/index.js": "vendored\n",
+		"go.sum":                  "lock\n",
+		"image.png":               "\x89PNG\x00\x00\x00binary",
+	})
+
+	files, skipped, err := Collect(root)
+	require.NoError(t, err)
+
+	var paths []string
+	for _, file := range files {
+		paths = append(paths, file.Path)
+	}
+	assert.Equal(t, []string{".gitignore", "main.go", "pkg/.gitignore", "pkg/util.go"}, paths)
+	assert.Equal(t, 6, skipped)
+	assert.Equal(t, "go", files[1].Language)
+
+	assert.Equal(t, "pkg/\n  .gitignore\n  util.go\n.gitignore\nmain.go\n", Tree(files))
+}
+
+func TestIngestDirectory(t *testing.T) {
+	root := t.TempDir()
+	writeFiles(t, root, map[string]string{"main.go": "package main\n", "lib/lib.py": "def f():\n    pass\n"})
+
+	ret, err := Ingest(root, DefaultChunkTokens)
+	require.NoError(t, err)
+	assert.Contains(t, ret, "(2):\nlib/\n  lib.py\nmain.go\n")
+	assert.Contains(t, ret, "==> lib/lib.py <==\ndef f():\n    pass\n")
+	assert.Contains(t, ret, "==> main.go <==\npackage main\n")
+}
+
+func TestSplitAtDeclarations(t *testing.T) {
+	body := strings.Repeat("\tx := 1\n", 20)
+	content := "package main\n\n// A does a\nfunc A() {\n" + body + "}\n\n// B does b\nfunc B() {\n" + body + "}\n"
+
+	chunks := Split(content, "go", 60)
+	// the package clause is small enough to stay with A
+	require.Len(t, chunks, 2)
+	assert.True(t, strings.HasPrefix(chunks[0].Content, "package main\n\n// A does a\nfunc A() {"))
+	assert.True(t, strings.HasPrefix(chunks[1].Content, "// B does b\nfunc B() {"))
+	assert.Equal(t, 1, chunks[0].StartLine)
+	assert.Equal(t, chunks[0].EndLine+1, chunks[1].StartLine)
+	assert.Equal(t, len(strings.Split(content, "\n"))-1, chunks[1].EndLine)
+
+	// the package clause and both functions fit together
+	assert.Len(t, Split(content, "go", 1000), 1)
+	assert.Len(t, Split(content, "go", 0), 1)
+}
+
+func TestSplitLargeDeclaration(t *testing.T) {
+	content := "def big():\n" + strings.Repeat("    x = 1\n", 40) + "\n" + strings.Repeat("    y = 2\n", 40)
+
+	chunks := Split(content, "python", 60)
+	require.Greater(t, len(chunks), 1)
+	var joined strings.Builder
+	for _, chunk := range chunks {
+		joined.WriteString(chunk.Content)
+	}
+	assert.Equal(t, content, joined.String())
+	assert.True(t, strings.HasPrefix(chunks[len(chunks)-1].Content, "    y = 2\n"))
+}
+
+func TestIsCode(t *testing.T) {
+	assert.True(t, IsCode("main.go"))
+	assert.True(t, IsCode(t.TempDir()))
+	assert.False(t, IsCode("notes.md"))
+}
+
+func TestBuildCorpus(t *testing.T) {
+	root := t.TempDir()
+	writeFiles(t, root, map[string]string{
+		"main.go":           "package main\n",
+		"main_test.go":      "package main\n",
+		"src/app/view.ts":   "export const view = 1;\n",
+		"src/app/view.css":  "body {}\n",
+		"docs/guide.md":     "# Guide\n",
+		"web/lib/client.ts": "export const client = 1;\n",
+	})
+
+	corpus, err := BuildCorpus(root, CorpusOptions{Include: []string{"*.go", "src/**/*.ts", "docs/"}, Exclude: []string{"*_test.go"}})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"docs/guide.md", "main.go", "src/app/view.ts"}, corpus.Files)
+	assert.Empty(t, corpus.Omitted)
+	assert.Contains(t, corpus.Content, "==> src/app/view.ts <==\nexport const view = 1;\n")
+	assert.NotContains(t, corpus.Content, "client.ts")
+
+	// the files over the budget are omitted, the smaller ones after them still fit
+	writeFiles(t, root, map[string]string{"big.go": strings.Repeat("// filler\n", 200)})
+	corpus, err = BuildCorpus(root, CorpusOptions{Include: []string{"*.go"}, MaxTokens: 100})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"main.go", "main_test.go"}, corpus.Files)
+	assert.Equal(t, []string{"big.go"}, corpus.Omitted)
+	assert.Contains(t, corpus.Content, "1 omitted over the budget of 100 tokens")
+
+	_, err = BuildCorpus(root, CorpusOptions{Include: []string{"*.rs"}})
+	assert.Error(t, err)
+}
+
+func TestAllowedRoot(t *testing.T) {
+	allowed := t.TempDir()
+	writeFiles(t, allowed, map[string]string{"repo/main.go": "package main\n"})
+	outside := t.TempDir()
+	require.NoError(t, os.Symlink(outside, filepath.Join(allowed, "link")))
+
+	root, err := AllowedRoot(filepath.Join(allowed, "repo"), []string{"", allowed})
+	require.NoError(t, err)
+	resolved, _ := filepath.EvalSymlinks(filepath.Join(allowed, "repo"))
+	assert.Equal(t, resolved, root)
+
+	for _, root := range []string{"repo", outside, filepath.Join(allowed, "link"), filepath.Join(allowed, "repo", "main.go")} {
+		_, err = AllowedRoot(root, []string{allowed})
+		assert.Error(t, err, root)
+	}
+	_, err = AllowedRoot(filepath.Join(allowed, "repo"), nil)
+	assert.Error(t, err)
+}
@@ -0,0 +1,148 @@
+package codebase
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/danielmiessler/fabric/internal/tools/textfile"
+	"github.com/danielmiessler/fabric/internal/util"
+	"github.com/go-git/go-git/v5/plumbing/format/gitignore"
+)
+
+// DefaultCorpusTokens is the token budget of a corpus when not set
+const DefaultCorpusTokens = 100000
+
+// CodeRootsEnv lists the directories whose repositories the server builds corpora of, separated like PATH
+const CodeRootsEnv = "FABRIC_CODE_ROOTS"
+
+// AllowedRoot resolves the symlinks of the repository root and checks it is one of the allowed directories
+// or below one of them
+func AllowedRoot(root string, allowed []string) (ret string, err error) {
+	if !filepath.IsAbs(root) {
+		err = fmt.Errorf("the repository root must be an absolute path")
+		return
+	}
+	if ret, err = filepath.EvalSymlinks(root); err != nil {
+		err = fmt.Errorf("the repository root is not a directory")
+		return
+	}
+	if info, statErr := os.Stat(ret); statErr != nil || !info.IsDir() {
+		err = fmt.Errorf("the repository root is not a directory")
+		return
+	}
+	var ok bool
+	if ret, ok = util.ResolveBelow(ret, allowed); ok {
+		return
+	}
+	err = fmt.Errorf("%s is not below a directory of %s", root, CodeRootsEnv)
+	return
+}
+
+// CorpusOptions selects the files of a corpus. The globs use the .gitignore syntax: "*.go" matches at any
+// depth, "src/**/*.ts" below src only, "docs/" a directory.
+type CorpusOptions struct {
+	Include []string `json:"include"` // all the files when empty
+	Exclude []string `json:"exclude"`
+	// MaxTokens is the budget of the corpus, see textfile.EstimateTokens. The files not fitting are omitted.
+	MaxTokens int `json:"maxTokens"`
+}
+
+// Corpus is the selected files of a repository concatenated under their paths
+type Corpus struct {
+	Content string   `json:"content"`
+	Files   []string `json:"files"`
+	Omitted []string `json:"omitted"` // selected but over the token budget
+	Tokens  int      `json:"tokens"`
+}
+
+// BuildCorpus concatenates the files of the repository matching the include globs and none of the exclude
+// globs, in path order, each under a "==> path <==" header, after the tree of the files. The files
+// skipped by Collect are never included. Files are added while they fit in the token budget, the ones
+// not fitting are omitted and listed.
+func BuildCorpus(root string, options CorpusOptions) (ret *Corpus, err error) {
+	var files []File
+	if files, _, err = Collect(root); err != nil {
+		return
+	}
+	include := parseGlobs(options.Include)
+	exclude := parseGlobs(options.Exclude)
+	maxTokens := options.MaxTokens
+	if maxTokens <= 0 {
+		maxTokens = DefaultCorpusTokens
+	}
+
+	var selected []File
+	for _, file := range files {
+		parts := strings.Split(file.Path, "/")
+		if (len(include) == 0 || matchesAny(include, parts)) && !matchesAny(exclude, parts) {
+			selected = append(selected, file)
+		}
+	}
+	if len(selected) == 0 {
+		err = fmt.Errorf("no files of %s match the globs", root)
+		return
+	}
+
+	ret = &Corpus{Files: []string{}, Omitted: []string{}}
+	var sections []string
+	tokens := 0
+	for _, file := range selected {
+		section := fmt.Sprintf("==> %s <==\n%s\n", file.Path, strings.TrimRight(file.Content, "\n"))
+		sectionTokens := textfile.EstimateTokens(section)
+		// the tree lists the file too
+		pathTokens := textfile.EstimateTokens(file.Path + "\n")
+		if tokens+sectionTokens+pathTokens > maxTokens {
+			ret.Omitted = append(ret.Omitted, file.Path)
+			continue
+		}
+		tokens += sectionTokens + pathTokens
+		sections = append(sections, section)
+		ret.Files = append(ret.Files, file.Path)
+	}
+	if len(ret.Files) == 0 {
+		err = fmt.Errorf("none of the %d selected files fits in %d tokens", len(selected), maxTokens)
+		return
+	}
+
+	var builder strings.Builder
+	fmt.Fprintf(&builder, "Files of %s (%d", filepath.Base(filepath.Clean(root)), len(ret.Files))
+	if len(ret.Omitted) > 0 {
+		fmt.Fprintf(&builder, ", %d omitted over the budget of %d tokens", len(ret.Omitted), maxTokens)
+	}
+	builder.WriteString("):\n")
+	kept := make([]File, len(ret.Files))
+	for i, path := range ret.Files {
+		kept[i] = File{Path: path}
+	}
+	builder.WriteString(Tree(kept))
+	for _, section := range sections {
+		builder.WriteString("\n")
+		builder.WriteString(section)
+	}
+	ret.Content = builder.String()
+	ret.Tokens = textfile.EstimateTokens(ret.Content)
+	return
+}
+
+func parseGlobs(globs []string) (ret []gitignore.Pattern) {
+	for _, glob := range globs {
+		if glob = strings.TrimSpace(glob); glob != "" {
+			ret = append(ret, gitignore.ParsePattern(glob, nil))
+		}
+	}
+	return
+}
+
+// matchesAny tells whether a glob matches the file or one of its directories
+func matchesAny(patterns []gitignore.Pattern, parts []string) bool {
+	for _, pattern := range patterns {
+		for i := 1; i <= len(parts); i++ {
+			if pattern.Match(parts[:i], i < len(parts)) == gitignore.Exclude {
+				return true
+			}
+		}
+	}
+	return false
+}
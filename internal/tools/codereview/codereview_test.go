@@ -0,0 +1,107 @@
+package codereview
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"sync/atomic"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const sampleDiff = `diff --git a/main.go b/main.go
+index 1111111..2222222 100644
+--- a/main.go
++++ b/main.go
+@@ -10,3 +12,4 @@ func main() {
+ 	a := 1
++	b := 2
+ 	c := 3
+diff --git a/old.go b/old.go
+deleted file mode 100644
+--- a/old.go
++++ /dev/null
+@@ -1,2 +0,0 @@
+-package old
+-
+diff --git a/logo.png b/logo.png
+Binary files a/logo.png and b/logo.png differ
+diff --git a/run.sh b/run.sh
+old mode 100644
+new mode 100755
+diff --git a/pkg/new.go b/pkg/new.go
+new file mode 100644
+--- /dev/null
++++ b/pkg/new.go
+@@ -0,0 +1,2 @@
++package pkg
++
+`
+
+func TestSplitDiff(t *testing.T) {
+	files := SplitDiff(sampleDiff)
+	require.Len(t, files, 2)
+
+	assert.Equal(t, "main.go", files[0].Path)
+	assert.Equal(t, 12, files[0].FirstLine)
+	assert.True(t, strings.HasPrefix(files[0].Patch, "diff --git a/main.go b/main.go\n"))
+	assert.True(t, strings.HasSuffix(files[0].Patch, " \tc := 3\n"))
+
+	assert.Equal(t, "pkg/new.go", files[1].Path)
+	assert.Equal(t, 1, files[1].FirstLine)
+}
+
+func TestParseFindings(t *testing.T) {
+	file := FileDiff{Path: "main.go", FirstLine: 12}
+	output := "```json\n[{\"line\": 13, \"severity\": \"Major\", \"title\": \"Unused b\"}, {\"severity\": \"nit\", \"title\": \"Naming\"}]\n```"
+
+	findings := ParseFindings(file, output)
+	require.Len(t, findings, 2)
+	assert.Equal(t, Finding{Path: "main.go", Line: 13, Severity: "high", Title: "Unused b"}, findings[0])
+	assert.Equal(t, 12, findings[1].Line)
+	assert.Equal(t, "low", findings[1].Severity)
+
+	assert.Empty(t, ParseFindings(file, "[]"))
+
+	unstructured := ParseFindings(file, "Looks good overall.")
+	require.Len(t, unstructured, 1)
+	assert.Equal(t, "info", unstructured[0].Severity)
+	assert.Equal(t, "Looks good overall.", unstructured[0].Detail)
+}
+
+func TestReviewAndReport(t *testing.T) {
+	files := []FileDiff{{Path: "b.go", FirstLine: 1}, {Path: "a.go", FirstLine: 5}, {Path: "c.go", FirstLine: 1}}
+	var running, maxRunning atomic.Int32
+	review := func(ctx context.Context, file FileDiff) (string, error) {
+		n := running.Add(1)
+		defer running.Add(-1)
+		for {
+			m := maxRunning.Load()
+			if n <= m || maxRunning.CompareAndSwap(m, n) {
+				break
+			}
+		}
+		if file.Path == "c.go" {
+			return "", errors.New("vendor down")
+		}
+		return `[{"severity": "critical", "title": "Bug in ` + file.Path + `"}]`, nil
+	}
+
+	results := Review(context.Background(), files, 2, review)
+	require.Len(t, results, 3)
+	assert.Equal(t, "b.go", results[0].Path)
+	assert.LessOrEqual(t, maxRunning.Load(), int32(2))
+
+	report := NewReport("/repo", "main..HEAD", results)
+	require.Len(t, report.Findings["critical"], 2)
+	assert.Equal(t, "a.go", report.Findings["critical"][0].Path)
+	assert.Equal(t, "vendor down", report.Failed["c.go"])
+
+	markdown := report.Markdown(DefaultEditorURL)
+	assert.Contains(t, markdown, "3 files reviewed in /repo: 2 critical.")
+	assert.Contains(t, markdown, "## Critical (2)\n\n- [a.go:5](vscode://file/repo/a.go:5) **Bug in a.go**\n")
+	assert.Contains(t, markdown, "## Not reviewed\n\n- c.go: vendor down\n")
+	assert.Contains(t, report.Markdown(""), "- a.go:5 **Bug in a.go**")
+}
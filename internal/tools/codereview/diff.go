@@ -0,0 +1,85 @@
+package codereview
+
+import (
+	"bytes"
+	"fmt"
+	"os/exec"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// FileDiff is the diff of one file of a commit range
+type FileDiff struct {
+	Path string
+	// Patch is the diff of the file in unified format, headers included
+	Patch string
+	// FirstLine is the first changed line of the file, 0 if unknown
+	FirstLine int
+}
+
+var hunkHeader = regexp.MustCompile(`^@@ -\d+(?:,\d+)? \+(\d+)(?:,\d+)? @@`)
+
+// Diff returns the diffs of the files changed in the commit range of the repository, like "main..HEAD"
+// or "HEAD~3". Deleted and binary files are left out, there is nothing to review.
+func Diff(repo string, commitRange string) (ret []FileDiff, err error) {
+	cmd := exec.Command("git", "-C", repo, "diff", "--no-color", "--no-ext-diff", "-U5", commitRange, "--")
+	var stderr bytes.Buffer
+	cmd.Stderr = &stderr
+	var out []byte
+	if out, err = cmd.Output(); err != nil {
+		err = fmt.Errorf("git diff %s in %s failed: %v %s", commitRange, repo, err, strings.TrimSpace(stderr.String()))
+		return
+	}
+	ret = SplitDiff(string(out))
+	return
+}
+
+// SplitDiff splits the output of git diff per file
+func SplitDiff(diff string) (ret []FileDiff) {
+	var current *FileDiff
+	var patch strings.Builder
+	skip := false
+
+	flush := func() {
+		// mode changes and renames have no hunk, nothing to review either
+		if current != nil && !skip && current.FirstLine > 0 {
+			current.Patch = patch.String()
+			ret = append(ret, *current)
+		}
+		current, skip = nil, false
+		patch.Reset()
+	}
+
+	for _, line := range strings.SplitAfter(diff, "\n") {
+		trimmed := strings.TrimRight(line, "\n")
+		switch {
+		case strings.HasPrefix(trimmed, "diff --git "):
+			flush()
+			current = &FileDiff{}
+			// a/old b/new, the path is taken from the +++ line when there is one
+			if i := strings.LastIndex(trimmed, " b/"); i >= 0 {
+				current.Path = trimmed[i+3:]
+			}
+		case current == nil:
+			continue
+		case strings.HasPrefix(trimmed, "+++ "):
+			if trimmed == "+++ /dev/null" {
+				skip = true
+			} else {
+				current.Path = strings.TrimPrefix(strings.TrimPrefix(trimmed, "+++ "), "b/")
+			}
+		case strings.HasPrefix(trimmed, "Binary files "):
+			skip = true
+		case current.FirstLine == 0:
+			if match := hunkHeader.FindStringSubmatch(trimmed); match != nil {
+				current.FirstLine, _ = strconv.Atoi(match[1])
+			}
+		}
+		if current != nil {
+			patch.WriteString(line)
+		}
+	}
+	flush()
+	return
+}
@@ -0,0 +1,121 @@
+package codereview
+
+import (
+	"fmt"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// DefaultEditorURL opens a file at a line in VS Code. {{path}} is the absolute path, slash separated and
+// starting with a slash also on Windows, {{line}} the line.
+const DefaultEditorURL = "vscode://file{{path}}:{{line}}"
+
+// Report aggregates the findings of the reviewed files
+type Report struct {
+	Repo     string               `json:"repo"`
+	Range    string               `json:"range"`
+	Files    int                  `json:"files"`
+	Findings map[string][]Finding `json:"findings"`         // by severity
+	Failed   map[string]string    `json:"failed,omitempty"` // errors by path of the files that could not be reviewed
+}
+
+// NewReport groups the findings by severity, sorted by path and line within a severity
+func NewReport(repo string, commitRange string, results []FileResult) (ret *Report) {
+	ret = &Report{Repo: repo, Range: commitRange, Files: len(results), Findings: map[string][]Finding{}}
+	for _, result := range results {
+		if result.Err != nil {
+			if ret.Failed == nil {
+				ret.Failed = map[string]string{}
+			}
+			ret.Failed[result.Path] = result.Err.Error()
+			continue
+		}
+		for _, finding := range result.Findings {
+			ret.Findings[finding.Severity] = append(ret.Findings[finding.Severity], finding)
+		}
+	}
+	for _, findings := range ret.Findings {
+		sort.SliceStable(findings, func(i, j int) bool {
+			if findings[i].Path != findings[j].Path {
+				return findings[i].Path < findings[j].Path
+			}
+			return findings[i].Line < findings[j].Line
+		})
+	}
+	return
+}
+
+// Markdown renders the report with a section per severity. Each finding links to its line through the
+// editor URL, see DefaultEditorURL, no links with an empty editor URL.
+func (o *Report) Markdown(editorURL string) string {
+	var builder strings.Builder
+	fmt.Fprintf(&builder, "# Code review of %s\n\n", o.Range)
+
+	var counts []string
+	total := 0
+	for _, severity := range Severities {
+		if n := len(o.Findings[severity]); n > 0 {
+			counts = append(counts, fmt.Sprintf("%d %s", n, severity))
+			total += n
+		}
+	}
+	fmt.Fprintf(&builder, "%d files reviewed in %s", o.Files, o.Repo)
+	if total > 0 {
+		fmt.Fprintf(&builder, ": %s.\n", strings.Join(counts, ", "))
+	} else {
+		builder.WriteString(", no findings.\n")
+	}
+
+	for _, severity := range Severities {
+		findings := o.Findings[severity]
+		if len(findings) == 0 {
+			continue
+		}
+		fmt.Fprintf(&builder, "\n## %s%s (%d)\n\n", strings.ToUpper(severity[:1]), severity[1:], len(findings))
+		for _, finding := range findings {
+			location := fmt.Sprintf("%s:%d", finding.Path, finding.Line)
+			if link := o.editorLink(editorURL, finding); link != "" {
+				location = fmt.Sprintf("[%s](%s)", location, link)
+			}
+			fmt.Fprintf(&builder, "- %s **%s**", location, finding.Title)
+			if finding.Detail != "" {
+				fmt.Fprintf(&builder, ": %s", indent(finding.Detail))
+			}
+			builder.WriteString("\n")
+			if finding.Suggestion != "" {
+				fmt.Fprintf(&builder, "  - Suggestion: %s\n", indent(finding.Suggestion))
+			}
+		}
+	}
+
+	if len(o.Failed) > 0 {
+		paths := make([]string, 0, len(o.Failed))
+		for path := range o.Failed {
+			paths = append(paths, path)
+		}
+		sort.Strings(paths)
+		builder.WriteString("\n## Not reviewed\n\n")
+		for _, path := range paths {
+			fmt.Fprintf(&builder, "- %s: %s\n", path, o.Failed[path])
+		}
+	}
+	return builder.String()
+}
+
+func (o *Report) editorLink(editorURL string, finding Finding) string {
+	if editorURL == "" {
+		return ""
+	}
+	path := filepath.ToSlash(filepath.Join(o.Repo, filepath.FromSlash(finding.Path)))
+	if !strings.HasPrefix(path, "/") {
+		path = "/" + path
+	}
+	return strings.NewReplacer("{{path}}", path, "{{line}}", strconv.Itoa(finding.Line)).Replace(editorURL)
+}
+
+// indent keeps multi-line text within its list item
+func indent(text string) string {
+	return strings.ReplaceAll(strings.TrimSpace(text), "\n", "\n  ")
+}
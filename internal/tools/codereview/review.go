@@ -0,0 +1,114 @@
+package codereview
+
+import (
+	"context"
+	"encoding/json"
+	"strings"
+	"sync"
+)
+
+// DefaultConcurrency is the number of files reviewed at the same time
+const DefaultConcurrency = 4
+
+// Severities from the most to the least severe, findings of another severity are counted as info
+var Severities = []string{"critical", "high", "medium", "low", "info"}
+
+// Finding is an issue the review pattern found in a file
+type Finding struct {
+	Path       string `json:"path"`
+	Line       int    `json:"line"`
+	Severity   string `json:"severity"`
+	Title      string `json:"title"`
+	Detail     string `json:"detail,omitempty"`
+	Suggestion string `json:"suggestion,omitempty"`
+}
+
+// FileResult is the review of a file, Err is set when it could not be reviewed
+type FileResult struct {
+	Path     string
+	Findings []Finding
+	Err      error
+}
+
+// ReviewFunc runs the review pattern on the diff of a file and returns its output
+type ReviewFunc func(ctx context.Context, file FileDiff) (output string, err error)
+
+// Review reviews the files with at most concurrency reviews at a time, the results are in the order of the files
+func Review(ctx context.Context, files []FileDiff, concurrency int, review ReviewFunc) (ret []FileResult) {
+	if concurrency <= 0 {
+		concurrency = DefaultConcurrency
+	}
+	ret = make([]FileResult, len(files))
+
+	indexes := make(chan int)
+	var wg sync.WaitGroup
+	for range min(concurrency, len(files)) {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range indexes {
+				ret[i] = reviewFile(ctx, files[i], review)
+			}
+		}()
+	}
+	for i := range files {
+		indexes <- i
+	}
+	close(indexes)
+	wg.Wait()
+	return
+}
+
+func reviewFile(ctx context.Context, file FileDiff, review ReviewFunc) (ret FileResult) {
+	ret.Path = file.Path
+	if ret.Err = ctx.Err(); ret.Err != nil {
+		return
+	}
+	var output string
+	if output, ret.Err = review(ctx, file); ret.Err == nil {
+		ret.Findings = ParseFindings(file, output)
+	}
+	return
+}
+
+// ParseFindings reads the JSON array of findings the review pattern outputs, code fences and text around
+// it are ignored. The findings get the path of the file and, without a line, its first changed line.
+// Output that isn't a JSON array is kept as a single info finding, so nothing the model said is lost.
+func ParseFindings(file FileDiff, output string) (ret []Finding) {
+	start, end := strings.Index(output, "["), strings.LastIndex(output, "]")
+	if start < 0 || end < start || json.Unmarshal([]byte(output[start:end+1]), &ret) != nil {
+		if text := strings.TrimSpace(output); text != "" {
+			ret = []Finding{{Path: file.Path, Line: file.FirstLine, Severity: "info", Title: "Review", Detail: text}}
+		}
+		return
+	}
+
+	for i := range ret {
+		ret[i].Path = file.Path
+		if ret[i].Line <= 0 {
+			ret[i].Line = file.FirstLine
+		}
+		ret[i].Severity = normalizeSeverity(ret[i].Severity)
+	}
+	return
+}
+
+func normalizeSeverity(severity string) string {
+	severity = strings.ToLower(strings.TrimSpace(severity))
+	switch severity {
+	case "blocker":
+		return "critical"
+	case "major", "error":
+		return "high"
+	case "minor", "warning":
+		return "medium"
+	case "nit", "style":
+		return "low"
+	}
+	for _, known := range Severities {
+		if severity == known {
+			return severity
+		}
+	}
+	return "info"
+}
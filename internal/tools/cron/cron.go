@@ -0,0 +1,152 @@
+// Package cron parses standard five field cron expressions ("minute hour day-of-month month day-of-week")
+// and computes when they fire next.
+package cron
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Schedule is a parsed cron expression
+type Schedule struct {
+	minutes  uint64
+	hours    uint64
+	days     uint64
+	months   uint64
+	weekdays uint64
+
+	// cron fires when either day field matches if both are restricted, a field starting with "*" or
+	// covering all the days is not
+	daysRestricted     bool
+	weekdaysRestricted bool
+}
+
+// the days of month 1 to 31 and the weekdays 0 to 6
+const (
+	allDays     uint64 = (1<<32 - 1) &^ 1
+	allWeekdays uint64 = 1<<7 - 1
+)
+
+var shortcuts = map[string]string{
+	"@yearly":   "0 0 1 1 *",
+	"@annually": "0 0 1 1 *",
+	"@monthly":  "0 0 1 * *",
+	"@weekly":   "0 0 * * 0",
+	"@daily":    "0 0 * * *",
+	"@midnight": "0 0 * * *",
+	"@hourly":   "0 * * * *",
+}
+
+// Parse parses a five field cron expression. Fields support "*", lists ("1,15"), ranges ("1-5")
+// and steps ("*/10", "0-30/5"); the @daily style shortcuts are accepted as well.
+func Parse(spec string) (ret *Schedule, err error) {
+	spec = strings.TrimSpace(spec)
+	if expanded, ok := shortcuts[spec]; ok {
+		spec = expanded
+	}
+
+	fields := strings.Fields(spec)
+	if len(fields) != 5 {
+		err = fmt.Errorf("invalid cron expression %q: expected 5 fields, got %d", spec, len(fields))
+		return
+	}
+
+	ret = &Schedule{}
+	if ret.minutes, err = parseField(fields[0], 0, 59); err != nil {
+		return nil, fmt.Errorf("invalid minute field: %v", err)
+	}
+	if ret.hours, err = parseField(fields[1], 0, 23); err != nil {
+		return nil, fmt.Errorf("invalid hour field: %v", err)
+	}
+	if ret.days, err = parseField(fields[2], 1, 31); err != nil {
+		return nil, fmt.Errorf("invalid day of month field: %v", err)
+	}
+	if ret.months, err = parseField(fields[3], 1, 12); err != nil {
+		return nil, fmt.Errorf("invalid month field: %v", err)
+	}
+	if ret.weekdays, err = parseField(fields[4], 0, 7); err != nil {
+		return nil, fmt.Errorf("invalid day of week field: %v", err)
+	}
+	// both 0 and 7 are Sunday
+	if ret.weekdays&(1<<7) != 0 {
+		ret.weekdays |= 1
+	}
+	ret.daysRestricted = !strings.HasPrefix(fields[2], "*") && ret.days != allDays
+	ret.weekdaysRestricted = !strings.HasPrefix(fields[4], "*") && ret.weekdays&allWeekdays != allWeekdays
+	return
+}
+
+// Next returns the first time after t the schedule fires, or the zero time if it never does
+func (o *Schedule) Next(t time.Time) time.Time {
+	t = t.Truncate(time.Minute).Add(time.Minute)
+	limit := t.AddDate(5, 0, 0)
+
+	for t.Before(limit) {
+		if o.months&(1<<uint(t.Month())) == 0 {
+			t = time.Date(t.Year(), t.Month()+1, 1, 0, 0, 0, 0, t.Location())
+			continue
+		}
+		if !o.dayMatches(t) {
+			t = time.Date(t.Year(), t.Month(), t.Day()+1, 0, 0, 0, 0, t.Location())
+			continue
+		}
+		if o.hours&(1<<uint(t.Hour())) == 0 {
+			t = time.Date(t.Year(), t.Month(), t.Day(), t.Hour()+1, 0, 0, 0, t.Location())
+			continue
+		}
+		if o.minutes&(1<<uint(t.Minute())) == 0 {
+			t = t.Add(time.Minute)
+			continue
+		}
+		return t
+	}
+	return time.Time{}
+}
+
+func (o *Schedule) dayMatches(t time.Time) bool {
+	dayMatch := o.days&(1<<uint(t.Day())) != 0
+	weekdayMatch := o.weekdays&(1<<uint(t.Weekday())) != 0
+	if o.daysRestricted && o.weekdaysRestricted {
+		return dayMatch || weekdayMatch
+	}
+	return dayMatch && weekdayMatch
+}
+
+func parseField(field string, min, max int) (ret uint64, err error) {
+	for _, part := range strings.Split(field, ",") {
+		step := 1
+		if rangePart, stepPart, found := strings.Cut(part, "/"); found {
+			if step, err = strconv.Atoi(stepPart); err != nil || step < 1 {
+				return 0, fmt.Errorf("invalid step %q", stepPart)
+			}
+			part = rangePart
+		}
+
+		start, end := min, max
+		if part != "*" {
+			startPart, endPart, isRange := strings.Cut(part, "-")
+			if start, err = strconv.Atoi(startPart); err != nil {
+				return 0, fmt.Errorf("invalid value %q", startPart)
+			}
+			end = start
+			if isRange {
+				if end, err = strconv.Atoi(endPart); err != nil {
+					return 0, fmt.Errorf("invalid value %q", endPart)
+				}
+			} else if step > 1 {
+				// "5/15" means from 5 to the maximum every 15
+				end = max
+			}
+		}
+		if start < min || end > max || start > end {
+			return 0, fmt.Errorf("%q is out of range %d-%d", part, min, max)
+		}
+
+		for value := start; value <= end; value += step {
+			ret |= 1 << uint(value)
+		}
+	}
+	return
+}
@@ -0,0 +1,56 @@
+package cron
+
+import (
+	"testing"
+	"time"
+)
+
+func TestScheduleNext(t *testing.T) {
+	// Friday
+	base := time.Date(2024, 3, 15, 10, 7, 30, 0, time.UTC)
+
+	tests := []struct {
+		spec     string
+		expected time.Time
+	}{
+		{"* * * * *", time.Date(2024, 3, 15, 10, 8, 0, 0, time.UTC)},
+		{"*/15 * * * *", time.Date(2024, 3, 15, 10, 15, 0, 0, time.UTC)},
+		{"0 9 * * *", time.Date(2024, 3, 16, 9, 0, 0, 0, time.UTC)},
+		{"30 8 * * 1-5", time.Date(2024, 3, 18, 8, 30, 0, 0, time.UTC)},
+		{"0 0 1 * *", time.Date(2024, 4, 1, 0, 0, 0, 0, time.UTC)},
+		{"0 12 * * 7", time.Date(2024, 3, 17, 12, 0, 0, 0, time.UTC)},
+		{"0 0 31 * 1", time.Date(2024, 3, 18, 0, 0, 0, 0, time.UTC)},
+		{"@weekly", time.Date(2024, 3, 17, 0, 0, 0, 0, time.UTC)},
+		{"0 0 29 2 *", time.Date(2028, 2, 29, 0, 0, 0, 0, time.UTC)},
+		// a day field starting with "*" or covering all the days isn't restricted, the other one applies alone
+		{"0 0 */1 * 1", time.Date(2024, 3, 18, 0, 0, 0, 0, time.UTC)},
+		{"0 0 1-31 * 1", time.Date(2024, 3, 18, 0, 0, 0, 0, time.UTC)},
+		{"0 0 */2 * 1", time.Date(2024, 3, 25, 0, 0, 0, 0, time.UTC)},
+		{"0 0 1 * */1", time.Date(2024, 4, 1, 0, 0, 0, 0, time.UTC)},
+		{"0 0 1 * 0-6", time.Date(2024, 4, 1, 0, 0, 0, 0, time.UTC)},
+		{"0 0 1 * 1-7", time.Date(2024, 4, 1, 0, 0, 0, 0, time.UTC)},
+		// both restricted, either one fires
+		{"0 0 16 * 1", time.Date(2024, 3, 16, 0, 0, 0, 0, time.UTC)},
+		{"0 0 1-5 * 1", time.Date(2024, 3, 18, 0, 0, 0, 0, time.UTC)},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.spec, func(t *testing.T) {
+			schedule, err := Parse(tt.spec)
+			if err != nil {
+				t.Fatalf("Parse(%q) failed: %v", tt.spec, err)
+			}
+			if next := schedule.Next(base); !next.Equal(tt.expected) {
+				t.Errorf("Next() = %v, expected %v", next, tt.expected)
+			}
+		})
+	}
+}
+
+func TestParseInvalid(t *testing.T) {
+	for _, spec := range []string{"", "* * * *", "60 * * * *", "* 24 * * *", "*/0 * * * *", "5-1 * * * *", "a * * * *"} {
+		if _, err := Parse(spec); err == nil {
+			t.Errorf("expected Parse(%q) to fail", spec)
+		}
+	}
+}
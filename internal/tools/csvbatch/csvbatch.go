@@ -0,0 +1,134 @@
+package csvbatch
+
+import (
+	"context"
+	"encoding/csv"
+	"fmt"
+	"io"
+	"path/filepath"
+	"strings"
+	"sync"
+
+	"github.com/danielmiessler/fabric/internal/domain"
+)
+
+// DefaultConcurrency is the number of rows run at the same time
+const DefaultConcurrency = 4
+
+// Table is a CSV file, the first line names the columns
+type Table struct {
+	Header []string
+	Rows   [][]string
+}
+
+// Read reads the CSV, every row must have the columns of the header
+func Read(r io.Reader) (ret *Table, err error) {
+	var records [][]string
+	if records, err = csv.NewReader(r).ReadAll(); err != nil {
+		err = fmt.Errorf("invalid CSV: %v", err)
+		return
+	}
+	if len(records) == 0 {
+		err = fmt.Errorf("the CSV has no header")
+		return
+	}
+	ret = &Table{Header: records[0], Rows: records[1:]}
+	// the byte order mark spreadsheets write is not part of the first name
+	for i, name := range ret.Header {
+		ret.Header[i] = strings.TrimSpace(strings.TrimPrefix(name, "\ufeff"))
+	}
+	return
+}
+
+// Column returns the index of the column, -1 when there is none of the name
+func (o *Table) Column(name string) int {
+	for i, column := range o.Header {
+		if column == name {
+			return i
+		}
+	}
+	return -1
+}
+
+// Row returns the values of the row by column name
+func (o *Table) Row(index int) (ret map[string]string) {
+	ret = make(map[string]string, len(o.Header))
+	for i, name := range o.Header {
+		ret[name] = o.Rows[index][i]
+	}
+	return
+}
+
+// SetColumn sets the values of the column, it is added after the others when there is none of the name
+func (o *Table) SetColumn(name string, values []string) {
+	column := o.Column(name)
+	if column < 0 {
+		o.Header = append(o.Header, name)
+		column = len(o.Header) - 1
+	}
+	for i := range o.Rows {
+		for len(o.Rows[i]) <= column {
+			o.Rows[i] = append(o.Rows[i], "")
+		}
+		o.Rows[i][column] = values[i]
+	}
+}
+
+func (o *Table) Write(w io.Writer) (err error) {
+	writer := csv.NewWriter(w)
+	if err = writer.Write(o.Header); err != nil {
+		return
+	}
+	if err = writer.WriteAll(o.Rows); err != nil {
+		return
+	}
+	return writer.Error()
+}
+
+// RowResult is the output of the pattern for a row, Err is set when the row could not be run. Metadata
+// is the one of the model call, when it was made.
+type RowResult struct {
+	Output   string
+	Metadata *domain.ExecutionMetadata
+	Err      error
+}
+
+// RowFunc runs the pattern for the row, by column name, and returns its output with the metadata of the
+// model call
+type RowFunc func(ctx context.Context, index int, row map[string]string) (output string, metadata *domain.ExecutionMetadata, err error)
+
+// Run runs the rows with at most concurrency rows at a time, the results are in the order of the rows.
+// A failed row doesn't stop the others.
+func Run(ctx context.Context, table *Table, concurrency int, run RowFunc) (ret []RowResult) {
+	if concurrency <= 0 {
+		concurrency = DefaultConcurrency
+	}
+	ret = make([]RowResult, len(table.Rows))
+
+	indexes := make(chan int)
+	var wg sync.WaitGroup
+	for range min(concurrency, len(table.Rows)) {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range indexes {
+				if ret[i].Err = ctx.Err(); ret[i].Err == nil {
+					ret[i].Output, ret[i].Metadata, ret[i].Err = run(ctx, i, table.Row(i))
+				}
+			}
+		}()
+	}
+	for i := range table.Rows {
+		indexes <- i
+	}
+	close(indexes)
+	wg.Wait()
+	return
+}
+
+// RowFile returns the path of the output file of the row in the directory, numbered from 1 and padded so
+// the files sort in the order of the rows
+func RowFile(dir string, index int, rows int) string {
+	width := len(fmt.Sprint(rows))
+	return filepath.Join(dir, fmt.Sprintf("row-%0*d.md", width, index+1))
+}
@@ -0,0 +1,147 @@
+package csvbatch
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"strings"
+	"sync/atomic"
+	"testing"
+
+	"github.com/danielmiessler/fabric/internal/domain"
+	"github.com/danielmiessler/fabric/internal/plugins/db/fsdb"
+	"github.com/danielmiessler/fabric/internal/tools/pricing"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const sampleCSV = "\ufeffclient, input\nAcme,\"first, with a comma\"\nGlobex,second\n"
+
+func TestRead(t *testing.T) {
+	table, err := Read(strings.NewReader(sampleCSV))
+	require.NoError(t, err)
+	assert.Equal(t, []string{"client", "input"}, table.Header)
+	assert.Equal(t, map[string]string{"client": "Acme", "input": "first, with a comma"}, table.Row(0))
+	assert.Equal(t, 1, table.Column("input"))
+	assert.Equal(t, -1, table.Column("output"))
+
+	_, err = Read(strings.NewReader(""))
+	assert.Error(t, err)
+	_, err = Read(strings.NewReader("a,b\n1\n"))
+	assert.Error(t, err)
+}
+
+func TestRunAndWrite(t *testing.T) {
+	table, err := Read(strings.NewReader(sampleCSV))
+	require.NoError(t, err)
+
+	var running, maxRunning atomic.Int32
+	results := Run(context.Background(), table, 1, func(ctx context.Context, index int, row map[string]string) (string, *domain.ExecutionMetadata, error) {
+		if n := running.Add(1); n > maxRunning.Load() {
+			maxRunning.Store(n)
+		}
+		defer running.Add(-1)
+		if row["client"] == "Globex" {
+			return "", nil, errors.New("quota exceeded")
+		}
+		return "report for " + row["client"], &domain.ExecutionMetadata{Model: "gpt-4o"}, nil
+	})
+	require.Len(t, results, 2)
+	assert.Equal(t, int32(1), maxRunning.Load())
+	assert.Equal(t, "report for Acme", results[0].Output)
+	assert.Equal(t, "gpt-4o", results[0].Metadata.Model)
+	assert.EqualError(t, results[1].Err, "quota exceeded")
+
+	table.SetColumn("output", []string{results[0].Output, ""})
+	var out bytes.Buffer
+	require.NoError(t, table.Write(&out))
+	assert.Equal(t, "client,input,output\nAcme,\"first, with a comma\",report for Acme\nGlobex,second,\n", out.String())
+
+	table.SetColumn("input", []string{"replaced", "too"})
+	assert.Equal(t, "replaced", table.Row(0)["input"])
+	assert.Len(t, table.Header, 3)
+}
+
+func TestRowFile(t *testing.T) {
+	assert.Equal(t, "out/row-001.md", RowFile("out", 0, 120))
+	assert.Equal(t, "out/row-9.md", RowFile("out", 8, 9))
+}
+
+func TestNewManifest(t *testing.T) {
+	results := []RowResult{
+		{Output: "a", Metadata: &domain.ExecutionMetadata{Model: "gpt-4o", Usage: domain.Usage{PromptTokens: 1000, CompletionTokens: 500}}},
+		{Err: errors.New("quota exceeded")},
+		{Output: "c", Metadata: &domain.ExecutionMetadata{Model: "llama3", Usage: domain.Usage{PromptTokens: 10, CompletionTokens: 5}}},
+	}
+	prices := &pricing.Prices{Models: map[string]pricing.Price{"gpt-4o": {Input: 2, Output: 10}}}
+
+	manifest := NewManifest(results, func(index int) string { return RowFile("out", index, 3) }, prices)
+	require.Len(t, manifest.Items, 3)
+	assert.Equal(t, 2, manifest.Succeeded)
+	assert.Equal(t, 1, manifest.Failed)
+	assert.Equal(t, ManifestItem{Row: 1, Status: StatusOK, OutputPath: "out/row-1.md", Model: "gpt-4o",
+		Tokens: ManifestTokens{Prompt: 1000, Completion: 500}, Cost: manifest.Items[0].Cost}, manifest.Items[0])
+	assert.InDelta(t, 0.007, *manifest.Items[0].Cost, 1e-9)
+	assert.Equal(t, ManifestItem{Row: 2, Status: StatusFailed, Error: "quota exceeded"}, manifest.Items[1])
+	assert.Nil(t, manifest.Items[2].Cost)
+	assert.Equal(t, ManifestTokens{Prompt: 1010, Completion: 505}, manifest.TotalTokens)
+	assert.InDelta(t, 0.007, *manifest.TotalCost, 1e-9)
+
+	manifest = NewManifest(results[1:], nil, nil)
+	assert.Empty(t, manifest.Items[1].OutputPath)
+	assert.Nil(t, manifest.TotalCost)
+}
+
+func TestEstimateAndLearn(t *testing.T) {
+	rows := []RowPrompt{
+		{Prompt: strings.Repeat("a", 4000), Input: strings.Repeat("a", 3000)},
+		{Prompt: strings.Repeat("a", 2000), Input: strings.Repeat("a", 1000)},
+	}
+	prices := &pricing.Prices{Models: map[string]pricing.Price{"gpt-4o": {Input: 2, Output: 10}}}
+
+	estimate := NewEstimate("summarize", rows, 0, nil, "gpt-4o", prices)
+	assert.Equal(t, 2, estimate.Rows)
+	assert.Equal(t, 1500, estimate.PromptTokens)
+	// a fifth of the input, at least 150 tokens a row
+	assert.Equal(t, 150+150, estimate.CompletionTokens)
+	require.NotNil(t, estimate.Cost)
+	assert.InDelta(t, (1500*2+300*10)/1e6, *estimate.Cost, 1e-9)
+	assert.False(t, estimate.Calibrated)
+
+	assert.Equal(t, 2*100, NewEstimate("improve_writing", rows, 100, nil, "", nil).CompletionTokens)
+	assert.Nil(t, NewEstimate("summarize", rows, 0, nil, "unpriced", prices).Cost)
+
+	calibrations := Calibrations{}
+	results := []RowResult{
+		{Metadata: &domain.ExecutionMetadata{Usage: domain.Usage{PromptTokens: 1200, CompletionTokens: 400}}},
+		{Err: errors.New("failed")},
+	}
+	actual, ok := calibrations.Learn("summarize", estimate, results)
+	require.True(t, ok)
+	assert.Equal(t, ManifestTokens{Prompt: 1200, Completion: 400}, actual)
+	assert.Equal(t, &Calibration{PromptRatio: 1.2, CompletionTokens: 400, Batches: 1}, calibrations["summarize"])
+
+	calibrated := NewEstimate("summarize", rows, 0, calibrations["summarize"], "gpt-4o", prices)
+	assert.True(t, calibrated.Calibrated)
+	assert.Equal(t, 1800, calibrated.PromptTokens)
+	assert.Equal(t, 800, calibrated.CompletionTokens)
+
+	// the last batch counts for half
+	calibrations.Learn("summarize", estimate, []RowResult{{Metadata: &domain.ExecutionMetadata{Usage: domain.Usage{PromptTokens: 1000, CompletionTokens: 200}}}})
+	assert.InDelta(t, 1.1, calibrations["summarize"].PromptRatio, 1e-9)
+	assert.InDelta(t, 300, calibrations["summarize"].CompletionTokens, 1e-9)
+	assert.Equal(t, 2, calibrations["summarize"].Batches)
+
+	_, ok = calibrations.Learn("summarize", estimate, []RowResult{{Err: errors.New("failed")}})
+	assert.False(t, ok)
+
+	store := &fsdb.Store{Path: t.TempDir() + "/" + fsdb.StoreFileName}
+	defer store.Close()
+	missing, err := LoadCalibrations(store)
+	require.NoError(t, err)
+	assert.Empty(t, missing)
+	require.NoError(t, calibrations.Save(store, "summarize"))
+	loaded, err := LoadCalibrations(store)
+	require.NoError(t, err)
+	assert.Equal(t, calibrations, loaded)
+}
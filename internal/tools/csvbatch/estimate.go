@@ -0,0 +1,198 @@
+package csvbatch
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/danielmiessler/fabric/internal/plugins/db/fsdb"
+	"github.com/danielmiessler/fabric/internal/tools/pricing"
+	"github.com/danielmiessler/fabric/internal/tools/textfile"
+)
+
+// calibrationWeight is the weight of the last batch in a calibration, the older batches fade away
+const calibrationWeight = 0.5
+
+// RowPrompt is what a row sends: the prompt, with the input in it, and the input alone
+type RowPrompt struct {
+	Prompt string
+	Input  string
+}
+
+// Estimate is the tokens and cost a batch is expected to take, before it runs. The cost is only set when
+// the price of the model is known.
+type Estimate struct {
+	Rows             int
+	PromptTokens     int
+	CompletionTokens int
+	Cost             *float64
+	Calibrated       bool // corrected by the past batches of the pattern
+
+	rowPromptTokens []int // estimated from the length of the prompts only, what Learn compares to
+}
+
+// Calibration corrects the estimates of a pattern with its past batches: the ratio of the actual prompt
+// tokens to the ones estimated from the length of the prompts, and the completion tokens of a row
+type Calibration struct {
+	PromptRatio      float64 `json:"promptRatio"`
+	CompletionTokens float64 `json:"completionTokens"`
+	Batches          int     `json:"batches"`
+}
+
+// Calibrations are the calibrations by pattern, kept in the fsdb.BatchEstimatesCollection of the fabric store
+// the estimates learn from
+type Calibrations map[string]*Calibration
+
+// completionHeuristic guesses the completion of a row of the patterns starting with prefix from its input:
+// ratio times the input tokens, within min and max, a max of 0 leaving it unbounded
+type completionHeuristic struct {
+	prefix   string
+	ratio    float64
+	min, max int
+}
+
+// the patterns rewriting their input answer about as long, the ones summing it up shorter
+var completionHeuristics = []completionHeuristic{
+	{"summarize", 0.2, 150, 800},
+	{"summary", 0.2, 150, 800},
+	{"extract_", 0.3, 200, 1500},
+	{"analyze_", 0.4, 300, 1500},
+	{"create_", 0.5, 300, 2000},
+	{"improve_", 1, 100, 0},
+	{"rewrite", 1, 100, 0},
+	{"translate", 1.1, 100, 0},
+	{"clean", 1, 100, 0},
+	{"fix", 1, 100, 0},
+	{"humanize", 1, 100, 0},
+}
+
+var defaultCompletionHeuristic = completionHeuristic{ratio: 0.5, min: 200, max: 1500}
+
+// NewEstimate estimates the batch of the pattern from the prompts of its rows. The prompt tokens are
+// estimated from the length of the prompts, the completion tokens of a row from the kind of pattern and
+// the length of its input; both are corrected by the calibration of the pattern when it has one. maxTokens,
+// if set, caps the completion of a row. The calibration and the prices may be nil.
+func NewEstimate(pattern string, rows []RowPrompt, maxTokens int, calibration *Calibration, model string,
+	prices *pricing.Prices) (ret *Estimate) {
+
+	ret = &Estimate{Rows: len(rows), Calibrated: calibration != nil && calibration.Batches > 0}
+	heuristic := completionHeuristicOf(pattern)
+	var promptTokens float64
+	for _, row := range rows {
+		rowTokens := textfile.EstimateTokens(row.Prompt)
+		ret.rowPromptTokens = append(ret.rowPromptTokens, rowTokens)
+		promptTokens += float64(rowTokens)
+
+		completion := heuristic.tokens(textfile.EstimateTokens(row.Input))
+		if ret.Calibrated {
+			completion = int(calibration.CompletionTokens + 0.5)
+		}
+		if maxTokens > 0 && completion > maxTokens {
+			completion = maxTokens
+		}
+		ret.CompletionTokens += completion
+	}
+	if ret.Calibrated {
+		promptTokens *= calibration.PromptRatio
+	}
+	ret.PromptTokens = int(promptTokens + 0.5)
+
+	if cost, ok := prices.Cost(model, ret.PromptTokens, ret.CompletionTokens); ok {
+		ret.Cost = &cost
+	}
+	return
+}
+
+// String describes the estimate on a line
+func (o *Estimate) String() string {
+	var builder strings.Builder
+	fmt.Fprintf(&builder, "Estimated %d prompt and %d completion tokens for %d rows",
+		o.PromptTokens, o.CompletionTokens, o.Rows)
+	if o.Cost != nil {
+		fmt.Fprintf(&builder, ", $%.4f", *o.Cost)
+	} else {
+		builder.WriteString(", the model has no price")
+	}
+	if o.Calibrated {
+		builder.WriteString(", corrected by the past batches of the pattern")
+	}
+	return builder.String()
+}
+
+func completionHeuristicOf(pattern string) completionHeuristic {
+	for _, heuristic := range completionHeuristics {
+		if strings.HasPrefix(pattern, heuristic.prefix) {
+			return heuristic
+		}
+	}
+	return defaultCompletionHeuristic
+}
+
+func (o completionHeuristic) tokens(inputTokens int) (ret int) {
+	ret = int(float64(inputTokens)*o.ratio + 0.5)
+	if ret < o.min {
+		ret = o.min
+	}
+	if o.max > 0 && ret > o.max {
+		ret = o.max
+	}
+	return
+}
+
+// LoadCalibrations reads the calibrations of the store
+func LoadCalibrations(store *fsdb.Store) (ret Calibrations, err error) {
+	ret = Calibrations{}
+	var patterns []string
+	if patterns, err = store.Keys(fsdb.BatchEstimatesCollection); err != nil {
+		return
+	}
+	for _, pattern := range patterns {
+		calibration := &Calibration{}
+		if _, err = store.Get(fsdb.BatchEstimatesCollection, pattern, calibration); err != nil {
+			err = fmt.Errorf("invalid batch estimate of %s: %v", pattern, err)
+			return
+		}
+		ret[pattern] = calibration
+	}
+	return
+}
+
+// Save writes the calibration of the pattern to the store
+func (o Calibrations) Save(store *fsdb.Store, pattern string) (err error) {
+	if calibration := o[pattern]; calibration != nil {
+		err = store.Put(fsdb.BatchEstimatesCollection, pattern, calibration)
+	}
+	return
+}
+
+// Learn compares the estimate of a batch of the pattern to the tokens its rows reported, and corrects the
+// calibration of the pattern with them. The rows without usage, failed or of vendors not reporting it,
+// are left out. Returns the actual tokens of the rows with usage, ok is false when there were none.
+func (o Calibrations) Learn(pattern string, estimate *Estimate, results []RowResult) (actual ManifestTokens, ok bool) {
+	var rows, estimatedPrompt int
+	for i, result := range results {
+		if result.Err != nil || result.Metadata == nil || result.Metadata.PromptTokens == 0 ||
+			i >= len(estimate.rowPromptTokens) {
+			continue
+		}
+		rows++
+		estimatedPrompt += estimate.rowPromptTokens[i]
+		actual.Prompt += result.Metadata.PromptTokens
+		actual.Completion += result.Metadata.CompletionTokens
+	}
+	if rows == 0 || estimatedPrompt == 0 {
+		return
+	}
+	ok = true
+
+	promptRatio := float64(actual.Prompt) / float64(estimatedPrompt)
+	completionTokens := float64(actual.Completion) / float64(rows)
+	calibration := o[pattern]
+	if calibration == nil || calibration.Batches == 0 {
+		o[pattern] = &Calibration{PromptRatio: promptRatio, CompletionTokens: completionTokens, Batches: 1}
+		return
+	}
+	calibration.PromptRatio += calibrationWeight * (promptRatio - calibration.PromptRatio)
+	calibration.CompletionTokens += calibrationWeight * (completionTokens - calibration.CompletionTokens)
+	calibration.Batches++
+	return
+}
@@ -0,0 +1,89 @@
+package csvbatch
+
+import (
+	"encoding/json"
+	"os"
+	"time"
+
+	"github.com/danielmiessler/fabric/internal/tools/pricing"
+)
+
+const (
+	StatusOK     = "ok"
+	StatusFailed = "failed"
+)
+
+// Manifest lists the results of a batch for the scripts consuming them. The costs are only set when the
+// prices of the models are known, a total cost is the one of the rows with a known cost.
+type Manifest struct {
+	Source      string         `json:"source"`
+	Pattern     string         `json:"pattern"`
+	Output      string         `json:"output,omitempty"` // the CSV with the output column, or the directory of the files
+	StartedAt   time.Time      `json:"startedAt"`
+	FinishedAt  time.Time      `json:"finishedAt"`
+	Items       []ManifestItem `json:"items"`
+	Succeeded   int            `json:"succeeded"`
+	Failed      int            `json:"failed"`
+	TotalTokens ManifestTokens `json:"totalTokens"`
+	TotalCost   *float64       `json:"totalCostUsd,omitempty"`
+}
+
+// ManifestItem is the result of a row, numbered from 1 as in the spreadsheets
+type ManifestItem struct {
+	Row        int            `json:"row"`
+	Status     string         `json:"status"`
+	OutputPath string         `json:"outputPath,omitempty"` // the file of the output, empty when written to a column
+	Error      string         `json:"error,omitempty"`
+	Model      string         `json:"model,omitempty"`
+	LatencyMs  int64          `json:"latencyMs,omitempty"`
+	Tokens     ManifestTokens `json:"tokens"`
+	Cost       *float64       `json:"costUsd,omitempty"`
+}
+
+type ManifestTokens struct {
+	Prompt     int `json:"prompt"`
+	Completion int `json:"completion"`
+}
+
+// NewManifest lists the results, outputPath returns the file of the output of a row, or "" when the
+// outputs aren't written to files. Prices may be nil.
+func NewManifest(results []RowResult, outputPath func(index int) string, prices *pricing.Prices) (ret *Manifest) {
+	ret = &Manifest{Items: make([]ManifestItem, len(results))}
+	for i, result := range results {
+		item := ManifestItem{Row: i + 1, Status: StatusOK}
+		if result.Err != nil {
+			item.Status, item.Error = StatusFailed, result.Err.Error()
+			ret.Failed++
+		} else {
+			ret.Succeeded++
+			if outputPath != nil {
+				item.OutputPath = outputPath(i)
+			}
+		}
+		if metadata := result.Metadata; metadata != nil {
+			item.Model, item.LatencyMs = metadata.Model, metadata.LatencyMs
+			item.Tokens = ManifestTokens{Prompt: metadata.PromptTokens, Completion: metadata.CompletionTokens}
+			if cost, ok := prices.Cost(metadata.Model, metadata.PromptTokens, metadata.CompletionTokens); ok {
+				item.Cost = &cost
+				total := cost
+				if ret.TotalCost != nil {
+					total += *ret.TotalCost
+				}
+				ret.TotalCost = &total
+			}
+		}
+		ret.TotalTokens.Prompt += item.Tokens.Prompt
+		ret.TotalTokens.Completion += item.Tokens.Completion
+		ret.Items[i] = item
+	}
+	return
+}
+
+// Save writes the manifest as indented JSON
+func (o *Manifest) Save(filePath string) (err error) {
+	var data []byte
+	if data, err = json.MarshalIndent(o, "", "  "); err != nil {
+		return
+	}
+	return os.WriteFile(filePath, append(data, '\n'), 0o644)
+}
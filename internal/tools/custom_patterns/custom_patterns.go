@@ -21,7 +21,8 @@ func NewCustomPatterns() (ret *CustomPatterns) {
 	}
 
 	ret.CustomPatternsDir = ret.AddSetupQuestionCustom("Directory", false,
-		"Enter the path to your custom patterns directory (leave empty to skip)")
+		"Enter the path to your custom patterns directory, several separated by "+string(os.PathListSeparator)+
+			" with the first ones winning on the same names (leave empty to skip)")
 
 	return
 }
@@ -31,30 +32,44 @@ type CustomPatterns struct {
 	CustomPatternsDir *plugins.SetupQuestion
 }
 
+// configure resolves the directories, several are separated by os.PathListSeparator like PATH: a personal
+// directory and a team-shared one, the earlier ones winning when they have a pattern of the same name
 func (o *CustomPatterns) configure() error {
 	if o.CustomPatternsDir.Value != "" {
-		// Expand home directory if needed
-		if strings.HasPrefix(o.CustomPatternsDir.Value, "~/") {
-			if homeDir, err := os.UserHomeDir(); err == nil {
-				o.CustomPatternsDir.Value = filepath.Join(homeDir, o.CustomPatternsDir.Value[2:])
+		var dirs []string
+		for _, dir := range filepath.SplitList(o.CustomPatternsDir.Value) {
+			if dir = strings.TrimSpace(dir); dir != "" {
+				dirs = append(dirs, resolveDir(dir))
 			}
 		}
+		o.CustomPatternsDir.Value = strings.Join(dirs, string(os.PathListSeparator))
+	}
 
-		// Convert to absolute path
-		if absPath, err := filepath.Abs(o.CustomPatternsDir.Value); err == nil {
-			o.CustomPatternsDir.Value = absPath
-		}
+	return nil
+}
 
-		// Check if directory exists, create only if it doesn't
-		if _, err := os.Stat(o.CustomPatternsDir.Value); os.IsNotExist(err) {
-			if err := os.MkdirAll(o.CustomPatternsDir.Value, 0755); err != nil {
-				// Log the error but don't clear the value - let it persist in env file
-				fmt.Printf("Warning: Could not create custom patterns directory %s: %v\n", o.CustomPatternsDir.Value, err)
-			}
+// resolveDir expands the home directory and makes the path absolute, creating the directory if it doesn't exist
+func resolveDir(dir string) string {
+	// Expand home directory if needed
+	if strings.HasPrefix(dir, "~/") {
+		if homeDir, err := os.UserHomeDir(); err == nil {
+			dir = filepath.Join(homeDir, dir[2:])
 		}
 	}
 
-	return nil
+	// Convert to absolute path
+	if absPath, err := filepath.Abs(dir); err == nil {
+		dir = absPath
+	}
+
+	// Check if directory exists, create only if it doesn't
+	if _, err := os.Stat(dir); os.IsNotExist(err) {
+		if err := os.MkdirAll(dir, 0755); err != nil {
+			// Log the error but don't clear the value - let it persist in env file
+			fmt.Printf("Warning: Could not create custom patterns directory %s: %v\n", dir, err)
+		}
+	}
+	return dir
 }
 
 // IsConfigured returns true if a custom patterns directory has been set
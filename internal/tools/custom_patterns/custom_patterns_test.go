@@ -77,3 +77,16 @@ func TestCustomPatterns_IsConfigured(t *testing.T) {
 	plugin.CustomPatternsDir.Value = ""
 	assert.False(t, plugin.IsConfigured())
 }
+
+func TestCustomPatterns_ConfigureSeveralDirs(t *testing.T) {
+	plugin := NewCustomPatterns()
+
+	personal, team := t.TempDir(), filepath.Join(t.TempDir(), "team")
+	plugin.CustomPatternsDir.Value = personal + string(os.PathListSeparator) + " " + team + string(os.PathListSeparator)
+	require.NoError(t, plugin.configure())
+
+	assert.Equal(t, personal+string(os.PathListSeparator)+team, plugin.CustomPatternsDir.Value)
+	info, err := os.Stat(team)
+	require.NoError(t, err)
+	assert.True(t, info.IsDir())
+}
@@ -29,6 +29,12 @@ func NeeDefaults(getVendorsModels func() (*ai.VendorsModels, error)) (ret *Defau
 	ret.ModelContextLength = ret.AddSetupQuestionCustom("Model Context Length", false,
 		"Enter model context length")
 
+	ret.FallbackModels = ret.AddSetupQuestionCustom("Fallback Models", false,
+		"Enter a comma separated fallback chain of Vendor|model pairs, used when the default vendor is degraded")
+
+	ret.AutosavePath = ret.AddSetupQuestionCustom("Autosave Path", false,
+		"Enter the path the output of every run is written to, like ~/FabricOutputs/{pattern}/{timestamp}.md, or default for it")
+
 	return
 }
 
@@ -38,6 +44,8 @@ type Defaults struct {
 	Vendor             *plugins.Setting
 	Model              *plugins.SetupQuestion
 	ModelContextLength *plugins.SetupQuestion
+	FallbackModels     *plugins.SetupQuestion
+	AutosavePath       *plugins.SetupQuestion
 	GetVendorsModels   func() (*ai.VendorsModels, error)
 }
 
@@ -0,0 +1,49 @@
+package email
+
+import (
+	"regexp"
+	"strings"
+)
+
+var (
+	// the line introducing the quoted history of a reply, like "On Mon, 1 Jan 2024, Ada <ada@example.com> wrote:"
+	replyHeader = regexp.MustCompile(`(?i)^(on\s.+\swrote:|le\s.+\sa écrit\s?:|am\s.+\sschrieb\s.+:)$`)
+	// the separator of forwarded and Outlook quoted messages
+	originalMessage = regexp.MustCompile(`(?i)^(-{2,}\s*(original message|forwarded message)\s*-{2,}|_{10,})$`)
+	outlookHeader   = regexp.MustCompile(`(?i)^(from|von|de):\s.+$`)
+	outlookNext     = regexp.MustCompile(`(?i)^(sent|date|gesendet|envoyé|to):\s`)
+	mobileSignature = regexp.MustCompile(`(?i)^(sent from my |get outlook for )`)
+	blankRuns       = regexp.MustCompile(`\n{3,}`)
+	hasDigit        = regexp.MustCompile(`\d`)
+)
+
+// Clean strips the quoted history and the signature of a message body: the "> " quoted lines, everything
+// from a reply header like "On ... wrote:" or an original message separator, and everything from the
+// "-- " signature delimiter.
+func Clean(body string) string {
+	lines := strings.Split(strings.ReplaceAll(body, "\r\n", "\n"), "\n")
+	var kept []string
+cut:
+	for i, line := range lines {
+		trimmed := strings.TrimSpace(line)
+		switch {
+		case line == "-- " || trimmed == "--":
+			break cut
+		case originalMessage.MatchString(trimmed):
+			break cut
+		case replyHeader.MatchString(trimmed):
+			break cut
+		// mail clients wrap long reply headers with their date over two lines
+		case i+1 < len(lines) && hasDigit.MatchString(trimmed) && replyHeader.MatchString(trimmed+" "+strings.TrimSpace(lines[i+1])):
+			break cut
+		case outlookHeader.MatchString(trimmed) && i+1 < len(lines) && outlookNext.MatchString(strings.TrimSpace(lines[i+1])):
+			break cut
+		case strings.HasPrefix(trimmed, ">"):
+			continue
+		case mobileSignature.MatchString(trimmed):
+			continue
+		}
+		kept = append(kept, strings.TrimRight(line, " \t"))
+	}
+	return strings.TrimSpace(blankRuns.ReplaceAllString(strings.Join(kept, "\n"), "\n\n"))
+}
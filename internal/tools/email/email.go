@@ -0,0 +1,236 @@
+package email
+
+import (
+	"bytes"
+	"encoding/base64"
+	"fmt"
+	"io"
+	"mime"
+	"mime/multipart"
+	"mime/quotedprintable"
+	"net/mail"
+	"path/filepath"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/danielmiessler/fabric/internal/tools/feed"
+)
+
+// DefaultMessages is the number of the latest messages of a mailbox taken when not set
+const DefaultMessages = 10
+
+// Message is an email reduced to what a pattern needs: the main headers and the cleaned body
+type Message struct {
+	From        string    `json:"from"`
+	To          string    `json:"to,omitempty"`
+	Cc          string    `json:"cc,omitempty"`
+	Subject     string    `json:"subject"`
+	Date        time.Time `json:"date,omitempty"`
+	Body        string    `json:"body"`
+	Attachments []string  `json:"attachments,omitempty"` // file names, the content is not read
+}
+
+// IsEmail tells whether Parse supports the file by its name
+func IsEmail(fileName string) bool {
+	switch strings.ToLower(filepath.Ext(fileName)) {
+	case ".eml", ".mbox":
+		return true
+	}
+	return false
+}
+
+// Parse reads an .eml file or an .mbox mailbox into its messages, oldest first for mailboxes. The bodies
+// are cleaned of quoted history and signatures.
+func Parse(fileName string, data []byte) (ret []*Message, err error) {
+	var raws [][]byte
+	switch strings.ToLower(filepath.Ext(fileName)) {
+	case ".eml":
+		raws = [][]byte{data}
+	case ".mbox":
+		raws = SplitMbox(data)
+	default:
+		err = fmt.Errorf("%s is not an .eml or .mbox file", fileName)
+		return
+	}
+	for i, raw := range raws {
+		var message *Message
+		if message, err = ParseMessage(raw); err != nil {
+			err = fmt.Errorf("message %d of %s: %v", i+1, fileName, err)
+			return
+		}
+		ret = append(ret, message)
+	}
+	if len(ret) == 0 {
+		err = fmt.Errorf("%s has no messages", fileName)
+	}
+	return
+}
+
+// ParseMessage parses a RFC 5322 message, preferring its plain text body over its HTML one
+func ParseMessage(data []byte) (ret *Message, err error) {
+	var parsed *mail.Message
+	if parsed, err = mail.ReadMessage(bytes.NewReader(data)); err != nil {
+		err = fmt.Errorf("invalid email: %v", err)
+		return
+	}
+	ret = &Message{
+		From:    decodeHeader(parsed.Header.Get("From")),
+		To:      decodeHeader(parsed.Header.Get("To")),
+		Cc:      decodeHeader(parsed.Header.Get("Cc")),
+		Subject: decodeHeader(parsed.Header.Get("Subject")),
+	}
+	if date, dateErr := parsed.Header.Date(); dateErr == nil {
+		ret.Date = date
+	}
+
+	var parts bodyParts
+	if err = parts.read(parsed.Header, parsed.Body); err != nil {
+		return
+	}
+	ret.Attachments = parts.attachments
+	switch {
+	case parts.plain != "":
+		ret.Body = Clean(parts.plain)
+	case parts.html != "":
+		ret.Body = Clean(feed.HTMLToMarkdown(parts.html))
+	}
+	return
+}
+
+// Markdown renders the message as a markdown section with its headers
+func (o *Message) Markdown() string {
+	var builder strings.Builder
+	subject := o.Subject
+	if subject == "" {
+		subject = "No subject"
+	}
+	fmt.Fprintf(&builder, "## %s\n\n", subject)
+	fmt.Fprintf(&builder, "- From: %s\n", o.From)
+	if o.To != "" {
+		fmt.Fprintf(&builder, "- To: %s\n", o.To)
+	}
+	if o.Cc != "" {
+		fmt.Fprintf(&builder, "- Cc: %s\n", o.Cc)
+	}
+	if !o.Date.IsZero() {
+		fmt.Fprintf(&builder, "- Date: %s\n", o.Date.Format(time.DateTime))
+	}
+	if len(o.Attachments) > 0 {
+		fmt.Fprintf(&builder, "- Attachments: %s\n", strings.Join(o.Attachments, ", "))
+	}
+	if o.Body != "" {
+		fmt.Fprintf(&builder, "\n%s\n", o.Body)
+	}
+	return builder.String()
+}
+
+// Markdown renders the latest messages, up to count (all when not positive), oldest first
+func Markdown(messages []*Message, count int) string {
+	if count > 0 && len(messages) > count {
+		messages = messages[len(messages)-count:]
+	}
+	sections := make([]string, len(messages))
+	for i, message := range messages {
+		sections[i] = message.Markdown()
+	}
+	return strings.Join(sections, "\n")
+}
+
+// header is the part of the headers of a message or a MIME part the body is decoded with
+type header interface {
+	Get(key string) string
+}
+
+type bodyParts struct {
+	plain       string
+	html        string
+	attachments []string
+}
+
+// read walks the MIME parts, keeping the first plain text and HTML bodies and the names of the attachments
+func (o *bodyParts) read(h header, body io.Reader) (err error) {
+	mediaType, params, typeErr := mime.ParseMediaType(h.Get("Content-Type"))
+	if typeErr != nil {
+		mediaType, params = "text/plain", map[string]string{}
+	}
+
+	disposition, dispositionParams, _ := mime.ParseMediaType(h.Get("Content-Disposition"))
+	if disposition == "attachment" || (disposition == "inline" && dispositionParams["filename"] != "") {
+		name := dispositionParams["filename"]
+		if name == "" {
+			name = params["name"]
+		}
+		o.attachments = append(o.attachments, decodeHeader(name))
+		return
+	}
+
+	if strings.HasPrefix(mediaType, "multipart/") {
+		reader := multipart.NewReader(body, params["boundary"])
+		for {
+			var part *multipart.Part
+			if part, err = reader.NextRawPart(); err == io.EOF {
+				err = nil
+				return
+			} else if err != nil {
+				err = fmt.Errorf("invalid multipart body: %v", err)
+				return
+			}
+			if err = o.read(part.Header, part); err != nil {
+				return
+			}
+		}
+	}
+
+	if mediaType != "text/plain" && mediaType != "text/html" {
+		if name := params["name"]; name != "" {
+			o.attachments = append(o.attachments, decodeHeader(name))
+		}
+		return
+	}
+	var content []byte
+	if content, err = io.ReadAll(decodeTransfer(h.Get("Content-Transfer-Encoding"), body)); err != nil {
+		err = fmt.Errorf("invalid %s body: %v", mediaType, err)
+		return
+	}
+	text := decodeCharset(content, params["charset"])
+	if mediaType == "text/plain" && o.plain == "" {
+		o.plain = text
+	} else if mediaType == "text/html" && o.html == "" {
+		o.html = text
+	}
+	return
+}
+
+func decodeTransfer(encoding string, body io.Reader) io.Reader {
+	switch strings.ToLower(strings.TrimSpace(encoding)) {
+	case "quoted-printable":
+		return quotedprintable.NewReader(body)
+	case "base64":
+		return base64.NewDecoder(base64.StdEncoding, body)
+	}
+	return body
+}
+
+// decodeCharset converts the Latin-1 bodies to UTF-8, the other charsets are kept as they are
+func decodeCharset(content []byte, charset string) string {
+	charset = strings.ToLower(charset)
+	if (charset == "iso-8859-1" || charset == "latin1" || charset == "windows-1252") && !utf8.Valid(content) {
+		runes := make([]rune, len(content))
+		for i, b := range content {
+			runes[i] = rune(b)
+		}
+		return string(runes)
+	}
+	return strings.ToValidUTF8(string(content), "\uFFFD")
+}
+
+var wordDecoder = &mime.WordDecoder{}
+
+// decodeHeader decodes the RFC 2047 encoded words of a header, like =?UTF-8?B?...?=
+func decodeHeader(value string) string {
+	if decoded, err := wordDecoder.DecodeHeader(value); err == nil {
+		return strings.TrimSpace(decoded)
+	}
+	return strings.TrimSpace(value)
+}
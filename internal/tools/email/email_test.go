@@ -0,0 +1,117 @@
+package email
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+const multipartEmail = "From: =?UTF-8?Q?Ada_Lovelace?= <ada@example.com>\r\n" +
+	"To: Charles <charles@example.com>\r\n" +
+	"Subject: =?UTF-8?B?UmU6IEFuYWx5dGljYWwgRW5naW5l?=\r\n" +
+	"Date: Mon, 01 Jan 2024 10:00:00 +0000\r\n" +
+	"MIME-Version: 1.0\r\n" +
+	"Content-Type: multipart/mixed; boundary=outer\r\n" +
+	"\r\n" +
+	"--outer\r\n" +
+	"Content-Type: multipart/alternative; boundary=inner\r\n" +
+	"\r\n" +
+	"--inner\r\n" +
+	"Content-Type: text/plain; charset=utf-8\r\n" +
+	"Content-Transfer-Encoding: quoted-printable\r\n" +
+	"\r\n" +
+	"The notes are ready, see the attached table.=\r\n" +
+	" Caf=C3=A9 tomorrow?\r\n" +
+	"\r\n" +
+	"On Sun, 31 Dec 2023 at 09:00, Charles <charles@example.com>\r\n" +
+	"wrote:\r\n" +
+	"> Are the notes ready?\r\n" +
+	"\r\n" +
+	"--inner\r\n" +
+	"Content-Type: text/html\r\n" +
+	"\r\n" +
+	"<p>The notes are ready</p>\r\n" +
+	"--inner--\r\n" +
+	"--outer\r\n" +
+	"Content-Type: text/csv; name=table.csv\r\n" +
+	"Content-Disposition: attachment; filename=table.csv\r\n" +
+	"Content-Transfer-Encoding: base64\r\n" +
+	"\r\n" +
+	"YSxiCjEsMgo=\r\n" +
+	"--outer--\r\n"
+
+func TestParseMessage(t *testing.T) {
+	message, err := ParseMessage([]byte(multipartEmail))
+	if err != nil {
+		t.Fatalf("ParseMessage failed: %v", err)
+	}
+	if message.From != "Ada Lovelace <ada@example.com>" || message.To != "Charles <charles@example.com>" {
+		t.Errorf("unexpected addresses %q, %q", message.From, message.To)
+	}
+	if message.Subject != "Re: Analytical Engine" {
+		t.Errorf("unexpected subject %q", message.Subject)
+	}
+	if !message.Date.Equal(time.Date(2024, 1, 1, 10, 0, 0, 0, time.UTC)) {
+		t.Errorf("unexpected date %v", message.Date)
+	}
+	if message.Body != "The notes are ready, see the attached table. Café tomorrow?" {
+		t.Errorf("unexpected body %q", message.Body)
+	}
+	if len(message.Attachments) != 1 || message.Attachments[0] != "table.csv" {
+		t.Errorf("unexpected attachments %v", message.Attachments)
+	}
+}
+
+func TestParseMessageHTMLOnly(t *testing.T) {
+	message, err := ParseMessage([]byte("From: ada@example.com\nSubject: Hi\nContent-Type: text/html; charset=utf-8\n\n<p>Hello <b>there</b></p>"))
+	if err != nil {
+		t.Fatalf("ParseMessage failed: %v", err)
+	}
+	if message.Body != "Hello **there**" {
+		t.Errorf("unexpected body %q", message.Body)
+	}
+}
+
+func TestClean(t *testing.T) {
+	tests := map[string]string{
+		"Thanks!\n\n-- \nAda\nAnalyst":                                "Thanks!",
+		"Sure.\n> quoted\nSee you.":                                   "Sure.\nSee you.",
+		"Done.\n\n-----Original Message-----\nFrom: x":                "Done.",
+		"Yes.\n\nFrom: Charles\nSent: Monday\nTo: Ada\n\nOld":         "Yes.",
+		"Ok.\n\nOn Mon, Jan 1, 2024 at 9:00 AM Charles wrote:\n> Old": "Ok.",
+		"Call me.\n\nSent from my iPhone":                             "Call me.",
+		"First\n\n\n\nSecond  ":                                       "First\n\nSecond",
+		"From: the report, the numbers are up.\nThe team agrees.":     "From: the report, the numbers are up.\nThe team agrees.",
+	}
+	for body, expected := range tests {
+		if cleaned := Clean(body); cleaned != expected {
+			t.Errorf("Clean(%q) = %q, expected %q", body, cleaned, expected)
+		}
+	}
+}
+
+func TestParseMbox(t *testing.T) {
+	mbox := "From ada@example.com Mon Jan  1 10:00:00 2024\n" +
+		"From: ada@example.com\nSubject: First\n\nHello\n>From the start\n\n" +
+		"From charles@example.com Tue Jan  2 10:00:00 2024\n" +
+		"From: charles@example.com\nSubject: Second\n\nHi back\n"
+	messages, err := Parse("inbox.mbox", []byte(mbox))
+	if err != nil {
+		t.Fatalf("Parse failed: %v", err)
+	}
+	if len(messages) != 2 || messages[0].Subject != "First" || messages[1].Subject != "Second" {
+		t.Fatalf("unexpected messages %+v", messages)
+	}
+	if messages[0].Body != "Hello\nFrom the start" {
+		t.Errorf("unexpected body %q", messages[0].Body)
+	}
+
+	markdown := Markdown(messages, 1)
+	if strings.Contains(markdown, "First") || !strings.HasPrefix(markdown, "## Second\n\n- From: charles@example.com\n") {
+		t.Errorf("unexpected markdown %q", markdown)
+	}
+
+	if _, err = Parse("notes.txt", []byte(mbox)); err == nil {
+		t.Error("expected an error for a text file")
+	}
+}
@@ -0,0 +1,36 @@
+package email
+
+import (
+	"bytes"
+	"regexp"
+)
+
+// escapedFrom matches the body lines starting with "From " that mboxrd mailboxes escape with ">"
+var escapedFrom = regexp.MustCompile(`(?m)^>(>*From )`)
+
+// SplitMbox splits an mbox mailbox into its messages. A message starts with a "From " line at the start
+// of the file or after a blank line, the escaped "From " lines of the bodies are restored.
+func SplitMbox(data []byte) (ret [][]byte) {
+	data = bytes.ReplaceAll(data, []byte("\r\n"), []byte("\n"))
+	var current []byte
+	inMessage := false
+	previousBlank := true
+	for _, line := range bytes.SplitAfter(data, []byte("\n")) {
+		if previousBlank && bytes.HasPrefix(line, []byte("From ")) {
+			if inMessage {
+				ret = append(ret, escapedFrom.ReplaceAll(current, []byte("$1")))
+			}
+			current, inMessage = nil, true
+			previousBlank = false
+			continue
+		}
+		previousBlank = len(bytes.TrimSpace(line)) == 0
+		if inMessage {
+			current = append(current, line...)
+		}
+	}
+	if inMessage {
+		ret = append(ret, escapedFrom.ReplaceAll(current, []byte("$1")))
+	}
+	return
+}
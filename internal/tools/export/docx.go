@@ -0,0 +1,278 @@
+package export
+
+import (
+	"archive/zip"
+	"bytes"
+	"fmt"
+	"io"
+	"regexp"
+	"strings"
+	"time"
+)
+
+// inlineRe finds the links and the emphasis of a markdown text, like renderInline
+var inlineRe = regexp.MustCompile(`\[([^\]]+)\]\(([^)\s]+)\)|\*\*(.+?)\*\*|__(.+?)__|\*([^*\s][^*]*?)\*|\b_([^_\s][^_]*?)_\b`)
+
+var xmlEscaper = strings.NewReplacer("&", "&amp;", "<", "&lt;", ">", "&gt;", `"`, "&quot;")
+
+// renderDocx writes the document as a Word document: the title, a line with the pattern, the model and the
+// date, and the body with the styles of docxStyles. The style templates are text, so they don't apply.
+func renderDocx(doc Document, body string) (ret string, err error) {
+	writer := &docxWriter{}
+	writer.paragraph("Title", writer.text(doc.Title, ""))
+	var meta []string
+	for _, value := range []string{doc.Pattern, doc.Model, doc.Date.Format("January 2, 2006")} {
+		if value != "" {
+			meta = append(meta, value)
+		}
+	}
+	writer.paragraph("Subtitle", writer.text(strings.Join(meta, " · "), ""))
+	writer.markdown(body)
+
+	description := ""
+	if doc.Watermark {
+		provenance := Provenance{Version: doc.Version, Pattern: doc.Pattern, Model: doc.Model, Date: doc.Date}
+		description = provenance.Line()
+		writer.paragraph("Provenance", writer.text(description, ""))
+	}
+
+	var buf bytes.Buffer
+	archive := zip.NewWriter(&buf)
+	files := []struct{ name, content string }{
+		{"[Content_Types].xml", docxContentTypes},
+		{"_rels/.rels", docxRootRels},
+		{"docProps/core.xml", docxCoreProperties(doc, description)},
+		{"word/document.xml", `<?xml version="1.0" encoding="UTF-8" standalone="yes"?>` + "\n" +
+			`<w:document xmlns:w="http://schemas.openxmlformats.org/wordprocessingml/2006/main" ` +
+			`xmlns:r="http://schemas.openxmlformats.org/officeDocument/2006/relationships"><w:body>` +
+			writer.body.String() + `</w:body></w:document>`},
+		{"word/styles.xml", docxStyles},
+		{"word/_rels/document.xml.rels", writer.relationships()},
+	}
+	for _, file := range files {
+		var entry io.Writer
+		if entry, err = archive.Create(file.name); err != nil {
+			return
+		}
+		if _, err = entry.Write([]byte(file.content)); err != nil {
+			return
+		}
+	}
+	if err = archive.Close(); err != nil {
+		return
+	}
+	ret = buf.String()
+	return
+}
+
+// docxWriter converts the markdown the patterns produce to WordprocessingML, as ToHTML does to HTML
+type docxWriter struct {
+	body  strings.Builder
+	links []string // the targets of the hyperlinks, their relationship ids are rLink1, rLink2...
+}
+
+func (o *docxWriter) paragraph(style string, runs string) {
+	o.body.WriteString(`<w:p><w:pPr><w:pStyle w:val="` + style + `"/></w:pPr>` + runs + `</w:p>`)
+}
+
+// text is a run of the text with the character properties, the line breaks are kept
+func (o *docxWriter) text(text string, properties string) string {
+	if properties != "" {
+		properties = "<w:rPr>" + properties + "</w:rPr>"
+	}
+	var out strings.Builder
+	for i, line := range strings.Split(text, "\n") {
+		if i > 0 {
+			out.WriteString(`<w:r><w:br/></w:r>`)
+		}
+		out.WriteString(`<w:r>` + properties + `<w:t xml:space="preserve">` + xmlEscaper.Replace(line) + `</w:t></w:r>`)
+	}
+	return out.String()
+}
+
+// inline renders the code spans, links and emphasis of the text as runs
+func (o *docxWriter) inline(text string) string {
+	var out strings.Builder
+	parts := strings.Split(text, "`")
+	for i, part := range parts {
+		// odd parts are inside a code span, an unclosed backtick is kept as is
+		if i%2 == 1 && i < len(parts)-1 {
+			out.WriteString(o.text(part, `<w:rStyle w:val="CodeChar"/>`))
+			continue
+		}
+		if i%2 == 1 {
+			part = "`" + part
+		}
+		last := 0
+		for _, match := range inlineRe.FindAllStringSubmatchIndex(part, -1) {
+			out.WriteString(o.text(part[last:match[0]], ""))
+			last = match[1]
+			group := func(n int) string {
+				if match[2*n] < 0 {
+					return ""
+				}
+				return part[match[2*n]:match[2*n+1]]
+			}
+			switch {
+			case group(1) != "":
+				o.links = append(o.links, group(2))
+				out.WriteString(fmt.Sprintf(`<w:hyperlink r:id="rLink%d">%s</w:hyperlink>`,
+					len(o.links), o.text(group(1), `<w:rStyle w:val="Hyperlink"/>`)))
+			case group(3) != "" || group(4) != "":
+				out.WriteString(o.text(group(3)+group(4), `<w:b/>`))
+			default:
+				out.WriteString(o.text(group(5)+group(6), `<w:i/>`))
+			}
+		}
+		out.WriteString(o.text(part[last:], ""))
+	}
+	return out.String()
+}
+
+func (o *docxWriter) markdown(markdown string) {
+	var paragraph []string
+	flushParagraph := func() {
+		if len(paragraph) > 0 {
+			o.paragraph("Normal", o.inline(strings.Join(paragraph, " ")))
+			paragraph = nil
+		}
+	}
+
+	number := 0
+	lines := strings.Split(strings.ReplaceAll(markdown, "\r\n", "\n"), "\n")
+	for i := 0; i < len(lines); i++ {
+		line := lines[i]
+		trimmed := strings.TrimSpace(line)
+		if !orderedRe.MatchString(line) && trimmed != "" && !strings.HasPrefix(line, " ") {
+			number = 0
+		}
+
+		switch {
+		case strings.HasPrefix(trimmed, "```"):
+			flushParagraph()
+			var code []string
+			for i++; i < len(lines) && !strings.HasPrefix(strings.TrimSpace(lines[i]), "```"); i++ {
+				code = append(code, lines[i])
+			}
+			o.paragraph("Code", o.text(strings.Join(code, "\n"), ""))
+		case trimmed == "":
+			flushParagraph()
+		case headingRe.MatchString(trimmed):
+			flushParagraph()
+			match := headingRe.FindStringSubmatch(trimmed)
+			o.paragraph(fmt.Sprintf("Heading%d", len(match[1])), o.inline(match[2]))
+		case ruleRe.MatchString(trimmed):
+			flushParagraph()
+			o.paragraph("Rule", "")
+		case strings.HasPrefix(trimmed, ">"):
+			flushParagraph()
+			var quote []string
+			for ; i < len(lines) && strings.HasPrefix(strings.TrimSpace(lines[i]), ">"); i++ {
+				quote = append(quote, strings.TrimPrefix(strings.TrimPrefix(strings.TrimSpace(lines[i]), ">"), " "))
+			}
+			i--
+			o.paragraph("Quote", o.inline(strings.Join(quote, " ")))
+		case unorderedRe.MatchString(line):
+			flushParagraph()
+			o.paragraph("ListParagraph", o.text("• ", "")+o.inline(unorderedRe.FindStringSubmatch(line)[1]))
+		case orderedRe.MatchString(line):
+			flushParagraph()
+			number++
+			o.paragraph("ListParagraph", o.text(fmt.Sprintf("%d. ", number), "")+o.inline(orderedRe.FindStringSubmatch(line)[1]))
+		default:
+			if number > 0 && strings.HasPrefix(line, " ") {
+				// continuation of the previous list item
+				continue
+			}
+			paragraph = append(paragraph, trimmed)
+		}
+	}
+	flushParagraph()
+}
+
+func (o *docxWriter) relationships() string {
+	var out strings.Builder
+	out.WriteString(`<?xml version="1.0" encoding="UTF-8" standalone="yes"?>` + "\n" +
+		`<Relationships xmlns="http://schemas.openxmlformats.org/package/2006/relationships">` +
+		`<Relationship Id="rStyles" Type="http://schemas.openxmlformats.org/officeDocument/2006/relationships/styles" Target="styles.xml"/>`)
+	for i, link := range o.links {
+		fmt.Fprintf(&out, `<Relationship Id="rLink%d" Type="http://schemas.openxmlformats.org/officeDocument/2006/relationships/hyperlink" Target="%s" TargetMode="External"/>`,
+			i+1, xmlEscaper.Replace(link))
+	}
+	out.WriteString(`</Relationships>`)
+	return out.String()
+}
+
+// docxCoreProperties are the document properties Word shows, the description holds the provenance
+func docxCoreProperties(doc Document, description string) string {
+	var keywords []string
+	for _, value := range []string{doc.Pattern, doc.Model} {
+		if value != "" {
+			keywords = append(keywords, value)
+		}
+	}
+	return `<?xml version="1.0" encoding="UTF-8" standalone="yes"?>` + "\n" +
+		`<cp:coreProperties xmlns:cp="http://schemas.openxmlformats.org/package/2006/metadata/core-properties" ` +
+		`xmlns:dc="http://purl.org/dc/elements/1.1/" xmlns:dcterms="http://purl.org/dc/terms/" ` +
+		`xmlns:xsi="http://www.w3.org/2001/XMLSchema-instance">` +
+		`<dc:title>` + xmlEscaper.Replace(doc.Title) + `</dc:title>` +
+		`<dc:creator>Fabric</dc:creator>` +
+		`<cp:keywords>` + xmlEscaper.Replace(strings.Join(keywords, ", ")) + `</cp:keywords>` +
+		`<dc:description>` + xmlEscaper.Replace(description) + `</dc:description>` +
+		`<dcterms:created xsi:type="dcterms:W3CDTF">` + doc.Date.UTC().Format(time.RFC3339) + `</dcterms:created>` +
+		`</cp:coreProperties>`
+}
+
+const docxContentTypes = `<?xml version="1.0" encoding="UTF-8" standalone="yes"?>
+<Types xmlns="http://schemas.openxmlformats.org/package/2006/content-types">` +
+	`<Default Extension="rels" ContentType="application/vnd.openxmlformats-package.relationships+xml"/>` +
+	`<Default Extension="xml" ContentType="application/xml"/>` +
+	`<Override PartName="/word/document.xml" ContentType="application/vnd.openxmlformats-officedocument.wordprocessingml.document.main+xml"/>` +
+	`<Override PartName="/word/styles.xml" ContentType="application/vnd.openxmlformats-officedocument.wordprocessingml.styles+xml"/>` +
+	`<Override PartName="/docProps/core.xml" ContentType="application/vnd.openxmlformats-package.core-properties+xml"/>` +
+	`</Types>`
+
+const docxRootRels = `<?xml version="1.0" encoding="UTF-8" standalone="yes"?>
+<Relationships xmlns="http://schemas.openxmlformats.org/package/2006/relationships">` +
+	`<Relationship Id="rDocument" Type="http://schemas.openxmlformats.org/officeDocument/2006/relationships/officeDocument" Target="word/document.xml"/>` +
+	`<Relationship Id="rCore" Type="http://schemas.openxmlformats.org/package/2006/relationships/metadata/core-properties" Target="docProps/core.xml"/>` +
+	`</Relationships>`
+
+// docxStyles are the paragraph and character styles of the exported documents, close to the minimal style
+var docxStyles = `<?xml version="1.0" encoding="UTF-8" standalone="yes"?>
+<w:styles xmlns:w="http://schemas.openxmlformats.org/wordprocessingml/2006/main">` +
+	`<w:docDefaults><w:rPrDefault><w:rPr><w:rFonts w:ascii="Calibri" w:hAnsi="Calibri" w:cs="Calibri"/><w:sz w:val="22"/></w:rPr></w:rPrDefault>` +
+	`<w:pPrDefault><w:pPr><w:spacing w:after="160" w:line="276" w:lineRule="auto"/></w:pPr></w:pPrDefault></w:docDefaults>` +
+	`<w:style w:type="paragraph" w:default="1" w:styleId="Normal"><w:name w:val="Normal"/></w:style>` +
+	`<w:style w:type="paragraph" w:styleId="Title"><w:name w:val="Title"/><w:basedOn w:val="Normal"/><w:next w:val="Subtitle"/>` +
+	`<w:pPr><w:spacing w:after="80"/></w:pPr><w:rPr><w:b/><w:sz w:val="48"/></w:rPr></w:style>` +
+	`<w:style w:type="paragraph" w:styleId="Subtitle"><w:name w:val="Subtitle"/><w:basedOn w:val="Normal"/>` +
+	`<w:pPr><w:spacing w:after="320"/></w:pPr><w:rPr><w:color w:val="777777"/><w:sz w:val="20"/></w:rPr></w:style>` +
+	docxHeadingStyles +
+	`<w:style w:type="paragraph" w:styleId="ListParagraph"><w:name w:val="List Paragraph"/><w:basedOn w:val="Normal"/>` +
+	`<w:pPr><w:spacing w:after="60"/><w:ind w:left="720" w:hanging="360"/></w:pPr></w:style>` +
+	`<w:style w:type="paragraph" w:styleId="Quote"><w:name w:val="Quote"/><w:basedOn w:val="Normal"/>` +
+	`<w:pPr><w:pBdr><w:left w:val="single" w:sz="18" w:space="8" w:color="CCCCCC"/></w:pBdr><w:ind w:left="360"/></w:pPr>` +
+	`<w:rPr><w:i/><w:color w:val="555555"/></w:rPr></w:style>` +
+	`<w:style w:type="paragraph" w:styleId="Code"><w:name w:val="Code"/><w:basedOn w:val="Normal"/>` +
+	`<w:pPr><w:shd w:val="clear" w:color="auto" w:fill="F4F4F4"/><w:spacing w:line="240" w:lineRule="auto"/></w:pPr>` +
+	`<w:rPr><w:rFonts w:ascii="Consolas" w:hAnsi="Consolas" w:cs="Consolas"/><w:sz w:val="18"/></w:rPr></w:style>` +
+	`<w:style w:type="paragraph" w:styleId="Rule"><w:name w:val="Rule"/><w:basedOn w:val="Normal"/>` +
+	`<w:pPr><w:pBdr><w:bottom w:val="single" w:sz="6" w:space="1" w:color="CCCCCC"/></w:pBdr></w:pPr></w:style>` +
+	`<w:style w:type="paragraph" w:styleId="Provenance"><w:name w:val="Provenance"/><w:basedOn w:val="Normal"/>` +
+	`<w:pPr><w:spacing w:before="480"/></w:pPr><w:rPr><w:color w:val="777777"/><w:sz w:val="16"/></w:rPr></w:style>` +
+	`<w:style w:type="character" w:styleId="CodeChar"><w:name w:val="Code Char"/>` +
+	`<w:rPr><w:rFonts w:ascii="Consolas" w:hAnsi="Consolas" w:cs="Consolas"/><w:shd w:val="clear" w:color="auto" w:fill="F4F4F4"/></w:rPr></w:style>` +
+	`<w:style w:type="character" w:styleId="Hyperlink"><w:name w:val="Hyperlink"/><w:rPr><w:color w:val="0563C1"/><w:u w:val="single"/></w:rPr></w:style>` +
+	`</w:styles>`
+
+var docxHeadingStyles = func() string {
+	var out strings.Builder
+	sizes := []int{36, 30, 26, 24, 22, 22}
+	for i, size := range sizes {
+		fmt.Fprintf(&out, `<w:style w:type="paragraph" w:styleId="Heading%d"><w:name w:val="heading %d"/><w:basedOn w:val="Normal"/>`+
+			`<w:next w:val="Normal"/><w:pPr><w:keepNext/><w:spacing w:before="240" w:after="120"/><w:outlineLvl w:val="%d"/></w:pPr>`+
+			`<w:rPr><w:b/><w:sz w:val="%d"/></w:rPr></w:style>`, i+1, i+1, i, size)
+	}
+	return out.String()
+}()
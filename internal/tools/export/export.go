@@ -0,0 +1,217 @@
+package export
+
+import (
+	"bytes"
+	"embed"
+	"errors"
+	"fmt"
+	htmltemplate "html/template"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	texttemplate "text/template"
+	"time"
+)
+
+const (
+	FormatMarkdown = "markdown"
+	FormatHTML     = "html"
+	// FormatDocx is a Word document, see renderDocx
+	FormatDocx = "docx"
+
+	// DefaultStyle is used when no style is selected
+	DefaultStyle = "minimal"
+
+	// StylesDir is the directory in the config dir with the custom style templates
+	StylesDir = "export_styles"
+)
+
+// extensions of the template files, e.g. report.md.tmpl and report.html.tmpl
+var templateExtensions = map[string]string{
+	FormatMarkdown: ".md.tmpl",
+	FormatHTML:     ".html.tmpl",
+}
+
+//go:embed styles/*.tmpl
+var builtinStyles embed.FS
+
+// Document is the output exported with a style
+type Document struct {
+	Title   string
+	Pattern string
+	Model   string
+	Date    time.Time
+	Content string // markdown
+
+	// Watermark embeds the Provenance of the document, Version is the fabric version it names
+	Watermark bool
+	Version   string
+}
+
+// TemplateData is passed to the style templates
+type TemplateData struct {
+	Document
+	HTML      htmltemplate.HTML // Content rendered as HTML
+	Body      string            // Content without its leading title heading, for styles printing the title themselves
+	BodyHTML  htmltemplate.HTML
+	Headings  []Heading
+	Citations []Citation
+}
+
+// Styles are the export styles, the built-in ones and those in Dir. A template in Dir replaces
+// the built-in template of the same name, so the built-in styles can be customized.
+type Styles struct {
+	Dir string
+}
+
+func NewStyles(dir string) *Styles {
+	return &Styles{Dir: dir}
+}
+
+// FormatOf returns the export format of a file name, FormatMarkdown unless it is an HTML or a Word file
+func FormatOf(fileName string) string {
+	switch strings.ToLower(filepath.Ext(fileName)) {
+	case ".html", ".htm":
+		return FormatHTML
+	case ".docx":
+		return FormatDocx
+	}
+	return FormatMarkdown
+}
+
+// Names returns the names of the available styles
+func (o *Styles) Names() (ret []string, err error) {
+	names := map[string]bool{}
+	collect := func(fsys fs.FS) (err error) {
+		var entries []fs.DirEntry
+		if entries, err = fs.ReadDir(fsys, "."); err != nil {
+			return
+		}
+		for _, entry := range entries {
+			for _, ext := range templateExtensions {
+				if name, found := strings.CutSuffix(entry.Name(), ext); found && !entry.IsDir() {
+					names[name] = true
+				}
+			}
+		}
+		return
+	}
+
+	styles, _ := fs.Sub(builtinStyles, "styles")
+	if err = collect(styles); err != nil {
+		return
+	}
+	if o.Dir != "" {
+		if err = collect(os.DirFS(o.Dir)); err != nil && !errors.Is(err, fs.ErrNotExist) {
+			err = fmt.Errorf("could not read export styles from %s: %v", o.Dir, err)
+			return
+		}
+		err = nil
+	}
+
+	for name := range names {
+		ret = append(ret, name)
+	}
+	sort.Strings(ret)
+	return
+}
+
+// Render exports the document in the format with the style. A Word document is binary, the style doesn't
+// apply to it.
+func (o *Styles) Render(style string, format string, doc Document) (ret string, err error) {
+	if style == "" {
+		style = DefaultStyle
+	}
+	ext, ok := templateExtensions[format]
+	if !ok && format != FormatDocx {
+		err = fmt.Errorf("unsupported export format %q, use %s, %s or %s", format, FormatMarkdown, FormatHTML, FormatDocx)
+		return
+	}
+	if strings.ContainsAny(style, `/\`) || strings.HasPrefix(style, ".") {
+		err = fmt.Errorf("invalid export style name %q", style)
+		return
+	}
+
+	var source []byte
+	if format != FormatDocx {
+		if source, err = o.templateSource(style + ext); err != nil {
+			if errors.Is(err, fs.ErrNotExist) {
+				names, _ := o.Names()
+				err = fmt.Errorf("export style %q has no %s template, available styles: %s", style, format, strings.Join(names, ", "))
+			}
+			return
+		}
+	}
+
+	if doc.Date.IsZero() {
+		doc.Date = time.Now()
+	}
+	doc.Content = strings.TrimSpace(doc.Content)
+	body := doc.Content
+	if title, rest, found := cutTitle(doc.Content); found {
+		body = rest
+		if doc.Title == "" {
+			doc.Title = title
+		}
+	}
+	if doc.Title == "" {
+		doc.Title = doc.Pattern
+	}
+	if doc.Title == "" {
+		doc.Title = "Fabric Output"
+	}
+	if format == FormatDocx {
+		return renderDocx(doc, body)
+	}
+	data := TemplateData{
+		Document:  doc,
+		HTML:      htmltemplate.HTML(ToHTML(doc.Content)),
+		Body:      body,
+		BodyHTML:  htmltemplate.HTML(ToHTML(body)),
+		Headings:  Headings(doc.Content),
+		Citations: Citations(doc.Content),
+	}
+
+	var buf bytes.Buffer
+	if format == FormatHTML {
+		var tmpl *htmltemplate.Template
+		if tmpl, err = htmltemplate.New(style).Parse(string(source)); err == nil {
+			err = tmpl.Execute(&buf, data)
+		}
+	} else {
+		var tmpl *texttemplate.Template
+		if tmpl, err = texttemplate.New(style).Parse(string(source)); err == nil {
+			err = tmpl.Execute(&buf, data)
+		}
+	}
+	if err != nil {
+		err = fmt.Errorf("could not apply export style %s: %v", style, err)
+		return
+	}
+	ret = buf.String()
+	if doc.Watermark {
+		ret = Provenance{Version: doc.Version, Pattern: doc.Pattern, Model: doc.Model, Date: doc.Date}.watermark(format, ret)
+	}
+	return
+}
+
+// templateSource reads the template from Dir, falling back to the built-in one
+func (o *Styles) templateSource(fileName string) (ret []byte, err error) {
+	if o.Dir != "" {
+		if ret, err = os.ReadFile(filepath.Join(o.Dir, fileName)); err == nil || !errors.Is(err, fs.ErrNotExist) {
+			return
+		}
+	}
+	return builtinStyles.ReadFile("styles/" + fileName)
+}
+
+// cutTitle splits a leading level 1 heading off the markdown
+func cutTitle(markdown string) (title string, rest string, found bool) {
+	firstLine, rest, _ := strings.Cut(markdown, "\n")
+	if match := headingRe.FindStringSubmatch(strings.TrimSpace(firstLine)); match != nil && len(match[1]) == 1 {
+		return Headings(firstLine)[0].Text, strings.TrimSpace(rest), true
+	}
+	return "", markdown, false
+}
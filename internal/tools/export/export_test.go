@@ -0,0 +1,185 @@
+package export
+
+import (
+	"archive/zip"
+	"io"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+const sample = `# Climate Summary
+
+## Key Ideas
+
+- Warming is **accelerating**, see [IPCC](https://www.ipcc.ch/report).
+- Sources: https://example.org/data.
+
+## Code
+
+` + "```go\nfmt.Println(\"<hi>\")\n```" + `
+`
+
+func TestToHTML(t *testing.T) {
+	html := ToHTML(sample)
+	for _, expected := range []string{
+		`<h1 id="climate-summary">Climate Summary</h1>`,
+		`<li>Warming is <strong>accelerating</strong>, see <a href="https://www.ipcc.ch/report">IPCC</a>.</li>`,
+		`<pre><code class="language-go">fmt.Println(&#34;&lt;hi&gt;&#34;)</code></pre>`,
+	} {
+		if !strings.Contains(html, expected) {
+			t.Errorf("expected %q in\n%s", expected, html)
+		}
+	}
+	if html := ToHTML("<script>alert(1)</script>"); strings.Contains(html, "<script>") {
+		t.Errorf("expected raw HTML to be escaped, got %s", html)
+	}
+}
+
+func TestCitations(t *testing.T) {
+	expected := []Citation{
+		{Number: 1, Text: "IPCC", URL: "https://www.ipcc.ch/report"},
+		{Number: 2, Text: "https://example.org/data", URL: "https://example.org/data"},
+	}
+	if citations := Citations(sample + "\nAgain [the report](https://www.ipcc.ch/report)."); !reflect.DeepEqual(citations, expected) {
+		t.Errorf("Citations() = %+v, expected %+v", citations, expected)
+	}
+}
+
+func TestStyles_Render(t *testing.T) {
+	styles := NewStyles(t.TempDir())
+	doc := Document{Pattern: "summarize", Model: "gpt-4o", Date: time.Date(2024, 3, 10, 0, 0, 0, 0, time.UTC), Content: sample}
+
+	report, err := styles.Render("report", FormatMarkdown, doc)
+	if err != nil {
+		t.Fatalf("Render() failed: %v", err)
+	}
+	for _, expected := range []string{"# Climate Summary", "| Pattern | summarize |", "- [Key Ideas](#key-ideas)", "March 10, 2024"} {
+		if !strings.Contains(report, expected) {
+			t.Errorf("expected %q in report\n%s", expected, report)
+		}
+	}
+
+	academic, err := styles.Render("academic", FormatHTML, doc)
+	if err != nil {
+		t.Fatalf("Render() failed: %v", err)
+	}
+	if !strings.Contains(academic, "<h2>References</h2>") || !strings.Contains(academic, `<a href="https://example.org/data">`) {
+		t.Errorf("expected the references section in\n%s", academic)
+	}
+
+	if _, err = styles.Render("missing", FormatHTML, doc); err == nil || !strings.Contains(err.Error(), "academic, minimal, report") {
+		t.Errorf("expected the available styles in the error, got %v", err)
+	}
+}
+
+func TestStyles_CustomTemplates(t *testing.T) {
+	dir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(dir, "minimal.md.tmpl"), []byte("custom: {{.Title}}"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, "memo.md.tmpl"), []byte("MEMO {{.Pattern}}"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	styles := NewStyles(dir)
+
+	names, err := styles.Names()
+	if err != nil {
+		t.Fatalf("Names() failed: %v", err)
+	}
+	if expected := []string{"academic", "memo", "minimal", "report"}; !reflect.DeepEqual(names, expected) {
+		t.Errorf("Names() = %v, expected %v", names, expected)
+	}
+
+	if ret, _ := styles.Render("", FormatMarkdown, Document{Content: sample}); ret != "custom: Climate Summary" {
+		t.Errorf("expected the customized default style, got %q", ret)
+	}
+	if ret, _ := styles.Render("memo", FormatMarkdown, Document{Pattern: "summarize"}); ret != "MEMO summarize" {
+		t.Errorf("expected the new style, got %q", ret)
+	}
+	if _, err := styles.Render("memo", FormatHTML, Document{}); err == nil {
+		t.Error("expected an error for the missing html template")
+	}
+}
+
+func TestStyles_Watermark(t *testing.T) {
+	styles := NewStyles(t.TempDir())
+	doc := Document{Pattern: "summarize", Model: "gpt-4o", Date: time.Date(2024, 3, 10, 9, 30, 0, 0, time.UTC), Content: sample,
+		Watermark: true, Version: "v1.4.300"}
+	line := "Generated with Fabric v1.4.300, pattern summarize, model gpt-4o, on 2024-03-10T09:30:00Z."
+
+	markdown, err := styles.Render("minimal", FormatMarkdown, doc)
+	if err != nil {
+		t.Fatalf("Render() failed: %v", err)
+	}
+	if !strings.HasSuffix(markdown, "\n\n---\n\n_"+line+"_\n") {
+		t.Errorf("expected the provenance footer in\n%s", markdown)
+	}
+
+	page, err := styles.Render("report", FormatHTML, doc)
+	if err != nil {
+		t.Fatalf("Render() failed: %v", err)
+	}
+	head, body, _ := strings.Cut(page, "</head>")
+	for _, expected := range []string{`<meta name="generator" content="Fabric v1.4.300">`, `<meta name="fabric:model" content="gpt-4o">`,
+		`<meta name="dcterms.created" content="2024-03-10T09:30:00Z">`} {
+		if !strings.Contains(head, expected) {
+			t.Errorf("expected %q in the head\n%s", expected, head)
+		}
+	}
+	if !strings.Contains(body, line+"</footer>\n</body>") {
+		t.Errorf("expected the provenance footer at the end of the body\n%s", body)
+	}
+
+	doc.Watermark = false
+	if plain, _ := styles.Render("minimal", FormatMarkdown, doc); strings.Contains(plain, "Generated with Fabric") {
+		t.Errorf("expected no provenance without the watermark, got\n%s", plain)
+	}
+}
+
+func TestStyles_RenderDocx(t *testing.T) {
+	doc := Document{Pattern: "summarize", Model: "gpt-4o", Date: time.Date(2024, 3, 10, 0, 0, 0, 0, time.UTC), Content: sample, Watermark: true}
+	docx, err := NewStyles(t.TempDir()).Render("", FormatDocx, doc)
+	if err != nil {
+		t.Fatalf("Render() failed: %v", err)
+	}
+
+	archive, err := zip.NewReader(strings.NewReader(docx), int64(len(docx)))
+	if err != nil {
+		t.Fatalf("expected a zip archive: %v", err)
+	}
+	parts := map[string]string{}
+	for _, file := range archive.File {
+		reader, err := file.Open()
+		if err != nil {
+			t.Fatalf("failed to open %s: %v", file.Name, err)
+		}
+		content, _ := io.ReadAll(reader)
+		reader.Close()
+		parts[file.Name] = string(content)
+	}
+
+	document := parts["word/document.xml"]
+	for _, expected := range []string{
+		`<w:pStyle w:val="Title"/></w:pPr><w:r><w:t xml:space="preserve">Climate Summary</w:t>`,
+		`<w:t xml:space="preserve">summarize · gpt-4o · March 10, 2024</w:t>`,
+		`<w:pStyle w:val="Heading2"/>`,
+		`<w:r><w:rPr><w:b/></w:rPr><w:t xml:space="preserve">accelerating</w:t></w:r>`,
+		`<w:hyperlink r:id="rLink1">`,
+		`fmt.Println(&quot;&lt;hi&gt;&quot;)`,
+		`Generated with Fabric, pattern summarize, model gpt-4o`,
+	} {
+		if !strings.Contains(document, expected) {
+			t.Errorf("expected %q in\n%s", expected, document)
+		}
+	}
+	if !strings.Contains(parts["word/_rels/document.xml.rels"], `Target="https://www.ipcc.ch/report" TargetMode="External"`) {
+		t.Errorf("expected the link relationship, got %s", parts["word/_rels/document.xml.rels"])
+	}
+	if !strings.Contains(parts["docProps/core.xml"], "<dc:title>Climate Summary</dc:title>") {
+		t.Errorf("expected the title property, got %s", parts["docProps/core.xml"])
+	}
+}
@@ -0,0 +1,189 @@
+package export
+
+import (
+	"html"
+	"regexp"
+	"strings"
+)
+
+var (
+	headingRe    = regexp.MustCompile(`^(#{1,6})\s+(.*?)\s*#*\s*$`)
+	orderedRe    = regexp.MustCompile(`^\s*\d+[.)]\s+(.*)$`)
+	unorderedRe  = regexp.MustCompile(`^\s*[-*+]\s+(.*)$`)
+	ruleRe       = regexp.MustCompile(`^\s*([-*_])(\s*([-*_]))\s*([-*_]\s*)+$`)
+	linkRe       = regexp.MustCompile(`\[([^\]]+)\]\(([^)\s]+)\)`)
+	boldRe       = regexp.MustCompile(`\*\*(.+?)\*\*|__(.+?)__`)
+	italicRe     = regexp.MustCompile(`\*([^*\s][^*]*?)\*|\b_([^_\s][^_]*?)_\b`)
+	slugInvalid  = regexp.MustCompile(`[^a-z0-9]+`)
+	bareURLRe    = regexp.MustCompile(`https?://[^\s<>()\[\]]+[^\s<>()\[\].,;:!?'"]`)
+	markdownLink = regexp.MustCompile(`\[([^\]]+)\]\((https?://[^)\s]+)\)`)
+)
+
+// Heading is a section heading of the exported output
+type Heading struct {
+	Level int
+	Text  string
+	ID    string
+}
+
+// Citation is a link found in the output, numbered in the order of first appearance
+type Citation struct {
+	Number int
+	Text   string
+	URL    string
+}
+
+// ToHTML renders the markdown the patterns produce, headings, lists, quotes, code blocks and
+// inline emphasis, code and links, as HTML. Raw HTML in the markdown is escaped.
+func ToHTML(markdown string) string {
+	var out strings.Builder
+	var paragraph []string
+	var listTag string
+
+	flushParagraph := func() {
+		if len(paragraph) > 0 {
+			out.WriteString("<p>" + renderInline(strings.Join(paragraph, " ")) + "</p>\n")
+			paragraph = nil
+		}
+	}
+	closeList := func() {
+		if listTag != "" {
+			out.WriteString("</" + listTag + ">\n")
+			listTag = ""
+		}
+	}
+	openList := func(tag string) {
+		if listTag != tag {
+			closeList()
+			out.WriteString("<" + tag + ">\n")
+			listTag = tag
+		}
+	}
+
+	lines := strings.Split(strings.ReplaceAll(markdown, "\r\n", "\n"), "\n")
+	for i := 0; i < len(lines); i++ {
+		line := lines[i]
+		trimmed := strings.TrimSpace(line)
+
+		switch {
+		case strings.HasPrefix(trimmed, "```"):
+			flushParagraph()
+			closeList()
+			language := strings.TrimSpace(strings.TrimPrefix(trimmed, "```"))
+			var code []string
+			for i++; i < len(lines) && !strings.HasPrefix(strings.TrimSpace(lines[i]), "```"); i++ {
+				code = append(code, lines[i])
+			}
+			if language != "" {
+				out.WriteString(`<pre><code class="language-` + html.EscapeString(language) + `">`)
+			} else {
+				out.WriteString("<pre><code>")
+			}
+			out.WriteString(html.EscapeString(strings.Join(code, "\n")) + "</code></pre>\n")
+		case trimmed == "":
+			flushParagraph()
+			closeList()
+		case headingRe.MatchString(trimmed):
+			flushParagraph()
+			closeList()
+			match := headingRe.FindStringSubmatch(trimmed)
+			level := string(rune('0' + len(match[1])))
+			out.WriteString("<h" + level + ` id="` + Slug(match[2]) + `">` + renderInline(match[2]) + "</h" + level + ">\n")
+		case ruleRe.MatchString(trimmed):
+			flushParagraph()
+			closeList()
+			out.WriteString("<hr>\n")
+		case strings.HasPrefix(trimmed, ">"):
+			flushParagraph()
+			closeList()
+			var quote []string
+			for ; i < len(lines) && strings.HasPrefix(strings.TrimSpace(lines[i]), ">"); i++ {
+				quote = append(quote, strings.TrimPrefix(strings.TrimPrefix(strings.TrimSpace(lines[i]), ">"), " "))
+			}
+			i--
+			out.WriteString("<blockquote>\n" + ToHTML(strings.Join(quote, "\n")) + "</blockquote>\n")
+		case unorderedRe.MatchString(line):
+			flushParagraph()
+			openList("ul")
+			out.WriteString("<li>" + renderInline(unorderedRe.FindStringSubmatch(line)[1]) + "</li>\n")
+		case orderedRe.MatchString(line):
+			flushParagraph()
+			openList("ol")
+			out.WriteString("<li>" + renderInline(orderedRe.FindStringSubmatch(line)[1]) + "</li>\n")
+		default:
+			if listTag != "" && strings.HasPrefix(line, " ") {
+				// continuation of the previous list item
+				continue
+			}
+			closeList()
+			paragraph = append(paragraph, trimmed)
+		}
+	}
+	flushParagraph()
+	closeList()
+	return out.String()
+}
+
+// renderInline escapes the text and renders code spans, links and emphasis
+func renderInline(text string) string {
+	var out strings.Builder
+	parts := strings.Split(text, "`")
+	for i, part := range parts {
+		// odd parts are inside a code span, an unclosed backtick is kept as is
+		if i%2 == 1 && i < len(parts)-1 {
+			out.WriteString("<code>" + html.EscapeString(part) + "</code>")
+			continue
+		}
+		if i%2 == 1 {
+			out.WriteString("`")
+		}
+		escaped := html.EscapeString(part)
+		escaped = linkRe.ReplaceAllString(escaped, `<a href="$2">$1</a>`)
+		escaped = boldRe.ReplaceAllString(escaped, "<strong>$1$2</strong>")
+		escaped = italicRe.ReplaceAllString(escaped, "<em>$1$2</em>")
+		out.WriteString(escaped)
+	}
+	return out.String()
+}
+
+// Slug returns the anchor id of a heading
+func Slug(text string) string {
+	return strings.Trim(slugInvalid.ReplaceAllString(strings.ToLower(text), "-"), "-")
+}
+
+// Headings returns the headings of the markdown, skipping code blocks
+func Headings(markdown string) (ret []Heading) {
+	inCode := false
+	for _, line := range strings.Split(markdown, "\n") {
+		trimmed := strings.TrimSpace(line)
+		if strings.HasPrefix(trimmed, "```") {
+			inCode = !inCode
+			continue
+		}
+		if match := headingRe.FindStringSubmatch(trimmed); match != nil && !inCode {
+			text := strings.NewReplacer("**", "", "__", "", "`", "").Replace(match[2])
+			ret = append(ret, Heading{Level: len(match[1]), Text: text, ID: Slug(match[2])})
+		}
+	}
+	return
+}
+
+// Citations returns the links of the markdown, each URL once
+func Citations(markdown string) (ret []Citation) {
+	seen := map[string]bool{}
+	add := func(text, url string) {
+		if !seen[url] {
+			seen[url] = true
+			ret = append(ret, Citation{Number: len(ret) + 1, Text: text, URL: url})
+		}
+	}
+	for _, line := range strings.Split(markdown, "\n") {
+		for _, match := range markdownLink.FindAllStringSubmatch(line, -1) {
+			add(match[1], match[2])
+		}
+		for _, url := range bareURLRe.FindAllString(markdownLink.ReplaceAllString(line, ""), -1) {
+			add(url, url)
+		}
+	}
+	return
+}
@@ -0,0 +1,69 @@
+package export
+
+import (
+	"fmt"
+	"html"
+	"strings"
+	"time"
+)
+
+// Provenance tells who generated a document, so exported outputs stay traceable once shared
+type Provenance struct {
+	Version string // of fabric
+	Pattern string
+	Model   string
+	Date    time.Time
+}
+
+// Line describes the provenance in one sentence, e.g. "Generated with Fabric v1.4.300, pattern
+// summarize, model gpt-4o, on 2024-03-10T09:30:00Z."
+func (o Provenance) Line() string {
+	parts := []string{"Generated with Fabric"}
+	if o.Version != "" {
+		parts[0] += " " + o.Version
+	}
+	if o.Pattern != "" {
+		parts = append(parts, "pattern "+o.Pattern)
+	}
+	if o.Model != "" {
+		parts = append(parts, "model "+o.Model)
+	}
+	return fmt.Sprintf("%s, on %s.", strings.Join(parts, ", "), o.Date.Format(time.RFC3339))
+}
+
+// watermark embeds the provenance in a rendered document: meta tags in the head and a footer in the body
+// of HTML, which is kept when the HTML is printed to PDF, and a footer after a rule in markdown. It
+// applies to the custom styles as well, whatever their templates.
+func (o Provenance) watermark(format string, content string) string {
+	if format != FormatHTML {
+		return strings.TrimRight(content, "\n") + "\n\n---\n\n_" + o.Line() + "_\n"
+	}
+
+	var meta strings.Builder
+	writeMeta := func(name, value string) {
+		if value != "" {
+			fmt.Fprintf(&meta, "<meta name=\"%s\" content=\"%s\">\n", name, html.EscapeString(value))
+		}
+	}
+	writeMeta("generator", strings.TrimSpace("Fabric "+o.Version))
+	writeMeta("fabric:pattern", o.Pattern)
+	writeMeta("fabric:model", o.Model)
+	writeMeta("dcterms.created", o.Date.Format(time.RFC3339))
+	footer := `<footer class="fabric-provenance" style="margin-top: 2rem; color: #777; font-size: 0.8rem;">` +
+		html.EscapeString(o.Line()) + "</footer>\n"
+
+	content = insertBefore(content, "</head>", meta.String(), false)
+	return insertBefore(content, "</body>", footer, true)
+}
+
+// insertBefore inserts the text before the last closing tag, or at the start or the end of the content
+// when the template has none
+func insertBefore(content string, tag string, text string, atEnd bool) string {
+	if i := strings.LastIndex(strings.ToLower(content), tag); i >= 0 {
+		return content[:i] + text + content[i:]
+	}
+	if atEnd {
+		return content + text
+	}
+	return text + content
+}
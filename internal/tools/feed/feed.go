@@ -0,0 +1,315 @@
+package feed
+
+import (
+	"bytes"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"net/http"
+	"sort"
+	"strings"
+	"time"
+
+	"golang.org/x/net/html/charset"
+)
+
+const (
+	// DefaultEntries is the number of entries taken from a feed when no number is given
+	DefaultEntries = 5
+
+	maxFeedBytes = 16 << 20
+)
+
+// Entry is an entry of a feed, its content converted to markdown
+type Entry struct {
+	Title     string    `json:"title"`
+	Link      string    `json:"link,omitempty"`
+	Author    string    `json:"author,omitempty"`
+	Published time.Time `json:"published,omitempty"`
+	Content   string    `json:"content"`
+}
+
+// Feed is a RSS 2.0, RSS 1.0 or Atom feed, its entries the latest first
+type Feed struct {
+	Title   string   `json:"title"`
+	Link    string   `json:"link,omitempty"`
+	Entries []*Entry `json:"entries"`
+}
+
+// Client fetches feeds
+type Client struct {
+	HttpClient *http.Client
+}
+
+func NewClient() *Client {
+	return &Client{HttpClient: &http.Client{Timeout: 30 * time.Second}}
+}
+
+// Fetch reads the feed at feedURL
+func (o *Client) Fetch(feedURL string) (ret *Feed, err error) {
+	var req *http.Request
+	if req, err = http.NewRequest(http.MethodGet, feedURL, nil); err != nil {
+		err = fmt.Errorf("error fetching feed: %v", err)
+		return
+	}
+	req.Header.Set("Accept", "application/rss+xml, application/atom+xml, application/xml;q=0.9, */*;q=0.8")
+
+	var resp *http.Response
+	if resp, err = o.HttpClient.Do(req); err != nil {
+		err = fmt.Errorf("error fetching feed: %v", err)
+		return
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		err = fmt.Errorf("error fetching feed: %s", resp.Status)
+		return
+	}
+
+	var data []byte
+	if data, err = io.ReadAll(io.LimitReader(resp.Body, maxFeedBytes)); err != nil {
+		err = fmt.Errorf("error fetching feed: %v", err)
+		return
+	}
+	return Parse(data)
+}
+
+// Parse parses a RSS 2.0, RSS 1.0 (RDF) or Atom feed. Entries with a date are sorted the latest first,
+// feeds are usually in that order already.
+func Parse(data []byte) (ret *Feed, err error) {
+	var doc xmlFeed
+	decoder := xml.NewDecoder(bytes.NewReader(data))
+	decoder.CharsetReader = charset.NewReaderLabel
+	decoder.Strict = false
+	decoder.Entity = xml.HTMLEntity
+	if err = decoder.Decode(&doc); err != nil {
+		err = fmt.Errorf("error parsing feed: %v", err)
+		return
+	}
+
+	ret = &Feed{}
+	switch strings.ToLower(doc.XMLName.Local) {
+	case "rss", "rdf":
+		if doc.Channel == nil {
+			err = fmt.Errorf("error parsing feed: no channel")
+			return
+		}
+		ret.Title = strings.TrimSpace(doc.Channel.Title)
+		ret.Link = pickLink(doc.Channel.Links)
+		// RSS 1.0 has its items next to the channel, RSS 2.0 in it
+		for _, item := range append(doc.Channel.Items, doc.Items...) {
+			ret.Entries = append(ret.Entries, item.entry())
+		}
+	case "feed":
+		ret.Title = strings.TrimSpace(doc.Title.text())
+		ret.Link = pickLink(doc.Links)
+		for _, entry := range doc.Entries {
+			ret.Entries = append(ret.Entries, entry.entry())
+		}
+	default:
+		err = fmt.Errorf("error parsing feed: <%s> is not a RSS or Atom feed", doc.XMLName.Local)
+		return
+	}
+
+	sort.SliceStable(ret.Entries, func(i, j int) bool {
+		return ret.Entries[i].Published.After(ret.Entries[j].Published)
+	})
+	return
+}
+
+// Latest returns the latest entries of the feed, up to count
+func (o *Feed) Latest(count int) []*Entry {
+	if count <= 0 || count > len(o.Entries) {
+		return o.Entries
+	}
+	return o.Entries[:count]
+}
+
+// Markdown renders the latest entries of the feed, up to count, as a markdown document to send to a pattern
+func (o *Feed) Markdown(count int) string {
+	var builder strings.Builder
+	title := o.Title
+	if title == "" {
+		title = "Feed"
+	}
+	fmt.Fprintf(&builder, "# %s\n", title)
+	if o.Link != "" {
+		fmt.Fprintf(&builder, "\n%s\n", o.Link)
+	}
+	for _, entry := range o.Latest(count) {
+		builder.WriteString("\n")
+		builder.WriteString(entry.Markdown())
+	}
+	return builder.String()
+}
+
+// Markdown renders the entry as a markdown section
+func (o *Entry) Markdown() string {
+	var builder strings.Builder
+	title := o.Title
+	if title == "" {
+		title = "Untitled"
+	}
+	fmt.Fprintf(&builder, "## %s\n\n", title)
+	if o.Link != "" {
+		fmt.Fprintf(&builder, "- Link: %s\n", o.Link)
+	}
+	if o.Author != "" {
+		fmt.Fprintf(&builder, "- Author: %s\n", o.Author)
+	}
+	if !o.Published.IsZero() {
+		fmt.Fprintf(&builder, "- Published: %s\n", o.Published.Format(time.DateTime))
+	}
+	if o.Content != "" {
+		fmt.Fprintf(&builder, "\n%s\n", o.Content)
+	}
+	return builder.String()
+}
+
+type xmlFeed struct {
+	XMLName xml.Name
+	// Atom
+	Title   xmlText    `xml:"title"`
+	Links   []xmlLink  `xml:"link"`
+	Entries []xmlEntry `xml:"entry"`
+	// RSS
+	Channel *xmlChannel `xml:"channel"`
+	Items   []xmlItem   `xml:"item"`
+}
+
+type xmlChannel struct {
+	Title string    `xml:"title"`
+	Links []xmlLink `xml:"link"`
+	Items []xmlItem `xml:"item"`
+}
+
+// xmlLink is a RSS link with the URL as text or an Atom link with the URL in href
+type xmlLink struct {
+	Href string `xml:"href,attr"`
+	Rel  string `xml:"rel,attr"`
+	Text string `xml:",chardata"`
+}
+
+// xmlText is an Atom text construct, escaped HTML or inline XHTML by its type
+type xmlText struct {
+	Type  string `xml:"type,attr"`
+	Text  string `xml:",chardata"`
+	Inner string `xml:",innerxml"`
+}
+
+func (o xmlText) text() string {
+	if o.Type == "xhtml" {
+		return o.Inner
+	}
+	return o.Text
+}
+
+type xmlItem struct {
+	Title       string    `xml:"title"`
+	Links       []xmlLink `xml:"link"`
+	Guid        string    `xml:"guid"`
+	Description string    `xml:"description"`
+	Encoded     string    `xml:"encoded"`
+	PubDate     string    `xml:"pubDate"`
+	Date        string    `xml:"date"`
+	Author      string    `xml:"author"`
+	Creator     string    `xml:"creator"`
+}
+
+func (o xmlItem) entry() *Entry {
+	content := o.Encoded
+	if strings.TrimSpace(content) == "" {
+		content = o.Description
+	}
+	link := pickLink(o.Links)
+	if link == "" && strings.HasPrefix(o.Guid, "http") {
+		link = strings.TrimSpace(o.Guid)
+	}
+	return &Entry{
+		Title:     strings.TrimSpace(o.Title),
+		Link:      link,
+		Author:    firstNonEmpty(o.Creator, o.Author),
+		Published: parseDate(firstNonEmpty(o.PubDate, o.Date)),
+		Content:   HTMLToMarkdown(content),
+	}
+}
+
+type xmlEntry struct {
+	Title     xmlText   `xml:"title"`
+	Links     []xmlLink `xml:"link"`
+	Summary   xmlText   `xml:"summary"`
+	Content   xmlText   `xml:"content"`
+	Published string    `xml:"published"`
+	Updated   string    `xml:"updated"`
+	Authors   []struct {
+		Name string `xml:"name"`
+	} `xml:"author"`
+}
+
+func (o xmlEntry) entry() *Entry {
+	content := o.Content.text()
+	if strings.TrimSpace(content) == "" {
+		content = o.Summary.text()
+	}
+	var authors []string
+	for _, author := range o.Authors {
+		if name := strings.TrimSpace(author.Name); name != "" {
+			authors = append(authors, name)
+		}
+	}
+	return &Entry{
+		Title:     strings.TrimSpace(HTMLToMarkdown(o.Title.text())),
+		Link:      pickLink(o.Links),
+		Author:    strings.Join(authors, ", "),
+		Published: parseDate(firstNonEmpty(o.Published, o.Updated)),
+		Content:   HTMLToMarkdown(content),
+	}
+}
+
+// pickLink returns the alternate link, the page of the feed or entry, skipping the self links of the feeds
+func pickLink(links []xmlLink) string {
+	for _, link := range links {
+		if link.Rel != "" && link.Rel != "alternate" {
+			continue
+		}
+		if href := strings.TrimSpace(link.Href); href != "" {
+			return href
+		}
+		if text := strings.TrimSpace(link.Text); text != "" {
+			return text
+		}
+	}
+	return ""
+}
+
+var dateLayouts = []string{
+	time.RFC1123Z,
+	time.RFC1123,
+	time.RFC3339,
+	"Mon, 2 Jan 2006 15:04:05 -0700",
+	"Mon, 2 Jan 2006 15:04:05 MST",
+	"Mon, 2 Jan 2006 15:04 -0700",
+	"2 Jan 2006 15:04:05 -0700",
+	"2006-01-02T15:04:05",
+	"2006-01-02",
+}
+
+// parseDate parses the RFC 822 dates of RSS and the RFC 3339 dates of Atom, zero when it can't
+func parseDate(value string) time.Time {
+	value = strings.TrimSpace(value)
+	for _, layout := range dateLayouts {
+		if date, err := time.Parse(layout, value); err == nil {
+			return date
+		}
+	}
+	return time.Time{}
+}
+
+func firstNonEmpty(values ...string) string {
+	for _, value := range values {
+		if value = strings.TrimSpace(value); value != "" {
+			return value
+		}
+	}
+	return ""
+}
@@ -0,0 +1,153 @@
+package feed
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+const rssFeed = `<?xml version="1.0" encoding="UTF-8"?>
+<rss version="2.0" xmlns:content="http://purl.org/rss/1.0/modules/content/" xmlns:dc="http://purl.org/dc/elements/1.1/" xmlns:atom="http://www.w3.org/2005/Atom">
+<channel>
+<title>Weekly Notes</title>
+<atom:link href="https://example.com/feed.xml" rel="self"/>
+<link>https://example.com/</link>
+<item>
+<title>Older issue</title>
+<link>https://example.com/1</link>
+<pubDate>Mon, 01 Jan 2024 08:00:00 GMT</pubDate>
+<description>Plain &amp; short</description>
+</item>
+<item>
+<title>Newer issue</title>
+<guid>https://example.com/2</guid>
+<dc:creator>Jane</dc:creator>
+<pubDate>Mon, 08 Jan 2024 08:00:00 +0000</pubDate>
+<description>Summary only</description>
+<content:encoded><![CDATA[<h2>Top story</h2><p>Read <a href="https://example.com/story">the <b>story</b></a>.</p><ul><li>one</li><li>two</li></ul><script>alert(1)</script>]]></content:encoded>
+</item>
+</channel>
+</rss>`
+
+const atomFeed = `<?xml version="1.0" encoding="utf-8"?>
+<feed xmlns="http://www.w3.org/2005/Atom">
+<title>Atom Blog</title>
+<link href="https://blog.example.com/atom.xml" rel="self"/>
+<link href="https://blog.example.com/"/>
+<entry>
+<title type="html">Hello &lt;em&gt;world&lt;/em&gt;</title>
+<link href="https://blog.example.com/hello" rel="alternate"/>
+<updated>2024-02-01T10:00:00Z</updated>
+<author><name>Sam</name></author>
+<summary>The summary</summary>
+<content type="xhtml"><div xmlns="http://www.w3.org/1999/xhtml"><p>Inline <code>xhtml</code> content</p></div></content>
+</entry>
+</feed>`
+
+func TestParseRSS(t *testing.T) {
+	feed, err := Parse([]byte(rssFeed))
+	if err != nil {
+		t.Fatalf("Parse failed: %v", err)
+	}
+	if feed.Title != "Weekly Notes" || feed.Link != "https://example.com/" {
+		t.Errorf("unexpected feed: %q %q", feed.Title, feed.Link)
+	}
+	if len(feed.Entries) != 2 {
+		t.Fatalf("expected 2 entries, got %d", len(feed.Entries))
+	}
+
+	latest := feed.Entries[0]
+	if latest.Title != "Newer issue" || latest.Link != "https://example.com/2" || latest.Author != "Jane" {
+		t.Errorf("unexpected latest entry: %+v", latest)
+	}
+	expected := "#### Top story\n\nRead [the **story**](https://example.com/story).\n\n- one\n- two"
+	if latest.Content != expected {
+		t.Errorf("unexpected content:\n%s\nexpected:\n%s", latest.Content, expected)
+	}
+	if feed.Entries[1].Content != "Plain & short" {
+		t.Errorf("unexpected content of the older entry: %q", feed.Entries[1].Content)
+	}
+}
+
+func TestParseRDF(t *testing.T) {
+	data := `<?xml version="1.0"?>
+<rdf:RDF xmlns:rdf="http://www.w3.org/1999/02/22-rdf-syntax-ns#" xmlns="http://purl.org/rss/1.0/" xmlns:dc="http://purl.org/dc/elements/1.1/">
+<channel><title>RDF News</title><link>https://rdf.example.com/</link></channel>
+<item><title>First</title><link>https://rdf.example.com/1</link><dc:date>2024-03-01T00:00:00Z</dc:date><description>Text</description></item>
+</rdf:RDF>`
+	feed, err := Parse([]byte(data))
+	if err != nil {
+		t.Fatalf("Parse failed: %v", err)
+	}
+	if feed.Title != "RDF News" || len(feed.Entries) != 1 || feed.Entries[0].Published.IsZero() {
+		t.Errorf("unexpected feed: %+v %+v", feed, feed.Entries)
+	}
+}
+
+func TestParseAtom(t *testing.T) {
+	feed, err := Parse([]byte(atomFeed))
+	if err != nil {
+		t.Fatalf("Parse failed: %v", err)
+	}
+	if feed.Title != "Atom Blog" || feed.Link != "https://blog.example.com/" {
+		t.Errorf("unexpected feed: %q %q", feed.Title, feed.Link)
+	}
+	if len(feed.Entries) != 1 {
+		t.Fatalf("expected 1 entry, got %d", len(feed.Entries))
+	}
+	entry := feed.Entries[0]
+	if entry.Title != "Hello _world_" || entry.Link != "https://blog.example.com/hello" || entry.Author != "Sam" {
+		t.Errorf("unexpected entry: %+v", entry)
+	}
+	if entry.Content != "Inline `xhtml` content" {
+		t.Errorf("unexpected content: %q", entry.Content)
+	}
+}
+
+func TestParseNotAFeed(t *testing.T) {
+	if _, err := Parse([]byte(`<html><body>page</body></html>`)); err == nil {
+		t.Error("expected an error for a HTML page")
+	}
+}
+
+func TestMarkdownLatest(t *testing.T) {
+	feed, err := Parse([]byte(rssFeed))
+	if err != nil {
+		t.Fatalf("Parse failed: %v", err)
+	}
+	markdown := feed.Markdown(1)
+	if !strings.HasPrefix(markdown, "# Weekly Notes\n\nhttps://example.com/\n\n## Newer issue\n\n- Link: https://example.com/2\n- Author: Jane\n- Published: 2024-01-08 08:00:00\n") {
+		t.Errorf("unexpected markdown:\n%s", markdown)
+	}
+	if strings.Contains(markdown, "Older issue") {
+		t.Error("expected only the latest entry")
+	}
+	if len(feed.Latest(0)) != 2 || len(feed.Latest(10)) != 2 {
+		t.Error("expected all the entries without a limit or with a larger one")
+	}
+}
+
+func TestFetch(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/feed.xml" {
+			http.NotFound(w, r)
+			return
+		}
+		w.Header().Set("Content-Type", "application/rss+xml")
+		w.Write([]byte(rssFeed))
+	}))
+	defer server.Close()
+
+	client := NewClient()
+	feed, err := client.Fetch(server.URL + "/feed.xml")
+	if err != nil {
+		t.Fatalf("Fetch failed: %v", err)
+	}
+	if len(feed.Entries) != 2 {
+		t.Errorf("expected 2 entries, got %d", len(feed.Entries))
+	}
+	if _, err = client.Fetch(server.URL + "/missing.xml"); err == nil {
+		t.Error("expected an error for a missing feed")
+	}
+}
@@ -0,0 +1,254 @@
+package feed
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"golang.org/x/net/html"
+	"golang.org/x/net/html/atom"
+)
+
+var (
+	whitespace     = regexp.MustCompile(`\s+`)
+	trailingSpaces = regexp.MustCompile(`[ \t]+\n`)
+	blankLines     = regexp.MustCompile(`\n{3,}`)
+	emptyLines     = regexp.MustCompile(`\n{2,}`)
+	codeLanguage   = regexp.MustCompile(`language-(\w+)`)
+)
+
+// dropped are the elements that are never content
+var dropped = map[atom.Atom]bool{
+	atom.Script: true, atom.Style: true, atom.Noscript: true, atom.Template: true, atom.Head: true,
+	atom.Iframe: true, atom.Svg: true, atom.Canvas: true, atom.Button: true, atom.Input: true,
+	atom.Select: true, atom.Form: true,
+}
+
+var blocks = map[atom.Atom]bool{
+	atom.P: true, atom.Div: true, atom.Section: true, atom.Article: true, atom.Main: true, atom.Header: true,
+	atom.Footer: true, atom.Aside: true, atom.Nav: true, atom.Figure: true, atom.Figcaption: true,
+	atom.Dl: true, atom.Dt: true, atom.Dd: true, atom.Address: true,
+}
+
+type htmlContext struct {
+	listDepth int
+	pre       bool
+}
+
+// HTMLToMarkdown converts the HTML of feed entries to markdown: headings, paragraphs, emphasis, links,
+// lists, quotes, code and tables are kept, the rest of the markup is dropped. Plain text is kept as it is.
+func HTMLToMarkdown(content string) string {
+	if !strings.Contains(content, "<") && !strings.Contains(content, "&") {
+		return strings.TrimSpace(content)
+	}
+	nodes, err := html.ParseFragment(strings.NewReader(content), &html.Node{Type: html.ElementNode, Data: "body", DataAtom: atom.Body})
+	if err != nil {
+		return strings.TrimSpace(content)
+	}
+	var builder strings.Builder
+	for _, node := range nodes {
+		builder.WriteString(convertNode(node, htmlContext{}))
+	}
+	markdown := trailingSpaces.ReplaceAllString(builder.String(), "\n")
+	return strings.TrimSpace(blankLines.ReplaceAllString(markdown, "\n\n"))
+}
+
+func convertChildren(node *html.Node, context htmlContext) string {
+	var builder strings.Builder
+	for child := node.FirstChild; child != nil; child = child.NextSibling {
+		builder.WriteString(convertNode(child, context))
+	}
+	return builder.String()
+}
+
+func convertNode(node *html.Node, context htmlContext) string {
+	if node.Type == html.TextNode {
+		if context.pre {
+			return node.Data
+		}
+		return whitespace.ReplaceAllString(node.Data, " ")
+	}
+	if node.Type != html.ElementNode || dropped[node.DataAtom] || attr(node, "aria-hidden") == "true" {
+		return ""
+	}
+
+	inner := func() string { return convertChildren(node, context) }
+	switch node.DataAtom {
+	case atom.H1, atom.H2, atom.H3, atom.H4, atom.H5, atom.H6:
+		// the entries are sections of the feed document, so their headings start at level 3
+		level := min(int(node.Data[1]-'0')+2, 6)
+		if text := inline(inner()); text != "" {
+			return fmt.Sprintf("\n\n%s %s\n\n", strings.Repeat("#", level), text)
+		}
+		return ""
+	case atom.Br:
+		return "\n"
+	case atom.Hr:
+		return "\n\n---\n\n"
+	case atom.Strong, atom.B:
+		return wrap(inner(), "**")
+	case atom.Em, atom.I:
+		return wrap(inner(), "_")
+	case atom.Del, atom.S:
+		return wrap(inner(), "~~")
+	case atom.Code:
+		if context.pre {
+			return inner()
+		}
+		return wrap(textContent(node), "`")
+	case atom.Pre:
+		code := strings.TrimRight(convertChildren(node, htmlContext{listDepth: context.listDepth, pre: true}), "\n")
+		language := ""
+		for child := node.FirstChild; child != nil; child = child.NextSibling {
+			if child.DataAtom == atom.Code {
+				if match := codeLanguage.FindStringSubmatch(attr(child, "class")); match != nil {
+					language = match[1]
+				}
+			}
+		}
+		return fmt.Sprintf("\n\n```%s\n%s\n```\n\n", language, code)
+	case atom.A:
+		text := inline(inner())
+		href := attr(node, "href")
+		if text == "" {
+			return ""
+		}
+		if href == "" || strings.HasPrefix(href, "#") || strings.HasPrefix(href, "javascript:") {
+			return text
+		}
+		return fmt.Sprintf("[%s](%s)", text, href)
+	case atom.Img:
+		alt, src := attr(node, "alt"), attr(node, "src")
+		// images without a description are decoration for the patterns
+		if alt == "" || src == "" || strings.HasPrefix(src, "data:") {
+			return ""
+		}
+		return fmt.Sprintf("![%s](%s)", alt, src)
+	case atom.Blockquote:
+		text := blankLines.ReplaceAllString(strings.TrimSpace(inner()), "\n\n")
+		lines := strings.Split(text, "\n")
+		for i, line := range lines {
+			lines[i] = strings.TrimRight("> "+line, " ")
+		}
+		return "\n\n" + strings.Join(lines, "\n") + "\n\n"
+	case atom.Ul, atom.Ol:
+		return "\n\n" + convertList(node, context) + "\n\n"
+	case atom.Table:
+		return "\n\n" + convertTable(node, context) + "\n\n"
+	}
+	if blocks[node.DataAtom] {
+		return "\n\n" + inner() + "\n\n"
+	}
+	return inner()
+}
+
+func convertList(node *html.Node, context htmlContext) string {
+	ordered := node.DataAtom == atom.Ol
+	indent := strings.Repeat("  ", context.listDepth)
+	number := 1
+	if start, err := strconv.Atoi(attr(node, "start")); err == nil {
+		number = start
+	}
+	var items []string
+	for child := node.FirstChild; child != nil; child = child.NextSibling {
+		if child.DataAtom != atom.Li {
+			continue
+		}
+		marker := "-"
+		if ordered {
+			marker = fmt.Sprintf("%d.", number)
+			number++
+		}
+		text := convertChildren(child, htmlContext{listDepth: context.listDepth + 1, pre: context.pre})
+		text = strings.TrimSpace(emptyLines.ReplaceAllString(text, "\n"))
+		items = append(items, fmt.Sprintf("%s%s %s", indent, marker, text))
+	}
+	return strings.Join(items, "\n")
+}
+
+func convertTable(node *html.Node, context htmlContext) string {
+	var rows [][]string
+	var collect func(*html.Node)
+	collect = func(node *html.Node) {
+		for child := node.FirstChild; child != nil; child = child.NextSibling {
+			if child.DataAtom != atom.Tr {
+				collect(child)
+				continue
+			}
+			var cells []string
+			for cell := child.FirstChild; cell != nil; cell = cell.NextSibling {
+				if cell.DataAtom == atom.Td || cell.DataAtom == atom.Th {
+					cells = append(cells, strings.ReplaceAll(inline(convertChildren(cell, context)), "|", `\|`))
+				}
+			}
+			if len(cells) > 0 {
+				rows = append(rows, cells)
+			}
+		}
+	}
+	collect(node)
+	if len(rows) == 0 {
+		return ""
+	}
+
+	columns := 0
+	for _, cells := range rows {
+		columns = max(columns, len(cells))
+	}
+	line := func(cells []string) string {
+		padded := make([]string, columns)
+		copy(padded, cells)
+		return "| " + strings.Join(padded, " | ") + " |"
+	}
+	separator := make([]string, columns)
+	for i := range separator {
+		separator[i] = "---"
+	}
+	lines := []string{line(rows[0]), line(separator)}
+	for _, cells := range rows[1:] {
+		lines = append(lines, line(cells))
+	}
+	return strings.Join(lines, "\n")
+}
+
+func attr(node *html.Node, name string) string {
+	for _, attribute := range node.Attr {
+		if attribute.Key == name {
+			return attribute.Val
+		}
+	}
+	return ""
+}
+
+func textContent(node *html.Node) string {
+	if node.Type == html.TextNode {
+		return node.Data
+	}
+	var builder strings.Builder
+	for child := node.FirstChild; child != nil; child = child.NextSibling {
+		builder.WriteString(textContent(child))
+	}
+	return builder.String()
+}
+
+// inline collapses the text of an inline element to a single line
+func inline(text string) string {
+	return strings.TrimSpace(whitespace.ReplaceAllString(text, " "))
+}
+
+// wrap puts emphasis markers around the text, they have to touch it so the surrounding spaces stay outside
+func wrap(text string, marker string) string {
+	trimmed := strings.TrimSpace(text)
+	if trimmed == "" {
+		return text
+	}
+	leading, trailing := "", ""
+	if strings.HasPrefix(text, " ") {
+		leading = " "
+	}
+	if strings.HasSuffix(text, " ") {
+		trailing = " "
+	}
+	return leading + marker + trimmed + marker + trailing
+}
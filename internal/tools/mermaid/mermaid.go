@@ -0,0 +1,80 @@
+// Package mermaid renders the mermaid diagrams of the outputs, like the ones of create_visualization,
+// to SVG with the mermaid CLI.
+package mermaid
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"regexp"
+	"strings"
+)
+
+// DefaultCommand is the mermaid CLI, installed with npm install -g @mermaid-js/mermaid-cli
+const DefaultCommand = "mmdc"
+
+// MaxSourceSize limits the diagrams rendered, the larger ones are left as text
+const MaxSourceSize = 64 << 10
+
+var fencePattern = regexp.MustCompile("(?ms)^ {0,3}(```|~~~)[ \t]*mermaid[ \t]*\r?\n(.*?)^ {0,3}(```|~~~)[ \t]*$")
+
+// Blocks returns the sources of the mermaid code fences of the markdown, in order
+func Blocks(markdown string) (ret []string) {
+	for _, match := range fencePattern.FindAllStringSubmatch(markdown, -1) {
+		ret = append(ret, strings.TrimRight(match[2], "\r\n"))
+	}
+	return
+}
+
+// Renderer renders the diagrams with Command, the mermaid CLI
+type Renderer struct {
+	Command string
+}
+
+func NewRenderer() *Renderer {
+	return &Renderer{Command: DefaultCommand}
+}
+
+// Available tells if the mermaid CLI is installed
+func (o *Renderer) Available() bool {
+	_, err := exec.LookPath(o.Command)
+	return err == nil
+}
+
+// RenderSVG renders the diagram to SVG, on a transparent background
+func (o *Renderer) RenderSVG(ctx context.Context, source string) (ret []byte, err error) {
+	if strings.TrimSpace(source) == "" {
+		err = fmt.Errorf("the diagram is empty")
+		return
+	}
+	if len(source) > MaxSourceSize {
+		err = fmt.Errorf("the diagram is too large to render")
+		return
+	}
+	if !o.Available() {
+		err = fmt.Errorf("%s not found, install the mermaid CLI with npm install -g @mermaid-js/mermaid-cli", o.Command)
+		return
+	}
+
+	var dir string
+	if dir, err = os.MkdirTemp("", "fabric-mermaid-"); err != nil {
+		return
+	}
+	defer os.RemoveAll(dir)
+
+	inputPath, outputPath := filepath.Join(dir, "diagram.mmd"), filepath.Join(dir, "diagram.svg")
+	if err = os.WriteFile(inputPath, []byte(source), 0o600); err != nil {
+		return
+	}
+	cmd := exec.CommandContext(ctx, o.Command, "--quiet", "-i", inputPath, "-o", outputPath, "-b", "transparent")
+	if output, renderErr := cmd.CombinedOutput(); renderErr != nil {
+		err = fmt.Errorf("could not render the diagram: %v: %s", renderErr, strings.TrimSpace(string(output)))
+		return
+	}
+	if ret, err = os.ReadFile(outputPath); err != nil {
+		err = fmt.Errorf("could not render the diagram: %v", err)
+	}
+	return
+}
@@ -0,0 +1,32 @@
+package mermaid
+
+import (
+	"context"
+	"strings"
+	"testing"
+)
+
+func TestBlocks(t *testing.T) {
+	markdown := "# Flow\n\n```mermaid\ngraph TD\n  A --> B\n```\n\n```go\nfmt.Println()\n```\n\n~~~ mermaid\nsequenceDiagram\n  A->>B: hi\n~~~\n"
+	blocks := Blocks(markdown)
+	if len(blocks) != 2 || blocks[0] != "graph TD\n  A --> B" || blocks[1] != "sequenceDiagram\n  A->>B: hi" {
+		t.Errorf("unexpected blocks: %q", blocks)
+	}
+	if blocks := Blocks("```mermaid\nnot closed"); len(blocks) != 0 {
+		t.Errorf("expected no blocks, got %q", blocks)
+	}
+}
+
+func TestRenderSVG_MissingCommand(t *testing.T) {
+	renderer := &Renderer{Command: "fabric-test-missing-mmdc"}
+	if renderer.Available() {
+		t.Fatal("the command should not be available")
+	}
+	if _, err := renderer.RenderSVG(context.Background(), "graph TD\n  A --> B"); err == nil ||
+		!strings.Contains(err.Error(), "not found") {
+		t.Errorf("expected a not found error, got %v", err)
+	}
+	if _, err := renderer.RenderSVG(context.Background(), " "); err == nil {
+		t.Error("expected an error for an empty diagram")
+	}
+}
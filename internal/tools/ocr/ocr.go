@@ -0,0 +1,101 @@
+package ocr
+
+// see https://github.com/tesseract-ocr/tesseract for more information
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"os/exec"
+	"strings"
+
+	"github.com/danielmiessler/fabric/internal/plugins"
+)
+
+// DefaultBinary is the command line tool of Tesseract
+const DefaultBinary = "tesseract"
+
+// DefaultLanguages are the Tesseract languages used when none are configured
+const DefaultLanguages = "eng"
+
+// Tesseract recognizes the text of images locally with the Tesseract command line tool
+type Tesseract struct {
+	*plugins.PluginBase
+	Binary    *plugins.SetupQuestion
+	Languages *plugins.SetupQuestion
+}
+
+func NewTesseract() (ret *Tesseract) {
+	label := "Tesseract"
+
+	ret = &Tesseract{
+		PluginBase: &plugins.PluginBase{
+			Name:             label,
+			SetupDescription: "Tesseract - local text recognition (OCR) for screenshot input",
+			EnvNamePrefix:    plugins.BuildEnvVariablePrefix(label),
+		},
+	}
+
+	ret.Binary = ret.AddSetupQuestionCustom("Binary", false,
+		fmt.Sprintf("Enter the Tesseract command (leave empty for %s)", DefaultBinary))
+	ret.Languages = ret.AddSetupQuestionCustom("Languages", false,
+		fmt.Sprintf("Enter the Tesseract languages to recognize joined with +, e.g. eng+deu (leave empty for %s)", DefaultLanguages))
+
+	return
+}
+
+// Recognize returns the text of the image, PNG or any other format Tesseract reads. languages overrides
+// the configured ones, they are joined with + and must be installed as Tesseract language data.
+func (o *Tesseract) Recognize(ctx context.Context, image []byte, languages string) (ret string, err error) {
+	binary := o.Binary.Value
+	if binary == "" {
+		binary = DefaultBinary
+	}
+	if languages == "" {
+		languages = o.Languages.Value
+	}
+	if languages == "" {
+		languages = DefaultLanguages
+	}
+	if _, err = exec.LookPath(binary); err != nil {
+		err = fmt.Errorf("tesseract not found, install it to recognize screenshots: %v", err)
+		return
+	}
+
+	var stdout, stderr bytes.Buffer
+	cmd := exec.CommandContext(ctx, binary, BuildArgs(languages)...)
+	cmd.Stdin = bytes.NewReader(image)
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+	if err = cmd.Run(); err != nil {
+		err = fmt.Errorf("tesseract failed: %v: %s", err, strings.TrimSpace(stderr.String()))
+		return
+	}
+	ret = CleanText(stdout.String())
+	return
+}
+
+// BuildArgs returns the tesseract arguments reading the image from stdin and printing the text to stdout
+func BuildArgs(languages string) []string {
+	return []string{"stdin", "stdout", "-l", languages}
+}
+
+// CleanText removes the page breaks and trailing spaces Tesseract prints and keeps at most one blank line
+// between the paragraphs
+func CleanText(output string) string {
+	var lines []string
+	blank := false
+	for _, line := range strings.Split(strings.ReplaceAll(output, "\f", "\n"), "\n") {
+		line = strings.TrimRight(line, " \t\r")
+		if line == "" {
+			blank = len(lines) > 0
+			continue
+		}
+		if blank {
+			lines = append(lines, "")
+			blank = false
+		}
+		lines = append(lines, line)
+	}
+	return strings.Join(lines, "\n")
+}
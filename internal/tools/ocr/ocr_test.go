@@ -0,0 +1,55 @@
+package ocr
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"runtime"
+	"testing"
+)
+
+func TestCleanText(t *testing.T) {
+	output := "\n  Title  \n\n\n\nFirst line\nsecond line \n\f"
+	if got := CleanText(output); got != "  Title\n\nFirst line\nsecond line" {
+		t.Errorf("unexpected text: %q", got)
+	}
+}
+
+func TestRecognize(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("uses a shell script as tesseract binary")
+	}
+
+	// stands in for tesseract, echoes the arguments and the image back as text
+	binary := filepath.Join(t.TempDir(), "tesseract")
+	if err := os.WriteFile(binary, []byte("#!/bin/sh\necho \"$@\"\ncat\necho\nprintf '\\f'\n"), 0755); err != nil {
+		t.Fatal(err)
+	}
+
+	tesseract := NewTesseract()
+	tesseract.Binary.Value = binary
+
+	text, err := tesseract.Recognize(context.Background(), []byte("image"), "")
+	if err != nil {
+		t.Fatalf("Recognize failed: %v", err)
+	}
+	if text != "stdin stdout -l eng\nimage" {
+		t.Errorf("unexpected text: %q", text)
+	}
+
+	tesseract.Languages.Value = "eng+deu"
+	if text, err = tesseract.Recognize(context.Background(), []byte("image"), "fra"); err != nil {
+		t.Fatalf("Recognize failed: %v", err)
+	}
+	if text != "stdin stdout -l fra\nimage" {
+		t.Errorf("expected the languages argument to override the configured ones, got %q", text)
+	}
+}
+
+func TestRecognizeMissingBinary(t *testing.T) {
+	tesseract := NewTesseract()
+	tesseract.Binary.Value = filepath.Join(t.TempDir(), "missing")
+	if _, err := tesseract.Recognize(context.Background(), []byte("image"), ""); err == nil {
+		t.Error("expected an error without tesseract")
+	}
+}
@@ -327,23 +327,24 @@ func (o *PatternsLoader) createUniquePatternsFile() (err error) {
 		}
 	}
 
-	// Add patterns from custom patterns directory if it exists
-	if o.Patterns.CustomPatternsDir != "" {
-		if customEntries, customErr := os.ReadDir(o.Patterns.CustomPatternsDir); customErr == nil {
+	// Add patterns from the custom patterns directories
+	customDirs := o.Patterns.CustomPatternsDirs()
+	for _, dir := range customDirs {
+		if customEntries, customErr := os.ReadDir(dir); customErr == nil {
 			for _, entry := range customEntries {
 				if entry.IsDir() {
 					patternNamesMap[entry.Name()] = true
 				}
 			}
-			fmt.Fprintf(os.Stderr, "📂 Also included patterns from custom directory: %s\n", o.Patterns.CustomPatternsDir)
+			fmt.Fprintf(os.Stderr, "📂 Also included patterns from custom directory: %s\n", dir)
 		} else {
-			fmt.Fprintf(os.Stderr, "Warning: Could not read custom patterns directory %s: %v\n", o.Patterns.CustomPatternsDir, customErr)
+			fmt.Fprintf(os.Stderr, "Warning: Could not read custom patterns directory %s: %v\n", dir, customErr)
 		}
 	}
 
 	if len(patternNamesMap) == 0 {
-		if o.Patterns.CustomPatternsDir != "" {
-			return fmt.Errorf("no patterns found in directories %s and %s", o.Patterns.Dir, o.Patterns.CustomPatternsDir)
+		if len(customDirs) > 0 {
+			return fmt.Errorf("no patterns found in directories %s and %s", o.Patterns.Dir, strings.Join(customDirs, ", "))
 		}
 		return fmt.Errorf("no patterns found in directory %s", o.Patterns.Dir)
 	}
@@ -0,0 +1,160 @@
+package podcast
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// TranscribeFunc transcribes the audio file at audioPath, e.g. through a speech-to-text vendor
+type TranscribeFunc func(audioPath string) (string, error)
+
+// Podcast lists the episodes of a podcast RSS feed and transcribes their audio.
+// Transcripts are cached in CacheDir so an episode is transcribed only once.
+type Podcast struct {
+	CacheDir   string
+	HttpClient *http.Client
+}
+
+func NewPodcast(cacheDir string) *Podcast {
+	return &Podcast{
+		CacheDir:   cacheDir,
+		HttpClient: &http.Client{Timeout: 30 * time.Minute},
+	}
+}
+
+type Episode struct {
+	Number    int // 1 is the latest episode of the feed
+	Title     string
+	Published string
+	AudioURL  string
+}
+
+type rssFeed struct {
+	Channel struct {
+		Title string    `xml:"title"`
+		Items []rssItem `xml:"item"`
+	} `xml:"channel"`
+}
+
+type rssItem struct {
+	Title     string `xml:"title"`
+	PubDate   string `xml:"pubDate"`
+	Enclosure struct {
+		URL  string `xml:"url,attr"`
+		Type string `xml:"type,attr"`
+	} `xml:"enclosure"`
+}
+
+// FetchEpisodes reads the feed and returns its episodes with audio, in feed order
+func (o *Podcast) FetchEpisodes(feedURL string) (ret []*Episode, err error) {
+	var resp *http.Response
+	if resp, err = o.HttpClient.Get(feedURL); err != nil {
+		err = fmt.Errorf("error fetching podcast feed: %v", err)
+		return
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		err = fmt.Errorf("error fetching podcast feed: %s", resp.Status)
+		return
+	}
+
+	var feed rssFeed
+	if err = xml.NewDecoder(resp.Body).Decode(&feed); err != nil {
+		err = fmt.Errorf("error parsing podcast feed: %v", err)
+		return
+	}
+
+	for _, item := range feed.Channel.Items {
+		if item.Enclosure.URL == "" {
+			continue
+		}
+		title := strings.TrimSpace(item.Title)
+		ret = append(ret, &Episode{
+			Number:    len(ret) + 1,
+			Title:     title,
+			Published: strings.TrimSpace(item.PubDate),
+			AudioURL:  item.Enclosure.URL,
+		})
+	}
+	return
+}
+
+// Transcript returns the transcript of the episode, from the cache if available. Otherwise the
+// audio is downloaded to a temporary file, transcribed and the transcript is cached.
+func (o *Podcast) Transcript(episode *Episode, transcribe TranscribeFunc) (ret string, err error) {
+	cacheFile := o.cacheFilePath(episode)
+	if content, readErr := os.ReadFile(cacheFile); readErr == nil {
+		ret = string(content)
+		return
+	}
+
+	var audioPath string
+	if audioPath, err = o.downloadAudio(episode.AudioURL); err != nil {
+		return
+	}
+	defer os.Remove(audioPath)
+
+	if ret, err = transcribe(audioPath); err != nil {
+		err = fmt.Errorf("error transcribing episode %q: %v", episode.Title, err)
+		return
+	}
+
+	if err = os.MkdirAll(o.CacheDir, 0755); err != nil {
+		err = fmt.Errorf("error creating transcript cache directory: %v", err)
+		return
+	}
+	if err = os.WriteFile(cacheFile, []byte(ret), 0644); err != nil {
+		err = fmt.Errorf("error caching transcript: %v", err)
+	}
+	return
+}
+
+func (o *Podcast) cacheFilePath(episode *Episode) string {
+	sum := sha256.Sum256([]byte(episode.AudioURL))
+	return filepath.Join(o.CacheDir, hex.EncodeToString(sum[:])+".txt")
+}
+
+func (o *Podcast) downloadAudio(audioURL string) (ret string, err error) {
+	var resp *http.Response
+	if resp, err = o.HttpClient.Get(audioURL); err != nil {
+		err = fmt.Errorf("error downloading episode audio: %v", err)
+		return
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		err = fmt.Errorf("error downloading episode audio: %s", resp.Status)
+		return
+	}
+
+	// keep the extension, speech-to-text backends detect the audio format from it
+	ext := ".mp3"
+	if parsed, parseErr := url.Parse(audioURL); parseErr == nil && path.Ext(parsed.Path) != "" {
+		ext = path.Ext(parsed.Path)
+	}
+
+	var file *os.File
+	if file, err = os.CreateTemp("", "fabric-podcast-*"+ext); err != nil {
+		return
+	}
+	defer file.Close()
+
+	if _, err = io.Copy(file, resp.Body); err != nil {
+		os.Remove(file.Name())
+		err = fmt.Errorf("error downloading episode audio: %v", err)
+		return
+	}
+	ret = file.Name()
+	return
+}
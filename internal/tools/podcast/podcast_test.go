@@ -0,0 +1,77 @@
+package podcast
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"testing"
+)
+
+func newTestServer(t *testing.T) *httptest.Server {
+	t.Helper()
+	mux := http.NewServeMux()
+	server := httptest.NewServer(mux)
+	mux.HandleFunc("/feed.xml", func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprintf(w, `<?xml version="1.0"?>
+<rss version="2.0"><channel><title>Test Cast</title>
+<item><title>Episode Two: More!</title><pubDate>Tue, 02 Jan 2024 00:00:00 GMT</pubDate>
+<enclosure url="%[1]s/two.mp3" type="audio/mpeg"/></item>
+<item><title>Show notes only</title></item>
+<item><title>Episode One</title><enclosure url="%[1]s/one.m4a" type="audio/mp4"/></item>
+</channel></rss>`, server.URL)
+	})
+	mux.HandleFunc("/two.mp3", func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte("audio"))
+	})
+	t.Cleanup(server.Close)
+	return server
+}
+
+func TestFetchEpisodes(t *testing.T) {
+	server := newTestServer(t)
+	podcast := NewPodcast(t.TempDir())
+
+	episodes, err := podcast.FetchEpisodes(server.URL + "/feed.xml")
+	if err != nil {
+		t.Fatalf("FetchEpisodes failed: %v", err)
+	}
+	if len(episodes) != 2 {
+		t.Fatalf("expected 2 episodes with audio, got %d", len(episodes))
+	}
+	if episodes[0].Number != 1 || episodes[0].Title != "Episode Two: More!" {
+		t.Errorf("unexpected first episode: %+v", episodes[0])
+	}
+	if episodes[1].Number != 2 || episodes[1].AudioURL != server.URL+"/one.m4a" {
+		t.Errorf("unexpected second episode: %+v", episodes[1])
+	}
+}
+
+func TestTranscriptIsCached(t *testing.T) {
+	server := newTestServer(t)
+	podcast := NewPodcast(t.TempDir())
+	episode := &Episode{Title: "Episode Two", AudioURL: server.URL + "/two.mp3"}
+
+	calls := 0
+	transcribe := func(audioPath string) (string, error) {
+		calls++
+		content, err := os.ReadFile(audioPath)
+		if err != nil {
+			return "", err
+		}
+		return "transcript of " + string(content), nil
+	}
+
+	for i := 0; i < 2; i++ {
+		transcript, err := podcast.Transcript(episode, transcribe)
+		if err != nil {
+			t.Fatalf("Transcript failed: %v", err)
+		}
+		if transcript != "transcript of audio" {
+			t.Errorf("unexpected transcript: %q", transcript)
+		}
+	}
+	if calls != 1 {
+		t.Errorf("expected audio to be transcribed once, got %d calls", calls)
+	}
+}
@@ -0,0 +1,87 @@
+package precheck
+
+// misspellings maps common misspellings of English words to their correct spelling
+var misspellings = map[string]string{
+	"accomodate":     "accommodate",
+	"acheive":        "achieve",
+	"accross":        "across",
+	"adress":         "address",
+	"agressive":      "aggressive",
+	"alot":           "a lot",
+	"apparantly":     "apparently",
+	"arguement":      "argument",
+	"basicly":        "basically",
+	"beacuse":        "because",
+	"becuase":        "because",
+	"begining":       "beginning",
+	"beleive":        "believe",
+	"buisness":       "business",
+	"calender":       "calendar",
+	"comming":        "coming",
+	"commited":       "committed",
+	"completly":      "completely",
+	"concious":       "conscious",
+	"definately":     "definitely",
+	"definitly":      "definitely",
+	"dependant":      "dependent",
+	"desicion":       "decision",
+	"diffrent":       "different",
+	"dissapoint":     "disappoint",
+	"embarass":       "embarrass",
+	"enviroment":     "environment",
+	"existance":      "existence",
+	"experiance":     "experience",
+	"familar":        "familiar",
+	"finaly":         "finally",
+	"foriegn":        "foreign",
+	"freind":         "friend",
+	"goverment":      "government",
+	"grammer":        "grammar",
+	"guarentee":      "guarantee",
+	"happend":        "happened",
+	"immediatly":     "immediately",
+	"independant":    "independent",
+	"knowlege":       "knowledge",
+	"lenght":         "length",
+	"liason":         "liaison",
+	"libary":         "library",
+	"maintainance":   "maintenance",
+	"neccessary":     "necessary",
+	"necesary":       "necessary",
+	"noticable":      "noticeable",
+	"occassion":      "occasion",
+	"occured":        "occurred",
+	"occurence":      "occurrence",
+	"occuring":       "occurring",
+	"oppurtunity":    "opportunity",
+	"paralel":        "parallel",
+	"persistant":     "persistent",
+	"posession":      "possession",
+	"prefered":       "preferred",
+	"probaly":        "probably",
+	"publically":     "publicly",
+	"realy":          "really",
+	"recieve":        "receive",
+	"recieved":       "received",
+	"recomend":       "recommend",
+	"refered":        "referred",
+	"relevent":       "relevant",
+	"remeber":        "remember",
+	"responsability": "responsibility",
+	"seperate":       "separate",
+	"seperately":     "separately",
+	"sucess":         "success",
+	"succesful":      "successful",
+	"successfull":    "successful",
+	"supercede":      "supersede",
+	"suprise":        "surprise",
+	"teh":            "the",
+	"tommorow":       "tomorrow",
+	"tomorow":        "tomorrow",
+	"truely":         "truly",
+	"untill":         "until",
+	"usefull":        "useful",
+	"wich":           "which",
+	"wierd":          "weird",
+	"writting":       "writing",
+}
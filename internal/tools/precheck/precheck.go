@@ -0,0 +1,221 @@
+package precheck
+
+import (
+	"fmt"
+	"regexp"
+	"slices"
+	"sort"
+	"strings"
+
+	"github.com/danielmiessler/fabric/internal/plugins/db/fsdb"
+)
+
+// WritingTag is the tag of the patterns the text is checked for, see fsdb.DerivePatternMetadata
+const WritingTag = "WRITING"
+
+// Modes of the check: show the issues instead of running, or ask the model to fix them
+const (
+	ModeShow   = "show"
+	ModeAppend = "append"
+)
+
+// Issue kinds
+const (
+	KindSpelling       = "spelling"
+	KindRepeatedWord   = "repeated-word"
+	KindArticle        = "article"
+	KindCapitalization = "capitalization"
+	KindSpacing        = "spacing"
+)
+
+// Issue is a likely typo or grammar slip, Line and Column are 1-based, the column counts bytes
+type Issue struct {
+	Kind       string `json:"kind"`
+	Line       int    `json:"line"`
+	Column     int    `json:"column"`
+	Text       string `json:"text"`
+	Suggestion string `json:"suggestion"`
+}
+
+func (o Issue) String() string {
+	return fmt.Sprintf("line %d: %s \"%s\", use \"%s\"", o.Line, o.Kind, o.Text, o.Suggestion)
+}
+
+var (
+	// code, inline code and URLs are not prose, they are masked before checking
+	codeFence  = regexp.MustCompile("(?s)```.*?(```|$)")
+	inlineCode = regexp.MustCompile("`[^`\n]*`")
+	url        = regexp.MustCompile(`\b(https?://|www\.)\S+`)
+
+	wordPattern      = regexp.MustCompile(`\b[A-Za-z']+\b`)
+	repeatedWord     = regexp.MustCompile(`(?i)\b([a-z]+)\s+([a-z]+)\b`)
+	articleBefore    = regexp.MustCompile(`\b([Aa]n?)\s+([A-Za-z]+)`)
+	sentenceStart    = regexp.MustCompile(`([A-Za-z]+)[.!?]\s+([a-z][a-z]*)`)
+	loneI            = regexp.MustCompile(`(^|\s)i(\s|'[a-z]+\b)`)
+	spaceBeforeComma = regexp.MustCompile(`\w([ \t]+)[,.;](\s|$)`)
+	missingSpace     = regexp.MustCompile(`[a-z]([,;])[a-zA-Z]`)
+	doubleSpace      = regexp.MustCompile(`\S(  +)\S`)
+)
+
+// legitimately doubled words
+var allowedRepeats = map[string]bool{"had": true, "that": true, "is": true, "bye": true, "so": true, "very": true}
+
+// abbreviations ending with a period that don't end a sentence
+var abbreviations = map[string]bool{"e.g": true, "eg": true, "i.e": true, "ie": true, "etc": true, "vs": true, "cf": true, "al": true, "approx": true}
+
+// Check finds likely typos and grammar slips in prose: common misspellings, repeated words, a/an misuse,
+// sentences starting in lowercase and spacing around punctuation. Code blocks, inline code and URLs are
+// skipped. The heuristics favor missing an issue over reporting a false one.
+func Check(text string) (ret []Issue) {
+	prose := mask(text)
+	lines := newLineIndex(text)
+	add := func(kind string, start, end int, suggestion string) {
+		line, column := lines.position(start)
+		ret = append(ret, Issue{Kind: kind, Line: line, Column: column, Text: text[start:end], Suggestion: suggestion})
+	}
+
+	for _, match := range wordPattern.FindAllStringIndex(prose, -1) {
+		word := prose[match[0]:match[1]]
+		if fix, ok := misspellings[strings.ToLower(word)]; ok {
+			add(KindSpelling, match[0], match[1], matchCase(fix, word))
+		}
+	}
+
+	for _, match := range repeatedWord.FindAllStringSubmatchIndex(prose, -1) {
+		first, second := prose[match[2]:match[3]], prose[match[4]:match[5]]
+		if strings.EqualFold(first, second) && !allowedRepeats[strings.ToLower(first)] {
+			add(KindRepeatedWord, match[0], match[1], first)
+		}
+	}
+
+	for _, match := range articleBefore.FindAllStringSubmatchIndex(prose, -1) {
+		article, word := prose[match[2]:match[3]], prose[match[4]:match[5]]
+		wantAn := startsWithVowelSound(word)
+		if wantAn == nil || *wantAn == (len(article) == 2) {
+			continue
+		}
+		fix := "a"
+		if *wantAn {
+			fix = "an"
+		}
+		add(KindArticle, match[0], match[1], matchCase(fix, article)+" "+word)
+	}
+
+	for _, match := range sentenceStart.FindAllStringSubmatchIndex(prose, -1) {
+		before, word := prose[match[2]:match[3]], prose[match[4]:match[5]]
+		if abbreviations[strings.ToLower(before)] || (match[2] > 0 && prose[match[2]-1] == '.') {
+			continue
+		}
+		add(KindCapitalization, match[4], match[5], strings.ToUpper(word[:1])+word[1:])
+	}
+	for _, match := range loneI.FindAllStringSubmatchIndex(prose, -1) {
+		add(KindCapitalization, match[3], match[3]+1, "I")
+	}
+
+	for _, match := range spaceBeforeComma.FindAllStringSubmatchIndex(prose, -1) {
+		add(KindSpacing, match[2], match[3]+1, text[match[3]:match[3]+1])
+	}
+	for _, match := range missingSpace.FindAllStringSubmatchIndex(prose, -1) {
+		add(KindSpacing, match[2], match[3], text[match[2]:match[3]]+" ")
+	}
+	for _, match := range doubleSpace.FindAllStringSubmatchIndex(prose, -1) {
+		// blanks of masked code don't count
+		if strings.Trim(text[match[2]:match[3]], " ") == "" {
+			add(KindSpacing, match[2], match[3], " ")
+		}
+	}
+
+	sort.SliceStable(ret, func(i, j int) bool {
+		if ret[i].Line != ret[j].Line {
+			return ret[i].Line < ret[j].Line
+		}
+		return ret[i].Column < ret[j].Column
+	})
+	return
+}
+
+// Instructions asks the model to fix the issues, to be appended to the input
+func Instructions(issues []Issue) string {
+	if len(issues) == 0 {
+		return ""
+	}
+	var builder strings.Builder
+	builder.WriteString("Before following the instructions, fix these issues of the input:\n")
+	for _, issue := range issues {
+		fmt.Fprintf(&builder, "- %s\n", issue)
+	}
+	return builder.String()
+}
+
+// mask blanks code and URLs with spaces, keeping the offsets and line breaks of the text
+func mask(text string) string {
+	masked := []byte(text)
+	for _, pattern := range []*regexp.Regexp{codeFence, inlineCode, url} {
+		for _, match := range pattern.FindAllStringIndex(string(masked), -1) {
+			for i := match[0]; i < match[1]; i++ {
+				if masked[i] != '\n' {
+					masked[i] = ' '
+				}
+			}
+		}
+	}
+	return string(masked)
+}
+
+// startsWithVowelSound tells whether the word takes "an", nil when it can't be told from the spelling
+func startsWithVowelSound(word string) *bool {
+	lower := strings.ToLower(word)
+	yes, no := true, false
+	// acronyms are read letter by letter
+	if len(word) > 1 && strings.ToUpper(word) == word {
+		if strings.ContainsRune("AEFHILMNORSX", rune(word[0])) {
+			return &yes
+		}
+		return &no
+	}
+	for _, prefix := range []string{"uni", "use", "usu", "uti", "eu", "one", "once", "ur"} {
+		if strings.HasPrefix(lower, prefix) {
+			return &no
+		}
+	}
+	if strings.HasPrefix(lower, "h") || strings.HasPrefix(lower, "y") {
+		// hour, honest, heir... and yttrium are too irregular
+		return nil
+	}
+	if strings.ContainsRune("aeiou", rune(lower[0])) {
+		return &yes
+	}
+	return &no
+}
+
+func matchCase(fix, original string) string {
+	if original != "" && original[0] >= 'A' && original[0] <= 'Z' {
+		return strings.ToUpper(fix[:1]) + fix[1:]
+	}
+	return fix
+}
+
+type lineIndex []int
+
+func newLineIndex(text string) (ret lineIndex) {
+	ret = lineIndex{0}
+	for i, c := range text {
+		if c == '\n' {
+			ret = append(ret, i+1)
+		}
+	}
+	return
+}
+
+// position returns the 1-based line and column of the offset
+func (o lineIndex) position(offset int) (line, column int) {
+	line = sort.Search(len(o), func(i int) bool { return o[i] > offset })
+	column = offset - o[line-1] + 1
+	return
+}
+
+// IsWritingPattern tells whether the pattern is tagged as a writing pattern from its name, like write_essay
+// or improve_writing
+func IsWritingPattern(name string) bool {
+	return slices.Contains(fsdb.DerivePatternMetadata(name, "").DerivedTags, WritingTag)
+}
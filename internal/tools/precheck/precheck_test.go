@@ -0,0 +1,48 @@
+package precheck
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCheck(t *testing.T) {
+	text := "Teh report is definately done. the the results\nare a important update , i think.\n\nSee `teh` and https://example.com/teh,x for an user."
+
+	issues := Check(text)
+	got := make([]Issue, 0, len(issues))
+	for _, issue := range issues {
+		got = append(got, Issue{Kind: issue.Kind, Line: issue.Line, Text: issue.Text, Suggestion: issue.Suggestion})
+	}
+	assert.Equal(t, []Issue{
+		{Kind: KindSpelling, Line: 1, Text: "Teh", Suggestion: "The"},
+		{Kind: KindSpelling, Line: 1, Text: "definately", Suggestion: "definitely"},
+		{Kind: KindRepeatedWord, Line: 1, Text: "the the", Suggestion: "the"},
+		{Kind: KindCapitalization, Line: 1, Text: "the", Suggestion: "The"},
+		{Kind: KindArticle, Line: 2, Text: "a important", Suggestion: "an important"},
+		{Kind: KindSpacing, Line: 2, Text: " ,", Suggestion: ","},
+		{Kind: KindCapitalization, Line: 2, Text: "i", Suggestion: "I"},
+		{Kind: KindArticle, Line: 4, Text: "an user", Suggestion: "a user"},
+	}, got)
+
+	assert.Equal(t, 32, issues[2].Column)
+}
+
+func TestCheckCleanText(t *testing.T) {
+	assert.Empty(t, Check("An hour later, e.g. at noon, a university had had an FBI agent visit.\n\n```go\nx := a,b\n```"))
+}
+
+func TestInstructions(t *testing.T) {
+	assert.Empty(t, Instructions(nil))
+
+	instructions := Instructions([]Issue{{Kind: KindSpelling, Line: 3, Text: "teh", Suggestion: "the"}})
+	require.Contains(t, instructions, "fix these issues")
+	assert.Contains(t, instructions, "- line 3: spelling \"teh\", use \"the\"\n")
+}
+
+func TestIsWritingPattern(t *testing.T) {
+	assert.True(t, IsWritingPattern("write_essay"))
+	assert.True(t, IsWritingPattern("improve_writing"))
+	assert.False(t, IsWritingPattern("extract_wisdom"))
+}
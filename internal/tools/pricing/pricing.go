@@ -0,0 +1,78 @@
+package pricing
+
+import (
+	"fmt"
+	"os"
+	"path"
+
+	"gopkg.in/yaml.v3"
+)
+
+// FileName is the prices file in the fabric config directory, it maps the models, or globs of their
+// names, to their prices in USD per million tokens
+//
+//	gpt-4o: {input: 2.5, output: 10}
+//	"claude-sonnet-*": {input: 3, output: 15}
+const FileName = "prices.yaml"
+
+// Price is what a model costs in USD per million tokens
+type Price struct {
+	Input  float64 `yaml:"input" json:"input"`
+	Output float64 `yaml:"output" json:"output"`
+}
+
+// Prices are the prices of the models they are configured for, fabric knows no prices of its own
+type Prices struct {
+	Models map[string]Price
+}
+
+// Load reads the prices file, a missing file means no prices. An invalid file returns nil prices, which
+// price nothing.
+func Load(filePath string) (ret *Prices, err error) {
+	var data []byte
+	if data, err = os.ReadFile(filePath); err != nil {
+		if os.IsNotExist(err) {
+			ret, err = &Prices{Models: map[string]Price{}}, nil
+		}
+		return
+	}
+	models := map[string]Price{}
+	if err = yaml.Unmarshal(data, &models); err != nil {
+		err = fmt.Errorf("invalid prices file %s: %v", filePath, err)
+		return
+	}
+	for model := range models {
+		if _, err = path.Match(model, ""); err != nil {
+			err = fmt.Errorf("invalid model glob %q in prices file %s: %v", model, filePath, err)
+			return
+		}
+	}
+	ret = &Prices{Models: models}
+	return
+}
+
+// Price returns the price of the model: the one of its name, or of the longest glob matching it
+func (o *Prices) Price(model string) (ret Price, ok bool) {
+	if o == nil || model == "" {
+		return
+	}
+	if ret, ok = o.Models[model]; ok {
+		return
+	}
+	longest := -1
+	for glob, price := range o.Models {
+		if matched, _ := path.Match(glob, model); matched && len(glob) > longest {
+			ret, ok, longest = price, true, len(glob)
+		}
+	}
+	return
+}
+
+// Cost returns what the tokens cost with the model in USD, ok is false when its price isn't known
+func (o *Prices) Cost(model string, promptTokens int, completionTokens int) (ret float64, ok bool) {
+	var price Price
+	if price, ok = o.Price(model); ok {
+		ret = (float64(promptTokens)*price.Input + float64(completionTokens)*price.Output) / 1_000_000
+	}
+	return
+}
@@ -0,0 +1,46 @@
+package pricing
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLoad(t *testing.T) {
+	filePath := filepath.Join(t.TempDir(), FileName)
+	prices, err := Load(filePath)
+	require.NoError(t, err)
+	_, ok := prices.Price("gpt-4o")
+	assert.False(t, ok)
+
+	require.NoError(t, os.WriteFile(filePath, []byte("gpt-4o: {input: 2.5, output: 10}\n\"gpt-*\": {input: 1, output: 1}\n\"gpt-4*\": {input: 2, output: 8}\n"), 0644))
+	prices, err = Load(filePath)
+	require.NoError(t, err)
+	price, ok := prices.Price("gpt-4o")
+	assert.True(t, ok)
+	assert.Equal(t, Price{Input: 2.5, Output: 10}, price)
+	price, _ = prices.Price("gpt-4.1")
+	assert.Equal(t, Price{Input: 2, Output: 8}, price)
+	price, _ = prices.Price("gpt-3.5-turbo")
+	assert.Equal(t, Price{Input: 1, Output: 1}, price)
+
+	require.NoError(t, os.WriteFile(filePath, []byte("\"[gpt\": {input: 1}\n"), 0644))
+	_, err = Load(filePath)
+	assert.Error(t, err)
+}
+
+func TestCost(t *testing.T) {
+	prices := &Prices{Models: map[string]Price{"gpt-4o": {Input: 2.5, Output: 10}}}
+
+	cost, ok := prices.Cost("gpt-4o", 1_000_000, 500_000)
+	assert.True(t, ok)
+	assert.InDelta(t, 7.5, cost, 1e-9)
+
+	_, ok = prices.Cost("llama3", 10, 10)
+	assert.False(t, ok)
+	_, ok = (*Prices)(nil).Cost("gpt-4o", 10, 10)
+	assert.False(t, ok)
+}
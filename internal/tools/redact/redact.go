@@ -0,0 +1,164 @@
+package redact
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+// Names of the built-in rules
+const (
+	RuleEmail        = "email"
+	RuleAWSAccessKey = "aws-access-key"
+	RuleAWSSecretKey = "aws-secret-key"
+	RuleAPIKey       = "api-key"
+	RulePrivateKey   = "private-key"
+	// RuleCustom names the matches of the custom regexes
+	RuleCustom = "custom"
+)
+
+// Rule finds one kind of secret, the matches of its regex are replaced, or those of its first group when it
+// has one, so the name of a key=value pair is kept
+type Rule struct {
+	Name  string
+	Regex *regexp.Regexp
+}
+
+// builtinRules are ordered from the most specific, the first rule matching a span of the text names it
+var builtinRules = []Rule{
+	{RulePrivateKey, regexp.MustCompile(`(?s)-----BEGIN [A-Z ]*PRIVATE KEY-----.*?-----END [A-Z ]*PRIVATE KEY-----`)},
+	{RuleAWSAccessKey, regexp.MustCompile(`\b(?:AKIA|ASIA|AGPA|AIDA|AROA)[0-9A-Z]{16}\b`)},
+	{RuleAWSSecretKey, regexp.MustCompile(`(?i)aws_?secret_?(?:access_?)?key["']?\s*[:=]\s*["']?([A-Za-z0-9/+=]{40})\b`)},
+	{RuleAPIKey, regexp.MustCompile(`\b(?:sk-(?:ant-|proj-)?[A-Za-z0-9_-]{20,}|gh[pousr]_[A-Za-z0-9]{36,}|github_pat_[A-Za-z0-9_]{40,}|xox[abprs]-[A-Za-z0-9-]{10,}|AIza[0-9A-Za-z_-]{35}|glpat-[A-Za-z0-9_-]{20})`)},
+	{RuleAPIKey, regexp.MustCompile(`(?i)\b(?:api_?key|apikey|secret|token|password|passwd)["']?\s*[:=]\s*["']?([^\s"',;]{8,})`)},
+	{RuleEmail, regexp.MustCompile(`\b[A-Za-z0-9._%+-]+@[A-Za-z0-9.-]+\.[A-Za-z]{2,}\b`)},
+}
+
+// RuleNames returns the names of the built-in rules
+func RuleNames() (ret []string) {
+	for _, rule := range builtinRules {
+		if len(ret) == 0 || ret[len(ret)-1] != rule.Name {
+			ret = append(ret, rule.Name)
+		}
+	}
+	return
+}
+
+// Match is a redacted secret, Line is 1-based. Preview shows the start of the secret only, so the list of
+// the redactions can be shown without leaking them.
+type Match struct {
+	Rule        string `json:"rule"`
+	Line        int    `json:"line"`
+	Preview     string `json:"preview"`
+	Placeholder string `json:"placeholder"`
+}
+
+func (o Match) String() string {
+	return fmt.Sprintf("line %d: %s %s", o.Line, o.Rule, o.Preview)
+}
+
+// Redactor scrubs secrets from the input before it is sent to a vendor
+type Redactor struct {
+	rules []Rule
+}
+
+// New returns a redactor with the built-in rules of the names, all of them when the names are nil and none
+// when they are empty, and the custom regexes
+func New(ruleNames []string, customRegexes []string) (ret *Redactor, err error) {
+	ret = &Redactor{}
+	enabled := map[string]bool{}
+	for _, name := range ruleNames {
+		if name = strings.TrimSpace(name); name != "" {
+			enabled[name] = true
+		}
+	}
+	for name := range enabled {
+		known := false
+		for _, rule := range builtinRules {
+			known = known || rule.Name == name
+		}
+		if !known {
+			err = fmt.Errorf("unknown redaction rule %q, available rules: %s", name, strings.Join(RuleNames(), ", "))
+			return
+		}
+	}
+	for _, rule := range builtinRules {
+		if ruleNames == nil || enabled[rule.Name] {
+			ret.rules = append(ret.rules, rule)
+		}
+	}
+
+	for _, custom := range customRegexes {
+		if custom = strings.TrimSpace(custom); custom == "" {
+			continue
+		}
+		var regex *regexp.Regexp
+		if regex, err = regexp.Compile(custom); err != nil {
+			err = fmt.Errorf("invalid redaction regex %q: %v", custom, err)
+			return
+		}
+		ret.rules = append(ret.rules, Rule{Name: RuleCustom, Regex: regex})
+	}
+	return
+}
+
+type span struct {
+	start, end int
+	rule       string
+}
+
+// Redact replaces the secrets of the text with placeholders like [REDACTED:email], returning the matches
+// in the order of the text
+func (o *Redactor) Redact(text string) (ret string, matches []Match) {
+	var spans []span
+	overlaps := func(start, end int) bool {
+		for _, s := range spans {
+			if start < s.end && s.start < end {
+				return true
+			}
+		}
+		return false
+	}
+	for _, rule := range o.rules {
+		for _, match := range rule.Regex.FindAllStringSubmatchIndex(text, -1) {
+			start, end := match[0], match[1]
+			if len(match) > 2 && match[2] >= 0 {
+				start, end = match[2], match[3]
+			}
+			if start < end && !overlaps(start, end) {
+				spans = append(spans, span{start, end, rule.Name})
+			}
+		}
+	}
+	if len(spans) == 0 {
+		return text, nil
+	}
+	sort.Slice(spans, func(i, j int) bool { return spans[i].start < spans[j].start })
+
+	var builder strings.Builder
+	last := 0
+	for _, s := range spans {
+		placeholder := "[REDACTED:" + s.rule + "]"
+		builder.WriteString(text[last:s.start])
+		builder.WriteString(placeholder)
+		last = s.end
+		matches = append(matches, Match{
+			Rule:        s.rule,
+			Line:        strings.Count(text[:s.start], "\n") + 1,
+			Preview:     preview(text[s.start:s.end]),
+			Placeholder: placeholder,
+		})
+	}
+	builder.WriteString(text[last:])
+	ret = builder.String()
+	return
+}
+
+// preview keeps the first characters of the secret, a quarter of it at most
+func preview(secret string) string {
+	secret = strings.SplitN(secret, "\n", 2)[0]
+	runes := []rune(secret)
+	keep := min(4, len(runes)/4)
+	return string(runes[:keep]) + "…"
+}
@@ -0,0 +1,178 @@
+// Package share bundles the input, the output and the execution metadata of a run to share it with
+// teammates, as a zip file or a private GitHub gist.
+package share
+
+import (
+	"archive/zip"
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"sort"
+	"strings"
+	"time"
+)
+
+// File names of the bundle
+const (
+	InputFile    = "input.md"
+	OutputFile   = "output.md"
+	MetadataFile = "metadata.json"
+)
+
+// DefaultGistAPI is the GitHub API the gists are created with
+const DefaultGistAPI = "https://api.github.com"
+
+const maxGistResponseBytes = 1 << 20
+
+// Bundle is a result to share: the input sent to the pattern, its output and the execution metadata the
+// run reported
+type Bundle struct {
+	Pattern  string
+	Model    string
+	Input    string
+	Output   string
+	Metadata json.RawMessage // as reported by the run, may be empty
+	Created  time.Time
+}
+
+// metadata is the content of the metadata file
+type metadata struct {
+	Pattern   string          `json:"pattern,omitempty"`
+	Model     string          `json:"model,omitempty"`
+	Created   time.Time       `json:"created"`
+	Execution json.RawMessage `json:"execution,omitempty"`
+}
+
+// Files returns the content of the files of the bundle by name, the input is left out when there is none
+func (o *Bundle) Files() (ret map[string]string, err error) {
+	var data []byte
+	if data, err = json.MarshalIndent(metadata{Pattern: o.Pattern, Model: o.Model, Created: o.Created,
+		Execution: o.Metadata}, "", "  "); err != nil {
+		err = fmt.Errorf("invalid execution metadata: %v", err)
+		return
+	}
+	ret = map[string]string{OutputFile: o.Output, MetadataFile: string(data) + "\n"}
+	if strings.TrimSpace(o.Input) != "" {
+		ret[InputFile] = o.Input
+	}
+	return
+}
+
+// Name is the base name of the zip file and the description of the gist
+func (o *Bundle) Name() string {
+	name := "fabric-output"
+	if o.Pattern != "" {
+		name = "fabric-" + o.Pattern
+	}
+	return name + "-" + o.Created.Format("20060102-150405")
+}
+
+// Zip writes the files of the bundle into a zip archive, under a directory of the name of the bundle
+func (o *Bundle) Zip(w io.Writer) (err error) {
+	var files map[string]string
+	if files, err = o.Files(); err != nil {
+		return
+	}
+	archive := zip.NewWriter(w)
+	for _, name := range sortedNames(files) {
+		var file io.Writer
+		if file, err = archive.CreateHeader(&zip.FileHeader{Name: o.Name() + "/" + name, Method: zip.Deflate,
+			Modified: o.Created}); err != nil {
+			return
+		}
+		if _, err = io.WriteString(file, files[name]); err != nil {
+			return
+		}
+	}
+	return archive.Close()
+}
+
+// GistClient creates gists with the GitHub API
+type GistClient struct {
+	HttpClient *http.Client
+	BaseURL    string
+}
+
+func NewGistClient() *GistClient {
+	return &GistClient{HttpClient: &http.Client{Timeout: 30 * time.Second}, BaseURL: DefaultGistAPI}
+}
+
+type gistFile struct {
+	Content string `json:"content"`
+}
+
+type gistRequest struct {
+	Description string              `json:"description"`
+	Public      bool                `json:"public"`
+	Files       map[string]gistFile `json:"files"`
+}
+
+// CreateGist creates a private gist of the files of the bundle with the token, which needs the gist scope,
+// and returns its URL
+func (o *GistClient) CreateGist(token string, bundle *Bundle) (url string, err error) {
+	if strings.TrimSpace(token) == "" {
+		err = fmt.Errorf("a GitHub token is required to create a gist")
+		return
+	}
+	var files map[string]string
+	if files, err = bundle.Files(); err != nil {
+		return
+	}
+	request := gistRequest{Description: bundle.Name(), Files: map[string]gistFile{}}
+	for name, content := range files {
+		// the gists refuse empty files
+		if strings.TrimSpace(content) != "" {
+			request.Files[name] = gistFile{Content: content}
+		}
+	}
+	var body []byte
+	if body, err = json.Marshal(request); err != nil {
+		return
+	}
+
+	var req *http.Request
+	if req, err = http.NewRequest(http.MethodPost, strings.TrimRight(o.BaseURL, "/")+"/gists", bytes.NewReader(body)); err != nil {
+		err = fmt.Errorf("error creating gist: %v", err)
+		return
+	}
+	req.Header.Set("Accept", "application/vnd.github+json")
+	req.Header.Set("Authorization", "Bearer "+strings.TrimSpace(token))
+	req.Header.Set("Content-Type", "application/json")
+
+	var resp *http.Response
+	if resp, err = o.HttpClient.Do(req); err != nil {
+		err = fmt.Errorf("error creating gist: %v", err)
+		return
+	}
+	defer resp.Body.Close()
+
+	var data []byte
+	if data, err = io.ReadAll(io.LimitReader(resp.Body, maxGistResponseBytes)); err != nil {
+		err = fmt.Errorf("error creating gist: %v", err)
+		return
+	}
+	var result struct {
+		HtmlUrl string `json:"html_url"`
+		Message string `json:"message"`
+	}
+	_ = json.Unmarshal(data, &result)
+	if resp.StatusCode != http.StatusCreated {
+		if result.Message == "" {
+			result.Message = resp.Status
+		}
+		err = fmt.Errorf("error creating gist: %s", result.Message)
+		return
+	}
+	url = result.HtmlUrl
+	return
+}
+
+func sortedNames(files map[string]string) (ret []string) {
+	for name := range files {
+		ret = append(ret, name)
+	}
+	sort.Strings(ret)
+	return
+}
@@ -0,0 +1,83 @@
+package share
+
+import (
+	"archive/zip"
+	"bytes"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+func testBundle() *Bundle {
+	return &Bundle{
+		Pattern:  "summarize",
+		Model:    "gpt-4o",
+		Input:    "The long article",
+		Output:   "# Summary\n\nShort",
+		Metadata: json.RawMessage(`{"latencyMs":1200}`),
+		Created:  time.Date(2024, 3, 10, 12, 0, 0, 0, time.UTC),
+	}
+}
+
+func TestBundleZip(t *testing.T) {
+	var buffer bytes.Buffer
+	if err := testBundle().Zip(&buffer); err != nil {
+		t.Fatalf("Zip failed: %v", err)
+	}
+	archive, err := zip.NewReader(bytes.NewReader(buffer.Bytes()), int64(buffer.Len()))
+	if err != nil {
+		t.Fatalf("invalid zip: %v", err)
+	}
+	contents := map[string]string{}
+	for _, file := range archive.File {
+		reader, _ := file.Open()
+		data, _ := io.ReadAll(reader)
+		reader.Close()
+		contents[file.Name] = string(data)
+	}
+	dir := "fabric-summarize-20240310-120000/"
+	if contents[dir+InputFile] != "The long article" || contents[dir+OutputFile] != "# Summary\n\nShort" {
+		t.Errorf("unexpected files: %v", contents)
+	}
+	if !strings.Contains(contents[dir+MetadataFile], `"latencyMs": 1200`) ||
+		!strings.Contains(contents[dir+MetadataFile], `"pattern": "summarize"`) {
+		t.Errorf("unexpected metadata: %s", contents[dir+MetadataFile])
+	}
+}
+
+func TestCreateGist(t *testing.T) {
+	var request gistRequest
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/gists" || r.Header.Get("Authorization") != "Bearer secret" {
+			w.WriteHeader(http.StatusUnauthorized)
+			w.Write([]byte(`{"message":"Bad credentials"}`))
+			return
+		}
+		json.NewDecoder(r.Body).Decode(&request)
+		w.WriteHeader(http.StatusCreated)
+		w.Write([]byte(`{"html_url":"https://gist.github.com/abc"}`))
+	}))
+	defer server.Close()
+
+	client := &GistClient{HttpClient: server.Client(), BaseURL: server.URL}
+	bundle := testBundle()
+	bundle.Input = ""
+	url, err := client.CreateGist("secret", bundle)
+	if err != nil || url != "https://gist.github.com/abc" {
+		t.Fatalf("CreateGist = %q, %v", url, err)
+	}
+	if request.Public || len(request.Files) != 2 || request.Files[OutputFile].Content != bundle.Output {
+		t.Errorf("unexpected gist request: %+v", request)
+	}
+
+	if _, err = client.CreateGist("wrong", bundle); err == nil || !strings.Contains(err.Error(), "Bad credentials") {
+		t.Errorf("expected the error of GitHub, got %v", err)
+	}
+	if _, err = client.CreateGist("", bundle); err == nil {
+		t.Error("expected an error without a token")
+	}
+}
@@ -0,0 +1,193 @@
+package speak
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"runtime"
+	"strings"
+	"sync"
+	"unicode"
+)
+
+// Synthesizer turns a sentence into audio
+type Synthesizer func(text string) (audio []byte, err error)
+
+// Player plays audio, returning when it's done
+type Player func(audio []byte) error
+
+// Speaker speaks streamed text sentence by sentence: the next sentence is synthesized while the
+// current one plays. Once synthesizing or playing failed, the remaining text is dropped.
+type Speaker struct {
+	splitter  SentenceSplitter
+	sentences chan string
+	done      chan struct{}
+
+	mu  sync.Mutex
+	err error
+}
+
+func NewSpeaker(synthesize Synthesizer, play Player) (ret *Speaker) {
+	ret = &Speaker{sentences: make(chan string, 256), done: make(chan struct{})}
+
+	audio := make(chan []byte, 2)
+	go func() {
+		defer close(audio)
+		for sentence := range ret.sentences {
+			if ret.failed() {
+				continue
+			}
+			data, err := synthesize(sentence)
+			if err != nil {
+				ret.fail(fmt.Errorf("could not synthesize speech: %v", err))
+				continue
+			}
+			audio <- data
+		}
+	}()
+	go func() {
+		defer close(ret.done)
+		for data := range audio {
+			if ret.failed() {
+				continue
+			}
+			if err := play(data); err != nil {
+				ret.fail(fmt.Errorf("could not play speech: %v", err))
+			}
+		}
+	}()
+	return
+}
+
+// Write queues the completed sentences of the chunk, the rest is kept until more text arrives
+func (o *Speaker) Write(chunk string) {
+	for _, sentence := range o.splitter.Write(chunk) {
+		o.sentences <- sentence
+	}
+}
+
+// Close speaks the remaining text and waits until everything was spoken
+func (o *Speaker) Close() error {
+	if rest := o.splitter.Flush(); rest != "" {
+		o.sentences <- rest
+	}
+	close(o.sentences)
+	<-o.done
+	return o.err
+}
+
+func (o *Speaker) fail(err error) {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	if o.err == nil {
+		o.err = err
+	}
+}
+
+func (o *Speaker) failed() bool {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	return o.err != nil
+}
+
+// SentenceSplitter collects streamed text and returns it sentence by sentence. A sentence ends with
+// '.', '!' or '?' followed by white space, or with a line break, so headings and list items are spoken
+// on their own. Markdown markup is removed, as it shouldn't be read out.
+type SentenceSplitter struct {
+	pending strings.Builder
+}
+
+func (o *SentenceSplitter) Write(chunk string) (ret []string) {
+	o.pending.WriteString(chunk)
+	text := o.pending.String()
+
+	start := 0
+	runes := []rune(text)
+	for i, r := range runes {
+		end := -1
+		switch {
+		case r == '\n':
+			end = i
+		case (r == '.' || r == '!' || r == '?') && i+1 < len(runes) && unicode.IsSpace(runes[i+1]):
+			end = i + 1
+		}
+		if end < 0 {
+			continue
+		}
+		if sentence := cleanSentence(string(runes[start:end])); sentence != "" {
+			ret = append(ret, sentence)
+		}
+		start = end
+	}
+
+	o.pending.Reset()
+	o.pending.WriteString(string(runes[start:]))
+	return
+}
+
+// Flush returns the text of the unfinished sentence
+func (o *SentenceSplitter) Flush() (ret string) {
+	ret = cleanSentence(o.pending.String())
+	o.pending.Reset()
+	return
+}
+
+var markdownReplacer = strings.NewReplacer("**", "", "__", "", "`", "", "*", "", "#", "", ">", "", "|", " ")
+
+// cleanSentence removes the markup and returns "" for text without anything to say
+func cleanSentence(text string) string {
+	text = strings.TrimSpace(markdownReplacer.Replace(text))
+	text = strings.TrimLeft(text, "-+ ")
+	if !strings.ContainsFunc(text, func(r rune) bool { return unicode.IsLetter(r) || unicode.IsDigit(r) }) {
+		return ""
+	}
+	return strings.Join(strings.Fields(text), " ")
+}
+
+// players are tried in order, the first one installed is used
+var players = map[string][][]string{
+	"darwin":  {{"afplay"}},
+	"linux":   {{"paplay"}, {"aplay", "-q"}, {"ffplay", "-nodisp", "-autoexit", "-loglevel", "quiet"}},
+	"windows": {{"powershell", "-NoProfile", "-Command", `(New-Object Media.SoundPlayer $env:FABRIC_AUDIO_FILE).PlaySync()`}},
+}
+
+// NewWAVPlayer returns a Player for WAV audio using the first audio player installed on the system
+func NewWAVPlayer() (ret Player, err error) {
+	var command []string
+	for _, candidate := range players[runtime.GOOS] {
+		if _, lookErr := exec.LookPath(candidate[0]); lookErr == nil {
+			command = candidate
+			break
+		}
+	}
+	if command == nil {
+		err = fmt.Errorf("no audio player found on %s", runtime.GOOS)
+		return
+	}
+
+	ret = func(audio []byte) (err error) {
+		var file *os.File
+		if file, err = os.CreateTemp("", "fabric-speech-*.wav"); err != nil {
+			return
+		}
+		defer os.Remove(file.Name())
+		if _, err = file.Write(audio); err == nil {
+			err = file.Close()
+		} else {
+			file.Close()
+		}
+		if err != nil {
+			return
+		}
+
+		args := command[1:]
+		if runtime.GOOS != "windows" {
+			args = append(append([]string{}, args...), file.Name())
+		}
+		cmd := exec.Command(command[0], args...)
+		// the file name is passed through the environment, so it can't inject into the PowerShell command
+		cmd.Env = append(os.Environ(), "FABRIC_AUDIO_FILE="+file.Name())
+		return cmd.Run()
+	}
+	return
+}
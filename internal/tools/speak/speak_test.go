@@ -0,0 +1,71 @@
+package speak
+
+import (
+	"errors"
+	"reflect"
+	"strings"
+	"sync"
+	"testing"
+)
+
+func TestSentenceSplitter(t *testing.T) {
+	var splitter SentenceSplitter
+	var sentences []string
+	for _, chunk := range []string{"# SUM", "MARY\n\nThe quick", " brown fox. It jumps", "! Does it? ", "- **Bold** item\n", "---\n", "Unfinished"} {
+		sentences = append(sentences, splitter.Write(chunk)...)
+	}
+
+	expected := []string{"SUMMARY", "The quick brown fox.", "It jumps!", "Does it?", "Bold item"}
+	if !reflect.DeepEqual(sentences, expected) {
+		t.Errorf("sentences = %q, expected %q", sentences, expected)
+	}
+	if rest := splitter.Flush(); rest != "Unfinished" {
+		t.Errorf("Flush() = %q, expected %q", rest, "Unfinished")
+	}
+	if rest := splitter.Flush(); rest != "" {
+		t.Errorf("expected nothing after flushing, got %q", rest)
+	}
+}
+
+func TestSpeaker(t *testing.T) {
+	var mu sync.Mutex
+	var played []string
+	speaker := NewSpeaker(
+		func(text string) ([]byte, error) { return []byte(strings.ToUpper(text)), nil },
+		func(audio []byte) error {
+			mu.Lock()
+			defer mu.Unlock()
+			played = append(played, string(audio))
+			return nil
+		},
+	)
+
+	speaker.Write("One. Two")
+	speaker.Write(" three.")
+	if err := speaker.Close(); err != nil {
+		t.Fatalf("Close() failed: %v", err)
+	}
+	if expected := []string{"ONE.", "TWO THREE."}; !reflect.DeepEqual(played, expected) {
+		t.Errorf("played %q, expected %q", played, expected)
+	}
+}
+
+func TestSpeaker_StopsAfterError(t *testing.T) {
+	calls := 0
+	speaker := NewSpeaker(
+		func(text string) ([]byte, error) {
+			calls++
+			return nil, errors.New("quota exceeded")
+		},
+		func(audio []byte) error { return nil },
+	)
+
+	speaker.Write("One. Two. Three. ")
+	err := speaker.Close()
+	if err == nil || !strings.Contains(err.Error(), "quota exceeded") {
+		t.Errorf("expected the synthesize error, got %v", err)
+	}
+	if calls != 1 {
+		t.Errorf("expected no more synthesizing after the error, got %d calls", calls)
+	}
+}
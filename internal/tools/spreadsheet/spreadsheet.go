@@ -0,0 +1,129 @@
+package spreadsheet
+
+import (
+	"bytes"
+	"encoding/csv"
+	"fmt"
+	"path/filepath"
+	"strings"
+	"unicode/utf8"
+)
+
+// MaxRows limits the rows read of a sheet, the rows past it are dropped and the sheet is marked truncated
+const MaxRows = 10000
+
+// Sheet is a table of a spreadsheet file, its first row is the header
+type Sheet struct {
+	Name      string     `json:"name"`
+	Columns   []string   `json:"columns"`
+	Rows      [][]string `json:"rows"` // as many cells as columns
+	Truncated bool       `json:"truncated,omitempty"`
+}
+
+// IsSpreadsheet tells whether Read supports the file by its name
+func IsSpreadsheet(fileName string) bool {
+	switch strings.ToLower(filepath.Ext(fileName)) {
+	case ".csv", ".tsv", ".xlsx":
+		return true
+	}
+	return false
+}
+
+// Read parses a CSV, TSV or XLSX file into its sheets, a CSV file has one sheet. Cells are read as
+// text, XLSX numbers like dates are kept as stored.
+func Read(fileName string, data []byte) (ret []*Sheet, err error) {
+	switch strings.ToLower(filepath.Ext(fileName)) {
+	case ".csv", ".tsv":
+		var sheet *Sheet
+		if sheet, err = readCSV(strings.TrimSuffix(filepath.Base(fileName), filepath.Ext(fileName)), data); err != nil {
+			return
+		}
+		ret = []*Sheet{sheet}
+	case ".xlsx":
+		ret, err = readXLSX(data)
+	default:
+		err = fmt.Errorf("%s is not a CSV, TSV or XLSX file", fileName)
+	}
+	return
+}
+
+func readCSV(name string, data []byte) (ret *Sheet, err error) {
+	data = bytes.TrimPrefix(data, []byte("\xef\xbb\xbf"))
+	if !utf8.Valid(data) {
+		err = fmt.Errorf("%s is not UTF-8 text", name)
+		return
+	}
+	reader := csv.NewReader(bytes.NewReader(data))
+	reader.Comma = sniffDelimiter(data)
+	reader.FieldsPerRecord = -1
+	reader.LazyQuotes = true
+
+	var records [][]string
+	if records, err = reader.ReadAll(); err != nil {
+		err = fmt.Errorf("invalid CSV: %v", err)
+		return
+	}
+	ret = newSheet(name, records)
+	return
+}
+
+// sniffDelimiter picks the delimiter appearing most in the first line among comma, semicolon and tab
+func sniffDelimiter(data []byte) (ret rune) {
+	firstLine, _, _ := bytes.Cut(data, []byte("\n"))
+	ret = ','
+	most := 0
+	for _, delimiter := range []rune{',', ';', '\t'} {
+		if count := bytes.Count(firstLine, []byte(string(delimiter))); count > most {
+			ret, most = delimiter, count
+		}
+	}
+	return
+}
+
+// newSheet makes the first record the header, naming the unnamed columns, and pads the rows to the columns
+func newSheet(name string, records [][]string) (ret *Sheet) {
+	ret = &Sheet{Name: name, Columns: []string{}, Rows: [][]string{}}
+	// trailing empty rows are common in spreadsheets
+	for len(records) > 0 && isEmptyRow(records[len(records)-1]) {
+		records = records[:len(records)-1]
+	}
+	if len(records) == 0 {
+		return
+	}
+
+	width := 0
+	for _, record := range records {
+		width = max(width, len(record))
+	}
+	for i := 0; i < width; i++ {
+		var column string
+		if i < len(records[0]) {
+			column = strings.TrimSpace(records[0][i])
+		}
+		if column == "" {
+			column = fmt.Sprintf("Column %d", i+1)
+		}
+		ret.Columns = append(ret.Columns, column)
+	}
+
+	rows := records[1:]
+	if len(rows) > MaxRows {
+		rows = rows[:MaxRows]
+		ret.Truncated = true
+	}
+	for _, record := range rows {
+		row := make([]string, width)
+		copy(row, record)
+		ret.Rows = append(ret.Rows, row)
+	}
+	return
+}
+
+func isEmptyRow(record []string) bool {
+	for _, cell := range record {
+		if strings.TrimSpace(cell) != "" {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,89 @@
+package spreadsheet
+
+import (
+	"archive/zip"
+	"bytes"
+	"reflect"
+	"testing"
+)
+
+func TestReadCSV(t *testing.T) {
+	sheets, err := Read("people.csv", []byte("\xef\xbb\xbfname,age,\nAda,36,x\nAlan,41\n,,\n"))
+	if err != nil {
+		t.Fatalf("Read failed: %v", err)
+	}
+	expected := []*Sheet{{
+		Name:    "people",
+		Columns: []string{"name", "age", "Column 3"},
+		Rows:    [][]string{{"Ada", "36", "x"}, {"Alan", "41", ""}},
+	}}
+	if !reflect.DeepEqual(sheets, expected) {
+		t.Errorf("expected %+v, got %+v", expected[0], sheets[0])
+	}
+}
+
+func TestReadCSVDelimiter(t *testing.T) {
+	sheets, err := Read("prices.csv", []byte("item;price\ntea;1,50\n"))
+	if err != nil {
+		t.Fatalf("Read failed: %v", err)
+	}
+	if !reflect.DeepEqual(sheets[0].Rows, [][]string{{"tea", "1,50"}}) {
+		t.Errorf("unexpected rows %v", sheets[0].Rows)
+	}
+
+	if _, err = Read("notes.txt", []byte("a,b")); err == nil {
+		t.Error("expected an error for a text file")
+	}
+}
+
+func TestReadXLSX(t *testing.T) {
+	var buffer bytes.Buffer
+	archive := zip.NewWriter(&buffer)
+	parts := map[string]string{
+		"xl/workbook.xml": `<workbook xmlns="http://schemas.openxmlformats.org/spreadsheetml/2006/main" xmlns:r="http://schemas.openxmlformats.org/officeDocument/2006/relationships">
+<sheets><sheet name="Budget" sheetId="1" r:id="rId1"/></sheets></workbook>`,
+		"xl/_rels/workbook.xml.rels": `<Relationships><Relationship Id="rId1" Target="worksheets/sheet1.xml"/></Relationships>`,
+		"xl/sharedStrings.xml":       `<sst><si><t>item</t></si><si><t>cost</t></si><si><r><t>ren</t></r><r><t>t</t></r></si></sst>`,
+		"xl/worksheets/sheet1.xml": `<worksheet><sheetData>
+<row r="1"><c r="A1" t="s"><v>0</v></c><c r="C1" t="s"><v>1</v></c></row>
+<row r="2"><c r="A2" t="s"><v>2</v></c><c r="B2" t="b"><v>1</v></c><c r="C2"><v>1200.5</v></c></row>
+<row r="3"><c r="A3" t="inlineStr"><is><t>food</t></is></c></row>
+</sheetData></worksheet>`,
+	}
+	for name, content := range parts {
+		writer, err := archive.Create(name)
+		if err != nil {
+			t.Fatal(err)
+		}
+		writer.Write([]byte(content))
+	}
+	archive.Close()
+
+	sheets, err := Read("budget.xlsx", buffer.Bytes())
+	if err != nil {
+		t.Fatalf("Read failed: %v", err)
+	}
+	expected := []*Sheet{{
+		Name:    "Budget",
+		Columns: []string{"item", "Column 2", "cost"},
+		Rows:    [][]string{{"rent", "TRUE", "1200.5"}, {"food", "", ""}},
+	}}
+	if !reflect.DeepEqual(sheets, expected) {
+		t.Errorf("expected %+v, got %+v", expected[0], sheets[0])
+	}
+
+	if _, err = Read("broken.xlsx", []byte("not a zip")); err == nil {
+		t.Error("expected an error for an invalid XLSX file")
+	}
+}
+
+func TestColumnIndex(t *testing.T) {
+	for ref, expected := range map[string]int{"A1": 0, "Z9": 25, "AA10": 26, "ab3": 27} {
+		if index, err := columnIndex(ref); err != nil || index != expected {
+			t.Errorf("columnIndex(%s) = %d, %v, expected %d", ref, index, err, expected)
+		}
+	}
+	if _, err := columnIndex("12"); err == nil {
+		t.Error("expected an error for a reference without column")
+	}
+}
@@ -0,0 +1,189 @@
+package spreadsheet
+
+import (
+	"archive/zip"
+	"bytes"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"path"
+	"strings"
+)
+
+// maxXLSXPartSize limits the uncompressed size of the parts read of an XLSX file
+const maxXLSXPartSize = 64 << 20
+
+type xlsxWorkbook struct {
+	Sheets []struct {
+		Name string `xml:"name,attr"`
+		ID   string `xml:"http://schemas.openxmlformats.org/officeDocument/2006/relationships id,attr"`
+	} `xml:"sheets>sheet"`
+}
+
+type xlsxRelationships struct {
+	Relationships []struct {
+		ID     string `xml:"Id,attr"`
+		Target string `xml:"Target,attr"`
+	} `xml:"Relationship"`
+}
+
+// xlsxText is a rich text, its runs are concatenated
+type xlsxText struct {
+	Text string `xml:"t"`
+	Runs []struct {
+		Text string `xml:"t"`
+	} `xml:"r"`
+}
+
+func (o xlsxText) String() string {
+	if len(o.Runs) == 0 {
+		return o.Text
+	}
+	var builder strings.Builder
+	for _, run := range o.Runs {
+		builder.WriteString(run.Text)
+	}
+	return builder.String()
+}
+
+type xlsxSharedStrings struct {
+	Items []xlsxText `xml:"si"`
+}
+
+type xlsxWorksheet struct {
+	Rows []struct {
+		Cells []struct {
+			Ref    string   `xml:"r,attr"`
+			Type   string   `xml:"t,attr"`
+			Value  string   `xml:"v"`
+			Inline xlsxText `xml:"is"`
+		} `xml:"c"`
+	} `xml:"sheetData>row"`
+}
+
+func readXLSX(data []byte) (ret []*Sheet, err error) {
+	var archive *zip.Reader
+	if archive, err = zip.NewReader(bytes.NewReader(data), int64(len(data))); err != nil {
+		err = fmt.Errorf("invalid XLSX file: %v", err)
+		return
+	}
+	files := map[string]*zip.File{}
+	for _, file := range archive.File {
+		files[file.Name] = file
+	}
+
+	var workbook xlsxWorkbook
+	if err = readXLSXPart(files, "xl/workbook.xml", &workbook); err != nil {
+		return
+	}
+	var relationships xlsxRelationships
+	if err = readXLSXPart(files, "xl/_rels/workbook.xml.rels", &relationships); err != nil {
+		return
+	}
+	targets := map[string]string{}
+	for _, relationship := range relationships.Relationships {
+		target := relationship.Target
+		if strings.HasPrefix(target, "/") {
+			target = strings.TrimPrefix(target, "/")
+		} else {
+			target = path.Join("xl", target)
+		}
+		targets[relationship.ID] = target
+	}
+
+	// workbooks without text cells have no shared strings
+	var sharedStrings xlsxSharedStrings
+	if files["xl/sharedStrings.xml"] != nil {
+		if err = readXLSXPart(files, "xl/sharedStrings.xml", &sharedStrings); err != nil {
+			return
+		}
+	}
+
+	for _, entry := range workbook.Sheets {
+		var worksheet xlsxWorksheet
+		if err = readXLSXPart(files, targets[entry.ID], &worksheet); err != nil {
+			return
+		}
+		var records [][]string
+		if records, err = worksheetRecords(&worksheet, sharedStrings.Items); err != nil {
+			err = fmt.Errorf("sheet %s: %v", entry.Name, err)
+			return
+		}
+		ret = append(ret, newSheet(entry.Name, records))
+	}
+	if len(ret) == 0 {
+		err = fmt.Errorf("the XLSX file has no sheets")
+	}
+	return
+}
+
+func readXLSXPart(files map[string]*zip.File, name string, part any) (err error) {
+	file := files[name]
+	if file == nil {
+		err = fmt.Errorf("invalid XLSX file: %s is missing", name)
+		return
+	}
+	var reader io.ReadCloser
+	if reader, err = file.Open(); err != nil {
+		return
+	}
+	defer reader.Close()
+	if err = xml.NewDecoder(io.LimitReader(reader, maxXLSXPartSize)).Decode(part); err != nil {
+		err = fmt.Errorf("invalid XLSX file: %s: %v", name, err)
+	}
+	return
+}
+
+// worksheetRecords lays the cells out by their references, the cells of sparse rows are skipped in the XML
+func worksheetRecords(worksheet *xlsxWorksheet, sharedStrings []xlsxText) (ret [][]string, err error) {
+	for _, row := range worksheet.Rows {
+		var record []string
+		for _, cell := range row.Cells {
+			column := len(record)
+			if cell.Ref != "" {
+				if column, err = columnIndex(cell.Ref); err != nil {
+					return
+				}
+			}
+			if column >= len(record) {
+				record = append(record, make([]string, column-len(record)+1)...)
+			}
+
+			value := cell.Value
+			switch cell.Type {
+			case "s":
+				var index int
+				if _, err = fmt.Sscan(cell.Value, &index); err != nil || index < 0 || index >= len(sharedStrings) {
+					err = fmt.Errorf("invalid shared string %q in %s", cell.Value, cell.Ref)
+					return
+				}
+				value = sharedStrings[index].String()
+			case "inlineStr":
+				value = cell.Inline.String()
+			case "b":
+				value = map[string]string{"0": "FALSE", "1": "TRUE"}[cell.Value]
+			}
+			record[column] = value
+		}
+		ret = append(ret, record)
+	}
+	return
+}
+
+// columnIndex returns the zero based column of a cell reference like AB12
+func columnIndex(ref string) (ret int, err error) {
+	letters := strings.TrimRight(strings.ToUpper(ref), "0123456789")
+	if letters == "" {
+		err = fmt.Errorf("invalid cell reference %s", ref)
+		return
+	}
+	for _, letter := range letters {
+		if letter < 'A' || letter > 'Z' {
+			err = fmt.Errorf("invalid cell reference %s", ref)
+			return
+		}
+		ret = ret*26 + int(letter-'A') + 1
+	}
+	ret--
+	return
+}
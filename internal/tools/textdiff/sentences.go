@@ -0,0 +1,120 @@
+package textdiff
+
+import (
+	"regexp"
+	"strings"
+)
+
+// minSimilarity is the similarity under which two sentences are not aligned, each gets a gap instead
+const minSimilarity = 0.3
+
+// SentencePair is a sentence of a aligned with its closest sentence of b. A or B is empty when the sentence
+// has no counterpart. Similarity goes from 0, nothing in common, to 1, the same words.
+type SentencePair struct {
+	A          string  `json:"a,omitempty"`
+	B          string  `json:"b,omitempty"`
+	Similarity float64 `json:"similarity"`
+}
+
+var (
+	sentenceEnd = regexp.MustCompile(`[.!?]["')\]]*\s+`)
+	wordToken   = regexp.MustCompile(`[\p{L}\p{N}]+`)
+)
+
+// Sentences aligns the sentences of a and b in order, maximizing the total similarity of the aligned pairs,
+// to show which parts of two outputs differ the most. The similarity is the Dice coefficient of the word
+// tokens of the sentences.
+func Sentences(a, b string) (ret []SentencePair) {
+	oldSentences, newSentences := splitSentences(a), splitSentences(b)
+	n, m := len(oldSentences), len(newSentences)
+	oldTokens, newTokens := make([]map[string]int, n), make([]map[string]int, m)
+	for i, sentence := range oldSentences {
+		oldTokens[i] = tokenCounts(sentence)
+	}
+	for j, sentence := range newSentences {
+		newTokens[j] = tokenCounts(sentence)
+	}
+
+	// score[i][j] is the best total similarity aligning the first i sentences of a with the first j of b
+	similarity := make([][]float64, n)
+	score := make([][]float64, n+1)
+	for i := range score {
+		score[i] = make([]float64, m+1)
+	}
+	for i := 1; i <= n; i++ {
+		similarity[i-1] = make([]float64, m)
+		for j := 1; j <= m; j++ {
+			s := dice(oldTokens[i-1], newTokens[j-1])
+			similarity[i-1][j-1] = s
+			score[i][j] = max(score[i-1][j], score[i][j-1])
+			if s >= minSimilarity {
+				score[i][j] = max(score[i][j], score[i-1][j-1]+s)
+			}
+		}
+	}
+
+	// walk back from (n, m), collecting the pairs in reverse
+	for i, j := n, m; i > 0 || j > 0; {
+		switch {
+		case i > 0 && j > 0 && similarity[i-1][j-1] >= minSimilarity &&
+			score[i][j] == score[i-1][j-1]+similarity[i-1][j-1]:
+			ret = append(ret, SentencePair{A: oldSentences[i-1], B: newSentences[j-1], Similarity: similarity[i-1][j-1]})
+			i--
+			j--
+		case j > 0 && (i == 0 || score[i][j] == score[i][j-1]):
+			ret = append(ret, SentencePair{B: newSentences[j-1]})
+			j--
+		default:
+			ret = append(ret, SentencePair{A: oldSentences[i-1]})
+			i--
+		}
+	}
+
+	for i, j := 0, len(ret)-1; i < j; i, j = i+1, j-1 {
+		ret[i], ret[j] = ret[j], ret[i]
+	}
+	return
+}
+
+// splitSentences splits the text at line breaks, so headings and list items stand alone, and at the ends of
+// sentences
+func splitSentences(text string) (ret []string) {
+	for _, line := range strings.Split(text, "\n") {
+		line = strings.TrimSpace(line)
+		start := 0
+		for _, end := range sentenceEnd.FindAllStringIndex(line, -1) {
+			if sentence := strings.TrimSpace(line[start:end[1]]); sentence != "" {
+				ret = append(ret, sentence)
+			}
+			start = end[1]
+		}
+		if sentence := strings.TrimSpace(line[start:]); sentence != "" {
+			ret = append(ret, sentence)
+		}
+	}
+	return
+}
+
+func tokenCounts(sentence string) (ret map[string]int) {
+	ret = map[string]int{}
+	for _, token := range wordToken.FindAllString(strings.ToLower(sentence), -1) {
+		ret[token]++
+	}
+	return
+}
+
+// dice is 2 * the common tokens / all the tokens, sentences without words are similar when both are
+func dice(a, b map[string]int) float64 {
+	total, common := 0, 0
+	for token, count := range a {
+		total += count
+		common += min(count, b[token])
+	}
+	for _, count := range b {
+		total += count
+	}
+	if total == 0 {
+		return 1
+	}
+	return 2 * float64(common) / float64(total)
+}
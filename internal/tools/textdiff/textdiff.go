@@ -0,0 +1,184 @@
+package textdiff
+
+import (
+	"fmt"
+	"strings"
+)
+
+type Op string
+
+const (
+	Equal  Op = "equal"
+	Delete Op = "delete"
+	Insert Op = "insert"
+)
+
+// Line is a line of the diff. OldLine and NewLine are 1-based, 0 when the line is missing on that side.
+type Line struct {
+	Op      Op     `json:"op"`
+	Text    string `json:"text"`
+	OldLine int    `json:"oldLine,omitempty"`
+	NewLine int    `json:"newLine,omitempty"`
+}
+
+// Lines computes the shortest line diff turning a into b (Myers' algorithm)
+func Lines(a, b string) (ret []Line) {
+	oldLines, newLines := splitLines(a), splitLines(b)
+	n, m := len(oldLines), len(newLines)
+	max := n + m
+	offset := max + 1
+
+	// trace keeps the furthest reaching x per diagonal after each edit step for the backtrack
+	v := make([]int, 2*max+3)
+	var trace [][]int
+	for d := 0; d <= max; d++ {
+		trace = append(trace, append([]int(nil), v...))
+		done := false
+		for k := -d; k <= d; k += 2 {
+			var x int
+			if k == -d || (k != d && v[offset+k-1] < v[offset+k+1]) {
+				x = v[offset+k+1]
+			} else {
+				x = v[offset+k-1] + 1
+			}
+			y := x - k
+			for x < n && y < m && oldLines[x] == newLines[y] {
+				x++
+				y++
+			}
+			v[offset+k] = x
+			if x >= n && y >= m {
+				done = true
+				break
+			}
+		}
+		if done {
+			trace = append(trace, v)
+			break
+		}
+	}
+
+	// walk back from (n, m), collecting the lines in reverse
+	x, y := n, m
+	for d := len(trace) - 2; d >= 0; d-- {
+		v := trace[d]
+		k := x - y
+		var prevK int
+		if k == -d || (k != d && v[offset+k-1] < v[offset+k+1]) {
+			prevK = k + 1
+		} else {
+			prevK = k - 1
+		}
+		prevX := v[offset+prevK]
+		prevY := prevX - prevK
+		for x > prevX && y > prevY {
+			ret = append(ret, Line{Op: Equal, Text: oldLines[x-1], OldLine: x, NewLine: y})
+			x--
+			y--
+		}
+		if d > 0 {
+			if x == prevX {
+				ret = append(ret, Line{Op: Insert, Text: newLines[y-1], NewLine: y})
+			} else {
+				ret = append(ret, Line{Op: Delete, Text: oldLines[x-1], OldLine: x})
+			}
+		}
+		x, y = prevX, prevY
+	}
+
+	for i, j := 0, len(ret)-1; i < j; i, j = i+1, j-1 {
+		ret[i], ret[j] = ret[j], ret[i]
+	}
+	return
+}
+
+// Unified formats the diff of a and b in the unified format with the given number of context lines
+func Unified(a, b, nameA, nameB string, context int) string {
+	lines := Lines(a, b)
+
+	var out strings.Builder
+	fmt.Fprintf(&out, "--- %s\n+++ %s\n", nameA, nameB)
+	for start := 0; start < len(lines); {
+		// find the next change and extend the hunk while changes are close enough to share context
+		first := start
+		for first < len(lines) && lines[first].Op == Equal {
+			first++
+		}
+		if first == len(lines) {
+			break
+		}
+		hunkStart := maxInt(start, first-context)
+		end := first
+		for end < len(lines) {
+			if lines[end].Op != Equal {
+				end++
+				continue
+			}
+			next := end
+			for next < len(lines) && lines[next].Op == Equal {
+				next++
+			}
+			if next == len(lines) || next-end > 2*context {
+				break
+			}
+			end = next
+		}
+		hunkEnd := minInt(len(lines), end+context)
+
+		writeHunk(&out, lines[hunkStart:hunkEnd])
+		start = hunkEnd
+	}
+	return out.String()
+}
+
+func writeHunk(out *strings.Builder, hunk []Line) {
+	oldStart, newStart, oldCount, newCount := 0, 0, 0, 0
+	for _, line := range hunk {
+		if line.OldLine > 0 {
+			if oldStart == 0 {
+				oldStart = line.OldLine
+			}
+			oldCount++
+		}
+		if line.NewLine > 0 {
+			if newStart == 0 {
+				newStart = line.NewLine
+			}
+			newCount++
+		}
+	}
+	fmt.Fprintf(out, "@@ -%d,%d +%d,%d @@\n", oldStart, oldCount, newStart, newCount)
+	for _, line := range hunk {
+		switch line.Op {
+		case Equal:
+			out.WriteString(" ")
+		case Delete:
+			out.WriteString("-")
+		case Insert:
+			out.WriteString("+")
+		}
+		out.WriteString(line.Text)
+		out.WriteString("\n")
+	}
+}
+
+func splitLines(text string) []string {
+	if text == "" {
+		return nil
+	}
+	return strings.Split(strings.TrimSuffix(text, "\n"), "\n")
+}
+
+func minInt(a, b int) int {
+	if a < b {
+		return a
+	}
+	return b
+}
+
+func maxInt(a, b int) int {
+	if a > b {
+		return a
+	}
+	return b
+}
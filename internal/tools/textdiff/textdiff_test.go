@@ -0,0 +1,105 @@
+package textdiff
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestLines(t *testing.T) {
+	tests := []struct {
+		name     string
+		a, b     string
+		expected string // one char per line: = equal, - delete, + insert
+	}{
+		{"identical", "a\nb\nc", "a\nb\nc", "==="},
+		{"empty old", "", "a\nb", "++"},
+		{"empty new", "a\nb", "", "--"},
+		{"changed line", "a\nb\nc", "a\nx\nc", "=-+="},
+		{"inserted line", "a\nc", "a\nb\nc", "=+="},
+		{"deleted line", "a\nb\nc", "a\nc", "=-="},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var ops strings.Builder
+			for _, line := range Lines(tt.a, tt.b) {
+				switch line.Op {
+				case Equal:
+					ops.WriteString("=")
+				case Delete:
+					ops.WriteString("-")
+				case Insert:
+					ops.WriteString("+")
+				}
+			}
+			if ops.String() != tt.expected {
+				t.Errorf("Lines() ops = %q, expected %q", ops.String(), tt.expected)
+			}
+		})
+	}
+}
+
+func TestLines_RebuildsBothSides(t *testing.T) {
+	a := "the\nquick\nbrown\nfox\njumps\nover\nthe\nlazy\ndog"
+	b := "a\nquick\nred\nfox\njumps\nhigh\nover\nthe\ndog\n!"
+
+	var oldText, newText []string
+	for _, line := range Lines(a, b) {
+		if line.Op != Insert {
+			oldText = append(oldText, line.Text)
+		}
+		if line.Op != Delete {
+			newText = append(newText, line.Text)
+		}
+	}
+	if strings.Join(oldText, "\n") != a || strings.Join(newText, "\n") != b {
+		t.Errorf("diff doesn't rebuild the inputs:\n%v\n%v", oldText, newText)
+	}
+}
+
+func TestUnified(t *testing.T) {
+	a := "1\n2\n3\n4\n5\n6\n7\n8\n9\n10"
+	b := "1\n2\n3\nfour\n5\n6\n7\n8\n9\n10"
+
+	expected := "--- a\n+++ b\n@@ -2,5 +2,5 @@\n 2\n 3\n-4\n+four\n 5\n 6\n"
+	if ret := Unified(a, b, "a", "b", 2); ret != expected {
+		t.Errorf("Unified() =\n%s\nexpected\n%s", ret, expected)
+	}
+
+	if ret := Unified(a, a, "a", "b", 2); ret != "--- a\n+++ b\n" {
+		t.Errorf("expected no hunks for identical texts, got\n%s", ret)
+	}
+}
+
+func TestSentences(t *testing.T) {
+	a := "# Summary\nThe cat sat on the mat. It was happy!\nPrices rose sharply."
+	b := "# Summary\nThe cat sat on a mat. Dogs barked all night.\nPrices rose sharply."
+
+	pairs := Sentences(a, b)
+	expected := []SentencePair{
+		{A: "# Summary", B: "# Summary", Similarity: 1},
+		{A: "The cat sat on the mat.", B: "The cat sat on a mat.", Similarity: 5.0 / 6},
+		{A: "It was happy!"},
+		{B: "Dogs barked all night."},
+		{A: "Prices rose sharply.", B: "Prices rose sharply.", Similarity: 1},
+	}
+	if len(pairs) != len(expected) {
+		t.Fatalf("Sentences() = %+v, expected %+v", pairs, expected)
+	}
+	for i := range expected {
+		if pairs[i].A != expected[i].A || pairs[i].B != expected[i].B ||
+			pairs[i].Similarity < expected[i].Similarity-0.001 || pairs[i].Similarity > expected[i].Similarity+0.001 {
+			t.Errorf("Sentences()[%d] = %+v, expected %+v", i, pairs[i], expected[i])
+		}
+	}
+}
+
+func TestSentencesEmpty(t *testing.T) {
+	if pairs := Sentences("", ""); len(pairs) != 0 {
+		t.Errorf("Sentences() = %+v, expected no pairs", pairs)
+	}
+	pairs := Sentences("", "One. Two.")
+	if len(pairs) != 2 || pairs[0].B != "One." || pairs[0].A != "" || pairs[1].B != "Two." {
+		t.Errorf("Sentences() = %+v, expected the two sentences of b", pairs)
+	}
+}
@@ -0,0 +1,65 @@
+package textfile
+
+import (
+	"path/filepath"
+	"strings"
+	"unicode/utf8"
+)
+
+// DefaultSeparator is put between combined input files
+const DefaultSeparator = "\n\n"
+
+// DefaultHeader precedes each of several combined input files
+const DefaultHeader = "==> {{path}} <=="
+
+// File is a text file read for Combine
+type File struct {
+	Path    string
+	Content string
+}
+
+// ReadFiles reads text files of at most maxBytes each, see Read
+func ReadFiles(paths []string, maxBytes int64) (ret []File, err error) {
+	for _, path := range paths {
+		var content string
+		if content, err = Read(path, maxBytes); err != nil {
+			return
+		}
+		ret = append(ret, File{Path: path, Content: content})
+	}
+	return
+}
+
+// Combine joins the files with the separator. With more than one file each file is preceded by the
+// header, in which {{path}} and {{name}} are replaced by the path and base name of the file. The
+// escapes \n and \t in separator and header are expanded so they can be given on the command line.
+func Combine(files []File, separator string, header string) (ret string) {
+	if len(files) == 1 {
+		return files[0].Content
+	}
+
+	separator = Unescape(separator)
+	header = Unescape(header)
+
+	parts := make([]string, 0, len(files))
+	for _, file := range files {
+		part := file.Content
+		if header != "" {
+			replacer := strings.NewReplacer("{{path}}", file.Path, "{{name}}", filepath.Base(file.Path))
+			part = replacer.Replace(header) + "\n" + part
+		}
+		parts = append(parts, part)
+	}
+	ret = strings.Join(parts, separator)
+	return
+}
+
+// Unescape expands the escapes \n, \t and \\
+func Unescape(value string) string {
+	return strings.NewReplacer(`\\`, `\`, `\n`, "\n", `\t`, "\t").Replace(value)
+}
+
+// EstimateTokens estimates the token count of text at about four characters per token
+func EstimateTokens(text string) int {
+	return (utf8.RuneCountInString(text) + 3) / 4
+}
@@ -0,0 +1,111 @@
+package textfile
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"os"
+	"unicode/utf16"
+	"unicode/utf8"
+)
+
+// DefaultMaxBytes is the size limit of input files
+const DefaultMaxBytes = 10 << 20
+
+// binarySniffLen is how much of the content is inspected to tell binary from text
+const binarySniffLen = 8000
+
+var ErrBinary = errors.New("binary content is not supported")
+
+var (
+	bomUTF8    = []byte{0xEF, 0xBB, 0xBF}
+	bomUTF16LE = []byte{0xFF, 0xFE}
+	bomUTF16BE = []byte{0xFE, 0xFF}
+)
+
+// Read reads a text file of at most maxBytes and decodes it to UTF-8, see Decode
+func Read(path string, maxBytes int64) (ret string, err error) {
+	var info os.FileInfo
+	if info, err = os.Stat(path); err != nil {
+		return
+	}
+	if info.IsDir() {
+		err = fmt.Errorf("%s is a directory", path)
+		return
+	}
+	if info.Size() > maxBytes {
+		err = fmt.Errorf("%s has %d bytes, more than the limit of %d bytes", path, info.Size(), maxBytes)
+		return
+	}
+
+	var data []byte
+	if data, err = os.ReadFile(path); err != nil {
+		return
+	}
+	if ret, err = Decode(data); err != nil {
+		err = fmt.Errorf("could not read %s: %w", path, err)
+	}
+	return
+}
+
+// Decode converts text to UTF-8. UTF-8 and UTF-16 are detected by their byte order mark, content without
+// one is UTF-8 if valid and Latin-1 otherwise. Content with NUL bytes or mostly control characters is
+// rejected with ErrBinary.
+func Decode(data []byte) (ret string, err error) {
+	switch {
+	case bytes.HasPrefix(data, bomUTF8):
+		data = data[len(bomUTF8):]
+	case bytes.HasPrefix(data, bomUTF16LE):
+		return decodeUTF16(data[len(bomUTF16LE):], binary.LittleEndian)
+	case bytes.HasPrefix(data, bomUTF16BE):
+		return decodeUTF16(data[len(bomUTF16BE):], binary.BigEndian)
+	}
+
+	if isBinary(data) {
+		err = ErrBinary
+		return
+	}
+	if utf8.Valid(data) {
+		ret = string(data)
+		return
+	}
+
+	// legacy single byte encodings are far more common than anything else, Latin-1 maps every byte
+	runes := make([]rune, len(data))
+	for i, b := range data {
+		runes[i] = rune(b)
+	}
+	ret = string(runes)
+	return
+}
+
+func decodeUTF16(data []byte, order binary.ByteOrder) (ret string, err error) {
+	if len(data)%2 != 0 {
+		err = fmt.Errorf("invalid UTF-16 content, odd number of bytes")
+		return
+	}
+	units := make([]uint16, len(data)/2)
+	for i := range units {
+		units[i] = order.Uint16(data[2*i:])
+	}
+	ret = string(utf16.Decode(units))
+	return
+}
+
+func isBinary(data []byte) bool {
+	sample := data[:min(len(data), binarySniffLen)]
+	if len(sample) == 0 {
+		return false
+	}
+	if bytes.IndexByte(sample, 0) >= 0 {
+		return true
+	}
+	control := 0
+	for _, b := range sample {
+		if b < 0x20 && b != '\n' && b != '\r' && b != '\t' && b != '\f' && b != 0x1b {
+			control++
+		}
+	}
+	return control*10 > len(sample)
+}
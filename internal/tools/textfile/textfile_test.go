@@ -0,0 +1,124 @@
+package textfile
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestDecode(t *testing.T) {
+	tests := []struct {
+		name     string
+		data     []byte
+		expected string
+	}{
+		{"utf-8", []byte("héllo wörld\n"), "héllo wörld\n"},
+		{"utf-8 with bom", []byte("\xEF\xBB\xBFhello"), "hello"},
+		{"utf-16le with bom", []byte{0xFF, 0xFE, 'h', 0, 0xE9, 0}, "hé"},
+		{"utf-16be with bom", []byte{0xFE, 0xFF, 0, 'h', 0, 0xE9}, "hé"},
+		{"latin-1", []byte("caf\xE9"), "café"},
+		{"empty", nil, ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ret, err := Decode(tt.data)
+			if err != nil {
+				t.Fatalf("Decode() failed: %v", err)
+			}
+			if ret != tt.expected {
+				t.Errorf("Decode() = %q, expected %q", ret, tt.expected)
+			}
+		})
+	}
+}
+
+func TestDecode_RejectsBinary(t *testing.T) {
+	for name, data := range map[string][]byte{
+		"nul bytes":        []byte("PK\x03\x04\x00\x00zip"),
+		"control bytes":    []byte(strings.Repeat("\x01\x02\x03a", 10)),
+		"utf-16 fragments": {0xFF, 0xFE, 'a'},
+	} {
+		if _, err := Decode(data); err == nil {
+			t.Errorf("%s: expected an error", name)
+		}
+	}
+	if _, err := Decode([]byte("\x00\x01")); !errors.Is(err, ErrBinary) {
+		t.Errorf("expected ErrBinary, got %v", err)
+	}
+}
+
+func TestRead(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "input.txt")
+	if err := os.WriteFile(path, []byte("some input"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	if ret, err := Read(path, DefaultMaxBytes); err != nil || ret != "some input" {
+		t.Errorf("Read() = %q, %v", ret, err)
+	}
+	if _, err := Read(path, 4); err == nil || !strings.Contains(err.Error(), "limit") {
+		t.Errorf("expected the size limit error, got %v", err)
+	}
+	if _, err := Read(dir, DefaultMaxBytes); err == nil {
+		t.Error("expected an error for a directory")
+	}
+}
+
+func TestCombine(t *testing.T) {
+	files := []File{{Path: "docs/a.md", Content: "alpha"}, {Path: "b.txt", Content: "beta"}}
+
+	tests := []struct {
+		name      string
+		files     []File
+		separator string
+		header    string
+		expected  string
+	}{
+		{"single file is kept as is", files[:1], DefaultSeparator, DefaultHeader, "alpha"},
+		{"default header", files, DefaultSeparator, DefaultHeader, "==> docs/a.md <==\nalpha\n\n==> b.txt <==\nbeta"},
+		{"escaped separator and name", files, `\n---\n`, "# {{name}}", "# a.md\nalpha\n---\n# b.txt\nbeta"},
+		{"no header", files, "|", "", "alpha|beta"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if ret := Combine(tt.files, tt.separator, tt.header); ret != tt.expected {
+				t.Errorf("Combine() = %q, expected %q", ret, tt.expected)
+			}
+		})
+	}
+}
+
+func TestReadFiles(t *testing.T) {
+	dir := t.TempDir()
+	first := filepath.Join(dir, "first.txt")
+	second := filepath.Join(dir, "second.txt")
+	if err := os.WriteFile(first, []byte("one"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(second, []byte("two"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	files, err := ReadFiles([]string{first, second}, DefaultMaxBytes)
+	if err != nil {
+		t.Fatalf("ReadFiles() failed: %v", err)
+	}
+	if len(files) != 2 || files[0].Content != "one" || files[1].Path != second {
+		t.Errorf("ReadFiles() = %+v", files)
+	}
+
+	if _, err = ReadFiles([]string{first, filepath.Join(dir, "missing.txt")}, DefaultMaxBytes); err == nil {
+		t.Error("ReadFiles() should fail for a missing file")
+	}
+}
+
+func TestEstimateTokens(t *testing.T) {
+	if ret := EstimateTokens(strings.Repeat("é", 9)); ret != 3 {
+		t.Errorf("EstimateTokens() = %d, expected 3", ret)
+	}
+}
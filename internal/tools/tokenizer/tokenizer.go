@@ -0,0 +1,107 @@
+package tokenizer
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"os"
+	"os/exec"
+	"path"
+	"strconv"
+	"strings"
+	"time"
+
+	"gopkg.in/yaml.v3"
+)
+
+// FileName is the tokenizers file in the fabric config directory, it maps the models, or globs of their
+// names, to the command counting their tokens
+//
+//	llama3.1:8b: llama-tokenize --count
+//	"qwen*": python3 ~/bin/count_qwen_tokens.py
+const FileName = "tokenizers.yaml"
+
+// Timeout is how long a tokenizer command may take to count the tokens
+const Timeout = 30 * time.Second
+
+// Tokenizers are the commands counting the tokens of the models they are configured for. A command
+// receives the text on stdin and the model in FABRIC_MODEL, and prints the number of tokens. The models
+// without one keep the generic estimate.
+type Tokenizers struct {
+	Commands map[string]string
+}
+
+// Load reads the tokenizers file, a missing file means no tokenizers. An invalid file returns nil
+// tokenizers, which count nothing.
+func Load(filePath string) (ret *Tokenizers, err error) {
+	var data []byte
+	if data, err = os.ReadFile(filePath); err != nil {
+		if os.IsNotExist(err) {
+			ret, err = &Tokenizers{Commands: map[string]string{}}, nil
+		}
+		return
+	}
+	commands := map[string]string{}
+	if err = yaml.Unmarshal(data, &commands); err != nil {
+		err = fmt.Errorf("invalid tokenizers file %s: %v", filePath, err)
+		return
+	}
+	for model := range commands {
+		if _, err = path.Match(model, ""); err != nil {
+			err = fmt.Errorf("invalid model glob %q in tokenizers file %s: %v", model, filePath, err)
+			return
+		}
+	}
+	ret = &Tokenizers{Commands: commands}
+	return
+}
+
+// Command returns the tokenizer of the model: the one of its name, or of the longest glob matching it
+func (o *Tokenizers) Command(model string) (ret string) {
+	if o == nil || model == "" {
+		return
+	}
+	if command, ok := o.Commands[model]; ok {
+		return command
+	}
+	longest := -1
+	for glob, command := range o.Commands {
+		if matched, _ := path.Match(glob, model); matched && len(glob) > longest {
+			ret, longest = command, len(glob)
+		}
+	}
+	return
+}
+
+// Count counts the tokens of the text with the tokenizer of the model, ok is false when it has none
+func (o *Tokenizers) Count(model string, text string) (ret int, ok bool, err error) {
+	command := o.Command(model)
+	if command == "" {
+		return
+	}
+	ok = true
+
+	ctx, cancel := context.WithTimeout(context.Background(), Timeout)
+	defer cancel()
+	cmd := exec.CommandContext(ctx, "sh", "-c", command)
+	cmd.Stdin = strings.NewReader(text)
+	cmd.Env = append(os.Environ(), "FABRIC_MODEL="+model)
+	var stdout, stderr bytes.Buffer
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+	if err = cmd.Run(); err != nil {
+		err = fmt.Errorf("tokenizer %q of %s failed: %v %s", command, model, err, strings.TrimSpace(stderr.String()))
+		return
+	}
+
+	// the count is the first field, so the output of wc -w and alike is fine
+	fields := strings.Fields(stdout.String())
+	if len(fields) == 0 {
+		err = fmt.Errorf("tokenizer %q of %s printed no count", command, model)
+		return
+	}
+	if ret, err = strconv.Atoi(fields[0]); err != nil || ret < 0 {
+		err = fmt.Errorf("tokenizer %q of %s printed %q instead of a count", command, model, fields[0])
+	}
+	return
+}
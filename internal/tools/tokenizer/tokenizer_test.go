@@ -0,0 +1,49 @@
+package tokenizer
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLoad(t *testing.T) {
+	filePath := filepath.Join(t.TempDir(), FileName)
+	tokenizers, err := Load(filePath)
+	require.NoError(t, err)
+	assert.Empty(t, tokenizers.Command("llama3"))
+
+	require.NoError(t, os.WriteFile(filePath, []byte("llama3.1:8b: exact\n\"llama*\": generic\n\"llama3*\": specific\n"), 0644))
+	tokenizers, err = Load(filePath)
+	require.NoError(t, err)
+	assert.Equal(t, "exact", tokenizers.Command("llama3.1:8b"))
+	assert.Equal(t, "specific", tokenizers.Command("llama3.2:1b"))
+	assert.Equal(t, "generic", tokenizers.Command("llama2"))
+	assert.Empty(t, tokenizers.Command("gpt-4o"))
+
+	require.NoError(t, os.WriteFile(filePath, []byte("\"[llama\": broken\n"), 0644))
+	_, err = Load(filePath)
+	assert.Error(t, err)
+}
+
+func TestCount(t *testing.T) {
+	tokenizers := &Tokenizers{Commands: map[string]string{"local*": "wc -w", "broken": "echo none"}}
+
+	count, ok, err := tokenizers.Count("local-model", "one two three")
+	require.NoError(t, err)
+	assert.True(t, ok)
+	assert.Equal(t, 3, count)
+
+	_, ok, err = tokenizers.Count("gpt-4o", "one two three")
+	assert.NoError(t, err)
+	assert.False(t, ok)
+
+	_, _, err = tokenizers.Count("broken", "text")
+	assert.Error(t, err)
+
+	var none *Tokenizers
+	_, ok, _ = none.Count("local-model", "text")
+	assert.False(t, ok)
+}
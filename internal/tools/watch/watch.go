@@ -0,0 +1,106 @@
+package watch
+
+import (
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+)
+
+// Dir polls a directory for new and changed files. Watching is done by polling rather than file system
+// events so it works the same on every platform and on network drives.
+type Dir struct {
+	Path       string
+	Extensions []string // lower case with the dot, all files when empty
+
+	files  map[string]*fileState
+	primed bool
+}
+
+type fileState struct {
+	size    int64
+	modTime time.Time
+	pending bool // changed since it was last returned
+}
+
+// NewDir watches the files of path, not its subdirectories, with one of the extensions
+func NewDir(path string, extensions []string) *Dir {
+	ret := &Dir{Path: path, files: map[string]*fileState{}}
+	for _, extension := range extensions {
+		if extension = strings.ToLower(strings.TrimSpace(extension)); extension == "" {
+			continue
+		}
+		if !strings.HasPrefix(extension, ".") {
+			extension = "." + extension
+		}
+		ret.Extensions = append(ret.Extensions, extension)
+	}
+	return ret
+}
+
+// Poll returns the paths of the files created or changed since the previous poll that didn't change
+// since, so files still being written are returned once they are complete. The files present at the
+// first poll are not new, they are only returned when they change.
+func (o *Dir) Poll() (ret []string, err error) {
+	var entries []os.DirEntry
+	if entries, err = os.ReadDir(o.Path); err != nil {
+		return
+	}
+
+	seen := map[string]bool{}
+	for _, entry := range entries {
+		if entry.IsDir() || !o.matches(entry.Name()) {
+			continue
+		}
+		info, infoErr := entry.Info()
+		if infoErr != nil {
+			// removed since the directory was read
+			continue
+		}
+		path := filepath.Join(o.Path, entry.Name())
+		seen[path] = true
+
+		state, known := o.files[path]
+		switch {
+		case !known:
+			o.files[path] = &fileState{size: info.Size(), modTime: info.ModTime(), pending: o.primed}
+		case state.size != info.Size() || !state.modTime.Equal(info.ModTime()):
+			state.size, state.modTime, state.pending = info.Size(), info.ModTime(), true
+		case state.pending:
+			state.pending = false
+			ret = append(ret, path)
+		}
+	}
+	for path := range o.files {
+		if !seen[path] {
+			delete(o.files, path)
+		}
+	}
+	o.primed = true
+	sort.Strings(ret)
+	return
+}
+
+// matches skips the hidden files, like the temporary files of editors, and the other extensions
+func (o *Dir) matches(name string) bool {
+	if strings.HasPrefix(name, ".") || strings.HasSuffix(name, "~") {
+		return false
+	}
+	if len(o.Extensions) == 0 {
+		return true
+	}
+	extension := strings.ToLower(filepath.Ext(name))
+	for _, allowed := range o.Extensions {
+		if extension == allowed {
+			return true
+		}
+	}
+	return false
+}
+
+// OutputPath is the path of the output of the input file in outputDir: its name with the .md extension
+func OutputPath(outputDir string, inputPath string) string {
+	name := filepath.Base(inputPath)
+	return filepath.Join(outputDir, strings.TrimSuffix(name, filepath.Ext(name))+".md")
+}
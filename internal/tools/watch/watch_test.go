@@ -0,0 +1,84 @@
+package watch
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func writeFile(t *testing.T, path string, content string, modTime time.Time) {
+	t.Helper()
+	require.NoError(t, os.WriteFile(path, []byte(content), 0644))
+	require.NoError(t, os.Chtimes(path, modTime, modTime))
+}
+
+func TestPollReturnsStableNewFiles(t *testing.T) {
+	dir := t.TempDir()
+	start := time.Now().Add(-time.Hour)
+	writeFile(t, filepath.Join(dir, "existing.md"), "old", start)
+
+	watched := NewDir(dir, nil)
+	ready, err := watched.Poll()
+	require.NoError(t, err)
+	assert.Empty(t, ready, "files present at the start are not new")
+
+	note := filepath.Join(dir, "note.md")
+	writeFile(t, note, "draft", start.Add(time.Minute))
+	ready, err = watched.Poll()
+	require.NoError(t, err)
+	assert.Empty(t, ready, "a new file waits a poll to be complete")
+
+	writeFile(t, note, "draft, longer", start.Add(2*time.Minute))
+	ready, err = watched.Poll()
+	require.NoError(t, err)
+	assert.Empty(t, ready, "a file still being written waits")
+
+	ready, err = watched.Poll()
+	require.NoError(t, err)
+	assert.Equal(t, []string{note}, ready)
+
+	ready, err = watched.Poll()
+	require.NoError(t, err)
+	assert.Empty(t, ready, "a file is returned once per change")
+
+	writeFile(t, filepath.Join(dir, "existing.md"), "changed", start.Add(3*time.Minute))
+	_, err = watched.Poll()
+	require.NoError(t, err)
+	ready, err = watched.Poll()
+	require.NoError(t, err)
+	assert.Equal(t, []string{filepath.Join(dir, "existing.md")}, ready)
+}
+
+func TestPollFiltersFiles(t *testing.T) {
+	dir := t.TempDir()
+	watched := NewDir(dir, []string{"MD", ".txt", " "})
+	assert.Equal(t, []string{".md", ".txt"}, watched.Extensions)
+	_, err := watched.Poll()
+	require.NoError(t, err)
+
+	modTime := time.Now().Add(-time.Hour)
+	for _, name := range []string{"a.md", "b.TXT", "c.pdf", ".hidden.md", "d.md~"} {
+		writeFile(t, filepath.Join(dir, name), name, modTime)
+	}
+	require.NoError(t, os.Mkdir(filepath.Join(dir, "sub.md"), 0755))
+
+	_, err = watched.Poll()
+	require.NoError(t, err)
+	ready, err := watched.Poll()
+	require.NoError(t, err)
+	assert.Equal(t, []string{filepath.Join(dir, "a.md"), filepath.Join(dir, "b.TXT")}, ready)
+}
+
+func TestPollMissingDirectory(t *testing.T) {
+	_, err := NewDir(filepath.Join(t.TempDir(), "missing"), nil).Poll()
+	assert.Error(t, err)
+}
+
+func TestOutputPath(t *testing.T) {
+	assert.Equal(t, filepath.Join("out", "report.md"), OutputPath("out", filepath.Join("in", "report.txt")))
+	assert.Equal(t, filepath.Join("out", "notes.md"), OutputPath("out", "notes"))
+}
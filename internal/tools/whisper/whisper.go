@@ -0,0 +1,150 @@
+package whisper
+
+// see https://github.com/ggml-org/whisper.cpp for more information
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+
+	"github.com/danielmiessler/fabric/internal/plugins"
+)
+
+// VendorName selects whisper.cpp as the transcription vendor, e.g. with --transcribe-vendor
+const VendorName = "whisper.cpp"
+
+// DefaultBinary is the command line tool of whisper.cpp, named main in releases before 1.7.4
+const DefaultBinary = "whisper-cli"
+
+// audio formats whisper-cli decodes itself, the others are converted to WAV with ffmpeg
+var nativeFormats = map[string]bool{".wav": true, ".mp3": true, ".flac": true, ".ogg": true}
+
+// WhisperCpp transcribes audio locally with the whisper.cpp command line tool
+type WhisperCpp struct {
+	*plugins.PluginBase
+	ModelPath *plugins.SetupQuestion
+	Binary    *plugins.SetupQuestion
+}
+
+func NewWhisperCpp() (ret *WhisperCpp) {
+	label := "Whisper.cpp"
+
+	ret = &WhisperCpp{
+		PluginBase: &plugins.PluginBase{
+			Name:             label,
+			SetupDescription: "Whisper.cpp - local speech-to-text for audio input",
+			EnvNamePrefix:    plugins.BuildEnvVariablePrefix(label),
+		},
+	}
+
+	ret.ModelPath = ret.AddSetupQuestionCustom("Model Path", true,
+		"Enter the path of the whisper.cpp ggml model file (e.g. ~/whisper.cpp/models/ggml-base.en.bin)")
+	ret.Binary = ret.AddSetupQuestionCustom("Binary", false,
+		fmt.Sprintf("Enter the whisper.cpp command (leave empty for %s)", DefaultBinary))
+
+	return
+}
+
+// TranscribeFile transcribes the audio file, model is the path of a ggml model file overriding the configured one
+func (o *WhisperCpp) TranscribeFile(ctx context.Context, filePath string, model string, language string) (ret string, err error) {
+	if model == "" {
+		model = o.ModelPath.Value
+	}
+	if model, err = expandHome(model); err != nil {
+		return
+	}
+	if _, err = os.Stat(model); err != nil {
+		err = fmt.Errorf("whisper.cpp model file not found: %v", err)
+		return
+	}
+
+	binary := o.Binary.Value
+	if binary == "" {
+		binary = DefaultBinary
+	}
+	if language == "" {
+		language = "auto"
+	}
+
+	audioPath := filePath
+	if !nativeFormats[strings.ToLower(filepath.Ext(filePath))] {
+		var cleanup func()
+		if audioPath, cleanup, err = convertToWav(ctx, filePath); err != nil {
+			return
+		}
+		defer cleanup()
+	}
+
+	var stdout, stderr bytes.Buffer
+	cmd := exec.CommandContext(ctx, binary, BuildArgs(model, audioPath, language)...)
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+	if err = cmd.Run(); err != nil {
+		err = fmt.Errorf("whisper.cpp failed: %v: %s", err, strings.TrimSpace(lastLine(stderr.String())))
+		return
+	}
+	ret = CleanTranscript(stdout.String())
+	return
+}
+
+// BuildArgs returns the whisper-cli arguments printing the plain transcript only
+func BuildArgs(model string, audioPath string, language string) []string {
+	return []string{"-m", model, "-f", audioPath, "-l", language, "--no-timestamps", "--no-prints"}
+}
+
+// CleanTranscript joins the segments whisper-cli prints one per line
+func CleanTranscript(output string) string {
+	var segments []string
+	for _, line := range strings.Split(output, "\n") {
+		if line = strings.TrimSpace(line); line != "" {
+			segments = append(segments, line)
+		}
+	}
+	return strings.Join(segments, " ")
+}
+
+// convertToWav converts the audio to the 16 kHz mono WAV whisper.cpp expects
+func convertToWav(ctx context.Context, filePath string) (wavPath string, cleanup func(), err error) {
+	if _, err = exec.LookPath("ffmpeg"); err != nil {
+		err = fmt.Errorf("whisper.cpp can't read %s, install ffmpeg to convert it", filepath.Ext(filePath))
+		return
+	}
+
+	var dir string
+	if dir, err = os.MkdirTemp("", "fabric-whisper-"); err != nil {
+		return
+	}
+	cleanup = func() { os.RemoveAll(dir) }
+
+	wavPath = filepath.Join(dir, "audio.wav")
+	cmd := exec.CommandContext(ctx, "ffmpeg", "-nostdin", "-loglevel", "error", "-i", filePath, "-ar", "16000", "-ac", "1", wavPath)
+	if output, convertErr := cmd.CombinedOutput(); convertErr != nil {
+		cleanup()
+		err = fmt.Errorf("could not convert %s to WAV: %v: %s", filePath, convertErr, strings.TrimSpace(string(output)))
+	}
+	return
+}
+
+func expandHome(path string) (ret string, err error) {
+	ret = path
+	if strings.HasPrefix(path, "~/") {
+		var home string
+		if home, err = os.UserHomeDir(); err != nil {
+			return
+		}
+		ret = filepath.Join(home, path[2:])
+	}
+	return
+}
+
+func lastLine(text string) string {
+	text = strings.TrimSpace(text)
+	if i := strings.LastIndex(text, "\n"); i >= 0 {
+		return text[i+1:]
+	}
+	return text
+}
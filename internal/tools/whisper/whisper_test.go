@@ -0,0 +1,56 @@
+package whisper
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"testing"
+)
+
+func TestCleanTranscript(t *testing.T) {
+	output := "\n Hello there.\n\n General Kenobi. \n"
+	if got := CleanTranscript(output); got != "Hello there. General Kenobi." {
+		t.Errorf("unexpected transcript: %q", got)
+	}
+}
+
+func TestTranscribeFile(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("uses a shell script as whisper.cpp binary")
+	}
+
+	dir := t.TempDir()
+	model := filepath.Join(dir, "ggml-test.bin")
+	if err := os.WriteFile(model, []byte("model"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	// stands in for whisper-cli, echoes the arguments back as transcript
+	binary := filepath.Join(dir, "whisper-cli")
+	if err := os.WriteFile(binary, []byte("#!/bin/sh\necho \"$@\"\n"), 0755); err != nil {
+		t.Fatal(err)
+	}
+
+	whisper := NewWhisperCpp()
+	whisper.ModelPath.Value = model
+	whisper.Binary.Value = binary
+
+	transcript, err := whisper.TranscribeFile(context.Background(), "talk.wav", "", "")
+	if err != nil {
+		t.Fatalf("TranscribeFile failed: %v", err)
+	}
+	expected := strings.Join(BuildArgs(model, "talk.wav", "auto"), " ")
+	if transcript != expected {
+		t.Errorf("expected %q, got %q", expected, transcript)
+	}
+}
+
+func TestTranscribeFileMissingModel(t *testing.T) {
+	whisper := NewWhisperCpp()
+	whisper.ModelPath.Value = filepath.Join(t.TempDir(), "missing.bin")
+
+	if _, err := whisper.TranscribeFile(context.Background(), "talk.wav", "", "en"); err == nil {
+		t.Error("expected an error for a missing model file")
+	}
+}
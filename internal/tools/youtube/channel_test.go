@@ -0,0 +1,52 @@
+package youtube
+
+import (
+	"testing"
+)
+
+func TestGetChannelIdOrHandle(t *testing.T) {
+	tests := []struct {
+		url       string
+		channelId string
+		handle    string
+	}{
+		{"https://www.youtube.com/channel/UCsXVk37bltHxD1rDPwtNM8Q", "UCsXVk37bltHxD1rDPwtNM8Q", ""},
+		{"https://youtube.com/channel/UC_x5XG1OV2P6uZZ5FSM9Ttw/videos", "UC_x5XG1OV2P6uZZ5FSM9Ttw", ""},
+		{"https://www.youtube.com/@kurzgesagt", "", "@kurzgesagt"},
+		{"https://m.youtube.com/@some.creator-1/videos", "", "@some.creator-1"},
+		{"youtube.com/@handle?si=abc", "", "@handle"},
+		{"https://www.youtube.com/watch?v=dQw4w9WgXcQ", "", ""},
+		{"https://www.youtube.com/playlist?list=PLrAXtmErZgOeiKm4sgNOknGvNjby9efdf", "", ""},
+		{"https://youtu.be/dQw4w9WgXcQ", "", ""},
+		{"https://www.youtube.com/channel/not-a-channel-id", "", ""},
+		{"", "", ""},
+	}
+
+	yt := &YouTube{}
+	for _, test := range tests {
+		channelId, handle := yt.GetChannelIdOrHandle(test.url)
+		if channelId != test.channelId || handle != test.handle {
+			t.Errorf("GetChannelIdOrHandle(%q) = %q, %q, expected %q, %q", test.url, channelId, handle, test.channelId, test.handle)
+		}
+	}
+}
+
+func TestIsChannelUrl(t *testing.T) {
+	tests := []struct {
+		url      string
+		expected bool
+	}{
+		{"https://www.youtube.com/channel/UCsXVk37bltHxD1rDPwtNM8Q", true},
+		{"https://www.youtube.com/@kurzgesagt/videos", true},
+		{"https://www.youtube.com/watch?v=dQw4w9WgXcQ&list=PLrAXtmErZgOeiKm4sgNOknGvNjby9efdf", false},
+		{"https://www.youtube.com/shorts/abcdefghijk", false},
+		{"https://example.com/@someone", false},
+	}
+
+	yt := &YouTube{}
+	for _, test := range tests {
+		if result := yt.IsChannelUrl(test.url); result != test.expected {
+			t.Errorf("IsChannelUrl(%q) = %v, expected %v", test.url, result, test.expected)
+		}
+	}
+}
@@ -34,6 +34,7 @@ var timestampRegex *regexp.Regexp
 var languageFileRegex *regexp.Regexp
 var videoPatternRegex *regexp.Regexp
 var playlistPatternRegex *regexp.Regexp
+var channelPatternRegex *regexp.Regexp
 var vttTagRegex *regexp.Regexp
 var durationRegex *regexp.Regexp
 
@@ -48,6 +49,8 @@ func init() {
 	videoPatternRegex = regexp.MustCompile(`(?:https?:\/\/)?(?:www\.)?(?:youtube\.com\/(?:live\/|[^\/\n\s]+\/\S+\/|(?:v|e(?:mbed)?)\/|(?:s(?:horts)\/)|\S*?[?&]v=)|youtu\.be\/)([a-zA-Z0-9_-]*)`)
 	// YouTube playlist ID pattern
 	playlistPatternRegex = regexp.MustCompile(`[?&]list=([a-zA-Z0-9_-]+)`)
+	// YouTube channel pattern, either by channel ID or by @handle
+	channelPatternRegex = regexp.MustCompile(`youtube\.com\/(?:channel\/(UC[a-zA-Z0-9_-]+)|(@[a-zA-Z0-9_.-]+))`)
 	// VTT formatting tags like <c.colorE5E5E5>, </c>, etc.
 	vttTagRegex = regexp.MustCompile(`<[^>]*>`)
 	// YouTube duration format PT1H2M3S
@@ -110,6 +113,71 @@ func (o *YouTube) GetVideoOrPlaylistId(url string) (videoId string, playlistId s
 	return
 }
 
+// GetChannelIdOrHandle extracts the channel ID or @handle from a channel URL
+func (o *YouTube) GetChannelIdOrHandle(url string) (channelId string, handle string) {
+	channelMatch := channelPatternRegex.FindStringSubmatch(url)
+	if len(channelMatch) > 2 {
+		channelId = channelMatch[1]
+		handle = channelMatch[2]
+	}
+	return
+}
+
+// IsChannelUrl reports whether the URL points to a channel rather than a video or playlist
+func (o *YouTube) IsChannelUrl(url string) bool {
+	channelId, handle := o.GetChannelIdOrHandle(url)
+	return channelId != "" || handle != ""
+}
+
+// FetchChannelUploadsPlaylistId resolves a channel URL to the playlist holding all channel uploads
+func (o *YouTube) FetchChannelUploadsPlaylistId(url string) (playlistId string, err error) {
+	channelId, handle := o.GetChannelIdOrHandle(url)
+	if channelId == "" && handle == "" {
+		err = fmt.Errorf("invalid YouTube channel URL: '%s'", url)
+		return
+	}
+
+	if err = o.initService(); err != nil {
+		return
+	}
+
+	call := o.service.Channels.List([]string{"contentDetails"})
+	if channelId != "" {
+		call = call.Id(channelId)
+	} else {
+		call = call.ForHandle(handle)
+	}
+
+	var response *youtube.ChannelListResponse
+	if response, err = call.Do(); err != nil {
+		err = fmt.Errorf("error getting channel details: %v", err)
+		return
+	}
+
+	if len(response.Items) == 0 || response.Items[0].ContentDetails == nil ||
+		response.Items[0].ContentDetails.RelatedPlaylists == nil {
+		err = fmt.Errorf("no channel found for URL: %s", url)
+		return
+	}
+	playlistId = response.Items[0].ContentDetails.RelatedPlaylists.Uploads
+	return
+}
+
+// ResolvePlaylistId returns the playlist ID of a playlist URL or the uploads playlist ID of a channel URL
+func (o *YouTube) ResolvePlaylistId(url string) (playlistId string, err error) {
+	if o.IsChannelUrl(url) {
+		return o.FetchChannelUploadsPlaylistId(url)
+	}
+
+	if _, playlistId, err = o.GetVideoOrPlaylistId(url); err != nil {
+		return
+	}
+	if playlistId == "" {
+		err = fmt.Errorf("URL is a video, not a playlist or channel: '%s'", url)
+	}
+	return
+}
+
 func (o *YouTube) GrabTranscriptForUrl(url string, language string) (ret string, err error) {
 	var videoId string
 	var playlistId string
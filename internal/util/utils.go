@@ -89,3 +89,43 @@ func GetDefaultConfigPath() (string, error) {
 	}
 	return defaultConfigPath, nil
 }
+
+// ResolveBelow resolves the symlinks of the absolute path, of its nearest existing parent when it doesn't
+// exist yet, and returns it when it is one of the roots or below one of them. ok is false otherwise.
+func ResolveBelow(path string, roots []string) (ret string, ok bool) {
+	if !filepath.IsAbs(path) {
+		return
+	}
+	var err error
+	if ret, err = resolveExisting(filepath.Clean(path)); err != nil {
+		return
+	}
+	for _, root := range roots {
+		if root = strings.TrimSpace(root); root == "" || !filepath.IsAbs(root) {
+			continue
+		}
+		if resolved, resolveErr := filepath.EvalSymlinks(root); resolveErr == nil {
+			if rel, relErr := filepath.Rel(resolved, ret); relErr == nil && filepath.IsLocal(rel) {
+				ok = true
+				return
+			}
+		}
+	}
+	ret = ""
+	return
+}
+
+// resolveExisting resolves the symlinks of the path, the missing end of the path is joined as it is
+func resolveExisting(path string) (ret string, err error) {
+	if ret, err = filepath.EvalSymlinks(path); err == nil || !os.IsNotExist(err) {
+		return
+	}
+	parent := filepath.Dir(path)
+	if parent == path {
+		return
+	}
+	if ret, err = resolveExisting(parent); err == nil {
+		ret = filepath.Join(ret, filepath.Base(path))
+	}
+	return
+}
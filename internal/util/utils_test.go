@@ -0,0 +1,36 @@
+package util
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestResolveBelow(t *testing.T) {
+	root, err := filepath.EvalSymlinks(t.TempDir())
+	if err != nil {
+		t.Fatalf("failed to resolve temp dir: %v", err)
+	}
+	outside := t.TempDir()
+	os.MkdirAll(filepath.Join(root, "notes"), os.ModePerm)
+	os.Symlink(outside, filepath.Join(root, "escape"))
+
+	tests := []struct {
+		path string
+		ok   bool
+	}{
+		{root, true},
+		{filepath.Join(root, "notes"), true},
+		{filepath.Join(root, "notes", "missing", "out"), true},
+		{filepath.Join(root, "notes", "..", ".."), false},
+		{filepath.Join(root, "escape"), false},
+		{filepath.Join(root, "escape", "missing"), false},
+		{"notes", false},
+		{outside, false},
+	}
+	for _, test := range tests {
+		if _, ok := ResolveBelow(test.path, []string{"", "relative", root}); ok != test.ok {
+			t.Errorf("ResolveBelow(%s) = %v, expected %v", test.path, ok, test.ok)
+		}
+	}
+}
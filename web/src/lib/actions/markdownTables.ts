@@ -0,0 +1,96 @@
+import { toCsv } from '$lib/utils/spreadsheet';
+
+// narrowest a column is dragged to, in pixels
+const MIN_COLUMN_WIDTH = 40;
+
+// The text of the cells of the table, the header row first
+export function tableRows(table: HTMLTableElement): string[][] {
+  return Array.from(table.rows).map(row => Array.from(row.cells).map(cell => (cell.textContent ?? '').trim()));
+}
+
+// Turns the tables of the rendered markdown into table widgets: they scroll sideways instead of
+// overflowing, their columns are resized by dragging the edge of their header, a double-click fitting
+// them again, and they are copied as CSV. content is the output, the tables are looked for again when
+// it changes.
+export function markdownTables(node: HTMLElement, content: string) {
+  function enhance() {
+    node.querySelectorAll<HTMLTableElement>('table:not([data-table-widget])').forEach(table => {
+      table.dataset.tableWidget = 'true';
+
+      const wrapper = document.createElement('div');
+      wrapper.className = 'not-prose my-3 overflow-x-auto rounded-md border border-white/10';
+      table.replaceWith(wrapper);
+
+      const toolbar = document.createElement('div');
+      toolbar.className = 'flex justify-end border-b border-white/10 px-2 py-0.5 text-xs text-muted-foreground';
+      const copy = document.createElement('button');
+      copy.type = 'button';
+      copy.className = 'rounded px-1.5 py-0.5 hover:bg-primary-500/20';
+      copy.textContent = 'Copy as CSV';
+      copy.addEventListener('click', async () => {
+        try {
+          await navigator.clipboard.writeText(toCsv(tableRows(table)));
+          copy.textContent = 'Copied';
+        } catch {
+          copy.textContent = 'Copy failed';
+        }
+        setTimeout(() => (copy.textContent = 'Copy as CSV'), 1500);
+      });
+      toolbar.appendChild(copy);
+
+      table.className = 'w-full border-collapse text-sm';
+      table.querySelectorAll<HTMLElement>('th, td').forEach(cell => {
+        cell.classList.add('border-b', 'border-white/10', 'px-2', 'py-1', 'text-left', 'align-top');
+      });
+      table.querySelectorAll<HTMLElement>('thead th').forEach(header => makeResizable(table, header));
+
+      wrapper.append(toolbar, table);
+    });
+  }
+
+  enhance();
+  return {
+    update(value: string) {
+      content = value;
+      // the markdown is rendered again after the update
+      queueMicrotask(enhance);
+    }
+  };
+}
+
+function makeResizable(table: HTMLTableElement, header: HTMLElement) {
+  header.classList.add('relative', 'font-semibold');
+  const handle = document.createElement('span');
+  handle.className = 'absolute right-0 top-0 h-full w-1.5 cursor-col-resize select-none hover:bg-primary-500/40';
+  handle.title = 'Drag to resize the column, double-click to fit it';
+  handle.setAttribute('aria-hidden', 'true');
+
+  handle.addEventListener('mousedown', start => {
+    start.preventDefault();
+    // the widths of all the columns are fixed once one is resized, so the others keep theirs
+    if (table.style.tableLayout !== 'fixed') {
+      table.querySelectorAll<HTMLElement>('thead th').forEach(th => (th.style.width = `${th.offsetWidth}px`));
+      table.style.tableLayout = 'fixed';
+      table.style.width = 'max-content';
+    }
+    const startX = start.clientX;
+    const startWidth = header.offsetWidth;
+    const move = (event: MouseEvent) => {
+      header.style.width = `${Math.max(MIN_COLUMN_WIDTH, startWidth + event.clientX - startX)}px`;
+    };
+    const stop = () => {
+      document.removeEventListener('mousemove', move);
+      document.removeEventListener('mouseup', stop);
+    };
+    document.addEventListener('mousemove', move);
+    document.addEventListener('mouseup', stop);
+  });
+
+  handle.addEventListener('dblclick', () => {
+    table.querySelectorAll<HTMLElement>('thead th').forEach(th => (th.style.width = ''));
+    table.style.tableLayout = '';
+    table.style.width = '';
+  });
+
+  header.appendChild(handle);
+}
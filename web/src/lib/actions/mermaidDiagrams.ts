@@ -0,0 +1,48 @@
+import { mermaidAPI } from '$lib/api/mermaid';
+
+// Enabled once the output stopped streaming, content is the output, the blocks are looked for again when
+// it changes
+export interface MermaidOptions {
+  enabled: boolean;
+  content: string;
+}
+
+// Replaces the mermaid code blocks of the rendered markdown with their diagrams. The blocks the server
+// can't render stay as text, the reason in their title.
+export function mermaidDiagrams(node: HTMLElement, options: MermaidOptions) {
+  let enabled = options.enabled;
+  function render() {
+    if (!enabled) return;
+    node.querySelectorAll<HTMLElement>('pre > code.language-mermaid').forEach(code => {
+      const pre = code.parentElement as HTMLElement;
+      if (pre.dataset.mermaid) return;
+      pre.dataset.mermaid = 'rendering';
+      mermaidAPI
+        .renderSvg(code.textContent ?? '')
+        .then(svg => {
+          // as an image, the scripts and links of the diagram never run
+          const image = document.createElement('img');
+          image.src = `data:image/svg+xml;charset=utf-8,${encodeURIComponent(svg)}`;
+          image.alt = code.textContent ?? 'mermaid diagram';
+          image.className = 'mermaid-diagram';
+          image.title = 'Double-click to show the source';
+          image.addEventListener('dblclick', () => image.replaceWith(pre));
+          pre.dataset.mermaid = 'rendered';
+          pre.replaceWith(image);
+        })
+        .catch(error => {
+          pre.dataset.mermaid = 'failed';
+          pre.title = `The diagram could not be rendered: ${error instanceof Error ? error.message : error}`;
+        });
+    });
+  }
+
+  render();
+  return {
+    update(value: MermaidOptions) {
+      enabled = value.enabled;
+      // the markdown is rendered again after the update
+      queueMicrotask(render);
+    }
+  };
+}
@@ -0,0 +1,35 @@
+import { api } from './base';
+
+export interface BackupSettings {
+  enabled: boolean;
+  directory: string;
+  schedule: string; // five field cron expression
+}
+
+export const backupAPI = {
+  // Returns the path of the created archive
+  async backupNow(directory?: string): Promise<string> {
+    const response = await api.post<{ path: string }>('/backup', { directory });
+    if (response.error) throw new Error(response.error);
+    return response.data?.path || '';
+  },
+
+  // The archive is verified by the server before anything is replaced. Returns the files of the archive
+  // kept as they are, the hooks file is only restored with fabric --restore
+  async restore(path: string): Promise<string[]> {
+    const response = await api.post<{ skipped: string[] | null }>('/backup/restore', { path });
+    if (response.error) throw new Error(response.error);
+    return response.data?.skipped ?? [];
+  },
+
+  async getSettings(): Promise<BackupSettings> {
+    const response = await api.get<BackupSettings>('/backup/settings');
+    if (response.error) throw new Error(response.error);
+    return response.data as BackupSettings;
+  },
+
+  async saveSettings(settings: BackupSettings): Promise<void> {
+    const response = await api.put('/backup/settings', settings);
+    if (response.error) throw new Error(response.error);
+  }
+}
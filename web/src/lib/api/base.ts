@@ -1,5 +1,9 @@
+import { version } from '$app/environment';
 import type { StorageEntity } from '$lib/interfaces/storage-interface';
 
+// Names the web UI and its build as the client of the runs, recorded in the run environment
+export const appHeaders = { 'X-Fabric-App': `web ${version}` };
+
 interface APIErrorResponse {
   error: string;
 }
@@ -16,6 +20,7 @@ export const api = {
       ...options,
       headers: {
         'Content-Type': 'application/json',
+        ...appHeaders,
         ...options.headers,
       },
     });
@@ -35,7 +40,7 @@ export const api = {
   stream: async function* (endpoint: string, data: unknown): AsyncGenerator<string> {
     const response = await fetch(`/api${endpoint}`, {
       method: 'POST',
-      headers: { 'Content-Type': 'application/json' },
+      headers: { 'Content-Type': 'application/json', ...appHeaders },
       body: JSON.stringify(data),
     });
 
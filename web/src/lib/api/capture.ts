@@ -0,0 +1,15 @@
+import { api } from './base';
+
+export interface Capture {
+  url: string;
+  title: string;
+  content: string;
+}
+
+export const captureAPI = {
+  // Captures posted by the bookmarklet can be fetched only once
+  async get(id: string): Promise<Capture | undefined> {
+    const response = await api.get<Capture>(`/capture/${encodeURIComponent(id)}`);
+    return response.data;
+  }
+}
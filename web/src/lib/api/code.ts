@@ -0,0 +1,25 @@
+import { api } from './base';
+
+// Globs use the .gitignore syntax: "*.go" matches at any depth, "src/**/*.ts" below src only
+export interface CorpusRequest {
+  root: string;
+  include: string[];
+  exclude: string[];
+  maxTokens: number;
+}
+
+export interface Corpus {
+  content: string; // the file tree followed by the files under their paths
+  files: string[];
+  omitted: string[]; // selected but over the token budget
+  tokens: number;
+}
+
+export const codeAPI = {
+  // Concatenates the matching files of the repository on the server within the token budget
+  async buildCorpus(request: CorpusRequest): Promise<Corpus> {
+    const response = await api.post<Corpus>('/code/corpus', request);
+    if (response.error) throw new Error(response.error);
+    return response.data as Corpus;
+  }
+};
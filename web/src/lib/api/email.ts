@@ -0,0 +1,30 @@
+export interface EmailMessage {
+  from: string;
+  to?: string;
+  cc?: string;
+  subject: string;
+  date?: string;
+  body: string; // without quoted history and signature
+  attachments?: string[];
+}
+
+export interface EmailResult {
+  messages: EmailMessage[];
+  markdown: string; // the messages rendered to send to a pattern
+}
+
+export const emailAPI = {
+  // Parses an .eml file or the latest messages of an .mbox mailbox on the server
+  async parse(file: File, messages?: number): Promise<EmailResult> {
+    const form = new FormData();
+    form.append('file', file, file.name);
+    if (messages) form.append('messages', String(messages));
+
+    const response = await fetch('/api/email', { method: 'POST', body: form });
+    const body = await response.json();
+    if (!response.ok) {
+      throw new Error(body.error || response.statusText);
+    }
+    return body as EmailResult;
+  }
+};
@@ -0,0 +1,75 @@
+import { api, appHeaders } from './base';
+
+export type ExportFormat = 'markdown' | 'html' | 'docx' | 'pdf';
+
+export interface ExportRequest {
+  content: string;
+  style?: string; // defaults to minimal
+  title?: string;
+  pattern?: string;
+  model?: string;
+  provenance?: boolean; // embeds the fabric version, pattern, model and date
+  notes?: string; // added after the output in a Notes section
+}
+
+export const exportAPI = {
+  // The built-in styles and the custom ones of the config dir
+  async listStyles(): Promise<{ styles: string[]; default: string }> {
+    const response = await api.get<{ styles: string[]; default: string }>('/export/styles');
+    if (response.error) throw new Error(response.error);
+    return response.data ?? { styles: [], default: 'minimal' };
+  },
+
+  async render(request: ExportRequest, format: 'markdown' | 'html'): Promise<string> {
+    const response = await api.post<{ content: string }>('/export', { ...request, format });
+    if (response.error) throw new Error(response.error);
+    return response.data?.content ?? '';
+  },
+
+  // The Word document of the output, the styles don't apply to it
+  async renderDocx(request: ExportRequest): Promise<Blob> {
+    const response = await fetch('/api/export', {
+      method: 'POST',
+      headers: { 'Content-Type': 'application/json', ...appHeaders },
+      body: JSON.stringify({ ...request, format: 'docx' })
+    });
+    if (!response.ok) {
+      const body = await response.json().catch(() => ({}));
+      throw new Error(body.error || response.statusText);
+    }
+    return response.blob();
+  },
+
+  // Downloads the export, PDF is the HTML export printed by the browser
+  async download(request: ExportRequest, format: ExportFormat, baseName: string): Promise<void> {
+    if (format === 'pdf') {
+      const html = await exportAPI.render(request, 'html');
+      const printWindow = window.open('', '_blank');
+      if (!printWindow) throw new Error('Allow pop-ups to export to PDF');
+      printWindow.document.write(html);
+      printWindow.document.close();
+      // give the new window a moment to lay out the document before opening the print dialog
+      setTimeout(() => {
+        printWindow.focus();
+        printWindow.print();
+      }, 250);
+      return;
+    }
+
+    let blob: Blob;
+    if (format === 'docx') {
+      blob = await exportAPI.renderDocx(request);
+    } else {
+      const content = await exportAPI.render(request, format);
+      blob = new Blob([content], { type: format === 'html' ? 'text/html' : 'text/markdown' });
+    }
+    const url = URL.createObjectURL(blob);
+    const a = document.createElement('a');
+    a.href = url;
+    a.download = `${baseName}.${{ markdown: 'md', html: 'html', docx: 'docx' }[format]}`;
+    document.body.appendChild(a);
+    a.click();
+    document.body.removeChild(a);
+    URL.revokeObjectURL(url);
+  }
+};
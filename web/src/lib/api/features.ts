@@ -0,0 +1,24 @@
+import { api } from './base';
+
+export interface FeatureState {
+  name: string;
+  description: string;
+  default: boolean;
+  restart: boolean; // applied by a running fabric --serve on restart only
+  enabled: boolean;
+}
+
+export const featuresAPI = {
+  // The experimental subsystems gated by the features file of the server
+  async list(): Promise<FeatureState[]> {
+    const response = await api.get<FeatureState[]>('/features');
+    if (response.error) throw new Error(response.error);
+    return response.data ?? [];
+  },
+
+  async save(flags: Record<string, boolean>): Promise<FeatureState[]> {
+    const response = await api.put<FeatureState[]>('/features', flags);
+    if (response.error) throw new Error(response.error);
+    return response.data ?? [];
+  }
+};
@@ -0,0 +1,23 @@
+import { api } from './base';
+
+export interface FeedEntry {
+  title: string;
+  link?: string;
+  author?: string;
+  published?: string;
+  content: string;
+}
+
+export interface FeedResult {
+  title: string;
+  entries: FeedEntry[];
+  markdown: string; // the entries rendered to send to a pattern
+}
+
+export const feedAPI = {
+  async fetch(url: string, entries: number): Promise<FeedResult> {
+    const response = await api.post<FeedResult>('/feed', { url, entries });
+    if (response.error) throw new Error(response.error);
+    return response.data ?? { title: '', entries: [], markdown: '' };
+  }
+};
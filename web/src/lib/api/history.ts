@@ -0,0 +1,175 @@
+import { api } from './base';
+import type { ExecutionMetadata } from '$lib/interfaces/chat-interface';
+
+export interface CalendarDay {
+  date: string; // YYYY-MM-DD
+  runs: number;
+  scheduled?: string[]; // jobs scheduled on the day
+}
+
+export interface Run {
+  id: string;
+  timestamp: string;
+  jobName?: string;
+  patternName?: string;
+  contextName?: string;
+  sessionName?: string;
+  strategyName?: string;
+  variables?: { [key: string]: string };
+  language?: string;
+  input: string;
+  output: string;
+  error?: string;
+  metadata?: ExecutionMetadata;
+  notes?: string; // written by the user, why the output is good or bad
+}
+
+// Selects the runs of the output history, the empty fields select every run
+export interface RunFilter {
+  q?: string; // in the pattern, job, input, output or notes
+  pattern?: string;
+  model?: string;
+  from?: string; // YYYY-MM-DD, inclusive
+  to?: string; // YYYY-MM-DD, inclusive
+  limit?: number;
+  offset?: number;
+}
+
+export interface RunFacets {
+  patterns: string[];
+  models: string[];
+}
+
+// The failed runs ended with an error or were cut at the token limit
+export interface PatternRunStats {
+  runs: number;
+  failed: number;
+}
+
+// How often and when a pattern was run, from the run history
+export interface PatternUsage {
+  runs: number;
+  lastRun: string;
+}
+
+export interface ReplayResult {
+  output: string;
+  metadata?: ExecutionMetadata;
+}
+
+export interface DiffLine {
+  op: 'equal' | 'delete' | 'insert';
+  text: string;
+  oldLine?: number;
+  newLine?: number;
+}
+
+// A sentence of run a aligned with its closest sentence of run b, a or b is missing without counterpart
+export interface SentencePair {
+  a?: string;
+  b?: string;
+  similarity: number; // 0 nothing in common to 1 the same words
+}
+
+export interface RunDiff {
+  a: Run;
+  b: Run;
+  lines: DiffLine[];
+  unified: string;
+  sentences: SentencePair[];
+}
+
+export const historyAPI = {
+  async getCalendar(from?: string, to?: string): Promise<CalendarDay[]> {
+    const params = new URLSearchParams();
+    if (from) params.set('from', from);
+    if (to) params.set('to', to);
+    const response = await api.get<CalendarDay[]>(`/history/calendar?${params}`);
+    return response.data || [];
+  },
+
+  async getDay(date: string): Promise<Run[]> {
+    const response = await api.get<Run[]>(`/history/days/${date}`);
+    return response.data || [];
+  },
+
+  async getRun(id: string): Promise<Run | undefined> {
+    const response = await api.get<Run>(`/history/runs/${encodeURIComponent(id)}`);
+    return response.data;
+  },
+
+  // Sets the notes of the run, empty notes remove them
+  async setNotes(id: string, notes: string): Promise<Run> {
+    const response = await api.put<Run>(`/history/runs/${encodeURIComponent(id)}/notes`, { notes });
+    if (response.error || !response.data) throw new Error(response.error || 'Failed to save the notes');
+    return response.data;
+  },
+
+  // The latest runs with the query in their pattern, job, input, output or notes
+  async search(query: string, limit = 20): Promise<Run[]> {
+    const params = new URLSearchParams({ q: query, limit: String(limit) });
+    const response = await api.get<Run[]>(`/history/search?${params}`);
+    if (response.error) throw new Error(response.error);
+    return response.data || [];
+  },
+
+  // The runs of the filter, newest first
+  async listRuns(filter: RunFilter): Promise<Run[]> {
+    const params = new URLSearchParams();
+    for (const [key, value] of Object.entries(filter)) {
+      if (value !== undefined && value !== '') params.set(key, String(value));
+    }
+    const response = await api.get<Run[]>(`/history/runs?${params}`);
+    if (response.error) throw new Error(response.error);
+    return response.data || [];
+  },
+
+  // The patterns and models the runs can be filtered by
+  async getFacets(): Promise<RunFacets> {
+    const response = await api.get<RunFacets>('/history/facets');
+    if (response.error) throw new Error(response.error);
+    return response.data ?? { patterns: [], models: [] };
+  },
+
+  // The median latency in milliseconds of the successful runs of the last days, by model
+  async getLatency(days?: number): Promise<Record<string, number>> {
+    const params = new URLSearchParams();
+    if (days) params.set('days', String(days));
+    const response = await api.get<Record<string, number>>(`/history/latency?${params}`);
+    if (response.error) throw new Error(response.error);
+    return response.data || {};
+  },
+
+  // The runs of the pattern in the last days and how many failed, by model
+  async getPatternStats(pattern: string, days?: number): Promise<Record<string, PatternRunStats>> {
+    const params = new URLSearchParams();
+    if (days) params.set('days', String(days));
+    const response = await api.get<Record<string, PatternRunStats>>(
+      `/history/patterns/${encodeURIComponent(pattern)}/stats?${params}`
+    );
+    if (response.error) throw new Error(response.error);
+    return response.data || {};
+  },
+
+  // How often and when each pattern was last run, by pattern
+  async getPatternUsage(): Promise<Record<string, PatternUsage>> {
+    const response = await api.get<Record<string, PatternUsage>>('/history/usage');
+    if (response.error) throw new Error(response.error);
+    return response.data || {};
+  },
+
+  // Diffs the output of run a to the output of run b
+  async diff(a: string, b: string): Promise<RunDiff> {
+    const params = new URLSearchParams({ a, b });
+    const response = await api.get<RunDiff>(`/history/diff?${params}`);
+    if (response.error) throw new Error(response.error);
+    return response.data as RunDiff;
+  },
+
+  // Runs the run again with the same model, seed and sampling parameters
+  async replay(id: string): Promise<ReplayResult> {
+    const response = await api.post<ReplayResult>(`/history/runs/${encodeURIComponent(id)}/replay`, {});
+    if (response.error) throw new Error(response.error);
+    return response.data as ReplayResult;
+  }
+}
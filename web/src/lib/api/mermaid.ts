@@ -0,0 +1,21 @@
+import { api } from './base';
+
+// the diagrams rendered or being rendered, by source, the outputs are rendered again as the view updates
+const rendered = new Map<string, Promise<string>>();
+
+export const mermaidAPI = {
+  // The SVG of the diagram, rendered by the mermaid CLI of the server
+  renderSvg(source: string): Promise<string> {
+    let svg = rendered.get(source);
+    if (!svg) {
+      svg = api.post<{ svg: string }>('/mermaid', { source }).then(response => {
+        if (response.error) throw new Error(response.error);
+        return response.data?.svg ?? '';
+      });
+      // the failures are retried, the CLI may have been installed since
+      svg.catch(() => rendered.delete(source));
+      rendered.set(source, svg);
+    }
+    return svg;
+  }
+};
@@ -1,5 +1,5 @@
 import { api } from './base';
-import type { VendorModel, ModelsResponse } from '$lib/interfaces/model-interface';
+import type { VendorModel, ModelsResponse, VendorQuota, VendorHealth } from '$lib/interfaces/model-interface';
 
 export const modelsApi = {
   async getAvailable(): Promise<VendorModel[]> {
@@ -21,4 +21,16 @@ export const modelsApi = {
       throw error;
     }
   },
+
+  async getQuotas(): Promise<VendorQuota[]> {
+    const response = await api.get<VendorQuota[]>('/vendors/quota');
+    if (response.error) throw new Error(response.error);
+    return response.data ?? [];
+  },
+
+  async getHealth(): Promise<VendorHealth[]> {
+    const response = await api.get<VendorHealth[]>('/vendors/health');
+    if (response.error) throw new Error(response.error);
+    return response.data ?? [];
+  },
 };
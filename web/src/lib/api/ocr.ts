@@ -0,0 +1,15 @@
+export const ocrAPI = {
+  // Recognizes the text of the image with Tesseract on the server, languages like "eng+deu"
+  async recognize(image: Blob, languages?: string): Promise<string> {
+    const form = new FormData();
+    form.append('file', image, 'screenshot.png');
+    if (languages) form.append('languages', languages);
+
+    const response = await fetch('/api/ocr', { method: 'POST', body: form });
+    const body = await response.json();
+    if (!response.ok) {
+      throw new Error(body.error || response.statusText);
+    }
+    return (body as { text: string }).text;
+  }
+};
@@ -0,0 +1,23 @@
+import { api } from './base';
+
+export interface PrecheckIssue {
+  kind: string;
+  line: number;
+  column: number;
+  text: string;
+  suggestion: string;
+}
+
+export interface PrecheckResult {
+  writing: boolean; // only the input of writing patterns is checked
+  issues: PrecheckIssue[];
+  instructions: string; // appended to the input to have the model fix the issues
+}
+
+export const precheckAPI = {
+  async check(text: string, pattern: string): Promise<PrecheckResult> {
+    const response = await api.post<PrecheckResult>('/precheck', { text, pattern });
+    if (response.error) throw new Error(response.error);
+    return response.data ?? { writing: false, issues: [], instructions: '' };
+  }
+};
@@ -0,0 +1,43 @@
+import { api } from './base';
+
+// A run proposed by another tool, it waits for approval
+export interface Proposal {
+  id: string;
+  source?: string;
+  reason?: string;
+  created: string;
+  run: {
+    patternName: string;
+    model?: string;
+    vendor?: string;
+    contextName?: string;
+    strategyName?: string;
+    variables?: Record<string, string>;
+    language?: string;
+    inputUrl?: string;
+    inputFile?: string;
+    input?: string;
+  };
+}
+
+export const proposalsAPI = {
+  async list(): Promise<Proposal[]> {
+    const response = await api.get<Proposal[]>('/proposals');
+    if (response.error) throw new Error(response.error);
+    return response.data ?? [];
+  },
+
+  // Runs the proposal in the background, the result is recorded in the run history
+  async approve(id: string): Promise<void> {
+    const response = await api.post(`/proposals/${id}/approve`, {});
+    if (response.error) throw new Error(response.error);
+  },
+
+  async reject(id: string): Promise<void> {
+    const response = await fetch(`/api/proposals/${id}`, { method: 'DELETE' });
+    if (!response.ok) {
+      const body = await response.json().catch(() => ({}));
+      throw new Error(body.error || response.statusText);
+    }
+  }
+};
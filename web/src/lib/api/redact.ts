@@ -0,0 +1,22 @@
+import { api } from './base';
+
+export interface RedactMatch {
+  rule: string;
+  line: number;
+  preview: string; // the start of the secret only
+  placeholder: string;
+}
+
+export interface RedactResult {
+  text: string; // with the secrets replaced by placeholders like [REDACTED:email]
+  matches: RedactMatch[];
+}
+
+export const redactAPI = {
+  // Rules are the built-in rules, all when empty, patterns the custom regexes
+  async redact(text: string, rules: string[], patterns: string[]): Promise<RedactResult> {
+    const response = await api.post<RedactResult>('/redact', { text, rules, patterns });
+    if (response.error) throw new Error(response.error);
+    return response.data ?? { text, matches: [] };
+  }
+};
@@ -0,0 +1,43 @@
+import { api, appHeaders } from './base';
+import type { ExecutionMetadata } from '$lib/interfaces/chat-interface';
+
+// A result shared with teammates: the input, the output and the execution metadata of the run
+export interface ShareRequest {
+  input?: string;
+  output: string;
+  pattern?: string;
+  model?: string;
+  metadata?: ExecutionMetadata;
+  token?: string; // GitHub token creating the gist, GITHUB_TOKEN of the server when not set
+}
+
+export const shareAPI = {
+  // Creates a private gist of the result, returns its URL
+  async createGist(request: ShareRequest): Promise<string> {
+    const response = await api.post<{ url: string }>('/share/gist', request);
+    if (response.error) throw new Error(response.error);
+    return response.data?.url ?? '';
+  },
+
+  // Downloads the zip bundle of the result
+  async downloadZip(request: ShareRequest): Promise<void> {
+    const response = await fetch('/api/share/zip', {
+      method: 'POST',
+      headers: { 'Content-Type': 'application/json', ...appHeaders },
+      body: JSON.stringify(request)
+    });
+    if (!response.ok) {
+      const body = await response.json().catch(() => ({}));
+      throw new Error(body.error || response.statusText);
+    }
+    const fileName = /filename="([^"]+)"/.exec(response.headers.get('Content-Disposition') ?? '')?.[1] ?? 'fabric-output.zip';
+    const url = URL.createObjectURL(await response.blob());
+    const a = document.createElement('a');
+    a.href = url;
+    a.download = fileName;
+    document.body.appendChild(a);
+    a.click();
+    document.body.removeChild(a);
+    URL.revokeObjectURL(url);
+  }
+};
@@ -0,0 +1,16 @@
+import type { Sheet } from '$lib/utils/spreadsheet';
+
+export const spreadsheetAPI = {
+  // Parses a CSV, TSV or XLSX file on the server into its sheets, the first row of each is the header
+  async parse(file: File): Promise<Sheet[]> {
+    const form = new FormData();
+    form.append('file', file, file.name);
+
+    const response = await fetch('/api/spreadsheet', { method: 'POST', body: form });
+    const body = await response.json();
+    if (!response.ok) {
+      throw new Error(body.error || response.statusText);
+    }
+    return (body as { sheets: Sheet[] }).sheets;
+  }
+};
@@ -0,0 +1,92 @@
+import { api } from './base';
+import type { Run } from './history';
+
+// A knowledge pack a starred output was imported from
+export interface Provenance {
+  pack: string;
+  author?: string;
+  imported: string;
+}
+
+// A run kept apart from the history with the notes of the user, provenance is empty when starred here
+export interface StarredOutput {
+  run: Run;
+  title?: string; // named by the user
+  annotation?: string;
+  starred: string;
+  provenance?: Provenance[];
+}
+
+export interface KnowledgePackRequest {
+  name: string;
+  author?: string;
+  description?: string;
+  runIds?: string[]; // all the starred outputs without ids
+}
+
+export interface ImportResult {
+  pack: { name: string; author?: string; description?: string; outputs: number };
+  imported: number;
+  skipped: number; // already starred, the local annotations are kept
+}
+
+export const starredAPI = {
+  async list(): Promise<StarredOutput[]> {
+    const response = await api.get<StarredOutput[]>('/starred');
+    if (response.error) throw new Error(response.error);
+    return response.data ?? [];
+  },
+
+  // Stars the run, or updates the annotation of a starred run
+  async star(runId: string, annotation = ''): Promise<StarredOutput> {
+    const response = await api.put<StarredOutput>(`/starred/${encodeURIComponent(runId)}`, { annotation });
+    if (response.error) throw new Error(response.error);
+    return response.data as StarredOutput;
+  },
+
+  // Names the starred output, an empty title removes the name
+  async rename(runId: string, title: string): Promise<StarredOutput> {
+    const response = await api.put<StarredOutput>(`/starred/${encodeURIComponent(runId)}/title`, { title });
+    if (response.error) throw new Error(response.error);
+    return response.data as StarredOutput;
+  },
+
+  async unstar(runId: string): Promise<void> {
+    const response = await api.delete(`/starred/${encodeURIComponent(runId)}`);
+    if (response.error) throw new Error(response.error);
+  },
+
+  // Downloads the knowledge pack archive of the starred outputs
+  async exportPack(request: KnowledgePackRequest): Promise<void> {
+    const response = await fetch('/api/starred/export', {
+      method: 'POST',
+      headers: { 'Content-Type': 'application/json' },
+      body: JSON.stringify(request)
+    });
+    if (!response.ok) {
+      const body = await response.json().catch(() => ({}));
+      throw new Error(body.error || response.statusText);
+    }
+    const fileName = /filename="([^"]+)"/.exec(response.headers.get('Content-Disposition') ?? '')?.[1]
+      ?? `${request.name}.fabricpack.tar.gz`;
+    const url = URL.createObjectURL(await response.blob());
+    const a = document.createElement('a');
+    a.href = url;
+    a.download = fileName;
+    document.body.appendChild(a);
+    a.click();
+    document.body.removeChild(a);
+    URL.revokeObjectURL(url);
+  },
+
+  async importPack(file: File): Promise<ImportResult> {
+    const form = new FormData();
+    form.append('file', file);
+    const response = await fetch('/api/starred/import', { method: 'POST', body: form });
+    const body = await response.json();
+    if (!response.ok) {
+      throw new Error(body.error || response.statusText);
+    }
+    return body as ImportResult;
+  }
+};
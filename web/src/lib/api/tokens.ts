@@ -0,0 +1,15 @@
+import { api } from './base';
+
+export interface TokenCounts {
+  tokenizer: string; // the command of the model in tokenizers.yaml, empty without one
+  counts?: number[];
+}
+
+export const tokensAPI = {
+  // Counts the tokens of the texts with the tokenizer of the model, undefined when the model has none
+  async count(model: string, texts: string[]): Promise<number[] | undefined> {
+    const response = await api.post<TokenCounts>('/tokens/count', { model, texts });
+    if (response.error) throw new Error(response.error);
+    return response.data?.tokenizer ? response.data.counts ?? [] : undefined;
+  }
+};
@@ -0,0 +1,18 @@
+// Speech-to-text backend, empty for the first configured vendor that supports transcription
+export type TranscribeBackend = '' | 'OpenAI' | 'whisper.cpp';
+
+export const transcribeAPI = {
+  async transcribe(file: File, backend: TranscribeBackend, language?: string): Promise<string> {
+    const form = new FormData();
+    form.append('file', file);
+    if (backend) form.append('vendor', backend);
+    if (language) form.append('language', language);
+
+    const response = await fetch('/api/transcribe', { method: 'POST', body: form });
+    const body = await response.json();
+    if (!response.ok) {
+      throw new Error(body.error || response.statusText);
+    }
+    return (body as { transcript: string }).transcript;
+  }
+};
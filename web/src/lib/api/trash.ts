@@ -0,0 +1,54 @@
+import { api } from './base';
+import type { Run } from './history';
+
+// A deleted pattern or run, kept until it is restored or purged
+export interface TrashedItem {
+  id: string;
+  kind: 'pattern' | 'run';
+  name: string; // of the pattern, the pattern of the run
+  path?: string; // the directory the pattern is restored to
+  deleted: string;
+  run?: Run;
+}
+
+export interface TrashSettings {
+  retentionDays: number; // 0 keeps the items forever
+}
+
+export const trashAPI = {
+  // The expired items are purged by the server before listing
+  async list(): Promise<TrashedItem[]> {
+    const response = await api.get<TrashedItem[]>('/trash');
+    if (response.error) throw new Error(response.error);
+    return response.data ?? [];
+  },
+
+  async trashRun(runId: string): Promise<TrashedItem> {
+    const response = await api.delete<TrashedItem>(`/history/runs/${encodeURIComponent(runId)}`);
+    if (response.error) throw new Error(response.error);
+    return response.data as TrashedItem;
+  },
+
+  async restore(id: string): Promise<TrashedItem> {
+    const response = await api.post<TrashedItem>(`/trash/${encodeURIComponent(id)}/restore`, {});
+    if (response.error) throw new Error(response.error);
+    return response.data as TrashedItem;
+  },
+
+  // Deletes the item for good
+  async purge(id: string): Promise<void> {
+    const response = await api.delete(`/trash/${encodeURIComponent(id)}`);
+    if (response.error) throw new Error(response.error);
+  },
+
+  async getSettings(): Promise<TrashSettings> {
+    const response = await api.get<TrashSettings>('/trash/settings');
+    if (response.error) throw new Error(response.error);
+    return response.data as TrashSettings;
+  },
+
+  async saveSettings(settings: TrashSettings): Promise<void> {
+    const response = await api.put('/trash/settings', settings);
+    if (response.error) throw new Error(response.error);
+  }
+};
@@ -0,0 +1,25 @@
+import { api } from './base';
+
+// A value kept across runs, filled in the patterns and inputs by {{var:name}}
+export interface StoredVariable {
+  name: string;
+  value: string;
+}
+
+export const variablesAPI = {
+  async list(): Promise<StoredVariable[]> {
+    const response = await api.get<StoredVariable[]>('/variables');
+    if (response.error) throw new Error(response.error);
+    return response.data ?? [];
+  },
+
+  async set(name: string, value: string): Promise<void> {
+    const response = await api.put(`/variables/${encodeURIComponent(name)}`, { value });
+    if (response.error) throw new Error(response.error);
+  },
+
+  async delete(name: string): Promise<void> {
+    const response = await api.delete(`/variables/${encodeURIComponent(name)}`);
+    if (response.error) throw new Error(response.error);
+  }
+};
@@ -0,0 +1,54 @@
+import { api } from './base';
+
+// A directory whose new and changed files are run through a pattern, the outputs written to outputDirectory
+export interface Watch {
+  name: string;
+  directory: string;
+  outputDirectory: string;
+  extensions?: string[]; // all files when empty
+  enabled: boolean;
+  patternName: string;
+  model?: string;
+  vendor?: string;
+  contextName?: string;
+  strategyName?: string;
+  variables?: Record<string, string>;
+  language?: string;
+}
+
+export type WatchEventStatus = 'running' | 'done' | 'failed';
+
+export interface WatchEvent {
+  time: string;
+  watch: string;
+  file: string;
+  output?: string;
+  status: WatchEventStatus;
+  error?: string;
+}
+
+export const watchesAPI = {
+  async list(): Promise<Watch[]> {
+    const response = await api.get<Watch[]>('/watches');
+    if (response.error) throw new Error(response.error);
+    return response.data ?? [];
+  },
+
+  async save(watch: Watch): Promise<Watch> {
+    const response = await api.post<Watch>(`/watches/${encodeURIComponent(watch.name)}`, watch);
+    if (response.error) throw new Error(response.error);
+    return response.data ?? watch;
+  },
+
+  async remove(name: string): Promise<void> {
+    const response = await api.delete(`/watches/${encodeURIComponent(name)}`);
+    if (response.error) throw new Error(response.error);
+  },
+
+  // The latest processed files, the latest first
+  async activity(): Promise<WatchEvent[]> {
+    const response = await api.get<WatchEvent[]>('/watches/activity');
+    if (response.error) throw new Error(response.error);
+    return response.data ?? [];
+  }
+};
@@ -0,0 +1,66 @@
+import { api, appHeaders } from './base';
+
+export interface YouTubeVideo {
+  id: string;
+  title: string;
+}
+
+export interface YouTubeBatchRequest {
+  videos: YouTubeVideo[];
+  patternName: string;
+  model: string;
+  vendor?: string;
+  variables?: Record<string, string>;
+  language: string;
+  timestamps: boolean;
+  aggregate: boolean; // also combine the outputs into one document
+  ignoreQuota?: boolean; // run even though the vendor is near its quota cap
+}
+
+export interface YouTubeBatchResult extends YouTubeVideo {
+  output?: string;
+  error?: string;
+}
+
+export interface YouTubeBatchResponse {
+  results: YouTubeBatchResult[];
+  document?: string;
+}
+
+export const youtubeAPI = {
+  // Lists the videos of a playlist or channel URL, a channel lists its uploads
+  async listVideos(url: string): Promise<YouTubeVideo[]> {
+    const response = await api.post<{ playlistId: string; videos: YouTubeVideo[] }>('/youtube/videos', { url });
+    if (response.error) throw new Error(response.error);
+    return response.data?.videos ?? [];
+  },
+
+  // Runs the pattern over the transcript of every video, the failures are reported per video
+  async runBatch(request: YouTubeBatchRequest): Promise<YouTubeBatchResponse> {
+    const response = await api.post<YouTubeBatchResponse>('/youtube/batch', request);
+    if (response.error) throw new Error(response.error);
+    return response.data as YouTubeBatchResponse;
+  },
+
+  // Downloads a zip of the outputs of the batch, a markdown file per video
+  async downloadFiles(patternName: string, results: YouTubeBatchResult[]): Promise<void> {
+    const response = await fetch('/api/youtube/batch/files', {
+      method: 'POST',
+      headers: { 'Content-Type': 'application/json', ...appHeaders },
+      body: JSON.stringify({ patternName, results })
+    });
+    if (!response.ok) {
+      const body = await response.json().catch(() => ({}));
+      throw new Error(body.error || response.statusText);
+    }
+    const fileName = /filename="([^"]+)"/.exec(response.headers.get('Content-Disposition') ?? '')?.[1] ?? 'fabric-youtube.zip';
+    const url = URL.createObjectURL(await response.blob());
+    const a = document.createElement('a');
+    a.href = url;
+    a.download = fileName;
+    document.body.appendChild(a);
+    a.click();
+    document.body.removeChild(a);
+    URL.revokeObjectURL(url);
+  }
+};
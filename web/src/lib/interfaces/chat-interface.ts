@@ -1,6 +1,6 @@
 export type MessageRole = 'system' | 'user' | 'assistant';
-export type ResponseFormat = 'markdown' | 'mermaid' | 'plain' | 'loading';
-export type ResponseType = 'content' | 'error' | 'complete';
+export type ResponseFormat = 'markdown' | 'mermaid' | 'plain' | 'json' | 'loading';
+export type ResponseType = 'chunk' | 'content' | 'error' | 'stall' | 'complete';
 
 export interface ChatPrompt {
   userInput: string;
@@ -8,7 +8,10 @@ export interface ChatPrompt {
   model: string;
   patternName?: string;
   strategyName?: string; // Optional strategy name to prepend strategy prompt
+  contextName?: string; // Optional context prepended to the system prompt
   variables?: { [key: string]: string }; // Pattern variables
+  images?: string[]; // data URLs of the images sent to vision models with the input
+  sessionName?: string; // the session continued, the server adds the conversation so far
 }
 
 export interface ChatConfig {
@@ -16,6 +19,9 @@ export interface ChatConfig {
   top_p: number;
   frequency_penalty: number;
   presence_penalty: number;
+  seed?: number; // Fixed seed for reproducible output, honored by vendors supporting it
+  maxTokens?: number; // Maximum number of tokens in the response, vendor default when unset
+  stopSequences?: string[]; // The model stops generating when it outputs one of these
 }
 
 export interface ChatRequest {
@@ -25,13 +31,50 @@ export interface ChatRequest {
   top_p: number;
   frequency_penalty: number;
   presence_penalty: number;
+  seed?: number;
+  maxTokens?: number;
+  stopSequences?: string[];
   language?: string;
 }
 
+export interface ExecutionMetadata {
+  model: string;
+  vendor: string;
+  strategy?: string;
+  latencyMs: number;
+  promptTokens?: number;
+  completionTokens?: number;
+  finishReason?: string;
+  seed?: number;
+  temperature?: number;
+  topP?: number;
+  presencePenalty?: number;
+  frequencyPenalty?: number;
+  maxTokens?: number;
+  stopSequences?: string[];
+  runDir?: string; // Working directory of the hook commands, while it is retained
+  runId?: string; // The run in the history, to star it
+  environment?: RunEnvironment;
+}
+
+// Configuration the run was executed with, the hashes are the SHA-256 of the files used
+export interface RunEnvironment {
+  fabricVersion?: string;
+  app?: string;
+  platform: string;
+  vendorEndpoint?: string;
+  patternHash?: string;
+  strategyHash?: string;
+  contextHash?: string;
+}
+
 export interface Message {
   role: MessageRole;
   content: string;
   format?: ResponseFormat;
+  metadata?: ExecutionMetadata; // Set on assistant messages once the run completes
+  reasoning?: string; // What a thinking model reasoned before answering, apart from the answer
+  toolCalls?: ToolCall[]; // The tools the model called while answering, like the web search of the vendor
 }
 
 export interface ChatState {
@@ -43,6 +86,30 @@ export interface StreamResponse {
   type: ResponseType;
   format: ResponseFormat;
   content: string;
+  metadata?: ExecutionMetadata;
+  stall?: StallInfo;
+  chunk?: StreamChunk; // Sent with "chunk" as the model answers, before the "content" of the whole answer
+}
+
+// An event of the response stream of the model, the same for every vendor
+export interface StreamChunk {
+  type: 'text' | 'reasoning' | 'tool_call';
+  text?: string;
+  toolCall?: ToolCall;
+}
+
+// A tool called by the model, arguments is the JSON it was called with
+export interface ToolCall {
+  id?: string;
+  name: string;
+  arguments?: string;
+}
+
+// A request without a response for the stall timeout of the server: "thinking" when the vendor is still
+// reachable, "dead" when it isn't, "unknown" when it couldn't be checked
+export interface StallInfo {
+  state: 'thinking' | 'dead' | 'unknown';
+  seconds: number;
 }
 
 export interface ChatError {
@@ -3,6 +3,26 @@ export interface VendorModel {
   vendor: string;
 }
 
+// Spend and remaining quota of the API key, for vendors reporting it
+export interface VendorQuota {
+  vendor: string;
+  currency?: string;
+  spent?: number;
+  limit?: number; // unset for keys without a spending cap
+  remaining?: number; // left of the limit, or the prepaid balance
+  nearCap: boolean;
+  error?: string;
+}
+
+// Circuit breaker state of a vendor, degraded after repeated failures
+export interface VendorHealth {
+  vendor: string;
+  status: 'healthy' | 'degraded';
+  failures: number;
+  lastError?: string;
+  retryAfter?: string;
+}
+
 export interface ModelsResponse {
   models: string[];
   vendors: Record<string, string[]>;
@@ -13,4 +13,29 @@ export interface Pattern extends StorageEntity {
   Description: string; // maps to description from JSON
   Pattern: string;     // pattern content from API
   tags: string[];      // array of tag strings
+  Meta?: PatternMeta;  // declared in the frontmatter of system.md or in meta.yaml
+  Source?: string;     // the patterns directory it is loaded from, the first of the custom ones having it
+}
+
+// Parameters a pattern declares, the Execute tab applies them when the pattern is selected
+export interface PatternMeta {
+  variables?: Record<string, string>; // defaults, applied by the server
+  model?: string;
+  temperature?: number;
+  format?: 'markdown' | 'mermaid' | 'plain' | 'json';
+  attachments?: string[]; // files of the pattern directory included in the prompt
+  requires?: ModelCapability[]; // the model must have them to run the pattern
+  minContext?: number; // context window in tokens the pattern needs
+  notes?: string; // compatibility notes, shown with the warnings
+  schema?: string; // JSON schema file of the pattern directory its JSON output is validated against
+}
+
+export type ModelCapability = 'tools' | 'vision' | 'json';
+
+// An extra file of a pattern directory, see GET /patterns/:name/attachments
+export interface PatternAttachment {
+  name: string; // relative to the pattern directory
+  size: number;
+  kind: 'text' | 'image' | 'other';
+  included: boolean; // listed in the attachments of the meta, included in the prompt
 }
\ No newline at end of file
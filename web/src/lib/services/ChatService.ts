@@ -2,15 +2,23 @@ import type {
   ChatRequest,
   StreamResponse,
   ChatError as IChatError,
-  ChatPrompt
+  ChatPrompt,
+  ExecutionMetadata,
+  StallInfo,
+  ToolCall
 } from '$lib/interfaces/chat-interface';
 import { get } from 'svelte/store';
 import { modelConfig } from '$lib/store/model-store';
-import { systemPrompt, selectedPatternName, patternVariables } from '$lib/store/pattern-store';
-import { chatConfig } from '$lib/store/chat-config';
-import { messageStore } from '$lib/store/chat-store';
+import { systemPrompt, selectedPatternName, patternVariables, patterns } from '$lib/store/pattern-store';
+import { chatConfig, speakOutput } from '$lib/store/chat-config';
+import { StreamSpeaker } from '$lib/services/speech-service';
+import { messageStore, currentSession } from '$lib/store/chat-store';
 import { languageStore } from '$lib/store/language-store';
 import { selectedStrategy } from '$lib/store/strategy-store';
+import { selectedContext } from '$lib/store/context-store';
+import { recordRun } from '$lib/store/stale-store';
+import { appHeaders } from '$lib/api/base';
+import { isJsonOutput } from '$lib/utils/json-schema';
 
 class LanguageValidator {
   constructor(private targetLanguage: string) {}
@@ -34,6 +42,8 @@ export class ChatError extends Error implements IChatError {
 
 export class ChatService {
   private validator: LanguageValidator;
+  // aborts the running request, to cancel or reconnect a stalled one
+  private controller: AbortController | null = null;
 
   constructor() {
     this.validator = new LanguageValidator(get(languageStore));
@@ -51,10 +61,12 @@ export class ChatService {
       // NEW: Log the full payload before sending to backend
       console.log('Final ChatRequest payload:', JSON.stringify(request, null, 2));
 
+      this.controller = new AbortController();
       const response = await fetch('/api/chat', {
         method: 'POST',
-        headers: { 'Content-Type': 'application/json' },
+        headers: { 'Content-Type': 'application/json', ...appHeaders },
         body: JSON.stringify(request),
+        signal: this.controller.signal
       });
 
       if (!response.ok) {
@@ -73,6 +85,12 @@ export class ChatService {
     }
   }
 
+  // Aborts the running request, its stream ends with an ABORTED error
+  public abort() {
+    this.controller?.abort();
+    this.controller = null;
+  }
+
   private cleanPatternOutput(content: string): string {
     // Remove markdown fence if present
     let cleaned = content.replace(/^```markdown\n/, '');
@@ -96,6 +114,9 @@ export class ChatService {
       const validator = new LanguageValidator(language);
 
       const processResponse = (response: StreamResponse) => {
+          // the pieces of the answer are cleaned up once it is whole
+          if (response.type === 'chunk') return response;
+
           const pattern = get(selectedPatternName);
 
           if (pattern) {
@@ -106,7 +127,9 @@ export class ChatService {
                   'sequenceDiagram', 'classDiagram', 'stateDiagram'
               ].some(starter => response.content.trim().startsWith(starter));
 
-              response.format = isMermaid ? 'mermaid' : 'markdown';
+              // the format the pattern declares wins over the guess
+              const declared = get(patterns).find(p => p.Name === pattern)?.Meta?.format;
+              response.format = declared || (isMermaid ? 'mermaid' : isJsonOutput(response.content) ? 'json' : 'markdown');
           }
 
           if (response.type === 'content') {
@@ -149,7 +172,10 @@ export class ChatService {
                       }
                   }
               } catch (error) {
-                  controller.error(new ChatError('Stream processing error', 'STREAM_ERROR', error));
+                  const aborted = error instanceof DOMException && error.name === 'AbortError';
+                  controller.error(aborted
+                      ? new ChatError('The request was cancelled', 'ABORTED')
+                      : new ChatError('Stream processing error', 'STREAM_ERROR', error));
               } finally {
                   reader.releaseLock();
                   controller.close();
@@ -161,7 +187,7 @@ export class ChatService {
       });
   }
 
-  private createChatPrompt(userInput: string, systemPromptText?: string): ChatPrompt {
+  private createChatPrompt(userInput: string, systemPromptText?: string, images?: string[]): ChatPrompt {
     const config = get(modelConfig);
     const language = get(languageStore);
 
@@ -182,12 +208,15 @@ export class ChatService {
         model: config.model,
         patternName: get(selectedPatternName),
         strategyName: get(selectedStrategy), // Add selected strategy to prompt
-        variables: get(patternVariables) // Add pattern variables
+        contextName: get(selectedContext),
+        variables: get(patternVariables), // Add pattern variables
+        images: images?.length ? images : undefined,
+        sessionName: get(currentSession) ?? undefined
     };
 }
 
-  public async createChatRequest(userInput: string, systemPromptText?: string, isPattern: boolean = false): Promise<ChatRequest> {
-    const prompt = this.createChatPrompt(userInput, systemPromptText);
+  public async createChatRequest(userInput: string, systemPromptText?: string, isPattern: boolean = false, images?: string[]): Promise<ChatRequest> {
+    const prompt = this.createChatPrompt(userInput, systemPromptText, images);
     const config = get(chatConfig);
     const language = get(languageStore);
 
@@ -204,17 +233,28 @@ export class ChatService {
     return this.fetchStream(request);
   }
 
-  public async streamChat(userInput: string, systemPromptText?: string): Promise<ReadableStream<StreamResponse>> {
-    const request = await this.createChatRequest(userInput, systemPromptText);
+  // Images are data URLs, for vision models
+  public async streamChat(userInput: string, systemPromptText?: string, images?: string[]): Promise<ReadableStream<StreamResponse>> {
+    const request = await this.createChatRequest(userInput, systemPromptText, false, images);
+    recordRun(userInput, systemPromptText, images);
     return this.fetchStream(request);
   }
 
   public async processStream(
     stream: ReadableStream<StreamResponse>,
     onContent: (content: string, response?: StreamResponse) => void,
-    onError: (error: Error) => void
+    onError: (error: Error) => void,
+    onMetadata?: (metadata: ExecutionMetadata) => void,
+    onStall?: (stall: StallInfo | null) => void,
+    onReasoning?: (reasoning: string, toolCalls: ToolCall[]) => void
   ): Promise<void> {
     const reader = stream.getReader();
+    const speaker = get(speakOutput) ? new StreamSpeaker() : null;
+    let spokenContent = '';
+    // the answer, the reasoning and the tool calls received so far in chunks
+    let answer = '';
+    let reasoning = '';
+    const toolCalls: ToolCall[] = [];
 
     try {
       while (true) {
@@ -225,11 +265,42 @@ export class ChatService {
           throw new ChatError(value.content, 'STREAM_CONTENT_ERROR');
         }
 
+        if (value.type === 'stall' && value.stall) {
+          onStall?.(value.stall);
+          continue;
+        }
+        onStall?.(null);
+
+        if (value.type === 'chunk' && value.chunk) {
+          const chunk = value.chunk;
+          if (chunk.type === 'text') {
+            answer += chunk.text ?? '';
+            onContent(answer, { ...value, format: 'markdown' });
+          } else {
+            if (chunk.type === 'reasoning') reasoning += chunk.text ?? '';
+            if (chunk.type === 'tool_call' && chunk.toolCall) toolCalls.push(chunk.toolCall);
+            onReasoning?.(reasoning, [...toolCalls]);
+          }
+          continue;
+        }
+
         if (value.type === 'content') {
-          onContent(value.content, value);
+          // the whole answer keeps the reasoning between think tags, it was already shown apart
+          const content = reasoning ? value.content.replace(/^\s*<think>[\s\S]*?<\/think>\s*/, '') : value.content;
+          onContent(content, { ...value, content });
+          if (speaker) {
+            spokenContent = content;
+            speaker.update(spokenContent);
+          }
+        }
+
+        if (value.type === 'complete' && value.metadata) {
+          onMetadata?.(value.metadata);
         }
       }
+      speaker?.finish(spokenContent);
     } catch (error) {
+      speaker?.cancel();
       onError(error instanceof ChatError ? error : new ChatError('Stream processing error', 'STREAM_ERROR', error));
     } finally {
       reader.releaseLock();
@@ -1,8 +1,17 @@
-import { createPipeline, transformers } from 'pdf-to-markdown-core/lib/src';
-import { PARSE_SCHEMA } from 'pdf-to-markdown-core/lib/src/PdfParser';
 import * as pdfjs from 'pdfjs-dist';
+import type { TextItem, TextMarkedContent } from 'pdfjs-dist/types/src/display/api';
 import pdfConfig from './pdf-config';
 
+// Lines set this much larger than the body text are taken as headings
+const HEADING_RATIO = 1.15;
+// Longest line taken as a heading, larger text beyond it is a pull quote or a title page
+const MAX_HEADING_LENGTH = 120;
+
+interface Line {
+  text: string;
+  size: number;
+}
+
 export class PdfConversionService {
   constructor() {
     if (typeof window !== 'undefined') {
@@ -13,66 +22,97 @@ export class PdfConversionService {
     }
   }
 
-  async convertToMarkdown(file: File): Promise<string> {
+  async pageCount(file: File): Promise<number> {
+    const doc = await this.open(file);
+    try {
+      return doc.numPages;
+    } finally {
+      await doc.destroy();
+    }
+  }
+
+  // Extracts the text of the given pages, all of them by default, page by page with the headings marked up
+  async convertToMarkdown(file: File, pages?: number[]): Promise<string> {
     console.log('Starting PDF conversion:', {
       fileName: file.name,
-      fileSize: file.size
+      fileSize: file.size,
+      pages: pages?.length ?? 'all'
     });
 
-    const buffer = await file.arrayBuffer();
-    console.log('Buffer created:', buffer.byteLength);
-
-    const pipeline = createPipeline(pdfjs, {
-      transformConfig: { 
-        transformers 
+    const doc = await this.open(file);
+    try {
+      const numbers = pages ?? Array.from({ length: doc.numPages }, (_, i) => i + 1);
+      const extracted: Line[][] = [];
+      for (const number of numbers) {
+        const page = await doc.getPage(number);
+        extracted.push(toLines((await page.getTextContent()).items));
+        page.cleanup();
       }
-    });
-    console.log('Pipeline created');
 
-    const result = await pipeline.parse(
-      buffer,
-      (progress) => console.log('Processing:', {
-        stage: progress.stages,
-        details: progress.stageDetails,
-        progress: progress.stageProgress
-      })
-    );
-    console.log('Parse complete, validating result');
-
-    const transformed = result.transform();
-    console.log('Transform applied:', transformed);
+      // scanned pages have no text layer, leave it to the caller to report
+      if (extracted.every(lines => lines.length === 0)) {
+        return '';
+      }
 
-    const markdown = transformed.convert({
-        convert: (items) => {
-          console.log('PDF Structure:', {
-            itemCount: items.length,
-            firstItem: items[0],
-            schema: PARSE_SCHEMA  // ['transform', 'width', 'height', 'str', 'fontName', 'dir']
-          });
-      
-          const text = items
-            .map(item => item.value('str'))  // Using 'str' instead of 'text' based on PARSE_SCHEMA
-            .filter(Boolean)
-            .join('\n');
-      
-          console.log('Converted text:', {
-            length: text.length,
-            preview: text.substring(0, 100)
-          });
-      
-          return text;
-        }
-      });
-      
+      const bodySize = medianSize(extracted.flat());
+      const markdown = numbers
+        .map((number, i) => `--- Page ${number} ---\n\n${toMarkdown(extracted[i], bodySize)}`)
+        .join('\n\n')
+        .trim();
+      console.log('PDF conversion completed:', { pages: numbers.length, length: markdown.length });
+      return markdown;
+    } finally {
+      await doc.destroy();
+    }
+  }
 
-    return markdown;
+  private async open(file: File) {
+    return pdfjs.getDocument({ data: new Uint8Array(await file.arrayBuffer()) }).promise;
   }
 }
 
-    
-
-
+// Joins the text items into lines, a line ends at an explicit line break or when the baseline moves
+function toLines(items: (TextItem | TextMarkedContent)[]): Line[] {
+  const lines: Line[] = [];
+  let text = '';
+  let size = 0;
+  let baseline: number | undefined;
 
+  const flush = () => {
+    if (text.trim()) lines.push({ text: text.trim(), size });
+    text = '';
+    size = 0;
+  };
 
+  for (const item of items) {
+    if (!('str' in item)) continue;
+    const y = item.transform[5];
+    if (baseline !== undefined && Math.abs(y - baseline) > 1) flush();
+    baseline = y;
+    text += item.str;
+    size = Math.max(size, item.height);
+    if (item.hasEOL) flush();
+  }
+  flush();
+  return lines;
+}
 
+function medianSize(lines: Line[]): number {
+  const sizes = lines.map(line => line.size).filter(size => size > 0).sort((a, b) => a - b);
+  return sizes.length ? sizes[Math.floor(sizes.length / 2)] : 0;
+}
 
+function toMarkdown(lines: Line[], bodySize: number): string {
+  return lines
+    .map(line => {
+      if (!bodySize || line.size < bodySize * HEADING_RATIO || line.text.length > MAX_HEADING_LENGTH) {
+        return line.text;
+      }
+      const ratio = line.size / bodySize;
+      const level = ratio >= 1.6 ? '#' : ratio >= 1.3 ? '##' : '###';
+      return `\n${level} ${line.text}\n`;
+    })
+    .join('\n')
+    .replace(/\n{3,}/g, '\n\n')
+    .trim();
+}
@@ -0,0 +1,148 @@
+import { get } from 'svelte/store';
+import { goto } from '$app/navigation';
+import { patterns, patternAPI } from '$lib/store/pattern-store';
+import { historyAPI } from '$lib/api/history';
+import { starredOutputs, loadStarred } from '$lib/store/starred-store';
+import { watches, loadWatches } from '$lib/store/watch-store';
+
+export interface QuickSearchResult {
+  id: string;
+  title: string;
+  detail?: string;
+  open: () => void | Promise<void>; // jumps to the result
+}
+
+// A source of the quick search, results are shown grouped by provider in the order they were registered
+export interface QuickSearchProvider {
+  group: string;
+  limit?: number;
+  search: (query: string) => QuickSearchResult[] | Promise<QuickSearchResult[]>;
+}
+
+const providers: QuickSearchProvider[] = [];
+
+// Adds a source to the quick search of the header, returns a function removing it
+export function registerQuickSearchProvider(provider: QuickSearchProvider): () => void {
+  providers.push(provider);
+  return () => {
+    const index = providers.indexOf(provider);
+    if (index >= 0) providers.splice(index, 1);
+  };
+}
+
+export interface QuickSearchGroup {
+  group: string;
+  results: QuickSearchResult[];
+}
+
+// Searches all the providers, a failing provider is left out rather than failing the search
+export async function quickSearch(query: string): Promise<QuickSearchGroup[]> {
+  const trimmed = query.trim();
+  if (!trimmed) return [];
+  const groups = await Promise.all(providers.map(async provider => {
+    try {
+      const results = await provider.search(trimmed);
+      return { group: provider.group, results: results.slice(0, provider.limit ?? 5) };
+    } catch (error) {
+      console.error(`Quick search of ${provider.group} failed:`, error);
+      return { group: provider.group, results: [] };
+    }
+  }));
+  return groups.filter(group => group.results.length > 0);
+}
+
+// Tells whether all the words of the query are in one of the texts, ignoring the case
+export function matches(query: string, ...texts: (string | undefined)[]): boolean {
+  const haystack = texts.filter(Boolean).join(' ').toLowerCase();
+  return query.toLowerCase().split(/\s+/).every(word => haystack.includes(word));
+}
+
+function preview(text: string, length = 80): string {
+  const line = text.replace(/\s+/g, ' ').trim();
+  return line.length > length ? `${line.slice(0, length)}…` : line;
+}
+
+// The pages and settings sections the quick search jumps to
+const pages: { title: string; href: string; keywords: string }[] = [
+  { title: 'Chat', href: '/chat', keywords: 'run pattern model temperature settings precheck transcription' },
+  { title: 'Run history', href: '/history', keywords: 'runs calendar heatmap replay compare diff' },
+  { title: 'Starred outputs', href: '/history#starred', keywords: 'favorites knowledge pack export import' },
+  { title: 'Proposed runs', href: '/history#proposals', keywords: 'approval queue' },
+  { title: 'Backup settings', href: '/history#backup', keywords: 'restore archive schedule settings' },
+  { title: 'Folder watch', href: '/watch', keywords: 'directory files automatic settings' },
+  { title: 'Posts', href: '/posts', keywords: 'blog notes' },
+  { title: 'About', href: '/about', keywords: 'fabric help' }
+];
+
+registerQuickSearchProvider({
+  group: 'Patterns',
+  limit: 6,
+  async search(query) {
+    if (get(patterns).length === 0) await patternAPI.loadPatterns();
+    return get(patterns)
+      .filter(pattern => matches(query, pattern.Name, pattern.Description, pattern.tags?.join(' ')))
+      // names starting with the query first
+      .sort((a, b) => Number(b.Name.startsWith(query)) - Number(a.Name.startsWith(query)))
+      .map(pattern => ({
+        id: `pattern-${pattern.Name}`,
+        title: pattern.Name,
+        detail: pattern.Description,
+        open: async () => {
+          patternAPI.selectPattern(pattern.Name);
+          await goto('/chat');
+        }
+      }));
+  }
+});
+
+registerQuickSearchProvider({
+  group: 'History',
+  async search(query) {
+    const runs = await historyAPI.search(query, 5);
+    return runs.map(run => ({
+      id: `run-${run.id}`,
+      title: `${run.patternName || run.jobName || 'Run'} · ${new Date(run.timestamp).toLocaleString()}`,
+      detail: preview(run.error || run.output || run.input),
+      open: () => goto(`/history?run=${encodeURIComponent(run.id)}`)
+    }));
+  }
+});
+
+registerQuickSearchProvider({
+  group: 'Starred',
+  async search(query) {
+    if (get(starredOutputs).length === 0) await loadStarred();
+    return get(starredOutputs)
+      .filter(output => matches(query, output.run.patternName, output.annotation, output.run.input, output.run.output))
+      .map(output => ({
+        id: `starred-${output.run.id}`,
+        title: `★ ${output.run.patternName || 'Output'}`,
+        detail: preview(output.annotation || output.run.output),
+        open: () => goto('/history#starred')
+      }));
+  }
+});
+
+registerQuickSearchProvider({
+  group: 'Watches',
+  async search(query) {
+    if (get(watches).length === 0) await loadWatches();
+    return get(watches)
+      .filter(watch => matches(query, watch.name, watch.directory, watch.patternName))
+      .map(watch => ({
+        id: `watch-${watch.name}`,
+        title: watch.name,
+        detail: `${watch.directory} → ${watch.patternName}`,
+        open: () => goto('/watch')
+      }));
+  }
+});
+
+registerQuickSearchProvider({
+  group: 'Pages',
+  search(query) {
+    return pages
+      .filter(page => matches(query, page.title, page.keywords))
+      .map(page => ({ id: `page-${page.href}`, title: page.title, open: () => goto(page.href) }));
+  }
+});
@@ -0,0 +1,57 @@
+// Reads streamed output aloud with the browser speech synthesis, one sentence as soon as it is complete
+
+// A sentence ends with '.', '!' or '?' followed by white space, or with a line break
+const SENTENCE_END = /[.!?](?=\s)|\n/g;
+
+function cleanSentence(text: string): string {
+  const cleaned = text
+    .replace(/\*\*|__|[`*#>]/g, '')
+    .replace(/\|/g, ' ')
+    .replace(/^[\s+-]+/, '')
+    .replace(/\s+/g, ' ')
+    .trim();
+  return /[\p{L}\p{N}]/u.test(cleaned) ? cleaned : '';
+}
+
+export function isSpeechSupported(): boolean {
+  return typeof window !== 'undefined' && 'speechSynthesis' in window;
+}
+
+export class StreamSpeaker {
+  private spoken = 0; // length of the text already queued for speaking
+
+  // update takes the output received so far and speaks the sentences completed since the last update
+  update(text: string): void {
+    if (text.length < this.spoken) {
+      this.spoken = 0; // a new response started
+    }
+    let end = this.spoken;
+    for (const match of text.slice(this.spoken).matchAll(SENTENCE_END)) {
+      const sentenceEnd = this.spoken + match.index! + (match[0] === '\n' ? 0 : 1);
+      this.say(text.slice(end, sentenceEnd));
+      end = sentenceEnd;
+    }
+    this.spoken = end;
+  }
+
+  // finish speaks the rest of the output
+  finish(text: string): void {
+    this.update(text);
+    this.say(text.slice(this.spoken));
+    this.spoken = text.length;
+  }
+
+  cancel(): void {
+    if (isSpeechSupported()) {
+      window.speechSynthesis.cancel();
+    }
+    this.spoken = 0;
+  }
+
+  private say(text: string): void {
+    const sentence = cleanSentence(text);
+    if (sentence && isSpeechSupported()) {
+      window.speechSynthesis.speak(new SpeechSynthesisUtterance(sentence));
+    }
+  }
+}
@@ -1,5 +1,7 @@
 import { writable } from 'svelte/store';
 import type { ChatConfig } from '$lib/interfaces/chat-interface';
+import type { TranscribeBackend } from '$lib/api/transcribe';
+import type { SheetFormat } from '$lib/utils/spreadsheet';
 
 const defaultConfig: ChatConfig = {
   temperature: 0.7,
@@ -10,6 +12,30 @@ const defaultConfig: ChatConfig = {
 
 export const chatConfig = writable<ChatConfig>(defaultConfig);
 
+// Read the output aloud while it streams, a browser setting not sent to the server
+export const speakOutput = writable<boolean>(false);
+
+// Checks the input of writing patterns for typos before running: show the issues first, or ask the
+// model to fix them
+export type PrecheckMode = 'off' | 'show' | 'append';
+export const precheckMode = writable<PrecheckMode>('off');
+
+// Backend transcribing the files of the audio input source
+export const transcribeBackend = writable<TranscribeBackend>('');
+
+// Tesseract languages of the screenshot input source joined with +, empty for the ones configured on the server
+export const ocrLanguages = writable<string>('');
+
+// Number of the latest messages of the .mbox mailboxes read by the email input source
+export const emailMessages = writable<number>(10);
+
+// Defaults of the column picker of spreadsheet files: the format sent to the pattern and the rows kept
+export const spreadsheetFormat = writable<SheetFormat>('markdown');
+export const spreadsheetRowLimit = writable<number>(100);
+
+// Watermarks the exports with their provenance: meta tags and a footer in HTML and PDF, a footer in markdown
+export const exportProvenance = writable<boolean>(false);
+
 export function updateConfig(newConfig: Partial<ChatConfig>): void {
   chatConfig.update(config => ({
     ...config,
@@ -1,8 +1,13 @@
 import { writable, derived, get } from 'svelte/store';
-import type { ChatState, Message, StreamResponse } from '$lib/interfaces/chat-interface';
+import type { ChatState, Message, StreamResponse, StallInfo } from '$lib/interfaces/chat-interface';
 import { ChatService, ChatError } from '$lib/services/ChatService';
 import { languageStore } from '$lib/store/language-store';
 import { selectedPatternName } from '$lib/store/pattern-store';
+import { sessionAPI } from '$lib/store/session-store';
+import { unsavedChanges } from '$lib/store/unsaved-store';
+import { lastRun } from '$lib/store/stale-store';
+import { startProgress, type Progress } from '$lib/store/progress-store';
+import { patternUsage } from '$lib/store/pattern-usage-store';
 
 // Initialize chat service
 const chatService = new ChatService();
@@ -20,6 +25,23 @@ export const messageStore = writable<Message[]>(initialMessages);
 export const streamingStore = writable<boolean>(false);
 export const errorStore = writable<string | null>(null);
 export const currentSession = writable<string | null>(null);
+// The stall of the running request reported by the server, null while it is answering
+export const stallStore = writable<StallInfo | null>(null);
+
+// set while a stalled request is abandoned, reconnecting sends it again
+let abandoned: 'cancel' | 'reconnect' | null = null;
+
+export const waitForStalled = () => stallStore.set(null);
+
+export const cancelStalled = () => {
+  abandoned = 'cancel';
+  chatService.abort();
+};
+
+export const reconnectStalled = () => {
+  abandoned = 'reconnect';
+  chatService.abort();
+};
 
 // Subscribe to messageStore changes to persist messages
 if (typeof localStorage !== 'undefined') {
@@ -28,6 +50,33 @@ if (typeof localStorage !== 'undefined') {
   });
 }
 
+// A running execution is lost with the page, and garbled by loading another session into the chat
+streamingStore.subscribe(streaming => {
+  if (streaming) {
+    unsavedChanges.mark({ id: 'execution', label: 'A running pattern execution', lostOn: ['unload', 'session'] });
+  } else {
+    unsavedChanges.clear('execution');
+  }
+});
+
+// The messages survive reloads in localStorage, so outputs are only lost by replacing the session
+export const markOutputExported = () => unsavedChanges.clear('chat-output');
+
+function markOutputUnexported() {
+  unsavedChanges.mark({
+    id: 'chat-output',
+    label: 'Chat outputs not exported or saved',
+    lostOn: ['session'],
+    save: {
+      label: 'Save session',
+      run: async () => {
+        await sessionAPI.exportToFile(get(messageStore));
+        markOutputExported();
+      }
+    }
+  });
+}
+
 // Derived store for chat state
 export const chatState = derived(
   [messageStore, streamingStore],
@@ -60,17 +109,51 @@ export const setSession = (sessionName: string | null) => {
 export const clearMessages = () => {
   messageStore.set([]);
   errorStore.set(null);
+  markOutputExported();
   if (typeof localStorage !== 'undefined') {
     localStorage.removeItem(MESSAGES_STORAGE_KEY);
   }
 };
 
+// Puts a past output back in the results with its input, after the current messages. What it was produced
+// from is unknown, so it isn't marked stale.
+export const restoreOutput = (input: string, output: string, metadata?: Message['metadata']) => {
+  messageStore.update(messages => [
+    ...messages,
+    { role: 'user', content: input },
+    { role: 'assistant', content: output, metadata }
+  ]);
+  lastRun.set(null);
+};
+
+// Continues a run as a chat: its input and output start a session on the server and become the
+// conversation. The next messages are sent to the session without the pattern, so the model sees the
+// conversation so far.
+export const continueInChat = async (input: string, output: string, metadata?: Message['metadata']) => {
+  const name = `chat-${new Date().toISOString().replace(/[-:]/g, '').replace(/\..*$/, '')}`;
+  await sessionAPI.appendMessages(name, [
+    { role: 'user', content: input },
+    { role: 'assistant', content: output }
+  ]);
+  messageStore.set([]);
+  restoreOutput(input, output, metadata);
+  currentSession.set(name);
+  selectedPatternName.set('');
+  await sessionAPI.loadSessions();
+  return name;
+};
+
+// A text handed to the chat input, like an output passed on to the next pattern, it is taken once
+export const nextInput = writable<string | null>(null);
+
 export const revertLastMessage = () => {
   messageStore.update(messages => messages.slice(0, -1));
 };
 
 
-  export async function sendMessage(content: string, systemPromptText?: string, isSystem: boolean = false) {
+  export async function sendMessage(content: string, systemPromptText?: string, isSystem: boolean = false, images?: string[]) {
+    // the run is announced to the screen readers while it streams
+    let progress: Progress | undefined;
     try {
         console.log('\n=== Message Processing Start ===');
         console.log('1. Initial state:', {
@@ -87,6 +170,7 @@ export const revertLastMessage = () => {
 
         streamingStore.set(true);
         errorStore.set(null);
+        const firstMessage = get(messageStore).length;
 
         // Add message
         messageStore.update(messages => [...messages, {
@@ -106,12 +190,20 @@ export const revertLastMessage = () => {
                 hasSystemPrompt: !!systemPromptText
             });
 
-            const stream = await chatService.streamChat(content, systemPromptText);
+            const pattern = get(selectedPatternName);
+            progress = startProgress({
+                running: pattern ? `Running ${pattern}` : 'Waiting for the answer',
+                done: 'Answer complete',
+                failed: 'The run failed'
+            });
+
+            const stream = await chatService.streamChat(content, systemPromptText, images);
             console.log('4. Stream created');
 
             await chatService.processStream(
                 stream,
                 (content: string, response?: StreamResponse) => {
+                    progress?.update(`${content.length.toLocaleString()} characters so far`);
                     messageStore.update(messages => {
                         const newMessages = [...messages];
                         const lastMessage = newMessages[newMessages.length - 1];
@@ -135,13 +227,58 @@ export const revertLastMessage = () => {
                     });
                 },
                 (error) => {
+                    if (abandoned) return;
+                    progress?.fail(error);
                     handleError(error);
+                },
+                (metadata) => {
+                    messageStore.update(messages => {
+                        const newMessages = [...messages];
+                        const lastMessage = newMessages[newMessages.length - 1];
+                        if (lastMessage?.role === 'assistant') {
+                            lastMessage.metadata = metadata;
+                        }
+                        return newMessages;
+                    });
+                },
+                (stall) => stallStore.set(stall),
+                (reasoning, toolCalls) => {
+                    messageStore.update(messages => {
+                        const newMessages = [...messages];
+                        const lastMessage = newMessages[newMessages.length - 1];
+                        if (lastMessage?.role === 'assistant') {
+                            lastMessage.reasoning = reasoning;
+                            lastMessage.toolCalls = toolCalls;
+                        } else {
+                            newMessages.push({ role: 'assistant', content: '', format: 'markdown', reasoning, toolCalls });
+                        }
+                        return newMessages;
+                    });
                 }
             );
+            stallStore.set(null);
+            if (abandoned) {
+                const action = abandoned;
+                abandoned = null;
+                progress?.done(action === 'reconnect' ? 'Reconnecting' : 'Run cancelled');
+                streamingStore.set(false);
+                if (action === 'reconnect') {
+                    messageStore.update(messages => messages.slice(0, firstMessage));
+                    return sendMessage(content, systemPromptText, isSystem, images);
+                }
+                return;
+            }
+            const answer = get(messageStore).at(-1);
+            if (answer?.role === 'assistant') {
+                markOutputUnexported();
+                if (pattern) patternUsage.record(pattern);
+                progress?.done(`Answer complete, ${answer.content.split(/\s+/).filter(Boolean).length.toLocaleString()} words`);
+            }
         }
 
         streamingStore.set(false);
     } catch (error) {
+        progress?.fail(error);
         if (error instanceof Error) {
             handleError(error);
         } else {
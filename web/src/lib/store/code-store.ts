@@ -0,0 +1,35 @@
+import { writable } from 'svelte/store';
+
+const STORAGE_KEY = 'codeSettings';
+
+// Globs are comma separated, in the .gitignore syntax
+export interface CodeSettings {
+  root: string;
+  include: string;
+  exclude: string;
+  maxTokens: number;
+}
+
+const defaultSettings: CodeSettings = { root: '', include: '', exclude: '', maxTokens: 100000 };
+
+function load(): CodeSettings {
+  if (typeof localStorage === 'undefined') return defaultSettings;
+  try {
+    return { ...defaultSettings, ...JSON.parse(localStorage.getItem(STORAGE_KEY) ?? '{}') };
+  } catch {
+    return defaultSettings;
+  }
+}
+
+// The repository and globs of the code input source, kept between runs of code patterns
+export const codeSettings = writable<CodeSettings>(load());
+
+codeSettings.subscribe(settings => {
+  if (typeof localStorage !== 'undefined') {
+    localStorage.setItem(STORAGE_KEY, JSON.stringify(settings));
+  }
+});
+
+export function splitGlobs(globs: string): string[] {
+  return globs.split(',').map(glob => glob.trim()).filter(Boolean);
+}
@@ -0,0 +1,34 @@
+import { writable } from 'svelte/store';
+import { DEFAULT_HEADER, DEFAULT_SEPARATOR } from '$lib/utils/combine-files';
+
+export interface CombineSettings {
+  separator: string;
+  header: string;
+}
+
+const STORAGE_KEY = 'combineFiles';
+const defaults: CombineSettings = { separator: DEFAULT_SEPARATOR, header: DEFAULT_HEADER };
+
+const stored: CombineSettings = typeof localStorage !== 'undefined'
+  ? { ...defaults, ...JSON.parse(localStorage.getItem(STORAGE_KEY) || '{}') }
+  : defaults;
+
+// Separator and header used to combine several attached files into the input
+const createCombineSettingsStore = () => {
+  const { subscribe, set } = writable<CombineSettings>(stored);
+
+  const save = (settings: CombineSettings) => {
+    set(settings);
+    if (typeof localStorage !== 'undefined') {
+      localStorage.setItem(STORAGE_KEY, JSON.stringify(settings));
+    }
+  };
+
+  return {
+    subscribe,
+    set: save,
+    reset: () => save(defaults)
+  };
+};
+
+export const combineSettings = createCombineSettingsStore();
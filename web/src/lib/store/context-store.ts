@@ -0,0 +1,25 @@
+import { writable } from 'svelte/store';
+import { contextAPI } from '$lib/api/contexts';
+
+/**
+ * Names of the contexts available in the fabric contexts directory.
+ */
+export const contexts = writable<string[]>([]);
+
+/**
+ * Currently selected context name.
+ * Default is empty string meaning "None".
+ */
+export const selectedContext = writable<string>("");
+
+/**
+ * Fetches the context names from the backend `/contexts/names` endpoint.
+ * Populates the `contexts` store.
+ */
+export async function fetchContexts() {
+  try {
+    contexts.set(await contextAPI.getAvailable());
+  } catch (error) {
+    console.error('Error fetching contexts:', error);
+  }
+}
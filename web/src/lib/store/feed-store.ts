@@ -0,0 +1,28 @@
+import { writable } from 'svelte/store';
+
+const STORAGE_KEY = 'feedSettings';
+
+export interface FeedSettings {
+  url: string;
+  entries: number;
+}
+
+const defaultSettings: FeedSettings = { url: '', entries: 5 };
+
+function load(): FeedSettings {
+  if (typeof localStorage === 'undefined') return defaultSettings;
+  try {
+    return { ...defaultSettings, ...JSON.parse(localStorage.getItem(STORAGE_KEY) ?? '{}') };
+  } catch {
+    return defaultSettings;
+  }
+}
+
+// The feed of the feed input source, kept so a daily feed is a click away
+export const feedSettings = writable<FeedSettings>(load());
+
+feedSettings.subscribe(settings => {
+  if (typeof localStorage !== 'undefined') {
+    localStorage.setItem(STORAGE_KEY, JSON.stringify(settings));
+  }
+});
@@ -0,0 +1,18 @@
+import { writable } from 'svelte/store';
+import { DEFAULT_FILE_NAME_TEMPLATE } from '$lib/utils/file-name';
+
+const STORAGE_KEY = 'outputFileNameTemplate';
+
+function load(): string {
+  if (typeof localStorage === 'undefined') return DEFAULT_FILE_NAME_TEMPLATE;
+  return localStorage.getItem(STORAGE_KEY) ?? DEFAULT_FILE_NAME_TEMPLATE;
+}
+
+// The template the saved outputs are named after, see outputFileName, kept between sessions
+export const fileNameTemplate = writable<string>(load());
+
+fileNameTemplate.subscribe(template => {
+  if (typeof localStorage !== 'undefined') {
+    localStorage.setItem(STORAGE_KEY, template);
+  }
+});
@@ -0,0 +1,43 @@
+import { writable } from 'svelte/store';
+
+const STORAGE_KEY = 'inputHistory';
+// inputs kept, the oldest are dropped
+export const MAX_INPUT_HISTORY = 20;
+
+// An input sent to chat, with the pattern it was run with
+export interface InputHistoryEntry {
+  input: string;
+  patternName: string;
+  sentAt: string;
+}
+
+const stored: InputHistoryEntry[] = typeof localStorage !== 'undefined'
+  ? JSON.parse(localStorage.getItem(STORAGE_KEY) || '[]')
+  : [];
+
+// The last inputs sent, most recent first, persisted in the browser across sessions
+const createInputHistoryStore = () => {
+  const { subscribe, set, update } = writable<InputHistoryEntry[]>(stored);
+
+  const save = (entries: InputHistoryEntry[]) => {
+    if (typeof localStorage !== 'undefined') {
+      localStorage.setItem(STORAGE_KEY, JSON.stringify(entries));
+    }
+    return entries;
+  };
+
+  return {
+    subscribe,
+    // records the input, an input sent again moves to the top
+    record: (input: string, patternName: string) => {
+      if (!input.trim()) return;
+      update(entries => save([
+        { input, patternName, sentAt: new Date().toISOString() },
+        ...entries.filter(entry => entry.input !== input)
+      ].slice(0, MAX_INPUT_HISTORY)));
+    },
+    clear: () => set(save([]))
+  };
+};
+
+export const inputHistory = createInputHistoryStore();
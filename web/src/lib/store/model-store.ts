@@ -1,7 +1,9 @@
 import { writable } from 'svelte/store';
 import { modelsApi } from '$lib/api/models';
 import { configApi } from '$lib/api/config';
-import type { VendorModel, ModelConfig } from '$lib/interfaces/model-interface';
+import { historyAPI } from '$lib/api/history';
+import { startProgress } from '$lib/store/progress-store';
+import type { VendorModel, ModelConfig, VendorQuota, VendorHealth } from '$lib/interfaces/model-interface';
 
 export const modelConfig = writable<ModelConfig>({
   model: '',
@@ -13,17 +15,84 @@ export const modelConfig = writable<ModelConfig>({
 });
 
 export const availableModels = writable<VendorModel[]>([]);
+export const modelsLoading = writable<boolean>(false);
 
-// Initialize available models
-export async function loadAvailableModels() {
+// How long a loaded model list is used before it is loaded again, the server refreshes its own copy
+const MODELS_MAX_AGE_MS = 10 * 60 * 1000;
+let modelsLoadedAt = 0;
+let pendingLoad: Promise<void> | undefined;
+
+// Initialize available models, a recently loaded list is kept unless forced
+export async function loadAvailableModels(force = false) {
+  if (!force && Date.now() - modelsLoadedAt < MODELS_MAX_AGE_MS) return;
+  if (pendingLoad) return pendingLoad;
+
+  modelsLoading.set(true);
+  // the refreshes of the list in the background are only announced when they fail
+  const progress = startProgress(
+    { running: 'Loading models', done: 'Models loaded', failed: 'Failed to load models' },
+    !force && modelsLoadedAt > 0
+  );
+  pendingLoad = (async () => {
+    try {
+      const models = await modelsApi.getAvailable();
+      const uniqueModels = [...new Map(models.map(model => [model.name, model])).values()];
+      availableModels.set(uniqueModels);
+      modelsLoadedAt = Date.now();
+      progress.done(`${uniqueModels.length} models loaded`);
+    } catch (error) {
+      console.error('Client failed to load available models:', error);
+      progress.fail(error);
+    } finally {
+      modelsLoading.set(false);
+      pendingLoad = undefined;
+    }
+  })();
+  return pendingLoad;
+}
+
+// Loads the model list when the browser is idle after startup and whenever it gets old, so the model
+// dropdown is filled by the time it is opened. Returns a function to stop.
+export function prefetchModelsWhenIdle(): () => void {
+  const whenIdle = (callback: () => void) =>
+    typeof requestIdleCallback !== 'undefined' ? requestIdleCallback(callback, { timeout: 5000 }) : setTimeout(callback, 1000);
+  whenIdle(() => loadAvailableModels());
+  const timer = setInterval(() => whenIdle(() => loadAvailableModels()), MODELS_MAX_AGE_MS);
+  return () => clearInterval(timer);
+}
+
+// Quotas of the vendors reporting them, by vendor name
+export const vendorQuotas = writable<Record<string, VendorQuota>>({});
+
+export async function loadVendorQuotas() {
+  try {
+    const quotas = await modelsApi.getQuotas();
+    vendorQuotas.set(Object.fromEntries(quotas.map(quota => [quota.vendor, quota])));
+  } catch (error) {
+    console.error('Failed to load vendor quotas:', error);
+  }
+}
+
+// Circuit breaker health of the configured vendors, by vendor name
+export const vendorHealth = writable<Record<string, VendorHealth>>({});
+
+export async function loadVendorHealth() {
+  try {
+    const health = await modelsApi.getHealth();
+    vendorHealth.set(Object.fromEntries(health.map(entry => [entry.vendor, entry])));
+  } catch (error) {
+    console.error('Failed to load vendor health:', error);
+  }
+}
+
+// Typical latency in milliseconds of the models in the run history, by model name
+export const modelLatency = writable<Record<string, number>>({});
+
+export async function loadModelLatency() {
   try {
-    const models = await modelsApi.getAvailable();
-    console.log('Load models:', models);
-    const uniqueModels = [...new Map(models.map(model => [model.name, model])).values()];
-    availableModels.set(uniqueModels);
+    modelLatency.set(await historyAPI.getLatency());
   } catch (error) {
-    console.error('Client failed to load available models:', error);
-    availableModels.set([]);
+    console.error('Failed to load model latency:', error);
   }
 }
 
@@ -1,5 +1,6 @@
 import { writable, get } from 'svelte/store';
 import { featureFlags } from '../config/features';
+import { DEFAULT_NOTE_TEMPLATE, noteFileName, noteFrontmatter, parseTags, type NoteFields } from '$lib/utils/obsidian-note';
 
 export interface ObsidianSettings {
   saveToObsidian: boolean;
@@ -66,3 +67,70 @@ export function getObsidianFilePath(noteName: string): string | undefined {
   
 }
 
+
+const VAULT_STORAGE_KEY = 'obsidianVault';
+
+// Where the outputs sent to Obsidian in one click are written: the folder in the vault set by
+// FABRIC_OBSIDIAN_VAULT on the web server, the template the notes are named after and the fields of their
+// frontmatter
+export interface ObsidianVault {
+  folder: string; // empty for the root of the vault
+  fileNameTemplate: string;
+  frontmatter: { pattern: boolean; model: boolean; date: boolean };
+  tags: string; // separated by commas or spaces
+}
+
+const defaultVault: ObsidianVault = {
+  folder: '',
+  fileNameTemplate: DEFAULT_NOTE_TEMPLATE,
+  frontmatter: { pattern: true, model: true, date: true },
+  tags: 'fabric'
+};
+
+function loadVault(): ObsidianVault {
+  if (typeof localStorage === 'undefined') return defaultVault;
+  try {
+    return { ...defaultVault, ...JSON.parse(localStorage.getItem(VAULT_STORAGE_KEY) ?? '{}') };
+  } catch {
+    return defaultVault;
+  }
+}
+
+// Kept between sessions
+export const obsidianVault = writable<ObsidianVault>(loadVault());
+
+obsidianVault.subscribe(vault => {
+  if (typeof localStorage !== 'undefined') {
+    localStorage.setItem(VAULT_STORAGE_KEY, JSON.stringify(vault));
+  }
+});
+
+// Writes the output as a new note of the vault, returns the path of the note
+export async function sendToObsidian(content: string, pattern: string, model: string): Promise<string> {
+  const vault = get(obsidianVault);
+  const fields: NoteFields = {
+    pattern,
+    model,
+    date: new Date().toISOString().split('T')[0],
+    tags: parseTags(vault.tags)
+  };
+  const response = await fetch('/obsidian', {
+    method: 'POST',
+    headers: { 'Content-Type': 'application/json' },
+    body: JSON.stringify({
+      pattern: pattern || 'fabric',
+      content,
+      folder: vault.folder,
+      fileName: noteFileName(vault.fileNameTemplate, fields),
+      frontmatter: noteFrontmatter({
+        pattern: vault.frontmatter.pattern ? pattern : undefined,
+        model: vault.frontmatter.model ? model : undefined,
+        date: vault.frontmatter.date ? fields.date : undefined,
+        tags: fields.tags
+      })
+    })
+  });
+  const data = await response.json();
+  if (!response.ok) throw new Error(data.error || 'Failed to save to Obsidian');
+  return data.filePath;
+}
@@ -0,0 +1,36 @@
+import { writable } from 'svelte/store';
+
+const STORAGE_KEY = 'colorblindPalette';
+
+// Colorblind-safe status colors, applied through the data-palette attribute of the root element
+function createPaletteStore() {
+  const { subscribe, set } = writable<boolean>(false);
+
+  function apply(enabled: boolean) {
+    set(enabled);
+    if (typeof document !== 'undefined') {
+      if (enabled) {
+        document.documentElement.setAttribute('data-palette', 'colorblind');
+      } else {
+        document.documentElement.removeAttribute('data-palette');
+      }
+    }
+  }
+
+  return {
+    subscribe,
+    setColorblind: (enabled: boolean) => {
+      apply(enabled);
+      if (typeof localStorage !== 'undefined') {
+        localStorage.setItem(STORAGE_KEY, JSON.stringify(enabled));
+      }
+    },
+    initPalette: () => {
+      if (typeof localStorage !== 'undefined') {
+        apply(JSON.parse(localStorage.getItem(STORAGE_KEY) || 'false'));
+      }
+    }
+  };
+}
+
+export const colorblindPalette = createPaletteStore();
@@ -0,0 +1,14 @@
+import { writable } from 'svelte/store';
+
+const STORAGE_KEY = 'htmlPasteCleanup';
+
+const stored = typeof localStorage !== 'undefined' ? localStorage.getItem(STORAGE_KEY) !== 'false' : true;
+
+// Converts pasted web content from HTML to markdown, so the patterns receive readable text
+export const htmlPasteCleanup = writable<boolean>(stored);
+
+htmlPasteCleanup.subscribe(enabled => {
+  if (typeof localStorage !== 'undefined') {
+    localStorage.setItem(STORAGE_KEY, String(enabled));
+  }
+});
@@ -0,0 +1,92 @@
+import { writable } from 'svelte/store';
+
+export type DoubleClickAction = 'select' | 'run';
+export type PatternSort = 'name' | 'recent' | 'used';
+
+function load<T>(key: string, fallback: T): T {
+  if (typeof localStorage === 'undefined') return fallback;
+  const stored = localStorage.getItem(key);
+  return stored ? (JSON.parse(stored) as T) : fallback;
+}
+
+function save(key: string, value: unknown) {
+  if (typeof localStorage !== 'undefined') {
+    localStorage.setItem(key, JSON.stringify(value));
+  }
+}
+
+// Patterns pinned to the top of the pattern list
+const createPinnedStore = () => {
+  const { subscribe, update } = writable<string[]>(load('pinnedPatterns', []));
+
+  return {
+    subscribe,
+    togglePin: (patternName: string) => {
+      update(pinned => {
+        const newPinned = pinned.includes(patternName)
+          ? pinned.filter(name => name !== patternName)
+          : [...pinned, patternName];
+        save('pinnedPatterns', newPinned);
+        return newPinned;
+      });
+    }
+  };
+};
+
+// Named collections of patterns, by collection name
+const createCollectionsStore = () => {
+  const { subscribe, update } = writable<Record<string, string[]>>(load('patternCollections', {}));
+
+  return {
+    subscribe,
+    add: (collection: string, patternName: string) => {
+      update(collections => {
+        const patterns = collections[collection] || [];
+        if (patterns.includes(patternName)) return collections;
+        const newCollections = { ...collections, [collection]: [...patterns, patternName] };
+        save('patternCollections', newCollections);
+        return newCollections;
+      });
+    },
+    remove: (collection: string, patternName: string) => {
+      update(collections => {
+        const patterns = (collections[collection] || []).filter(name => name !== patternName);
+        const newCollections = { ...collections, [collection]: patterns };
+        if (patterns.length === 0) delete newCollections[collection];
+        save('patternCollections', newCollections);
+        return newCollections;
+      });
+    }
+  };
+};
+
+// What double-clicking a pattern in the list does
+const createDoubleClickStore = () => {
+  const { subscribe, set } = writable<DoubleClickAction>(load('patternDoubleClick', 'select'));
+
+  return {
+    subscribe,
+    set: (action: DoubleClickAction) => {
+      set(action);
+      save('patternDoubleClick', action);
+    }
+  };
+};
+
+// The order of the patterns in the pattern list and the pattern dropdown
+const createSortStore = () => {
+  const { subscribe, set } = writable<PatternSort>(load('patternSort', 'name'));
+
+  return {
+    subscribe,
+    set: (sort: PatternSort) => {
+      set(sort);
+      save('patternSort', sort);
+    }
+  };
+};
+
+export const pinnedPatterns = createPinnedStore();
+export const patternCollections = createCollectionsStore();
+export const patternDoubleClick = createDoubleClickStore();
+export const patternSort = createSortStore();
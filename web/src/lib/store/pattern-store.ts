@@ -1,11 +1,46 @@
 import { createStorageAPI } from '$lib/api/base';
-import type { Pattern, PatternDescription } from '$lib/interfaces/pattern-interface';
+import type { Pattern, PatternAttachment, PatternDescription, PatternMeta } from '$lib/interfaces/pattern-interface';
 import { get, writable, derived } from 'svelte/store';
 import { languageStore } from './language-store';
+import { modelConfig } from './model-store';
+import { updateConfig } from './chat-config';
+import { startProgress } from './progress-store';
 
 // Store for all patterns
 const allPatterns = writable<Pattern[]>([]);
 
+// Pattern descriptions, kept to describe patterns added after the initial load
+let patternDescriptions: PatternDescription[] = [];
+
+// Descriptions and tags the server derives from the pattern files, see GET /patterns/metadata
+interface PatternMetadata {
+  name: string;
+  description: string;
+  derivedTags?: string[];
+}
+let patternMetadata = new Map<string, PatternMetadata>();
+
+// A pattern added, modified or removed on the server, see GET /patterns/events
+interface PatternChange {
+  type: 'added' | 'modified' | 'removed';
+  name: string;
+  pattern?: { Name: string; Pattern: string; Meta?: PatternMeta; Source?: string };
+}
+
+const toPattern = (name: string, content: string, meta?: PatternMeta, source?: string): Pattern => {
+  const desc = patternDescriptions.find(d => d.patternName === name);
+  const metadata = patternMetadata.get(name);
+  return {
+    Name: name,
+    Description: desc?.description || metadata?.description || name.charAt(0).toUpperCase() + name.slice(1),
+    Pattern: content || "",
+    // curated tags from the descriptions, completed by the derived ones
+    tags: [...new Set([...(desc?.tags || []), ...(metadata?.derivedTags || [])])],
+    Meta: meta,
+    Source: source
+  };
+};
+
 // Filtered patterns based on language
 export const patterns = derived(
   [allPatterns, languageStore],
@@ -39,68 +74,165 @@ export const setSystemPrompt = (prompt: string) => {
   console.log('Current system prompt:', get(systemPrompt));
 };
 
+// Applies the model and temperature a pattern declares to the Execute tab, its variable defaults are applied by the server
+const applyPatternMeta = (meta?: PatternMeta) => {
+  if (!meta) return;
+  if (meta.model) modelConfig.update(config => ({ ...config, model: meta.model! }));
+  if (meta.temperature !== undefined) {
+    modelConfig.update(config => ({ ...config, temperature: meta.temperature! }));
+    updateConfig({ temperature: meta.temperature });
+  }
+};
+
 export const patternAPI = {
   ...createStorageAPI<Pattern>('patterns'),
 
   async loadPatterns() {
+    const progress = startProgress({ running: 'Loading patterns', done: 'Patterns loaded', failed: 'Failed to load patterns' });
     try {
       // First load pattern descriptions
       const descriptionsResponse = await fetch('/data/pattern_descriptions.json');
       const descriptionsData = await descriptionsResponse.json();
-      const descriptions = descriptionsData.patterns as PatternDescription[];
-      console.log("Loaded pattern descriptions:", descriptions.length);
+      patternDescriptions = descriptionsData.patterns as PatternDescription[];
+      console.log("Loaded pattern descriptions:", patternDescriptions.length);
 
-      // Then load pattern names and contents
-      const response = await fetch(`/api/patterns/names`);
-      const data = await response.json();
-      console.log("Load Patterns:", data);
-      console.log("Loading patterns from API...");
-
-      // Create an array of promises to fetch all pattern contents
-      const patternsPromises = data.map(async (pattern: string) => {
-        try {
-          console.log(`Loading pattern: ${pattern}`);
-          const patternResponse = await fetch(`/api/patterns/${pattern}`);
-          const patternData = await patternResponse.json();
-          console.log(`Pattern ${pattern} content length:`, patternData.Pattern?.length || 0);
-
-          // Find matching description from JSON
-          const desc = descriptions.find(d => d.patternName === pattern);
-          if (!desc) {
-            console.warn(`No description found for pattern: ${pattern}`);
-          }
+      try {
+        const metadataResponse = await fetch('/api/patterns/metadata');
+        const metadata = (await metadataResponse.json()) as PatternMetadata[] | null;
+        patternMetadata = new Map((metadata || []).map(m => [m.name, m]));
+      } catch (error) {
+        console.warn('Failed to load pattern metadata:', error);
+      }
 
-          return {
-            Name: pattern,
-            Description: desc?.description || pattern.charAt(0).toUpperCase() + pattern.slice(1),
-            Pattern: patternData.Pattern || "",
-            tags: desc?.tags || []  // Add tags from description
-          };
-        } catch (error) {
-          console.error(`Failed to load pattern ${pattern}:`, error);
-          // Still try to get description even if pattern content fails
-          const desc = descriptions.find(d => d.patternName === pattern);
-          return {
-            Name: pattern,
-            Description: desc?.description || pattern.charAt(0).toUpperCase() + pattern.slice(1),
-            Pattern: "",
-            tags: desc?.tags || []  // Add tags here too for consistency
-          };
-        }
-      });
+      // Then load all patterns with their contents in one request, the server reads them concurrently
+      const response = await fetch(`/api/patterns/all`);
+      const data = await response.json();
+      const stats = data.stats;
+      console.log(`Loaded ${stats.patterns} patterns in ${stats.durationMs}ms with ${stats.workers} workers (${stats.failed} failed)`);
 
-      // Wait for all pattern contents to be fetched
-      const loadedPatterns = await Promise.all(patternsPromises);
-      console.log("Patterns with content:", loadedPatterns);
+      const loadedPatterns: Pattern[] = (data.patterns || []).map(
+        (pattern: { Name: string; Pattern: string; Meta?: PatternMeta; Source?: string }) =>
+          toPattern(pattern.Name, pattern.Pattern, pattern.Meta, pattern.Source)
+      );
       allPatterns.set(loadedPatterns);
+      progress.done(`${loadedPatterns.length} patterns loaded`);
       return loadedPatterns;
     } catch (error) {
       console.error('Failed to load patterns:', error);
+      progress.fail(error);
       allPatterns.set([]);
       return [];
     }
   },
 
+  // Applies the patterns changed on the server as they are edited, returns a function to stop watching
+  watchChanges(): () => void {
+    const events = new EventSource('/api/patterns/events');
+    events.addEventListener('patterns', (event) => {
+      const changes = JSON.parse((event as MessageEvent).data) as PatternChange[];
+      allPatterns.update(current => {
+        let updated = current;
+        for (const change of changes) {
+          updated = updated.filter(p => p.Name !== change.name);
+          if (change.type !== 'removed' && change.pattern) {
+            updated.push(toPattern(change.name, change.pattern.Pattern, change.pattern.Meta, change.pattern.Source));
+          }
+        }
+        return updated.sort((a, b) => a.Name.localeCompare(b.Name));
+      });
+
+      // keep the system prompt of the selected pattern in sync with its edits
+      const selected = changes.find(c => c.name === get(selectedPatternName));
+      if (selected?.type === 'modified' && selected.pattern) {
+        setSystemPrompt(selected.pattern.Pattern);
+        applyPatternMeta(selected.pattern.Meta);
+      }
+      console.log('Applied pattern changes:', changes.map(c => `${c.type} ${c.name}`));
+    });
+    return () => events.close();
+  },
+
+  // Saves the system prompt of a pattern, the body is the raw prompt
+  async saveContent(patternName: string, content: string) {
+    const response = await fetch(`/api/patterns/${encodeURIComponent(patternName)}`, {
+      method: 'POST',
+      headers: { 'Content-Type': 'text/plain' },
+      body: content,
+    });
+    if (!response.ok) throw new Error(`Failed to save pattern ${patternName}: ${response.statusText}`);
+    // a new pattern is listed at once, before the pattern events tell
+    allPatterns.update(current =>
+      current.some(p => p.Name === patternName)
+        ? current.map(p => (p.Name === patternName ? toPattern(p.Name, content, p.Meta, p.Source) : p))
+        : [...current, toPattern(patternName, content)].sort((a, b) => a.Name.localeCompare(b.Name))
+    );
+    if (get(selectedPatternName) === patternName) setSystemPrompt(content);
+  },
+
+  // The user prompt of the pattern, its user.md, empty when it has none
+  async loadUserPrompt(patternName: string): Promise<string> {
+    const response = await fetch(`/api/patterns/${encodeURIComponent(patternName)}/user`);
+    const body = await response.json().catch(() => ({}));
+    if (!response.ok) throw new Error(body.error || response.statusText);
+    return body.content ?? '';
+  },
+
+  // Saves the user prompt of the pattern, an empty one removes its user.md
+  async saveUserPrompt(patternName: string, content: string) {
+    const response = await fetch(`/api/patterns/${encodeURIComponent(patternName)}/user`, {
+      method: 'PUT',
+      headers: { 'Content-Type': 'text/plain' },
+      body: content,
+    });
+    if (!response.ok) {
+      const body = await response.json().catch(() => ({}));
+      throw new Error(body.error || `Failed to save the user prompt of ${patternName}: ${response.statusText}`);
+    }
+  },
+
+  // Moves the pattern to the trash of the server, it can be restored from the history page
+  async trash(patternName: string) {
+    const response = await fetch(`/api/patterns/${encodeURIComponent(patternName)}`, { method: 'DELETE' });
+    if (!response.ok) {
+      const body = await response.json().catch(() => ({}));
+      throw new Error(body.error || response.statusText);
+    }
+    allPatterns.update(current => current.filter(p => p.Name !== patternName));
+  },
+
+  // Opens the pattern directory in the file manager of the machine running the server
+  async reveal(patternName: string) {
+    const response = await fetch(`/api/patterns/${encodeURIComponent(patternName)}/reveal`, { method: 'POST' });
+    if (!response.ok) {
+      const body = await response.json().catch(() => ({}));
+      throw new Error(body.error || response.statusText);
+    }
+  },
+
+  // The extra files of the pattern directory, like examples, images or data
+  async getAttachments(patternName: string): Promise<PatternAttachment[]> {
+    const response = await fetch(`/api/patterns/${encodeURIComponent(patternName)}/attachments`);
+    const body = await response.json().catch(() => ({}));
+    if (!response.ok) throw new Error(body.error || response.statusText);
+    return body as PatternAttachment[];
+  },
+
+  attachmentUrl(patternName: string, file: string): string {
+    return `/api/patterns/${encodeURIComponent(patternName)}/attachment?file=${encodeURIComponent(file)}`;
+  },
+
+  // Opens the attachment with the default app of the machine running the server
+  async openAttachment(patternName: string, file: string) {
+    const response = await fetch(
+      `/api/patterns/${encodeURIComponent(patternName)}/attachment/open?file=${encodeURIComponent(file)}`,
+      { method: 'POST' }
+    );
+    if (!response.ok) {
+      const body = await response.json().catch(() => ({}));
+      throw new Error(body.error || response.statusText);
+    }
+  },
+
   selectPattern(patternName: string) {
     const patterns = get(allPatterns);
     console.log('Selecting pattern:', patternName);
@@ -113,6 +245,7 @@ export const patternAPI = {
       console.log(`Setting system prompt with content length: ${selectedPattern.Pattern.length}`);
       console.log(`Content preview:`, selectedPattern.Pattern.substring(0, 100));
       setSystemPrompt(selectedPattern.Pattern);
+      applyPatternMeta(selectedPattern.Meta);
       selectedPatternName.set(patternName);  // Make sure this is set before setting system prompt
     } else {
       console.log('No pattern found for name:', patternName);
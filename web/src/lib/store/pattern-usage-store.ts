@@ -0,0 +1,59 @@
+import { writable } from 'svelte/store';
+import { historyAPI, type PatternUsage } from '$lib/api/history';
+import type { PatternSort } from '$lib/store/pattern-list-store';
+
+// The patterns in the quick-access row
+export const QUICK_ACCESS_COUNT = 5;
+
+// How often and when each pattern was run, from the run history so the runs of the CLI count too
+const createUsageStore = () => {
+  const { subscribe, set, update } = writable<Record<string, PatternUsage>>({});
+
+  return {
+    subscribe,
+    load: async () => {
+      try {
+        set(await historyAPI.getPatternUsage());
+      } catch (error) {
+        console.error('Failed to load the pattern usage:', error);
+      }
+    },
+    // counts a run of the web UI without waiting for the next load
+    record: (patternName: string) => {
+      update(usage => ({
+        ...usage,
+        [patternName]: { runs: (usage[patternName]?.runs ?? 0) + 1, lastRun: new Date().toISOString() }
+      }));
+    }
+  };
+};
+
+export const patternUsage = createUsageStore();
+
+const lastRunTime = (usage?: PatternUsage) => (usage ? Date.parse(usage.lastRun) : 0);
+
+// Sorts the patterns, the ones never run keep their order after the others
+export function sortPatterns<T extends { Name: string }>(
+  patterns: T[],
+  sort: PatternSort,
+  usage: Record<string, PatternUsage>
+): T[] {
+  if (sort === 'name') return patterns;
+  return [...patterns].sort((a, b) => {
+    const ua = usage[a.Name];
+    const ub = usage[b.Name];
+    if (sort === 'used' && (ub?.runs ?? 0) !== (ua?.runs ?? 0)) return (ub?.runs ?? 0) - (ua?.runs ?? 0);
+    return lastRunTime(ub) - lastRunTime(ua);
+  });
+}
+
+// The names of the most run patterns among the given ones, most run first
+export function topPatterns(names: string[], usage: Record<string, PatternUsage>, count = QUICK_ACCESS_COUNT): string[] {
+  return sortPatterns(
+    names.filter(name => usage[name]?.runs).map(name => ({ Name: name })),
+    'used',
+    usage
+  )
+    .slice(0, count)
+    .map(pattern => pattern.Name);
+}
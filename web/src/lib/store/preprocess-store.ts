@@ -0,0 +1,38 @@
+import { writable } from 'svelte/store';
+import { preprocessSteps, type PreprocessSetting } from '$lib/utils/preprocess';
+
+const STORAGE_KEY = 'preprocessSteps';
+
+const defaultSettings: PreprocessSetting[] = preprocessSteps.map(({ step }) => ({ step, enabled: false }));
+
+// the stored order, without the steps no longer known and with the new ones at the end
+function load(): PreprocessSetting[] {
+  if (typeof localStorage === 'undefined') return defaultSettings;
+  try {
+    const stored = (JSON.parse(localStorage.getItem(STORAGE_KEY) ?? '[]') as PreprocessSetting[])
+      .filter(setting => defaultSettings.some(({ step }) => step === setting.step));
+    return [...stored, ...defaultSettings.filter(({ step }) => !stored.some(setting => setting.step === step))];
+  } catch {
+    return defaultSettings;
+  }
+}
+
+// The preprocessing pipeline run on the input of every input source before it is sent
+export const preprocessSettings = writable<PreprocessSetting[]>(load());
+
+preprocessSettings.subscribe(settings => {
+  if (typeof localStorage !== 'undefined') {
+    localStorage.setItem(STORAGE_KEY, JSON.stringify(settings));
+  }
+});
+
+// Moves the step at the index up (-1) or down (1) the pipeline
+export function moveStep(index: number, offset: -1 | 1) {
+  preprocessSettings.update(settings => {
+    const target = index + offset;
+    if (target < 0 || target >= settings.length) return settings;
+    const moved = [...settings];
+    [moved[index], moved[target]] = [moved[target], moved[index]];
+    return moved;
+  });
+}
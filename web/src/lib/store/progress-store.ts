@@ -0,0 +1,81 @@
+import { writable } from 'svelte/store';
+
+// How often a long operation tells it is still running, and how long it may run before it tells at all,
+// so the quick ones only announce their outcome
+export const PROGRESS_INTERVAL_MS = 10_000;
+export const PROGRESS_DELAY_MS = 2_000;
+
+// A text read by the screen readers: polite ones wait for the reader to be idle, assertive ones interrupt
+// it, for the failures
+export interface Announcement {
+  id: number;
+  text: string;
+  politeness: 'polite' | 'assertive';
+}
+
+export const announcement = writable<Announcement | null>(null);
+
+let nextId = 1;
+
+export function announce(text: string, politeness: Announcement['politeness'] = 'polite') {
+  announcement.set({ id: nextId++, text, politeness });
+}
+
+// What is announced of an operation: while it runs, once it is done and once it failed
+export interface ProgressLabels {
+  running: string; // like "Loading patterns"
+  done: string; // like "Patterns loaded"
+  failed: string; // like "Failed to load patterns"
+}
+
+export interface Progress {
+  // Sets the detail added to the next announcements, like the size of the output so far
+  update(detail: string): void;
+  // Ends the operation, announcing the done label or the text given
+  done(text?: string): void;
+  // Ends the operation, announcing the failed label and the error
+  fail(error?: unknown): void;
+}
+
+// Announces a long operation: after PROGRESS_DELAY_MS, then every PROGRESS_INTERVAL_MS with the time it
+// has been running, until it is done or failed. A quiet operation, like a background refresh, only
+// announces its failure.
+export function startProgress(labels: ProgressLabels, quiet = false): Progress {
+  const started = Date.now();
+  let detail = '';
+  let ended = false;
+
+  const tell = () => {
+    const seconds = Math.round((Date.now() - started) / 1000);
+    announce(`${labels.running}, ${seconds} second${seconds === 1 ? '' : 's'}${detail ? `, ${detail}` : ''}`);
+  };
+  let interval: ReturnType<typeof setInterval> | undefined;
+  const delay = quiet
+    ? undefined
+    : setTimeout(() => {
+        tell();
+        interval = setInterval(tell, PROGRESS_INTERVAL_MS);
+      }, PROGRESS_DELAY_MS);
+
+  const end = () => {
+    if (ended) return false;
+    ended = true;
+    clearTimeout(delay);
+    clearInterval(interval);
+    return true;
+  };
+
+  return {
+    update(text: string) {
+      detail = text;
+    },
+    done(text?: string) {
+      if (end() && !quiet) announce(text ?? labels.done);
+    },
+    fail(error?: unknown) {
+      if (!end()) return;
+      const reason = error instanceof Error ? error.message : error ? String(error) : '';
+      announce(reason ? `${labels.failed}: ${reason}` : labels.failed, 'assertive');
+    }
+  };
+}
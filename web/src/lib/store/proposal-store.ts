@@ -0,0 +1,22 @@
+import { writable } from 'svelte/store';
+import { proposalsAPI, type Proposal } from '$lib/api/proposals';
+
+// How often the queue is checked for runs proposed by other tools
+const POLL_INTERVAL_MS = 15000;
+
+export const proposals = writable<Proposal[]>([]);
+
+export async function loadProposals() {
+  try {
+    proposals.set(await proposalsAPI.list());
+  } catch (error) {
+    console.error('Failed to load proposed runs:', error);
+  }
+}
+
+// Keeps the proposals up to date, returns a function to stop
+export function watchProposals(): () => void {
+  loadProposals();
+  const timer = setInterval(loadProposals, POLL_INTERVAL_MS);
+  return () => clearInterval(timer);
+}
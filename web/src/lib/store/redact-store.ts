@@ -0,0 +1,40 @@
+import { writable } from 'svelte/store';
+
+const STORAGE_KEY = 'redactSettings';
+
+// The built-in redaction rules of the server
+export const redactRules: [string, string][] = [
+  ['api-key', 'API keys'], ['aws-access-key', 'AWS access keys'], ['aws-secret-key', 'AWS secret keys'],
+  ['private-key', 'Private keys'], ['email', 'Emails']
+];
+
+// Rules are the enabled built-in rules, patterns the custom regexes, one per line
+export interface RedactSettings {
+  enabled: boolean;
+  rules: string[];
+  patterns: string;
+}
+
+const defaultSettings: RedactSettings = { enabled: false, rules: redactRules.map(([rule]) => rule), patterns: '' };
+
+function load(): RedactSettings {
+  if (typeof localStorage === 'undefined') return defaultSettings;
+  try {
+    return { ...defaultSettings, ...JSON.parse(localStorage.getItem(STORAGE_KEY) ?? '{}') };
+  } catch {
+    return defaultSettings;
+  }
+}
+
+// Scrubs secrets from the input before it is sent, kept between sessions
+export const redactSettings = writable<RedactSettings>(load());
+
+redactSettings.subscribe(settings => {
+  if (typeof localStorage !== 'undefined') {
+    localStorage.setItem(STORAGE_KEY, JSON.stringify(settings));
+  }
+});
+
+export function splitPatterns(patterns: string): string[] {
+  return patterns.split('\n').map(pattern => pattern.trim()).filter(Boolean);
+}
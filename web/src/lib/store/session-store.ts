@@ -1,4 +1,4 @@
-import { createStorageAPI } from '$lib/api/base';
+import { api, createStorageAPI } from '$lib/api/base';
 import type { Session } from '$lib/interfaces/session-interface';
 import type { Message } from '$lib/interfaces/chat-interface';
 import { get, writable } from 'svelte/store';
@@ -51,6 +51,21 @@ export const sessionAPI = {
   },
 
 
+  // Adds the messages to the session on the server, creating it when missing
+  async appendMessages(name: string, messages: Pick<Message, 'role' | 'content'>[]): Promise<void> {
+    const response = await api.post(`/sessions/${encodeURIComponent(name)}/messages`, { messages });
+    if (response.error) throw new Error(response.error);
+  },
+
+  // The conversation of a session on the server, its system and meta messages left out
+  async loadConversation(name: string): Promise<Message[]> {
+    const response = await api.get<{ Messages: Message[] | null }>(`/sessions/${encodeURIComponent(name)}`);
+    if (response.error) throw new Error(response.error);
+    return (response.data?.Messages ?? [])
+      .filter(message => message.role === 'user' || message.role === 'assistant')
+      .map(message => ({ role: message.role, content: message.content }));
+  },
+
   selectSession(sessionName: string) {
     const allSessions = get(sessions);
     const selectedSession = allSessions.find(session => session.Name === sessionName);
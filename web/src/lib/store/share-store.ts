@@ -0,0 +1,29 @@
+import { writable } from 'svelte/store';
+
+const STORAGE_KEY = 'shareSettings';
+
+// The GitHub token creating the gists, it needs the gist scope. When empty, the server uses its
+// GITHUB_TOKEN.
+export interface ShareSettings {
+  githubToken: string;
+}
+
+const defaultSettings: ShareSettings = { githubToken: '' };
+
+function load(): ShareSettings {
+  if (typeof localStorage === 'undefined') return defaultSettings;
+  try {
+    return { ...defaultSettings, ...JSON.parse(localStorage.getItem(STORAGE_KEY) ?? '{}') };
+  } catch {
+    return defaultSettings;
+  }
+}
+
+// Kept between sessions
+export const shareSettings = writable<ShareSettings>(load());
+
+shareSettings.subscribe(settings => {
+  if (typeof localStorage !== 'undefined') {
+    localStorage.setItem(STORAGE_KEY, JSON.stringify(settings));
+  }
+});
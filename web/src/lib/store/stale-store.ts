@@ -0,0 +1,82 @@
+import { writable, derived, get } from 'svelte/store';
+import { modelConfig } from '$lib/store/model-store';
+import { chatConfig } from '$lib/store/chat-config';
+import { systemPrompt, selectedPatternName, patternVariables } from '$lib/store/pattern-store';
+import { selectedStrategy } from '$lib/store/strategy-store';
+import { selectedContext } from '$lib/store/context-store';
+import { languageStore } from '$lib/store/language-store';
+
+// What an output was produced from, compared with the current settings to tell when it no longer
+// matches them
+export interface RunInputs {
+  pattern: string;
+  prompt: string; // the text of the pattern, changed by editing it
+  model: string;
+  parameters: string; // the model parameters, serialized to be compared
+  strategy: string;
+  context: string;
+  variables: string;
+  language: string;
+}
+
+// The last request sent, kept to tell whether its output is stale and to run it again
+export interface LastRun {
+  input: string;
+  systemPromptText?: string;
+  images?: string[];
+  inputs: RunInputs;
+}
+
+export const lastRun = writable<LastRun | null>(null);
+
+// The text typed in the chat input, a new input makes the output stale
+export const draftInput = writable<string>('');
+
+function currentInputs(): RunInputs {
+  return {
+    pattern: get(selectedPatternName),
+    prompt: get(systemPrompt),
+    model: get(modelConfig).model,
+    parameters: JSON.stringify(get(chatConfig)),
+    strategy: get(selectedStrategy),
+    context: get(selectedContext),
+    variables: JSON.stringify(get(patternVariables)),
+    language: get(languageStore)
+  };
+}
+
+export function recordRun(input: string, systemPromptText?: string, images?: string[]) {
+  lastRun.set({ input, systemPromptText, images, inputs: currentInputs() });
+}
+
+// The changes since the last run, empty while its output is current
+export const staleReasons = derived(
+  [lastRun, draftInput, selectedPatternName, systemPrompt, modelConfig, chatConfig, selectedStrategy, selectedContext, patternVariables, languageStore],
+  ([$lastRun, $draftInput]) => {
+    if (!$lastRun) return [];
+    const now = currentInputs();
+    const was = $lastRun.inputs;
+    const reasons: string[] = [];
+    if ($draftInput.trim() && $draftInput.trim() !== $lastRun.input.trim()) reasons.push('input');
+    if (now.pattern !== was.pattern) reasons.push('pattern');
+    else if (now.prompt !== was.prompt) reasons.push('pattern text');
+    if (now.model !== was.model) reasons.push('model');
+    if (now.parameters !== was.parameters) reasons.push('parameters');
+    if (now.strategy !== was.strategy) reasons.push('strategy');
+    if (now.context !== was.context) reasons.push('context');
+    if (now.variables !== was.variables) reasons.push('variables');
+    if (now.language !== was.language) reasons.push('language');
+    return reasons;
+  }
+);
+
+// The system prompt to run the last input again with the current pattern, keeping the instructions added
+// to the pattern for attached files
+export function rerunPrompt(run: LastRun): string | undefined {
+  const current = get(systemPrompt);
+  if (run.systemPromptText === undefined) return undefined;
+  if (run.systemPromptText.startsWith(run.inputs.prompt)) {
+    return current + run.systemPromptText.slice(run.inputs.prompt.length);
+  }
+  return current;
+}
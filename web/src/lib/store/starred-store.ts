@@ -0,0 +1,12 @@
+import { writable } from 'svelte/store';
+import { starredAPI, type StarredOutput } from '$lib/api/starred';
+
+export const starredOutputs = writable<StarredOutput[]>([]);
+
+export async function loadStarred() {
+  try {
+    starredOutputs.set(await starredAPI.list());
+  } catch (error) {
+    console.error('Failed to load starred outputs:', error);
+  }
+}
@@ -18,7 +18,7 @@ export const selectedStrategy = writable<string>("");
  */
 export async function fetchStrategies() {
   try {
-    const response = await fetch('/strategies/strategies.json');
+    const response = await fetch('/api/strategies');
     if (!response.ok) {
       console.error('Failed to fetch strategies:', response.statusText);
       return;
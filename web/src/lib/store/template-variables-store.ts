@@ -0,0 +1,68 @@
+import { get, writable } from 'svelte/store';
+
+const STORAGE_KEY = 'templateVariables';
+// recent values kept per variable of each pattern
+const MAX_RECENT = 5;
+
+// Recent values by pattern name, then variable name, most recent first. Inputs without a pattern use ''.
+type RecentValues = Record<string, Record<string, string[]>>;
+
+const stored: RecentValues = typeof localStorage !== 'undefined'
+  ? JSON.parse(localStorage.getItem(STORAGE_KEY) || '{}')
+  : {};
+
+export const recentVariableValues = writable<RecentValues>(stored);
+
+function remember(patternName: string, values: Record<string, string>) {
+  recentVariableValues.update(recent => {
+    const forPattern = { ...(recent[patternName] || {}) };
+    for (const [name, value] of Object.entries(values)) {
+      if (!value) continue;
+      forPattern[name] = [value, ...(forPattern[name] || []).filter(v => v !== value)].slice(0, MAX_RECENT);
+    }
+    const updated = { ...recent, [patternName]: forPattern };
+    if (typeof localStorage !== 'undefined') {
+      localStorage.setItem(STORAGE_KEY, JSON.stringify(updated));
+    }
+    return updated;
+  });
+}
+
+export interface VariablesRequest {
+  patternName: string;
+  names: string[];
+  // prefilled values: those already given, the defaults of the pattern or the most recent ones
+  values: Record<string, string>;
+  resolve: (values: Record<string, string> | null) => void;
+}
+
+// The open form, resolved with the filled values or null when cancelled
+export const variablesRequest = writable<VariablesRequest | null>(null);
+
+// Asks for the values of the variables, prefilled from the given ones then the most recent ones.
+// Resolves with the values, remembered for the pattern, or null when the form is cancelled.
+export function requestVariables(
+  patternName: string,
+  names: string[],
+  given: Record<string, string>
+): Promise<Record<string, string> | null> {
+  if (names.length === 0) {
+    return Promise.resolve({});
+  }
+  const recent = get(recentVariableValues);
+  const values = Object.fromEntries(
+    names.map(name => [name, given[name] ?? recent[patternName]?.[name]?.[0] ?? ''])
+  );
+  return new Promise(resolve => {
+    variablesRequest.set({
+      patternName,
+      names,
+      values,
+      resolve: (filled: Record<string, string> | null) => {
+        variablesRequest.set(null);
+        if (filled) remember(patternName, filled);
+        resolve(filled);
+      }
+    });
+  });
+}
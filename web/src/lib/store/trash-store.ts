@@ -0,0 +1,12 @@
+import { writable } from 'svelte/store';
+import { trashAPI, type TrashedItem } from '$lib/api/trash';
+
+export const trashedItems = writable<TrashedItem[]>([]);
+
+export async function loadTrash() {
+  try {
+    trashedItems.set(await trashAPI.list());
+  } catch (error) {
+    console.error('Failed to load the trash:', error);
+  }
+}
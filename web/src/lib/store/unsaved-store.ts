@@ -0,0 +1,48 @@
+import { get, writable } from 'svelte/store';
+
+// What discards the work: closing the app, leaving the page or replacing the chat session
+export type LossTrigger = 'unload' | 'navigate' | 'session';
+
+// Work that isn't saved yet, listed in the confirmation before it is discarded
+export interface UnsavedItem {
+  id: string;
+  label: string;
+  lostOn: LossTrigger[];
+  // saves or exports the work, when it can be
+  save?: { label: string; run: () => Promise<void> };
+}
+
+const items = writable<UnsavedItem[]>([]);
+
+export const unsavedChanges = {
+  subscribe: items.subscribe,
+  mark: (item: UnsavedItem) => {
+    items.update(current => [...current.filter(i => i.id !== item.id), item]);
+  },
+  clear: (id: string) => {
+    items.update(current => current.filter(i => i.id !== id));
+  }
+};
+
+export function unsavedFor(trigger: LossTrigger): UnsavedItem[] {
+  return get(items).filter(item => item.lostOn.includes(trigger));
+}
+
+// The open confirmation, resolved with true to discard the work and go on
+export const discardRequest = writable<{ trigger: LossTrigger; resolve: (discard: boolean) => void } | null>(null);
+
+// Asks for confirmation when the trigger would discard unsaved work, resolves with whether to go on
+export function confirmDiscard(trigger: LossTrigger): Promise<boolean> {
+  if (unsavedFor(trigger).length === 0) {
+    return Promise.resolve(true);
+  }
+  return new Promise(resolve => {
+    discardRequest.set({
+      trigger,
+      resolve: (discard: boolean) => {
+        discardRequest.set(null);
+        resolve(discard);
+      }
+    });
+  });
+}
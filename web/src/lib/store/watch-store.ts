@@ -0,0 +1,21 @@
+import { writable } from 'svelte/store';
+import { watchesAPI, type Watch, type WatchEvent } from '$lib/api/watches';
+
+export const watches = writable<Watch[]>([]);
+export const watchActivity = writable<WatchEvent[]>([]);
+
+export async function loadWatches() {
+  try {
+    watches.set(await watchesAPI.list());
+  } catch (error) {
+    console.error('Failed to load watches:', error);
+  }
+}
+
+export async function loadWatchActivity() {
+  try {
+    watchActivity.set(await watchesAPI.activity());
+  } catch (error) {
+    console.error('Failed to load the watch activity:', error);
+  }
+}
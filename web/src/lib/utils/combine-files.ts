@@ -0,0 +1,22 @@
+export interface NamedContent {
+  name: string;
+  content: string;
+}
+
+export const DEFAULT_SEPARATOR = '\\n\\n';
+export const DEFAULT_HEADER = '==> {{name}} <==';
+
+// Expands the escapes \n, \t and \\ of separators and headers typed into a text field
+export function unescape(value: string): string {
+  return value.replace(/\\([nt\\])/g, (_, c) => (c === 'n' ? '\n' : c === 't' ? '\t' : '\\'));
+}
+
+// Joins the files with the separator, each preceded by the header when there is more than one file.
+// {{name}} in the header is replaced by the file name, like the --input-header flag of the CLI.
+export function combineFiles(files: NamedContent[], separator: string, header: string): string {
+  if (files.length === 1) return files[0].content;
+  const head = unescape(header);
+  return files
+    .map(f => (head ? `${head.split(/\{\{(?:name|path)\}\}/).join(f.name)}\n${f.content}` : f.content))
+    .join(unescape(separator));
+}
@@ -0,0 +1,39 @@
+import type { PatternMeta } from '$lib/interfaces/pattern-interface';
+import type { PatternRunStats } from '$lib/api/history';
+import { contextWindow, supportsJson, supportsTools, supportsVision } from './model-limits';
+
+// Below this many runs the failures of a pattern with a model say nothing of their compatibility
+const MIN_RUNS = 3;
+// From this share of failed runs the pattern is likely to fail with the model
+const FAILURE_RATE = 0.5;
+
+// Returns why the pattern is likely to fail with the model: the capabilities and context window its meta
+// requires that the model is known to lack, and its failures with the model in the run history
+export function compatibilityWarnings(
+  model: string,
+  meta: PatternMeta | undefined,
+  stats: PatternRunStats | undefined
+): string[] {
+  const warnings: string[] = [];
+  if (!model) return warnings;
+
+  for (const capability of meta?.requires ?? []) {
+    if (capability === 'tools' && supportsTools(model) === false) {
+      warnings.push(`it needs function calling, which ${model} doesn't support`);
+    } else if (capability === 'json' && supportsJson(model) === false) {
+      warnings.push(`it needs a JSON output format, which ${model} doesn't support`);
+    } else if (capability === 'vision' && !supportsVision(model)) {
+      warnings.push(`it reads images, which ${model} isn't known to do`);
+    }
+  }
+  const window = contextWindow(model);
+  if (meta?.minContext && window !== undefined && window < meta.minContext) {
+    warnings.push(
+      `it needs a context window of ${meta.minContext.toLocaleString()} tokens, ${model} has ${window.toLocaleString()}`
+    );
+  }
+  if (stats && stats.runs >= MIN_RUNS && stats.failed / stats.runs >= FAILURE_RATE) {
+    warnings.push(`${stats.failed} of its last ${stats.runs} runs with ${model} failed or were cut short`);
+  }
+  return warnings;
+}
@@ -0,0 +1,58 @@
+import { marked } from 'marked';
+
+// The formats an output is copied as, each target takes another: editors markdown, chat apps plain text,
+// email clients HTML
+export type CopyFormat = 'markdown' | 'text' | 'html';
+
+export function markdownToHtml(markdown: string): string {
+  return marked.parse(markdown, { gfm: true, breaks: true, async: false }) as string;
+}
+
+// Strips the markdown syntax, keeping the text, the list items and the targets of the links
+export function markdownToPlainText(markdown: string): string {
+  const fences: string[] = [];
+  return (
+    markdown
+      // the code of the fenced blocks is kept as is
+      .replace(/^(```|~~~)[^\n]*\n([\s\S]*?)^\1[ \t]*$/gm, (_, _fence, code: string) => {
+        fences.push(code.replace(/\n$/, ''));
+        return `\u0000${fences.length - 1}\u0000`;
+      })
+      .replace(/^ {0,3}#{1,6}[ \t]+(.*?)[ \t#]*$/gm, '$1')
+      .replace(/^ {0,3}([-*_])([ \t]*\1){2,}[ \t]*$/gm, '')
+      .replace(/^ {0,3}>[ \t]?/gm, '')
+      .replace(/^([ \t]*)[*+][ \t]+/gm, '$1- ')
+      .replace(/^[ \t]*\|?[ \t]*:?-+:?[ \t]*(\|[ \t]*:?-+:?[ \t]*)*\|?[ \t]*$\n?/gm, '')
+      .replace(/!\[([^\]]*)\]\([^)]*\)/g, '$1')
+      .replace(/\[([^\]]+)\]\(([^)\s]+)[^)]*\)/g, (_, text: string, url: string) => (text === url ? url : `${text} (${url})`))
+      .replace(/`([^`\n]+)`/g, '$1')
+      .replace(/(\*\*|__)(?=\S)([\s\S]*?\S)\1/g, '$2')
+      .replace(/(^|[^\w*])([*_])(?=\S)([^*_\n]*?\S)\2(?!\w)/g, '$1$3')
+      .replace(/~~(?=\S)([\s\S]*?\S)~~/g, '$1')
+      .replace(/\u0000(\d+)\u0000/g, (_, index: string) => fences[Number(index)])
+      .replace(/\n{3,}/g, '\n\n')
+      .trim()
+  );
+}
+
+// Copies the markdown output in the format. HTML is copied with its plain text, for the targets not
+// taking HTML.
+export async function copyAs(markdown: string, format: CopyFormat): Promise<void> {
+  if (format === 'markdown') {
+    await navigator.clipboard.writeText(markdown);
+  } else if (format === 'text') {
+    await navigator.clipboard.writeText(markdownToPlainText(markdown));
+  } else {
+    const html = markdownToHtml(markdown);
+    if (typeof ClipboardItem === 'undefined') {
+      await navigator.clipboard.writeText(html);
+      return;
+    }
+    await navigator.clipboard.write([
+      new ClipboardItem({
+        'text/html': new Blob([html], { type: 'text/html' }),
+        'text/plain': new Blob([markdownToPlainText(markdown)], { type: 'text/plain' })
+      })
+    ]);
+  }
+}
@@ -0,0 +1,33 @@
+// The fields a saved output is named after
+export interface FileNameFields {
+  pattern?: string;
+  model?: string;
+}
+
+export const DEFAULT_FILE_NAME_TEMPLATE = '{pattern}-{date}-{model}';
+
+// Characters the file systems don't allow in the names of the files
+const forbidden = /[\\/:*?"<>|\u0000-\u001f]/g;
+
+const pad = (n: number) => String(n).padStart(2, '0');
+
+// Names a saved output after the template, without its extension: {pattern}, {model}, {date}
+// (YYYY-MM-DD), {time} (HHMMSS) and {timestamp} (YYYYMMDD-HHMMSS) are replaced, an extension ending the
+// template is dropped as the format of the export sets it
+export function outputFileName(template: string, fields: FileNameFields, now = new Date()): string {
+  const date = `${now.getFullYear()}-${pad(now.getMonth() + 1)}-${pad(now.getDate())}`;
+  const time = `${pad(now.getHours())}${pad(now.getMinutes())}${pad(now.getSeconds())}`;
+  const values: Record<string, string> = {
+    pattern: fields.pattern || 'fabric-output',
+    model: fields.model || 'model',
+    date,
+    time,
+    timestamp: `${date.replace(/-/g, '')}-${time}`
+  };
+  const name = (template.trim() || DEFAULT_FILE_NAME_TEMPLATE)
+    .replace(/\.(md|markdown|html|docx|pdf|json|txt)$/i, '')
+    .replace(/\{(\w+)\}/g, (token, key: string) => values[key] ?? token)
+    .replace(forbidden, '-')
+    .trim();
+  return name || values.pattern;
+}
@@ -31,6 +31,47 @@ export async function readFileAsJson<T>(file: File): Promise<T> {
   });
 }
 
+// Size limit of text input files, the same as the server's
+export const MAX_TEXT_FILE_BYTES = 10 * 1024 * 1024;
+
+// Reads a text file with the rules of the server: UTF-8 and UTF-16 are detected by their byte order
+// mark, content without one is UTF-8 if valid and Latin-1 otherwise, binary content is rejected.
+export async function readTextFile(file: File, maxBytes = MAX_TEXT_FILE_BYTES): Promise<string> {
+  if (file.size > maxBytes) {
+    throw new Error(`${file.name} has ${file.size} bytes, more than the limit of ${maxBytes} bytes`);
+  }
+
+  const data = new Uint8Array(await file.arrayBuffer());
+  if (data[0] === 0xef && data[1] === 0xbb && data[2] === 0xbf) {
+    return new TextDecoder('utf-8').decode(data.subarray(3));
+  }
+  if (data[0] === 0xff && data[1] === 0xfe) {
+    return new TextDecoder('utf-16le').decode(data.subarray(2));
+  }
+  if (data[0] === 0xfe && data[1] === 0xff) {
+    return new TextDecoder('utf-16be').decode(data.subarray(2));
+  }
+
+  if (isBinary(data)) {
+    throw new Error(`${file.name} is a binary file, only text files are supported`);
+  }
+  try {
+    return new TextDecoder('utf-8', { fatal: true }).decode(data);
+  } catch {
+    return new TextDecoder('latin1').decode(data);
+  }
+}
+
+function isBinary(data: Uint8Array): boolean {
+  const sample = data.subarray(0, 8000);
+  let control = 0;
+  for (const b of sample) {
+    if (b === 0) return true;
+    if (b < 0x20 && b !== 0x0a && b !== 0x0d && b !== 0x09 && b !== 0x0c && b !== 0x1b) control++;
+  }
+  return control * 10 > sample.length;
+}
+
 export async function saveToFile(data: any, filename: string): Promise<void> {
   const blob = new Blob([JSON.stringify(data, null, 2)], { type: 'application/json' });
   const url = URL.createObjectURL(blob);
@@ -42,3 +83,13 @@ export async function saveToFile(data: any, filename: string): Promise<void> {
   document.body.removeChild(a);
   URL.revokeObjectURL(url);
 }
+
+// Reads the file as a data URL, e.g. to send an image to a vision model
+export function readDataURL(file: Blob): Promise<string> {
+  return new Promise((resolve, reject) => {
+    const reader = new FileReader();
+    reader.onload = () => resolve(reader.result as string);
+    reader.onerror = () => reject(reader.error ?? new Error('Could not read the file'));
+    reader.readAsDataURL(file);
+  });
+}
@@ -0,0 +1,133 @@
+// Elements that are never content
+const DROPPED = new Set(['SCRIPT', 'STYLE', 'NOSCRIPT', 'TEMPLATE', 'HEAD', 'META', 'LINK', 'IFRAME', 'SVG', 'CANVAS', 'BUTTON', 'INPUT', 'SELECT', 'FORM']);
+const BLOCKS = new Set(['P', 'DIV', 'SECTION', 'ARTICLE', 'MAIN', 'HEADER', 'FOOTER', 'ASIDE', 'NAV', 'FIGURE', 'FIGCAPTION', 'DL', 'DT', 'DD', 'ADDRESS']);
+
+// Tells whether pasted plain text is the source of an HTML page rather than prose
+export function looksLikeHtml(text: string): boolean {
+  const trimmed = text.trimStart().slice(0, 200).toLowerCase();
+  if (trimmed.startsWith('<!doctype html') || trimmed.startsWith('<html')) return true;
+  const tags = text.match(/<\/?(p|div|span|a|ul|ol|li|h[1-6]|table|tr|td|br|strong|em|img)\b[^>]*>/gi);
+  return (tags?.length ?? 0) >= 5;
+}
+
+// Converts rich HTML, like content copied from a web page, to markdown: headings, paragraphs, emphasis,
+// links, lists, quotes, code and tables are kept, scripts, styles and the markup noise are dropped
+export function htmlToMarkdown(html: string): string {
+  const doc = new DOMParser().parseFromString(html, 'text/html');
+  const markdown = convertChildren(doc.body, { listDepth: 0, pre: false });
+  return markdown
+    .replace(/[ \t]+\n/g, '\n')
+    .replace(/\n{3,}/g, '\n\n')
+    .trim();
+}
+
+interface Context {
+  listDepth: number;
+  pre: boolean;
+}
+
+function convertChildren(node: Node, context: Context): string {
+  return Array.from(node.childNodes).map(child => convert(child, context)).join('');
+}
+
+function convert(node: Node, context: Context): string {
+  if (node.nodeType === Node.TEXT_NODE) {
+    const text = node.textContent ?? '';
+    return context.pre ? text : text.replace(/\s+/g, ' ');
+  }
+  if (node.nodeType !== Node.ELEMENT_NODE) return '';
+
+  const element = node as HTMLElement;
+  const tag = element.tagName;
+  if (DROPPED.has(tag) || element.hidden || element.getAttribute('aria-hidden') === 'true') return '';
+
+  const inner = () => convertChildren(element, context);
+  switch (tag) {
+    case 'H1': case 'H2': case 'H3': case 'H4': case 'H5': case 'H6': {
+      const text = inline(inner());
+      return text ? `\n\n${'#'.repeat(Number(tag[1]))} ${text}\n\n` : '';
+    }
+    case 'BR':
+      return '\n';
+    case 'HR':
+      return '\n\n---\n\n';
+    case 'STRONG': case 'B':
+      return wrap(inner(), '**');
+    case 'EM': case 'I':
+      return wrap(inner(), '_');
+    case 'DEL': case 'S':
+      return wrap(inner(), '~~');
+    case 'CODE':
+      return context.pre ? inner() : wrap(element.textContent ?? '', '`');
+    case 'PRE': {
+      const code = convertChildren(element, { ...context, pre: true }).replace(/\n+$/, '');
+      const language = /language-(\w+)/.exec(element.querySelector('code')?.className ?? '')?.[1] ?? '';
+      return `\n\n\`\`\`${language}\n${code}\n\`\`\`\n\n`;
+    }
+    case 'A': {
+      const text = inline(inner());
+      const href = element.getAttribute('href') ?? '';
+      if (!text) return '';
+      if (!href || href.startsWith('#') || href.startsWith('javascript:')) return text;
+      return `[${text}](${href})`;
+    }
+    case 'IMG': {
+      const alt = element.getAttribute('alt') ?? '';
+      const src = element.getAttribute('src') ?? '';
+      // images without a description are decoration for the patterns
+      return alt && src && !src.startsWith('data:') ? `![${alt}](${src})` : '';
+    }
+    case 'BLOCKQUOTE': {
+      const text = convertChildren(element, context).trim().replace(/\n{3,}/g, '\n\n');
+      return `\n\n${text.split('\n').map(line => `> ${line}`.trimEnd()).join('\n')}\n\n`;
+    }
+    case 'UL': case 'OL':
+      return `\n\n${list(element, context)}\n\n`;
+    case 'TABLE':
+      return `\n\n${table(element, context)}\n\n`;
+    default:
+      if (BLOCKS.has(tag)) return `\n\n${inner()}\n\n`;
+      return inner();
+  }
+}
+
+function list(element: HTMLElement, context: Context): string {
+  const ordered = element.tagName === 'OL';
+  const indent = '  '.repeat(context.listDepth);
+  let n = Number(element.getAttribute('start') ?? 1);
+  const items: string[] = [];
+  for (const child of Array.from(element.children)) {
+    if (child.tagName !== 'LI') continue;
+    const marker = ordered ? `${n++}.` : '-';
+    const text = convertChildren(child, { ...context, listDepth: context.listDepth + 1 })
+      .replace(/\n{2,}/g, '\n')
+      .trim();
+    items.push(`${indent}${marker} ${text}`);
+  }
+  return items.join('\n');
+}
+
+function table(element: HTMLElement, context: Context): string {
+  const rows = Array.from(element.querySelectorAll('tr')).map(row =>
+    Array.from(row.children).map(cell => inline(convertChildren(cell, context)).replace(/\|/g, '\\|'))
+  ).filter(cells => cells.length > 0);
+  if (rows.length === 0) return '';
+
+  const columns = Math.max(...rows.map(cells => cells.length));
+  const line = (cells: string[]) => `| ${Array.from({ length: columns }, (_, i) => cells[i] ?? '').join(' | ')} |`;
+  return [line(rows[0]), line(Array(columns).fill('---')), ...rows.slice(1).map(line)].join('\n');
+}
+
+// Collapses the text of an inline element to a single line
+function inline(text: string): string {
+  return text.replace(/\s+/g, ' ').trim();
+}
+
+// Emphasis markers have to touch the text, the surrounding spaces are kept outside
+function wrap(text: string, marker: string): string {
+  const trimmed = text.trim();
+  if (!trimmed) return text;
+  const leading = text.startsWith(' ') ? ' ' : '';
+  const trailing = text.endsWith(' ') ? ' ' : '';
+  return `${leading}${marker}${trimmed}${marker}${trailing}`;
+}
@@ -0,0 +1,147 @@
+// The subset of JSON Schema the outputs of the patterns are validated against: types, properties,
+// required, additionalProperties, items, enum, const and the bounds of strings, numbers and arrays
+export interface JsonSchema {
+  type?: string | string[];
+  properties?: Record<string, JsonSchema>;
+  required?: string[];
+  additionalProperties?: boolean | JsonSchema;
+  items?: JsonSchema;
+  enum?: unknown[];
+  const?: unknown;
+  minLength?: number;
+  maxLength?: number;
+  pattern?: string;
+  minimum?: number;
+  maximum?: number;
+  minItems?: number;
+  maxItems?: number;
+}
+
+export interface SchemaError {
+  path: string; // like $.items[2].name
+  message: string;
+}
+
+export type JsonValue = null | boolean | number | string | JsonValue[] | { [key: string]: JsonValue };
+
+export interface ParsedJson {
+  value?: JsonValue;
+  error?: string;
+  repaired: boolean; // the text was only parsed once repaired
+}
+
+function typeOf(value: unknown): string {
+  if (value === null) return 'null';
+  if (Array.isArray(value)) return 'array';
+  if (typeof value === 'number') return Number.isInteger(value) ? 'integer' : 'number';
+  return typeof value;
+}
+
+function matchesType(value: unknown, type: string): boolean {
+  const actual = typeOf(value);
+  return actual === type || (type === 'number' && actual === 'integer');
+}
+
+// The path of the property or item of the container at the path, like $.items[2].name
+export function jsonPath(path: string, key: string | number): string {
+  if (typeof key === 'number') return `${path}[${key}]`;
+  return /^[A-Za-z_$][\w$]*$/.test(key) ? `${path}.${key}` : `${path}[${JSON.stringify(key)}]`;
+}
+
+// Validates the value against the schema, returning every error found
+export function validateJson(value: unknown, schema: JsonSchema, path = '$'): SchemaError[] {
+  const errors: SchemaError[] = [];
+  const fail = (message: string) => errors.push({ path, message });
+
+  if (schema.type) {
+    const types = Array.isArray(schema.type) ? schema.type : [schema.type];
+    if (!types.some(type => matchesType(value, type))) {
+      fail(`expected ${types.join(' or ')}, got ${typeOf(value)}`);
+      return errors;
+    }
+  }
+  if (schema.enum && !schema.enum.some(option => JSON.stringify(option) === JSON.stringify(value))) {
+    fail(`expected one of ${schema.enum.map(option => JSON.stringify(option)).join(', ')}`);
+  }
+  if (schema.const !== undefined && JSON.stringify(schema.const) !== JSON.stringify(value)) {
+    fail(`expected ${JSON.stringify(schema.const)}`);
+  }
+
+  if (typeof value === 'string') {
+    if (schema.minLength !== undefined && value.length < schema.minLength) fail(`shorter than ${schema.minLength} characters`);
+    if (schema.maxLength !== undefined && value.length > schema.maxLength) fail(`longer than ${schema.maxLength} characters`);
+    if (schema.pattern !== undefined && !new RegExp(schema.pattern, 'u').test(value)) fail(`doesn't match ${schema.pattern}`);
+  } else if (typeof value === 'number') {
+    if (schema.minimum !== undefined && value < schema.minimum) fail(`less than ${schema.minimum}`);
+    if (schema.maximum !== undefined && value > schema.maximum) fail(`greater than ${schema.maximum}`);
+  } else if (Array.isArray(value)) {
+    if (schema.minItems !== undefined && value.length < schema.minItems) fail(`fewer than ${schema.minItems} items`);
+    if (schema.maxItems !== undefined && value.length > schema.maxItems) fail(`more than ${schema.maxItems} items`);
+    if (schema.items) {
+      value.forEach((item, i) => errors.push(...validateJson(item, schema.items!, jsonPath(path, i))));
+    }
+  } else if (value !== null && typeof value === 'object') {
+    const record = value as Record<string, unknown>;
+    for (const key of schema.required ?? []) {
+      if (!(key in record)) fail(`missing required property "${key}"`);
+    }
+    for (const [key, item] of Object.entries(record)) {
+      const propertyPath = jsonPath(path, key);
+      const propertySchema = schema.properties?.[key];
+      if (propertySchema) {
+        errors.push(...validateJson(item, propertySchema, propertyPath));
+      } else if (schema.additionalProperties === false) {
+        errors.push({ path: propertyPath, message: 'unexpected property' });
+      } else if (typeof schema.additionalProperties === 'object') {
+        errors.push(...validateJson(item, schema.additionalProperties, propertyPath));
+      }
+    }
+  }
+  return errors;
+}
+
+// Fixes what keeps the JSON of the models from parsing: the code fence around it, the text before and
+// after it, and the trailing commas
+export function repairJson(text: string): string {
+  let repaired = text.trim().replace(/^```[\w-]*\n([\s\S]*?)\n?```$/, '$1').trim();
+  const start = repaired.search(/[[{]/);
+  const end = Math.max(repaired.lastIndexOf('}'), repaired.lastIndexOf(']'));
+  if (start > 0 || (start >= 0 && end < repaired.length - 1)) {
+    repaired = repaired.slice(start, end + 1);
+  }
+  return repaired.replace(/,(\s*[}\]])/g, '$1');
+}
+
+// Parses the JSON, repairing it when it doesn't parse as is
+export function parseJson(text: string): ParsedJson {
+  try {
+    return { value: JSON.parse(text), repaired: false };
+  } catch (error) {
+    try {
+      return { value: JSON.parse(repairJson(text)), repaired: true };
+    } catch {
+      return { error: (error as Error).message, repaired: false };
+    }
+  }
+}
+
+// Tells whether the output is JSON, an object or an array, maybe fenced or surrounded by a sentence
+export function looksLikeJson(text: string): boolean {
+  const trimmed = repairJson(text);
+  if (!/^[[{]/.test(trimmed)) return false;
+  const parsed = parseJson(text);
+  return parsed.value !== undefined && parsed.value !== null && typeof parsed.value === 'object';
+}
+
+// Tells whether the whole output is a JSON object or array, maybe in a code fence, the outputs of the
+// patterns whose format is json are checked with parseJson instead
+export function isJsonOutput(text: string): boolean {
+  const unfenced = text.trim().replace(/^```(?:json)?\s*\n/, '').replace(/\n?```$/, '').trim();
+  if (!/^[[{]/.test(unfenced)) return false;
+  try {
+    JSON.parse(unfenced);
+    return true;
+  } catch {
+    return false;
+  }
+}
@@ -0,0 +1,94 @@
+// The mark of a line of the edited text in the diff gutter, like the gutters of code editors
+export type LineMark = 'unchanged' | 'added' | 'modified';
+
+export interface LineDiff {
+  added: number; // lines of the edited text not in the original
+  removed: number; // lines of the original not in the edited text
+  marks: LineMark[]; // for every line of the edited text
+  // for every line of the edited text and the end, whether lines of the original were removed before it
+  removedBefore: boolean[];
+}
+
+// Above this many line pairs the changed lines are compared as a whole block, so typing in a huge prompt
+// stays responsive
+const MAX_COMPARED_PAIRS = 4_000_000;
+
+const splitLines = (text: string) => (text === '' ? [] : text.split(/\r\n|\r|\n/));
+
+// Diffs the lines of the edited text against the original with a longest common subsequence. The changes
+// between two unchanged lines form a hunk, its added lines replacing removed ones are marked modified.
+export function diffLines(original: string, edited: string): LineDiff {
+  const a = splitLines(original);
+  const b = splitLines(edited);
+
+  // the common start and end are unchanged whatever the edits between them
+  let start = 0;
+  while (start < a.length && start < b.length && a[start] === b[start]) start++;
+  let end = 0;
+  while (end < a.length - start && end < b.length - start && a[a.length - 1 - end] === b[b.length - 1 - end]) end++;
+  const middleA = a.slice(start, a.length - end);
+  const middleB = b.slice(start, b.length - end);
+
+  // the operations on the middle lines, ' ' kept, '-' removed, '+' added
+  const ops: string[] = [];
+  if (middleA.length * middleB.length > MAX_COMPARED_PAIRS) {
+    ops.push(...middleA.map(() => '-'), ...middleB.map(() => '+'));
+  } else {
+    const n = middleA.length;
+    const m = middleB.length;
+    const lcs: number[][] = Array.from({ length: n + 1 }, () => new Array(m + 1).fill(0));
+    for (let i = n - 1; i >= 0; i--) {
+      for (let j = m - 1; j >= 0; j--) {
+        lcs[i][j] = middleA[i] === middleB[j] ? lcs[i + 1][j + 1] + 1 : Math.max(lcs[i + 1][j], lcs[i][j + 1]);
+      }
+    }
+    let i = 0;
+    let j = 0;
+    while (i < n || j < m) {
+      if (i < n && j < m && middleA[i] === middleB[j]) {
+        ops.push(' ');
+        i++;
+        j++;
+      } else if (j < m && (i === n || lcs[i][j + 1] >= lcs[i + 1][j])) {
+        ops.push('+');
+        j++;
+      } else {
+        ops.push('-');
+        i++;
+      }
+    }
+  }
+
+  const diff: LineDiff = {
+    added: 0,
+    removed: 0,
+    marks: new Array(start).fill('unchanged'),
+    removedBefore: new Array(b.length + 1).fill(false)
+  };
+  let hunkRemoved = 0;
+  let hunkAdded: number[] = [];
+  const closeHunk = () => {
+    hunkAdded.forEach((line, k) => (diff.marks[line] = k < hunkRemoved ? 'modified' : 'added'));
+    if (hunkRemoved > hunkAdded.length) {
+      diff.removedBefore[diff.marks.length] = true;
+    }
+    hunkRemoved = 0;
+    hunkAdded = [];
+  };
+  for (const op of ops) {
+    if (op === ' ') {
+      closeHunk();
+      diff.marks.push('unchanged');
+    } else if (op === '-') {
+      hunkRemoved++;
+      diff.removed++;
+    } else {
+      hunkAdded.push(diff.marks.length);
+      diff.marks.push('added');
+      diff.added++;
+    }
+  }
+  closeHunk();
+  diff.marks.push(...new Array(end).fill('unchanged'));
+  return diff;
+}
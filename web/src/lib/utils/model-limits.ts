@@ -0,0 +1,182 @@
+// Context windows in tokens by model name prefix, the longest matching prefix wins
+const CONTEXT_WINDOWS: [string, number][] = [
+  ['gpt-4.1', 1_047_576],
+  ['gpt-4o', 128_000],
+  ['gpt-4-turbo', 128_000],
+  ['gpt-4', 8_192],
+  ['gpt-3.5-turbo', 16_385],
+  ['gpt-5', 400_000],
+  ['o1', 200_000],
+  ['o3', 200_000],
+  ['o4', 200_000],
+  ['claude', 200_000],
+  ['gemini-1.5-pro', 2_097_152],
+  ['gemini', 1_048_576],
+  ['llama3.1', 128_000],
+  ['llama3.2', 128_000],
+  ['llama3.3', 128_000],
+  ['llama3', 8_192],
+  ['llama2', 4_096],
+  ['mistral-large', 128_000],
+  ['mistral', 32_768],
+  ['mixtral', 32_768],
+  ['qwen2.5', 32_768],
+  ['deepseek', 128_000],
+  ['grok', 131_072],
+  ['command-r', 128_000],
+  ['phi3', 128_000],
+  ['gemma', 8_192]
+];
+
+// Relative price tiers by model name prefix, from 1 (cheapest) to 4, the longest matching prefix wins
+const COST_TIERS: [string, number][] = [
+  ['gpt-4.1-nano', 1],
+  ['gpt-4.1-mini', 1],
+  ['gpt-4.1', 2],
+  ['gpt-4o-mini', 1],
+  ['gpt-4o', 2],
+  ['gpt-4-turbo', 3],
+  ['gpt-4', 4],
+  ['gpt-3.5-turbo', 1],
+  ['gpt-5-nano', 1],
+  ['gpt-5-mini', 1],
+  ['gpt-5', 2],
+  ['o1-mini', 2],
+  ['o1', 4],
+  ['o3-mini', 2],
+  ['o3', 3],
+  ['o4-mini', 2],
+  ['claude-3-haiku', 1],
+  ['claude-3-5-haiku', 1],
+  ['claude-haiku', 1],
+  ['claude', 3],
+  ['claude-3-opus', 4],
+  ['claude-opus', 4],
+  ['gemini-1.5-flash', 1],
+  ['gemini-2.0-flash', 1],
+  ['gemini-2.5-flash', 1],
+  ['gemini', 2],
+  ['mistral-small', 1],
+  ['mistral-large', 2],
+  ['deepseek', 1],
+  ['grok', 2],
+  ['command-r-plus', 2],
+  ['command-r', 1]
+];
+
+// Whether the models read images by model name prefix, the longest matching prefix wins
+const VISION: [string, boolean][] = [
+  ['gpt-4o', true],
+  ['gpt-4.1', true],
+  ['gpt-4-turbo', true],
+  ['gpt-4', false],
+  ['gpt-3.5', false],
+  ['gpt-5', true],
+  ['o1', true],
+  ['o1-mini', false],
+  ['o3', true],
+  ['o3-mini', false],
+  ['o4-mini', true],
+  ['claude', true],
+  ['claude-2', false],
+  ['claude-instant', false],
+  ['gemini', true],
+  ['llava', true],
+  ['bakllava', true],
+  ['llama3.2-vision', true],
+  ['llama4', true],
+  ['qwen2.5vl', true],
+  ['qwen2.5-vl', true],
+  ['qwen-vl', true],
+  ['gemma3', true],
+  ['minicpm-v', true],
+  ['moondream', true],
+  ['pixtral', true],
+  ['grok-2-vision', true],
+  ['grok-4', true]
+];
+
+// Whether the models call tools (function calling) by model name prefix, the longest matching prefix wins
+const TOOLS: [string, boolean][] = [
+  ['gpt-4', true],
+  ['gpt-3.5-turbo', true],
+  ['gpt-5', true],
+  ['o1', true],
+  ['o1-mini', false],
+  ['o3', true],
+  ['o4', true],
+  ['claude', true],
+  ['claude-2', false],
+  ['claude-instant', false],
+  ['gemini', true],
+  ['llama3.1', true],
+  ['llama3.2', true],
+  ['llama3.3', true],
+  ['llama4', true],
+  ['mistral', true],
+  ['mixtral', true],
+  ['qwen2.5', true],
+  ['qwen3', true],
+  ['deepseek-chat', true],
+  ['deepseek-v3', true],
+  ['grok', true],
+  ['command-r', true]
+];
+
+// Whether the models take a JSON output format by model name prefix, the longest matching prefix wins
+const JSON_OUTPUT: [string, boolean][] = [
+  ['gpt-4o', true],
+  ['gpt-4.1', true],
+  ['gpt-4-turbo', true],
+  ['gpt-3.5-turbo', true],
+  ['gpt-5', true],
+  ['o1', true],
+  ['o3', true],
+  ['o4', true],
+  ['gemini', true],
+  ['mistral', true],
+  ['deepseek', true],
+  ['grok', true]
+];
+
+// Vendors running the models on the local machine, their runs cost nothing
+const LOCAL_VENDORS = ['ollama', 'lm studio'];
+
+// Returns the entry of the longest prefix of the model name, vendor prefixes like "openai/" or "models/"
+// are ignored
+function lookup<T>(table: [string, T][], model: string): T | undefined {
+  const name = model.toLowerCase().split('/').pop() || '';
+  let match: [string, T] | undefined;
+  for (const entry of table) {
+    if (name.startsWith(entry[0]) && (!match || entry[0].length > match[0].length)) {
+      match = entry;
+    }
+  }
+  return match?.[1];
+}
+
+// Returns the context window of the model, undefined when it isn't known
+export function contextWindow(model: string): number | undefined {
+  return lookup(CONTEXT_WINDOWS, model);
+}
+
+// Tells whether the model reads the images attached to the input, false when it isn't known
+export function supportsVision(model: string): boolean {
+  return lookup(VISION, model) ?? false;
+}
+
+// Returns the price tier of the model from 1 to 4, 0 for local models and undefined when it isn't known
+export function costTier(model: string, vendor?: string): number | undefined {
+  if (vendor && LOCAL_VENDORS.includes(vendor.toLowerCase())) return 0;
+  return lookup(COST_TIERS, model);
+}
+
+// Tells whether the model calls tools, undefined when it isn't known
+export function supportsTools(model: string): boolean | undefined {
+  return lookup(TOOLS, model);
+}
+
+// Tells whether the model can be held to a JSON output, undefined when it isn't known
+export function supportsJson(model: string): boolean | undefined {
+  return lookup(JSON_OUTPUT, model);
+}
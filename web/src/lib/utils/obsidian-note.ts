@@ -0,0 +1,59 @@
+// The fields a note sent to Obsidian is named after and that its frontmatter may hold
+export interface NoteFields {
+  pattern: string;
+  model: string;
+  date: string; // YYYY-MM-DD
+  tags: string[];
+}
+
+export const DEFAULT_NOTE_TEMPLATE = '{{date}}-{{pattern}}';
+
+// Characters Obsidian doesn't allow in the names of the notes
+const forbidden = /[\\/:*?"<>|#^[\]]/g;
+
+// Names the note after the template, {{pattern}}, {{model}}, {{date}} and {{time}} are replaced by the
+// fields of the note
+export function noteFileName(template: string, fields: NoteFields, now = new Date()): string {
+  const values: Record<string, string> = {
+    pattern: fields.pattern || 'fabric',
+    model: fields.model || 'model',
+    date: fields.date,
+    time: now.toTimeString().slice(0, 5).replace(':', '')
+  };
+  const name = (template || DEFAULT_NOTE_TEMPLATE)
+    .replace(/\{\{(\w+)\}\}/g, (placeholder, key: string) => values[key] ?? placeholder)
+    .replace(forbidden, '-')
+    .trim();
+  return `${name || values.date}.md`;
+}
+
+// Splits the tags typed into a text field, separated by commas or spaces, without their #
+export function parseTags(text: string): string[] {
+  return text
+    .split(/[\s,]+/)
+    .map(tag => tag.replace(/^#/, ''))
+    .filter(Boolean);
+}
+
+// The YAML frontmatter of the note with the selected fields, empty when none is selected
+export function noteFrontmatter(fields: Partial<NoteFields>): string {
+  const lines: string[] = [];
+  for (const [key, value] of Object.entries(fields)) {
+    if (Array.isArray(value)) {
+      if (value.length === 0) continue;
+      lines.push(`${key}:`, ...value.map(item => `  - ${yamlString(item)}`));
+    } else if (value) {
+      lines.push(`${key}: ${yamlString(value)}`);
+    }
+  }
+  return lines.length > 0 ? `---\n${lines.join('\n')}\n---\n\n` : '';
+}
+
+// Quotes the values YAML would read as something else than a plain string, the dates are left for
+// Obsidian to read as dates
+function yamlString(value: string): string {
+  if (/^\d{4}-\d{2}-\d{2}$/.test(value)) return value;
+  return /^[\w][\w .\-/]*$/.test(value) && !/^(true|false|null|yes|no|~)$/i.test(value) && !/^[\d.-]+$/.test(value)
+    ? value
+    : JSON.stringify(value);
+}
@@ -0,0 +1,20 @@
+// Parses a page range like "1-5, 8, 10-12" into the sorted, distinct page numbers it covers
+export function parsePageRange(spec: string, pageCount: number): number[] {
+  const pages = new Set<number>();
+  for (const part of spec.split(',').map(p => p.trim()).filter(Boolean)) {
+    const match = part.match(/^(\d+)\s*(?:-\s*(\d+))?$/);
+    if (!match) {
+      throw new Error(`Invalid page range "${part}", use e.g. 1-5, 8`);
+    }
+    const first = Number(match[1]);
+    const last = match[2] ? Number(match[2]) : first;
+    if (first < 1 || last > pageCount || first > last) {
+      throw new Error(`Pages ${part} are outside of 1-${pageCount}`);
+    }
+    for (let page = first; page <= last; page++) pages.add(page);
+  }
+  if (pages.size === 0) {
+    throw new Error('No pages selected');
+  }
+  return [...pages].sort((a, b) => a - b);
+}
@@ -0,0 +1,99 @@
+import type { Pattern } from '$lib/interfaces/pattern-interface';
+
+export interface PatternSearchOptions {
+  searchPrompts?: boolean; // match the system prompts too
+}
+
+// The weight of a match in each field, a typo halves it at least. The typos are tolerated in the names
+// and tags only, the words of the longer texts would match too many of them
+const NAME_WEIGHT = 100;
+const TAG_WEIGHT = 40;
+const DESCRIPTION_WEIGHT = 30;
+const PROMPT_WEIGHT = 10;
+
+// The typos a term of the query may have to still match a word, by the length of the term
+const maxTypos = (term: string) => (term.length <= 3 ? 0 : term.length <= 6 ? 1 : 2);
+
+// The words of a name like extract_wisdom or of a text, lowercased
+const splitWords = (text: string) => text.toLowerCase().split(/[^\p{L}\p{N}]+/u).filter(Boolean);
+
+// The edit distance of a to b, counting the transposition of two letters as one typo, or limit + 1
+// once it is over the limit
+function editDistance(a: string, b: string, limit: number): number {
+  if (Math.abs(a.length - b.length) > limit) return limit + 1;
+  let previous2: number[] = [];
+  let previous = Array.from({ length: b.length + 1 }, (_, j) => j);
+  for (let i = 1; i <= a.length; i++) {
+    const current = [i];
+    let rowMin = i;
+    for (let j = 1; j <= b.length; j++) {
+      const cost = a[i - 1] === b[j - 1] ? 0 : 1;
+      current[j] = Math.min(previous[j] + 1, current[j - 1] + 1, previous[j - 1] + cost);
+      if (i > 1 && j > 1 && a[i - 1] === b[j - 2] && a[i - 2] === b[j - 1]) {
+        current[j] = Math.min(current[j], previous2[j - 2] + 1);
+      }
+      rowMin = Math.min(rowMin, current[j]);
+    }
+    if (rowMin > limit) return limit + 1;
+    previous2 = previous;
+    previous = current;
+  }
+  return previous[b.length];
+}
+
+// The fewest typos of the term against the start of a word, so "sumar" finds summarize, or
+// undefined when there are too many
+function typos(term: string, words: string[]): number | undefined {
+  const limit = maxTypos(term);
+  if (limit === 0) return undefined;
+  let best: number | undefined;
+  for (const word of words) {
+    for (let length = term.length - limit; length <= term.length + limit; length++) {
+      if (length <= 0 || length > word.length) continue;
+      const distance = editDistance(term, word.slice(0, length), limit);
+      if (distance <= limit && (best === undefined || distance < best)) best = distance;
+    }
+  }
+  return best;
+}
+
+// The score of the term in a field: the weight when the field contains it, less with typos, 0 without
+function fieldScore(term: string, text: string, weight: number, fuzzy = true): number {
+  const lower = text.toLowerCase();
+  const index = lower.indexOf(term);
+  if (index >= 0) {
+    // a match at the start of the field or of a word counts more
+    return index === 0 || !/[\p{L}\p{N}]/u.test(lower[index - 1]) ? weight : weight * 0.8;
+  }
+  if (!fuzzy) return 0;
+  const distance = typos(term, splitWords(text));
+  return distance === undefined ? 0 : weight / (2 * distance);
+}
+
+// The score of the pattern for the query, 0 when a term of the query matches none of its fields
+export function scorePattern(pattern: Pattern, query: string, options: PatternSearchOptions = {}): number {
+  let score = 0;
+  for (const term of query.toLowerCase().split(/\s+/).filter(Boolean)) {
+    const termScore = Math.max(
+      fieldScore(term, pattern.Name, NAME_WEIGHT),
+      ...(pattern.tags ?? []).map(tag => fieldScore(term, tag, TAG_WEIGHT)),
+      fieldScore(term, pattern.Description, DESCRIPTION_WEIGHT, false),
+      options.searchPrompts ? fieldScore(term, pattern.Pattern, PROMPT_WEIGHT, false) : 0
+    );
+    if (termScore === 0) return 0;
+    score += termScore;
+  }
+  if (pattern.Name.toLowerCase() === query.trim().toLowerCase()) score += NAME_WEIGHT;
+  return score;
+}
+
+// The patterns matching the query, tolerating typos, the best matches first; the patterns keep their
+// order when the query is empty or they match as well
+export function filterPatterns<T extends Pattern>(patterns: T[], query: string, options: PatternSearchOptions = {}): T[] {
+  if (!query.trim()) return patterns;
+  return patterns
+    .map(pattern => ({ pattern, score: scorePattern(pattern, query, options) }))
+    .filter(match => match.score > 0)
+    .sort((a, b) => b.score - a.score)
+    .map(match => match.pattern);
+}
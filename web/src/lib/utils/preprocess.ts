@@ -0,0 +1,41 @@
+export type PreprocessStep = 'trim' | 'strip-html' | 'remove-images' | 'collapse-blank-lines' | 'lowercase';
+
+// A step of the pipeline, the steps run in the order of the list
+export interface PreprocessSetting {
+  step: PreprocessStep;
+  enabled: boolean;
+}
+
+export const preprocessSteps: { step: PreprocessStep; label: string; description: string }[] = [
+  { step: 'strip-html', label: 'Strip HTML', description: 'Remove the HTML tags, scripts and styles, keeping the text' },
+  { step: 'remove-images', label: 'Remove images', description: 'Remove the markdown images, ![alt](url)' },
+  { step: 'trim', label: 'Trim whitespace', description: 'Remove the trailing spaces of the lines and the blank start and end' },
+  { step: 'collapse-blank-lines', label: 'Collapse blank lines', description: 'Keep a single blank line between paragraphs' },
+  { step: 'lowercase', label: 'Lowercase', description: 'Convert the text to lowercase' }
+];
+
+const entities: Record<string, string> = { amp: '&', lt: '<', gt: '>', quot: '"', apos: "'", nbsp: ' ', '#39': "'" };
+
+function stripHtml(text: string): string {
+  return text
+    .replace(/<(script|style|head)\b[^>]*>[\s\S]*?<\/\1>/gi, '')
+    .replace(/<!--[\s\S]*?-->/g, '')
+    .replace(/<br\s*\/?>/gi, '\n')
+    .replace(/<\/(p|div|li|tr|h[1-6]|blockquote|pre|section|article)>/gi, '\n')
+    .replace(/<\/?[a-z][^>]*>/gi, '')
+    .replace(/&(amp|lt|gt|quot|apos|nbsp|#39);/g, (_, name: string) => entities[name]);
+}
+
+const steps: Record<PreprocessStep, (text: string) => string> = {
+  'trim': text => text.replace(/[ \t]+$/gm, '').trim(),
+  'strip-html': stripHtml,
+  // inline and reference images, with an optional title
+  'remove-images': text => text.replace(/!\[[^\]]*\](\([^)]*\)|\[[^\]]*\])/g, ''),
+  'collapse-blank-lines': text => text.replace(/\n[ \t]*(\n[ \t]*)+\n/g, '\n\n'),
+  'lowercase': text => text.toLowerCase()
+};
+
+// Runs the enabled steps on the text, in order
+export function preprocess(text: string, settings: PreprocessSetting[]): string {
+  return settings.reduce((result, setting) => (setting.enabled ? steps[setting.step](result) : result), text);
+}
@@ -0,0 +1,55 @@
+export interface Region {
+  x: number;
+  y: number;
+  width: number;
+  height: number;
+}
+
+// Asks the browser for a screen, window or tab and grabs one frame of it. The capture stops right
+// after, the browser shows its sharing indicator only for a moment.
+export async function captureScreen(): Promise<HTMLCanvasElement> {
+  if (!navigator.mediaDevices?.getDisplayMedia) {
+    throw new Error('Screen capture is not supported by this browser');
+  }
+  const stream = await navigator.mediaDevices.getDisplayMedia({ video: true, audio: false });
+  try {
+    const video = document.createElement('video');
+    video.srcObject = stream;
+    video.muted = true;
+    await video.play();
+    // the first frame may not be painted yet when play resolves
+    await new Promise(resolve => requestAnimationFrame(resolve));
+
+    const canvas = document.createElement('canvas');
+    canvas.width = video.videoWidth;
+    canvas.height = video.videoHeight;
+    canvas.getContext('2d')?.drawImage(video, 0, 0);
+    video.srcObject = null;
+    return canvas;
+  } finally {
+    stream.getTracks().forEach(track => track.stop());
+  }
+}
+
+// Returns the region of the canvas as PNG, the whole canvas without a region. The region is in
+// canvas pixels.
+export function cropToPng(canvas: HTMLCanvasElement, region?: Region): Promise<Blob> {
+  const { x, y, width, height } = region ?? { x: 0, y: 0, width: canvas.width, height: canvas.height };
+  const cropped = document.createElement('canvas');
+  cropped.width = Math.max(1, Math.round(width));
+  cropped.height = Math.max(1, Math.round(height));
+  cropped.getContext('2d')?.drawImage(canvas, x, y, width, height, 0, 0, cropped.width, cropped.height);
+  return new Promise((resolve, reject) => {
+    cropped.toBlob(blob => (blob ? resolve(blob) : reject(new Error('Could not encode the screenshot'))), 'image/png');
+  });
+}
+
+// Normalizes the rectangle between two corners dragged in any direction
+export function regionBetween(a: { x: number; y: number }, b: { x: number; y: number }): Region {
+  return {
+    x: Math.min(a.x, b.x),
+    y: Math.min(a.y, b.y),
+    width: Math.abs(a.x - b.x),
+    height: Math.abs(a.y - b.y)
+  };
+}
@@ -0,0 +1,47 @@
+export interface Sheet {
+  name: string;
+  columns: string[];
+  rows: string[][];
+  // the server reads the first 10000 rows only
+  truncated?: boolean;
+}
+
+export type SheetFormat = 'markdown' | 'json';
+
+export function isSpreadsheetFile(name: string): boolean {
+  return /\.(csv|tsv|xlsx)$/i.test(name);
+}
+
+// Serializes the first rows of the chosen columns of the sheet for the pattern, as a markdown table or
+// as a JSON array of objects keyed by the column names
+export function serializeSheet(sheet: Sheet, columns: number[], rowLimit: number, format: SheetFormat): string {
+  if (columns.length === 0) {
+    throw new Error(`No columns of ${sheet.name} selected`);
+  }
+  const rows = sheet.rows.slice(0, Math.max(rowLimit, 0));
+  const total = sheet.truncated ? `over ${sheet.rows.length}` : `${sheet.rows.length}`;
+  const heading = `Sheet ${sheet.name}, ${rows.length} of ${total} rows:`;
+
+  if (format === 'json') {
+    const records = rows.map(row => Object.fromEntries(columns.map(c => [sheet.columns[c], row[c] ?? ''])));
+    return `${heading}\n\n${JSON.stringify(records, null, 2)}`;
+  }
+  const line = (cells: string[]) => `| ${cells.map(escapeCell).join(' | ')} |`;
+  return [
+    heading,
+    '',
+    line(columns.map(c => sheet.columns[c])),
+    `|${columns.map(() => ' --- ').join('|')}|`,
+    ...rows.map(row => line(columns.map(c => row[c] ?? '')))
+  ].join('\n');
+}
+
+function escapeCell(cell: string): string {
+  return cell.replace(/\|/g, '\\|').replace(/\r?\n/g, '<br>');
+}
+
+// Serializes the rows as CSV, the cells with commas, quotes or line breaks quoted
+export function toCsv(rows: string[][]): string {
+  const cell = (value: string) => (/[",\r\n]/.test(value) ? `"${value.replace(/"/g, '""')}"` : value);
+  return rows.map(row => row.map(cell).join(',')).join('\r\n');
+}
@@ -0,0 +1,19 @@
+// Placeholders the server fills itself: the input, plugin and extension calls
+const RESERVED = new Set(['input']);
+const PLACEHOLDER = /\{\{\s*([A-Za-z_][\w.-]*)\s*\}\}/g;
+
+// Returns the distinct {{variable}} placeholders of the texts in order of appearance
+export function findVariables(...texts: string[]): string[] {
+  const names = new Set<string>();
+  for (const text of texts) {
+    for (const match of text.matchAll(PLACEHOLDER)) {
+      if (!RESERVED.has(match[1])) names.add(match[1]);
+    }
+  }
+  return [...names];
+}
+
+// Replaces the placeholders of the given variables, others are left for the server
+export function fillVariables(text: string, values: Record<string, string>): string {
+  return text.replace(PLACEHOLDER, (placeholder, name: string) => (name in values ? values[name] : placeholder));
+}
@@ -0,0 +1,66 @@
+import { countTokens } from './tokenizer';
+
+export interface TextStats {
+  characters: number;
+  words: number;
+  lines: number;
+  tokens: number; // as counted by BPE tokenizers, see countTokens
+}
+
+export function textStats(text: string): TextStats {
+  const trimmed = text.trim();
+  return {
+    characters: text.length,
+    words: trimmed ? trimmed.split(/\s+/).length : 0,
+    lines: text ? text.split(/\r\n|\r|\n/).length : 0,
+    tokens: countTokens(text)
+  };
+}
+
+// Average silent reading speed of adults, in words per minute
+const WORDS_PER_MINUTE = 238;
+
+// The minutes it takes to read the words, at least one for any text
+export function readingMinutes(words: number): number {
+  return words > 0 ? Math.max(1, Math.round(words / WORDS_PER_MINUTE)) : 0;
+}
+
+export interface Readability {
+  score: number; // Flesch reading ease, 100 very easy to 0 very difficult, may fall outside for extreme texts
+  label: string;
+}
+
+const readabilityLabels: [number, string][] = [
+  [90, 'very easy'],
+  [80, 'easy'],
+  [70, 'fairly easy'],
+  [60, 'plain English'],
+  [50, 'fairly difficult'],
+  [30, 'difficult'],
+  [-Infinity, 'very difficult']
+];
+
+// The Flesch reading ease of the prose of a markdown text, its code, links and markup left out. It is
+// made for English, undefined for a text without words.
+export function readability(markdown: string): Readability | undefined {
+  const prose = markdown
+    .replace(/```[\s\S]*?```/g, ' ')
+    .replace(/`[^`]*`/g, ' ')
+    .replace(/!?\[([^\]]*)\]\([^)]*\)/g, '$1')
+    .replace(/https?:\/\/\S+/g, ' ');
+  const words = prose.match(/\p{L}+(?:['’]\p{L}+)*/gu) ?? [];
+  if (words.length === 0) return undefined;
+  // list items and headings end without a period, each line of them is a sentence
+  const sentences = Math.max(1, prose.split(/[.!?]+(?:\s|$)|\n\s*(?:[-*+]|\d+\.|#+)\s|\n{2,}/).filter(s => /\p{L}/u.test(s)).length);
+  const syllables = words.reduce((total, word) => total + countSyllables(word), 0);
+  const score = Math.round(206.835 - 1.015 * (words.length / sentences) - 84.6 * (syllables / words.length));
+  return { score, label: readabilityLabels.find(([min]) => score >= min)![1] };
+}
+
+// Estimates the syllables of an English word by its groups of vowels, without the silent final e
+export function countSyllables(word: string): number {
+  const lower = word.toLowerCase().replace(/['’]/g, '');
+  if (lower.length <= 3) return 1;
+  const groups = lower.replace(/(?:[^laeiouy]es|[^laeiouy]ed|[^laeiouy]e)$/, '').replace(/^y/, '').match(/[aeiouy]{1,2}/g);
+  return Math.max(1, groups?.length ?? 1);
+}
@@ -0,0 +1,31 @@
+// Splits text like the pre-tokenizer of the cl100k and o200k BPE encodings (GPT-4, GPT-4o) before merging:
+// contractions, words with their leading space, numbers of up to 3 digits, punctuation runs and whitespace
+const PRE_TOKENIZE = /'(?:[sdmt]|ll|ve|re)|[^\r\n\p{L}\p{N}]?\p{L}+|\p{N}{1,3}| ?[^\s\p{L}\p{N}]+[\r\n]*|\s*[\r\n]+|\s+(?!\S)|\s+/giu;
+
+// BPE vocabularies hold whole common words, longer and rarer words are merged from pieces of about this
+// many characters
+const CHARS_PER_WORD_PIECE = 6;
+
+// Counts the tokens of text as BPE tokenizers do: the text is pre-tokenized exactly, then the merges of each
+// piece are estimated, the vocabulary itself is too large to ship to the browser. Much closer to the real
+// count than 4 characters per token for code, numbers and languages other than English.
+export function countTokens(text: string): number {
+  let tokens = 0;
+  for (const [piece] of text.matchAll(PRE_TOKENIZE)) {
+    tokens += pieceTokens(piece);
+  }
+  return tokens;
+}
+
+function pieceTokens(piece: string): number {
+  const word = piece.trimStart();
+  if (word === '') return 1; // a whitespace run
+  // scripts without spaces between words, like CJK, take about a token per character
+  const wide = [...word].filter(c => c.codePointAt(0)! > 0x2e80).length;
+  if (wide > 0) return wide + Math.ceil((word.length - wide) / CHARS_PER_WORD_PIECE);
+  if (/^\p{L}+$/u.test(word)) return Math.max(1, Math.ceil((word.length - 2) / CHARS_PER_WORD_PIECE));
+  // numbers are pre-tokenized by 3 digits, all of them are in the vocabulary
+  if (/^\p{N}+$/u.test(word)) return 1;
+  // punctuation merges less
+  return Math.max(1, Math.ceil(word.length / 2));
+}
@@ -0,0 +1,87 @@
+export interface WordChange {
+  op: 'equal' | 'insert' | 'delete';
+  text: string;
+}
+
+// The patterns editing their input rather than producing something new from it, their output is read
+// against the input
+const EDITING_PATTERN = /^(improve|fix|clean|humanize|refine|rewrite|correct|edit|proofread)(_|$)/;
+
+export function isEditingPattern(name: string | undefined): boolean {
+  return !!name && EDITING_PATTERN.test(name);
+}
+
+// Above this many word pairs the changed words are compared as a whole block, as for the lines of
+// line-diff
+const MAX_COMPARED_PAIRS = 4_000_000;
+
+// The words, the punctuation and the whitespace between them, the changes rebuild the text as is
+const splitWords = (text: string) => text.match(/\s+|[\p{L}\p{N}]+|[^\s\p{L}\p{N}]/gu) ?? [];
+
+// Diffs the words of the edited text against the original with a longest common subsequence, the
+// consecutive words of a change are merged
+export function diffWords(original: string, edited: string): WordChange[] {
+  const a = splitWords(original);
+  const b = splitWords(edited);
+
+  let start = 0;
+  while (start < a.length && start < b.length && a[start] === b[start]) start++;
+  let end = 0;
+  while (end < a.length - start && end < b.length - start && a[a.length - 1 - end] === b[b.length - 1 - end]) end++;
+  const middleA = a.slice(start, a.length - end);
+  const middleB = b.slice(start, b.length - end);
+
+  const changes: WordChange[] = [];
+  const push = (op: WordChange['op'], text: string) => {
+    const last = changes[changes.length - 1];
+    if (last?.op === op) last.text += text;
+    else changes.push({ op, text });
+  };
+
+  a.slice(0, start).forEach(word => push('equal', word));
+  const n = middleA.length;
+  const m = middleB.length;
+  if (n * m > MAX_COMPARED_PAIRS) {
+    middleA.forEach(word => push('delete', word));
+    middleB.forEach(word => push('insert', word));
+  } else {
+    const lcs: number[][] = Array.from({ length: n + 1 }, () => new Array(m + 1).fill(0));
+    for (let i = n - 1; i >= 0; i--) {
+      for (let j = m - 1; j >= 0; j--) {
+        lcs[i][j] = middleA[i] === middleB[j] ? lcs[i + 1][j + 1] + 1 : Math.max(lcs[i + 1][j], lcs[i][j + 1]);
+      }
+    }
+    let i = 0;
+    let j = 0;
+    while (i < n || j < m) {
+      if (i < n && j < m && middleA[i] === middleB[j]) {
+        push('equal', middleA[i++]);
+        j++;
+      } else if (i < n && (j === m || lcs[i + 1][j] >= lcs[i][j + 1])) {
+        // the deletions come first, so a replaced word reads as the old one then the new one
+        push('delete', middleA[i++]);
+      } else {
+        push('insert', middleB[j++]);
+      }
+    }
+  }
+  a.slice(a.length - end).forEach(word => push('equal', word));
+  return changes;
+}
+
+const escapeHtml = (text: string) =>
+  text.replace(/&/g, '&amp;').replace(/</g, '&lt;').replace(/>/g, '&gt;').replace(/"/g, '&quot;');
+
+// The changes as a redline, deletions struck through in red and insertions underlined in blue, with
+// inline styles so it keeps them pasted into a word processor or an email
+export function redlineHtml(changes: WordChange[]): string {
+  const body = changes
+    .map(change => {
+      const text = escapeHtml(change.text);
+      if (change.op === 'insert') return `<ins style="color:#1d4ed8;text-decoration:underline">${text}</ins>`;
+      if (change.op === 'delete') return `<del style="color:#b91c1c;text-decoration:line-through">${text}</del>`;
+      return text;
+    })
+    .join('');
+  return `<div style="white-space:pre-wrap">${body}</div>`;
+}
@@ -0,0 +1,5 @@
+import { dev } from '$app/environment';
+
+export const csr = dev;
+
+export const prerender = false;
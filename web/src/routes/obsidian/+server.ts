@@ -1,105 +1,90 @@
 import { json } from '@sveltejs/kit';
 import type { RequestHandler } from './$types';
-import { exec } from 'child_process';
-import { promisify } from 'util';
+import { mkdir, realpath, writeFile } from 'fs/promises';
+import path from 'path';
 
-const execAsync = promisify(exec);
+// The vault is set on the web server only, by FABRIC_OBSIDIAN_VAULT, absolute or relative to the server
+const defaultVault = 'myfiles/Fabric_obsidian';
 
+// The note is written to the folder of the vault under fileName, with the frontmatter before the content.
+// The requests with a noteName only are written as before: named after the date and the note name, the
+// content in a markdown code block.
 interface ObsidianRequest {
   pattern: string;
-  noteName: string;
   content: string;
+  noteName?: string;
+  folder?: string; // in the vault, its root when empty
+  fileName?: string;
+  frontmatter?: string;
 }
 
-function escapeShellArg(arg: string): string {
-  // Replace single quotes with '\'' and wrap in single quotes
-  return `'${arg.replace(/'/g, "'\\''")}'`;
+const isInside = (root: string, dir: string) => {
+  const relative = path.relative(root, dir);
+  return !relative.startsWith('..') && !path.isAbsolute(relative);
+};
+
+// Resolves the folder of the request in the vault, refusing the folders leaving it, symlinks included
+async function vaultFolder(folder: string | undefined): Promise<string | undefined> {
+  const vault = path.resolve(process.env.FABRIC_OBSIDIAN_VAULT?.trim() || defaultVault);
+  const dir = path.resolve(vault, folder?.trim() || '.');
+  if (!isInside(vault, dir)) return undefined;
+  await mkdir(dir, { recursive: true });
+  return isInside(await realpath(vault), await realpath(dir)) ? dir : undefined;
 }
 
-export const POST: RequestHandler = async ({ request }) => {
-  let tempFile: string | undefined;
+// Writes the note without overwriting one of the same name, " 2", " 3"... is appended to the name instead
+async function writeNote(dir: string, fileName: string, text: string): Promise<string> {
+  const ext = path.extname(fileName);
+  const base = fileName.slice(0, fileName.length - ext.length);
+  for (let i = 1; ; i++) {
+    const filePath = path.join(dir, i === 1 ? fileName : `${base} ${i}${ext}`);
+    try {
+      await writeFile(filePath, text, { flag: 'wx' });
+      return filePath;
+    } catch (error) {
+      if ((error as NodeJS.ErrnoException).code !== 'EEXIST') throw error;
+    }
+  }
+}
 
+export const POST: RequestHandler = async ({ request }) => {
   try {
-    // Parse and validate request
     const body = await request.json() as ObsidianRequest;
-    if (!body.pattern || !body.noteName || !body.content) {
+    if (!body.pattern || !body.content || !(body.noteName || body.fileName)) {
       return json(
-        { error: 'Missing required fields: pattern, noteName, or content' },
+        { error: 'Missing required fields: pattern, content, and noteName or fileName' },
         { status: 400 }
       );
     }
 
-    console.log('\n=== Obsidian Request ===');
-    console.log('1. Pattern:', body.pattern);
-    console.log('2. Note name:', body.noteName);
-    console.log('3. Content length:', body.content.length);
-
-  
-
-
-    
-
-    // Format content with markdown code blocks
-    const formattedContent = `\`\`\`markdown\n${body.content}\n\`\`\``;
-    const escapedFormattedContent = escapeShellArg(formattedContent);
-
-    // Generate file name and path
-    const fileName = `${new Date().toISOString().split('T')[0]}-${body.noteName}.md`;
-   
-    const obsidianDir = 'myfiles/Fabric_obsidian';
-    const filePath = `${obsidianDir}/${fileName}`;
-    await execAsync(`mkdir -p "${obsidianDir}"`);
-    console.log('4. Ensured Obsidian directory exists');
-
-
-    // Create temp file
-    tempFile = `/tmp/fabric-${Date.now()}.txt`;
-
-    // Write formatted content to temp file
-    await execAsync(`echo ${escapedFormattedContent} > "${tempFile}"`);
-    console.log('5. Wrote formatted content to temp file');
-
-    // Copy from temp file to final location (safer than direct write)
-    await execAsync(`cp "${tempFile}" "${filePath}"`);
-    console.log('6. Copied content to final location:', filePath);
+    let fileName: string;
+    let text: string;
+    if (body.fileName) {
+      fileName = path.basename(body.fileName.endsWith('.md') ? body.fileName : `${body.fileName}.md`);
+      text = `${body.frontmatter ?? ''}${body.content}\n`;
+    } else {
+      fileName = path.basename(`${new Date().toISOString().split('T')[0]}-${body.noteName}.md`);
+      text = `\`\`\`markdown\n${body.content}\n\`\`\`\n`;
+    }
 
-    // Verify file was created and has content
-    const { stdout: lsOutput } = await execAsync(`ls -l "${filePath}"`);
-    const { stdout: wcOutput } = await execAsync(`wc -l "${filePath}"`);
-    console.log('7. File verification:', lsOutput);
-    console.log('8. Line count:', wcOutput);
+    const dir = await vaultFolder(body.folder);
+    if (!dir) {
+      return json({ error: `The folder ${body.folder} is outside the vault` }, { status: 403 });
+    }
+    const filePath = await writeNote(dir, fileName, text);
+    console.log('Saved to Obsidian:', filePath);
 
-    // Return success response with file details
     return json({
       success: true,
-      fileName,
+      fileName: path.basename(filePath),
       filePath,
-      message: `Successfully saved to ${fileName}`
+      message: `Successfully saved to ${path.basename(filePath)}`
     });
-
   } catch (error) {
-    console.error('\n=== Error ===');
-    console.error('Type:', error?.constructor?.name);
-    console.error('Message:', error instanceof Error ? error.message : String(error));
-    console.error('Stack:', error instanceof Error ? error.stack : 'No stack trace');
-    
+    console.error('Failed to save to Obsidian:', error);
     return json(
-      {
-        error: error instanceof Error ? error.message : 'Failed to process request',
-        details: error instanceof Error ? error.stack : undefined
-      },
+      { error: error instanceof Error ? error.message : 'Failed to process request' },
       { status: 500 }
     );
-
-  } finally {
-    // Clean up temp file if it exists
-    if (tempFile) {
-      try {
-        await execAsync(`rm -f "${tempFile}"`);
-        console.log('9. Cleaned up temp file');
-      } catch (cleanupError) {
-        console.error('Failed to clean up temp file:', cleanupError);
-      }
-    }
   }
-};
\ No newline at end of file
+};